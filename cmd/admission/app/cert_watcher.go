@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+// certWatcher serves the TLS keypair loaded from disk, and reloads it when the files change,
+// so that rotated certificates are used without restarting the server.
+type certWatcher struct {
+	certFile string
+	keyFile  string
+
+	// cert holds the *tls.Certificate in use.
+	cert atomic.Value
+	// certStat and keyStat are the stats of files when the certificate is loaded.
+	certStat os.FileInfo
+	keyStat  os.FileInfo
+}
+
+// newCertWatcher creates a certWatcher with the keypair loaded from certFile and keyFile.
+func newCertWatcher(certFile, keyFile string) (*certWatcher, error) {
+	w := &certWatcher{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// GetCertificate returns the certificate in use, it is used as tls.Config.GetCertificate.
+func (w *certWatcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return w.cert.Load().(*tls.Certificate), nil
+}
+
+// Run checks the files every interval until stopCh is closed.
+func (w *certWatcher) Run(interval time.Duration, stopCh <-chan struct{}) {
+	wait.Until(func() {
+		changed, err := w.changed()
+		if err != nil {
+			klog.Errorf("Failed to check TLS keypair (%s, %s): %v", w.certFile, w.keyFile, err)
+			return
+		}
+		if !changed {
+			return
+		}
+
+		// The old keypair is kept if the new one is invalid, e.g. only one of the files is written.
+		if err := w.load(); err != nil {
+			klog.Errorf("Failed to reload TLS keypair (%s, %s): %v", w.certFile, w.keyFile, err)
+			return
+		}
+		klog.Infof("Reloaded TLS keypair (%s, %s).", w.certFile, w.keyFile)
+	}, interval, stopCh)
+}
+
+func (w *certWatcher) load() error {
+	certStat, err := os.Stat(w.certFile)
+	if err != nil {
+		return err
+	}
+	keyStat, err := os.Stat(w.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(w.certFile, w.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS keypair: %v", err)
+	}
+
+	w.cert.Store(&cert)
+	w.certStat, w.keyStat = certStat, keyStat
+
+	return nil
+}
+
+// changed returns whether any of the files is changed since the certificate is loaded.
+func (w *certWatcher) changed() (bool, error) {
+	certStat, err := os.Stat(w.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyStat, err := os.Stat(w.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	return !sameFile(certStat, w.certStat) || !sameFile(keyStat, w.keyStat), nil
+}
+
+// sameFile compares the size and modification time of files; os.Stat follows symlinks,
+// so the symlink swap of a mounted secret is detected too.
+func sameFile(l, r os.FileInfo) bool {
+	return l.Size() == r.Size() && l.ModTime().Equal(r.ModTime())
+}
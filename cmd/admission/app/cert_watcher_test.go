@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func writeKeyPair(t *testing.T, host, certFile, keyFile string) {
+	cert, key, err := certutil.GenerateSelfSignedCertKey(host, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate keypair: %v", err)
+	}
+	if err := ioutil.WriteFile(certFile, cert, 0600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+// servedHost returns the host the served certificate is generated for.
+func servedHost(t *testing.T, w *certWatcher) string {
+	cert, err := w.GetCertificate(nil)
+	if err != nil || len(cert.Certificate) == 0 {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	// The common name of generated certificate is host@timestamp.
+	return strings.Split(leaf.Subject.CommonName, "@")[0]
+}
+
+func TestCertWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cert-watcher")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeKeyPair(t, "old.volcano.sh", certFile, keyFile)
+
+	w, err := newCertWatcher(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("failed to create cert watcher: %v", err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go w.Run(10*time.Millisecond, stopCh)
+
+	// Make sure the modification time changes on file systems with coarse timestamps.
+	time.Sleep(10 * time.Millisecond)
+	writeKeyPair(t, "new.volcano.sh", certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+
+	if err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return servedHost(t, w) == "new.volcano.sh", nil
+	}); err != nil {
+		t.Errorf("expected rotated certificate served, got %s", servedHost(t, w))
+	}
+
+	// An invalid keypair is ignored, the rotated one is still served.
+	if err := ioutil.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cert, err := w.GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Errorf("expected certificate served after invalid rotation, got %v", err)
+	}
+}
@@ -18,12 +18,17 @@ package options
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	"volcano.sh/volcano/pkg/admission/policy"
 )
 
 const (
 	defaultSchedulerName = "volcano"
+	defaultSlowThreshold = time.Second
+	defaultPolicyTimeout = 3 * time.Second
 )
 
 // Config admission-controller server config.
@@ -39,6 +44,21 @@ type Config struct {
 	WebhookNamespace string
 	SchedulerName    string
 	WebhookURL       string
+	EnableAudit      bool
+	AuditLogPath     string
+	SlowThreshold    time.Duration
+	// ValidateJobQueue rejects jobs whose queue does not exist or is closed
+	ValidateJobQueue bool
+	// ValidateJobCapability rejects jobs whose minResources exceed the capability of their queue
+	ValidateJobCapability bool
+	// CertReloadInterval is the interval to check and reload the certificate files, 0 disables the reload
+	CertReloadInterval time.Duration
+	// JobPolicyURL is the external policy endpoint consulted for jobs, empty disables it
+	JobPolicyURL string
+	// JobPolicyTimeout is the timeout of requests to the external policy endpoint
+	JobPolicyTimeout time.Duration
+	// JobPolicyFailurePolicy is Fail or Ignore, deciding the verdict if the policy endpoint can not be consulted
+	JobPolicyFailurePolicy string
 }
 
 // NewConfig create new config
@@ -55,6 +75,8 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 		"File containing the default x509 Certificate for HTTPS. (CA cert, if any, concatenated "+
 		"after server cert).")
 	fs.StringVar(&c.KeyFile, "tls-private-key-file", c.KeyFile, "File containing the default x509 private key matching --tls-cert-file.")
+	fs.DurationVar(&c.CertReloadInterval, "tls-cert-reload-interval", 0, "The interval to check --tls-cert-file and "+
+		"--tls-private-key-file, and reload them if changed; the files are loaded only once at startup if 0")
 	fs.IntVar(&c.Port, "port", 443, "the port used by admission-controller-server.")
 	fs.BoolVar(&c.PrintVersion, "version", false, "Show version and quit")
 
@@ -63,7 +85,24 @@ func (c *Config) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&c.WebhookName, "webhook-service-name", "", "The name of this webhook")
 	fs.StringVar(&c.WebhookURL, "webhook-url", "", "The url of this webhook")
 
-	fs.StringVar(&c.SchedulerName, "scheduler-name", defaultSchedulerName, "Volcano will handle pods whose .spec.SchedulerName is same as scheduler-name")
+	fs.StringVar(&c.SchedulerName, "scheduler-name", defaultSchedulerName, "Volcano will handle pods whose .spec.SchedulerName is same as scheduler-name, it is also set on job pod templates which do not specify one")
+
+	fs.BoolVar(&c.EnableAudit, "enable-audit", false, "Enable audit log of admission decisions")
+	fs.StringVar(&c.AuditLogPath, "audit-log-path", "", "File to write audit records as JSON lines; stdout is used if empty or '-'")
+
+	fs.DurationVar(&c.SlowThreshold, "slow-admission-threshold", defaultSlowThreshold, "Admission requests taking longer than "+
+		"the threshold are logged with their UID at level 2, 0 disables the log")
+
+	fs.BoolVar(&c.ValidateJobQueue, "validate-job-queue", true, "Reject jobs whose queue does not exist or is closed; "+
+		"disable it if queues are created asynchronously with their jobs")
+	fs.BoolVar(&c.ValidateJobCapability, "validate-job-capability", true, "Reject jobs whose minResources can never fit "+
+		"the capability of their queue; disable it if the capability of queues is raised dynamically")
+
+	fs.StringVar(&c.JobPolicyURL, "job-policy-url", "", "The URL of an external policy endpoint which jobs are posted to "+
+		"for an allow or deny verdict after the checks of Volcano, the job is denied if either denies it; disabled if empty")
+	fs.DurationVar(&c.JobPolicyTimeout, "job-policy-timeout", defaultPolicyTimeout, "The timeout of requests to --job-policy-url")
+	fs.StringVar(&c.JobPolicyFailurePolicy, "job-policy-failure-policy", string(policy.Fail), "Fail or Ignore, whether "+
+		"jobs are denied or allowed if --job-policy-url is unreachable, times out or answers with an error")
 }
 
 // CheckPortOrDie check valid port range
@@ -25,11 +25,19 @@ import (
 	"strconv"
 	"syscall"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/cmd/admission/app/options"
+	"volcano.sh/volcano/pkg/admission/audit"
+	"volcano.sh/volcano/pkg/admission/policy"
 	"volcano.sh/volcano/pkg/admission/router"
+	informers "volcano.sh/volcano/pkg/client/informers/externalversions"
 	"volcano.sh/volcano/pkg/version"
 )
 
@@ -54,12 +62,71 @@ func Run(config *options.Config) error {
 		return fmt.Errorf("unable to read cacert file (%s): %v", config.CaCertFile, err)
 	}
 
+	var auditSink audit.Sink
+	if config.EnableAudit {
+		if auditSink, err = audit.NewSink(config.AuditLogPath); err != nil {
+			return fmt.Errorf("unable to create audit sink (%s): %v", config.AuditLogPath, err)
+		}
+	}
+
+	var jobPolicy policy.Checker
+	if config.JobPolicyURL != "" {
+		jobPolicy, err = policy.NewHTTPChecker(config.JobPolicyURL, config.JobPolicyTimeout,
+			policy.FailurePolicy(config.JobPolicyFailurePolicy))
+		if err != nil {
+			return fmt.Errorf("unable to create job policy checker (%s): %v", config.JobPolicyURL, err)
+		}
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
 	vClient := getVolcanoClient(restConfig)
 	kubeClient := getKubeClient(restConfig)
+
+	informerFactory := informers.NewSharedInformerFactory(vClient, 0)
+	queueInformer := informerFactory.Scheduling().V1alpha2().Queues()
+	podGroupInformer := informerFactory.Scheduling().V1alpha2().PodGroups()
+	queueLister := queueInformer.Lister()
+	podGroupLister := podGroupInformer.Lister()
+	informerFactory.Start(stopCh)
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	namespaceInformer := kubeInformerFactory.Core().V1().Namespaces()
+	namespaceLister := namespaceInformer.Lister()
+	priorityClassInformer := kubeInformerFactory.Scheduling().V1beta1().PriorityClasses()
+	priorityClassLister := priorityClassInformer.Lister()
+	kubeInformerFactory.Start(stopCh)
+	// Only the pods of the scheduler are cached, which are the pods counted by the quotas of queues.
+	podInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("spec.schedulerName", config.SchedulerName).String()
+		}))
+	podInformer := podInformerFactory.Core().V1().Pods()
+	podLister := podInformer.Lister()
+	podInformerFactory.Start(stopCh)
+	// The webhooks are served at once, and report ready when the caches are synced.
+	hasSynced := func() bool {
+		return queueInformer.Informer().HasSynced() && podGroupInformer.Informer().HasSynced() &&
+			namespaceInformer.Informer().HasSynced() && podInformer.Informer().HasSynced() &&
+			priorityClassInformer.Informer().HasSynced()
+	}
+
 	router.ForEachAdmission(func(service *router.AdmissionService) {
 		if service.Config != nil {
 			service.Config.VolcanoClient = vClient
+			service.Config.KubeClient = kubeClient
 			service.Config.SchedulerName = config.SchedulerName
+			service.Config.AuditSink = auditSink
+			service.Config.SlowThreshold = config.SlowThreshold
+			service.Config.ValidateJobQueue = config.ValidateJobQueue
+			service.Config.ValidateJobCapability = config.ValidateJobCapability
+			service.Config.QueueLister = queueLister
+			service.Config.PodGroupLister = podGroupLister
+			service.Config.NamespaceLister = namespaceLister
+			service.Config.PodLister = podLister
+			service.Config.PriorityClassLister = priorityClassLister
+			service.Config.HasSynced = hasSynced
+			service.Config.JobPolicy = jobPolicy
 		}
 
 		klog.V(3).Infof("Registered '%s' as webhook.", service.Path)
@@ -69,13 +136,20 @@ func Run(config *options.Config) error {
 		registerWebhookConfig(kubeClient, config, service, caBundle)
 	})
 
+	// Metrics and probes are served on the same mux as webhooks.
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	http.HandleFunc("/readyz", readyzHandler)
+
 	webhookServeError := make(chan struct{})
 	stopChannel := make(chan os.Signal)
 	signal.Notify(stopChannel, syscall.SIGTERM, syscall.SIGINT)
 
 	server := &http.Server{
 		Addr:      ":" + strconv.Itoa(config.Port),
-		TLSConfig: configTLS(config, restConfig),
+		TLSConfig: configTLS(config, restConfig, stopCh),
 	}
 	go func() {
 		err = server.ListenAndServeTLS("", "")
@@ -18,7 +18,10 @@ package app
 
 import (
 	"crypto/tls"
+	"fmt"
+	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 
 	"k8s.io/api/admissionregistration/v1beta1"
@@ -102,7 +105,20 @@ func getVolcanoClient(restConfig *rest.Config) *versioned.Clientset {
 // configTLS is a helper function that generate tls certificates from directly defined tls config or kubeconfig
 // These are passed in as command line for cluster certification. If tls config is passed in, we use the directly
 // defined tls config, else use that defined in kubeconfig
-func configTLS(config *options.Config, restConfig *rest.Config) *tls.Config {
+// If tls-cert-reload-interval is set, the certificate files are reloaded when they change.
+func configTLS(config *options.Config, restConfig *rest.Config, stopCh <-chan struct{}) *tls.Config {
+	if len(config.CertFile) != 0 && len(config.KeyFile) != 0 && config.CertReloadInterval > 0 {
+		watcher, err := newCertWatcher(config.CertFile, config.KeyFile)
+		if err != nil {
+			klog.Fatal(err)
+		}
+		go watcher.Run(config.CertReloadInterval, stopCh)
+
+		return &tls.Config{
+			GetCertificate: watcher.GetCertificate,
+		}
+	}
+
 	if len(config.CertFile) != 0 && len(config.KeyFile) != 0 {
 		sCert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
 		if err != nil {
@@ -183,3 +199,22 @@ func webhookConfigName(name, path string) string {
 	raw := strings.Join([]string{name, strings.ReplaceAll(path, "/", "-")}, "-")
 	return re.ReplaceAllString(raw, "-")
 }
+
+// readyzHandler reports ready only if all admission services are ready, so that
+// no admission request is routed to an instance whose caches are not synced.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	var notReady []string
+	router.ForEachAdmission(func(service *router.AdmissionService) {
+		if !service.Ready() {
+			notReady = append(notReady, service.Path)
+		}
+	})
+
+	if len(notReady) != 0 {
+		sort.Strings(notReady)
+		http.Error(w, fmt.Sprintf("admission services are not ready: %s", strings.Join(notReady, ", ")),
+			http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"volcano.sh/volcano/pkg/admission/router"
+)
+
+func TestReadyzHandler(t *testing.T) {
+	synced := false
+	service := &router.AdmissionService{
+		Path:   "/test/readyz",
+		Config: &router.AdmissionServiceConfig{HasSynced: func() bool { return synced }},
+	}
+	if err := router.RegisterAdmission(service); err != nil {
+		t.Fatalf("failed to register admission service: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), service.Path) {
+		t.Errorf("expected not ready with %s, got %d: %s", service.Path, w.Code, w.Body.String())
+	}
+
+	synced = true
+	w = httptest.NewRecorder()
+	readyzHandler(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected ready, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -32,7 +32,12 @@ import (
 
 	_ "volcano.sh/volcano/pkg/admission/jobs/mutate"
 	_ "volcano.sh/volcano/pkg/admission/jobs/validate"
+	_ "volcano.sh/volcano/pkg/admission/podgroups/mutate"
+	_ "volcano.sh/volcano/pkg/admission/podgroups/validate"
 	_ "volcano.sh/volcano/pkg/admission/pods"
+	_ "volcano.sh/volcano/pkg/admission/pods/mutate"
+	_ "volcano.sh/volcano/pkg/admission/queues/mutate"
+	_ "volcano.sh/volcano/pkg/admission/queues/validate"
 )
 
 var logFlushFreq = pflag.Duration("log-flush-frequency", 5*time.Second, "Maximum number of seconds between log flushes")
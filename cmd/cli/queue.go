@@ -58,6 +58,50 @@ func buildQueueCmd() *cobra.Command {
 	queue.InitOperateFlags(queueOperateCmd)
 	queueCmd.AddCommand(queueOperateCmd)
 
+	queueOpenCmd := &cobra.Command{
+		Use:   "open NAME",
+		Short: "open queue and wait until it is open",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.OpenQueue(args[0]))
+		},
+	}
+	queue.InitOpenFlags(queueOpenCmd)
+	queueCmd.AddCommand(queueOpenCmd)
+
+	queueCloseCmd := &cobra.Command{
+		Use:   "close NAME",
+		Short: "close queue and wait until it is closing or closed",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.CloseQueue(args[0]))
+		},
+	}
+	queue.InitCloseFlags(queueCloseCmd)
+	queueCmd.AddCommand(queueCloseCmd)
+
+	queueCordonCmd := &cobra.Command{
+		Use:   "cordon NAME",
+		Short: "cordon queue to reject new jobs, the admitted ones are still scheduled",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.CordonQueue(args[0]))
+		},
+	}
+	queue.InitCordonFlags(queueCordonCmd)
+	queueCmd.AddCommand(queueCordonCmd)
+
+	queueUncordonCmd := &cobra.Command{
+		Use:   "uncordon NAME",
+		Short: "uncordon queue to accept new jobs again",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.UncordonQueue(args[0]))
+		},
+	}
+	queue.InitUncordonFlags(queueUncordonCmd)
+	queueCmd.AddCommand(queueUncordonCmd)
+
 	queueListCmd := &cobra.Command{
 		Use:   "list",
 		Short: "lists all the queue",
@@ -78,5 +122,38 @@ func buildQueueCmd() *cobra.Command {
 	queue.InitGetFlags(queueGetCmd)
 	queueCmd.AddCommand(queueGetCmd)
 
+	queueDescribeCmd := &cobra.Command{
+		Use:   "describe NAME",
+		Short: "describe the state of queue, the transitions it allows and its pending commands",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.DescribeQueue(args[0]))
+		},
+	}
+	queue.InitDescribeFlags(queueDescribeCmd)
+	queueCmd.AddCommand(queueDescribeCmd)
+
+	queueWhatIfCmd := &cobra.Command{
+		Use:   "what-if NAME",
+		Short: "simulate a change of capability or guarantee of queue and show the podgroups it would starve",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.WhatIfQueue(args[0]))
+		},
+	}
+	queue.InitWhatIfFlags(queueWhatIfCmd)
+	queueCmd.AddCommand(queueWhatIfCmd)
+
+	queueRedriveCmd := &cobra.Command{
+		Use:   "redrive NAME",
+		Short: "re-drive the request of queue dropped by vc-controllers after retries",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, queue.RedriveQueue(args[0]))
+		},
+	}
+	queue.InitRedriveFlags(queueRedriveCmd)
+	queueCmd.AddCommand(queueRedriveCmd)
+
 	return queueCmd
 }
@@ -18,8 +18,12 @@ package options
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	"volcano.sh/volcano/pkg/controllers/tracing"
 )
 
 const (
@@ -29,6 +33,16 @@ const (
 	defaultSchedulerName = "volcano"
 
 	defaultHealthzBindAddress = "127.0.0.1:11252"
+	defaultMaxRetries         = 15
+	defaultEventDedupWindow   = time.Minute
+	defaultRetryMaxDelay      = 30 * time.Second
+	defaultQueueWorkers       = 1
+	defaultWeightMinRatio     = 0.5
+	defaultWeightMaxRatio     = 2.0
+	defaultDrainTimeout       = 10 * time.Second
+	defaultSnapshotConfigMap  = "volcano-system/volcano-queue-usage"
+	defaultCommandQueueWait   = time.Minute
+	defaultQueueState         = "Open"
 )
 
 // ServerOption is the main context object for the controller manager.
@@ -47,6 +61,63 @@ type ServerOption struct {
 	// HealthzBindAddress is the IP address and port for the health check server to serve on,
 	// defaulting to 127.0.0.1:11252
 	HealthzBindAddress string
+	// EnablePprof enables pprof handlers on the health check server
+	EnablePprof bool
+	// ListenAddress is the address to serve prometheus metrics on
+	ListenAddress string
+	// QueueMaxRetries is the number of times a queue request is retried before it is dropped,
+	// 0 means retry forever
+	QueueMaxRetries int
+	// CommandMaxRetries is the number of times a queue command is retried before it is dropped,
+	// 0 means retry forever
+	CommandMaxRetries int
+	// CommandBatchSize is the max number of commands targeting the same queue which are
+	// handled together, batching is disabled if it is less than 2
+	CommandBatchSize int
+	// CommandQueueWaitTimeout is how long a command targeting a queue which does not exist yet is retried
+	CommandQueueWaitTimeout time.Duration
+	// EventQPS and EventBurst rate limit the events written by queue controller apart from the other
+	// API calls, the events share the limit of kube-api-qps and kube-api-burst if EventQPS is 0
+	EventQPS   float32
+	EventBurst int
+	// QueueEventDedupWindow is the window in which identical events of a queue are suppressed
+	QueueEventDedupWindow time.Duration
+	// EventStdout writes the events of queue controller to stdout as JSON in addition to apiserver
+	EventStdout bool
+	// ResyncPeriod is the resync period of queues, 0 disables the resync
+	ResyncPeriod time.Duration
+	// RetryMaxDelay is the upper limit of the delay before a failed queue request or command is retried
+	RetryMaxDelay time.Duration
+	// WatchNamespaces are the namespaces whose podgroups are watched by queue controller, empty means all
+	WatchNamespaces []string
+	// StructuredLogging appends key-value pairs to the logs of queue controller
+	StructuredLogging bool
+	// QueueIdleTimeout is the duration after which an idle queue is closed, 0 disables it
+	QueueIdleTimeout time.Duration
+	// QueueWorkerThreads is the number of threads handling queue requests concurrently
+	QueueWorkerThreads int
+	// CommandWorkerThreads is the number of threads handling the commands of every kind concurrently
+	CommandWorkerThreads int
+	// ShutdownDrainTimeout is how long the queued requests and commands are handled after termination signal
+	ShutdownDrainTimeout time.Duration
+	// QueueWeightAdjustPeriod is the interval of adjusting the effective weights of queues, 0 disables it
+	QueueWeightAdjustPeriod time.Duration
+	// QueueWeightMinRatio is the lower bound of the effective weight of a queue relative to its spec.weight
+	QueueWeightMinRatio float64
+	// QueueWeightMaxRatio is the upper bound of the effective weight of a queue relative to its spec.weight
+	QueueWeightMaxRatio float64
+	// QueueUsageSnapshotPeriod is the interval of exporting the usage snapshot of all queues, 0 disables it
+	QueueUsageSnapshotPeriod time.Duration
+	// QueueUsageSnapshotConfigMap is the namespace/name of the ConfigMap the usage snapshot is written to
+	QueueUsageSnapshotConfigMap string
+	// QueuePauseConfigMap is the namespace/name of the ConfigMap whose annotation pauses the reconciliation of queues
+	QueuePauseConfigMap string
+	// OTelEndpoint is the OTLP/HTTP endpoint the spans of queue controller are exported to, tracing is disabled if empty
+	OTelEndpoint string
+	// QueueDeadLetterConfigMap is the namespace/name of the ConfigMap the dropped queue requests are recorded to
+	QueueDeadLetterConfigMap string
+	// QueueDefaultState is the state of the new queues which do not set spec.state, Open or Closed
+	QueueDefaultState string
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -67,8 +138,74 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&s.PrintVersion, "version", false, "Show version and quit")
 	fs.Uint32Var(&s.WorkerThreads, "worker-threads", defaultWorkers, "The number of threads syncing job operations concurrently. "+
 		"Larger number = faster job updating, but more CPU load")
-	fs.StringVar(&s.SchedulerName, "scheduler-name", defaultSchedulerName, "Volcano will handle pods whose .spec.SchedulerName is same as scheduler-name")
-	fs.StringVar(&s.HealthzBindAddress, "healthz-bind-address", defaultHealthzBindAddress, "The address to listen on for /healthz HTTP requests.")
+	fs.StringVar(&s.SchedulerName, "scheduler-name", defaultSchedulerName, "Volcano will handle pods whose .spec.SchedulerName is same as scheduler-name, "+
+		"it is also set on the pods of jobs which do not specify a scheduler")
+	fs.StringVar(&s.HealthzBindAddress, "healthz-bind-address", defaultHealthzBindAddress, "The address to listen on for /healthz, /readyz "+
+		"and /healthz/leader HTTP requests, the health check server is disabled if empty; /readyz reports ready on the "+
+		"leader once its caches are synced and on standby replicas at once, /healthz/leader fails unless leading.")
+	fs.BoolVar(&s.EnablePprof, "enable-pprof", false, "Enable pprof handlers on the health check server.")
+	fs.StringVar(&s.ListenAddress, "listen-address", "", "The address to serve /metrics on, it is also served by the health "+
+		"check server; the metrics server is disabled if empty.")
+	fs.IntVar(&s.QueueMaxRetries, "queue-max-retries", defaultMaxRetries, "The number of times a queue request is retried before it is dropped, 0 means retry forever")
+	fs.IntVar(&s.CommandMaxRetries, "command-max-retries", defaultMaxRetries, "The number of times a queue command is retried before it is dropped, 0 means retry forever")
+	fs.DurationVar(&s.CommandQueueWaitTimeout, "command-queue-wait-timeout", defaultCommandQueueWait, "How long after its "+
+		"creation a command targeting a queue which does not exist yet is kept and retried with backoff, so that a command "+
+		"created slightly before its queue is not lost; a warning event is recorded on the command once it expires, "+
+		"0 drops the command at once")
+	fs.Float32Var(&s.EventQPS, "event-qps", 0, "QPS of writing the events of queue controller to kubernetes apiserver "+
+		"with a client of its own, so that the events are not throttled by the other API calls during incidents; "+
+		"0 writes the events with the client of kube-api-qps and kube-api-burst")
+	fs.IntVar(&s.EventBurst, "event-burst", 0, "Burst of writing the events of queue controller to kubernetes apiserver, "+
+		"it must be positive when event-qps is set")
+	fs.DurationVar(&s.QueueEventDedupWindow, "queue-event-dedup-window", defaultEventDedupWindow, "The window in which identical events "+
+		"of a queue are suppressed, 0 disables the suppression")
+	fs.BoolVar(&s.EventStdout, "event-stdout", false, "Write the events of queue controller to stdout, one JSON object "+
+		"per line with the queue, type, reason and message, in addition to recording them to the Kubernetes API")
+	fs.DurationVar(&s.ResyncPeriod, "resync-period", 0, "The period to resync the informers of queue controller and reconcile "+
+		"all queues, 0 disables the resync")
+	fs.IntVar(&s.CommandBatchSize, "command-batch-size", 0, "The max number of commands targeting the same queue which are coalesced "+
+		"into one request, batching is disabled if it is less than 2")
+	fs.DurationVar(&s.RetryMaxDelay, "queue-retry-max-delay", defaultRetryMaxDelay, "The upper limit of the delay before a failed "+
+		"queue request or command is retried. The n-th retry is delayed 5ms*2^(n-1), i.e. 5ms, 10ms, 20ms, ... 10.2s, 20.4s, 41s, 82s, "+
+		"capped by this value, and then randomly shortened by up to 50% so that the retries of items failed together are spread out; "+
+		"retries of all items are also limited to 10 qps with a burst of 100")
+	fs.StringSliceVar(&s.WatchNamespaces, "watch-namespaces", nil, "Comma separated namespaces whose podgroups are counted "+
+		"in the status of queues, all namespaces are watched if empty; queues are cluster scoped and always watched")
+	fs.BoolVar(&s.StructuredLogging, "structured-logging", false, "Log the requests and commands handled by queue controller "+
+		"with key-value pairs, e.g. msg=\"...\" queue=\"q1\" action=\"SyncQueue\", so that the logs can be filtered by queue, action and event")
+	fs.DurationVar(&s.QueueIdleTimeout, "queue-idle-timeout", 0, "The duration after which an open queue without pending or running "+
+		"podgroups is closed, it is opened again once a podgroup is created in it; 0 disables it")
+	fs.IntVar(&s.QueueWorkerThreads, "queue-worker-threads", defaultQueueWorkers, "The number of threads handling queue "+
+		"requests concurrently, a queue is never handled by two threads at the same time")
+	fs.IntVar(&s.CommandWorkerThreads, "command-worker-threads", defaultQueueWorkers, "The number of threads handling "+
+		"the commands of every kind concurrently, a command is never handled by two threads at the same time")
+	fs.DurationVar(&s.ShutdownDrainTimeout, "shutdown-drain-timeout", defaultDrainTimeout, "How long queue controller "+
+		"keeps handling the queued requests and commands after SIGTERM or SIGINT, while no new item is accepted; "+
+		"0 drops them at once. It must not be longer than the leader election lease of 15s")
+	fs.DurationVar(&s.QueueWeightAdjustPeriod, "queue-weight-adjust-period", 0, "The interval of adjusting the effective weight "+
+		"of queues by their historical usage, so that queues which used less than their share for a while get more weight; "+
+		"spec.weight is never changed and is used by the scheduler when it is 0")
+	fs.Float64Var(&s.QueueWeightMinRatio, "queue-weight-min-ratio", defaultWeightMinRatio, "The lower bound of the effective "+
+		"weight of a queue relative to its spec.weight, between 0 and 1")
+	fs.Float64Var(&s.QueueWeightMaxRatio, "queue-weight-max-ratio", defaultWeightMaxRatio, "The upper bound of the effective "+
+		"weight of a queue relative to its spec.weight, not less than 1")
+	fs.DurationVar(&s.QueueUsageSnapshotPeriod, "queue-usage-snapshot-period", 0, "The interval of exporting the capability, "+
+		"guarantee and usage of all queues as JSON to the ConfigMap of --queue-usage-snapshot-configmap for capacity planning, "+
+		"0 disables it")
+	fs.StringVar(&s.QueueUsageSnapshotConfigMap, "queue-usage-snapshot-configmap", defaultSnapshotConfigMap, "The namespace/name "+
+		"of the ConfigMap the usage snapshot of queues is written to, it is created if it does not exist")
+	fs.StringVar(&s.QueuePauseConfigMap, "queue-pause-configmap", "", "The namespace/name of the ConfigMap whose annotation "+
+		"volcano.sh/queue-reconciliation-paused=true pauses the state transitions of queues, e.g. during maintenance; "+
+		"the queue requests are kept and handled once it is removed. Pausing is disabled if empty")
+	fs.StringVar(&s.OTelEndpoint, "otel-endpoint", "", "The OpenTelemetry collector the spans of handling queue requests and "+
+		"commands are exported to by OTLP/HTTP, e.g. http://otel-collector:4318; tracing is disabled if empty. The exporter "+
+		"is minimal: failed exports are not retried and their spans are dropped, and headers, compression and TLS settings "+
+		"other than the system certificates are not supported, so a local collector is recommended")
+	fs.StringVar(&s.QueueDeadLetterConfigMap, "queue-dead-letter-configmap", "", "The namespace/name of the ConfigMap "+
+		"the queue requests dropped after retries are recorded to, e.g. volcano-system/volcano-queue-dead-letters, so that they "+
+		"can be re-driven by 'vcctl queue redrive'; it is created if it does not exist. The requests are only logged if empty")
+	fs.StringVar(&s.QueueDefaultState, "queue-default-state", defaultQueueState, "The state of the new queues which do not "+
+		"set spec.state, Open or Closed; Closed queues accept no podgroups until they are opened explicitly, e.g. by 'vcctl queue open'")
 }
 
 // CheckOptionOrDie checks the LockObjectNamespace
@@ -76,5 +213,73 @@ func (s *ServerOption) CheckOptionOrDie() error {
 	if s.EnableLeaderElection && s.LockObjectNamespace == "" {
 		return fmt.Errorf("lock-object-namespace must not be nil when LeaderElection is enabled")
 	}
+	if s.QueueMaxRetries < 0 || s.CommandMaxRetries < 0 {
+		return fmt.Errorf("queue-max-retries and command-max-retries must not be negative")
+	}
+	if s.CommandQueueWaitTimeout < 0 {
+		return fmt.Errorf("command-queue-wait-timeout must not be negative")
+	}
+	if s.QueueEventDedupWindow < 0 {
+		return fmt.Errorf("queue-event-dedup-window must not be negative")
+	}
+	if s.RetryMaxDelay <= 0 {
+		return fmt.Errorf("queue-retry-max-delay must be positive")
+	}
+	if s.ResyncPeriod < 0 {
+		return fmt.Errorf("resync-period must not be negative")
+	}
+	if s.QueueIdleTimeout < 0 {
+		return fmt.Errorf("queue-idle-timeout must not be negative")
+	}
+	if s.ShutdownDrainTimeout < 0 {
+		return fmt.Errorf("shutdown-drain-timeout must not be negative")
+	}
+	if s.QueueWeightAdjustPeriod < 0 {
+		return fmt.Errorf("queue-weight-adjust-period must not be negative")
+	}
+	if s.QueueWeightMinRatio <= 0 || s.QueueWeightMinRatio > 1 || s.QueueWeightMaxRatio < 1 {
+		return fmt.Errorf("queue-weight-min-ratio must be in (0, 1] and queue-weight-max-ratio must not be less than 1")
+	}
+	if s.QueueUsageSnapshotPeriod < 0 {
+		return fmt.Errorf("queue-usage-snapshot-period must not be negative")
+	}
+	if s.QueueUsageSnapshotPeriod > 0 {
+		if parts := strings.Split(s.QueueUsageSnapshotConfigMap, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("queue-usage-snapshot-configmap must be namespace/name, got %q", s.QueueUsageSnapshotConfigMap)
+		}
+	}
+	if s.QueuePauseConfigMap != "" {
+		if parts := strings.Split(s.QueuePauseConfigMap, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("queue-pause-configmap must be namespace/name, got %q", s.QueuePauseConfigMap)
+		}
+	}
+	if s.QueueDeadLetterConfigMap != "" {
+		if parts := strings.Split(s.QueueDeadLetterConfigMap, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("queue-dead-letter-configmap must be namespace/name, got %q", s.QueueDeadLetterConfigMap)
+		}
+	}
+	if s.QueueDefaultState != defaultQueueState && s.QueueDefaultState != "Closed" {
+		return fmt.Errorf("queue-default-state must be Open or Closed, got %q", s.QueueDefaultState)
+	}
+	if s.OTelEndpoint != "" {
+		if _, err := tracing.NewTracer(s.OTelEndpoint, ""); err != nil {
+			return err
+		}
+	}
+	if s.QueueWorkerThreads < 1 || s.CommandWorkerThreads < 1 {
+		return fmt.Errorf("queue-worker-threads and command-worker-threads must be positive")
+	}
+	if s.KubeAPIQPS < 0 {
+		return fmt.Errorf("kube-api-qps must not be negative")
+	}
+	if s.KubeAPIQPS > 0 && s.KubeAPIBurst <= 0 {
+		return fmt.Errorf("kube-api-burst must be positive when kube-api-qps is set")
+	}
+	if s.EventQPS < 0 {
+		return fmt.Errorf("event-qps must not be negative")
+	}
+	if s.EventQPS > 0 && s.EventBurst <= 0 {
+		return fmt.Errorf("event-burst must be positive when event-qps is set")
+	}
 	return nil
 }
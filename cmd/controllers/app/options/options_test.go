@@ -19,6 +19,7 @@ package options
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -31,18 +32,33 @@ func TestAddFlags(t *testing.T) {
 	args := []string{
 		"--master=127.0.0.1",
 		"--kube-api-burst=200",
+		"--watch-namespaces=ns1,ns2",
 	}
 	fs.Parse(args)
 
 	// This is a snapshot of expected options parsed by args.
 	expected := &ServerOption{
-		Master:             "127.0.0.1",
-		KubeAPIQPS:         defaultQPS,
-		KubeAPIBurst:       200,
-		PrintVersion:       false,
-		WorkerThreads:      defaultWorkers,
-		SchedulerName:      defaultSchedulerName,
-		HealthzBindAddress: "127.0.0.1:11252",
+		Master:                "127.0.0.1",
+		KubeAPIQPS:            defaultQPS,
+		KubeAPIBurst:          200,
+		PrintVersion:          false,
+		WorkerThreads:         defaultWorkers,
+		SchedulerName:         defaultSchedulerName,
+		HealthzBindAddress:    "127.0.0.1:11252",
+		QueueMaxRetries:       defaultMaxRetries,
+		CommandMaxRetries:     defaultMaxRetries,
+		QueueEventDedupWindow: defaultEventDedupWindow,
+		RetryMaxDelay:         defaultRetryMaxDelay,
+		WatchNamespaces:       []string{"ns1", "ns2"},
+		QueueWorkerThreads:    defaultQueueWorkers,
+		CommandWorkerThreads:  defaultQueueWorkers,
+		ShutdownDrainTimeout:  defaultDrainTimeout,
+		QueueWeightMinRatio:   defaultWeightMinRatio,
+		QueueWeightMaxRatio:   defaultWeightMaxRatio,
+
+		QueueUsageSnapshotConfigMap: defaultSnapshotConfigMap,
+		CommandQueueWaitTimeout:     defaultCommandQueueWait,
+		QueueDefaultState:           defaultQueueState,
 	}
 
 	if !reflect.DeepEqual(expected, s) {
@@ -53,5 +69,367 @@ func TestAddFlags(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected nil but got %v\n", err)
 	}
+}
+
+func TestCheckKubeAPIRateLimit(t *testing.T) {
+	testCases := []struct {
+		name      string
+		qps       float32
+		burst     int
+		expectErr bool
+	}{
+		{
+			name:  "defaults",
+			qps:   defaultQPS,
+			burst: defaultBurst,
+		},
+		{
+			name:  "rate limiter disabled",
+			qps:   0,
+			burst: 0,
+		},
+		{
+			name:      "negative qps",
+			qps:       -1,
+			burst:     defaultBurst,
+			expectErr: true,
+		},
+		{
+			name:      "qps without burst",
+			qps:       defaultQPS,
+			burst:     0,
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("ratelimittest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
+
+		s.KubeAPIQPS = testCase.qps
+		s.KubeAPIBurst = testCase.burst
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckEventRateLimit(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		expectErr bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "events rate limited apart",
+			args: []string{"--event-qps=20", "--event-burst=50"},
+		},
+		{
+			name:      "negative qps",
+			args:      []string{"--event-qps=-1"},
+			expectErr: true,
+		},
+		{
+			name:      "qps without burst",
+			args:      []string{"--event-qps=20"},
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("eventratelimittest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		if err := fs.Parse(testCase.args); err != nil {
+			t.Fatalf("case %s: failed to parse flags: %v", testCase.name, err)
+		}
+
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckWorkerThreads(t *testing.T) {
+	testCases := []struct {
+		name           string
+		queueWorkers   int
+		commandWorkers int
+		expectErr      bool
+	}{
+		{
+			name:           "defaults",
+			queueWorkers:   defaultQueueWorkers,
+			commandWorkers: defaultQueueWorkers,
+		},
+		{
+			name:           "concurrent workers",
+			queueWorkers:   8,
+			commandWorkers: 4,
+		},
+		{
+			name:           "no queue worker",
+			queueWorkers:   0,
+			commandWorkers: defaultQueueWorkers,
+			expectErr:      true,
+		},
+		{
+			name:           "negative command workers",
+			queueWorkers:   defaultQueueWorkers,
+			commandWorkers: -1,
+			expectErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("workerthreadstest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
+
+		s.QueueWorkerThreads = testCase.queueWorkers
+		s.CommandWorkerThreads = testCase.commandWorkers
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckQueueWeight(t *testing.T) {
+	testCases := []struct {
+		name      string
+		args      []string
+		expectErr bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "dynamic weight enabled",
+			args: []string{"--queue-weight-adjust-period=1m", "--queue-weight-min-ratio=1", "--queue-weight-max-ratio=4"},
+		},
+		{
+			name:      "negative period",
+			args:      []string{"--queue-weight-adjust-period=-1m"},
+			expectErr: true,
+		},
+		{
+			name:      "zero min ratio",
+			args:      []string{"--queue-weight-min-ratio=0"},
+			expectErr: true,
+		},
+		{
+			name:      "min ratio greater than 1",
+			args:      []string{"--queue-weight-min-ratio=1.5"},
+			expectErr: true,
+		},
+		{
+			name:      "max ratio less than 1",
+			args:      []string{"--queue-weight-max-ratio=0.8"},
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("queueweighttest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		if err := fs.Parse(testCase.args); err != nil {
+			t.Fatalf("case %s: failed to parse flags: %v", testCase.name, err)
+		}
+
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckQueueUsageSnapshot(t *testing.T) {
+	testCases := []struct {
+		name      string
+		period    time.Duration
+		configMap string
+		expectErr bool
+	}{
+		{
+			name:      "disabled",
+			configMap: "",
+		},
+		{
+			name:      "enabled",
+			period:    time.Minute,
+			configMap: defaultSnapshotConfigMap,
+		},
+		{
+			name:      "negative period",
+			period:    -time.Minute,
+			configMap: defaultSnapshotConfigMap,
+			expectErr: true,
+		},
+		{
+			name:      "configmap without namespace",
+			period:    time.Minute,
+			configMap: "volcano-queue-usage",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("snapshottest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
+
+		s.QueueUsageSnapshotPeriod = testCase.period
+		s.QueueUsageSnapshotConfigMap = testCase.configMap
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckQueuePauseConfigMap(t *testing.T) {
+	testCases := []struct {
+		name      string
+		configMap string
+		expectErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:      "enabled",
+			configMap: "volcano-system/volcano-queue-pause",
+		},
+		{
+			name:      "configmap without namespace",
+			configMap: "volcano-queue-pause",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("pausetest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
+
+		s.QueuePauseConfigMap = testCase.configMap
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckQueueDeadLetterConfigMap(t *testing.T) {
+	testCases := []struct {
+		name      string
+		configMap string
+		expectErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:      "enabled",
+			configMap: "volcano-system/volcano-queue-dead-letters",
+		},
+		{
+			name:      "configmap without name",
+			configMap: "volcano-system/",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("deadlettertest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
 
+		s.QueueDeadLetterConfigMap = testCase.configMap
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckQueueDefaultState(t *testing.T) {
+	testCases := []struct {
+		name      string
+		state     string
+		expectErr bool
+	}{
+		{
+			name:  "open",
+			state: "Open",
+		},
+		{
+			name:  "closed",
+			state: "Closed",
+		},
+		{
+			name:      "closing",
+			state:     "Closing",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("defaultstatetest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse([]string{"--queue-default-state=" + testCase.state})
+
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
+}
+
+func TestCheckOTelEndpoint(t *testing.T) {
+	testCases := []struct {
+		name      string
+		endpoint  string
+		expectErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:     "collector",
+			endpoint: "http://otel-collector:4318",
+		},
+		{
+			name:      "grpc endpoint",
+			endpoint:  "otel-collector:4317",
+			expectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fs := pflag.NewFlagSet("oteltest", pflag.ContinueOnError)
+		s := NewServerOption()
+		s.AddFlags(fs)
+		fs.Parse(nil)
+
+		s.OTelEndpoint = testCase.endpoint
+		err := s.CheckOptionOrDie()
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("case %s: expected error %v, but got %v", testCase.name, testCase.expectErr, err)
+		}
+	}
 }
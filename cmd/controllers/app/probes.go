@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// probes serves the readiness and leadership probes of the controllers. Only the leader runs
+// the controllers, so a standby replica reports ready without synced caches, and the
+// leadership is probed apart by /healthz/leader.
+type probes struct {
+	leading int32
+	synced  func() bool
+}
+
+func newProbes(synced func() bool) *probes {
+	return &probes{synced: synced}
+}
+
+// setLeading records whether this replica is the leader, it is always the leader if leader
+// election is disabled.
+func (p *probes) setLeading(leading bool) {
+	var value int32
+	if leading {
+		value = 1
+	}
+	atomic.StoreInt32(&p.leading, value)
+}
+
+func (p *probes) isLeading() bool {
+	return atomic.LoadInt32(&p.leading) == 1
+}
+
+// handlers returns the probe handlers by their paths.
+func (p *probes) handlers() map[string]http.Handler {
+	return map[string]http.Handler{
+		"/readyz":         http.HandlerFunc(p.readyz),
+		"/healthz/leader": http.HandlerFunc(p.leader),
+	}
+}
+
+// readyz reports ready on the leader once the caches are synced, and on a standby replica
+// at once, so that replicas behind a Service do not flap while waiting for the lease.
+func (p *probes) readyz(w http.ResponseWriter, r *http.Request) {
+	if !p.isLeading() {
+		fmt.Fprint(w, "ready but not leading")
+		return
+	}
+	if !p.synced() {
+		http.Error(w, "caches of queue controller are not synced", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprint(w, "ok")
+}
+
+// leader reports whether this replica is the leader.
+func (p *probes) leader(w http.ResponseWriter, r *http.Request) {
+	if !p.isLeading() {
+		http.Error(w, "not leading", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprint(w, "leading")
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProbes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		leading      bool
+		synced       bool
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "standby ready without synced caches",
+			path:         "/readyz",
+			expectedCode: http.StatusOK,
+			expectedBody: "ready but not leading",
+		},
+		{
+			name:         "leader not ready until synced",
+			leading:      true,
+			path:         "/readyz",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: "not synced",
+		},
+		{
+			name:         "leader ready once synced",
+			leading:      true,
+			synced:       true,
+			path:         "/readyz",
+			expectedCode: http.StatusOK,
+			expectedBody: "ok",
+		},
+		{
+			name:         "standby not leading",
+			synced:       true,
+			path:         "/healthz/leader",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: "not leading",
+		},
+		{
+			name:         "leader leading",
+			leading:      true,
+			path:         "/healthz/leader",
+			expectedCode: http.StatusOK,
+			expectedBody: "leading",
+		},
+	}
+
+	for _, testCase := range testCases {
+		synced := testCase.synced
+		p := newProbes(func() bool { return synced })
+		p.setLeading(testCase.leading)
+
+		recorder := httptest.NewRecorder()
+		p.handlers()[testCase.path].ServeHTTP(recorder, httptest.NewRequest("GET", testCase.path, nil))
+
+		if recorder.Code != testCase.expectedCode {
+			t.Errorf("case %s: expected code %d, got %d", testCase.name, testCase.expectedCode, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), testCase.expectedBody) {
+			t.Errorf("case %s: expected body %q, got %q", testCase.name, testCase.expectedBody, recorder.Body.String())
+		}
+	}
+}
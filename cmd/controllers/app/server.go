@@ -19,9 +19,14 @@ package app
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/klog"
 
 	"k8s.io/api/core/v1"
@@ -41,17 +46,25 @@ import (
 
 	"volcano.sh/volcano/cmd/controllers/app/options"
 	"volcano.sh/volcano/pkg/apis/helpers"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclientset "volcano.sh/volcano/pkg/client/clientset/versioned"
+	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
 	"volcano.sh/volcano/pkg/controllers/garbagecollector"
 	"volcano.sh/volcano/pkg/controllers/job"
+	"volcano.sh/volcano/pkg/controllers/jobflow"
 	"volcano.sh/volcano/pkg/controllers/podgroup"
 	"volcano.sh/volcano/pkg/controllers/queue"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 )
 
 const (
 	leaseDuration = 15 * time.Second
 	renewDeadline = 10 * time.Second
 	retryPeriod   = 5 * time.Second
+
+	// queueRequestTimeout bounds the requests of queue controller, which are abandoned but not
+	// aborted once the drain of the controller times out.
+	queueRequestTimeout = 30 * time.Second
 )
 
 func buildConfig(opt *options.ServerOption) (*rest.Config, error) {
@@ -76,20 +89,48 @@ func buildConfig(opt *options.ServerOption) (*rest.Config, error) {
 
 //Run the controller
 func Run(opt *options.ServerOption) error {
+	// The lease is kept while draining, another controller could take over once it expired.
+	if opt.EnableLeaderElection && opt.ShutdownDrainTimeout > leaseDuration {
+		return fmt.Errorf("shutdown-drain-timeout %v must not be longer than the leader election lease duration %v",
+			opt.ShutdownDrainTimeout, leaseDuration)
+	}
+
 	config, err := buildConfig(opt)
 	if err != nil {
 		return err
 	}
 
-	if err := helpers.StartHealthz(opt.HealthzBindAddress, "volcano-controller"); err != nil {
-		return err
+	if opt.ListenAddress != "" {
+		if err := startMetricsServer(opt.ListenAddress); err != nil {
+			return err
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("unable to get hostname: %v", err)
+	}
+	// add a uniquifier so that two processes on the same host don't accidentally both become active
+	id := hostname + "_" + string(uuid.NewUUID())
+
+	run, synced := startControllers(config, opt, id)
+	probes := newProbes(synced)
+
+	if opt.HealthzBindAddress != "" {
+		handlers := probes.handlers()
+		handlers["/metrics"] = promhttp.Handler()
+		if err := helpers.StartHealthzWithOptions(opt.HealthzBindAddress, "volcano-controller", opt.EnablePprof,
+			handlers); err != nil {
+			return err
+		}
 	}
 
-	run := startControllers(config, opt)
+	ctx := signalContext()
 
 	if !opt.EnableLeaderElection {
-		run(context.TODO())
-		return fmt.Errorf("finished without leader elect")
+		probes.setLeading(true)
+		run(ctx)
+		return nil
 	}
 
 	leaderElectionClient, err := kubeclientset.NewForConfig(rest.AddUserAgent(config, "leader-election"))
@@ -102,13 +143,6 @@ func Run(opt *options.ServerOption) error {
 	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: leaderElectionClient.CoreV1().Events(opt.LockObjectNamespace)})
 	eventRecorder := broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "vc-controllers"})
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		return fmt.Errorf("unable to get hostname: %v", err)
-	}
-	// add a uniquifier so that two processes on the same host don't accidentally both become active
-	id := hostname + "_" + string(uuid.NewUUID())
-
 	rl, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
 		opt.LockObjectNamespace,
 		"vc-controllers",
@@ -121,38 +155,155 @@ func Run(opt *options.ServerOption) error {
 		return fmt.Errorf("couldn't create resource lock: %v", err)
 	}
 
-	leaderelection.RunOrDie(context.TODO(), leaderelection.LeaderElectionConfig{
+	stopped := make(chan struct{})
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
 		Lock:          rl,
 		LeaseDuration: leaseDuration,
 		RenewDeadline: renewDeadline,
 		RetryPeriod:   retryPeriod,
 		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: run,
+			OnStartedLeading: func(ctx context.Context) {
+				probes.setLeading(true)
+				defer close(stopped)
+				run(ctx)
+			},
 			OnStoppedLeading: func() {
-				klog.Fatalf("leaderelection lost")
+				// Leadership is given up on termination, after the controllers are drained.
+				if ctx.Err() == nil {
+					klog.Fatalf("leaderelection lost")
+				}
 			},
 		},
 	})
-	return fmt.Errorf("lost lease")
+	if ctx.Err() == nil {
+		return fmt.Errorf("lost lease")
+	}
+	if probes.isLeading() {
+		<-stopped
+	}
+	return nil
+}
+
+// startMetricsServer serves the prometheus metrics on a mux of its own, so that the handlers which
+// libraries register to http.DefaultServeMux are not exposed.
+func startMetricsServer(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s for metrics: %v", address, err)
+	}
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		if err := http.Serve(listener, metricsMux); err != nil {
+			klog.Errorf("Metrics server failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// signalContext returns a context which is cancelled on SIGTERM or SIGINT, so that the
+// controllers are stopped gracefully; the process exits at once on the second signal.
+func signalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-signals
+		klog.Infof("Received termination signal, stopping controllers.")
+		cancel()
+		<-signals
+		klog.Errorf("Received termination signal again, exiting.")
+		os.Exit(1)
+	}()
+
+	return ctx
 }
 
-func startControllers(config *rest.Config, opt *options.ServerOption) func(ctx context.Context) {
+func startControllers(config *rest.Config, opt *options.ServerOption, id string) (func(ctx context.Context), func() bool) {
 	// TODO: add user agent for different controllers
 	kubeClient := kubeclientset.NewForConfigOrDie(config)
 	vcClient := vcclientset.NewForConfigOrDie(config)
 
 	sharedInformers := informers.NewSharedInformerFactory(kubeClient, 0)
+	vcInformers := informerfactory.NewSharedInformerFactory(vcClient, 0)
+
+	// Events of queue controller are written by a client of their own if they are rate limited apart.
+	var eventClient kubeclientset.Interface
+	if opt.EventQPS > 0 {
+		eventConfig := rest.CopyConfig(config)
+		eventConfig.QPS = opt.EventQPS
+		eventConfig.Burst = opt.EventBurst
+		eventClient = kubeclientset.NewForConfigOrDie(rest.AddUserAgent(eventConfig, "queue-controller-events"))
+	}
+
+	requestConfig := rest.CopyConfig(config)
+	requestConfig.Timeout = queueRequestTimeout
+	requestClient := vcclientset.NewForConfigOrDie(rest.AddUserAgent(requestConfig, "queue-controller"))
+
+	// The endpoint is validated with the options, the tracer is nil if tracing is disabled.
+	var tracer *tracing.Tracer
+	if opt.OTelEndpoint != "" {
+		var err error
+		if tracer, err = tracing.NewTracer(opt.OTelEndpoint, "vc-controllers"); err != nil {
+			klog.Errorf("Failed to create tracer, tracing is disabled: %v", err)
+		}
+	}
 
-	jobController := job.NewJobController(kubeClient, vcClient, sharedInformers, opt.WorkerThreads)
-	queueController := queue.NewQueueController(kubeClient, vcClient)
+	jobController := job.NewJobController(kubeClient, vcClient, sharedInformers, opt.WorkerThreads, opt.SchedulerName)
+	queueController := queue.NewQueueController(kubeClient, vcClient, queue.Options{
+		QueueMaxRetries:   opt.QueueMaxRetries,
+		CommandMaxRetries: opt.CommandMaxRetries,
+		CommandBatchSize:  opt.CommandBatchSize,
+		EventDedupWindow:  opt.QueueEventDedupWindow,
+		EventStdout:       opt.EventStdout,
+		EventClient:       eventClient,
+		RequestClient:     requestClient,
+		ResyncPeriod:      opt.ResyncPeriod,
+		RetryMaxDelay:     opt.RetryMaxDelay,
+		WatchNamespaces:   opt.WatchNamespaces,
+		StructuredLogging: opt.StructuredLogging,
+		IdleTimeout:       opt.QueueIdleTimeout,
+		QueueWorkers:      opt.QueueWorkerThreads,
+		CommandWorkers:    opt.CommandWorkerThreads,
+
+		ShutdownDrainTimeout:    opt.ShutdownDrainTimeout,
+		CommandQueueWaitTimeout: opt.CommandQueueWaitTimeout,
+
+		WeightAdjustPeriod: opt.QueueWeightAdjustPeriod,
+		WeightMinRatio:     opt.QueueWeightMinRatio,
+		WeightMaxRatio:     opt.QueueWeightMaxRatio,
+
+		UsageSnapshotPeriod:    opt.QueueUsageSnapshotPeriod,
+		UsageSnapshotConfigMap: opt.QueueUsageSnapshotConfigMap,
+		Identity:               id,
+		PauseConfigMap:         opt.QueuePauseConfigMap,
+		DeadLetterConfigMap:    opt.QueueDeadLetterConfigMap,
+		DefaultState:           schedulingv1alpha2.QueueState(opt.QueueDefaultState),
+		Tracer:                 tracer,
+	})
 	garbageCollector := garbagecollector.NewGarbageCollector(vcClient)
 	pgController := podgroup.NewPodgroupController(kubeClient, vcClient, sharedInformers, opt.SchedulerName)
+	jobFlowController := jobflow.NewJobFlowController(kubeClient, vcClient, vcInformers)
 
-	return func(ctx context.Context) {
+	run := func(ctx context.Context) {
+		queueStopped := make(chan struct{})
 		go jobController.Run(ctx.Done())
-		go queueController.Run(ctx.Done())
+		go func() {
+			defer close(queueStopped)
+			queueController.Run(ctx.Done())
+		}()
 		go garbageCollector.Run(ctx.Done())
 		go pgController.Run(ctx.Done())
+		go jobFlowController.Run(ctx.Done())
+		go tracer.Run(ctx.Done())
 		<-ctx.Done()
+		// Queue controller returns once its workqueues are drained.
+		<-queueStopped
+		tracer.Flush()
 	}
+
+	return run, queueController.Ready
 }
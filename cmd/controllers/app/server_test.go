@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"volcano.sh/volcano/cmd/controllers/app/options"
+)
+
+func TestRunRejectsDrainTimeoutOverLease(t *testing.T) {
+	opt := options.NewServerOption()
+	opt.EnableLeaderElection = true
+	opt.ShutdownDrainTimeout = leaseDuration + time.Second
+
+	err := Run(opt)
+	if err == nil || !strings.Contains(err.Error(), "shutdown-drain-timeout") {
+		t.Errorf("expected shutdown-drain-timeout to be rejected, got %v", err)
+	}
+}
@@ -58,6 +58,8 @@ type ServerOption struct {
 	// HealthzBindAddress is the IP address and port for the health check server to serve on
 	// defaulting to 127.0.0.1:11251
 	HealthzBindAddress string
+	// EnableOrderDump serves the ordering of sessions at /debug/ordering of the listen address
+	EnableOrderDump bool
 
 	// Parameters for scheduling tuning: the number of feasible nodes to find and score
 	MinNodesToFind             int32
@@ -94,6 +96,8 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.Float32Var(&s.KubeAPIQPS, "kube-api-qps", defaultQPS, "QPS to use while talking with kubernetes apiserver")
 	fs.IntVar(&s.KubeAPIBurst, "kube-api-burst", defaultBurst, "Burst to use while talking with kubernetes apiserver")
 	fs.StringVar(&s.HealthzBindAddress, "healthz-bind-address", defaultHealthzBindAddress, "The address to listen on for /healthz HTTP requests.")
+	fs.BoolVar(&s.EnableOrderDump, "enable-order-dump", false, "Serve the queue, job and task ordering and queue shares of the next "+
+		"session for a podgroup at /debug/ordering?podgroup=<namespace>/<name> of the listen address, for debugging")
 
 	// Minimum number of feasible nodes to find and score
 	fs.Int32Var(&s.MinNodesToFind, "minimum-feasible-nodes", defaultMinNodesToFind, "The minimum number of feasible nodes to find and score")
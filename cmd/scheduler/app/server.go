@@ -95,6 +95,9 @@ func Run(opt *options.ServerOption) error {
 
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
+		if opt.EnableOrderDump {
+			http.Handle("/debug/ordering", sched.OrderDumpHandler())
+		}
 		klog.Fatalf("Prometheus Http Server failed %s", http.ListenAndServe(opt.ListenAddress, nil))
 	}()
 
@@ -69,6 +69,7 @@ ${HELM_BIN_DIR}/helm template ${VK_ROOT}/installer/helm/chart/volcano --namespac
       --name volcano --set basic.image_tag_version=${VOLCANO_IMAGE_TAG} \
       -x templates/admission.yaml \
       -x templates/batch_v1alpha1_job.yaml \
+      -x templates/batch_v1alpha1_jobflow.yaml \
       -x templates/bus_v1alpha1_command.yaml \
       -x templates/controllers.yaml \
       -x templates/scheduler.yaml \
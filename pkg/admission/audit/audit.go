@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/api/admission/v1beta1"
+	"k8s.io/klog"
+)
+
+const (
+	// DecisionAllowed is recorded when the request is admitted.
+	DecisionAllowed = "allowed"
+	// DecisionRejected is recorded when the request is rejected.
+	DecisionRejected = "rejected"
+)
+
+// Record is one audit entry of an admission decision.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Webhook   string    `json:"webhook"`
+	UID       string    `json:"uid"`
+	Operation string    `json:"operation"`
+	User      string    `json:"user"`
+	Groups    []string  `json:"groups,omitempty"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Queue     string    `json:"queue"`
+	Decision  string    `json:"decision"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// Sink is the destination of audit records.
+type Sink interface {
+	Write(record *Record) error
+}
+
+// jsonSink writes one JSON document per line.
+type jsonSink struct {
+	sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONSink returns a sink writing records as JSON lines to w.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonSink) Write(record *Record) error {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.encoder.Encode(record)
+}
+
+// NewSink creates the sink for the given path; stdout is used if path is empty or "-".
+func NewSink(path string) (Sink, error) {
+	if path == "" || path == "-" {
+		return NewJSONSink(os.Stdout), nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJSONSink(f), nil
+}
+
+// Log records the decision of the admission review in sink; it does nothing if sink is nil.
+func Log(sink Sink, webhook string, ar v1beta1.AdmissionReview, queue string, resp *v1beta1.AdmissionResponse) {
+	if sink == nil || ar.Request == nil || resp == nil {
+		return
+	}
+
+	record := &Record{
+		Timestamp: time.Now(),
+		Webhook:   webhook,
+		UID:       string(ar.Request.UID),
+		Operation: string(ar.Request.Operation),
+		User:      ar.Request.UserInfo.Username,
+		Groups:    ar.Request.UserInfo.Groups,
+		Kind:      ar.Request.Kind.Kind,
+		Namespace: ar.Request.Namespace,
+		Name:      ar.Request.Name,
+		Queue:     queue,
+		Decision:  DecisionAllowed,
+	}
+	if !resp.Allowed {
+		record.Decision = DecisionRejected
+	}
+	if resp.Result != nil {
+		record.Reason = resp.Result.Message
+	}
+
+	if err := sink.Write(record); err != nil {
+		klog.Errorf("Failed to write audit record of <%s/%s>: %v", record.Namespace, record.Name, err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLog(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Response *v1beta1.AdmissionResponse
+		Decision string
+		Reason   string
+	}{
+		{
+			Name:     "allowed request",
+			Response: &v1beta1.AdmissionResponse{Allowed: true},
+			Decision: DecisionAllowed,
+		},
+		{
+			Name: "rejected request",
+			Response: &v1beta1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Message: "unable to find job queue"},
+			},
+			Decision: DecisionRejected,
+			Reason:   "unable to find job queue",
+		},
+	}
+
+	for _, testCase := range testCases {
+		buf := &bytes.Buffer{}
+		ar := v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Operation: v1beta1.Create,
+				Kind:      metav1.GroupVersionKind{Kind: "Job"},
+				Namespace: "default",
+				Name:      "job1",
+				UserInfo: authenticationv1.UserInfo{
+					Username: "alice",
+				},
+			},
+		}
+
+		Log(NewJSONSink(buf), "/jobs/validate", ar, "q1", testCase.Response)
+
+		record := Record{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("case %s: failed to decode audit record: %v", testCase.Name, err)
+		}
+		if record.User != "alice" || record.Queue != "q1" || record.Name != "job1" {
+			t.Errorf("case %s: unexpected audit record %v", testCase.Name, record)
+		}
+		if record.Decision != testCase.Decision {
+			t.Errorf("case %s: expected decision %s, got %s", testCase.Name, testCase.Decision, record.Decision)
+		}
+		if record.Reason != testCase.Reason {
+			t.Errorf("case %s: expected reason %s, got %s", testCase.Name, testCase.Reason, record.Reason)
+		}
+	}
+
+	// nil sink means audit is disabled.
+	Log(nil, "/jobs/validate", v1beta1.AdmissionReview{}, "q1", &v1beta1.AdmissionResponse{})
+}
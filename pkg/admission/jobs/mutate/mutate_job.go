@@ -17,12 +17,14 @@ limitations under the License.
 package mutate
 
 import (
-	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"k8s.io/api/admission/v1beta1"
 	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 
@@ -33,8 +35,9 @@ import (
 )
 
 const (
-	//DefaultQueue constant stores the name of the queue as "default"
-	DefaultQueue = "default"
+	//DefaultQueue constant stores the name of the queue as "default", it is used if the namespace
+	//of the job does not set its default queue
+	DefaultQueue = util.DefaultQueue
 )
 
 func init() {
@@ -45,6 +48,8 @@ var service = &router.AdmissionService{
 	Path: "/jobs/mutate",
 	Func: MutateJobs,
 
+	Config: config,
+
 	MutatingConfig: &whv1beta1.MutatingWebhookConfiguration{
 		Webhooks: []whv1beta1.Webhook{{
 			Name: "mutatejob.volcano.sh",
@@ -62,11 +67,9 @@ var service = &router.AdmissionService{
 	},
 }
 
-type patchOperation struct {
-	Op    string      `json:"op"`
-	Path  string      `json:"path"`
-	Value interface{} `json:"value,omitempty"`
-}
+var config = &router.AdmissionServiceConfig{}
+
+type patchOperation = util.PatchOperation
 
 // MutateJobs mutate jobs
 func MutateJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
@@ -80,10 +83,25 @@ func MutateJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 	reviewResponse := v1beta1.AdmissionResponse{}
 	reviewResponse.Allowed = true
 
-	var patchBytes []byte
 	switch ar.Request.Operation {
 	case v1beta1.Create:
-		patchBytes, err = createPatch(job)
+		// The priority class of the job is set on its pods, which are rejected if it is missing.
+		if msg, err := checkPriorityClasses(job); err != nil {
+			return util.ToInternalErrorResponse(err)
+		} else if msg != "" {
+			return util.ToDeniedResponse(msg)
+		}
+
+		// The requests in percentages of the queue capability are resolved against the queue at admission.
+		var requests []patchOperation
+		var msg string
+		if requests, msg, err = patchResourcePercentages(job, "/spec/tasks"); err != nil {
+			return util.ToInternalErrorResponse(err)
+		} else if msg != "" {
+			return util.ToDeniedResponse(strings.TrimSpace(msg))
+		}
+
+		err = createPatch(job, requests).WriteResponse(&reviewResponse)
 		break
 	default:
 		err = fmt.Errorf("expect operation to be 'CREATE' ")
@@ -94,35 +112,101 @@ func MutateJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 		reviewResponse.Result = &metav1.Status{Message: err.Error()}
 		return &reviewResponse
 	}
-	klog.V(3).Infof("AdmissionResponse: patch=%v\n", string(patchBytes))
-	reviewResponse.Patch = patchBytes
-	pt := v1beta1.PatchTypeJSONPatch
-	reviewResponse.PatchType = &pt
+	klog.V(3).Infof("AdmissionResponse: patch=%v\n", string(reviewResponse.Patch))
 
 	return &reviewResponse
 }
 
-func createPatch(job *v1alpha1.Job) ([]byte, error) {
-	var patch []patchOperation
-	pathQueue := patchDefaultQueue(job)
-	if pathQueue != nil {
-		patch = append(patch, *pathQueue)
+func createPatch(job *v1alpha1.Job, requests []patchOperation) *util.PatchBuilder {
+	patch := util.NewJSONPatchBuilder()
+	if op := patchDefaultQueue(job); op != nil {
+		patch.Add(op.Path, op.Value)
+	}
+	if op := mutateSpec(job.Spec.Tasks, "/spec/tasks"); op != nil {
+		patch.Replace(op.Path, op.Value)
 	}
-	pathSpec := mutateSpec(job.Spec.Tasks, "/spec/tasks")
-	if pathSpec != nil {
-		patch = append(patch, *pathSpec)
+	for _, op := range patchDefaultScheduler(job.Spec.Tasks, "/spec/tasks", config.SchedulerName) {
+		patch.Add(op.Path, op.Value)
 	}
-	return json.Marshal(patch)
+	for _, op := range requests {
+		patch.Add(op.Path, op.Value)
+	}
+	return patch
+}
+
+// checkPriorityClasses returns the message of the priority classes of the job and its tasks
+// which do not exist, empty if all of them exist.
+func checkPriorityClasses(job *v1alpha1.Job) (string, error) {
+	if config.KubeClient == nil && config.PriorityClassLister == nil {
+		return "", nil
+	}
+
+	names := []string{job.Spec.PriorityClassName}
+	for _, task := range job.Spec.Tasks {
+		names = append(names, task.Template.Spec.PriorityClassName)
+	}
+
+	checked := map[string]bool{}
+	msg := ""
+	for _, name := range names {
+		if len(name) == 0 || checked[name] {
+			continue
+		}
+		checked[name] = true
+
+		if _, err := getPriorityClass(name); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get priority class %s: %v", name, err)
+			}
+			msg += fmt.Sprintf(" priority class %s is not found;", name)
+		}
+	}
+
+	return msg, nil
+}
+
+// getPriorityClass gets the priority class from the informer cache if there is one; a priority class
+// which is not found in the cache is got from the apiserver only before the cache has synced.
+func getPriorityClass(name string) (*schedulingv1beta1.PriorityClass, error) {
+	if config.PriorityClassLister != nil {
+		pc, err := config.PriorityClassLister.Get(name)
+		if !apierrors.IsNotFound(err) || config.KubeClient == nil || config.HasSynced == nil || config.HasSynced() {
+			return pc, err
+		}
+	}
+
+	return config.KubeClient.SchedulingV1beta1().PriorityClasses().Get(name, metav1.GetOptions{})
 }
 
 func patchDefaultQueue(job *v1alpha1.Job) *patchOperation {
 	//Add default queue if not specified.
 	if job.Spec.Queue == "" {
-		return &patchOperation{Op: "add", Path: "/spec/queue", Value: DefaultQueue}
+		return &patchOperation{Op: "add", Path: "/spec/queue", Value: util.GetDefaultQueue(config.NamespaceLister, job.Namespace)}
 	}
 	return nil
 }
 
+// patchDefaultScheduler sets the scheduler name of task pod templates which do not specify one,
+// otherwise those pods are bound by default-scheduler and gang scheduling does not apply.
+func patchDefaultScheduler(tasks []v1alpha1.TaskSpec, basePath string, schedulerName string) []patchOperation {
+	if len(schedulerName) == 0 {
+		return nil
+	}
+
+	var patch []patchOperation
+	for index := range tasks {
+		if len(tasks[index].Template.Spec.SchedulerName) != 0 {
+			continue
+		}
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/%d/template/spec/schedulerName", basePath, index),
+			Value: schedulerName,
+		})
+	}
+	return patch
+}
+
 func mutateSpec(tasks []v1alpha1.TaskSpec, basePath string) *patchOperation {
 	patched := false
 	for index := range tasks {
@@ -20,7 +20,12 @@ import (
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 )
@@ -141,3 +146,244 @@ func TestCreatePatchExecution(t *testing.T) {
 	}
 
 }
+
+func TestPatchDefaultScheduler(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Tasks         []v1alpha1.TaskSpec
+		SchedulerName string
+		ExpectPaths   []string
+	}{
+		{
+			Name: "inject scheduler name into tasks without one",
+			Tasks: []v1alpha1.TaskSpec{
+				{Name: "task-0"},
+				{
+					Name: "task-1",
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{SchedulerName: "custom-scheduler"},
+					},
+				},
+				{Name: "task-2"},
+			},
+			SchedulerName: "volcano",
+			ExpectPaths: []string{
+				"/spec/tasks/0/template/spec/schedulerName",
+				"/spec/tasks/2/template/spec/schedulerName",
+			},
+		},
+		{
+			Name: "keep explicitly set scheduler name",
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name: "task-0",
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{SchedulerName: "default-scheduler"},
+					},
+				},
+			},
+			SchedulerName: "volcano",
+		},
+		{
+			Name:          "inject renamed scheduler",
+			Tasks:         []v1alpha1.TaskSpec{{Name: "task-0"}},
+			SchedulerName: "volcano-batch",
+			ExpectPaths:   []string{"/spec/tasks/0/template/spec/schedulerName"},
+		},
+		{
+			Name:  "no scheduler name configured",
+			Tasks: []v1alpha1.TaskSpec{{Name: "task-0"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		patch := patchDefaultScheduler(testCase.Tasks, "/spec/tasks", testCase.SchedulerName)
+		if len(patch) != len(testCase.ExpectPaths) {
+			t.Errorf("case %s: expected %d patch operations, but got %v",
+				testCase.Name, len(testCase.ExpectPaths), patch)
+			continue
+		}
+		for i, op := range patch {
+			if op.Op != "add" || op.Path != testCase.ExpectPaths[i] || op.Value != testCase.SchedulerName {
+				t.Errorf("case %s: expected add %s with value %s, but got %v",
+					testCase.Name, testCase.ExpectPaths[i], testCase.SchedulerName, op)
+			}
+		}
+	}
+}
+
+func TestPatchDefaultQueue(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Annotations: map[string]string{v1alpha1.DefaultQueueAnnotationKey: "queue-a"},
+		},
+	})
+	indexer.Add(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-b"},
+	})
+
+	lister := config.NamespaceLister
+	config.NamespaceLister = corelisters.NewNamespaceLister(indexer)
+	defer func() { config.NamespaceLister = lister }()
+
+	testCases := []struct {
+		Name        string
+		Namespace   string
+		Queue       string
+		ExpectQueue string
+	}{
+		{
+			Name:        "use default queue of namespace",
+			Namespace:   "team-a",
+			ExpectQueue: "queue-a",
+		},
+		{
+			Name:        "namespace without default queue",
+			Namespace:   "team-b",
+			ExpectQueue: DefaultQueue,
+		},
+		{
+			Name:        "namespace not found",
+			Namespace:   "team-c",
+			ExpectQueue: DefaultQueue,
+		},
+		{
+			Name:      "keep queue of job",
+			Namespace: "team-a",
+			Queue:     "queue-b",
+		},
+	}
+
+	for _, testCase := range testCases {
+		job := &v1alpha1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: testCase.Namespace},
+			Spec:       v1alpha1.JobSpec{Queue: testCase.Queue},
+		}
+
+		patch := patchDefaultQueue(job)
+		if len(testCase.ExpectQueue) == 0 {
+			if patch != nil {
+				t.Errorf("case %s: expected no patch, but got %v", testCase.Name, patch)
+			}
+			continue
+		}
+		if patch == nil || patch.Path != "/spec/queue" || patch.Value != testCase.ExpectQueue {
+			t.Errorf("case %s: expected queue %s, but got %v", testCase.Name, testCase.ExpectQueue, patch)
+		}
+	}
+}
+
+func TestCheckPriorityClasses(t *testing.T) {
+	kubeClient := config.KubeClient
+	config.KubeClient = kubeclient.NewSimpleClientset(&v1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      1000,
+	})
+	defer func() { config.KubeClient = kubeClient }()
+
+	testCases := []struct {
+		Name          string
+		JobClass      string
+		TaskClass     string
+		ExpectMessage string
+	}{
+		{
+			Name:     "priority class of job exists",
+			JobClass: "high",
+		},
+		{
+			Name: "no priority class",
+		},
+		{
+			Name:          "priority class of job is missing",
+			JobClass:      "missing",
+			ExpectMessage: " priority class missing is not found;",
+		},
+		{
+			Name:          "priority class of task is missing",
+			JobClass:      "high",
+			TaskClass:     "missing",
+			ExpectMessage: " priority class missing is not found;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		job := &v1alpha1.Job{
+			Spec: v1alpha1.JobSpec{
+				PriorityClassName: testCase.JobClass,
+				Tasks: []v1alpha1.TaskSpec{{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{PriorityClassName: testCase.TaskClass},
+					},
+				}},
+			},
+		}
+
+		msg, err := checkPriorityClasses(job)
+		if err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.Name, err)
+		}
+		if msg != testCase.ExpectMessage {
+			t.Errorf("case %s: expected message %q, got %q", testCase.Name, testCase.ExpectMessage, msg)
+		}
+	}
+}
+
+func TestCheckPriorityClassesFromCache(t *testing.T) {
+	kubeClient := config.KubeClient
+	config.KubeClient = kubeclient.NewSimpleClientset(&v1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "new"},
+		Value:      1000,
+	})
+	defer func() { config.KubeClient = kubeClient }()
+
+	pcInformer := kubeinformers.NewSharedInformerFactory(kubeclient.NewSimpleClientset(), 0).Scheduling().V1beta1().PriorityClasses()
+	pcInformer.Informer().GetIndexer().Add(&v1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      1000,
+	})
+	config.PriorityClassLister = pcInformer.Lister()
+	defer func() { config.PriorityClassLister = nil }()
+
+	synced := false
+	config.HasSynced = func() bool { return synced }
+	defer func() { config.HasSynced = nil }()
+
+	testCases := []struct {
+		Name          string
+		Synced        bool
+		JobClass      string
+		ExpectMessage string
+	}{
+		{
+			Name:     "priority class in cache",
+			Synced:   true,
+			JobClass: "high",
+		},
+		{
+			Name:     "priority class not in cache before synced",
+			JobClass: "new",
+		},
+		{
+			Name:          "priority class not in cache after synced",
+			Synced:        true,
+			JobClass:      "new",
+			ExpectMessage: " priority class new is not found;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		synced = testCase.Synced
+		job := &v1alpha1.Job{Spec: v1alpha1.JobSpec{PriorityClassName: testCase.JobClass}}
+
+		msg, err := checkPriorityClasses(job)
+		if err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.Name, err)
+		}
+		if msg != testCase.ExpectMessage {
+			t.Errorf("case %s: expected message %q, got %q", testCase.Name, testCase.ExpectMessage, msg)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"volcano.sh/volcano/pkg/admission/util"
+	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+// patchResourcePercentages resolves the requests of the tasks annotated with percentages of the
+// capability of the job queue, and returns the operations setting them on the first container of
+// the tasks. The message of the invalid percentages is returned if any; the error is returned if
+// the queue could not be got.
+func patchResourcePercentages(job *v1alpha1.Job, basePath string) ([]patchOperation, string, error) {
+	var queue *schedulingv1alpha2.Queue
+	var patch []patchOperation
+	msg := ""
+
+	for index, task := range job.Spec.Tasks {
+		value, found := task.Template.Annotations[v1alpha1.ResourcePercentageAnnotationKey]
+		if !found {
+			continue
+		}
+
+		percentages, err := parseResourcePercentages(value)
+		if err != nil {
+			msg += fmt.Sprintf(" invalid annotation %s of task %s: %v;", v1alpha1.ResourcePercentageAnnotationKey, task.Name, err)
+			continue
+		}
+		if len(task.Template.Spec.Containers) == 0 {
+			msg += fmt.Sprintf(" task %s has no container to set the resource percentages;", task.Name)
+			continue
+		}
+
+		if queue == nil {
+			queueName := job.Spec.Queue
+			if queueName == "" {
+				queueName = util.GetDefaultQueue(config.NamespaceLister, job.Namespace)
+			}
+			if queue, err = util.GetQueue(config.QueueLister, config.VolcanoClient, queueName); err != nil {
+				if apierrors.IsNotFound(err) {
+					return nil, fmt.Sprintf(" queue %s of the resource percentages is not found;", queueName), nil
+				}
+				return nil, "", fmt.Errorf("failed to get queue %s: %v", queueName, err)
+			}
+		}
+
+		container := task.Template.Spec.Containers[0]
+		requests, taskMsg := resolveResourcePercentages(percentages, queue, container.Resources.Limits)
+		if taskMsg != "" {
+			msg += fmt.Sprintf(" task %s:%s", task.Name, taskMsg)
+			continue
+		}
+
+		resources := *container.Resources.DeepCopy()
+		if resources.Requests == nil {
+			resources.Requests = v1.ResourceList{}
+		}
+		for name, quantity := range requests {
+			resources.Requests[name] = quantity
+		}
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  fmt.Sprintf("%s/%d/template/spec/containers/0/resources", basePath, index),
+			Value: resources,
+		})
+	}
+
+	return patch, msg, nil
+}
+
+// parseResourcePercentages parses the percentages in the form of "cpu=20,memory=12.5%".
+func parseResourcePercentages(value string) (map[v1.ResourceName]float64, error) {
+	percentages := map[v1.ResourceName]float64{}
+	for _, item := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("%q is not in the form of <resource>=<percentage>", item)
+		}
+
+		name := v1.ResourceName(strings.TrimSpace(kv[0]))
+		if _, found := percentages[name]; found {
+			return nil, fmt.Errorf("percentage of %s is set more than once", name)
+		}
+		percentage, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(kv[1]), "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("percentage of %s is not a number", name)
+		}
+		if percentage <= 0 || percentage > 100 {
+			return nil, fmt.Errorf("percentage of %s must be greater than 0 and not greater than 100", name)
+		}
+		percentages[name] = percentage
+	}
+
+	return percentages, nil
+}
+
+// resolveResourcePercentages returns the requests of the percentages of the queue capability, or the
+// message of the percentages which could not be resolved or exceed the limits of the container.
+func resolveResourcePercentages(percentages map[v1.ResourceName]float64, queue *schedulingv1alpha2.Queue,
+	limits v1.ResourceList) (v1.ResourceList, string) {
+	var names []string
+	for name := range percentages {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	requests := v1.ResourceList{}
+	msg := ""
+	for _, n := range names {
+		name := v1.ResourceName(n)
+		capability, found := queuehelpers.EffectiveCapability(queue)[name]
+		if !found || capability.Sign() <= 0 {
+			msg += fmt.Sprintf(" queue %s has no capability of %s;", queue.Name, name)
+			continue
+		}
+
+		quantity := percentageOf(name, capability, percentages[name])
+		if quantity.Sign() <= 0 {
+			msg += fmt.Sprintf(" %v%% of capability %s of queue %s is less than one unit;",
+				percentages[name], capability.String(), queue.Name)
+			continue
+		}
+		if limit, found := limits[name]; found && limit.Cmp(quantity) < 0 {
+			msg += fmt.Sprintf(" request %s of %s exceeds the limit %s;", quantity.String(), name, limit.String())
+			continue
+		}
+		requests[name] = quantity
+	}
+
+	return requests, msg
+}
+
+// percentageOf returns the percentage of the capability, CPU is rounded down to millicores and the
+// other resources to units, e.g. bytes of memory.
+func percentageOf(name v1.ResourceName, capability resource.Quantity, percentage float64) resource.Quantity {
+	if name == v1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(math.Floor(float64(capability.MilliValue())*percentage/100)), capability.Format)
+	}
+
+	return *resource.NewQuantity(int64(math.Floor(float64(capability.Value())*percentage/100)), capability.Format)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+)
+
+func TestParseResourcePercentages(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected map[v1.ResourceName]float64
+	}{
+		{
+			value:    "cpu=20, memory=12.5%",
+			expected: map[v1.ResourceName]float64{v1.ResourceCPU: 20, v1.ResourceMemory: 12.5},
+		},
+		{
+			value:    "cpu=100",
+			expected: map[v1.ResourceName]float64{v1.ResourceCPU: 100},
+		},
+		{value: "cpu=101"},
+		{value: "cpu=0"},
+		{value: "cpu=abc"},
+		{value: "cpu"},
+		{value: "=20"},
+		{value: "cpu=20,cpu=30"},
+	}
+
+	for _, testCase := range testCases {
+		percentages, err := parseResourcePercentages(testCase.value)
+		if (err == nil) != (testCase.expected != nil) {
+			t.Errorf("%q: expected %v, got error %v", testCase.value, testCase.expected, err)
+			continue
+		}
+		for name, percentage := range testCase.expected {
+			if percentages[name] != percentage {
+				t.Errorf("%q: expected %v, got %v", testCase.value, testCase.expected, percentages)
+			}
+		}
+	}
+}
+
+func newPercentageJob(queue, percentages string, limits v1.ResourceList) *v1alpha1.Job {
+	return &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "job"},
+		Spec: v1alpha1.JobSpec{
+			Queue: queue,
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name: "plain",
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{Containers: []v1.Container{{Name: "c"}}},
+					},
+				},
+				{
+					Name: "worker",
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{v1alpha1.ResourcePercentageAnnotationKey: percentages},
+						},
+						Spec: v1.PodSpec{Containers: []v1.Container{
+							{
+								Name: "c",
+								Resources: v1.ResourceRequirements{
+									Requests: v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+									Limits:   limits,
+								},
+							},
+							{Name: "sidecar"},
+						}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPatchResourcePercentages(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec: schedulingv1alpha2.QueueSpec{
+			Capability: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10"),
+				v1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+	})
+	indexer.Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "percent"},
+		Spec: schedulingv1alpha2.QueueSpec{
+			CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 10},
+		},
+		Status: schedulingv1alpha2.QueueStatus{
+			Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+		},
+	})
+	indexer.Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: DefaultQueue}})
+	lister := config.QueueLister
+	config.QueueLister = schedulinglisters.NewQueueLister(indexer)
+	defer func() { config.QueueLister = lister }()
+
+	testCases := []struct {
+		name             string
+		job              *v1alpha1.Job
+		expectedRequests v1.ResourceList
+		expectedMsg      string
+	}{
+		{
+			name: "resolves percentages of queue capability",
+			job:  newPercentageJob("q1", "cpu=25,memory=20%", nil),
+			expectedRequests: v1.ResourceList{
+				v1.ResourceCPU:              resource.MustParse("2500m"),
+				v1.ResourceMemory:           resource.MustParse("2Gi"),
+				v1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+			},
+		},
+		{
+			name: "resolves percentages of capability resolved by queue controller",
+			job:  newPercentageJob("percent", "cpu=50", nil),
+			expectedRequests: v1.ResourceList{
+				v1.ResourceCPU:              resource.MustParse("2"),
+				v1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+			},
+		},
+		{
+			name:        "rejects percentage greater than 100",
+			job:         newPercentageJob("q1", "cpu=120", nil),
+			expectedMsg: "percentage of cpu must be greater than 0 and not greater than 100",
+		},
+		{
+			name:        "rejects resource without capability",
+			job:         newPercentageJob("q1", "nvidia.com/gpu=50", nil),
+			expectedMsg: "queue q1 has no capability of nvidia.com/gpu",
+		},
+		{
+			name:        "rejects queue without capability",
+			job:         newPercentageJob("", "cpu=50", nil),
+			expectedMsg: "queue default has no capability of cpu",
+		},
+		{
+			name:        "rejects unknown queue",
+			job:         newPercentageJob("q2", "cpu=50", nil),
+			expectedMsg: "queue q2 of the resource percentages is not found",
+		},
+		{
+			name:        "rejects request exceeding limit",
+			job:         newPercentageJob("q1", "cpu=50", v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}),
+			expectedMsg: "request 5 of cpu exceeds the limit 2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		patch, msg, err := patchResourcePercentages(testCase.job, "/spec/tasks")
+		if err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.name, err)
+			continue
+		}
+		if testCase.expectedMsg != "" {
+			if !strings.Contains(msg, testCase.expectedMsg) {
+				t.Errorf("case %s: expected message containing %q, got %q", testCase.name, testCase.expectedMsg, msg)
+			}
+			continue
+		}
+		if msg != "" {
+			t.Errorf("case %s: unexpected message %q", testCase.name, msg)
+			continue
+		}
+
+		if len(patch) != 1 || patch[0].Path != "/spec/tasks/1/template/spec/containers/0/resources" {
+			t.Errorf("case %s: expected patch of the first container of task worker, got %v", testCase.name, patch)
+			continue
+		}
+		requests := patch[0].Value.(v1.ResourceRequirements).Requests
+		if len(requests) != len(testCase.expectedRequests) {
+			t.Errorf("case %s: expected requests %v, got %v", testCase.name, testCase.expectedRequests, requests)
+		}
+		for name, expected := range testCase.expectedRequests {
+			if quantity := requests[name]; quantity.Cmp(expected) != 0 {
+				t.Errorf("case %s: expected %s %s, got %s", testCase.name, name, expected.String(), quantity.String())
+			}
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/api/admission/v1beta1"
 	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -31,11 +32,16 @@ import (
 	k8scorev1 "k8s.io/kubernetes/pkg/apis/core/v1"
 	k8scorevalid "k8s.io/kubernetes/pkg/apis/core/validation"
 
+	"volcano.sh/volcano/pkg/admission/audit"
+	"volcano.sh/volcano/pkg/admission/policy"
 	"volcano.sh/volcano/pkg/admission/router"
 	"volcano.sh/volcano/pkg/admission/schema"
 	"volcano.sh/volcano/pkg/admission/util"
 	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/controllers/job/plugins"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
 )
 
 func init() {
@@ -71,9 +77,18 @@ var config = &router.AdmissionServiceConfig{}
 func AdmitJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 	klog.V(3).Infof("admitting jobs -- %s", ar.Request.Operation)
 
+	response, queue := admitJobs(ar)
+	audit.Log(config.AuditSink, "/jobs/validate", ar, queue, response)
+
+	return response
+}
+
+// admitJobs returns the response to the admission review and the queue of the job, which is empty
+// if the job could not be decoded.
+func admitJobs(ar v1beta1.AdmissionReview) (*v1beta1.AdmissionResponse, string) {
 	job, err := schema.DecodeJob(ar.Request.Object, ar.Request.Resource)
 	if err != nil {
-		return util.ToAdmissionResponse(err)
+		return util.ToAdmissionResponse(err), ""
 	}
 	var msg string
 	reviewResponse := v1beta1.AdmissionResponse{}
@@ -81,61 +96,117 @@ func AdmitJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 
 	switch ar.Request.Operation {
 	case v1beta1.Create:
-		msg = validateJob(job, &reviewResponse)
+		msg, err = validateJob(job, &reviewResponse)
+		if err != nil {
+			return util.ToInternalErrorResponse(err), job.Spec.Queue
+		}
 		break
 	case v1beta1.Update:
-		_, err := schema.DecodeJob(ar.Request.OldObject, ar.Request.Resource)
+		oldJob, err := schema.DecodeJob(ar.Request.OldObject, ar.Request.Resource)
 		if err != nil {
-			return util.ToAdmissionResponse(err)
+			return util.ToAdmissionResponse(err), job.Spec.Queue
+		}
+		// The errors are messages of the same form as the ones of validateJob, " <message>;".
+		if err := validateSuspend(job); err != nil {
+			reviewResponse.Allowed = false
+			msg = msg + err.Error()
+		}
+		if err := validateJobUpdate(oldJob, job); err != nil {
+			reviewResponse.Allowed = false
+			msg = msg + err.Error()
 		}
 		break
 	default:
 		err := fmt.Errorf("expect operation to be 'CREATE' or 'UPDATE'")
-		return util.ToAdmissionResponse(err)
+		return util.ToAdmissionResponse(err), job.Spec.Queue
+	}
+
+	// The external policy only has a say on the jobs which Volcano admits, a deny of either wins.
+	if reviewResponse.Allowed {
+		if decision := checkJobPolicy(ar, job); !decision.Allowed {
+			reviewResponse.Allowed = false
+			msg = fmt.Sprintf("denied by external policy: %s", decision.Reason)
+		}
 	}
 
 	if !reviewResponse.Allowed {
-		reviewResponse.Result = &metav1.Status{Message: strings.TrimSpace(msg)}
+		reviewResponse = *util.ToDeniedResponse(strings.TrimSpace(msg))
 	}
-	return &reviewResponse
+
+	return &reviewResponse, job.Spec.Queue
+}
+
+// checkJobPolicy returns the verdict of the external policy on the job, which allows the job if
+// the policy is not configured.
+func checkJobPolicy(ar v1beta1.AdmissionReview, job *v1alpha1.Job) *policy.Decision {
+	if config.JobPolicy == nil {
+		return &policy.Decision{Allowed: true}
+	}
+
+	return config.JobPolicy.Check(&policy.Request{
+		Operation: string(ar.Request.Operation),
+		Kind:      helpers.JobKind.Kind,
+		Namespace: job.Namespace,
+		Name:      job.Name,
+		User:      ar.Request.UserInfo.Username,
+		Object:    job,
+	})
 }
 
-func validateJob(job *v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) string {
+// validateJob returns the reasons to reject the job, or an error if the job could not be validated.
+func validateJob(job *v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) (string, error) {
 	var msg string
 	taskNames := map[string]string{}
 	var totalReplicas int32
 
 	if job.Spec.MinAvailable <= 0 {
 		reviewResponse.Allowed = false
-		return fmt.Sprintf("'minAvailable' must be greater than zero.")
+		return fmt.Sprintf("'minAvailable' must be greater than zero."), nil
 	}
 
 	if job.Spec.MaxRetry < 0 {
 		reviewResponse.Allowed = false
-		return fmt.Sprintf("'maxRetry' cannot be less than zero.")
+		return fmt.Sprintf("'maxRetry' cannot be less than zero."), nil
 	}
 
 	if job.Spec.TTLSecondsAfterFinished != nil && *job.Spec.TTLSecondsAfterFinished < 0 {
 		reviewResponse.Allowed = false
-		return fmt.Sprintf("'ttlSecondsAfterFinished' cannot be less than zero.")
+		return fmt.Sprintf("'ttlSecondsAfterFinished' cannot be less than zero."), nil
 	}
 
 	if len(job.Spec.Tasks) == 0 {
 		reviewResponse.Allowed = false
-		return fmt.Sprintf("No task specified in job spec")
+		return fmt.Sprintf("No task specified in job spec"), nil
 	}
 
+	injected := injectedVolumes(job)
 	for index, task := range job.Spec.Tasks {
 		if task.Replicas <= 0 {
 			msg = msg + fmt.Sprintf(" 'replicas' is not set positive in task: %s;", task.Name)
 		}
 
+		if task.MaxRetry < 0 {
+			msg = msg + fmt.Sprintf(" 'maxRetry' cannot be less than zero in task: %s;", task.Name)
+		}
+
+		if task.BackoffSeconds != nil && *task.BackoffSeconds < 0 {
+			msg = msg + fmt.Sprintf(" 'backoffSeconds' cannot be less than zero in task: %s;", task.Name)
+		}
+
+		if task.MinAvailable != nil {
+			if *task.MinAvailable < 0 {
+				msg = msg + fmt.Sprintf(" 'minAvailable' cannot be less than zero in task: %s;", task.Name)
+			} else if *task.MinAvailable > task.Replicas {
+				msg = msg + fmt.Sprintf(" 'minAvailable' cannot be greater than 'replicas' in task: %s;", task.Name)
+			}
+		}
+
 		// count replicas
 		totalReplicas = totalReplicas + task.Replicas
 
 		// validate task name
 		if errMsgs := validation.IsDNS1123Label(task.Name); len(errMsgs) > 0 {
-			msg = msg + fmt.Sprintf(" %v;", errMsgs)
+			msg = msg + fmt.Sprintf(" invalid task name %q: %v;", task.Name, errMsgs)
 		}
 
 		// duplicate task name
@@ -151,11 +222,24 @@ func validateJob(job *v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) s
 				getValidEvents(), getValidActions())
 		}
 
-		msg += validateTaskTemplate(task, job, index)
+		if err := validateVolumeMounts(task, injected); err != nil {
+			msg += err.Error()
+		}
+
+		msg += validateTaskTemplate(task, job, index, injected)
+		msg += validateTaskQueue(task, job)
 	}
 
+	msg += validateTaskResourcePolicy(job)
+
+	// The podgroup of such a job can never be scheduled.
 	if totalReplicas < job.Spec.MinAvailable {
-		msg = msg + " 'minAvailable' should not be greater than total replicas in tasks;"
+		msg = msg + fmt.Sprintf(" 'minAvailable' should not be greater than total replicas in tasks: "+
+			"requested minAvailable is %d, total replicas are %d;", job.Spec.MinAvailable, totalReplicas)
+	}
+
+	if err := validateTaskDependencies(job); err != nil {
+		msg = msg + err.Error()
 	}
 
 	if err := validatePolicies(job.Spec.Policies, field.NewPath("spec.policies")); err != nil {
@@ -176,24 +260,140 @@ func validateJob(job *v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) s
 		msg = msg + err.Error()
 	}
 
-	// Check whether Queue already present or not
-	if _, err := config.VolcanoClient.SchedulingV1alpha2().Queues().Get(job.Spec.Queue, metav1.GetOptions{}); err != nil {
-		// TODO: deprecate v1alpha1
-		if _, err := config.VolcanoClient.SchedulingV1alpha1().Queues().Get(job.Spec.Queue, metav1.GetOptions{}); err != nil {
-			msg = msg + fmt.Sprintf(" unable to find job queue: %v", err)
-		}
+	if err := validateSuspend(job); err != nil {
+		msg = msg + err.Error()
+	}
+
+	if err := validateCompletionMode(job); err != nil {
+		msg = msg + err.Error()
+	}
+
+	queueMsg, err := validateJobQueue(job)
+	if err != nil {
+		return "", err
+	}
+	msg = msg + queueMsg
+
+	capabilityMsg, err := validateJobCapability(job)
+	if err != nil {
+		return "", err
 	}
+	msg = msg + capabilityMsg
 
 	if msg != "" {
 		reviewResponse.Allowed = false
 	}
 
-	return msg
+	return msg, nil
 }
 
-func validateTaskTemplate(task v1alpha1.TaskSpec, job *v1alpha1.Job, index int) string {
+// validateJobQueue checks whether the queue of the job exists and accepts new jobs.
+func validateJobQueue(job *v1alpha1.Job) (string, error) {
+	if !config.ValidateJobQueue {
+		return "", nil
+	}
+
+	queueName := job.Spec.Queue
+	if queueName == "" {
+		queueName = util.GetDefaultQueue(config.NamespaceLister, job.Namespace)
+	}
+
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, queueName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get queue %s: %v", queueName, err)
+		}
+		// TODO: deprecate v1alpha1
+		if _, err := config.VolcanoClient.SchedulingV1alpha1().Queues().Get(queueName, metav1.GetOptions{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return "", fmt.Errorf("failed to get queue %s: %v", queueName, err)
+			}
+			return fmt.Sprintf(" unable to find job queue: %v;", err), nil
+		}
+		return "", nil
+	}
+
+	if queue.DeletionTimestamp != nil {
+		return fmt.Sprintf(" job queue %s is being deleted;", queueName), nil
+	}
+	// The queue closed as idle is opened again by the podgroup of the job.
+	if queue.Status.State == schedulingv1alpha2.QueueStateClosed &&
+		queue.Annotations[schedulingv1alpha2.IdleClosedAnnotationKey] != "true" {
+		return fmt.Sprintf(" job queue %s is closed;", queueName), nil
+	}
+	// The podgroups admitted before the queue is cordoned are still scheduled.
+	if queue.Annotations[schedulingv1alpha2.CordonedAnnotationKey] == "true" {
+		return fmt.Sprintf(" job queue %s is cordoned;", queueName), nil
+	}
+
+	return "", nil
+}
+
+// validateJobCapability checks whether the capability of the queue can ever fit the minResources
+// of the job; the check is static, the current usage of the queue is not considered.
+func validateJobCapability(job *v1alpha1.Job) (string, error) {
+	if !config.ValidateJobCapability {
+		return "", nil
+	}
+
+	queueName := job.Spec.Queue
+	if queueName == "" {
+		queueName = util.GetDefaultQueue(config.NamespaceLister, job.Namespace)
+	}
+
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, queueName)
+	if err != nil {
+		// The existence of the queue is checked by validateJobQueue.
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get queue %s: %v", queueName, err)
+	}
+	queueCapability := queuehelpers.EffectiveCapability(queue)
+	if len(queueCapability) == 0 {
+		return "", nil
+	}
+
+	required, capability := exceededCapability(job, queueCapability)
+	if len(required) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf(" job requires at least %s but capability of queue %s is %s;",
+		required, queueName, capability), nil
+}
+
+// validateTaskQueue rejects the task whose pods are annotated to another queue than the one of the
+// job if the job is a gang, as the pods webhook only moves the pods of podgroups scheduled one by one.
+func validateTaskQueue(task v1alpha1.TaskSpec, job *v1alpha1.Job) string {
+	queue := task.Template.Annotations[schedulingv1alpha2.QueueNameAnnotationKey]
+	if queue == "" || queue == job.Spec.Queue || job.Spec.MinAvailable <= 1 {
+		return ""
+	}
+
+	return fmt.Sprintf(" the pods of task %s can not be moved to queue %s by annotation %s, as the job "+
+		"with 'minAvailable' %d is scheduled as a gang in queue %s;",
+		task.Name, queue, schedulingv1alpha2.QueueNameAnnotationKey, job.Spec.MinAvailable, job.Spec.Queue)
+}
+
+func validateTaskTemplate(task v1alpha1.TaskSpec, job *v1alpha1.Job, index int, injected map[string]bool) string {
 	var v1PodTemplate v1.PodTemplate
 	v1PodTemplate.Template = *task.Template.DeepCopy()
+
+	// The volumes injected by the plugins are only added on pod creation, declare placeholders
+	// of them so that mounting them is valid.
+	declared := map[string]bool{}
+	for _, volume := range v1PodTemplate.Template.Spec.Volumes {
+		declared[volume.Name] = true
+	}
+	for name := range injected {
+		if !declared[name] {
+			v1PodTemplate.Template.Spec.Volumes = append(v1PodTemplate.Template.Spec.Volumes, v1.Volume{
+				Name:         name,
+				VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+			})
+		}
+	}
 	k8scorev1.SetObjectDefaults_PodTemplate(&v1PodTemplate)
 
 	var coreTemplateSpec k8score.PodTemplateSpec
@@ -215,7 +415,15 @@ func validateTaskTemplate(task v1alpha1.TaskSpec, job *v1alpha1.Job, index int)
 		Template: coreTemplateSpec,
 	}
 
-	if allErrs := k8scorevalid.ValidatePodTemplate(&corePodTemplate); len(allErrs) > 0 {
+	var allErrs field.ErrorList
+	for _, err := range k8scorevalid.ValidatePodTemplate(&corePodTemplate) {
+		// Mounts of undeclared volumes are reported by validateVolumeMounts.
+		if err.Type == field.ErrorTypeNotFound && strings.Contains(err.Field, ".volumeMounts[") {
+			continue
+		}
+		allErrs = append(allErrs, err)
+	}
+	if len(allErrs) > 0 {
 		msg := fmt.Sprintf("spec.task[%d].", index)
 		for index := range allErrs {
 			msg += allErrs[index].Error() + ". "
@@ -17,21 +17,32 @@ limitations under the License.
 package validate
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
 	"k8s.io/api/admission/v1beta1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubetesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 
+	"volcano.sh/volcano/pkg/admission/audit"
+	"volcano.sh/volcano/pkg/admission/policy"
 	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	schedulingv1aplha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	fakeclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
 )
 
 func TestValidateExecution(t *testing.T) {
 	var invTTL int32 = -1
 	var policyExitCode int32 = -1
+	var taskMinAvailable int32 = 3
 	namespace := "test"
 	priviledged := true
 
@@ -136,12 +147,12 @@ func TestValidateExecution(t *testing.T) {
 			ret:            "duplicated task name duplicated-task-1",
 			ExpectErr:      true,
 		},
-		// Duplicated Policy Event
+		// task names differing in case
 		{
-			Name: "job-policy-duplicated",
+			Name: "task-name-case-job",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-policy-duplicated",
+					Name:      "task-name-case-job",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -149,7 +160,7 @@ func TestValidateExecution(t *testing.T) {
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "task-1",
+							Name:     "Worker",
 							Replicas: 1,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
@@ -165,37 +176,44 @@ func TestValidateExecution(t *testing.T) {
 								},
 							},
 						},
-					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:  v1alpha1.PodFailedEvent,
-							Action: v1alpha1.AbortJobAction,
-						},
 						{
-							Event:  v1alpha1.PodFailedEvent,
-							Action: v1alpha1.RestartJobAction,
+							Name:     "worker",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "duplicate",
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            `invalid task name "Worker"`,
 			ExpectErr:      true,
 		},
-		// Min Available illegal
+		// empty task name
 		{
-			Name: "Min Available illegal",
+			Name: "empty-task-name-job",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-min-illegal",
+					Name:      "empty-task-name-job",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
-					MinAvailable: 2,
+					MinAvailable: 1,
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "task-1",
+							Name:     "",
 							Replicas: 1,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
@@ -214,16 +232,16 @@ func TestValidateExecution(t *testing.T) {
 					},
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "'minAvailable' should not be greater than total replicas in tasks",
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            `invalid task name ""`,
 			ExpectErr:      true,
 		},
-		// Job Plugin illegal
+		// Duplicated Policy Event
 		{
-			Name: "Job Plugin illegal",
+			Name: "job-policy-duplicated",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-plugin-illegal",
+					Name:      "job-policy-duplicated",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -248,25 +266,32 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Plugins: map[string][]string{
-						"big_plugin": {},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.PodFailedEvent,
+							Action: v1alpha1.AbortJobAction,
+						},
+						{
+							Event:  v1alpha1.PodFailedEvent,
+							Action: v1alpha1.RestartJobAction,
+						},
 					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "unable to find job plugin: big_plugin",
+			ret:            "duplicate",
 			ExpectErr:      true,
 		},
-		// ttl-illegal
+		// Min Available illegal
 		{
-			Name: "job-ttl-illegal",
+			Name: "Min Available illegal",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-ttl-illegal",
+					Name:      "job-min-illegal",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
-					MinAvailable: 1,
+					MinAvailable: 2,
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
@@ -287,23 +312,22 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					TTLSecondsAfterFinished: &invTTL,
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "'ttlSecondsAfterFinished' cannot be less than zero",
+			ret:            "'minAvailable' should not be greater than total replicas in tasks",
 			ExpectErr:      true,
 		},
-		// min-MinAvailable less than zero
+		// Min Available greater than replicas of positive tasks
 		{
-			Name: "minAvailable-lessThanZero",
+			Name: "Min Available with zero-replica task",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "minAvailable-lessThanZero",
+					Name:      "job-min-zero-replica",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
-					MinAvailable: -1,
+					MinAvailable: 2,
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
@@ -323,29 +347,9 @@ func TestValidateExecution(t *testing.T) {
 								},
 							},
 						},
-					},
-				},
-			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
-			ret:            "'minAvailable' must be greater than zero.",
-			ExpectErr:      true,
-		},
-		// maxretry less than zero
-		{
-			Name: "maxretry-lessThanZero",
-			Job: v1alpha1.Job{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "maxretry-lessThanZero",
-					Namespace: namespace,
-				},
-				Spec: v1alpha1.JobSpec{
-					MinAvailable: 1,
-					MaxRetry:     -1,
-					Queue:        "default",
-					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "task-1",
-							Replicas: 1,
+							Name:     "task-2",
+							Replicas: 0,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
 									Labels: map[string]string{"name": "test"},
@@ -364,33 +368,32 @@ func TestValidateExecution(t *testing.T) {
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
-			ret:            "'maxRetry' cannot be less than zero.",
+			ret:            "requested minAvailable is 2, total replicas are 1;",
 			ExpectErr:      true,
 		},
-		// no task specified in the job
+		// Min Available of job without tasks
 		{
-			Name: "no-task",
+			Name: "Min Available without tasks",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "no-task",
+					Name:      "job-min-no-task",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
 					MinAvailable: 1,
 					Queue:        "default",
-					Tasks:        []v1alpha1.TaskSpec{},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
 			ret:            "No task specified in job spec",
 			ExpectErr:      true,
 		},
-		// replica set less than zero
+		// Job Plugin illegal
 		{
-			Name: "replica-lessThanZero",
+			Name: "Job Plugin illegal",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "replica-lessThanZero",
+					Name:      "job-plugin-illegal",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -399,7 +402,7 @@ func TestValidateExecution(t *testing.T) {
 					Tasks: []v1alpha1.TaskSpec{
 						{
 							Name:     "task-1",
-							Replicas: -1,
+							Replicas: 1,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
 									Labels: map[string]string{"name": "test"},
@@ -415,18 +418,21 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
+					Plugins: map[string][]string{
+						"big_plugin": {},
+					},
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
-			ret:            "'replicas' is not set positive in task: task-1;",
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "unable to find job plugin: big_plugin",
 			ExpectErr:      true,
 		},
-		// task name error
+		// ttl-illegal
 		{
-			Name: "nonDNS-task",
+			Name: "job-ttl-illegal",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "replica-lessThanZero",
+					Name:      "job-ttl-illegal",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -434,7 +440,7 @@ func TestValidateExecution(t *testing.T) {
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "Task-1",
+							Name:     "task-1",
 							Replicas: 1,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
@@ -451,20 +457,19 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
+					TTLSecondsAfterFinished: &invTTL,
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
-			ret: "[a DNS-1123 label must consist of lower case alphanumeric characters or '-', and " +
-				"must start and end with an alphanumeric character (e.g. 'my-name',  " +
-				"or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')];",
-			ExpectErr: true,
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "'ttlSecondsAfterFinished' cannot be less than zero",
+			ExpectErr:      true,
 		},
-		// Policy Event with exit code
+		// task-retry-illegal
 		{
-			Name: "job-policy-withExitCode",
+			Name: "job-task-retry-illegal",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-policy-withExitCode",
+					Name:      "job-task-retry-illegal",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -472,8 +477,10 @@ func TestValidateExecution(t *testing.T) {
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "task-1",
-							Replicas: 1,
+							Name:           "task-1",
+							Replicas:       1,
+							MaxRetry:       -1,
+							BackoffSeconds: &invTTL,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
 									Labels: map[string]string{"name": "test"},
@@ -489,34 +496,34 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:    v1alpha1.PodFailedEvent,
-							Action:   v1alpha1.AbortJobAction,
-							ExitCode: &policyExitCode,
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "must not specify event and exitCode simultaneously",
+			ret:            "'maxRetry' cannot be less than zero in task: task-1; 'backoffSeconds' cannot be less than zero in task: task-1;",
 			ExpectErr:      true,
 		},
-		// Both policy event and exit code are nil
+		// suspend with resume policy
 		{
-			Name: "policy-noEvent-noExCode",
+			Name: "suspend-with-resume-policy",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "policy-noEvent-noExCode",
+					Name:      "suspend-with-resume-policy",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
 					MinAvailable: 1,
 					Queue:        "default",
+					Suspend:      true,
 					Tasks: []v1alpha1.TaskSpec{
 						{
 							Name:     "task-1",
 							Replicas: 1,
+							Policies: []v1alpha1.LifecyclePolicy{
+								{
+									Event:  v1alpha1.PodEvictedEvent,
+									Action: v1alpha1.ResumeJobAction,
+								},
+							},
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
 									Labels: map[string]string{"name": "test"},
@@ -532,23 +539,18 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Action: v1alpha1.AbortJobAction,
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "either event and exitCode should be specified",
+			ret:            "policy action ResumeJob conflicts with 'suspend';",
 			ExpectErr:      true,
 		},
-		// invalid policy event
+		// task minAvailable greater than replicas
 		{
-			Name: "invalid-policy-event",
+			Name: "task-minAvailable-greater-than-replicas",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "invalid-policy-event",
+					Name:      "task-minAvailable-greater-than-replicas",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -556,8 +558,9 @@ func TestValidateExecution(t *testing.T) {
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
-							Name:     "task-1",
-							Replicas: 1,
+							Name:         "task-1",
+							Replicas:     2,
+							MinAvailable: &taskMinAvailable,
 							Template: v1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
 									Labels: map[string]string{"name": "test"},
@@ -573,29 +576,24 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:  v1alpha1.Event("someFakeEvent"),
-							Action: v1alpha1.AbortJobAction,
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "invalid policy event",
+			ret:            "'minAvailable' cannot be greater than 'replicas' in task: task-1;",
 			ExpectErr:      true,
 		},
-		// invalid policy action
+		// unknown completion mode
 		{
-			Name: "invalid-policy-action",
+			Name: "unknown-completion-mode",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "invalid-policy-action",
+					Name:      "unknown-completion-mode",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
-					MinAvailable: 1,
-					Queue:        "default",
+					MinAvailable:   1,
+					Queue:          "default",
+					CompletionMode: "Ordered",
 					Tasks: []v1alpha1.TaskSpec{
 						{
 							Name:     "task-1",
@@ -615,24 +613,18 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:  v1alpha1.PodEvictedEvent,
-							Action: v1alpha1.Action("someFakeAction"),
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "invalid policy action",
+			ret:            "invalid completionMode \"Ordered\", valid modes are NonIndexed and Indexed;",
 			ExpectErr:      true,
 		},
-		// policy exit-code zero
+		// undeclared-volume-mount
 		{
-			Name: "policy-extcode-zero",
+			Name: "undeclared-volume-mount",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "policy-extcode-zero",
+					Name:      "undeclared-volume-mount",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -649,34 +641,27 @@ func TestValidateExecution(t *testing.T) {
 								Spec: v1.PodSpec{
 									Containers: []v1.Container{
 										{
-											Name:  "fake-name",
-											Image: "busybox:1.24",
+											Name:         "fake-name",
+											Image:        "busybox:1.24",
+											VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
 										},
 									},
 								},
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Action: v1alpha1.AbortJobAction,
-							ExitCode: func(i int32) *int32 {
-								return &i
-							}(int32(0)),
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "0 is not a valid error code",
+			ret:            "undeclared volume mounts in task task-1: data of container fake-name;",
 			ExpectErr:      true,
 		},
-		// duplicate policy exit-code
+		// declared-volume-mount
 		{
-			Name: "duplicate-exitcode",
+			Name: "declared-volume-mount",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "duplicate-exitcode",
+					Name:      "declared-volume-mount",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -691,45 +676,36 @@ func TestValidateExecution(t *testing.T) {
 									Labels: map[string]string{"name": "test"},
 								},
 								Spec: v1.PodSpec{
+									Volumes: []v1.Volume{{Name: "data", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}},
 									Containers: []v1.Container{
 										{
-											Name:  "fake-name",
-											Image: "busybox:1.24",
+											Name:         "fake-name",
+											Image:        "busybox:1.24",
+											VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
 										},
 									},
 								},
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							ExitCode: func(i int32) *int32 {
-								return &i
-							}(int32(1)),
-						},
-						{
-							ExitCode: func(i int32) *int32 {
-								return &i
-							}(int32(1)),
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "duplicate exitCode 1",
-			ExpectErr:      true,
+			ret:            "",
+			ExpectErr:      false,
 		},
-		// Policy with any event and other events
+		// plugin-injected-volume-mount
 		{
-			Name: "job-policy-withExitCode",
+			Name: "plugin-injected-volume-mount",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-policy-withExitCode",
+					Name:      "plugin-injected-volume-mount",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
 					MinAvailable: 1,
 					Queue:        "default",
+					Plugins:      map[string][]string{"svc": {}},
 					Tasks: []v1alpha1.TaskSpec{
 						{
 							Name:     "task-1",
@@ -741,40 +717,31 @@ func TestValidateExecution(t *testing.T) {
 								Spec: v1.PodSpec{
 									Containers: []v1.Container{
 										{
-											Name:  "fake-name",
-											Image: "busybox:1.24",
+											Name:         "fake-name",
+											Image:        "busybox:1.24",
+											VolumeMounts: []v1.VolumeMount{{Name: "plugin-injected-volume-mount-svc", MountPath: "/data"}},
 										},
 									},
 								},
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:  v1alpha1.AnyEvent,
-							Action: v1alpha1.AbortJobAction,
-						},
-						{
-							Event:  v1alpha1.PodFailedEvent,
-							Action: v1alpha1.RestartJobAction,
-						},
-					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "if there's * here, no other policy should be here",
-			ExpectErr:      true,
+			ret:            "",
+			ExpectErr:      false,
 		},
-		// invalid mount volume
+		// min-MinAvailable less than zero
 		{
-			Name: "invalid-mount-volume",
+			Name: "minAvailable-lessThanZero",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "invalid-mount-volume",
+					Name:      "minAvailable-lessThanZero",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
-					MinAvailable: 1,
+					MinAvailable: -1,
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
@@ -795,33 +762,23 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
-						{
-							Event:  v1alpha1.AnyEvent,
-							Action: v1alpha1.AbortJobAction,
-						},
-					},
-					Volumes: []v1alpha1.VolumeSpec{
-						{
-							MountPath: "",
-						},
-					},
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            " mountPath is required;",
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            "'minAvailable' must be greater than zero.",
 			ExpectErr:      true,
 		},
-		// duplicate mount volume
+		// maxretry less than zero
 		{
-			Name: "duplicate-mount-volume",
+			Name: "maxretry-lessThanZero",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "duplicate-mount-volume",
+					Name:      "maxretry-lessThanZero",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
 					MinAvailable: 1,
+					MaxRetry:     -1,
 					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
@@ -842,33 +799,110 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
-					Policies: []v1alpha1.LifecyclePolicy{
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            "'maxRetry' cannot be less than zero.",
+			ExpectErr:      true,
+		},
+		// no task specified in the job
+		{
+			Name: "no-task",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "no-task",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks:        []v1alpha1.TaskSpec{},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            "No task specified in job spec",
+			ExpectErr:      true,
+		},
+		// replica set less than zero
+		{
+			Name: "replica-lessThanZero",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "replica-lessThanZero",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
 						{
-							Event:  v1alpha1.AnyEvent,
-							Action: v1alpha1.AbortJobAction,
+							Name:     "task-1",
+							Replicas: -1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
 						},
 					},
-					Volumes: []v1alpha1.VolumeSpec{
-						{
-							MountPath:       "/var",
-							VolumeClaimName: "pvc1",
-						},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret:            "'replicas' is not set positive in task: task-1;",
+			ExpectErr:      true,
+		},
+		// task name error
+		{
+			Name: "nonDNS-task",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "replica-lessThanZero",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
 						{
-							MountPath:       "/var",
-							VolumeClaimName: "pvc2",
+							Name:     "Task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
-			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            " duplicated mountPath: /var;",
-			ExpectErr:      true,
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: false},
+			ret: "[a DNS-1123 label must consist of lower case alphanumeric characters or '-', and " +
+				"must start and end with an alphanumeric character (e.g. 'my-name',  " +
+				"or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')];",
+			ExpectErr: true,
 		},
+		// Policy Event with exit code
 		{
-			Name: "volume without VolumeClaimName and VolumeClaim",
+			Name: "job-policy-withExitCode",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "invalid-volume",
+					Name:      "job-policy-withExitCode",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -895,30 +929,64 @@ func TestValidateExecution(t *testing.T) {
 					},
 					Policies: []v1alpha1.LifecyclePolicy{
 						{
-							Event:  v1alpha1.AnyEvent,
-							Action: v1alpha1.AbortJobAction,
+							Event:    v1alpha1.PodFailedEvent,
+							Action:   v1alpha1.AbortJobAction,
+							ExitCode: &policyExitCode,
 						},
 					},
-					Volumes: []v1alpha1.VolumeSpec{
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "must not specify event and exitCode simultaneously",
+			ExpectErr:      true,
+		},
+		// Both policy event and exit code are nil
+		{
+			Name: "policy-noEvent-noExCode",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "policy-noEvent-noExCode",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
 						{
-							MountPath: "/var",
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
 						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
 						{
-							MountPath: "/var",
+							Action: v1alpha1.AbortJobAction,
 						},
 					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            " either VolumeClaim or VolumeClaimName must be specified;",
+			ret:            "either event and exitCode should be specified",
 			ExpectErr:      true,
 		},
-		// task Policy with any event and other events
+		// invalid policy event
 		{
-			Name: "taskpolicy-withAnyandOthrEvent",
+			Name: "invalid-policy-event",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "taskpolicy-withAnyandOthrEvent",
+					Name:      "invalid-policy-event",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -941,35 +1009,31 @@ func TestValidateExecution(t *testing.T) {
 									},
 								},
 							},
-							Policies: []v1alpha1.LifecyclePolicy{
-								{
-									Event:  v1alpha1.AnyEvent,
-									Action: v1alpha1.AbortJobAction,
-								},
-								{
-									Event:  v1alpha1.PodFailedEvent,
-									Action: v1alpha1.RestartJobAction,
-								},
-							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.Event("someFakeEvent"),
+							Action: v1alpha1.AbortJobAction,
 						},
 					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "if there's * here, no other policy should be here",
+			ret:            "invalid policy event",
 			ExpectErr:      true,
 		},
-		// job with no queue created
+		// invalid policy action
 		{
-			Name: "job-with-noQueue",
+			Name: "invalid-policy-action",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "job-with-noQueue",
+					Name:      "invalid-policy-action",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
 					MinAvailable: 1,
-					Queue:        "jobQueue",
+					Queue:        "default",
 					Tasks: []v1alpha1.TaskSpec{
 						{
 							Name:     "task-1",
@@ -989,17 +1053,24 @@ func TestValidateExecution(t *testing.T) {
 							},
 						},
 					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.PodEvictedEvent,
+							Action: v1alpha1.Action("someFakeAction"),
+						},
+					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "unable to find job queue",
+			ret:            "invalid policy action",
 			ExpectErr:      true,
 		},
+		// policy exit-code zero
 		{
-			Name: "job with priviledged container",
+			Name: "policy-extcode-zero",
 			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "valid-Job",
+					Name:      "policy-extcode-zero",
 					Namespace: namespace,
 				},
 				Spec: v1alpha1.JobSpec{
@@ -1018,51 +1089,458 @@ func TestValidateExecution(t *testing.T) {
 										{
 											Name:  "fake-name",
 											Image: "busybox:1.24",
-											SecurityContext: &v1.SecurityContext{
-												Privileged: &priviledged,
-											},
 										},
 									},
 								},
 							},
 						},
 					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Action: v1alpha1.AbortJobAction,
+							ExitCode: func(i int32) *int32 {
+								return &i
+							}(int32(0)),
+						},
+					},
 				},
 			},
 			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
-			ret:            "",
-			ExpectErr:      false,
+			ret:            "0 is not a valid error code",
+			ExpectErr:      true,
 		},
-	}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.Name, func(t *testing.T) {
-			defaultqueue := schedulingv1aplha2.Queue{
+		// duplicate policy exit-code
+		{
+			Name: "duplicate-exitcode",
+			Job: v1alpha1.Job{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "default",
-				},
-				Spec: schedulingv1aplha2.QueueSpec{
-					Weight: 1,
+					Name:      "duplicate-exitcode",
+					Namespace: namespace,
 				},
-			}
-			// create fake volcano clientset
-			config.VolcanoClient = fakeclient.NewSimpleClientset()
-
-			//create default queue
-			_, err := config.VolcanoClient.SchedulingV1alpha2().Queues().Create(&defaultqueue)
-			if err != nil {
-				t.Error("Queue Creation Failed")
-			}
-
-			ret := validateJob(&testCase.Job, &testCase.reviewResponse)
-			//fmt.Printf("test-case name:%s, ret:%v  testCase.reviewResponse:%v \n", testCase.Name, ret,testCase.reviewResponse)
-			if testCase.ExpectErr == true && ret == "" {
-				t.Errorf("Expect error msg :%s, but got nil.", testCase.ret)
-			}
-			if testCase.ExpectErr == true && testCase.reviewResponse.Allowed != false {
-				t.Errorf("Expect Allowed as false but got true.")
-			}
-			if testCase.ExpectErr == true && !strings.Contains(ret, testCase.ret) {
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							ExitCode: func(i int32) *int32 {
+								return &i
+							}(int32(1)),
+						},
+						{
+							ExitCode: func(i int32) *int32 {
+								return &i
+							}(int32(1)),
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "duplicate exitCode 1",
+			ExpectErr:      true,
+		},
+		// Policy with any event and other events
+		{
+			Name: "job-policy-withExitCode",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job-policy-withExitCode",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.AnyEvent,
+							Action: v1alpha1.AbortJobAction,
+						},
+						{
+							Event:  v1alpha1.PodFailedEvent,
+							Action: v1alpha1.RestartJobAction,
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "if there's * here, no other policy should be here",
+			ExpectErr:      true,
+		},
+		// invalid mount volume
+		{
+			Name: "invalid-mount-volume",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "invalid-mount-volume",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.AnyEvent,
+							Action: v1alpha1.AbortJobAction,
+						},
+					},
+					Volumes: []v1alpha1.VolumeSpec{
+						{
+							MountPath: "",
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            " mountPath is required;",
+			ExpectErr:      true,
+		},
+		// duplicate mount volume
+		{
+			Name: "duplicate-mount-volume",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "duplicate-mount-volume",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.AnyEvent,
+							Action: v1alpha1.AbortJobAction,
+						},
+					},
+					Volumes: []v1alpha1.VolumeSpec{
+						{
+							MountPath:       "/var",
+							VolumeClaimName: "pvc1",
+						},
+						{
+							MountPath:       "/var",
+							VolumeClaimName: "pvc2",
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            " duplicated mountPath: /var;",
+			ExpectErr:      true,
+		},
+		{
+			Name: "volume without VolumeClaimName and VolumeClaim",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "invalid-volume",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Event:  v1alpha1.AnyEvent,
+							Action: v1alpha1.AbortJobAction,
+						},
+					},
+					Volumes: []v1alpha1.VolumeSpec{
+						{
+							MountPath: "/var",
+						},
+						{
+							MountPath: "/var",
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            " either VolumeClaim or VolumeClaimName must be specified;",
+			ExpectErr:      true,
+		},
+		// task Policy with any event and other events
+		{
+			Name: "taskpolicy-withAnyandOthrEvent",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "taskpolicy-withAnyandOthrEvent",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+							Policies: []v1alpha1.LifecyclePolicy{
+								{
+									Event:  v1alpha1.AnyEvent,
+									Action: v1alpha1.AbortJobAction,
+								},
+								{
+									Event:  v1alpha1.PodFailedEvent,
+									Action: v1alpha1.RestartJobAction,
+								},
+							},
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "if there's * here, no other policy should be here",
+			ExpectErr:      true,
+		},
+		// job with no queue created
+		{
+			Name: "job-with-noQueue",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job-with-noQueue",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "jobQueue",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "unable to find job queue",
+			ExpectErr:      true,
+		},
+		{
+			Name: "job with priviledged container",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "valid-Job",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 1,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 1,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels: map[string]string{"name": "test"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+											SecurityContext: &v1.SecurityContext{
+												Privileged: &priviledged,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "",
+			ExpectErr:      false,
+		},
+		{
+			Name: "gang-job-with-task-moved-to-another-queue",
+			Job: v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "gang-job",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 2,
+					Queue:        "default",
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task-1",
+							Replicas: 2,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Labels:      map[string]string{"name": "test"},
+									Annotations: map[string]string{schedulingv1aplha2.QueueNameAnnotationKey: "other"},
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name:  "fake-name",
+											Image: "busybox:1.24",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			reviewResponse: v1beta1.AdmissionResponse{Allowed: true},
+			ret:            "the pods of task task-1 can not be moved to queue other",
+			ExpectErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			defaultqueue := schedulingv1aplha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Spec: schedulingv1aplha2.QueueSpec{
+					Weight: 1,
+				},
+			}
+			// create fake volcano clientset
+			config.VolcanoClient = fakeclient.NewSimpleClientset()
+			config.ValidateJobQueue = true
+
+			//create default queue
+			_, err := config.VolcanoClient.SchedulingV1alpha2().Queues().Create(&defaultqueue)
+			if err != nil {
+				t.Error("Queue Creation Failed")
+			}
+
+			ret, err := validateJob(&testCase.Job, &testCase.reviewResponse)
+			if err != nil {
+				t.Fatalf("Expect no internal error, but got %v", err)
+			}
+			//fmt.Printf("test-case name:%s, ret:%v  testCase.reviewResponse:%v \n", testCase.Name, ret,testCase.reviewResponse)
+			if testCase.ExpectErr == true && ret == "" {
+				t.Errorf("Expect error msg :%s, but got nil.", testCase.ret)
+			}
+			if testCase.ExpectErr == true && testCase.reviewResponse.Allowed != false {
+				t.Errorf("Expect Allowed as false but got true.")
+			}
+			if testCase.ExpectErr == true && !strings.Contains(ret, testCase.ret) {
 				t.Errorf("Expect error msg :%s, but got diff error %v", testCase.ret, ret)
 			}
 
@@ -1075,3 +1553,602 @@ func TestValidateExecution(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateJobQueueLookup(t *testing.T) {
+	job := v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.JobSpec{
+			MinAvailable: 1,
+			Queue:        "missing",
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name:     "task-1",
+					Replicas: 1,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:  "fake-name",
+									Image: "busybox:1.24",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name          string
+		QueueErr      error
+		ExpectInvalid bool
+		ExpectErr     bool
+	}{
+		{
+			Name:          "queue not found",
+			ExpectInvalid: true,
+		},
+		{
+			Name:      "queue lookup failed",
+			QueueErr:  fmt.Errorf("apiserver unavailable"),
+			ExpectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset()
+			if testCase.QueueErr != nil {
+				client.PrependReactor("get", "queues", func(action kubetesting.Action) (bool, runtime.Object, error) {
+					return true, nil, testCase.QueueErr
+				})
+			}
+			config.VolcanoClient = client
+			config.ValidateJobQueue = true
+
+			reviewResponse := v1beta1.AdmissionResponse{Allowed: true}
+			msg, err := validateJob(&job, &reviewResponse)
+			if (err != nil) != testCase.ExpectErr {
+				t.Errorf("expected internal error %v, got %v", testCase.ExpectErr, err)
+			}
+			if testCase.ExpectInvalid && (reviewResponse.Allowed || !strings.Contains(msg, "unable to find job queue")) {
+				t.Errorf("expected job to be rejected for missing queue, got allowed %v: %s", reviewResponse.Allowed, msg)
+			}
+		})
+	}
+}
+
+func TestValidateJobQueue(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, queue := range []*schedulingv1aplha2.Queue{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status:     schedulingv1aplha2.QueueStatus{State: schedulingv1aplha2.QueueStateOpen},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "closed"},
+			Status:     schedulingv1aplha2.QueueStatus{State: schedulingv1aplha2.QueueStateClosed},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cordoned",
+				Annotations: map[string]string{schedulingv1aplha2.CordonedAnnotationKey: "true"},
+			},
+			Status: schedulingv1aplha2.QueueStatus{State: schedulingv1aplha2.QueueStateOpen},
+		},
+	} {
+		if err := indexer.Add(queue); err != nil {
+			t.Fatalf("failed to add queue %s: %v", queue.Name, err)
+		}
+	}
+
+	config.VolcanoClient = fakeclient.NewSimpleClientset()
+	config.QueueLister = schedulinglisters.NewQueueLister(indexer)
+	defer func() {
+		config.QueueLister = nil
+		config.ValidateJobQueue = false
+	}()
+
+	testCases := []struct {
+		Name     string
+		Queue    string
+		Disabled bool
+		ret      string
+	}{
+		{
+			Name:  "open queue",
+			Queue: "default",
+		},
+		{
+			Name: "empty queue is the default queue",
+		},
+		{
+			Name:  "closed queue",
+			Queue: "closed",
+			ret:   "job queue closed is closed;",
+		},
+		{
+			Name:  "cordoned queue",
+			Queue: "cordoned",
+			ret:   "job queue cordoned is cordoned;",
+		},
+		{
+			Name:  "missing queue",
+			Queue: "missing",
+			ret:   "unable to find job queue",
+		},
+		{
+			Name:     "missing queue without validation",
+			Queue:    "missing",
+			Disabled: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			config.ValidateJobQueue = !testCase.Disabled
+			job := &v1alpha1.Job{
+				Spec: v1alpha1.JobSpec{Queue: testCase.Queue},
+			}
+
+			ret, err := validateJobQueue(job)
+			if err != nil {
+				t.Fatalf("expected no internal error, but got %v", err)
+			}
+			if testCase.ret == "" && ret != "" {
+				t.Errorf("expected no error, but got %s", ret)
+			}
+			if !strings.Contains(ret, testCase.ret) {
+				t.Errorf("expected error %s, but got %s", testCase.ret, ret)
+			}
+		})
+	}
+}
+
+func TestValidateJobCapability(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&schedulingv1aplha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: schedulingv1aplha2.QueueSpec{
+			Capability: v1.ResourceList{
+				v1.ResourceCPU:   resource.MustParse("8"),
+				"nvidia.com/gpu": resource.MustParse("2"),
+			},
+		},
+	}); err != nil {
+		t.Fatalf("failed to add queue: %v", err)
+	}
+	if err := indexer.Add(&schedulingv1aplha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "percent"},
+		Spec: schedulingv1aplha2.QueueSpec{
+			CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 10},
+		},
+		Status: schedulingv1aplha2.QueueStatus{
+			Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+		},
+	}); err != nil {
+		t.Fatalf("failed to add queue: %v", err)
+	}
+
+	config.VolcanoClient = fakeclient.NewSimpleClientset()
+	config.QueueLister = schedulinglisters.NewQueueLister(indexer)
+	defer func() {
+		config.QueueLister = nil
+		config.ValidateJobCapability = false
+	}()
+
+	task := func(name string, replicas int32, requests, limits v1.ResourceList) v1alpha1.TaskSpec {
+		return v1alpha1.TaskSpec{
+			Name:     name,
+			Replicas: replicas,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Resources: v1.ResourceRequirements{Requests: requests, Limits: limits},
+					}},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name         string
+		Queue        string
+		Tasks        []v1alpha1.TaskSpec
+		MinAvailable int32
+		Disabled     bool
+		ret          string
+	}{
+		{
+			Name:         "fits capability",
+			Tasks:        []v1alpha1.TaskSpec{task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, nil)},
+			MinAvailable: 4,
+		},
+		{
+			Name:         "exceeds cpu capability",
+			Tasks:        []v1alpha1.TaskSpec{task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}, nil)},
+			MinAvailable: 4,
+			ret:          "job requires at least cpu 12 but capability of queue default is cpu 8;",
+		},
+		{
+			Name: "gpu limit is counted without request",
+			Tasks: []v1alpha1.TaskSpec{task("worker", 3, v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")})},
+			MinAvailable: 3,
+			ret:          "job requires at least nvidia.com/gpu 3 but capability of queue default is nvidia.com/gpu 2;",
+		},
+		{
+			Name: "cheapest pods are counted",
+			Tasks: []v1alpha1.TaskSpec{
+				task("ps", 1, v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")}, nil),
+				task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, nil),
+			},
+			MinAvailable: 4,
+		},
+		{
+			Name:         "disabled",
+			Tasks:        []v1alpha1.TaskSpec{task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}, nil)},
+			MinAvailable: 4,
+			Disabled:     true,
+		},
+		{
+			Name:         "exceeds capability resolved by queue controller",
+			Queue:        "percent",
+			Tasks:        []v1alpha1.TaskSpec{task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}, nil)},
+			MinAvailable: 4,
+			ret:          "job requires at least cpu 8 but capability of queue percent is cpu 4;",
+		},
+		{
+			Name:         "missing queue is left to queue validation",
+			Queue:        "missing",
+			Tasks:        []v1alpha1.TaskSpec{task("worker", 4, v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}, nil)},
+			MinAvailable: 4,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			config.ValidateJobCapability = !testCase.Disabled
+			job := &v1alpha1.Job{
+				Spec: v1alpha1.JobSpec{
+					Queue:        testCase.Queue,
+					Tasks:        testCase.Tasks,
+					MinAvailable: testCase.MinAvailable,
+				},
+			}
+
+			ret, err := validateJobCapability(job)
+			if err != nil {
+				t.Fatalf("expected no internal error, but got %v", err)
+			}
+			if testCase.ret == "" && ret != "" {
+				t.Errorf("expected no error, but got %s", ret)
+			}
+			if !strings.Contains(ret, testCase.ret) {
+				t.Errorf("expected error %s, but got %s", testCase.ret, ret)
+			}
+		})
+	}
+}
+
+func TestValidateTaskDependencies(t *testing.T) {
+	task := func(name string, replicas int32, dependsOn ...string) v1alpha1.TaskSpec {
+		return v1alpha1.TaskSpec{Name: name, Replicas: replicas, DependsOn: dependsOn}
+	}
+
+	testCases := []struct {
+		Name         string
+		Tasks        []v1alpha1.TaskSpec
+		MinAvailable int32
+		ret          string
+	}{
+		{
+			Name:         "tasks without dependencies",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2)},
+			MinAvailable: 3,
+		},
+		{
+			Name:         "chain of dependencies",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2, "ps"), task("evaluator", 1, "ps", "worker")},
+			MinAvailable: 1,
+		},
+		{
+			Name:         "unknown task",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2, "master")},
+			MinAvailable: 1,
+			ret:          "unknown task master in dependsOn of task worker;",
+		},
+		{
+			Name:         "task depends on itself",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2, "worker")},
+			MinAvailable: 1,
+			ret:          "dependency cycle in tasks: worker -> worker;",
+		},
+		{
+			Name:         "cycle of tasks",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2, "ps", "evaluator"), task("evaluator", 1, "worker")},
+			MinAvailable: 1,
+			ret:          "dependency cycle in tasks: worker -> evaluator -> worker;",
+		},
+		{
+			Name:         "minAvailable includes dependent tasks",
+			Tasks:        []v1alpha1.TaskSpec{task("ps", 1), task("worker", 2, "ps")},
+			MinAvailable: 3,
+			ret:          "requested minAvailable is 3, replicas are 1;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			job := &v1alpha1.Job{
+				Spec: v1alpha1.JobSpec{MinAvailable: testCase.MinAvailable, Tasks: testCase.Tasks},
+			}
+
+			err := validateTaskDependencies(job)
+			if testCase.ret == "" {
+				if err != nil {
+					t.Errorf("expected no error, but got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.ret) {
+				t.Errorf("expected error %s, but got %v", testCase.ret, err)
+			}
+		})
+	}
+}
+
+type fakePolicy struct {
+	decision policy.Decision
+	checked  []string
+}
+
+func (p *fakePolicy) Check(request *policy.Request) *policy.Decision {
+	p.checked = append(p.checked, request.Namespace+"/"+request.Name)
+	return &p.decision
+}
+
+func TestAdmitJobsPolicy(t *testing.T) {
+	newJob := func(name string, minAvailable int32) v1alpha1.Job {
+		return v1alpha1.Job{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Job",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+			},
+			Spec: v1alpha1.JobSpec{
+				MinAvailable: minAvailable,
+				Queue:        "default",
+				Tasks: []v1alpha1.TaskSpec{
+					{
+						Name:     "task-1",
+						Replicas: 1,
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name:  "fake-name",
+										Image: "busybox:1.24",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name          string
+		Job           v1alpha1.Job
+		Decision      policy.Decision
+		ExpectAllowed bool
+		ExpectChecked bool
+		ExpectMsg     string
+	}{
+		{
+			Name:          "allowed by both",
+			Job:           newJob("job1", 1),
+			Decision:      policy.Decision{Allowed: true},
+			ExpectAllowed: true,
+			ExpectChecked: true,
+		},
+		{
+			Name:          "denied by external policy",
+			Job:           newJob("job2", 1),
+			Decision:      policy.Decision{Allowed: false, Reason: "missing cost-center label"},
+			ExpectChecked: true,
+			ExpectMsg:     "denied by external policy: missing cost-center label",
+		},
+		{
+			Name:      "denied by volcano",
+			Job:       newJob("job3", 0),
+			Decision:  policy.Decision{Allowed: true},
+			ExpectMsg: "'minAvailable' must be greater than zero.",
+		},
+	}
+
+	validateJobQueue, validateJobCapability := config.ValidateJobQueue, config.ValidateJobCapability
+	config.ValidateJobQueue, config.ValidateJobCapability = false, false
+	config.VolcanoClient = fakeclient.NewSimpleClientset()
+	defer func() {
+		config.ValidateJobQueue, config.ValidateJobCapability = validateJobQueue, validateJobCapability
+		config.JobPolicy = nil
+	}()
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			checker := &fakePolicy{decision: testCase.Decision}
+			config.JobPolicy = checker
+
+			raw, err := json.Marshal(testCase.Job)
+			if err != nil {
+				t.Fatalf("failed to encode job: %v", err)
+			}
+			response := AdmitJobs(v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Operation: v1beta1.Create,
+					Resource: metav1.GroupVersionResource{
+						Group:    v1alpha1.SchemeGroupVersion.Group,
+						Version:  v1alpha1.SchemeGroupVersion.Version,
+						Resource: "jobs",
+					},
+					Object: runtime.RawExtension{Raw: raw},
+				},
+			})
+
+			if response.Allowed != testCase.ExpectAllowed {
+				t.Errorf("expected allowed %v, got %v", testCase.ExpectAllowed, response.Result)
+			}
+			if checked := len(checker.checked) == 1; checked != testCase.ExpectChecked {
+				t.Errorf("expected external policy checked %v, got %v", testCase.ExpectChecked, checker.checked)
+			}
+			if testCase.ExpectMsg != "" && (response.Result == nil || response.Result.Message != testCase.ExpectMsg) {
+				t.Errorf("expected message %q, got %v", testCase.ExpectMsg, response.Result)
+			}
+		})
+	}
+}
+
+func TestAdmitJobsUpdate(t *testing.T) {
+	newJob := func(image string, tasks ...string) *v1alpha1.Job {
+		job := &v1alpha1.Job{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Job",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "job1",
+				Namespace: "test",
+			},
+			Spec: v1alpha1.JobSpec{
+				MinAvailable: 1,
+				Queue:        "default",
+			},
+		}
+		for _, name := range tasks {
+			job.Spec.Tasks = append(job.Spec.Tasks, v1alpha1.TaskSpec{
+				Name:     name,
+				Replicas: 1,
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{{Name: "fake-name", Image: image}},
+					},
+				},
+			})
+		}
+		return job
+	}
+	withPlugins := newJob("busybox:1.24", "task-1")
+	withPlugins.Spec.Plugins = map[string][]string{"ssh": {}}
+	suspendedWithPlugins := withPlugins.DeepCopy()
+	suspendedWithPlugins.Spec.Suspend = true
+	suspendedWithPlugins.Spec.Policies = []v1alpha1.LifecyclePolicy{{
+		Event:  v1alpha1.PodEvictedEvent,
+		Action: v1alpha1.ResumeJobAction,
+	}}
+
+	testCases := []struct {
+		Name          string
+		Job           *v1alpha1.Job
+		ExpectAllowed bool
+		ExpectMsg     string
+	}{
+		{
+			Name:          "image changed",
+			Job:           newJob("busybox:1.31", "task-1"),
+			ExpectAllowed: true,
+		},
+		{
+			Name:      "task added",
+			Job:       newJob("busybox:1.24", "task-1", "task-2"),
+			ExpectMsg: "tasks may not be added or removed;",
+		},
+		{
+			Name:      "task renamed",
+			Job:       newJob("busybox:1.24", "task-2"),
+			ExpectMsg: "task task-1 may not be renamed or reordered;",
+		},
+		{
+			Name:      "plugins changed",
+			Job:       withPlugins,
+			ExpectMsg: "plugins may not be changed;",
+		},
+		{
+			Name:      "suspended with resume policy and plugins changed",
+			Job:       suspendedWithPlugins,
+			ExpectMsg: "policy action ResumeJob conflicts with 'suspend'; plugins may not be changed;",
+		},
+	}
+
+	config.JobPolicy = nil
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			oldRaw, err := json.Marshal(newJob("busybox:1.24", "task-1"))
+			if err != nil {
+				t.Fatalf("failed to encode job: %v", err)
+			}
+			raw, err := json.Marshal(testCase.Job)
+			if err != nil {
+				t.Fatalf("failed to encode job: %v", err)
+			}
+			response := AdmitJobs(v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Operation: v1beta1.Update,
+					Resource: metav1.GroupVersionResource{
+						Group:    v1alpha1.SchemeGroupVersion.Group,
+						Version:  v1alpha1.SchemeGroupVersion.Version,
+						Resource: "jobs",
+					},
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			})
+
+			if response.Allowed != testCase.ExpectAllowed {
+				t.Errorf("expected allowed %v, got %v", testCase.ExpectAllowed, response.Result)
+			}
+			if testCase.ExpectMsg != "" && (response.Result == nil || response.Result.Message != testCase.ExpectMsg) {
+				t.Errorf("expected message %q, got %v", testCase.ExpectMsg, response.Result)
+			}
+		})
+	}
+}
+
+func TestAdmitJobsAudit(t *testing.T) {
+	var buf bytes.Buffer
+	config.AuditSink = audit.NewJSONSink(&buf)
+	defer func() { config.AuditSink = nil }()
+
+	response := AdmitJobs(v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Operation: v1beta1.Create,
+			Resource: metav1.GroupVersionResource{
+				Group:    v1alpha1.SchemeGroupVersion.Group,
+				Version:  v1alpha1.SchemeGroupVersion.Version,
+				Resource: "jobs",
+			},
+			Name:   "job1",
+			Object: runtime.RawExtension{Raw: []byte("{")},
+		},
+	})
+	if response.Allowed {
+		t.Fatalf("expected undecodable job to be rejected")
+	}
+
+	record := &audit.Record{}
+	if err := json.Unmarshal(buf.Bytes(), record); err != nil {
+		t.Fatalf("expected an audit record of the undecodable job, got %q: %v", buf.String(), err)
+	}
+	if record.Name != "job1" || record.Decision != audit.DecisionRejected {
+		t.Errorf("expected job1 rejected in the audit record, got %+v", record)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/api/core/v1"
+
+	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+)
+
+// taskResourcePolicy is the resources which the pods of a task must or must not request.
+type taskResourcePolicy struct {
+	// Required resources must be requested by at least one container of the task.
+	Required []v1.ResourceName `json:"required,omitempty"`
+	// Forbidden resources must not be requested or limited by any container of the task.
+	Forbidden []v1.ResourceName `json:"forbidden,omitempty"`
+}
+
+// validateTaskResourcePolicy checks the tasks of the job against the resource policies of
+// the job annotation, so that e.g. GPU tasks request GPUs and the other tasks do not.
+func validateTaskResourcePolicy(job *v1alpha1.Job) string {
+	value, found := job.Annotations[v1alpha1.TaskResourcePolicyAnnotationKey]
+	if !found {
+		return ""
+	}
+
+	policies := map[string]taskResourcePolicy{}
+	if err := json.Unmarshal([]byte(value), &policies); err != nil {
+		return fmt.Sprintf(" invalid annotation %s: %v;", v1alpha1.TaskResourcePolicyAnnotationKey, err)
+	}
+
+	tasks := map[string]v1alpha1.TaskSpec{}
+	for _, task := range job.Spec.Tasks {
+		tasks[task.Name] = task
+	}
+
+	var names []string
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var msg string
+	for _, name := range names {
+		task, found := tasks[name]
+		if !found {
+			msg += fmt.Sprintf(" annotation %s has a policy of unknown task %s;",
+				v1alpha1.TaskResourcePolicyAnnotationKey, name)
+			continue
+		}
+
+		policy := policies[name]
+		requested := requestedResources(task.Template.Spec)
+		for _, rn := range policy.Required {
+			if !requested[rn] {
+				msg += fmt.Sprintf(" task %s must request %s by the resource policy of the job;", name, rn)
+			}
+		}
+		for _, rn := range policy.Forbidden {
+			if requested[rn] {
+				msg += fmt.Sprintf(" task %s must not request %s by the resource policy of the job;", name, rn)
+			}
+		}
+	}
+
+	return msg
+}
+
+// requestedResources returns the resources which any container of the pod requests or limits
+// by a quantity greater than zero.
+func requestedResources(spec v1.PodSpec) map[v1.ResourceName]bool {
+	requested := map[v1.ResourceName]bool{}
+	for _, containers := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			for _, list := range []v1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+				for rn, quantity := range list {
+					if quantity.Sign() > 0 {
+						requested[rn] = true
+					}
+				}
+			}
+		}
+	}
+
+	return requested
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+)
+
+func TestValidateTaskResourcePolicy(t *testing.T) {
+	newTask := func(name string, requests, limits v1.ResourceList) v1alpha1.TaskSpec {
+		return v1alpha1.TaskSpec{
+			Name:     name,
+			Replicas: 1,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:      "main",
+						Resources: v1.ResourceRequirements{Requests: requests, Limits: limits},
+					}},
+				},
+			},
+		}
+	}
+	cpu := v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}
+	gpu := v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+	tasks := []v1alpha1.TaskSpec{
+		newTask("ps", cpu, nil),
+		newTask("worker", cpu, gpu),
+	}
+
+	testCases := []struct {
+		name       string
+		annotation string
+		tasks      []v1alpha1.TaskSpec
+		expect     string
+	}{
+		{
+			name:  "no policy",
+			tasks: []v1alpha1.TaskSpec{newTask("ps", gpu, nil)},
+		},
+		{
+			name:       "policies are satisfied",
+			annotation: `{"worker":{"required":["nvidia.com/gpu"]},"ps":{"forbidden":["nvidia.com/gpu"]}}`,
+			tasks:      tasks,
+		},
+		{
+			name:       "required resource is not requested",
+			annotation: `{"ps":{"required":["nvidia.com/gpu"]}}`,
+			tasks:      tasks,
+			expect:     " task ps must request nvidia.com/gpu by the resource policy of the job;",
+		},
+		{
+			name:       "forbidden resource is limited",
+			annotation: `{"worker":{"forbidden":["nvidia.com/gpu"]}}`,
+			tasks:      tasks,
+			expect:     " task worker must not request nvidia.com/gpu by the resource policy of the job;",
+		},
+		{
+			name:       "forbidden resource of zero quantity",
+			annotation: `{"ps":{"forbidden":["nvidia.com/gpu"]}}`,
+			tasks:      []v1alpha1.TaskSpec{newTask("ps", v1.ResourceList{"nvidia.com/gpu": resource.MustParse("0")}, nil)},
+		},
+		{
+			name:       "policy of unknown task",
+			annotation: `{"chief":{"required":["nvidia.com/gpu"]}}`,
+			tasks:      tasks,
+			expect:     " annotation volcano.sh/task-resource-policy has a policy of unknown task chief;",
+		},
+		{
+			name:       "malformed annotation",
+			annotation: `worker=nvidia.com/gpu`,
+			tasks:      tasks,
+			expect:     " invalid annotation volcano.sh/task-resource-policy: invalid character 'w' looking for beginning of value;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		job := &v1alpha1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+			Spec:       v1alpha1.JobSpec{Tasks: testCase.tasks},
+		}
+		if testCase.annotation != "" {
+			job.Annotations = map[string]string{v1alpha1.TaskResourcePolicyAnnotationKey: testCase.annotation}
+		}
+
+		if msg := validateTaskResourcePolicy(job); msg != testCase.expect {
+			t.Errorf("case %s: expected %q, got %q", testCase.name, testCase.expect, msg)
+		}
+	}
+}
@@ -18,13 +18,21 @@ package validate
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kubernetes/pkg/apis/core/validation"
 
 	batchv1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	"volcano.sh/volcano/pkg/controllers/job/plugins"
+	pluginsinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
 )
 
 // policyEventMap defines all policy events and whether to allow external use
@@ -32,6 +40,7 @@ var policyEventMap = map[batchv1alpha1.Event]bool{
 	batchv1alpha1.AnyEvent:           true,
 	batchv1alpha1.PodFailedEvent:     true,
 	batchv1alpha1.PodEvictedEvent:    true,
+	batchv1alpha1.PodOOMKilledEvent:  true,
 	batchv1alpha1.JobUnknownEvent:    true,
 	batchv1alpha1.TaskCompletedEvent: true,
 	batchv1alpha1.OutOfSyncEvent:     false,
@@ -48,6 +57,7 @@ var policyActionMap = map[batchv1alpha1.Action]bool{
 	batchv1alpha1.ResumeJobAction:    true,
 	batchv1alpha1.SyncJobAction:      false,
 	batchv1alpha1.EnqueueAction:      false,
+	batchv1alpha1.SuspendJobAction:   false,
 }
 
 func validatePolicies(policies []batchv1alpha1.LifecyclePolicy, fldPath *field.Path) error {
@@ -157,6 +167,58 @@ func getValidActions() []batchv1alpha1.Action {
 	return actions
 }
 
+// validateSuspend checks that a suspended job has no policy resuming it, as a suspended job
+// is only resumed by unsetting spec.suspend.
+func validateSuspend(job *batchv1alpha1.Job) error {
+	if !job.Spec.Suspend {
+		return nil
+	}
+
+	policies := job.Spec.Policies
+	for _, task := range job.Spec.Tasks {
+		policies = append(policies, task.Policies...)
+	}
+	for _, policy := range policies {
+		if policy.Action == batchv1alpha1.ResumeJobAction {
+			return fmt.Errorf(" policy action %s conflicts with 'suspend';", policy.Action)
+		}
+	}
+
+	return nil
+}
+
+// validateJobUpdate rejects the updates of the job spec which the pods created again from the new
+// spec can not pick up: the tasks are matched with their pods by name, and the volumes, plugins
+// and completion mode are only set up when the job is created.
+func validateJobUpdate(old, job *batchv1alpha1.Job) error {
+	var msg string
+
+	if len(old.Spec.Tasks) != len(job.Spec.Tasks) {
+		msg += " tasks may not be added or removed;"
+	} else {
+		for i := range job.Spec.Tasks {
+			if old.Spec.Tasks[i].Name != job.Spec.Tasks[i].Name {
+				msg += fmt.Sprintf(" task %s may not be renamed or reordered;", old.Spec.Tasks[i].Name)
+				break
+			}
+		}
+	}
+	if !reflect.DeepEqual(old.Spec.Volumes, job.Spec.Volumes) {
+		msg += " volumes may not be changed;"
+	}
+	if !reflect.DeepEqual(old.Spec.Plugins, job.Spec.Plugins) {
+		msg += " plugins may not be changed;"
+	}
+	if old.Spec.CompletionMode != job.Spec.CompletionMode {
+		msg += " completionMode may not be changed;"
+	}
+
+	if msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
 // validateIO validates IO configuration
 func validateIO(volumes []batchv1alpha1.VolumeSpec) error {
 	volumeMap := map[string]bool{}
@@ -184,3 +246,191 @@ func validateIO(volumes []batchv1alpha1.VolumeSpec) error {
 	}
 	return nil
 }
+
+// validateTaskDependencies validates that the dependencies of tasks are known tasks without cycles,
+// and that minAvailable can be reached by the tasks without dependencies, as the pods of the other
+// tasks are not created until their dependencies are running.
+func validateTaskDependencies(job *batchv1alpha1.Job) error {
+	dependencies := map[string][]string{}
+	var independentReplicas int32
+	for _, task := range job.Spec.Tasks {
+		dependencies[task.Name] = task.DependsOn
+		if len(task.DependsOn) == 0 {
+			independentReplicas += task.Replicas
+		}
+	}
+
+	for _, task := range job.Spec.Tasks {
+		for _, dependency := range task.DependsOn {
+			if _, found := dependencies[dependency]; !found {
+				return fmt.Errorf(" unknown task %s in dependsOn of task %s;", dependency, task.Name)
+			}
+		}
+	}
+
+	// Depth first search, the tasks on the current path are visiting, a cycle is found
+	// once a visiting task is reached again.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := map[string]int{}
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch states[name] {
+		case visiting:
+			for i := range path {
+				if path[i] == name {
+					path = path[i:]
+					break
+				}
+			}
+			return fmt.Errorf(" dependency cycle in tasks: %s -> %s;", strings.Join(path, " -> "), name)
+		case visited:
+			return nil
+		}
+
+		states[name] = visiting
+		path = append(path, name)
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[name] = visited
+
+		return nil
+	}
+	for _, task := range job.Spec.Tasks {
+		if err := visit(task.Name); err != nil {
+			return err
+		}
+	}
+
+	if independentReplicas < job.Spec.MinAvailable {
+		return fmt.Errorf(" 'minAvailable' should not be greater than the replicas of tasks without dependsOn: "+
+			"requested minAvailable is %d, replicas are %d;", job.Spec.MinAvailable, independentReplicas)
+	}
+
+	return nil
+}
+
+// minRequired returns the least amount of the resource which minAvailable pods of the job
+// request, whichever pods are chosen; it is a lower bound of the minResources of the job
+// as the pods counted by job controller depend on the priority of tasks.
+func minRequired(job *batchv1alpha1.Job, name v1.ResourceName) resource.Quantity {
+	type taskRequest struct {
+		replicas int32
+		request  resource.Quantity
+	}
+
+	var requests []taskRequest
+	for _, task := range job.Spec.Tasks {
+		requests = append(requests, taskRequest{
+			replicas: task.Replicas,
+			request:  helpers.GetPodRequests(&task.Template.Spec)[name],
+		})
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].request.Cmp(requests[j].request) < 0
+	})
+
+	var total resource.Quantity
+	remaining := job.Spec.MinAvailable
+	for _, r := range requests {
+		if remaining <= 0 {
+			break
+		}
+		n := r.replicas
+		if n > remaining {
+			n = remaining
+		}
+		for i := int32(0); i < n; i++ {
+			total.Add(r.request)
+		}
+		remaining -= n
+	}
+
+	return total
+}
+
+// exceededCapability returns the resources which the job provably requires beyond the capability,
+// together with the capability of them, both formatted as "name quantity" sorted by name.
+func exceededCapability(job *batchv1alpha1.Job, capability v1.ResourceList) (string, string) {
+	var names []string
+	for name := range capability {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var required, limits []string
+	for _, name := range names {
+		limit := capability[v1.ResourceName(name)]
+		need := minRequired(job, v1.ResourceName(name))
+		if need.Cmp(limit) > 0 {
+			required = append(required, fmt.Sprintf("%s %s", name, need.String()))
+			limits = append(limits, fmt.Sprintf("%s %s", name, limit.String()))
+		}
+	}
+
+	return strings.Join(required, ", "), strings.Join(limits, ", ")
+}
+
+// validateCompletionMode checks that the completion mode of the job is known.
+func validateCompletionMode(job *batchv1alpha1.Job) error {
+	switch job.Spec.CompletionMode {
+	case "", batchv1alpha1.NonIndexedCompletion, batchv1alpha1.IndexedCompletion:
+		return nil
+	}
+
+	return fmt.Errorf(" invalid completionMode %q, valid modes are %s and %s;", job.Spec.CompletionMode,
+		batchv1alpha1.NonIndexedCompletion, batchv1alpha1.IndexedCompletion)
+}
+
+// injectedVolumes returns the volumes mounted into the pods of the job by its plugins, e.g. the
+// ssh secret, which the containers of its tasks may reference without declaring them.
+func injectedVolumes(job *batchv1alpha1.Job) map[string]bool {
+	volumes := map[string]bool{}
+	for name, arguments := range job.Spec.Plugins {
+		builder, found := plugins.GetPluginBuilder(name)
+		if !found {
+			continue
+		}
+		injector, ok := builder(pluginsinterface.PluginClientset{}, arguments).(pluginsinterface.VolumeInjector)
+		if !ok {
+			continue
+		}
+		for _, volume := range injector.InjectedVolumes(job) {
+			volumes[volume] = true
+		}
+	}
+
+	return volumes
+}
+
+// validateVolumeMounts checks that the volume mounts of the containers of the task reference
+// the volumes of its template or the volumes injected by the plugins of the job.
+func validateVolumeMounts(task batchv1alpha1.TaskSpec, injected map[string]bool) error {
+	declared := map[string]bool{}
+	for _, volume := range task.Template.Spec.Volumes {
+		declared[volume.Name] = true
+	}
+
+	var dangling []string
+	for _, containers := range [][]v1.Container{task.Template.Spec.InitContainers, task.Template.Spec.Containers} {
+		for _, container := range containers {
+			for _, mount := range container.VolumeMounts {
+				if !declared[mount.Name] && !injected[mount.Name] {
+					dangling = append(dangling, fmt.Sprintf("%s of container %s", mount.Name, container.Name))
+				}
+			}
+		}
+	}
+	if len(dangling) > 0 {
+		return fmt.Errorf(" undeclared volume mounts in task %s: %s;", task.Name, strings.Join(dangling, ", "))
+	}
+
+	return nil
+}
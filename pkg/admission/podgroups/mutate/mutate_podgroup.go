@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/api/admission/v1beta1"
+	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/admission/router"
+	"volcano.sh/volcano/pkg/admission/schema"
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func init() {
+	router.RegisterAdmission(service)
+}
+
+var service = &router.AdmissionService{
+	Path: "/podgroups/mutate",
+	Func: MutatePodGroups,
+
+	Config: config,
+
+	MutatingConfig: &whv1beta1.MutatingWebhookConfiguration{
+		Webhooks: []whv1beta1.Webhook{{
+			Name: "mutatepodgroup.volcano.sh",
+			Rules: []whv1beta1.RuleWithOperations{
+				{
+					Operations: []whv1beta1.OperationType{whv1beta1.Create},
+					Rule: whv1beta1.Rule{
+						APIGroups:   []string{schedulingv1alpha2.SchemeGroupVersion.Group},
+						APIVersions: []string{schedulingv1alpha2.SchemeGroupVersion.Version},
+						Resources:   []string{"podgroups"},
+					},
+				},
+			},
+		}},
+	},
+}
+
+var config = &router.AdmissionServiceConfig{}
+
+type patchOperation = util.PatchOperation
+
+// MutatePodGroups defaults the minResources of podgroups from their queue
+func MutatePodGroups(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	klog.V(3).Infof("mutating podgroups")
+
+	podGroup, err := schema.DecodePodGroup(ar.Request.Object, ar.Request.Resource)
+	if err != nil {
+		return util.ToAdmissionResponse(err)
+	}
+
+	reviewResponse := v1beta1.AdmissionResponse{}
+	reviewResponse.Allowed = true
+
+	switch ar.Request.Operation {
+	case v1beta1.Create:
+		// Nothing to default leaves the patch empty, the podgroup is admitted as is.
+		err = createPatch(podGroup).WriteResponse(&reviewResponse)
+	default:
+		err = fmt.Errorf("expect operation to be 'CREATE' ")
+		return util.ToAdmissionResponse(err)
+	}
+
+	if err != nil {
+		reviewResponse.Result = &metav1.Status{Message: err.Error()}
+		return &reviewResponse
+	}
+	klog.V(3).Infof("AdmissionResponse: patch=%v\n", string(reviewResponse.Patch))
+
+	return &reviewResponse
+}
+
+func createPatch(podGroup *schedulingv1alpha2.PodGroup) *util.PatchBuilder {
+	patch := util.NewJSONPatchBuilder()
+	if podGroup.Spec.MinResources != nil && len(*podGroup.Spec.MinResources) != 0 {
+		return patch
+	}
+
+	if minResources := defaultMinResources(podGroup); len(minResources) != 0 {
+		patch.Add("/spec/minResources", minResources)
+	}
+	return patch
+}
+
+// defaultMinResources returns the default minResources from the annotation of the queue of the podgroup,
+// nil if the queue or the annotation is absent; a malformed annotation is ignored, as the podgroup is
+// still valid without minResources.
+func defaultMinResources(podGroup *schedulingv1alpha2.PodGroup) v1.ResourceList {
+	if config.QueueLister == nil || podGroup.Spec.Queue == "" {
+		return nil
+	}
+
+	queue, err := config.QueueLister.Get(podGroup.Spec.Queue)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to get Queue %s of PodGroup <%s/%s>: %v",
+				podGroup.Spec.Queue, podGroup.Namespace, podGroup.Name, err)
+		}
+		return nil
+	}
+
+	value, found := queue.Annotations[schedulingv1alpha2.DefaultMinResourcesAnnotationKey]
+	if !found {
+		return nil
+	}
+
+	minResources := v1.ResourceList{}
+	if err := json.Unmarshal([]byte(value), &minResources); err != nil {
+		klog.Errorf("Failed to parse annotation %s of Queue %s: %v",
+			schedulingv1alpha2.DefaultMinResourcesAnnotationKey, queue.Name, err)
+		return nil
+	}
+
+	return minResources
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+)
+
+func TestCreatePatch(t *testing.T) {
+	newQueue := func(name, minResources string) *schedulingv1alpha2.Queue {
+		queue := &schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if minResources != "" {
+			queue.Annotations = map[string]string{schedulingv1alpha2.DefaultMinResourcesAnnotationKey: minResources}
+		}
+		return queue
+	}
+	newPodGroup := func(queue string, minResources *v1.ResourceList) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "default"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: queue, MinResources: minResources},
+		}
+	}
+
+	queueIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, queue := range []*schedulingv1alpha2.Queue{
+		newQueue("defaulted", `{"cpu":"1","memory":"1Gi"}`),
+		newQueue("no-annotation", ""),
+		newQueue("malformed", `cpu=1`),
+	} {
+		if err := queueIndexer.Add(queue); err != nil {
+			t.Fatalf("add queue failed for %v", err)
+		}
+	}
+	config.QueueLister = schedulinglisters.NewQueueLister(queueIndexer)
+	defer func() {
+		config.QueueLister = nil
+	}()
+
+	testCases := []struct {
+		Name     string
+		PodGroup *schedulingv1alpha2.PodGroup
+		Expect   []patchOperation
+	}{
+		{
+			Name:     "default absent minResources",
+			PodGroup: newPodGroup("defaulted", nil),
+			Expect: []patchOperation{
+				{
+					Op:   "add",
+					Path: "/spec/minResources",
+					Value: map[string]interface{}{
+						"cpu":    "1",
+						"memory": "1Gi",
+					},
+				},
+			},
+		},
+		{
+			Name:     "default empty minResources",
+			PodGroup: newPodGroup("defaulted", &v1.ResourceList{}),
+			Expect: []patchOperation{
+				{
+					Op:   "add",
+					Path: "/spec/minResources",
+					Value: map[string]interface{}{
+						"cpu":    "1",
+						"memory": "1Gi",
+					},
+				},
+			},
+		},
+		{
+			Name:     "keep specified minResources",
+			PodGroup: newPodGroup("defaulted", &v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}),
+			Expect:   nil,
+		},
+		{
+			Name:     "queue without annotation",
+			PodGroup: newPodGroup("no-annotation", nil),
+			Expect:   nil,
+		},
+		{
+			Name:     "queue with malformed annotation",
+			PodGroup: newPodGroup("malformed", nil),
+			Expect:   nil,
+		},
+		{
+			Name:     "queue not found",
+			PodGroup: newPodGroup("missing", nil),
+			Expect:   nil,
+		},
+		{
+			Name:     "podgroup without queue",
+			PodGroup: newPodGroup("", nil),
+			Expect:   nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			patchBytes, err := createPatch(testCase.PodGroup).Build()
+			if err != nil {
+				t.Fatalf("create patch failed for %v", err)
+			}
+
+			var patch []patchOperation
+			if patchBytes != nil {
+				if err := json.Unmarshal(patchBytes, &patch); err != nil {
+					t.Fatalf("unmarshal patch failed for %v", err)
+				}
+			}
+			if !reflect.DeepEqual(patch, testCase.Expect) {
+				t.Errorf("expected patch %v, got %v", testCase.Expect, patch)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+
+	"k8s.io/api/admission/v1beta1"
+	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/admission/router"
+	"volcano.sh/volcano/pkg/admission/schema"
+	"volcano.sh/volcano/pkg/admission/util"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func init() {
+	router.RegisterAdmission(service)
+}
+
+var service = &router.AdmissionService{
+	Path: "/podgroups/validate",
+	Func: AdmitPodGroups,
+
+	Config: config,
+
+	ValidatingConfig: &whv1beta1.ValidatingWebhookConfiguration{
+		Webhooks: []whv1beta1.Webhook{{
+			Name: "validatepodgroup.volcano.sh",
+			Rules: []whv1beta1.RuleWithOperations{
+				{
+					Operations: []whv1beta1.OperationType{whv1beta1.Create, whv1beta1.Update},
+					Rule: whv1beta1.Rule{
+						APIGroups:   []string{schedulingv1alpha2.SchemeGroupVersion.Group},
+						APIVersions: []string{schedulingv1alpha2.SchemeGroupVersion.Version},
+						Resources:   []string{"podgroups"},
+					},
+				},
+			},
+		}},
+	},
+}
+
+var config = &router.AdmissionServiceConfig{}
+
+// AdmitPodGroups is to admit podgroups and return response
+func AdmitPodGroups(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	klog.V(3).Infof("admitting podgroups -- %s", ar.Request.Operation)
+
+	podGroup, err := schema.DecodePodGroup(ar.Request.Object, ar.Request.Resource)
+	if err != nil {
+		return util.ToAdmissionResponse(err)
+	}
+
+	var oldPodGroup *schedulingv1alpha2.PodGroup
+	switch ar.Request.Operation {
+	case v1beta1.Create:
+	case v1beta1.Update:
+		if oldPodGroup, err = schema.DecodePodGroup(ar.Request.OldObject, ar.Request.Resource); err != nil {
+			return util.ToAdmissionResponse(err)
+		}
+	default:
+		err := fmt.Errorf("expect operation to be 'CREATE' or 'UPDATE'")
+		return util.ToAdmissionResponse(err)
+	}
+
+	if noReclaim(podGroup) && (oldPodGroup == nil || !noReclaim(oldPodGroup)) {
+		if response := validateNoReclaim(podGroup); !response.Allowed {
+			return response
+		}
+	}
+
+	// The podgroup is updated by scheduler for its status as well, which must not be
+	// rejected when the job was scaled down afterwards.
+	if oldPodGroup != nil && oldPodGroup.Spec.MinMember == podGroup.Spec.MinMember {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	return validateMinMember(podGroup)
+}
+
+// noReclaim returns whether the podgroup is annotated to be exempt from reclaim.
+func noReclaim(podGroup *schedulingv1alpha2.PodGroup) bool {
+	return podGroup.Annotations[schedulingv1alpha2.NoReclaimAnnotationKey] == "true"
+}
+
+// validateNoReclaim rejects the podgroup exempt from reclaim unless its queue allows it, so that
+// the resources of a reclaimable queue can not be held against the others at will.
+func validateNoReclaim(podGroup *schedulingv1alpha2.PodGroup) *v1beta1.AdmissionResponse {
+	queueName := podGroup.Spec.Queue
+	if queueName == "" {
+		queueName = util.GetDefaultQueue(config.NamespaceLister, podGroup.Namespace)
+	}
+
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, queueName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return util.ToDeniedResponse(fmt.Sprintf("podgroup %s/%s can not be annotated with %s as its queue %s is not found",
+				podGroup.Namespace, podGroup.Name, schedulingv1alpha2.NoReclaimAnnotationKey, queueName))
+		}
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to get queue %s: %v", queueName, err))
+	}
+
+	if queue.Annotations[schedulingv1alpha2.AllowNoReclaimAnnotationKey] != "true" {
+		return util.ToDeniedResponse(fmt.Sprintf("podgroup %s/%s can not be annotated with %s as its queue %s "+
+			"is not annotated with %s=true", podGroup.Namespace, podGroup.Name, schedulingv1alpha2.NoReclaimAnnotationKey,
+			queueName, schedulingv1alpha2.AllowNoReclaimAnnotationKey))
+	}
+
+	return &v1beta1.AdmissionResponse{Allowed: true}
+}
+
+// validateMinMember rejects the podgroup of a job whose minMember exceeds the pods which the
+// job creates. Standalone podgroups are skipped, as their pods join them dynamically.
+func validateMinMember(podGroup *schedulingv1alpha2.PodGroup) *v1beta1.AdmissionResponse {
+	owner := jobOwner(podGroup)
+	if owner == nil {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	job, err := config.VolcanoClient.BatchV1alpha1().Jobs(podGroup.Namespace).Get(owner.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The podgroup is garbage collected with its job.
+			klog.V(3).Infof("Job %s/%s of PodGroup %s is not found, skip validating minMember.",
+				podGroup.Namespace, owner.Name, podGroup.Name)
+			return &v1beta1.AdmissionResponse{Allowed: true}
+		}
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to get job %s/%s: %v", podGroup.Namespace, owner.Name, err))
+	}
+
+	var replicas int32
+	for _, task := range job.Spec.Tasks {
+		replicas += task.Replicas
+	}
+	if podGroup.Spec.MinMember > replicas {
+		return util.ToDeniedResponse(fmt.Sprintf("'minMember' %d of podgroup %s/%s should not be greater than "+
+			"the %d replicas of tasks in its job %s", podGroup.Spec.MinMember, podGroup.Namespace, podGroup.Name,
+			replicas, job.Name))
+	}
+
+	return &v1beta1.AdmissionResponse{Allowed: true}
+}
+
+// jobOwner returns the controller reference of the podgroup if it is owned by a job, nil otherwise.
+func jobOwner(podGroup *schedulingv1alpha2.PodGroup) *metav1.OwnerReference {
+	owner := metav1.GetControllerOf(podGroup)
+	if owner == nil || owner.APIVersion != helpers.JobKind.GroupVersion().String() || owner.Kind != helpers.JobKind.Kind {
+		return nil
+	}
+
+	return owner
+}
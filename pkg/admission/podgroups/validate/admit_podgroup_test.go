@@ -0,0 +1,280 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	batchv1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcfake "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
+)
+
+func TestAdmitPodGroups(t *testing.T) {
+	job := &batchv1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job1"},
+		Spec: batchv1alpha1.JobSpec{
+			Tasks: []batchv1alpha1.TaskSpec{
+				{Name: "ps", Replicas: 1},
+				{Name: "worker", Replicas: 2},
+			},
+		},
+	}
+	vcClient := vcfake.NewSimpleClientset()
+	if _, err := vcClient.BatchV1alpha1().Jobs(job.Namespace).Create(job); err != nil {
+		t.Fatalf("create job failed for %v", err)
+	}
+	config.VolcanoClient = vcClient
+	defer func() { config.VolcanoClient = nil }()
+
+	newPodGroup := func(minMember int32, owner *batchv1alpha1.Job) *schedulingv1alpha2.PodGroup {
+		pg := &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pg1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{MinMember: minMember},
+		}
+		if owner != nil {
+			pg.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, helpers.JobKind)}
+		}
+		return pg
+	}
+	otherOwner := newPodGroup(10, nil)
+	otherOwner.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "StatefulSet",
+		Name:       "job1",
+		Controller: &[]bool{true}[0],
+	}}
+
+	testCases := []struct {
+		name          string
+		operation     v1beta1.Operation
+		oldPodGroup   *schedulingv1alpha2.PodGroup
+		podGroup      *schedulingv1alpha2.PodGroup
+		expectAllow   bool
+		expectMessage string
+	}{
+		{
+			name:        "minMember equal to replicas of job",
+			operation:   v1beta1.Create,
+			podGroup:    newPodGroup(3, job),
+			expectAllow: true,
+		},
+		{
+			name:          "minMember greater than replicas of job",
+			operation:     v1beta1.Create,
+			podGroup:      newPodGroup(4, job),
+			expectMessage: "'minMember' 4 of podgroup default/pg1 should not be greater than the 3 replicas",
+		},
+		{
+			name:        "standalone podgroup is skipped",
+			operation:   v1beta1.Create,
+			podGroup:    newPodGroup(10, nil),
+			expectAllow: true,
+		},
+		{
+			name:        "podgroup owned by other kind is skipped",
+			operation:   v1beta1.Create,
+			podGroup:    otherOwner,
+			expectAllow: true,
+		},
+		{
+			name:      "job not found is skipped",
+			operation: v1beta1.Create,
+			podGroup: newPodGroup(10, &batchv1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted"},
+			}),
+			expectAllow: true,
+		},
+		{
+			name:          "minMember increased beyond replicas of job",
+			operation:     v1beta1.Update,
+			oldPodGroup:   newPodGroup(3, job),
+			podGroup:      newPodGroup(4, job),
+			expectMessage: "should not be greater than the 3 replicas",
+		},
+		{
+			name:        "minMember unchanged",
+			operation:   v1beta1.Update,
+			oldPodGroup: newPodGroup(4, job),
+			podGroup:    newPodGroup(4, job),
+			expectAllow: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := &v1beta1.AdmissionRequest{
+			Resource: metav1.GroupVersionResource{
+				Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+				Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+				Resource: "podgroups",
+			},
+			Operation: testCase.operation,
+			Object:    rawExtension(t, testCase.podGroup),
+		}
+		if testCase.oldPodGroup != nil {
+			request.OldObject = rawExtension(t, testCase.oldPodGroup)
+		}
+
+		response := AdmitPodGroups(v1beta1.AdmissionReview{Request: request})
+		if response.Allowed != testCase.expectAllow {
+			t.Errorf("case %s: expected allowed %v, got %v: %v", testCase.name, testCase.expectAllow, response.Allowed, response.Result)
+			continue
+		}
+		if !testCase.expectAllow && !strings.Contains(response.Result.Message, testCase.expectMessage) {
+			t.Errorf("case %s: expected message containing %q, got %q", testCase.name, testCase.expectMessage, response.Result.Message)
+		}
+	}
+}
+
+func TestAdmitPodGroupsNoReclaim(t *testing.T) {
+	factory := informerfactory.NewSharedInformerFactory(vcfake.NewSimpleClientset(), 0)
+	queueInformer := factory.Scheduling().V1alpha2().Queues()
+	config.QueueLister = queueInformer.Lister()
+	defer func() { config.QueueLister = nil }()
+	namespaceInformer := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0).Core().V1().Namespaces()
+	config.NamespaceLister = namespaceInformer.Lister()
+	defer func() { config.NamespaceLister = nil }()
+
+	namespaceInformer.Informer().GetIndexer().Add(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "critical",
+			Annotations: map[string]string{batchv1alpha1.DefaultQueueAnnotationKey: "critical"},
+		},
+	})
+
+	queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "critical",
+			Annotations: map[string]string{schedulingv1alpha2.AllowNoReclaimAnnotationKey: "true"},
+		},
+	})
+	queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	})
+
+	newPodGroup := func(queue string, noReclaim bool) *schedulingv1alpha2.PodGroup {
+		pg := &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pg1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: queue},
+		}
+		if noReclaim {
+			pg.Annotations = map[string]string{schedulingv1alpha2.NoReclaimAnnotationKey: "true"}
+		}
+		return pg
+	}
+
+	testCases := []struct {
+		name          string
+		operation     v1beta1.Operation
+		oldPodGroup   *schedulingv1alpha2.PodGroup
+		podGroup      *schedulingv1alpha2.PodGroup
+		expectAllow   bool
+		expectMessage string
+	}{
+		{
+			name:        "queue allows no-reclaim",
+			operation:   v1beta1.Create,
+			podGroup:    newPodGroup("critical", true),
+			expectAllow: true,
+		},
+		{
+			name:          "queue does not allow no-reclaim",
+			operation:     v1beta1.Create,
+			podGroup:      newPodGroup("", true),
+			expectMessage: "its queue default is not annotated with volcano.sh/allow-no-reclaim=true",
+		},
+		{
+			name:      "default queue of namespace allows no-reclaim",
+			operation: v1beta1.Create,
+			podGroup: func() *schedulingv1alpha2.PodGroup {
+				pg := newPodGroup("", true)
+				pg.Namespace = "critical"
+				return pg
+			}(),
+			expectAllow: true,
+		},
+		{
+			name:          "queue not found",
+			operation:     v1beta1.Create,
+			podGroup:      newPodGroup("unknown", true),
+			expectMessage: "its queue unknown is not found",
+		},
+		{
+			name:        "podgroup without annotation",
+			operation:   v1beta1.Create,
+			podGroup:    newPodGroup("default", false),
+			expectAllow: true,
+		},
+		{
+			name:          "annotation added on update",
+			operation:     v1beta1.Update,
+			oldPodGroup:   newPodGroup("default", false),
+			podGroup:      newPodGroup("default", true),
+			expectMessage: "can not be annotated with volcano.sh/no-reclaim",
+		},
+		{
+			name:        "annotation kept on update",
+			operation:   v1beta1.Update,
+			oldPodGroup: newPodGroup("default", true),
+			podGroup:    newPodGroup("default", true),
+			expectAllow: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		request := &v1beta1.AdmissionRequest{
+			Resource: metav1.GroupVersionResource{
+				Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+				Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+				Resource: "podgroups",
+			},
+			Operation: testCase.operation,
+			Object:    rawExtension(t, testCase.podGroup),
+		}
+		if testCase.oldPodGroup != nil {
+			request.OldObject = rawExtension(t, testCase.oldPodGroup)
+		}
+
+		response := AdmitPodGroups(v1beta1.AdmissionReview{Request: request})
+		if response.Allowed != testCase.expectAllow {
+			t.Errorf("case %s: expected allowed %v, got %v: %v", testCase.name, testCase.expectAllow, response.Allowed, response.Result)
+			continue
+		}
+		if !testCase.expectAllow && !strings.Contains(response.Result.Message, testCase.expectMessage) {
+			t.Errorf("case %s: expected message containing %q, got %q", testCase.name, testCase.expectMessage, response.Result.Message)
+		}
+	}
+}
+
+func rawExtension(t *testing.T, podGroup *schedulingv1alpha2.PodGroup) runtime.RawExtension {
+	raw, err := json.Marshal(podGroup)
+	if err != nil {
+		t.Fatalf("marshal podgroup failed for %v", err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
@@ -18,6 +18,7 @@ package pods
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/api/admission/v1beta1"
@@ -25,6 +26,7 @@ import (
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/admission/router"
@@ -33,6 +35,7 @@ import (
 	"volcano.sh/volcano/pkg/apis/helpers"
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
 )
 
 func init() {
@@ -113,6 +116,16 @@ func validatePod(pod *v1.Pod, reviewResponse *v1beta1.AdmissionResponse) string
 		if err := checkPGPhase(pod, pgName, true); err != nil {
 			msg = err.Error()
 			reviewResponse.Allowed = false
+			return msg
+		}
+		if err := checkQueueCapability(pod, pgName); err != nil {
+			msg = err.Error()
+			reviewResponse.Allowed = false
+			return msg
+		}
+		if err := checkNamespaceQuota(pod, pgName); err != nil {
+			msg = err.Error()
+			reviewResponse.Allowed = false
 		}
 		return msg
 	}
@@ -122,6 +135,11 @@ func validatePod(pod *v1.Pod, reviewResponse *v1beta1.AdmissionResponse) string
 	if err := checkPGPhase(pod, pgName, false); err != nil {
 		msg = err.Error()
 		reviewResponse.Allowed = false
+		return msg
+	}
+	if err := checkNamespaceQuota(pod, pgName); err != nil {
+		msg = err.Error()
+		reviewResponse.Allowed = false
 	}
 
 	return msg
@@ -147,3 +165,170 @@ func checkPGPhase(pod *v1.Pod, pgName string, isVCJob bool) error {
 	return fmt.Errorf("failed to create pod <%s/%s> as the podgroup phase is Pending",
 		pod.Namespace, pod.Name)
 }
+
+// checkQueueCapability rejects pods requesting more resources than the capability of their queue,
+// such pods can never be scheduled.
+func checkQueueCapability(pod *v1.Pod, pgName string) error {
+	if config.PodGroupLister == nil || config.QueueLister == nil {
+		return nil
+	}
+
+	queue, err := getQueue(pod, pgName)
+	if err != nil || queue == nil {
+		return err
+	}
+
+	requests := helpers.GetPodRequests(&pod.Spec)
+	var exceeded []string
+	for name, capability := range queuehelpers.EffectiveCapability(queue) {
+		request, found := requests[name]
+		if found && request.Cmp(capability) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s %s > %s", name, request.String(), capability.String()))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	sort.Strings(exceeded)
+
+	return fmt.Errorf("failed to create pod <%s/%s> as its requests exceed the capability of queue %s: %s",
+		pod.Namespace, pod.Name, queue.Name, strings.Join(exceeded, ", "))
+}
+
+// checkNamespaceQuota rejects pods whose requests, together with the requests of the other pods of
+// the namespace in the queue, exceed the quota of the namespace in the queue; the pods are admitted
+// if the queue has no quota for the namespace. The pods of a podgroup are created in parallel, so
+// each of them is charged with the minResources of the podgroup not used by its pods yet, which
+// admits or rejects them as one. Normal pods without a podgroup yet are checked against the queue
+// of their annotation.
+func checkNamespaceQuota(pod *v1.Pod, pgName string) error {
+	if config.PodGroupLister == nil || config.QueueLister == nil || config.PodLister == nil {
+		return nil
+	}
+
+	queueName := pod.Annotations[v1alpha2.QueueNameAnnotationKey]
+	var minResources v1.ResourceList
+	pg, err := config.PodGroupLister.PodGroups(pod.Namespace).Get(pgName)
+	if err == nil {
+		queueName = pg.Spec.Queue
+		if pg.Spec.MinResources != nil {
+			minResources = *pg.Spec.MinResources
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get PodGroup for pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+	}
+	if queueName == "" {
+		return nil
+	}
+
+	queue, err := config.QueueLister.Get(queueName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Queue %s for pod <%s/%s>: %v", queueName, pod.Namespace, pod.Name, err)
+	}
+	quota, found := queue.Spec.NamespaceQuotas[pod.Namespace]
+	if !found {
+		return nil
+	}
+
+	used, podGroupUsed, err := namespaceUsage(pod.Namespace, queue.Name, pgName)
+	if err != nil {
+		return fmt.Errorf("failed to get the usage of namespace %s in queue %s for pod <%s/%s>: %v",
+			pod.Namespace, queue.Name, pod.Namespace, pod.Name, err)
+	}
+
+	requests := helpers.GetPodRequests(&pod.Spec)
+	var exceeded []string
+	for name, limit := range quota {
+		request, found := requests[name]
+		if remaining, ok := minResources[name]; ok {
+			remaining.Sub(podGroupUsed[name])
+			if remaining.Cmp(request) > 0 {
+				request, found = remaining, true
+			}
+		}
+		if !found {
+			continue
+		}
+		usage := used[name]
+		total := usage.DeepCopy()
+		total.Add(request)
+		if total.Cmp(limit) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s %s + %s > %s", name, usage.String(), request.String(), limit.String()))
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+	sort.Strings(exceeded)
+
+	return fmt.Errorf("failed to create pod <%s/%s> as its requests exceed the quota of namespace %s in queue %s: %s",
+		pod.Namespace, pod.Name, pod.Namespace, queue.Name, strings.Join(exceeded, ", "))
+}
+
+// namespaceUsage returns the resources requested by the pods of the namespace in the queue, and the
+// resources requested by the pods of the podgroup among them; the terminated pods are not counted
+// as they do not hold resources any more.
+func namespaceUsage(namespace, queue, pgName string) (v1.ResourceList, v1.ResourceList, error) {
+	podGroups, err := config.PodGroupLister.PodGroups(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, nil, err
+	}
+	inQueue := map[string]bool{}
+	for _, pg := range podGroups {
+		if pg.Spec.Queue == queue {
+			inQueue[pg.Name] = true
+		}
+	}
+
+	pods, err := config.PodLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, nil, err
+	}
+	usage := v1.ResourceList{}
+	podGroupUsage := v1.ResourceList{}
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		name := pod.Annotations[v1alpha2.GroupNameAnnotationKey]
+		if !inQueue[name] {
+			continue
+		}
+		for resourceName, quantity := range helpers.GetPodRequests(&pod.Spec) {
+			used := usage[resourceName]
+			used.Add(quantity)
+			usage[resourceName] = used
+			if name == pgName {
+				used := podGroupUsage[resourceName]
+				used.Add(quantity)
+				podGroupUsage[resourceName] = used
+			}
+		}
+	}
+
+	return usage, podGroupUsage, nil
+}
+
+// getQueue returns the queue of the podgroup of the pod from the informer caches, nil if the
+// podgroup or the queue is not found.
+func getQueue(pod *v1.Pod, pgName string) (*v1alpha2.Queue, error) {
+	pg, err := config.PodGroupLister.PodGroups(pod.Namespace).Get(pgName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get PodGroup for pod <%s/%s>: %v", pod.Namespace, pod.Name, err)
+	}
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, pg.Spec.Queue)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get Queue %s for pod <%s/%s>: %v", pg.Spec.Queue, pod.Namespace, pod.Name, err)
+	}
+
+	return queue, nil
+}
@@ -22,10 +22,14 @@ import (
 
 	"k8s.io/api/admission/v1beta1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
 )
 
 func TestValidatePod(t *testing.T) {
@@ -180,3 +184,389 @@ func TestValidatePod(t *testing.T) {
 		}
 	}
 }
+
+func buildPod(name, pgName string, requests, initRequests v1.ResourceList) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "test",
+			Name:        name,
+			Annotations: map[string]string{v1alpha2.GroupNameAnnotationKey: pgName},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "c1", Resources: v1.ResourceRequirements{Requests: requests}},
+				{Name: "c2", Resources: v1.ResourceRequirements{Requests: requests}},
+			},
+		},
+	}
+	if initRequests != nil {
+		pod.Spec.InitContainers = []v1.Container{
+			{Name: "init", Resources: v1.ResourceRequirements{Requests: initRequests}},
+		}
+	}
+	return pod
+}
+
+func buildNormalPod(name, queue string, requests v1.ResourceList) *v1.Pod {
+	pod := buildPod(name, "", requests, nil)
+	pod.Annotations = map[string]string{v1alpha2.QueueNameAnnotationKey: queue}
+	return pod
+}
+
+func TestCheckQueueCapability(t *testing.T) {
+	queueIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pgIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, queue := range []*v1alpha2.Queue{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "capped"},
+			Spec: v1alpha2.QueueSpec{
+				Capability: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "capped-decimal"},
+			Spec: v1alpha2.QueueSpec{
+				Capability: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("1500m"),
+					v1.ResourceMemory: resource.MustParse("4G"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "percent"},
+			Spec: v1alpha2.QueueSpec{
+				CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 10},
+			},
+			Status: v1alpha2.QueueStatus{
+				Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlimited"},
+		},
+	} {
+		if err := queueIndexer.Add(queue); err != nil {
+			t.Fatalf("failed to add queue %s: %v", queue.Name, err)
+		}
+	}
+	for _, pg := range []*v1alpha2.PodGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-capped"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "capped"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-capped-decimal"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "capped-decimal"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-percent"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "percent"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-unlimited"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "unlimited"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-missing-queue"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "missing"},
+		},
+	} {
+		if err := pgIndexer.Add(pg); err != nil {
+			t.Fatalf("failed to add podgroup %s: %v", pg.Name, err)
+		}
+	}
+
+	config.QueueLister = schedulinglisters.NewQueueLister(queueIndexer)
+	config.PodGroupLister = schedulinglisters.NewPodGroupLister(pgIndexer)
+	defer func() {
+		config.QueueLister = nil
+		config.PodGroupLister = nil
+	}()
+
+	testCases := []struct {
+		Name      string
+		Pod       *v1.Pod
+		ExpectErr bool
+		ret       string
+	}{
+		{
+			Name: "requests within capability",
+			Pod: buildPod("p1", "pg-capped", v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			}, nil),
+		},
+		{
+			Name: "requests exceed capability",
+			Pod: buildPod("p2", "pg-capped", v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "exceed the capability of queue capped: cpu 6 > 4",
+		},
+		{
+			Name: "init container exceeds capability",
+			Pod: buildPod("p3", "pg-capped", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1"),
+			}, v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			}),
+			ExpectErr: true,
+			ret:       "memory 8Gi > 4Gi",
+		},
+		{
+			Name: "decimal requests within binary capability",
+			Pod: buildPod("p8", "pg-capped", v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2000m"),
+				v1.ResourceMemory: resource.MustParse("2G"),
+			}, nil),
+		},
+		{
+			Name: "binary requests exceed decimal capability",
+			Pod: buildPod("p9", "pg-capped-decimal", v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("750m"),
+				v1.ResourceMemory: resource.MustParse("2Gi"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "exceed the capability of queue capped-decimal: memory 4Gi > 4G",
+		},
+		{
+			Name: "whole cpu requests exceed milli cpu capability",
+			Pod: buildPod("p10", "pg-capped-decimal", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "cpu 2 > 1500m",
+		},
+		{
+			Name: "requests exceed capability resolved by queue controller",
+			Pod: buildPod("p11", "pg-percent", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("1500m"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "exceed the capability of queue percent: cpu 3 > 2",
+		},
+		{
+			Name: "resource not capped by queue",
+			Pod: buildPod("p4", "pg-capped", v1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("8"),
+			}, nil),
+		},
+		{
+			Name: "queue without capability",
+			Pod: buildPod("p5", "pg-unlimited", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+		{
+			Name: "podgroup not found",
+			Pod: buildPod("p6", "pg-unknown", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+		{
+			Name: "queue not found",
+			Pod: buildPod("p7", "pg-missing-queue", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := checkQueueCapability(testCase.Pod, testCase.Pod.Annotations[v1alpha2.GroupNameAnnotationKey])
+			if testCase.ExpectErr != (err != nil) {
+				t.Fatalf("expected error %v, but got %v", testCase.ExpectErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), testCase.ret) {
+				t.Errorf("expected error msg %s, but got %v", testCase.ret, err)
+			}
+		})
+	}
+}
+
+func TestCheckNamespaceQuota(t *testing.T) {
+	queueIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pgIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, queue := range []*v1alpha2.Queue{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+			Spec: v1alpha2.QueueSpec{
+				NamespaceQuotas: map[string]v1.ResourceList{
+					"test": {v1.ResourceCPU: resource.MustParse("8")},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other"},
+			Spec: v1alpha2.QueueSpec{
+				NamespaceQuotas: map[string]v1.ResourceList{
+					"team-a": {v1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "unlimited"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gang"},
+			Spec: v1alpha2.QueueSpec{
+				NamespaceQuotas: map[string]v1.ResourceList{
+					"test": {v1.ResourceCPU: resource.MustParse("8")},
+				},
+			},
+		},
+	} {
+		if err := queueIndexer.Add(queue); err != nil {
+			t.Fatalf("failed to add queue %s: %v", queue.Name, err)
+		}
+	}
+	for _, pg := range []*v1alpha2.PodGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-shared"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "shared"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-other"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "other"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-unlimited"},
+			Spec:       v1alpha2.PodGroupSpec{Queue: "unlimited"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-gang"},
+			Spec: v1alpha2.PodGroupSpec{
+				Queue:        "shared",
+				MinResources: &v1.ResourceList{v1.ResourceCPU: resource.MustParse("7")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pg-started-gang"},
+			Spec: v1alpha2.PodGroupSpec{
+				Queue:        "gang",
+				MinResources: &v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")},
+			},
+		},
+	} {
+		if err := pgIndexer.Add(pg); err != nil {
+			t.Fatalf("failed to add podgroup %s: %v", pg.Name, err)
+		}
+	}
+	// 2 cpu of each running pod is counted in queue shared, the others are not.
+	running := buildPod("running", "pg-shared", v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil)
+	running.Status.Phase = v1.PodRunning
+	succeeded := buildPod("succeeded", "pg-shared", v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}, nil)
+	succeeded.Status.Phase = v1.PodSucceeded
+	otherQueue := buildPod("other-queue", "pg-unlimited", v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}, nil)
+	otherNamespace := buildPod("other-namespace", "pg-shared", v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}, nil)
+	otherNamespace.Namespace = "team-a"
+	// 2 cpu of the running member of pg-started-gang are part of its minResources.
+	gangMember := buildPod("gang-member", "pg-started-gang", v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil)
+	gangMember.Status.Phase = v1.PodRunning
+	for _, pod := range []*v1.Pod{running, succeeded, otherQueue, otherNamespace, gangMember} {
+		if err := podIndexer.Add(pod); err != nil {
+			t.Fatalf("failed to add pod %s: %v", pod.Name, err)
+		}
+	}
+
+	config.QueueLister = schedulinglisters.NewQueueLister(queueIndexer)
+	config.PodGroupLister = schedulinglisters.NewPodGroupLister(pgIndexer)
+	config.PodLister = corelisters.NewPodLister(podIndexer)
+	defer func() {
+		config.QueueLister = nil
+		config.PodGroupLister = nil
+		config.PodLister = nil
+	}()
+
+	testCases := []struct {
+		Name      string
+		Pod       *v1.Pod
+		ExpectErr bool
+		ret       string
+	}{
+		{
+			Name: "requests within quota",
+			Pod: buildPod("p1", "pg-shared", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("3"),
+			}, nil),
+		},
+		{
+			Name: "requests exceed quota",
+			Pod: buildPod("p2", "pg-shared", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("3500m"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "exceed the quota of namespace test in queue shared: cpu 2 + 7 > 8",
+		},
+		{
+			Name: "resource not limited by quota",
+			Pod: buildPod("p3", "pg-shared", v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("64Gi"),
+			}, nil),
+		},
+		{
+			Name: "namespace without quota",
+			Pod: buildPod("p4", "pg-other", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+		{
+			Name: "queue without quotas",
+			Pod: buildPod("p5", "pg-unlimited", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+		{
+			Name: "podgroup not found",
+			Pod: buildPod("p6", "pg-unknown", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}, nil),
+		},
+		{
+			Name: "minResources of podgroup exceed quota",
+			Pod: buildPod("p7", "pg-gang", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("500m"),
+			}, nil),
+			ExpectErr: true,
+			ret:       "exceed the quota of namespace test in queue shared: cpu 2 + 7 > 8",
+		},
+		{
+			Name: "minResources of podgroup partly used by its pods",
+			Pod: buildPod("p8", "pg-started-gang", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("500m"),
+			}, nil),
+		},
+		{
+			Name: "normal pod exceeds quota of annotated queue",
+			Pod: buildNormalPod("p9", "shared", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("4"),
+			}),
+			ExpectErr: true,
+			ret:       "exceed the quota of namespace test in queue shared: cpu 2 + 8 > 8",
+		},
+		{
+			Name: "normal pod without queue",
+			Pod: buildNormalPod("p10", "", v1.ResourceList{
+				v1.ResourceCPU: resource.MustParse("64"),
+			}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := checkNamespaceQuota(testCase.Pod, testCase.Pod.Annotations[v1alpha2.GroupNameAnnotationKey])
+			if testCase.ExpectErr != (err != nil) {
+				t.Fatalf("expected error %v, but got %v", testCase.ExpectErr, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), testCase.ret) {
+				t.Errorf("expected error msg %s, but got %v", testCase.ret, err)
+			}
+		})
+	}
+}
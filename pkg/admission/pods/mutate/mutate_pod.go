@@ -0,0 +1,222 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"fmt"
+
+	"k8s.io/api/admission/v1beta1"
+	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/admission/router"
+	"volcano.sh/volcano/pkg/admission/schema"
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func init() {
+	router.RegisterAdmission(service)
+}
+
+var service = &router.AdmissionService{
+	Path: "/pods/mutate",
+	Func: MutatePods,
+
+	Config: config,
+
+	MutatingConfig: &whv1beta1.MutatingWebhookConfiguration{
+		Webhooks: []whv1beta1.Webhook{{
+			Name: "mutatepod.volcano.sh",
+			Rules: []whv1beta1.RuleWithOperations{
+				{
+					Operations: []whv1beta1.OperationType{whv1beta1.Create},
+					Rule: whv1beta1.Rule{
+						APIGroups:   []string{""},
+						APIVersions: []string{"v1"},
+						Resources:   []string{"pods"},
+					},
+				},
+			},
+		}},
+	},
+}
+
+var config = &router.AdmissionServiceConfig{}
+
+// groupNamePath is the JSON pointer of the group name annotation of pods.
+const groupNamePath = "/metadata/annotations/scheduling.k8s.io~1group-name"
+
+// MutatePods moves the pods annotated with a queue to a podgroup of that queue.
+func MutatePods(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	klog.V(3).Infof("mutating pods -- %s", ar.Request.Operation)
+
+	pod, err := schema.DecodePod(ar.Request.Object, ar.Request.Resource)
+	if err != nil {
+		return util.ToAdmissionResponse(err)
+	}
+
+	switch ar.Request.Operation {
+	case v1beta1.Create:
+		return overrideQueue(pod)
+	default:
+		err = fmt.Errorf("expect operation to be 'CREATE' ")
+		return util.ToAdmissionResponse(err)
+	}
+}
+
+// overrideQueue validates the queue annotated on the pod, and assigns the pod of a podgroup in
+// another queue to the podgroup of the annotated queue, which is created if it does not exist.
+// Pods of a gang podgroup are not moved, and neither are pods to a queue not accepting podgroups.
+// Normal pods without a podgroup are left to the podgroup controller, which creates their podgroup
+// in the annotated queue.
+func overrideQueue(pod *v1.Pod) *v1beta1.AdmissionResponse {
+	queueName := pod.Annotations[schedulingv1alpha2.QueueNameAnnotationKey]
+	if pod.Spec.SchedulerName != config.SchedulerName || queueName == "" {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, queueName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return util.ToDeniedResponse(fmt.Sprintf("queue %s of pod <%s/%s> annotated by %s is not found",
+				queueName, pod.Namespace, pod.Name, schedulingv1alpha2.QueueNameAnnotationKey))
+		}
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to get queue %s: %v", queueName, err))
+	}
+
+	pgName := pod.Annotations[schedulingv1alpha2.GroupNameAnnotationKey]
+	if pgName == "" {
+		if msg := validateQueueState(queue); msg != "" {
+			return util.ToDeniedResponse(fmt.Sprintf("pod <%s/%s>: %s", pod.Namespace, pod.Name, msg))
+		}
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	podGroup, err := getPodGroup(pod.Namespace, pgName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return util.ToDeniedResponse(fmt.Sprintf("podgroup %s of pod <%s/%s> is not found",
+				pgName, pod.Namespace, pod.Name))
+		}
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to get podgroup %s/%s: %v", pod.Namespace, pgName, err))
+	}
+	if podGroup.Spec.Queue == queueName {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+	// The podgroup moved to is scheduled one by one, a member of a gang in it never joins the gang;
+	// the jobs webhook rejects such jobs on creation.
+	if podGroup.Spec.MinMember > 1 {
+		return util.ToDeniedResponse(fmt.Sprintf("pod <%s/%s> of podgroup %s with minMember %d can not be moved to queue %s",
+			pod.Namespace, pod.Name, pgName, podGroup.Spec.MinMember, queueName))
+	}
+	if msg := validateQueueState(queue); msg != "" {
+		return util.ToDeniedResponse(fmt.Sprintf("pod <%s/%s>: %s", pod.Namespace, pod.Name, msg))
+	}
+
+	override, err := createOverridePodGroup(podGroup, queueName, pod)
+	if err != nil {
+		return util.ToInternalErrorResponse(err)
+	}
+
+	reviewResponse := &v1beta1.AdmissionResponse{Allowed: true}
+	patch := util.NewJSONPatchBuilder()
+	patch.Replace(groupNamePath, override.Name)
+	if err := patch.WriteResponse(reviewResponse); err != nil {
+		return util.ToInternalErrorResponse(err)
+	}
+	klog.V(3).Infof("Pod <%s/%s> is moved from podgroup %s to %s of queue %s.",
+		pod.Namespace, pod.Name, pgName, override.Name, queueName)
+
+	return reviewResponse
+}
+
+// validateQueueState returns why the queue does not accept new podgroups, as the jobs webhook does.
+func validateQueueState(queue *schedulingv1alpha2.Queue) string {
+	if queue.DeletionTimestamp != nil {
+		return fmt.Sprintf("queue %s is being deleted", queue.Name)
+	}
+	// The queue closed as idle is opened again by the new podgroup.
+	if queue.Status.State == schedulingv1alpha2.QueueStateClosed &&
+		queue.Annotations[schedulingv1alpha2.IdleClosedAnnotationKey] != "true" {
+		return fmt.Sprintf("queue %s is closed", queue.Name)
+	}
+	if queue.Annotations[schedulingv1alpha2.CordonedAnnotationKey] == "true" {
+		return fmt.Sprintf("queue %s is cordoned", queue.Name)
+	}
+
+	return ""
+}
+
+// overridePodGroupName returns the name of the podgroup in the queue for the pods of podGroup.
+func overridePodGroupName(podGroup *schedulingv1alpha2.PodGroup, queue string) string {
+	return podGroup.Name + "-" + queue
+}
+
+// createOverridePodGroup creates the podgroup in the queue for the pods of podGroup if it does not exist.
+// The pods of the podgroup are scheduled one by one, and it is garbage collected with the owner of podGroup.
+func createOverridePodGroup(podGroup *schedulingv1alpha2.PodGroup, queue string, pod *v1.Pod) (*schedulingv1alpha2.PodGroup, error) {
+	name := overridePodGroupName(podGroup, queue)
+	if existing, err := getPodGroup(podGroup.Namespace, name); err == nil {
+		return existing, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get podgroup %s/%s: %v", podGroup.Namespace, name, err)
+	}
+
+	priorityClassName := pod.Spec.PriorityClassName
+	if priorityClassName == "" {
+		priorityClassName = podGroup.Spec.PriorityClassName
+	}
+	override := &schedulingv1alpha2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       podGroup.Namespace,
+			Name:            name,
+			OwnerReferences: podGroup.OwnerReferences,
+		},
+		Spec: schedulingv1alpha2.PodGroupSpec{
+			MinMember:         1,
+			Queue:             queue,
+			PriorityClassName: priorityClassName,
+		},
+	}
+
+	created, err := config.VolcanoClient.SchedulingV1alpha2().PodGroups(podGroup.Namespace).Create(override)
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return override, nil
+		}
+		return nil, fmt.Errorf("failed to create podgroup %s/%s: %v", podGroup.Namespace, name, err)
+	}
+
+	return created, nil
+}
+
+// getPodGroup gets the podgroup from the cache, and from apiserver if it is not in the cache yet,
+// as the podgroups of jobs are created right before their pods.
+func getPodGroup(namespace, name string) (*schedulingv1alpha2.PodGroup, error) {
+	if config.PodGroupLister != nil {
+		podGroup, err := config.PodGroupLister.PodGroups(namespace).Get(name)
+		if err == nil || !apierrors.IsNotFound(err) {
+			return podGroup, err
+		}
+	}
+
+	return config.VolcanoClient.SchedulingV1alpha2().PodGroups(namespace).Get(name, metav1.GetOptions{})
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+)
+
+func TestOverrideQueue(t *testing.T) {
+	namespace := "test"
+	isController := true
+	owner := metav1.OwnerReference{
+		APIVersion: "batch.volcano.sh/v1alpha1",
+		Kind:       "Job",
+		Name:       "job1",
+		UID:        "7a09885b-b753-4924-9fba-77c0836bac20",
+		Controller: &isController,
+	}
+
+	newPod := func(schedulerName, pgName, queue string) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        "job1-sidecar-0",
+				Annotations: map[string]string{},
+			},
+			Spec: v1.PodSpec{SchedulerName: schedulerName},
+		}
+		if pgName != "" {
+			pod.Annotations[schedulingv1alpha2.GroupNameAnnotationKey] = pgName
+		}
+		if queue != "" {
+			pod.Annotations[schedulingv1alpha2.QueueNameAnnotationKey] = queue
+		}
+		return pod
+	}
+
+	queueIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, name := range []string{"default", "preemptible"} {
+		queueIndexer.Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	queueIndexer.Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "closed"},
+		Status:     schedulingv1alpha2.QueueStatus{State: schedulingv1alpha2.QueueStateClosed},
+	})
+	queueIndexer.Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "idle",
+			Annotations: map[string]string{schedulingv1alpha2.IdleClosedAnnotationKey: "true"},
+		},
+		Status: schedulingv1alpha2.QueueStatus{State: schedulingv1alpha2.QueueStateClosed},
+	})
+	queueIndexer.Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cordoned",
+			Annotations: map[string]string{schedulingv1alpha2.CordonedAnnotationKey: "true"},
+		},
+	})
+	config.QueueLister = schedulinglisters.NewQueueLister(queueIndexer)
+	config.SchedulerName = "volcano"
+	defer func() {
+		config.QueueLister = nil
+		config.SchedulerName = ""
+		config.VolcanoClient = nil
+	}()
+
+	testCases := []struct {
+		name           string
+		pod            *v1.Pod
+		expectAllowed  bool
+		expectPatch    []util.PatchOperation
+		expectPodGroup string
+		existing       bool
+	}{
+		{
+			name:          "pod without queue annotation",
+			pod:           newPod("volcano", "job1", ""),
+			expectAllowed: true,
+		},
+		{
+			name:          "pod of other scheduler",
+			pod:           newPod("default-scheduler", "job1", "preemptible"),
+			expectAllowed: true,
+		},
+		{
+			name:          "queue not found",
+			pod:           newPod("volcano", "job1", "unknown"),
+			expectAllowed: false,
+		},
+		{
+			name:          "normal pod left to podgroup controller",
+			pod:           newPod("volcano", "", "preemptible"),
+			expectAllowed: true,
+		},
+		{
+			name:          "podgroup not found",
+			pod:           newPod("volcano", "job2", "preemptible"),
+			expectAllowed: false,
+		},
+		{
+			name:          "queue of the podgroup",
+			pod:           newPod("volcano", "job1", "default"),
+			expectAllowed: true,
+		},
+		{
+			name:          "normal pod to closed queue",
+			pod:           newPod("volcano", "", "closed"),
+			expectAllowed: false,
+		},
+		{
+			name:          "gang pod not moved",
+			pod:           newPod("volcano", "gang1", "preemptible"),
+			expectAllowed: false,
+		},
+		{
+			name:          "pod not moved to closed queue",
+			pod:           newPod("volcano", "job1", "closed"),
+			expectAllowed: false,
+		},
+		{
+			name:          "pod not moved to cordoned queue",
+			pod:           newPod("volcano", "job1", "cordoned"),
+			expectAllowed: false,
+		},
+		{
+			name:          "pod moved to queue closed as idle",
+			pod:           newPod("volcano", "job1", "idle"),
+			expectAllowed: true,
+			expectPatch: []util.PatchOperation{
+				{Op: "replace", Path: groupNamePath, Value: "job1-idle"},
+			},
+		},
+		{
+			name:          "pod moved to podgroup of the queue",
+			pod:           newPod("volcano", "job1", "preemptible"),
+			expectAllowed: true,
+			expectPatch: []util.PatchOperation{
+				{Op: "replace", Path: groupNamePath, Value: "job1-preemptible"},
+			},
+			expectPodGroup: "job1-preemptible",
+		},
+		{
+			name:          "pod moved to existing podgroup of the queue",
+			pod:           newPod("volcano", "job1", "preemptible"),
+			expectAllowed: true,
+			expectPatch: []util.PatchOperation{
+				{Op: "replace", Path: groupNamePath, Value: "job1-preemptible"},
+			},
+			expectPodGroup: "job1-preemptible",
+			existing:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		vcClient := vcclient.NewSimpleClientset()
+		vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(&schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				Name:            "job1",
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Spec: schedulingv1alpha2.PodGroupSpec{MinMember: 1, Queue: "default"},
+		})
+		vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(&schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "gang1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{MinMember: 3, Queue: "default"},
+		})
+		if testCase.existing {
+			vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(&schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "job1-preemptible"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{MinMember: 1, Queue: "preemptible"},
+			})
+		}
+		config.VolcanoClient = vcClient
+
+		response := overrideQueue(testCase.pod)
+		if response.Allowed != testCase.expectAllowed {
+			t.Errorf("case %s: expected allowed %v, got %v", testCase.name, testCase.expectAllowed, response.Result)
+			continue
+		}
+
+		var patch []util.PatchOperation
+		if response.Patch != nil {
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("case %s: failed to decode patch: %v", testCase.name, err)
+			}
+		}
+		if !reflect.DeepEqual(patch, testCase.expectPatch) {
+			t.Errorf("case %s: expected patch %v, got %v", testCase.name, testCase.expectPatch, patch)
+		}
+
+		if testCase.expectPodGroup == "" {
+			continue
+		}
+		podGroup, err := vcClient.SchedulingV1alpha2().PodGroups(namespace).Get(testCase.expectPodGroup, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("case %s: failed to get podgroup %s: %v", testCase.name, testCase.expectPodGroup, err)
+			continue
+		}
+		if podGroup.Spec.Queue != "preemptible" || podGroup.Spec.MinMember != 1 {
+			t.Errorf("case %s: expected podgroup of one member in queue preemptible, got %v", testCase.name, podGroup.Spec)
+		}
+		if !testCase.existing && !reflect.DeepEqual(podGroup.OwnerReferences, []metav1.OwnerReference{owner}) {
+			t.Errorf("case %s: expected podgroup owned by job1, got %v", testCase.name, podGroup.OwnerReferences)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// FailurePolicy decides the verdict when the policy endpoint can not be consulted.
+type FailurePolicy string
+
+const (
+	// Fail denies the request if the policy endpoint can not be consulted.
+	Fail FailurePolicy = "Fail"
+	// Ignore allows the request if the policy endpoint can not be consulted.
+	Ignore FailurePolicy = "Ignore"
+)
+
+// maxResponseSize is the limit of the response body read from the policy endpoint.
+const maxResponseSize = 1 << 20
+
+// Request is posted to the policy endpoint for a verdict on the object.
+type Request struct {
+	Operation string      `json:"operation"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	User      string      `json:"user,omitempty"`
+	Object    interface{} `json:"object"`
+}
+
+// Decision is the verdict of the policy endpoint.
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Checker decides whether an admission request is allowed by an external policy.
+type Checker interface {
+	Check(request *Request) *Decision
+}
+
+// httpChecker posts the requests to an HTTP policy endpoint.
+type httpChecker struct {
+	url           string
+	failurePolicy FailurePolicy
+	client        *http.Client
+}
+
+// NewHTTPChecker returns a checker consulting the policy endpoint at url, the failure policy applies
+// if the endpoint is unreachable, times out or answers with an error.
+func NewHTTPChecker(url string, timeout time.Duration, failurePolicy FailurePolicy) (Checker, error) {
+	if failurePolicy != Fail && failurePolicy != Ignore {
+		return nil, fmt.Errorf("failure policy must be %s or %s, got %q", Fail, Ignore, failurePolicy)
+	}
+
+	return &httpChecker{
+		url:           url,
+		failurePolicy: failurePolicy,
+		client:        &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *httpChecker) Check(request *Request) *Decision {
+	decision, err := c.post(request)
+	if err == nil {
+		return decision
+	}
+
+	if c.failurePolicy == Ignore {
+		klog.Warningf("Ignored failure of policy endpoint for %s %s/%s: %v",
+			request.Kind, request.Namespace, request.Name, err)
+		return &Decision{Allowed: true}
+	}
+
+	return &Decision{
+		Allowed: false,
+		Reason:  fmt.Sprintf("failed to consult policy endpoint: %v", err),
+	}
+}
+
+func (c *httpChecker) post(request *Request) (*Decision, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy endpoint returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	decision := &Decision{}
+	if err := json.Unmarshal(data, decision); err != nil {
+		return nil, fmt.Errorf("failed to decode decision of policy endpoint: %v", err)
+	}
+
+	return decision, nil
+}
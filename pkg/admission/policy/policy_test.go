@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPChecker(t *testing.T) {
+	testCases := []struct {
+		name          string
+		handler       http.HandlerFunc
+		unreachable   bool
+		failurePolicy FailurePolicy
+		expectAllowed bool
+		expectReason  string
+	}{
+		{
+			name: "allowed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"allowed": true}`))
+			},
+			failurePolicy: Fail,
+			expectAllowed: true,
+		},
+		{
+			name: "denied with reason",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"allowed": false, "reason": "gpu jobs need an owner label"}`))
+			},
+			failurePolicy: Ignore,
+			expectReason:  "gpu jobs need an owner label",
+		},
+		{
+			name: "server error fails",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "policy not loaded", http.StatusInternalServerError)
+			},
+			failurePolicy: Fail,
+			expectReason:  "policy not loaded",
+		},
+		{
+			name: "malformed decision fails",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`allowed`))
+			},
+			failurePolicy: Fail,
+			expectReason:  "failed to decode decision",
+		},
+		{
+			name: "timeout ignored",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte(`{"allowed": false}`))
+			},
+			failurePolicy: Ignore,
+			expectAllowed: true,
+		},
+		{
+			name:          "unreachable fails",
+			unreachable:   true,
+			failurePolicy: Fail,
+			expectReason:  "failed to consult policy endpoint",
+		},
+		{
+			name:          "unreachable ignored",
+			unreachable:   true,
+			failurePolicy: Ignore,
+			expectAllowed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var received Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&received)
+				testCase.handler(w, r)
+			}))
+			defer server.Close()
+			if testCase.unreachable {
+				server.Close()
+			}
+
+			checker, err := NewHTTPChecker(server.URL, 100*time.Millisecond, testCase.failurePolicy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			decision := checker.Check(&Request{Operation: "CREATE", Kind: "Job", Namespace: "ns1", Name: "job1"})
+			if decision.Allowed != testCase.expectAllowed {
+				t.Errorf("expected allowed %v, got %v", testCase.expectAllowed, decision)
+			}
+			if !strings.Contains(decision.Reason, testCase.expectReason) {
+				t.Errorf("expected reason %q, got %q", testCase.expectReason, decision.Reason)
+			}
+			if !testCase.unreachable && received.Name != "job1" {
+				t.Errorf("expected request of job1 posted, got %v", received)
+			}
+		})
+	}
+}
+
+func TestNewHTTPCheckerFailurePolicy(t *testing.T) {
+	if _, err := NewHTTPChecker("http://127.0.0.1", time.Second, "Retry"); err == nil {
+		t.Errorf("expected error of unknown failure policy")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/api/admission/v1beta1"
+	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/admission/router"
+	"volcano.sh/volcano/pkg/admission/schema"
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+const (
+	// DefaultWeight is the weight of queue if not specified
+	DefaultWeight int32 = 1
+	// DefaultReclaimable is whether queue is reclaimable if not specified
+	DefaultReclaimable = true
+)
+
+func init() {
+	router.RegisterAdmission(service)
+}
+
+var service = &router.AdmissionService{
+	Path: "/queues/mutate",
+	Func: MutateQueues,
+
+	MutatingConfig: &whv1beta1.MutatingWebhookConfiguration{
+		Webhooks: []whv1beta1.Webhook{{
+			Name: "mutatequeue.volcano.sh",
+			Rules: []whv1beta1.RuleWithOperations{
+				{
+					Operations: []whv1beta1.OperationType{whv1beta1.Create},
+					Rule: whv1beta1.Rule{
+						APIGroups:   []string{schedulingv1alpha2.SchemeGroupVersion.Group},
+						APIVersions: []string{schedulingv1alpha2.SchemeGroupVersion.Version},
+						Resources:   []string{"queues"},
+					},
+				},
+			},
+		}},
+	},
+}
+
+type patchOperation = util.PatchOperation
+
+// MutateQueues mutate queues
+func MutateQueues(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	klog.V(3).Infof("mutating queues")
+
+	if _, err := schema.DecodeQueue(ar.Request.Object, ar.Request.Resource); err != nil {
+		return util.ToAdmissionResponse(err)
+	}
+
+	reviewResponse := v1beta1.AdmissionResponse{}
+	reviewResponse.Allowed = true
+
+	var err error
+	switch ar.Request.Operation {
+	case v1beta1.Create:
+		// Nothing to default leaves the patch empty, the queue is admitted as is.
+		var patch *util.PatchBuilder
+		if patch, err = createPatch(ar.Request.Object.Raw); err == nil {
+			err = patch.WriteResponse(&reviewResponse)
+		}
+		break
+	default:
+		err = fmt.Errorf("expect operation to be 'CREATE' ")
+		return util.ToAdmissionResponse(err)
+	}
+
+	if err != nil {
+		reviewResponse.Result = &metav1.Status{Message: err.Error()}
+		return &reviewResponse
+	}
+	klog.V(3).Infof("AdmissionResponse: patch=%v\n", string(reviewResponse.Patch))
+
+	return &reviewResponse
+}
+
+// createPatch checks the raw object instead of the decoded queue, as an explicit
+// zero weight can not be told from an absent one after decoding. The absent fields
+// are merged into the queue, and the builder turns the merge into JSONPatch.
+func createPatch(raw []byte) (*util.PatchBuilder, error) {
+	var queue struct {
+		Spec map[string]json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &queue); err != nil {
+		return nil, err
+	}
+
+	defaults := map[string]interface{}{}
+	if _, found := queue.Spec["weight"]; !found {
+		defaults["weight"] = DefaultWeight
+	}
+	if _, found := queue.Spec["reclaimable"]; !found {
+		defaults["reclaimable"] = DefaultReclaimable
+	}
+
+	patch := util.NewStrategicMergePatchBuilder(raw, schedulingv1alpha2.Queue{})
+	if len(defaults) != 0 {
+		patch.Merge(map[string]interface{}{"spec": defaults})
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCreatePatch(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Queue  string
+		Expect []patchOperation
+	}{
+		{
+			Name:  "default absent weight and reclaimable",
+			Queue: `{"metadata":{"name":"q1"},"spec":{"capability":{"cpu":"1"}}}`,
+			Expect: []patchOperation{
+				{Op: "add", Path: "/spec/reclaimable", Value: DefaultReclaimable},
+				{Op: "add", Path: "/spec/weight", Value: float64(DefaultWeight)},
+			},
+		},
+		{
+			Name:  "preserve explicit zero weight",
+			Queue: `{"metadata":{"name":"q1"},"spec":{"weight":0}}`,
+			Expect: []patchOperation{
+				{Op: "add", Path: "/spec/reclaimable", Value: DefaultReclaimable},
+			},
+		},
+		{
+			Name:  "default absent spec",
+			Queue: `{"metadata":{"name":"q1"}}`,
+			Expect: []patchOperation{
+				{
+					Op:   "add",
+					Path: "/spec",
+					Value: map[string]interface{}{
+						"weight":      float64(DefaultWeight),
+						"reclaimable": DefaultReclaimable,
+					},
+				},
+			},
+		},
+		{
+			Name:   "no-op when fields are present",
+			Queue:  `{"metadata":{"name":"q1"},"spec":{"weight":3,"reclaimable":false}}`,
+			Expect: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			builder, err := createPatch([]byte(testCase.Queue))
+			if err != nil {
+				t.Fatalf("create patch failed for %v", err)
+			}
+			patchBytes, err := builder.Build()
+			if err != nil {
+				t.Fatalf("create patch failed for %v", err)
+			}
+
+			var patch []patchOperation
+			if patchBytes != nil {
+				if err := json.Unmarshal(patchBytes, &patch); err != nil {
+					t.Fatalf("unmarshal patch failed for %v", err)
+				}
+			}
+
+			if !reflect.DeepEqual(patch, testCase.Expect) {
+				t.Errorf("expected patch %v, got %v", testCase.Expect, patch)
+			}
+		})
+	}
+}
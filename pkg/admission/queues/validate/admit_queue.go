@@ -0,0 +1,289 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/api/admission/v1beta1"
+	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/api/core/v1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kubeschedulinglisters "k8s.io/client-go/listers/scheduling/v1beta1"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/admission/router"
+	"volcano.sh/volcano/pkg/admission/schema"
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+func init() {
+	router.RegisterAdmission(service)
+}
+
+var service = &router.AdmissionService{
+	Path: "/queues/validate",
+	Func: AdmitQueues,
+
+	Config: config,
+
+	ValidatingConfig: &whv1beta1.ValidatingWebhookConfiguration{
+		Webhooks: []whv1beta1.Webhook{{
+			Name: "validatequeue.volcano.sh",
+			Rules: []whv1beta1.RuleWithOperations{
+				{
+					Operations: []whv1beta1.OperationType{whv1beta1.Create, whv1beta1.Update, whv1beta1.Delete},
+					Rule: whv1beta1.Rule{
+						APIGroups:   []string{schedulingv1alpha2.SchemeGroupVersion.Group},
+						APIVersions: []string{schedulingv1alpha2.SchemeGroupVersion.Version},
+						Resources:   []string{"queues"},
+					},
+				},
+			},
+		}},
+	},
+}
+
+var config = &router.AdmissionServiceConfig{}
+
+// AdmitQueues is to admit queues and return response
+func AdmitQueues(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	klog.V(3).Infof("admitting queues -- %s", ar.Request.Operation)
+
+	// The object of a DELETE request is not set by all versions of apiserver,
+	// so the queue is looked up by name.
+	if ar.Request.Operation == v1beta1.Delete {
+		return admitQueueDeletion(ar.Request.Name)
+	}
+
+	queue, err := schema.DecodeQueue(ar.Request.Object, ar.Request.Resource)
+	if err != nil {
+		return util.ToAdmissionResponse(err)
+	}
+
+	reviewResponse := v1beta1.AdmissionResponse{}
+	reviewResponse.Allowed = true
+
+	switch ar.Request.Operation {
+	case v1beta1.Create, v1beta1.Update:
+		errs := validateQueue(queue)
+		errs = append(errs, validatePriority(config.PriorityClassLister, queue)...)
+		if err := internalError(errs); err != nil {
+			return util.ToInternalErrorResponse(err)
+		}
+		if len(errs) != 0 {
+			// Return the offending fields as causes, so that clients e.g.
+			// `kubectl apply --dry-run=server` show each of them.
+			status := apierrors.NewInvalid(schedulingv1alpha2.SchemeGroupVersion.WithKind("Queue").GroupKind(),
+				queue.Name, errs).ErrStatus
+			reviewResponse.Allowed = false
+			reviewResponse.Result = &status
+		}
+	default:
+		err := fmt.Errorf("expect operation to be 'CREATE', 'UPDATE' or 'DELETE'")
+		return util.ToAdmissionResponse(err)
+	}
+
+	return &reviewResponse
+}
+
+// admitQueueDeletion rejects deleting a queue which still has podgroups, as they would be
+// orphaned, unless the queue is annotated to be deleted by force.
+func admitQueueDeletion(name string) *v1beta1.AdmissionResponse {
+	queue, err := util.GetQueue(config.QueueLister, config.VolcanoClient, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &v1beta1.AdmissionResponse{Allowed: true}
+		}
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to get queue %s: %v", name, err))
+	}
+	if queue.Annotations[schedulingv1alpha2.ForceDeleteAnnotationKey] == "true" {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	count, err := countPodGroups(name)
+	if err != nil {
+		return util.ToInternalErrorResponse(fmt.Errorf("failed to list podgroups of queue %s: %v", name, err))
+	}
+	if count != 0 {
+		return util.ToDeniedResponse(fmt.Sprintf("queue %s still has %d podgroups, delete them first "+
+			"or annotate the queue with %s=true to delete it by force", name, count,
+			schedulingv1alpha2.ForceDeleteAnnotationKey))
+	}
+
+	return &v1beta1.AdmissionResponse{Allowed: true}
+}
+
+// countPodGroups returns the number of podgroups in the queue of all namespaces.
+func countPodGroups(queue string) (int, error) {
+	var podGroups []*schedulingv1alpha2.PodGroup
+	if config.PodGroupLister != nil {
+		var err error
+		if podGroups, err = config.PodGroupLister.List(labels.Everything()); err != nil {
+			return 0, err
+		}
+	} else {
+		list, err := config.VolcanoClient.SchedulingV1alpha2().PodGroups(metav1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			return 0, err
+		}
+		for i := range list.Items {
+			podGroups = append(podGroups, &list.Items[i])
+		}
+	}
+
+	count := 0
+	for _, pg := range podGroups {
+		if pg.Spec.Queue == queue {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// internalError returns the first error of the list which is not caused by the queue itself.
+func internalError(errs field.ErrorList) error {
+	for _, err := range errs {
+		if err.Type == field.ErrorTypeInternal {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateQueue(queue *schedulingv1alpha2.Queue) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if queue.Spec.Weight < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("weight"), queue.Spec.Weight,
+			"must be greater than or equal to zero"))
+	}
+
+	// The capability resolved by queue controller is validated, which includes the resources of
+	// spec.capabilityPercent.
+	capability := queuehelpers.EffectiveCapability(queue)
+	capabilityPath := func(name v1.ResourceName) *field.Path {
+		if _, found := queue.Spec.Capability[name]; found {
+			return specPath.Child("capability").Key(string(name))
+		}
+		return field.NewPath("status").Child("capability").Key(string(name))
+	}
+
+	for name, guaranteed := range queue.Spec.Guaranteed {
+		if guaranteed.Sign() < 0 {
+			errs = append(errs, field.Invalid(specPath.Child("guaranteed").Key(string(name)), guaranteed.String(),
+				"must be greater than or equal to zero"))
+			continue
+		}
+
+		quantity, found := capability[name]
+		if found && quantity.Cmp(guaranteed) < 0 {
+			errs = append(errs, field.Invalid(capabilityPath(name), quantity.String(),
+				fmt.Sprintf("must not be less than guaranteed %s", guaranteed.String())))
+		}
+	}
+
+	if ratio := queue.Spec.OvercommitRatio; ratio != nil && *ratio < 1 {
+		errs = append(errs, field.Invalid(specPath.Child("overcommitRatio"), *ratio,
+			"must be greater than or equal to 1"))
+	}
+
+	for name, percent := range queue.Spec.CapabilityPercent {
+		if percent < 1 || percent > 100 {
+			errs = append(errs, field.Invalid(specPath.Child("capabilityPercent").Key(string(name)), percent,
+				"must be between 1 and 100"))
+		}
+	}
+
+	for name, budget := range queue.Spec.MaxReclaimPerCycle {
+		if budget.Sign() < 0 {
+			errs = append(errs, field.Invalid(specPath.Child("maxReclaimPerCycle").Key(string(name)), budget.String(),
+				"must be greater than or equal to zero"))
+		}
+	}
+
+	// The namespaces are validated in order, so that the causes are stable.
+	var namespaces []string
+	for namespace := range queue.Spec.NamespaceQuotas {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		quotaPath := specPath.Child("namespaceQuotas").Key(namespace)
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(quotaPath, namespace, fmt.Sprintf("invalid namespace: %v", msgs)))
+		}
+		for name, quantity := range queue.Spec.NamespaceQuotas[namespace] {
+			if quantity.Sign() < 0 {
+				errs = append(errs, field.Invalid(quotaPath.Key(string(name)), quantity.String(),
+					"must be greater than or equal to zero"))
+			}
+		}
+	}
+
+	if queue.Spec.Reclaimable != nil && *queue.Spec.Reclaimable {
+		for name, quantity := range capability {
+			if quantity.Sign() <= 0 {
+				errs = append(errs, field.Invalid(capabilityPath(name), quantity.String(),
+					"reclaimable queue must have a capability greater than zero, otherwise it can never be met"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePriority rejects the priority of queue which is not the value of any PriorityClass.
+func validatePriority(pcLister kubeschedulinglisters.PriorityClassLister, queue *schedulingv1alpha2.Queue) field.ErrorList {
+	if queue.Spec.Priority == 0 || pcLister == nil {
+		return nil
+	}
+
+	priorityPath := field.NewPath("spec").Child("priority")
+	priorityClasses, err := pcLister.List(labels.Everything())
+	if err != nil {
+		return field.ErrorList{field.InternalError(priorityPath, fmt.Errorf("failed to list PriorityClasses: %v", err))}
+	}
+
+	for _, pc := range priorityClasses {
+		if pc.Value == queue.Spec.Priority {
+			return nil
+		}
+	}
+
+	return field.ErrorList{field.NotSupported(priorityPath, queue.Spec.Priority, priorityValues(priorityClasses))}
+}
+
+func priorityValues(priorityClasses []*schedulingv1beta1.PriorityClass) []string {
+	var values []string
+	for _, pc := range priorityClasses {
+		values = append(values, fmt.Sprintf("%d", pc.Value))
+	}
+	sort.Strings(values)
+
+	return values
+}
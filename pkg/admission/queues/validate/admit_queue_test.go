@@ -0,0 +1,432 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubeschedulinglisters "k8s.io/client-go/listers/scheduling/v1beta1"
+
+	"volcano.sh/volcano/pkg/admission/util"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcfake "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
+)
+
+func TestAdmitQueues(t *testing.T) {
+	trueValue := true
+	overRatio, underRatio := 1.2, 0.8
+
+	pcInformer := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0).Scheduling().V1beta1().PriorityClasses()
+	pcInformer.Informer().GetIndexer().Add(&schedulingv1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "production"},
+		Value:      1000,
+	})
+	config.PriorityClassLister = pcInformer.Lister()
+	defer func() { config.PriorityClassLister = nil }()
+
+	testCases := []struct {
+		Name         string
+		Queue        schedulingv1alpha2.Queue
+		ExpectAllow  bool
+		ExpectFields []string
+	}{
+		{
+			Name: "valid queue",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:          1,
+					Capability:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+					Guaranteed:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+					Reclaimable:     &trueValue,
+					OvercommitRatio: &overRatio,
+				},
+			},
+			ExpectAllow: true,
+		},
+		{
+			Name: "priority of PriorityClass",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:   1,
+					Priority: 1000,
+				},
+			},
+			ExpectAllow: true,
+		},
+		{
+			Name: "unknown priority",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:   1,
+					Priority: 10,
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.priority"},
+		},
+		{
+			Name: "negative weight",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight: -1,
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.weight"},
+		},
+		{
+			Name: "capability less than guaranteed",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:     1,
+					Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+					Guaranteed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.capability[cpu]"},
+		},
+		{
+			Name: "decimal capability less than binary guaranteed",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:     1,
+					Capability: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2G")},
+					Guaranteed: v1.ResourceList{v1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.capability[memory]"},
+		},
+		{
+			Name: "capability equal to guaranteed in other formats",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight: 1,
+					Capability: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+					Guaranteed: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1000m"),
+						v1.ResourceMemory: resource.MustParse("2147483648"),
+					},
+				},
+			},
+			ExpectAllow: true,
+		},
+		{
+			Name: "reclaimable queue with zero capability",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:      -1,
+					Capability:  v1.ResourceList{v1.ResourceMemory: resource.MustParse("0")},
+					Reclaimable: &trueValue,
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.weight", "spec.capability[memory]"},
+		},
+		{
+			Name: "overcommit ratio less than 1",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:          1,
+					OvercommitRatio: &underRatio,
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.overcommitRatio"},
+		},
+		{
+			Name: "capability percent of cluster",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:            1,
+					CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 50},
+				},
+			},
+			ExpectAllow: true,
+		},
+		{
+			Name: "resolved capability percent less than guaranteed",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:            1,
+					CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 10},
+					Guaranteed:        v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+					Reclaimable:       &trueValue,
+				},
+				Status: schedulingv1alpha2.QueueStatus{
+					Capability: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("1"),
+						v1.ResourceMemory: resource.MustParse("0"),
+					},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"status.capability[cpu]", "status.capability[memory]"},
+		},
+		{
+			Name: "capability percent above 100",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:            1,
+					CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceMemory: 150},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.capabilityPercent[memory]"},
+		},
+		{
+			Name: "namespace quotas",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight: 1,
+					NamespaceQuotas: map[string]v1.ResourceList{
+						"team-a": {v1.ResourceCPU: resource.MustParse("4")},
+					},
+				},
+			},
+			ExpectAllow: true,
+		},
+		{
+			Name: "negative namespace quota",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight: 1,
+					NamespaceQuotas: map[string]v1.ResourceList{
+						"team-a": {v1.ResourceCPU: resource.MustParse("-1")},
+						"Team_B": {v1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.namespaceQuotas[Team_B]", "spec.namespaceQuotas[team-a][cpu]"},
+		},
+		{
+			Name: "negative reclaim budget",
+			Queue: schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: schedulingv1alpha2.QueueSpec{
+					Weight:             1,
+					MaxReclaimPerCycle: v1.ResourceList{v1.ResourceCPU: resource.MustParse("-2")},
+				},
+			},
+			ExpectAllow:  false,
+			ExpectFields: []string{"spec.maxReclaimPerCycle[cpu]"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			raw, err := json.Marshal(testCase.Queue)
+			if err != nil {
+				t.Fatalf("marshal queue failed for %v", err)
+			}
+
+			ar := v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Kind: metav1.GroupVersionKind{
+						Group:   schedulingv1alpha2.SchemeGroupVersion.Group,
+						Version: schedulingv1alpha2.SchemeGroupVersion.Version,
+						Kind:    "Queue",
+					},
+					Resource: metav1.GroupVersionResource{
+						Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+						Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+						Resource: "queues",
+					},
+					Name:      testCase.Queue.Name,
+					Operation: v1beta1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			}
+
+			response := AdmitQueues(ar)
+			if response.Allowed != testCase.ExpectAllow {
+				t.Fatalf("expected allowed %v, got %v: %v", testCase.ExpectAllow, response.Allowed, response.Result)
+			}
+			if testCase.ExpectAllow {
+				return
+			}
+
+			if response.Result == nil || response.Result.Details == nil {
+				t.Fatalf("expected status details, got %v", response.Result)
+			}
+			var fields []string
+			for _, cause := range response.Result.Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			if len(fields) != len(testCase.ExpectFields) {
+				t.Fatalf("expected fields %v, got %v", testCase.ExpectFields, fields)
+			}
+			for i := range fields {
+				if fields[i] != testCase.ExpectFields[i] {
+					t.Errorf("expected fields %v, got %v", testCase.ExpectFields, fields)
+				}
+			}
+		})
+	}
+}
+
+// failingPriorityClassLister fails to list PriorityClasses.
+type failingPriorityClassLister struct {
+	kubeschedulinglisters.PriorityClassLister
+}
+
+func (failingPriorityClassLister) List(selector labels.Selector) ([]*schedulingv1beta1.PriorityClass, error) {
+	return nil, fmt.Errorf("cache unavailable")
+}
+
+func TestAdmitQueuesInternalError(t *testing.T) {
+	config.PriorityClassLister = failingPriorityClassLister{}
+	defer func() { config.PriorityClassLister = nil }()
+
+	raw, err := json.Marshal(schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec: schedulingv1alpha2.QueueSpec{
+			Weight:   1,
+			Priority: 10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal queue failed for %v", err)
+	}
+
+	response := AdmitQueues(v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Resource: metav1.GroupVersionResource{
+				Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+				Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+				Resource: "queues",
+			},
+			Name:      "q1",
+			Operation: v1beta1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	if !util.IsInternalError(response) {
+		t.Errorf("expected internal error response, got %v", response.Result)
+	}
+}
+
+func TestAdmitQueueDeletion(t *testing.T) {
+	factory := informerfactory.NewSharedInformerFactory(vcfake.NewSimpleClientset(), 0)
+	queueInformer := factory.Scheduling().V1alpha2().Queues()
+	podGroupInformer := factory.Scheduling().V1alpha2().PodGroups()
+	config.QueueLister = queueInformer.Lister()
+	config.PodGroupLister = podGroupInformer.Lister()
+	defer func() {
+		config.QueueLister = nil
+		config.PodGroupLister = nil
+	}()
+
+	queues := []*schedulingv1alpha2.Queue{
+		{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "busy"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "forced",
+			Annotations: map[string]string{schedulingv1alpha2.ForceDeleteAnnotationKey: "true"},
+		}},
+	}
+	for _, queue := range queues {
+		queueInformer.Informer().GetIndexer().Add(queue)
+	}
+	for i, queue := range []string{"busy", "busy", "forced", "other"} {
+		podGroupInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: fmt.Sprintf("ns%d", i), Name: "pg"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: queue},
+		})
+	}
+
+	testCases := []struct {
+		name          string
+		queue         string
+		expectAllow   bool
+		expectMessage string
+	}{
+		{
+			name:        "queue without podgroups",
+			queue:       "empty",
+			expectAllow: true,
+		},
+		{
+			name:          "queue with podgroups",
+			queue:         "busy",
+			expectMessage: "queue busy still has 2 podgroups",
+		},
+		{
+			name:        "queue with podgroups deleted by force",
+			queue:       "forced",
+			expectAllow: true,
+		},
+		{
+			name:        "queue not found",
+			queue:       "unknown",
+			expectAllow: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		response := AdmitQueues(v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource: metav1.GroupVersionResource{
+					Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+					Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+					Resource: "queues",
+				},
+				Name:      testCase.queue,
+				Operation: v1beta1.Delete,
+			},
+		})
+		if response.Allowed != testCase.expectAllow {
+			t.Errorf("case %s: expected allowed %v, got %v: %v", testCase.name, testCase.expectAllow, response.Allowed, response.Result)
+			continue
+		}
+		if !testCase.expectAllow && !strings.Contains(response.Result.Message, testCase.expectMessage) {
+			t.Errorf("case %s: expected message containing %q, got %q", testCase.name, testCase.expectMessage, response.Result.Message)
+		}
+	}
+}
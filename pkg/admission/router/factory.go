@@ -21,7 +21,7 @@ func RegisterAdmission(service *AdmissionService) error {
 
 	// Also register handler to the service.
 	service.Handler = func(w http.ResponseWriter, r *http.Request) {
-		Serve(w, r, service.Func)
+		Serve(w, r, service)
 	}
 
 	admissionMap[service.Path] = service
@@ -1,11 +1,18 @@
 package router
 
 import (
+	"time"
+
 	"k8s.io/api/admission/v1beta1"
 	whv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	kubeschedulinglisters "k8s.io/client-go/listers/scheduling/v1beta1"
 
+	"volcano.sh/volcano/pkg/admission/audit"
+	"volcano.sh/volcano/pkg/admission/policy"
 	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
 )
 
 //The AdmitFunc returns response
@@ -15,6 +22,28 @@ type AdmissionServiceConfig struct {
 	SchedulerName string
 	KubeClient    kubernetes.Interface
 	VolcanoClient versioned.Interface
+	// AuditSink records admission decisions, audit is disabled if nil.
+	AuditSink audit.Sink
+	// SlowThreshold is the latency above which an admission request is logged, 0 disables the log.
+	SlowThreshold time.Duration
+	// ValidateJobQueue rejects jobs whose queue does not exist or is closed.
+	ValidateJobQueue bool
+	// ValidateJobCapability rejects jobs whose minResources exceed the capability of their queue.
+	ValidateJobCapability bool
+	// QueueLister lists queues from the informer cache.
+	QueueLister schedulinglisters.QueueLister
+	// PodGroupLister lists podgroups from the informer cache.
+	PodGroupLister schedulinglisters.PodGroupLister
+	// NamespaceLister lists namespaces from the informer cache.
+	NamespaceLister corelisters.NamespaceLister
+	// PodLister lists the pods of the scheduler from the informer cache.
+	PodLister corelisters.PodLister
+	// PriorityClassLister lists PriorityClasses from the informer cache.
+	PriorityClassLister kubeschedulinglisters.PriorityClassLister
+	// HasSynced returns whether the informer caches backing the listers are synced, nil means no caches.
+	HasSynced func() bool
+	// JobPolicy is consulted for the jobs admitted by the checks of Volcano, nil disables it.
+	JobPolicy policy.Checker
 }
 
 type AdmissionService struct {
@@ -27,3 +56,12 @@ type AdmissionService struct {
 
 	Config *AdmissionServiceConfig
 }
+
+// Ready returns whether the dependencies of the admission service are synced,
+// decisions made before that may be wrong.
+func (a *AdmissionService) Ready() bool {
+	if a.Config == nil || a.Config.HasSynced == nil {
+		return true
+	}
+	return a.Config.HasSynced()
+}
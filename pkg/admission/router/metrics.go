@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto" // auto-registry collectors in default registry
+)
+
+const (
+	// volcanoNamespace - namespace in prometheus used by volcano
+	volcanoNamespace = "volcano"
+
+	// unknownOperation label of the requests which can not be decoded
+	unknownOperation = "UNKNOWN"
+)
+
+var (
+	admissionDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "admission_duration_seconds",
+			Help:      "Latency of admission webhooks in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{"webhook", "operation"},
+	)
+
+	admissionErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "admission_errors_total",
+			Help:      "Total number of admission requests which failed to be served",
+		}, []string{"webhook", "operation"},
+	)
+)
+
+// updateAdmissionDuration updates latency of the webhook
+func updateAdmissionDuration(webhook, operation string, duration time.Duration) {
+	admissionDuration.WithLabelValues(webhook, operation).Observe(duration.Seconds())
+}
+
+// registerAdmissionError records an admission request failed to be served
+func registerAdmissionError(webhook, operation string) {
+	admissionErrors.WithLabelValues(webhook, operation).Inc()
+}
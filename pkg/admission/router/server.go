@@ -18,11 +18,14 @@ package router
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"k8s.io/api/admission/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/admission/schema"
@@ -35,8 +38,24 @@ var CONTENTTYPE = "Content-Type"
 // APPLICATIONJSON json content
 var APPLICATIONJSON = "application/json"
 
-// Serve the http request
-func Serve(w http.ResponseWriter, r *http.Request, admit AdmitFunc) {
+// Serve the http request by the admission service
+func Serve(w http.ResponseWriter, r *http.Request, service *AdmissionService) {
+	startTime := time.Now()
+	operation := unknownOperation
+	failed := false
+	var uid types.UID
+	defer func() {
+		duration := time.Since(startTime)
+		updateAdmissionDuration(service.Path, operation, duration)
+		if failed {
+			registerAdmissionError(service.Path, operation)
+		}
+		if service.Config != nil && service.Config.SlowThreshold > 0 && duration > service.Config.SlowThreshold {
+			klog.V(2).Infof("Admission request %s of %s for %s took %v, longer than %v.",
+				uid, operation, service.Path, duration, service.Config.SlowThreshold)
+		}
+	}()
+
 	var body []byte
 	if r.Body != nil {
 		if data, err := ioutil.ReadAll(r.Body); err == nil {
@@ -48,31 +67,56 @@ func Serve(w http.ResponseWriter, r *http.Request, admit AdmitFunc) {
 	contentType := r.Header.Get(CONTENTTYPE)
 	if contentType != APPLICATIONJSON {
 		klog.Errorf("contentType=%s, expect application/json", contentType)
+		failed = true
 		return
 	}
 
 	var reviewResponse *v1beta1.AdmissionResponse
 	ar := v1beta1.AdmissionReview{}
 	deserializer := schema.Codecs.UniversalDeserializer()
-	if _, _, err := deserializer.Decode(body, nil, &ar); err != nil {
+	if _, _, err := deserializer.Decode(body, nil, &ar); err != nil || ar.Request == nil {
+		if err == nil {
+			err = fmt.Errorf("admission review has no request")
+		}
 		reviewResponse = util.ToAdmissionResponse(err)
+		failed = true
 	} else {
-		reviewResponse = admit(ar)
+		operation = string(ar.Request.Operation)
+		uid = ar.Request.UID
+		if service.Ready() {
+			reviewResponse = service.Func(ar)
+		} else {
+			reviewResponse = util.ToInternalErrorResponse(fmt.Errorf("admission service %s is not ready", service.Path))
+		}
 	}
 	klog.V(3).Infof("sending response: %v", reviewResponse)
 
+	// Internal errors are not a decision on the object, fail the call and
+	// let the failurePolicy of the webhook decide.
+	if util.IsInternalError(reviewResponse) {
+		failed = true
+		http.Error(w, reviewResponse.Result.Message, http.StatusInternalServerError)
+		return
+	}
+
 	response := createResponse(reviewResponse, &ar)
 	resp, err := json.Marshal(response)
 	if err != nil {
 		klog.Error(err)
+		failed = true
 	}
 	if _, err := w.Write(resp); err != nil {
 		klog.Error(err)
+		failed = true
 	}
 }
 
 func createResponse(reviewResponse *v1beta1.AdmissionResponse, ar *v1beta1.AdmissionReview) v1beta1.AdmissionReview {
 	response := v1beta1.AdmissionReview{}
+	if ar.Request == nil {
+		response.Response = reviewResponse
+		return response
+	}
 	if reviewResponse != nil {
 		response.Response = reviewResponse
 		response.Response.UID = ar.Request.UID
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/api/admission/v1beta1"
+
+	"volcano.sh/volcano/pkg/admission/util"
+)
+
+func errorCount(t *testing.T, webhook, operation string) float64 {
+	metric := &dto.Metric{}
+	if err := admissionErrors.WithLabelValues(webhook, operation).Write(metric); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func sampleCount(t *testing.T, webhook, operation string) uint64 {
+	metric := &dto.Metric{}
+	if err := admissionDuration.WithLabelValues(webhook, operation).(interface {
+		Write(*dto.Metric) error
+	}).Write(metric); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestServe(t *testing.T) {
+	review, _ := json.Marshal(v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			UID:       "uid-1",
+			Operation: v1beta1.Create,
+		},
+	})
+
+	testCases := []struct {
+		Name            string
+		webhook         string
+		contentType     string
+		body            []byte
+		response        *v1beta1.AdmissionResponse
+		notReady        bool
+		ExpectCode      int
+		ExpectOperation string
+		ExpectError     bool
+		ExpectAllowed   bool
+	}{
+		{
+			Name:            "admitted request",
+			webhook:         "/test/admitted",
+			contentType:     APPLICATIONJSON,
+			body:            review,
+			response:        &v1beta1.AdmissionResponse{Allowed: true},
+			ExpectCode:      http.StatusOK,
+			ExpectOperation: string(v1beta1.Create),
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "denied request",
+			webhook:         "/test/denied",
+			contentType:     APPLICATIONJSON,
+			body:            review,
+			response:        util.ToDeniedResponse("invalid object"),
+			ExpectCode:      http.StatusOK,
+			ExpectOperation: string(v1beta1.Create),
+		},
+		{
+			Name:            "internal error",
+			webhook:         "/test/internal-error",
+			contentType:     APPLICATIONJSON,
+			body:            review,
+			response:        util.ToInternalErrorResponse(fmt.Errorf("apiserver unavailable")),
+			ExpectCode:      http.StatusInternalServerError,
+			ExpectOperation: string(v1beta1.Create),
+			ExpectError:     true,
+		},
+		{
+			Name:            "not ready",
+			webhook:         "/test/not-ready",
+			contentType:     APPLICATIONJSON,
+			body:            review,
+			response:        &v1beta1.AdmissionResponse{Allowed: true},
+			notReady:        true,
+			ExpectCode:      http.StatusInternalServerError,
+			ExpectOperation: string(v1beta1.Create),
+			ExpectError:     true,
+		},
+		{
+			Name:            "wrong content type",
+			webhook:         "/test/content-type",
+			contentType:     "text/plain",
+			body:            review,
+			ExpectCode:      http.StatusOK,
+			ExpectOperation: unknownOperation,
+			ExpectError:     true,
+		},
+		{
+			Name:            "undecodable review",
+			webhook:         "/test/undecodable",
+			contentType:     APPLICATIONJSON,
+			body:            []byte("{}"),
+			ExpectCode:      http.StatusOK,
+			ExpectOperation: unknownOperation,
+			ExpectError:     true,
+		},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			service := &AdmissionService{
+				Path: testcase.webhook,
+				Func: func(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+					return testcase.response
+				},
+				Config: &AdmissionServiceConfig{
+					HasSynced: func() bool { return !testcase.notReady },
+				},
+			}
+
+			r := httptest.NewRequest(http.MethodPost, testcase.webhook, bytes.NewReader(testcase.body))
+			r.Header.Set(CONTENTTYPE, testcase.contentType)
+			w := httptest.NewRecorder()
+			Serve(w, r, service)
+
+			if count := sampleCount(t, testcase.webhook, testcase.ExpectOperation); count != 1 {
+				t.Errorf("expected 1 latency sample of operation %s, got %d", testcase.ExpectOperation, count)
+			}
+			expectedErrors := 0.0
+			if testcase.ExpectError {
+				expectedErrors = 1
+			}
+			if count := errorCount(t, testcase.webhook, testcase.ExpectOperation); count != expectedErrors {
+				t.Errorf("expected %v errors of operation %s, got %v", expectedErrors, testcase.ExpectOperation, count)
+			}
+
+			if w.Code != testcase.ExpectCode {
+				t.Errorf("expected status code %d, got %d", testcase.ExpectCode, w.Code)
+			}
+			if w.Body.Len() == 0 || w.Code != http.StatusOK {
+				return
+			}
+			response := v1beta1.AdmissionReview{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			allowed := response.Response != nil && response.Response.Allowed
+			if allowed != testcase.ExpectAllowed {
+				t.Errorf("expected allowed %v, got %v", testcase.ExpectAllowed, allowed)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/kubernetes/pkg/apis/core/v1"
 
 	batchv1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 )
 
 func init() {
@@ -67,3 +68,51 @@ func DecodePod(object runtime.RawExtension, resource metav1.GroupVersionResource
 
 	return &pod, nil
 }
+
+//DecodeQueue decodes the queue using deserializer from the raw object
+func DecodeQueue(object runtime.RawExtension, resource metav1.GroupVersionResource) (*schedulingv1alpha2.Queue, error) {
+	queueResource := metav1.GroupVersionResource{
+		Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+		Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+		Resource: "queues",
+	}
+	raw := object.Raw
+	queue := schedulingv1alpha2.Queue{}
+
+	if resource != queueResource {
+		err := fmt.Errorf("expect resource to be %s", queueResource)
+		return &queue, err
+	}
+
+	deserializer := Codecs.UniversalDeserializer()
+	if _, _, err := deserializer.Decode(raw, nil, &queue); err != nil {
+		return &queue, err
+	}
+	klog.V(3).Infof("the queue struct is %+v", queue)
+
+	return &queue, nil
+}
+
+//DecodePodGroup decodes the podgroup using deserializer from the raw object
+func DecodePodGroup(object runtime.RawExtension, resource metav1.GroupVersionResource) (*schedulingv1alpha2.PodGroup, error) {
+	podGroupResource := metav1.GroupVersionResource{
+		Group:    schedulingv1alpha2.SchemeGroupVersion.Group,
+		Version:  schedulingv1alpha2.SchemeGroupVersion.Version,
+		Resource: "podgroups",
+	}
+	raw := object.Raw
+	podGroup := schedulingv1alpha2.PodGroup{}
+
+	if resource != podGroupResource {
+		err := fmt.Errorf("expect resource to be %s", podGroupResource)
+		return &podGroup, err
+	}
+
+	deserializer := Codecs.UniversalDeserializer()
+	if _, _, err := deserializer.Decode(raw, nil, &podGroup); err != nil {
+		return &podGroup, err
+	}
+	klog.V(3).Infof("the podgroup struct is %+v", podGroup)
+
+	return &podGroup, nil
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// PatchMode is how a mutating webhook describes its changes to the object.
+type PatchMode string
+
+const (
+	// JSONPatchMode describes the changes by JSONPatch operations, it is the default mode.
+	JSONPatchMode PatchMode = "JSONPatch"
+	// StrategicMergePatchMode describes the changes by strategic merge patches, e.g. to default
+	// the containers of a nested pod template by name instead of by index.
+	StrategicMergePatchMode PatchMode = "StrategicMergePatch"
+)
+
+// PatchOperation is an operation of JSONPatch.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchBuilder collects the changes of a mutating webhook to an object. The apiserver only
+// applies JSONPatch from admission webhooks, so the strategic merge patches are merged into the
+// object, and the merged object is compared with the original one into JSONPatch operations.
+type PatchBuilder struct {
+	mode       PatchMode
+	operations []PatchOperation
+
+	// original and merged are the objects before and after the strategic merge patches.
+	original   []byte
+	merged     []byte
+	dataStruct interface{}
+
+	// err is the first error of the changes, it is returned by Build.
+	err error
+}
+
+// NewJSONPatchBuilder creates a PatchBuilder of JSONPatch operations.
+func NewJSONPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{mode: JSONPatchMode}
+}
+
+// NewStrategicMergePatchBuilder creates a PatchBuilder of strategic merge patches to the raw object,
+// the patch strategies of the fields are read from dataStruct, e.g. v1alpha1.Job{}.
+func NewStrategicMergePatchBuilder(original []byte, dataStruct interface{}) *PatchBuilder {
+	return &PatchBuilder{
+		mode:       StrategicMergePatchMode,
+		original:   original,
+		merged:     original,
+		dataStruct: dataStruct,
+	}
+}
+
+// Mode returns the mode of the builder.
+func (b *PatchBuilder) Mode() PatchMode {
+	return b.mode
+}
+
+// Add adds the JSONPatch operation which adds value at path.
+func (b *PatchBuilder) Add(path string, value interface{}) {
+	b.addOperation(PatchOperation{Op: "add", Path: path, Value: value})
+}
+
+// Replace adds the JSONPatch operation which replaces the value at path.
+func (b *PatchBuilder) Replace(path string, value interface{}) {
+	b.addOperation(PatchOperation{Op: "replace", Path: path, Value: value})
+}
+
+func (b *PatchBuilder) addOperation(op PatchOperation) {
+	if b.mode != JSONPatchMode {
+		b.setErr(fmt.Errorf("%s operation on %s is not allowed in %s mode", op.Op, op.Path, b.mode))
+		return
+	}
+	b.operations = append(b.operations, op)
+}
+
+// Merge merges the strategic merge patch, a partial object in the form of the data struct, into the object.
+func (b *PatchBuilder) Merge(patch interface{}) {
+	if b.mode != StrategicMergePatchMode {
+		b.setErr(fmt.Errorf("merge is not allowed in %s mode", b.mode))
+		return
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		b.setErr(err)
+		return
+	}
+	merged, err := strategicpatch.StrategicMergePatch(b.merged, data, b.dataStruct)
+	if err != nil {
+		b.setErr(err)
+		return
+	}
+	b.merged = merged
+}
+
+func (b *PatchBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build returns the JSONPatch of the changes, nil if there is no change.
+func (b *PatchBuilder) Build() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	operations := b.operations
+	if b.mode == StrategicMergePatchMode {
+		var original, merged interface{}
+		if err := json.Unmarshal(b.original, &original); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b.merged, &merged); err != nil {
+			return nil, err
+		}
+		operations = diffJSON("", original, merged, nil)
+	}
+
+	if len(operations) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(operations)
+}
+
+// WriteResponse sets the patch of the admission response, and its patch type to the type of the patch.
+func (b *PatchBuilder) WriteResponse(response *v1beta1.AdmissionResponse) error {
+	patch, err := b.Build()
+	if err != nil {
+		return err
+	}
+	if patch == nil {
+		return nil
+	}
+
+	pt := v1beta1.PatchTypeJSONPatch
+	response.Patch = patch
+	response.PatchType = &pt
+
+	return nil
+}
+
+// diffJSON appends the JSONPatch operations which turn the decoded JSON value from into to;
+// objects are compared field by field, and other values are replaced as a whole.
+func diffJSON(path string, from, to interface{}, operations []PatchOperation) []PatchOperation {
+	fromObj, fromOK := from.(map[string]interface{})
+	toObj, toOK := to.(map[string]interface{})
+	if !fromOK || !toOK {
+		if !reflect.DeepEqual(from, to) {
+			operations = append(operations, PatchOperation{Op: "replace", Path: path, Value: to})
+		}
+		return operations
+	}
+
+	var keys []string
+	for key := range fromObj {
+		keys = append(keys, key)
+	}
+	for key := range toObj {
+		if _, found := fromObj[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := path + "/" + escapeJSONPointer(key)
+		fromValue, inFrom := fromObj[key]
+		toValue, inTo := toObj[key]
+		switch {
+		case !inTo:
+			operations = append(operations, PatchOperation{Op: "remove", Path: fieldPath})
+		case !inFrom:
+			operations = append(operations, PatchOperation{Op: "add", Path: fieldPath, Value: toValue})
+		default:
+			operations = diffJSON(fieldPath, fromValue, toValue, operations)
+		}
+	}
+
+	return operations
+}
+
+// escapeJSONPointer escapes a reference token of JSON pointer as RFC 6901.
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
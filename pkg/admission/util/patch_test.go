@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJSONPatchBuilder(t *testing.T) {
+	patch := NewJSONPatchBuilder()
+	patch.Add("/spec/queue", "default")
+	patch.Replace("/spec/minAvailable", 1)
+
+	response := &v1beta1.AdmissionResponse{}
+	if err := patch.WriteResponse(response); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var got []PatchOperation
+	if err := json.Unmarshal(response.Patch, &got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []PatchOperation{
+		{Op: "add", Path: "/spec/queue", Value: "default"},
+		{Op: "replace", Path: "/spec/minAvailable", Value: float64(1)},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected patch %v, got %v", expected, got)
+	}
+	if response.PatchType == nil || *response.PatchType != v1beta1.PatchTypeJSONPatch {
+		t.Errorf("expected patch type %s, got %v", v1beta1.PatchTypeJSONPatch, response.PatchType)
+	}
+
+	patch.Merge(map[string]interface{}{})
+	if _, err := patch.Build(); err == nil {
+		t.Errorf("expected error of merge in %s mode", JSONPatchMode)
+	}
+}
+
+func TestStrategicMergePatchBuilder(t *testing.T) {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pod",
+			Labels: map[string]string{"a/b": "c"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "main", Image: "busybox"},
+				{Name: "sidecar", Image: "proxy"},
+			},
+		},
+	}
+	original, _ := json.Marshal(pod)
+
+	testCases := []struct {
+		name    string
+		patches []interface{}
+		expect  []PatchOperation
+	}{
+		{
+			name: "containers are merged by name",
+			patches: []interface{}{
+				map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "sidecar", "imagePullPolicy": "IfNotPresent"},
+						},
+					},
+				},
+			},
+			expect: []PatchOperation{{
+				Op:   "replace",
+				Path: "/spec/containers",
+				Value: []interface{}{
+					map[string]interface{}{"name": "main", "image": "busybox", "resources": map[string]interface{}{}},
+					map[string]interface{}{"name": "sidecar", "image": "proxy", "resources": map[string]interface{}{},
+						"imagePullPolicy": "IfNotPresent"},
+				},
+			}},
+		},
+		{
+			name: "fields are added, replaced and removed",
+			patches: []interface{}{
+				map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"a/b": "d", "e": "f"}},
+				},
+				map[string]interface{}{
+					"spec": map[string]interface{}{"schedulerName": "volcano"},
+				},
+				map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"e": nil}},
+				},
+			},
+			expect: []PatchOperation{
+				{Op: "replace", Path: "/metadata/labels/a~1b", Value: "d"},
+				{Op: "add", Path: "/spec/schedulerName", Value: "volcano"},
+			},
+		},
+		{
+			name:    "no change",
+			patches: []interface{}{map[string]interface{}{"spec": map[string]interface{}{}}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		patch := NewStrategicMergePatchBuilder(original, v1.Pod{})
+		for _, p := range testCase.patches {
+			patch.Merge(p)
+		}
+
+		response := &v1beta1.AdmissionResponse{}
+		if err := patch.WriteResponse(response); err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.name, err)
+			continue
+		}
+
+		if len(testCase.expect) == 0 {
+			if response.Patch != nil || response.PatchType != nil {
+				t.Errorf("case %s: expected no patch, got %s", testCase.name, response.Patch)
+			}
+			continue
+		}
+
+		var got []PatchOperation
+		if err := json.Unmarshal(response.Patch, &got); err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, testCase.expect) {
+			t.Errorf("case %s: expected patch %v, got %v", testCase.name, testCase.expect, got)
+		}
+		if response.PatchType == nil || *response.PatchType != v1beta1.PatchTypeJSONPatch {
+			t.Errorf("case %s: expected patch type %s, got %v", testCase.name, v1beta1.PatchTypeJSONPatch, response.PatchType)
+		}
+	}
+
+	patch := NewStrategicMergePatchBuilder(original, v1.Pod{})
+	patch.Add("/spec/schedulerName", "volcano")
+	if _, err := patch.Build(); err == nil {
+		t.Errorf("expected error of JSONPatch operation in %s mode", StrategicMergePatchMode)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog"
+
+	batchv1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	schedulinglisters "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+)
+
+// DefaultQueue is the queue of the jobs and podgroups which do not specify one, unless their
+// namespace sets its default queue.
+const DefaultQueue = "default"
+
+// GetQueue gets the queue from the informer cache if there is one, otherwise from the apiserver.
+func GetQueue(lister schedulinglisters.QueueLister, client versioned.Interface, name string) (*schedulingv1alpha2.Queue, error) {
+	if lister != nil {
+		return lister.Get(name)
+	}
+
+	return client.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+}
+
+// GetDefaultQueue returns the queue set by the annotation of the namespace, or DefaultQueue if it is not set.
+func GetDefaultQueue(lister corelisters.NamespaceLister, namespace string) string {
+	if lister == nil {
+		return DefaultQueue
+	}
+
+	ns, err := lister.Get(namespace)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to get namespace <%s>, use queue <%s>: %v", namespace, DefaultQueue, err)
+		}
+		return DefaultQueue
+	}
+
+	if queue := ns.Annotations[batchv1alpha1.DefaultQueueAnnotationKey]; len(queue) != 0 {
+		return queue
+	}
+	return DefaultQueue
+}
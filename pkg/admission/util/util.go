@@ -1,5 +1,6 @@
 package util
 
+import "net/http"
 import "k8s.io/api/admission/v1beta1"
 import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 import "k8s.io/klog"
@@ -13,3 +14,36 @@ func ToAdmissionResponse(err error) *v1beta1.AdmissionResponse {
 		},
 	}
 }
+
+// ToDeniedResponse rejects the admission request because the object failed validation
+func ToDeniedResponse(message string) *v1beta1.AdmissionResponse {
+	return &v1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: message,
+		},
+	}
+}
+
+// ToInternalErrorResponse reports a failure of the admission service itself, e.g. the apiserver is
+// unreachable; such a response is served as HTTP 500, so the webhook failurePolicy decides the outcome
+func ToInternalErrorResponse(err error) *v1beta1.AdmissionResponse {
+	klog.Error(err)
+	return &v1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInternalError,
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		},
+	}
+}
+
+// IsInternalError returns whether the admission response reports a failure of the admission service
+func IsInternalError(response *v1beta1.AdmissionResponse) bool {
+	return response != nil && response.Result != nil && response.Result.Reason == metav1.StatusReasonInternalError
+}
@@ -85,8 +85,33 @@ type JobSpec struct {
 	// If specified, indicates the job's priority.
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty" protobuf:"bytes,10,opt,name=priorityClassName"`
+
+	// Suspend specifies whether the Job controller should run the pods of the Job or not.
+	// When set to true, the running pods of the Job are deleted, succeeded and failed pods are
+	// kept, and no new pod is created until it is set to false again.
+	// +optional
+	Suspend bool `json:"suspend,omitempty" protobuf:"varint,11,opt,name=suspend"`
+
+	// CompletionMode specifies how the completion of the Job is tracked, NonIndexed by default.
+	// With Indexed, every pod of the Job gets a stable completion index, from 0 to the total
+	// replicas of all tasks minus 1, in the VC_JOB_COMPLETION_INDEX environment variable and the
+	// volcano.sh/job-completion-index annotation, and the Job completes once every index succeeded.
+	// The indexes are started together as one gang: the PodGroup keeps minMember of minAvailable,
+	// the pods of succeeded indexes keep counting to it and are not recreated once deleted.
+	// +optional
+	CompletionMode CompletionMode `json:"completionMode,omitempty" protobuf:"bytes,12,opt,name=completionMode"`
 }
 
+// CompletionMode specifies how the completion of a Job is tracked.
+type CompletionMode string
+
+const (
+	// NonIndexedCompletion completes the Job once all of its pods are finished.
+	NonIndexedCompletion CompletionMode = "NonIndexed"
+	// IndexedCompletion completes the Job once a pod of every completion index succeeded.
+	IndexedCompletion CompletionMode = "Indexed"
+)
+
 // VolumeSpec defines the specification of Volume, e.g. PVC
 type VolumeSpec struct {
 	// Path within the container at which the volume should be mounted.  Must
@@ -116,6 +141,10 @@ const (
 	ExecuteAction JobEvent = "ExecuteAction"
 	//JobStatusError is generated if update job status failed
 	JobStatusError JobEvent = "JobStatusError"
+	// JobSuspended is generated when the pods of a suspended job are released
+	JobSuspended JobEvent = "Suspended"
+	// JobResumed is generated when a suspended job is resumed
+	JobResumed JobEvent = "Resumed"
 )
 
 // Event represent the phase of Job, e.g. pod-failed.
@@ -128,6 +157,9 @@ const (
 	PodFailedEvent Event = "PodFailed"
 	// PodEvictedEvent is triggered if Pod was deleted
 	PodEvictedEvent Event = "PodEvicted"
+	// PodOOMKilledEvent is triggered if a container of Pod was terminated for OOMKilled, no matter whether
+	// the Pod fails or the container is restarted; it only matches the policies listing it rather than AnyEvent
+	PodOOMKilledEvent Event = "PodOOMKilled"
 	// JobUnknownEvent These below are several events can lead to job 'Unknown'
 	// 1. Task Unschedulable, this is triggered when part of
 	//    pods can't be scheduled while some are already running in gang-scheduling case.
@@ -169,6 +201,8 @@ const (
 	SyncJobAction Action = "SyncJob"
 	// EnqueueAction is the action to sync Job inqueue status.
 	EnqueueAction Action = "EnqueueJob"
+	// SuspendJobAction is the action to release the pods of a job whose spec.suspend is set.
+	SuspendJobAction Action = "SuspendJob"
 )
 
 // LifecyclePolicy specifies the lifecycle and error handling of task and job.
@@ -216,6 +250,26 @@ type TaskSpec struct {
 	// Specifies the lifecycle of task
 	// +optional
 	Policies []LifecyclePolicy `json:"policies,omitempty" protobuf:"bytes,4,opt,name=policies"`
+
+	// DependsOn specifies the names of tasks in the same Job which must be running
+	// before the pods of this task are created
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty" protobuf:"bytes,5,rep,name=dependsOn"`
+
+	// MaxRetry specifies the number of times the failed pods of this task are recreated,
+	// the Job fails once the retries are exhausted; 0 means the failed pods are not recreated
+	// +optional
+	MaxRetry int32 `json:"maxRetry,omitempty" protobuf:"bytes,6,opt,name=maxRetry"`
+
+	// BackoffSeconds specifies the delay before a failed pod of this task is recreated,
+	// which is doubled for every retry of the task up to 5 minutes; defaults to 10
+	// +optional
+	BackoffSeconds *int32 `json:"backoffSeconds,omitempty" protobuf:"bytes,7,opt,name=backoffSeconds"`
+
+	// MinAvailable is the minimal available pods of this task to run the Job, in addition
+	// to the minAvailable of the Job; the Job is not scheduled until every task meets it.
+	// +optional
+	MinAvailable *int32 `json:"minAvailable,omitempty" protobuf:"bytes,8,opt,name=minAvailable"`
 }
 
 // JobPhase defines the phase of the job
@@ -242,6 +296,10 @@ const (
 	Terminated JobPhase = "Terminated"
 	// Failed is the phase that the job is restarted failed reached the maximum number of retries.
 	Failed JobPhase = "Failed"
+	// Suspending is the phase that the job is suspended, waiting for releasing pods
+	Suspending JobPhase = "Suspending"
+	// Suspended is the phase that the job is suspended and its pods are released
+	Suspended JobPhase = "Suspended"
 )
 
 // JobState contains details for the current state of the job.
@@ -305,6 +363,20 @@ type JobStatus struct {
 
 	// The resources that controlled by this job, e.g. Service, ConfigMap
 	ControlledResources map[string]string `json:"controlledResources,omitempty" protobuf:"bytes,11,opt,name=controlledResources"`
+
+	// The number of times the failed pods of each task are recreated.
+	// +optional
+	TaskRetryCount map[string]int32 `json:"taskRetryCount,omitempty" protobuf:"bytes,12,opt,name=taskRetryCount"`
+
+	// The completion indexes which succeeded, in ascending order and with ranges of consecutive
+	// indexes compressed, e.g. "0,2-4"; only tracked for Indexed completion mode.
+	// +optional
+	CompletedIndexes string `json:"completedIndexes,omitempty" protobuf:"bytes,13,opt,name=completedIndexes"`
+
+	// The generation of the job spec which the pods are created from; the pods of a superseded
+	// task template are deleted and created again from the current spec.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"bytes,14,opt,name=observedGeneration"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
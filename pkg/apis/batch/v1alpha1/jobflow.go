@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// JobFlow defines a flow of volcano jobs, every job of the flow is created once the jobs it depends on
+// are completed
+type JobFlow struct {
+	metav1.TypeMeta `json:",inline"`
+
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the stages of the flow
+	// +optional
+	Spec JobFlowSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Current status of JobFlow
+	// +optional
+	Status JobFlowStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// JobFlowSpec describes the stages of the flow and how a failure is handled
+type JobFlowSpec struct {
+	// Stages are the jobs of the flow
+	Stages []StageSpec `json:"stages,omitempty" protobuf:"bytes,1,opt,name=stages"`
+
+	// AbortOnFailure aborts the stages depending on a failed stage, directly or not; otherwise
+	// they keep waiting. The stages not depending on the failed stage are run in both cases.
+	// +optional
+	AbortOnFailure bool `json:"abortOnFailure,omitempty" protobuf:"varint,2,opt,name=abortOnFailure"`
+}
+
+// StageSpec describes a stage of the flow
+type StageSpec struct {
+	// Name of the stage, it is unique in the flow; the job of the stage is named <flow>-<stage>
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// DependsOn are the names of the stages which must be completed before the stage is started
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty" protobuf:"bytes,2,opt,name=dependsOn"`
+
+	// Template is the spec of the job of the stage
+	Template JobSpec `json:"template" protobuf:"bytes,3,opt,name=template"`
+}
+
+// StagePhase is the phase of a stage
+type StagePhase string
+
+const (
+	// StageWaiting is the phase that the stage waits for the stages it depends on
+	StageWaiting StagePhase = "Waiting"
+	// StageRunning is the phase that the job of the stage is created and not finished
+	StageRunning StagePhase = "Running"
+	// StageCompleted is the phase that the job of the stage is completed
+	StageCompleted StagePhase = "Completed"
+	// StageFailed is the phase that the job of the stage is finished without completing
+	StageFailed StagePhase = "Failed"
+	// StageAborted is the phase that the stage is not started as a stage it depends on failed
+	StageAborted StagePhase = "Aborted"
+)
+
+// StageStatus is the status of a stage
+type StageStatus struct {
+	// Name of the stage
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// Phase of the stage
+	Phase StagePhase `json:"phase,omitempty" protobuf:"bytes,2,opt,name=phase"`
+
+	// JobName is the name of the job of the stage, empty if the job is not created
+	// +optional
+	JobName string `json:"jobName,omitempty" protobuf:"bytes,3,opt,name=jobName"`
+}
+
+// JobFlowPhase is the phase of a flow
+type JobFlowPhase string
+
+const (
+	// FlowRunning is the phase that some stages of the flow are waiting or running
+	FlowRunning JobFlowPhase = "Running"
+	// FlowCompleted is the phase that all stages of the flow are completed
+	FlowCompleted JobFlowPhase = "Completed"
+	// FlowFailed is the phase that some stages failed and no other stage can be started
+	FlowFailed JobFlowPhase = "Failed"
+	// FlowInvalid is the phase that the stages of the flow are invalid, e.g. they depend on
+	// each other or on unknown stages; no job is created for an invalid flow
+	FlowInvalid JobFlowPhase = "Invalid"
+)
+
+// JobFlowStatus is the status of a flow
+type JobFlowStatus struct {
+	// Phase of the flow
+	// +optional
+	Phase JobFlowPhase `json:"phase,omitempty" protobuf:"bytes,1,opt,name=phase"`
+
+	// Message is a human readable message of the phase, e.g. why the flow is invalid
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
+
+	// Stages are the status of the stages, in the order of the spec
+	// +optional
+	Stages []StageStatus `json:"stages,omitempty" protobuf:"bytes,3,opt,name=stages"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// JobFlowList defines the list of flows
+type JobFlowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []JobFlow `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -27,8 +27,26 @@ const (
 	DefaultTaskSpec = "default"
 	// JobVersion job version key used in pod annotation
 	JobVersion = "volcano.sh/job-version"
+	// PodTemplateHashKey pod label of the hash of the task template which the pod is created from
+	PodTemplateHashKey = "volcano.sh/pod-template-hash"
 	// JobTypeKey job type key used in labels
 	JobTypeKey = "volcano.sh/job-type"
 	// PodgroupNamePrefix podgroup name prefix
 	PodgroupNamePrefix = "podgroup-"
+	// DefaultQueueAnnotationKey namespace annotation of the queue of jobs which do not specify one
+	DefaultQueueAnnotationKey = "volcano.sh/default-queue"
+	// JobFlowNameKey is the label of the jobs created for the stages of a JobFlow, the value is the flow name
+	JobFlowNameKey = "volcano.sh/job-flow-name"
+	// JobFlowStageKey is the label of the jobs created for the stages of a JobFlow, the value is the stage name
+	JobFlowStageKey = "volcano.sh/job-flow-stage"
+	// ResourcePercentageAnnotationKey task template annotation of the requests of its pods in percentages of the
+	// capability of the queue, e.g. "cpu=20,memory=10"; they are resolved on the first container at admission
+	ResourcePercentageAnnotationKey = "volcano.sh/resource-percentage"
+	// TaskResourcePolicyAnnotationKey job annotation of the resources which the pods of each task must or
+	// must not request, e.g. {"worker":{"required":["nvidia.com/gpu"]},"ps":{"forbidden":["nvidia.com/gpu"]}}
+	TaskResourcePolicyAnnotationKey = "volcano.sh/task-resource-policy"
+	// JobCompletionIndexAnnotationKey pod annotation of the completion index of pods of Indexed jobs
+	JobCompletionIndexAnnotationKey = "volcano.sh/job-completion-index"
+	// JobCompletionIndexEnv environment variable of the completion index of pods of Indexed jobs
+	JobCompletionIndexEnv = "VC_JOB_COMPLETION_INDEX"
 )
@@ -0,0 +1,25 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// CommandCreatorAnnotationKey is the annotation key of Command recording who created it,
+// it is copied into the status of the target object when the command is applied.
+const CommandCreatorAnnotationKey = "bus.volcano.sh/creator"
+
+// QueueNameLabelKey is the label key of Command naming the queue it targets, so that the commands
+// of a queue are selected, e.g. to delete a batch of them in one call.
+const QueueNameLabelKey = "bus.volcano.sh/queue-name"
@@ -46,6 +46,9 @@ import (
 // JobKind  creates job GroupVersionKind
 var JobKind = vcbatch.SchemeGroupVersion.WithKind("Job")
 
+// JobFlowKind creates jobflow GroupVersionKind
+var JobFlowKind = vcbatch.SchemeGroupVersion.WithKind("JobFlow")
+
 // CommandKind  creates command GroupVersionKind
 var CommandKind = vcbus.SchemeGroupVersion.WithKind("Command")
 
@@ -191,8 +194,52 @@ func GeneratePodgroupName(pod *v1.Pod) string {
 	return pgName
 }
 
+// GetPodRequests returns the resources requested by a pod of the spec, which is the sum of the requests
+// of its containers, or the request of an init container if it is larger, as the init containers run
+// one by one before the containers. The limit of a container is used for the resources it does not
+// request, as the apiserver defaults the requests to the limits.
+func GetPodRequests(spec *v1.PodSpec) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for _, c := range spec.Containers {
+		for name, quantity := range containerRequests(c) {
+			total := requests[name]
+			total.Add(quantity)
+			requests[name] = total
+		}
+	}
+
+	for _, c := range spec.InitContainers {
+		for name, quantity := range containerRequests(c) {
+			if total, found := requests[name]; !found || total.Cmp(quantity) < 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	return requests
+}
+
+func containerRequests(c v1.Container) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for name, quantity := range c.Resources.Limits {
+		requests[name] = quantity
+	}
+	for name, quantity := range c.Resources.Requests {
+		requests[name] = quantity
+	}
+
+	return requests
+}
+
 // StartHealthz register healthz interface
 func StartHealthz(healthzBindAddress, name string) error {
+	return StartHealthzWithOptions(healthzBindAddress, name, false, nil)
+}
+
+// StartHealthzWithOptions register healthz and readyz interface, and the handlers by their paths,
+// e.g. a /readyz of its own replaces the default one which always reports ready; pprof handlers
+// are registered if enablePprof is true.
+func StartHealthzWithOptions(healthzBindAddress, name string, enablePprof bool, handlers map[string]http.Handler) error {
 	listener, err := net.Listen("tcp", healthzBindAddress)
 	if err != nil {
 		return fmt.Errorf("failed to create listener: %v", err)
@@ -200,6 +247,16 @@ func StartHealthz(healthzBindAddress, name string) error {
 
 	pathRecorderMux := mux.NewPathRecorderMux(name)
 	healthz.InstallHandler(pathRecorderMux)
+	if _, found := handlers["/readyz"]; !found {
+		healthz.InstallPathHandler(pathRecorderMux, "/readyz")
+	}
+	for path, handler := range handlers {
+		pathRecorderMux.Handle(path, handler)
+	}
+
+	if enablePprof {
+		installPprof(pathRecorderMux)
+	}
 
 	server := &http.Server{
 		Addr:           listener.Addr().String(),
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetPodRequests(t *testing.T) {
+	container := func(requests, limits v1.ResourceList) v1.Container {
+		return v1.Container{Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+	cpu := func(quantity string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(quantity)}
+	}
+
+	testCases := []struct {
+		name     string
+		spec     v1.PodSpec
+		expected v1.ResourceList
+	}{
+		{
+			name: "containers are summed up",
+			spec: v1.PodSpec{Containers: []v1.Container{
+				container(cpu("1"), nil),
+				container(cpu("500m"), nil),
+			}},
+			expected: cpu("1500m"),
+		},
+		{
+			name: "limit is used for resources not requested",
+			spec: v1.PodSpec{Containers: []v1.Container{
+				container(cpu("1"), nil),
+				container(nil, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("2"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				}),
+			}},
+			expected: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("3"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		{
+			name: "larger init container",
+			spec: v1.PodSpec{
+				InitContainers: []v1.Container{container(cpu("3"), nil), container(cpu("1"), nil)},
+				Containers:     []v1.Container{container(cpu("1"), nil), container(cpu("1"), nil)},
+			},
+			expected: cpu("3"),
+		},
+		{
+			name: "smaller init container",
+			spec: v1.PodSpec{
+				InitContainers: []v1.Container{container(cpu("1"), nil)},
+				Containers:     []v1.Container{container(cpu("2"), nil)},
+			},
+			expected: cpu("2"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		if requests := GetPodRequests(&testCase.spec); !equality.Semantic.DeepEqual(requests, testCase.expected) {
+			t.Errorf("case %s: expected %v, got %v", testCase.name, testCase.expected, requests)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/mux"
+)
+
+const pprofPrefix = "/debug/pprof/"
+
+// installPprof registers the pprof handlers on the mux. net/http/pprof is not imported, as its init
+// registers the handlers on http.DefaultServeMux, which is served by the webhooks and the metrics.
+func installPprof(m *mux.PathRecorderMux) {
+	m.HandlePrefix(pprofPrefix, http.HandlerFunc(pprofIndex))
+	m.HandleFunc(pprofPrefix+"cmdline", pprofCmdline)
+	m.HandleFunc(pprofPrefix+"profile", pprofProfile)
+	m.HandleFunc(pprofPrefix+"trace", pprofTrace)
+}
+
+// pprofIndex writes the named profile, e.g. /debug/pprof/heap, or lists the profiles.
+func pprofIndex(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, pprofPrefix)
+	if name == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, profile := range pprof.Profiles() {
+			fmt.Fprintf(w, "%d\t%s\n", profile.Count(), profile.Name())
+		}
+		return
+	}
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("unknown profile %s", name), http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.FormValue("debug"))
+	if debug != 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	if name == "heap" && r.FormValue("gc") != "" {
+		runtime.GC()
+	}
+	if err := profile.WriteTo(w, debug); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// pprofCmdline writes the command line of the process, the arguments are separated by NUL bytes.
+func pprofCmdline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.Join(os.Args, "\x00"))
+}
+
+// pprofProfile writes the CPU profile of the given seconds, 30 by default.
+func pprofProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		http.Error(w, fmt.Sprintf("could not enable CPU profiling: %v", err), http.StatusInternalServerError)
+		return
+	}
+	sleep(r, 30)
+	pprof.StopCPUProfile()
+}
+
+// pprofTrace writes the execution trace of the given seconds, 1 by default.
+func pprofTrace(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		http.Error(w, fmt.Sprintf("could not enable tracing: %v", err), http.StatusInternalServerError)
+		return
+	}
+	sleep(r, 1)
+	trace.Stop()
+}
+
+// sleep waits for the seconds of the request, or the default seconds, until the request is done.
+func sleep(r *http.Request, defaultSeconds float64) {
+	seconds, err := strconv.ParseFloat(r.FormValue("seconds"), 64)
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+
+	select {
+	case <-time.After(time.Duration(seconds * float64(time.Second))):
+	case <-r.Context().Done():
+	}
+}
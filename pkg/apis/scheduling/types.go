@@ -36,6 +36,9 @@ const (
 	QueueStateClosing QueueState = "Closing"
 	// QueueStateUnknown indicate `Unknown` state of queue
 	QueueStateUnknown QueueState = "Unknown"
+	// QueueStateDraining indicate `Draining` state of queue, the queue is being deleted
+	// and waits for its podgroups to finish
+	QueueStateDraining QueueState = "Draining"
 )
 
 // These are the valid phase of podGroups.
@@ -54,6 +57,9 @@ const (
 	// PodGroupInqueue means controllers can start to create pods,
 	// is a new state between PodGroupPending and PodGroupRunning
 	PodGroupInqueue PodGroupPhase = "Inqueue"
+
+	// PodGroupCompleted means all pods of PodGroup have succeeded.
+	PodGroupCompleted PodGroupPhase = "Completed"
 )
 
 type PodGroupConditionType string
@@ -64,6 +70,26 @@ const (
 
 	// PodGroupScheduled is scheduled event type
 	PodGroupScheduled PodGroupConditionType = "Scheduled"
+
+	// PodGroupGangDowngradedType means a SoftGang PodGroup could not be scheduled as a gang within
+	// its gang timeout, and its members are scheduled best-effort.
+	PodGroupGangDowngradedType PodGroupConditionType = "GangDowngraded"
+
+	// PodGroupPreemptedType means tasks of the PodGroup were preempted by other PodGroups, the
+	// PodGroup is not preempted again until the preemption cooldown passes.
+	PodGroupPreemptedType PodGroupConditionType = "Preempted"
+)
+
+// PodGroupSchedulingPolicy is how the members of PodGroup are scheduled.
+type PodGroupSchedulingPolicy string
+
+const (
+	// GangSchedulingPolicy starts the members only if minMember of them can be scheduled.
+	GangSchedulingPolicy PodGroupSchedulingPolicy = "Gang"
+
+	// SoftGangSchedulingPolicy schedules the members as a gang until the gang timeout expires,
+	// and best-effort afterwards.
+	SoftGangSchedulingPolicy PodGroupSchedulingPolicy = "SoftGang"
 )
 
 type PodGroupConditionDetail string
@@ -111,6 +137,12 @@ const (
 
 	// NotEnoughPodsReason is probed if there're not enough tasks compared to `spec.minMember`
 	NotEnoughPodsReason string = "NotEnoughTasks"
+
+	// GangTimeoutReason is probed if the SoftGang podgroup is not scheduled as a gang within its gang timeout
+	GangTimeoutReason string = "GangTimeout"
+
+	// PreemptedReason is probed if tasks of the podgroup are preempted by other podgroups
+	PreemptedReason string = "Preempted"
 )
 
 // QueueEvent represent the phase of queue
@@ -121,6 +153,8 @@ const (
 	QueueOutOfSyncEvent QueueEvent = "OutOfSync"
 	// QueueCommandIssuedEvent is triggered if a command is raised by user
 	QueueCommandIssuedEvent QueueEvent = "CommandIssued"
+	// QueueIdleEvent is triggered if the queue has no pending or running PodGroups for the idle timeout
+	QueueIdleEvent QueueEvent = "Idle"
 )
 
 // QueueAction is the action that queue controller will take according to the event.
@@ -133,6 +167,11 @@ const (
 	OpenQueueAction QueueAction = "OpenQueue"
 	// CloseQueueAction is the action to close queue
 	CloseQueueAction QueueAction = "CloseQueue"
+	// CordonQueueAction is the action to cordon queue, a cordoned queue rejects new jobs
+	// but keeps scheduling the admitted ones
+	CordonQueueAction QueueAction = "CordonQueue"
+	// UncordonQueueAction is the action to uncordon queue
+	UncordonQueueAction QueueAction = "UncordonQueue"
 )
 
 // +genclient
@@ -181,6 +220,21 @@ type PodGroupSpec struct {
 	// if there's not enough resources to start all tasks, the scheduler
 	// will not start anyone.
 	MinResources *v1.ResourceList
+
+	// SchedulingPolicy defines how the members are scheduled, Gang or SoftGang. Defaults to Gang.
+	// +optional
+	SchedulingPolicy PodGroupSchedulingPolicy
+
+	// GangTimeoutSeconds is the time after creation within which a SoftGang PodGroup is scheduled
+	// as a gang; once it expires, the members which can be scheduled are started.
+	// +optional
+	GangTimeoutSeconds *int32
+
+	// MinTaskMember defines the minimal number of members of each task, by the name of the task
+	// in the volcano.sh/task-spec annotation of the pods, in addition to MinMember; the pod group
+	// is not started until every task meets it.
+	// +optional
+	MinTaskMember map[string]int32
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -253,6 +307,66 @@ type QueueStatus struct {
 	Inqueue int32
 	// State is status of queue
 	State QueueState
+	// The number of `Completed` PodGroup in this queue.
+	Completed int32
+	// UnmetGuarantee is the part of guaranteed resources which can not be reserved for
+	// the queue, as the guarantees of all queues exceed the cluster capacity; it is set by scheduler.
+	UnmetGuarantee v1.ResourceList
+	// Conditions records the last transitions of the queue state, the latest one is the last.
+	Conditions []QueueCondition
+	// Overcommitted indicates the queue is allocated more resources than its capability
+	// by the overcommit ratio; it is set by scheduler.
+	Overcommitted bool
+	// LastCommand records the last command applied to the queue, e.g. to open or close it.
+	LastCommand *QueueCommandRecord
+	// Used is the sum of minResources of the 'Inqueue', 'Running' and 'Unknown' PodGroups in this queue
+	// and its descendants, per resource name including extended resources such as GPUs.
+	Used v1.ResourceList
+	// EffectiveWeight is the weight used by scheduler instead of spec.weight, it is adjusted by
+	// queue controller according to the historical usage of the queue when dynamic weight is enabled.
+	EffectiveWeight int32
+	// Capability is the capability used by scheduler instead of spec.capability, it is resolved by
+	// queue controller from spec.capabilityPercent against the allocatable resources of the cluster.
+	Capability v1.ResourceList
+	// ReclaimBudget is the part of spec.maxReclaimPerCycle left after the last reclaim of the
+	// queue; it is set by scheduler.
+	ReclaimBudget v1.ResourceList
+}
+
+// QueueCommandRecord records a command applied to the queue.
+type QueueCommandRecord struct {
+	// Action is the action of the command.
+	Action QueueAction
+
+	// Command is the namespace/name of the command.
+	Command string
+
+	// Creator is the creator of the command, taken from its creator annotation.
+	Creator string
+
+	// Time is when the command was applied.
+	Time metav1.Time
+}
+
+// QueueCondition records a transition of the queue state.
+type QueueCondition struct {
+	// Type is the state which the queue transitioned to.
+	Type QueueState
+
+	// Status is the status of the condition.
+	Status v1.ConditionStatus
+
+	// PreviousState is the state which the queue transitioned from.
+	PreviousState QueueState
+
+	// Last time the state transitioned from previous state to current state.
+	LastTransitionTime metav1.Time
+
+	// Unique, one-word, CamelCase reason for the transition, e.g. the action of the queue.
+	Reason string
+
+	// Human-readable message indicating details about the transition.
+	Message string
 }
 
 // QueueSpec represents the template of Queue.
@@ -261,6 +375,29 @@ type QueueSpec struct {
 	Capability v1.ResourceList
 	// State controller the status of queue
 	State QueueState
+	// Parent is the name of the parent queue, empty for a root queue
+	Parent string
+	// Guaranteed is the amount of resources reserved for the queue
+	Guaranteed v1.ResourceList
+	// Reclaimable indicates whether resources of the queue can be reclaimed by other queues
+	Reclaimable *bool
+	// Priority of the queue, the queue with higher priority is considered before the ones with
+	// lower priority whatever their share are; it must be the value of a PriorityClass
+	Priority int32
+	// OvercommitRatio allows the queue to exceed its capability by the ratio, e.g. 1.2, while the
+	// cluster has headroom, i.e. the requests of all queues do not exceed the cluster capacity;
+	// the queue shrinks back to its capability under contention. It must not be less than 1.
+	OvercommitRatio *float64
+	// CapabilityPercent is the capability of the queue as the percentage of the total allocatable
+	// resources of the cluster per resource name; the capability of a resource set in both is taken
+	// from Capability.
+	CapabilityPercent map[v1.ResourceName]int32
+	// NamespaceQuotas limits the resources requested by the pods of the queue per namespace; the
+	// namespaces not listed are not limited.
+	NamespaceQuotas map[string]v1.ResourceList
+	// MaxReclaimPerCycle caps the resources the queue reclaims from other queues in one scheduling
+	// cycle per resource name; the resources not listed are not capped.
+	MaxReclaimPerCycle v1.ResourceList
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
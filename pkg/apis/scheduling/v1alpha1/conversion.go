@@ -22,6 +22,7 @@ func addConversionFuncs(scheme *runtime.Scheme) error {
 	err := scheme.AddConversionFuncs(
 		Convert_scheduling_QueueStatus_To_v1alpha1_QueueStatus,
 		Convert_scheduling_QueueSpec_To_v1alpha1_QueueSpec,
+		Convert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec,
 	)
 	if err != nil {
 		return err
@@ -42,3 +43,8 @@ func Convert_scheduling_QueueSpec_To_v1alpha1_QueueSpec(in *scheduling.QueueSpec
 	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
 	return nil
 }
+
+func Convert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(in *scheduling.PodGroupSpec, out *PodGroupSpec, s conversion.Scope) error {
+	// SchedulingPolicy, GangTimeoutSeconds and MinTaskMember are not supported by v1alpha1.
+	return autoConvert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(in, out, s)
+}
@@ -126,6 +126,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*scheduling.PodGroupSpec)(nil), (*PodGroupSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(a.(*scheduling.PodGroupSpec), b.(*PodGroupSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*scheduling.QueueSpec)(nil), (*QueueSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_scheduling_QueueSpec_To_v1alpha1_QueueSpec(a.(*scheduling.QueueSpec), b.(*QueueSpec), scope)
 	}); err != nil {
@@ -203,7 +208,17 @@ func Convert_scheduling_PodGroupCondition_To_v1alpha1_PodGroupCondition(in *sche
 
 func autoConvert_v1alpha1_PodGroupList_To_scheduling_PodGroupList(in *PodGroupList, out *scheduling.PodGroupList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]scheduling.PodGroup)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]scheduling.PodGroup, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_PodGroup_To_scheduling_PodGroup(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -214,7 +229,17 @@ func Convert_v1alpha1_PodGroupList_To_scheduling_PodGroupList(in *PodGroupList,
 
 func autoConvert_scheduling_PodGroupList_To_v1alpha1_PodGroupList(in *scheduling.PodGroupList, out *PodGroupList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]PodGroup)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodGroup, len(*in))
+		for i := range *in {
+			if err := Convert_scheduling_PodGroup_To_v1alpha1_PodGroup(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -241,14 +266,12 @@ func autoConvert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(in *scheduling
 	out.Queue = in.Queue
 	out.PriorityClassName = in.PriorityClassName
 	out.MinResources = (*v1.ResourceList)(unsafe.Pointer(in.MinResources))
+	// WARNING: in.SchedulingPolicy requires manual conversion: does not exist in peer-type
+	// WARNING: in.GangTimeoutSeconds requires manual conversion: does not exist in peer-type
+	// WARNING: in.MinTaskMember requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec is an autogenerated conversion function.
-func Convert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(in *scheduling.PodGroupSpec, out *PodGroupSpec, s conversion.Scope) error {
-	return autoConvert_scheduling_PodGroupSpec_To_v1alpha1_PodGroupSpec(in, out, s)
-}
-
 func autoConvert_v1alpha1_PodGroupStatus_To_scheduling_PodGroupStatus(in *PodGroupStatus, out *scheduling.PodGroupStatus, s conversion.Scope) error {
 	out.Phase = scheduling.PodGroupPhase(in.Phase)
 	out.Conditions = *(*[]scheduling.PodGroupCondition)(unsafe.Pointer(&in.Conditions))
@@ -366,6 +389,14 @@ func autoConvert_scheduling_QueueSpec_To_v1alpha1_QueueSpec(in *scheduling.Queue
 	out.Weight = in.Weight
 	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
 	// WARNING: in.State requires manual conversion: does not exist in peer-type
+	// WARNING: in.Parent requires manual conversion: does not exist in peer-type
+	// WARNING: in.Guaranteed requires manual conversion: does not exist in peer-type
+	// WARNING: in.Reclaimable requires manual conversion: does not exist in peer-type
+	// WARNING: in.Priority requires manual conversion: does not exist in peer-type
+	// WARNING: in.OvercommitRatio requires manual conversion: does not exist in peer-type
+	// WARNING: in.CapabilityPercent requires manual conversion: does not exist in peer-type
+	// WARNING: in.NamespaceQuotas requires manual conversion: does not exist in peer-type
+	// WARNING: in.MaxReclaimPerCycle requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -387,5 +418,14 @@ func autoConvert_scheduling_QueueStatus_To_v1alpha1_QueueStatus(in *scheduling.Q
 	out.Running = in.Running
 	// WARNING: in.Inqueue requires manual conversion: does not exist in peer-type
 	// WARNING: in.State requires manual conversion: does not exist in peer-type
+	// WARNING: in.Completed requires manual conversion: does not exist in peer-type
+	// WARNING: in.UnmetGuarantee requires manual conversion: does not exist in peer-type
+	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
+	// WARNING: in.Overcommitted requires manual conversion: does not exist in peer-type
+	// WARNING: in.LastCommand requires manual conversion: does not exist in peer-type
+	// WARNING: in.Used requires manual conversion: does not exist in peer-type
+	// WARNING: in.EffectiveWeight requires manual conversion: does not exist in peer-type
+	// WARNING: in.Capability requires manual conversion: does not exist in peer-type
+	// WARNING: in.ReclaimBudget requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -19,3 +19,44 @@ package v1alpha2
 // GroupNameAnnotationKey is the annotation key of Pod to identify
 // which PodGroup it belongs to.
 const GroupNameAnnotationKey = "scheduling.k8s.io/group-name"
+
+// OwnPodGroupsAnnotationKey is the annotation key of Queue to enable the queue controller
+// to own the PodGroups of the Queue by ControllerRef, so the PodGroups are deleted together
+// with the Queue unless it is deleted with the orphan policy.
+const OwnPodGroupsAnnotationKey = "scheduling.volcano.sh/own-podgroups"
+
+// AutoMinResourcesAnnotationKey is the annotation key of PodGroup to control whether the podgroup
+// controller computes its minResources from the member pods. It is computed when minResources is
+// not set unless the annotation is "false"; once computed, the annotation is set to "true" so that
+// minResources is recomputed as the member pods change.
+const AutoMinResourcesAnnotationKey = "scheduling.volcano.sh/auto-min-resources"
+
+// DefaultMinResourcesAnnotationKey is the annotation key of Queue holding the default minResources,
+// in the JSON form of a resource list such as {"cpu":"1","memory":"1Gi"}, of the PodGroups created
+// in the Queue without minResources.
+const DefaultMinResourcesAnnotationKey = "scheduling.volcano.sh/default-min-resources"
+
+// IdleClosedAnnotationKey is the annotation key of Queue set to "true" when the queue controller
+// closes the Queue as it is idle; such a Queue is opened again once a PodGroup is created in it.
+const IdleClosedAnnotationKey = "scheduling.volcano.sh/idle-closed"
+
+// CordonedAnnotationKey is the annotation key of Queue set to "true" when the Queue is cordoned;
+// new jobs are rejected in a cordoned Queue, while its admitted PodGroups are still scheduled.
+const CordonedAnnotationKey = "scheduling.volcano.sh/cordoned"
+
+// ForceDeleteAnnotationKey is the annotation key of Queue set to "true" to allow deleting the Queue
+// while it still has PodGroups, which are then drained by the queue controller.
+const ForceDeleteAnnotationKey = "volcano.sh/force-delete"
+
+// NoReclaimAnnotationKey is the annotation key of PodGroup set to "true" to exempt its tasks from
+// reclaim, e.g. for serving workloads; it is only admitted in the Queues allowing it.
+const NoReclaimAnnotationKey = "volcano.sh/no-reclaim"
+
+// AllowNoReclaimAnnotationKey is the annotation key of Queue set to "true" to allow its PodGroups
+// to be annotated with NoReclaimAnnotationKey.
+const AllowNoReclaimAnnotationKey = "volcano.sh/allow-no-reclaim"
+
+// QueueNameAnnotationKey is the annotation key of Pod to pin the pod to a Queue other than the one of
+// its PodGroup, e.g. for a preemptible sidecar; the pod is moved to a PodGroup of its own in that Queue,
+// so the minAvailable of its job should not count it.
+const QueueNameAnnotationKey = "volcano.sh/queue-name"
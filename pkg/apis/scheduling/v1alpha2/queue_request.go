@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewQueueRequest creates a QueueRequest, it returns an error if the action is unknown.
+func NewQueueRequest(name string, event QueueEvent, action QueueAction) (*QueueRequest, error) {
+	if err := ValidateQueueAction(action); err != nil {
+		return nil, err
+	}
+
+	return &QueueRequest{
+		Name:   name,
+		Event:  event,
+		Action: action,
+	}, nil
+}
+
+// validQueueActions are the actions handled by the queue controller.
+var validQueueActions = []QueueAction{
+	SyncQueueAction,
+	OpenQueueAction,
+	CloseQueueAction,
+	CordonQueueAction,
+	UncordonQueueAction,
+}
+
+// ValidActions returns the actions handled by the queue controller.
+func ValidActions() []QueueAction {
+	return append([]QueueAction(nil), validQueueActions...)
+}
+
+// ValidateQueueAction returns an error naming the valid actions if the action is not a known QueueAction.
+func ValidateQueueAction(action QueueAction) error {
+	var names []string
+	for _, valid := range validQueueActions {
+		if action == valid {
+			return nil
+		}
+		names = append(names, string(valid))
+	}
+
+	return fmt.Errorf("unknown queue action %q, expected one of %s", action, strings.Join(names, ", "))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewQueueRequest(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		action      QueueAction
+		ExpectedReq *QueueRequest
+		ExpectErr   bool
+	}{
+		{
+			Name:   "known action",
+			action: OpenQueueAction,
+			ExpectedReq: &QueueRequest{
+				Name:   "q1",
+				Event:  QueueCommandIssuedEvent,
+				Action: OpenQueueAction,
+			},
+		},
+		{
+			Name:      "typo in action",
+			action:    "OpenQueeu",
+			ExpectErr: true,
+		},
+		{
+			Name:      "empty action",
+			action:    "",
+			ExpectErr: true,
+		},
+	}
+
+	for i, testcase := range testCases {
+		req, err := NewQueueRequest("q1", QueueCommandIssuedEvent, testcase.action)
+		if (err != nil) != testcase.ExpectErr {
+			t.Errorf("case %d (%s): expected error %v, got %v", i, testcase.Name, testcase.ExpectErr, err)
+		}
+		if !reflect.DeepEqual(req, testcase.ExpectedReq) {
+			t.Errorf("case %d (%s): expected request %v, got %v", i, testcase.Name, testcase.ExpectedReq, req)
+		}
+	}
+}
+
+func TestValidateQueueAction(t *testing.T) {
+	for _, action := range ValidActions() {
+		if err := ValidateQueueAction(action); err != nil {
+			t.Errorf("expected action %s to be valid, got %v", action, err)
+		}
+	}
+
+	err := ValidateQueueAction("DrainQueue")
+	if err == nil {
+		t.Fatalf("expected action DrainQueue to be invalid")
+	}
+	expected := `unknown queue action "DrainQueue", expected one of SyncQueue, OpenQueue, CloseQueue, CordonQueue, UncordonQueue`
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestValidActions(t *testing.T) {
+	expected := []QueueAction{SyncQueueAction, OpenQueueAction, CloseQueueAction, CordonQueueAction, UncordonQueueAction}
+	actions := ValidActions()
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected actions %v, got %v", expected, actions)
+	}
+
+	actions[0] = "Changed"
+	if !reflect.DeepEqual(ValidActions(), expected) {
+		t.Errorf("expected ValidActions to return a copy")
+	}
+}
@@ -36,6 +36,9 @@ const (
 	QueueStateClosing QueueState = "Closing"
 	// QueueStateUnknown indicate `Unknown` state of queue
 	QueueStateUnknown QueueState = "Unknown"
+	// QueueStateDraining indicate `Draining` state of queue, the queue is being deleted
+	// and waits for its podgroups to finish
+	QueueStateDraining QueueState = "Draining"
 )
 
 // These are the valid phase of podGroups.
@@ -54,6 +57,9 @@ const (
 	// PodGroupInqueue means controllers can start to create pods,
 	// is a new state between PodGroupPending and PodGroupRunning
 	PodGroupInqueue PodGroupPhase = "Inqueue"
+
+	// PodGroupCompleted means all pods of PodGroup have succeeded.
+	PodGroupCompleted PodGroupPhase = "Completed"
 )
 
 type PodGroupConditionType string
@@ -64,6 +70,26 @@ const (
 
 	// PodGroupScheduled is scheduled event type
 	PodGroupScheduled PodGroupConditionType = "Scheduled"
+
+	// PodGroupGangDowngradedType means a SoftGang PodGroup could not be scheduled as a gang within
+	// its gang timeout, and its members are scheduled best-effort.
+	PodGroupGangDowngradedType PodGroupConditionType = "GangDowngraded"
+
+	// PodGroupPreemptedType means tasks of the PodGroup were preempted by other PodGroups, the
+	// PodGroup is not preempted again until the preemption cooldown passes.
+	PodGroupPreemptedType PodGroupConditionType = "Preempted"
+)
+
+// PodGroupSchedulingPolicy is how the members of PodGroup are scheduled.
+type PodGroupSchedulingPolicy string
+
+const (
+	// GangSchedulingPolicy starts the members only if minMember of them can be scheduled.
+	GangSchedulingPolicy PodGroupSchedulingPolicy = "Gang"
+
+	// SoftGangSchedulingPolicy schedules the members as a gang until the gang timeout expires,
+	// and best-effort afterwards.
+	SoftGangSchedulingPolicy PodGroupSchedulingPolicy = "SoftGang"
 )
 
 type PodGroupConditionDetail string
@@ -111,6 +137,12 @@ const (
 
 	// NotEnoughPodsReason is probed if there're not enough tasks compared to `spec.minMember`
 	NotEnoughPodsReason string = "NotEnoughTasks"
+
+	// GangTimeoutReason is probed if the SoftGang podgroup is not scheduled as a gang within its gang timeout
+	GangTimeoutReason string = "GangTimeout"
+
+	// PreemptedReason is probed if tasks of the podgroup are preempted by other podgroups
+	PreemptedReason string = "Preempted"
 )
 
 // QueueEvent represent the phase of queue
@@ -121,6 +153,8 @@ const (
 	QueueOutOfSyncEvent QueueEvent = "OutOfSync"
 	// QueueCommandIssuedEvent is triggered if a command is raised by user
 	QueueCommandIssuedEvent QueueEvent = "CommandIssued"
+	// QueueIdleEvent is triggered if the queue has no pending or running PodGroups for the idle timeout
+	QueueIdleEvent QueueEvent = "Idle"
 )
 
 // QueueAction is the action that queue controller will take according to the event.
@@ -133,6 +167,11 @@ const (
 	OpenQueueAction QueueAction = "OpenQueue"
 	// CloseQueueAction is the action to close queue
 	CloseQueueAction QueueAction = "CloseQueue"
+	// CordonQueueAction is the action to cordon queue, a cordoned queue rejects new jobs
+	// but keeps scheduling the admitted ones
+	CordonQueueAction QueueAction = "CordonQueue"
+	// UncordonQueueAction is the action to uncordon queue
+	UncordonQueueAction QueueAction = "UncordonQueue"
 )
 
 // +genclient
@@ -182,6 +221,21 @@ type PodGroupSpec struct {
 	// if there's not enough resources to start all tasks, the scheduler
 	// will not start anyone.
 	MinResources *v1.ResourceList `json:"minResources,omitempty" protobuf:"bytes,4,opt,name=minResources"`
+
+	// SchedulingPolicy defines how the members are scheduled, Gang or SoftGang. Defaults to Gang.
+	// +optional
+	SchedulingPolicy PodGroupSchedulingPolicy `json:"schedulingPolicy,omitempty" protobuf:"bytes,5,opt,name=schedulingPolicy"`
+
+	// GangTimeoutSeconds is the time after creation within which a SoftGang PodGroup is scheduled
+	// as a gang; once it expires, the members which can be scheduled are started.
+	// +optional
+	GangTimeoutSeconds *int32 `json:"gangTimeoutSeconds,omitempty" protobuf:"bytes,6,opt,name=gangTimeoutSeconds"`
+
+	// MinTaskMember defines the minimal number of members of each task, by the name of the task
+	// in the volcano.sh/task-spec annotation of the pods, in addition to MinMember; the pod group
+	// is not started until every task meets it.
+	// +optional
+	MinTaskMember map[string]int32 `json:"minTaskMember,omitempty" protobuf:"bytes,7,rep,name=minTaskMember"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -254,6 +308,81 @@ type QueueStatus struct {
 	Inqueue int32 `json:"inqueue,omitempty" protobuf:"bytes,4,opt,name=inqueue"`
 	// State is state of queue
 	State QueueState `json:"state,omitempty" protobuf:"bytes,5,opt,name=state"`
+	// The number of `Completed` PodGroup in this queue.
+	Completed int32 `json:"completed,omitempty" protobuf:"bytes,6,opt,name=completed"`
+	// UnmetGuarantee is the part of guaranteed resources which can not be reserved for
+	// the queue, as the guarantees of all queues exceed the cluster capacity; it is set by scheduler.
+	UnmetGuarantee v1.ResourceList `json:"unmetGuarantee,omitempty" protobuf:"bytes,7,opt,name=unmetGuarantee"`
+	// Conditions records the last transitions of the queue state, the latest one is the last.
+	// +optional
+	Conditions []QueueCondition `json:"conditions,omitempty" protobuf:"bytes,8,rep,name=conditions"`
+	// Overcommitted indicates the queue is allocated more resources than its capability
+	// by the overcommit ratio; it is set by scheduler.
+	// +optional
+	Overcommitted bool `json:"overcommitted,omitempty" protobuf:"varint,9,opt,name=overcommitted"`
+	// LastCommand records the last command applied to the queue, e.g. to open or close it.
+	// +optional
+	LastCommand *QueueCommandRecord `json:"lastCommand,omitempty" protobuf:"bytes,10,opt,name=lastCommand"`
+	// Used is the sum of minResources of the 'Inqueue', 'Running' and 'Unknown' PodGroups in this queue
+	// and its descendants, per resource name including extended resources such as GPUs.
+	// +optional
+	Used v1.ResourceList `json:"used,omitempty" protobuf:"bytes,11,opt,name=used"`
+	// EffectiveWeight is the weight used by scheduler instead of spec.weight, it is adjusted by
+	// queue controller within bounds of spec.weight according to the historical usage of the queue
+	// when dynamic weight is enabled, and unset otherwise.
+	// +optional
+	EffectiveWeight int32 `json:"effectiveWeight,omitempty" protobuf:"varint,12,opt,name=effectiveWeight"`
+	// Capability is the capability used by scheduler instead of spec.capability, it is resolved by
+	// queue controller from spec.capabilityPercent against the allocatable resources of the cluster
+	// and merged with spec.capability; it is unset if spec.capabilityPercent is not set.
+	// +optional
+	Capability v1.ResourceList `json:"capability,omitempty" protobuf:"bytes,13,opt,name=capability"`
+	// ReclaimBudget is the part of spec.maxReclaimPerCycle left after the last reclaim of the
+	// queue; it is set by scheduler, and unset if spec.maxReclaimPerCycle is not set.
+	// +optional
+	ReclaimBudget v1.ResourceList `json:"reclaimBudget,omitempty" protobuf:"bytes,14,opt,name=reclaimBudget"`
+}
+
+// QueueCommandRecord records a command applied to the queue.
+type QueueCommandRecord struct {
+	// Action is the action of the command.
+	Action QueueAction `json:"action,omitempty" protobuf:"bytes,1,opt,name=action"`
+
+	// Command is the namespace/name of the command.
+	Command string `json:"command,omitempty" protobuf:"bytes,2,opt,name=command"`
+
+	// Creator is the creator of the command, taken from its creator annotation.
+	// +optional
+	Creator string `json:"creator,omitempty" protobuf:"bytes,3,opt,name=creator"`
+
+	// Time is when the command was applied.
+	// +optional
+	Time metav1.Time `json:"time,omitempty" protobuf:"bytes,4,opt,name=time"`
+}
+
+// QueueCondition records a transition of the queue state.
+type QueueCondition struct {
+	// Type is the state which the queue transitioned to.
+	Type QueueState `json:"type,omitempty" protobuf:"bytes,1,opt,name=type"`
+
+	// Status is the status of the condition.
+	Status v1.ConditionStatus `json:"status,omitempty" protobuf:"bytes,2,opt,name=status"`
+
+	// PreviousState is the state which the queue transitioned from.
+	// +optional
+	PreviousState QueueState `json:"previousState,omitempty" protobuf:"bytes,3,opt,name=previousState"`
+
+	// Last time the state transitioned from previous state to current state.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,4,opt,name=lastTransitionTime"`
+
+	// Unique, one-word, CamelCase reason for the transition, e.g. the action of the queue.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,5,opt,name=reason"`
+
+	// Human-readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,6,opt,name=message"`
 }
 
 // QueueSpec represents the template of Queue.
@@ -262,6 +391,33 @@ type QueueSpec struct {
 	Capability v1.ResourceList `json:"capability,omitempty" protobuf:"bytes,2,opt,name=capability"`
 	// State controller the status of queue
 	State QueueState `json:"state,omitempty" protobuf:"bytes,3,opt,name=state"`
+	// Parent is the name of the parent queue, empty for a root queue
+	Parent string `json:"parent,omitempty" protobuf:"bytes,4,opt,name=parent"`
+	// Guaranteed is the amount of resources reserved for the queue
+	Guaranteed v1.ResourceList `json:"guaranteed,omitempty" protobuf:"bytes,5,opt,name=guaranteed"`
+	// Reclaimable indicates whether resources of the queue can be reclaimed by other queues
+	Reclaimable *bool `json:"reclaimable,omitempty" protobuf:"bytes,6,opt,name=reclaimable"`
+	// Priority of the queue, the queue with higher priority is considered before the ones with
+	// lower priority whatever their share are; it must be the value of a PriorityClass
+	Priority int32 `json:"priority,omitempty" protobuf:"varint,7,opt,name=priority"`
+	// OvercommitRatio allows the queue to exceed its capability by the ratio, e.g. 1.2, while the
+	// cluster has headroom, i.e. the requests of all queues do not exceed the cluster capacity;
+	// the queue shrinks back to its capability under contention. It must not be less than 1.
+	OvercommitRatio *float64 `json:"overcommitRatio,omitempty" protobuf:"fixed64,8,opt,name=overcommitRatio"`
+	// CapabilityPercent is the capability of the queue as the percentage, from 1 to 100, of the total
+	// allocatable resources of the cluster per resource name, so that it follows the cluster as nodes are
+	// added or removed; the capability of a resource set in both is taken from Capability.
+	// +optional
+	CapabilityPercent map[v1.ResourceName]int32 `json:"capabilityPercent,omitempty" protobuf:"bytes,9,rep,name=capabilityPercent"`
+	// NamespaceQuotas limits the resources requested by the pods of the queue per namespace, so that
+	// one namespace can not consume the entire queue; the namespaces not listed are not limited.
+	// +optional
+	NamespaceQuotas map[string]v1.ResourceList `json:"namespaceQuotas,omitempty" protobuf:"bytes,10,rep,name=namespaceQuotas"`
+	// MaxReclaimPerCycle caps the resources the queue reclaims from other queues in one scheduling
+	// cycle per resource name, so that a large deficit is reclaimed over several cycles instead of
+	// by mass evictions; the resources not listed are not capped.
+	// +optional
+	MaxReclaimPerCycle v1.ResourceList `json:"maxReclaimPerCycle,omitempty" protobuf:"bytes,11,opt,name=maxReclaimPerCycle"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
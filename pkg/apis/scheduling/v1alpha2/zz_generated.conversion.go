@@ -96,6 +96,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*QueueCommandRecord)(nil), (*scheduling.QueueCommandRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_QueueCommandRecord_To_scheduling_QueueCommandRecord(a.(*QueueCommandRecord), b.(*scheduling.QueueCommandRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*scheduling.QueueCommandRecord)(nil), (*QueueCommandRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_scheduling_QueueCommandRecord_To_v1alpha2_QueueCommandRecord(a.(*scheduling.QueueCommandRecord), b.(*QueueCommandRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*QueueCondition)(nil), (*scheduling.QueueCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_QueueCondition_To_scheduling_QueueCondition(a.(*QueueCondition), b.(*scheduling.QueueCondition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*scheduling.QueueCondition)(nil), (*QueueCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_scheduling_QueueCondition_To_v1alpha2_QueueCondition(a.(*scheduling.QueueCondition), b.(*QueueCondition), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*QueueList)(nil), (*scheduling.QueueList)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_QueueList_To_scheduling_QueueList(a.(*QueueList), b.(*scheduling.QueueList), scope)
 	}); err != nil {
@@ -228,6 +248,9 @@ func autoConvert_v1alpha2_PodGroupSpec_To_scheduling_PodGroupSpec(in *PodGroupSp
 	out.Queue = in.Queue
 	out.PriorityClassName = in.PriorityClassName
 	out.MinResources = (*v1.ResourceList)(unsafe.Pointer(in.MinResources))
+	out.SchedulingPolicy = scheduling.PodGroupSchedulingPolicy(in.SchedulingPolicy)
+	out.GangTimeoutSeconds = (*int32)(unsafe.Pointer(in.GangTimeoutSeconds))
+	out.MinTaskMember = *(*map[string]int32)(unsafe.Pointer(&in.MinTaskMember))
 	return nil
 }
 
@@ -241,6 +264,9 @@ func autoConvert_scheduling_PodGroupSpec_To_v1alpha2_PodGroupSpec(in *scheduling
 	out.Queue = in.Queue
 	out.PriorityClassName = in.PriorityClassName
 	out.MinResources = (*v1.ResourceList)(unsafe.Pointer(in.MinResources))
+	out.SchedulingPolicy = PodGroupSchedulingPolicy(in.SchedulingPolicy)
+	out.GangTimeoutSeconds = (*int32)(unsafe.Pointer(in.GangTimeoutSeconds))
+	out.MinTaskMember = *(*map[string]int32)(unsafe.Pointer(&in.MinTaskMember))
 	return nil
 }
 
@@ -309,6 +335,62 @@ func Convert_scheduling_Queue_To_v1alpha2_Queue(in *scheduling.Queue, out *Queue
 	return autoConvert_scheduling_Queue_To_v1alpha2_Queue(in, out, s)
 }
 
+func autoConvert_v1alpha2_QueueCommandRecord_To_scheduling_QueueCommandRecord(in *QueueCommandRecord, out *scheduling.QueueCommandRecord, s conversion.Scope) error {
+	out.Action = scheduling.QueueAction(in.Action)
+	out.Command = in.Command
+	out.Creator = in.Creator
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_v1alpha2_QueueCommandRecord_To_scheduling_QueueCommandRecord is an autogenerated conversion function.
+func Convert_v1alpha2_QueueCommandRecord_To_scheduling_QueueCommandRecord(in *QueueCommandRecord, out *scheduling.QueueCommandRecord, s conversion.Scope) error {
+	return autoConvert_v1alpha2_QueueCommandRecord_To_scheduling_QueueCommandRecord(in, out, s)
+}
+
+func autoConvert_scheduling_QueueCommandRecord_To_v1alpha2_QueueCommandRecord(in *scheduling.QueueCommandRecord, out *QueueCommandRecord, s conversion.Scope) error {
+	out.Action = QueueAction(in.Action)
+	out.Command = in.Command
+	out.Creator = in.Creator
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_scheduling_QueueCommandRecord_To_v1alpha2_QueueCommandRecord is an autogenerated conversion function.
+func Convert_scheduling_QueueCommandRecord_To_v1alpha2_QueueCommandRecord(in *scheduling.QueueCommandRecord, out *QueueCommandRecord, s conversion.Scope) error {
+	return autoConvert_scheduling_QueueCommandRecord_To_v1alpha2_QueueCommandRecord(in, out, s)
+}
+
+func autoConvert_v1alpha2_QueueCondition_To_scheduling_QueueCondition(in *QueueCondition, out *scheduling.QueueCondition, s conversion.Scope) error {
+	out.Type = scheduling.QueueState(in.Type)
+	out.Status = v1.ConditionStatus(in.Status)
+	out.PreviousState = scheduling.QueueState(in.PreviousState)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha2_QueueCondition_To_scheduling_QueueCondition is an autogenerated conversion function.
+func Convert_v1alpha2_QueueCondition_To_scheduling_QueueCondition(in *QueueCondition, out *scheduling.QueueCondition, s conversion.Scope) error {
+	return autoConvert_v1alpha2_QueueCondition_To_scheduling_QueueCondition(in, out, s)
+}
+
+func autoConvert_scheduling_QueueCondition_To_v1alpha2_QueueCondition(in *scheduling.QueueCondition, out *QueueCondition, s conversion.Scope) error {
+	out.Type = QueueState(in.Type)
+	out.Status = v1.ConditionStatus(in.Status)
+	out.PreviousState = QueueState(in.PreviousState)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_scheduling_QueueCondition_To_v1alpha2_QueueCondition is an autogenerated conversion function.
+func Convert_scheduling_QueueCondition_To_v1alpha2_QueueCondition(in *scheduling.QueueCondition, out *QueueCondition, s conversion.Scope) error {
+	return autoConvert_scheduling_QueueCondition_To_v1alpha2_QueueCondition(in, out, s)
+}
+
 func autoConvert_v1alpha2_QueueList_To_scheduling_QueueList(in *QueueList, out *scheduling.QueueList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
 	out.Items = *(*[]scheduling.Queue)(unsafe.Pointer(&in.Items))
@@ -359,6 +441,14 @@ func autoConvert_v1alpha2_QueueSpec_To_scheduling_QueueSpec(in *QueueSpec, out *
 	out.Weight = in.Weight
 	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
 	out.State = scheduling.QueueState(in.State)
+	out.Parent = in.Parent
+	out.Guaranteed = *(*v1.ResourceList)(unsafe.Pointer(&in.Guaranteed))
+	out.Reclaimable = (*bool)(unsafe.Pointer(in.Reclaimable))
+	out.Priority = in.Priority
+	out.OvercommitRatio = (*float64)(unsafe.Pointer(in.OvercommitRatio))
+	out.CapabilityPercent = *(*map[v1.ResourceName]int32)(unsafe.Pointer(&in.CapabilityPercent))
+	out.NamespaceQuotas = *(*map[string]v1.ResourceList)(unsafe.Pointer(&in.NamespaceQuotas))
+	out.MaxReclaimPerCycle = *(*v1.ResourceList)(unsafe.Pointer(&in.MaxReclaimPerCycle))
 	return nil
 }
 
@@ -371,6 +461,14 @@ func autoConvert_scheduling_QueueSpec_To_v1alpha2_QueueSpec(in *scheduling.Queue
 	out.Weight = in.Weight
 	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
 	out.State = QueueState(in.State)
+	out.Parent = in.Parent
+	out.Guaranteed = *(*v1.ResourceList)(unsafe.Pointer(&in.Guaranteed))
+	out.Reclaimable = (*bool)(unsafe.Pointer(in.Reclaimable))
+	out.Priority = in.Priority
+	out.OvercommitRatio = (*float64)(unsafe.Pointer(in.OvercommitRatio))
+	out.CapabilityPercent = *(*map[v1.ResourceName]int32)(unsafe.Pointer(&in.CapabilityPercent))
+	out.NamespaceQuotas = *(*map[string]v1.ResourceList)(unsafe.Pointer(&in.NamespaceQuotas))
+	out.MaxReclaimPerCycle = *(*v1.ResourceList)(unsafe.Pointer(&in.MaxReclaimPerCycle))
 	return nil
 }
 
@@ -385,6 +483,15 @@ func autoConvert_v1alpha2_QueueStatus_To_scheduling_QueueStatus(in *QueueStatus,
 	out.Running = in.Running
 	out.Inqueue = in.Inqueue
 	out.State = scheduling.QueueState(in.State)
+	out.Completed = in.Completed
+	out.UnmetGuarantee = *(*v1.ResourceList)(unsafe.Pointer(&in.UnmetGuarantee))
+	out.Conditions = *(*[]scheduling.QueueCondition)(unsafe.Pointer(&in.Conditions))
+	out.Overcommitted = in.Overcommitted
+	out.LastCommand = (*scheduling.QueueCommandRecord)(unsafe.Pointer(in.LastCommand))
+	out.Used = *(*v1.ResourceList)(unsafe.Pointer(&in.Used))
+	out.EffectiveWeight = in.EffectiveWeight
+	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
+	out.ReclaimBudget = *(*v1.ResourceList)(unsafe.Pointer(&in.ReclaimBudget))
 	return nil
 }
 
@@ -399,6 +506,15 @@ func autoConvert_scheduling_QueueStatus_To_v1alpha2_QueueStatus(in *scheduling.Q
 	out.Running = in.Running
 	out.Inqueue = in.Inqueue
 	out.State = QueueState(in.State)
+	out.Completed = in.Completed
+	out.UnmetGuarantee = *(*v1.ResourceList)(unsafe.Pointer(&in.UnmetGuarantee))
+	out.Conditions = *(*[]QueueCondition)(unsafe.Pointer(&in.Conditions))
+	out.Overcommitted = in.Overcommitted
+	out.LastCommand = (*QueueCommandRecord)(unsafe.Pointer(in.LastCommand))
+	out.Used = *(*v1.ResourceList)(unsafe.Pointer(&in.Used))
+	out.EffectiveWeight = in.EffectiveWeight
+	out.Capability = *(*v1.ResourceList)(unsafe.Pointer(&in.Capability))
+	out.ReclaimBudget = *(*v1.ResourceList)(unsafe.Pointer(&in.ReclaimBudget))
 	return nil
 }
 
@@ -118,6 +118,18 @@ func (in *PodGroupSpec) DeepCopyInto(out *PodGroupSpec) {
 			}
 		}
 	}
+	if in.GangTimeoutSeconds != nil {
+		in, out := &in.GangTimeoutSeconds, &out.GangTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinTaskMember != nil {
+		in, out := &in.MinTaskMember, &out.MinTaskMember
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -160,7 +172,7 @@ func (in *Queue) DeepCopyInto(out *Queue) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -182,6 +194,40 @@ func (in *Queue) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueCommandRecord) DeepCopyInto(out *QueueCommandRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueCommandRecord.
+func (in *QueueCommandRecord) DeepCopy() *QueueCommandRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueCommandRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueCondition) DeepCopyInto(out *QueueCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueCondition.
+func (in *QueueCondition) DeepCopy() *QueueCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QueueList) DeepCopyInto(out *QueueList) {
 	*out = *in
@@ -241,6 +287,54 @@ func (in *QueueSpec) DeepCopyInto(out *QueueSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Guaranteed != nil {
+		in, out := &in.Guaranteed, &out.Guaranteed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Reclaimable != nil {
+		in, out := &in.Reclaimable, &out.Reclaimable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OvercommitRatio != nil {
+		in, out := &in.OvercommitRatio, &out.OvercommitRatio
+		*out = new(float64)
+		**out = **in
+	}
+	if in.CapabilityPercent != nil {
+		in, out := &in.CapabilityPercent, &out.CapabilityPercent
+		*out = make(map[v1.ResourceName]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NamespaceQuotas != nil {
+		in, out := &in.NamespaceQuotas, &out.NamespaceQuotas
+		*out = make(map[string]v1.ResourceList, len(*in))
+		for key, val := range *in {
+			var outVal map[v1.ResourceName]resource.Quantity
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(v1.ResourceList, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val.DeepCopy()
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.MaxReclaimPerCycle != nil {
+		in, out := &in.MaxReclaimPerCycle, &out.MaxReclaimPerCycle
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -257,6 +351,46 @@ func (in *QueueSpec) DeepCopy() *QueueSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QueueStatus) DeepCopyInto(out *QueueStatus) {
 	*out = *in
+	if in.UnmetGuarantee != nil {
+		in, out := &in.UnmetGuarantee, &out.UnmetGuarantee
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]QueueCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCommand != nil {
+		in, out := &in.LastCommand, &out.LastCommand
+		*out = new(QueueCommandRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Used != nil {
+		in, out := &in.Used, &out.Used
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Capability != nil {
+		in, out := &in.Capability, &out.Capability
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.ReclaimBudget != nil {
+		in, out := &in.ReclaimBudget, &out.ReclaimBudget
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 	return
 }
 
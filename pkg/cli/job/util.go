@@ -33,6 +33,7 @@ import (
 	vcbus "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/helpers"
 	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 )
 
 func homeDir() string {
@@ -91,6 +92,10 @@ func createJobCommand(config *rest.Config, ns, name string, action vcbatch.Actio
 		TargetObject: ctrlRef,
 		Action:       string(action),
 	}
+	// The span of the command joins the trace of the caller.
+	if traceparent := tracing.TraceParentFromEnv(); traceparent != "" {
+		cmd.Annotations = map[string]string{tracing.TraceParentAnnotationKey: traceparent}
+	}
 
 	if _, err := jobClient.BusV1alpha1().Commands(ns).Create(cmd); err != nil {
 		return err
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+)
+
+var cordonQueueFlags = &stateFlags{}
+var uncordonQueueFlags = &stateFlags{}
+
+// InitCordonFlags is used to init all flags during queue cordoning
+func InitCordonFlags(cmd *cobra.Command) {
+	initStateFlags(cmd, cordonQueueFlags)
+}
+
+// InitUncordonFlags is used to init all flags during queue uncordoning
+func InitUncordonFlags(cmd *cobra.Command) {
+	initStateFlags(cmd, uncordonQueueFlags)
+}
+
+// CordonQueue creates a command to cordon the queue, and waits until it is cordoned;
+// new jobs are rejected in a cordoned queue, while its admitted podgroups are still scheduled
+func CordonQueue(name string) error {
+	return changeQueueCordon(cordonQueueFlags, name, true)
+}
+
+// UncordonQueue creates a command to uncordon the queue, and waits until it is uncordoned
+func UncordonQueue(name string) error {
+	return changeQueueCordon(uncordonQueueFlags, name, false)
+}
+
+func changeQueueCordon(flags *stateFlags, name string, cordon bool) error {
+	if len(name) == 0 {
+		return fmt.Errorf("Queue name must be specified")
+	}
+
+	action, result := schedulingv1alpha2.UncordonQueueAction, "uncordoned"
+	if cordon {
+		action, result = schedulingv1alpha2.CordonQueueAction, "cordoned"
+	}
+
+	config, err := buildConfig(flags.Master, flags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if err := createQueueCommand(config, name, action); err != nil {
+		return err
+	}
+
+	queueClient := versioned.NewForConfigOrDie(config)
+	err = wait.PollImmediate(statePollInterval, flags.Timeout, func() (bool, error) {
+		queue, err := queueClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return (queue.Annotations[schedulingv1alpha2.CordonedAnnotationKey] == "true") == cordon, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("Queue %s is not %s after %v, the %s command was not processed; "+
+			"check whether vc-controllers is running", name, result, flags.Timeout, action)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Queue %s is %s\n", name, result)
+
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+	"volcano.sh/volcano/pkg/controllers/queue/state"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+type describeFlags struct {
+	commonFlags
+}
+
+var describeQueueFlags = &describeFlags{}
+
+// InitDescribeFlags is used to init all flags during queue describing
+func InitDescribeFlags(cmd *cobra.Command) {
+	initFlags(cmd, &describeQueueFlags.commonFlags)
+}
+
+// DescribeQueue prints the state of the queue, the transitions it allows, the commands
+// targeting it which are not processed yet, and the history of its state
+func DescribeQueue(name string) error {
+	config, err := buildConfig(describeQueueFlags.Master, describeQueueFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	queueClient := versioned.NewForConfigOrDie(config)
+	queue, err := queueClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	commands, err := queueClient.BusV1alpha1().Commands("").List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var pending []busv1alpha1.Command
+	for _, command := range commands.Items {
+		if queuehelpers.IsQueueReference(command.TargetObject) && command.TargetObject.Name == name {
+			pending = append(pending, command)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreationTimestamp.Before(&pending[j].CreationTimestamp)
+	})
+
+	return PrintQueueDescription(queue, pending, os.Stdout)
+}
+
+// PrintQueueDescription prints the description of queue with the commands pending on it
+func PrintQueueDescription(queue *v1alpha2.Queue, pending []busv1alpha1.Command, writer io.Writer) error {
+	current := queue.Status.State
+	if queue.DeletionTimestamp != nil {
+		// A deleted queue is drained, see state.NewState.
+		current = v1alpha2.QueueStateDraining
+	}
+	if current == "" {
+		current = v1alpha2.QueueStateOpen
+	}
+
+	if _, err := fmt.Fprintf(writer, "%-16s%s\n%-16s%s\n", "Name:", queue.Name, "State:", current); err != nil {
+		return err
+	}
+	if queue.Spec.State != "" {
+		if _, err := fmt.Fprintf(writer, "%-16s%s\n", "Spec State:", queue.Spec.State); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(writer, "Transitions:\n"); err != nil {
+		return err
+	}
+	transitions := state.Transitions(current)
+	if len(transitions) == 0 {
+		if _, err := fmt.Fprintf(writer, "  <unknown state>\n"); err != nil {
+			return err
+		}
+	}
+	for _, transition := range transitions {
+		states := make([]string, 0, len(transition.States))
+		for _, s := range transition.States {
+			states = append(states, string(s))
+		}
+		if _, err := fmt.Fprintf(writer, "  %-14s-> %s\n", transition.Action, strings.Join(states, " | ")); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(writer, "Pending Commands:\n"); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		if _, err := fmt.Fprintf(writer, "  <none>\n"); err != nil {
+			return err
+		}
+	}
+	for _, command := range pending {
+		if _, err := fmt.Fprintf(writer, "  %-40s%-16s%-16s%s\n", command.Namespace+"/"+command.Name, command.Action,
+			command.Annotations[busv1alpha1.CommandCreatorAnnotationKey],
+			command.CreationTimestamp.Format(timeFormat)); err != nil {
+			return err
+		}
+	}
+
+	if last := queue.Status.LastCommand; last != nil {
+		if _, err := fmt.Fprintf(writer, "%-16s%s %s by %s at %s\n", "Last Command:", last.Action, last.Command,
+			last.Creator, last.Time.Format(timeFormat)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(writer, "Conditions:\n"); err != nil {
+		return err
+	}
+	if len(queue.Status.Conditions) == 0 {
+		_, err := fmt.Fprintf(writer, "  <none>\n")
+		return err
+	}
+	if _, err := fmt.Fprintf(writer, "  %-21s%-10s%-10s%-16s%s\n", "Time", "From", "To", "Reason", "Message"); err != nil {
+		return err
+	}
+	for _, condition := range queue.Status.Conditions {
+		if _, err := fmt.Fprintf(writer, "  %-21s%-10s%-10s%-16s%s\n", condition.LastTransitionTime.Format(timeFormat),
+			condition.PreviousState, condition.Type, condition.Reason, condition.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
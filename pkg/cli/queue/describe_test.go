@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestDescribeQueue(t *testing.T) {
+	queue := &v1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Status:     v1alpha2.QueueStatus{State: v1alpha2.QueueStateClosing},
+	}
+	newCommand := func(name, target string) busv1alpha1.Command {
+		ref := metav1.NewControllerRef(&v1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: target}},
+			helpers.V1alpha2QueueKind)
+		return busv1alpha1.Command{
+			ObjectMeta:   metav1.ObjectMeta{Namespace: "default", Name: name},
+			TargetObject: ref,
+			Action:       string(v1alpha2.OpenQueueAction),
+		}
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		var val []byte
+		if strings.HasSuffix(r.URL.Path, "/commands") {
+			val, _ = json.Marshal(busv1alpha1.CommandList{Items: []busv1alpha1.Command{
+				newCommand("q1-openqueue-abc", "q1"),
+				newCommand("q2-openqueue-def", "q2"),
+			}})
+		} else {
+			val, _ = json.Marshal(queue)
+		}
+		w.Write(val)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	describeQueueFlags.Master = server.URL
+	if err := DescribeQueue("q1"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestPrintQueueDescription(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Date(2019, 10, 1, 8, 0, 0, 0, time.Local))
+
+	testCases := []struct {
+		Name     string
+		Queue    *v1alpha2.Queue
+		Pending  []busv1alpha1.Command
+		Expected []string
+		Absent   []string
+	}{
+		{
+			Name:  "empty state is open",
+			Queue: &v1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}},
+			Expected: []string{
+				"State:          Open",
+				"CloseQueue    -> Closing | Closed",
+				"Pending Commands:\n  <none>",
+				"Conditions:\n  <none>",
+			},
+			Absent: []string{"Last Command:"},
+		},
+		{
+			Name: "closing queue with history and pending command",
+			Queue: &v1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec:       v1alpha2.QueueSpec{State: v1alpha2.QueueStateClosed},
+				Status: v1alpha2.QueueStatus{
+					State: v1alpha2.QueueStateClosing,
+					LastCommand: &v1alpha2.QueueCommandRecord{
+						Action:  v1alpha2.CloseQueueAction,
+						Command: "default/q1-closequeue-abc",
+						Creator: "alice",
+						Time:    transitionTime,
+					},
+					Conditions: []v1alpha2.QueueCondition{{
+						Type:               v1alpha2.QueueStateClosing,
+						PreviousState:      v1alpha2.QueueStateOpen,
+						LastTransitionTime: transitionTime,
+						Reason:             string(v1alpha2.CloseQueueAction),
+					}},
+				},
+			},
+			Pending: []busv1alpha1.Command{{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         "default",
+					Name:              "q1-openqueue-def",
+					Annotations:       map[string]string{busv1alpha1.CommandCreatorAnnotationKey: "bob"},
+					CreationTimestamp: transitionTime,
+				},
+				Action: string(v1alpha2.OpenQueueAction),
+			}},
+			Expected: []string{
+				"State:          Closing",
+				"Spec State:     Closed",
+				"OpenQueue     -> Open",
+				"CordonQueue   -> Closing",
+				"UncordonQueue -> Closing",
+				"default/q1-openqueue-def                OpenQueue       bob             2019-10-01 08:00:00",
+				"Last Command:   CloseQueue default/q1-closequeue-abc by alice at 2019-10-01 08:00:00",
+				"2019-10-01 08:00:00  Open      Closing   CloseQueue",
+			},
+		},
+		{
+			Name: "deleted queue is draining",
+			Queue: &v1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1", DeletionTimestamp: &transitionTime},
+				Status:     v1alpha2.QueueStatus{State: v1alpha2.QueueStateOpen},
+			},
+			Expected: []string{
+				"State:          Draining",
+				"CloseQueue    -> Draining",
+				"CordonQueue   -> Draining",
+			},
+		},
+		{
+			Name: "state not known",
+			Queue: &v1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Status:     v1alpha2.QueueStatus{State: "Invalid"},
+			},
+			Expected: []string{"Transitions:\n  <unknown state>"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		if err := PrintQueueDescription(testCase.Queue, testCase.Pending, &buf); err != nil {
+			t.Errorf("case %s: expected no error, got %v", testCase.Name, err)
+		}
+		output := buf.String()
+		for _, expected := range testCase.Expected {
+			if !strings.Contains(output, expected) {
+				t.Errorf("case %s: expected %q in output:\n%s", testCase.Name, expected, output)
+			}
+		}
+		for _, absent := range testCase.Absent {
+			if strings.Contains(output, absent) {
+				t.Errorf("case %s: expected no %q in output:\n%s", testCase.Name, absent, output)
+			}
+		}
+	}
+}
+
+func TestInitDescribeFlags(t *testing.T) {
+	var cmd cobra.Command
+	InitDescribeFlags(&cmd)
+
+	if cmd.Flag("master") == nil {
+		t.Errorf("Could not find the flag master")
+	}
+}
@@ -17,20 +17,29 @@ limitations under the License.
 package queue
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/client/pager"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
 )
 
 type listFlags struct {
 	commonFlags
+
+	// Output is the output format, wide or json; a table of the basic information if empty
+	Output string
 }
 
 const (
@@ -52,19 +61,54 @@ const (
 	// Inqueue status of queue
 	Inqueue string = "Inqueue"
 
+	// Completed status of queue
+	Completed string = "Completed"
+
 	// State is state of queue
 	State string = "State"
+
+	// Capability of the queue
+	Capability string = "Capability"
+
+	// Guaranteed resources of the queue
+	Guaranteed string = "Guaranteed"
 )
 
+const (
+	// OutputWide prints the resources of queues as well
+	OutputWide = "wide"
+	// OutputJSON prints queues as json
+	OutputJSON = "json"
+)
+
+// queueSummary is the usage summary of a queue, the podgroups of the queue are
+// counted by phase as the status of queue may be stale
+type queueSummary struct {
+	Name       string                           `json:"name"`
+	Weight     int32                            `json:"weight"`
+	State      v1alpha2.QueueState              `json:"state"`
+	Capability v1.ResourceList                  `json:"capability,omitempty"`
+	Guaranteed v1.ResourceList                  `json:"guaranteed,omitempty"`
+	PodGroups  map[v1alpha2.PodGroupPhase]int32 `json:"podGroups"`
+}
+
 var listQueueFlags = &listFlags{}
 
 // InitListFlags inits all flags
 func InitListFlags(cmd *cobra.Command) {
 	initFlags(cmd, &listQueueFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&listQueueFlags.Output, "output", "o", "",
+		"output format, valid formats are wide and json")
 }
 
 // ListQueue lists all the queue
 func ListQueue() error {
+	if listQueueFlags.Output != "" && listQueueFlags.Output != OutputWide && listQueueFlags.Output != OutputJSON {
+		return fmt.Errorf("output format %s is not supported, valid formats are %s and %s",
+			listQueueFlags.Output, OutputWide, OutputJSON)
+	}
+
 	config, err := buildConfig(listQueueFlags.Master, listQueueFlags.Kubeconfig)
 	if err != nil {
 		return err
@@ -80,25 +124,103 @@ func ListQueue() error {
 		fmt.Printf("No resources found\n")
 		return nil
 	}
-	PrintQueues(queues, os.Stdout)
 
-	return nil
+	// Podgroups are counted page by page, so that the memory does not grow with their number.
+	summaries, countPodGroup := summarizeQueues(queues)
+	if err := pager.ListPodGroups(jobClient, "", metav1.ListOptions{}, func(pg *v1alpha2.PodGroup) error {
+		countPodGroup(pg)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return printSummaries(summaries, listQueueFlags.Output, os.Stdout)
 }
 
-// PrintQueues prints queue information
-func PrintQueues(queues *v1alpha2.QueueList, writer io.Writer) {
-	_, err := fmt.Fprintf(writer, "%-25s%-8s%-8s%-8s%-8s%-8s%-8s\n",
-		Name, Weight, State, Inqueue, Pending, Running, Unknown)
-	if err != nil {
-		fmt.Printf("Failed to print queue command result: %s.\n", err)
+// PrintQueues prints queue information with the podgroups of each queue counted by phase
+func PrintQueues(queues *v1alpha2.QueueList, podGroups *v1alpha2.PodGroupList, output string, writer io.Writer) error {
+	summaries, countPodGroup := summarizeQueues(queues)
+	for i := range podGroups.Items {
+		countPodGroup(&podGroups.Items[i])
 	}
-	for _, queue := range queues.Items {
-		_, err = fmt.Fprintf(writer, "%-25s%-8d%-8s%-8d%-8d%-8d%-8d\n",
-			queue.Name, queue.Spec.Weight, queue.Status.State, queue.Status.Inqueue,
-			queue.Status.Pending, queue.Status.Running, queue.Status.Unknown)
+
+	return printSummaries(summaries, output, writer)
+}
+
+func printSummaries(summaries []queueSummary, output string, writer io.Writer) error {
+	if output == OutputJSON {
+		data, err := json.MarshalIndent(summaries, "", "  ")
 		if err != nil {
-			fmt.Printf("Failed to print queue command result: %s.\n", err)
+			return err
 		}
+		_, err = fmt.Fprintf(writer, "%s\n", data)
+		return err
+	}
+
+	header := fmt.Sprintf("%-25s%-8s%-8s%-8s%-8s%-8s%-8s%-10s",
+		Name, Weight, State, Inqueue, Pending, Running, Unknown, Completed)
+	if output == OutputWide {
+		header += fmt.Sprintf("%-30s%-30s", Capability, Guaranteed)
+	}
+	if _, err := fmt.Fprintln(writer, header); err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		line := fmt.Sprintf("%-25s%-8d%-8s%-8d%-8d%-8d%-8d%-10d",
+			summary.Name, summary.Weight, summary.State,
+			summary.PodGroups[v1alpha2.PodGroupInqueue], summary.PodGroups[v1alpha2.PodGroupPending],
+			summary.PodGroups[v1alpha2.PodGroupRunning], summary.PodGroups[v1alpha2.PodGroupUnknown],
+			summary.PodGroups[v1alpha2.PodGroupCompleted])
+		if output == OutputWide {
+			line += fmt.Sprintf("%-30s%-30s", formatResources(summary.Capability), formatResources(summary.Guaranteed))
+		}
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summarizeQueues returns the summaries of queues, and the function counting a podgroup in the
+// summary of its queue.
+func summarizeQueues(queues *v1alpha2.QueueList) ([]queueSummary, func(pg *v1alpha2.PodGroup)) {
+	summaries := make([]queueSummary, 0, len(queues.Items))
+	index := map[string]int{}
+	for _, queue := range queues.Items {
+		index[queue.Name] = len(summaries)
+		summaries = append(summaries, queueSummary{
+			Name:       queue.Name,
+			Weight:     queue.Spec.Weight,
+			State:      queue.Status.State,
+			Capability: queuehelpers.EffectiveCapability(&queue),
+			Guaranteed: queue.Spec.Guaranteed,
+			PodGroups:  map[v1alpha2.PodGroupPhase]int32{},
+		})
+	}
+
+	return summaries, func(pg *v1alpha2.PodGroup) {
+		if i, found := index[pg.Spec.Queue]; found {
+			phase := pg.Status.Phase
+			if queuehelpers.IsPodGroupCompleted(pg) {
+				phase = v1alpha2.PodGroupCompleted
+			}
+			summaries[i].PodGroups[phase]++
+		}
+	}
+}
+
+// formatResources formats resources as name=quantity pairs sorted by name
+func formatResources(resources v1.ResourceList) string {
+	if len(resources) == 0 {
+		return "-"
+	}
+
+	var pairs []string
+	for name, quantity := range resources {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, quantity.String()))
 	}
+	sort.Strings(pairs)
 
+	return strings.Join(pairs, ",")
 }
@@ -101,5 +101,5 @@ func OperateQueue() error {
 			operateQueueFlags.Action, ActionOpen, ActionClose, ActionUpdate)
 	}
 
-	return createQueueCommand(config, action)
+	return createQueueCommand(config, operateQueueFlags.Name, action)
 }
@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 
 	"github.com/spf13/cobra"
 
@@ -115,6 +118,37 @@ func TestOperateQueue(t *testing.T) {
 	}
 }
 
+func TestOperateQueueTraceParent(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	os.Setenv(tracing.TraceParentEnv, traceparent)
+	defer os.Unsetenv(tracing.TraceParentEnv)
+
+	var created busv1alpha1.Command
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&created)
+			val, _ := json.Marshal(created)
+			w.Write(val)
+			return
+		}
+		val, _ := json.Marshal(v1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "test-queue"}})
+		w.Write(val)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	operateQueueFlags.Master = server.URL
+	operateQueueFlags.Name = "test-queue"
+	operateQueueFlags.Action = ActionClose
+	if err := OperateQueue(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if created.Annotations[tracing.TraceParentAnnotationKey] != traceparent {
+		t.Errorf("expected command annotated with traceparent %s, got %v", traceparent, created.Annotations)
+	}
+}
+
 func TestInitOperateFlags(t *testing.T) {
 	var cmd cobra.Command
 	InitOperateFlags(&cmd)
@@ -17,14 +17,18 @@ limitations under the License.
 package queue
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha1"
@@ -192,3 +196,91 @@ func TestListQueue_nonempty(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintQueues(t *testing.T) {
+	queues := &v1alpha2.QueueList{
+		Items: []v1alpha2.Queue{
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "q1"},
+				Spec: v1alpha2.QueueSpec{
+					Weight: 2,
+					Capability: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+						corev1.ResourceCPU:    resource.MustParse("4"),
+					},
+				},
+				Status: v1alpha2.QueueStatus{State: v1alpha2.QueueStateOpen},
+			},
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "q2"},
+				Spec:       v1alpha2.QueueSpec{Weight: 1},
+				Status:     v1alpha2.QueueStatus{State: v1alpha2.QueueStateClosed},
+			},
+		},
+	}
+	podGroups := &v1alpha2.PodGroupList{
+		Items: []v1alpha2.PodGroup{
+			{
+				Spec:   v1alpha2.PodGroupSpec{Queue: "q1"},
+				Status: v1alpha2.PodGroupStatus{Phase: v1alpha2.PodGroupRunning},
+			},
+			{
+				Spec:   v1alpha2.PodGroupSpec{Queue: "q1"},
+				Status: v1alpha2.PodGroupStatus{Phase: v1alpha2.PodGroupRunning},
+			},
+			{
+				Spec:   v1alpha2.PodGroupSpec{Queue: "q1"},
+				Status: v1alpha2.PodGroupStatus{Phase: v1alpha2.PodGroupPending},
+			},
+			{
+				Spec:   v1alpha2.PodGroupSpec{Queue: "unknown"},
+				Status: v1alpha2.PodGroupStatus{Phase: v1alpha2.PodGroupRunning},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintQueues(queues, podGroups, "", &buf); err != nil {
+		t.Fatalf("failed to print queues: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 queues, got %q", buf.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "q1 2 Open 0 1 2 0 0" {
+		t.Errorf("unexpected summary of q1: %q", lines[1])
+	}
+	if strings.Contains(buf.String(), Capability) {
+		t.Errorf("expected no resources without wide output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := PrintQueues(queues, podGroups, OutputWide, &buf); err != nil {
+		t.Fatalf("failed to print queues: %v", err)
+	}
+	if !strings.Contains(buf.String(), "cpu=4,memory=8Gi") {
+		t.Errorf("expected capability of q1 in wide output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := PrintQueues(queues, podGroups, OutputJSON, &buf); err != nil {
+		t.Fatalf("failed to print queues: %v", err)
+	}
+	var summaries []queueSummary
+	if err := json.Unmarshal(buf.Bytes(), &summaries); err != nil {
+		t.Fatalf("failed to decode json output: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].PodGroups[v1alpha2.PodGroupRunning] != 2 ||
+		summaries[1].State != v1alpha2.QueueStateClosed {
+		t.Errorf("unexpected json output: %s", buf.String())
+	}
+}
+
+func TestListQueueInvalidOutput(t *testing.T) {
+	listQueueFlags.Output = "yaml"
+	defer func() { listQueueFlags.Output = "" }()
+
+	if err := ListQueue(); err == nil {
+		t.Errorf("expected error for output format yaml")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+type redriveFlags struct {
+	commonFlags
+
+	// ConfigMap is the namespace/name of the dead-letter ConfigMap of the queue controller
+	ConfigMap string
+}
+
+var redriveQueueFlags = &redriveFlags{}
+
+// InitRedriveFlags is used to init all flags during queue redriving
+func InitRedriveFlags(cmd *cobra.Command) {
+	initFlags(cmd, &redriveQueueFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&redriveQueueFlags.ConfigMap, "configmap", "c", queuehelpers.DefaultDeadLetterConfigMap,
+		"the namespace/name of the dead-letter ConfigMap, i.e. --queue-dead-letter-configmap of vc-controllers")
+}
+
+// RedriveQueue re-drives the dropped request of the queue recorded in the dead-letter ConfigMap:
+// its action is issued again by a command, and the record is removed.
+func RedriveQueue(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("Queue name must be specified")
+	}
+
+	namespace, cmName, err := cache.SplitMetaNamespaceKey(redriveQueueFlags.ConfigMap)
+	if err != nil || namespace == "" || cmName == "" {
+		return fmt.Errorf("ConfigMap must be namespace/name, got %q", redriveQueueFlags.ConfigMap)
+	}
+
+	config, err := buildConfig(redriveQueueFlags.Master, redriveQueueFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	configMaps := kubernetes.NewForConfigOrDie(config).CoreV1().ConfigMaps(namespace)
+	cm, err := configMaps.Get(cmName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	letter, err := queuehelpers.GetDeadLetter(cm, name)
+	if err != nil {
+		return err
+	}
+
+	if err := createQueueCommand(config, name, letter.Action); err != nil {
+		return err
+	}
+
+	// The record is kept if the request was dropped again in the meantime.
+	cm = cm.DeepCopy()
+	delete(cm.Data, name)
+	if _, err := configMaps.Update(cm); err != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("Queue %s is re-driven, but failed to remove its dead letter: %v", name, err)
+	}
+
+	fmt.Printf("Queue %s is re-driven with action %s, which was dropped at %s on event %s for %s\n",
+		name, letter.Action, letter.Time.Format("2006-01-02 15:04:05"), letter.Event, letter.Error)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+func TestRedriveQueue(t *testing.T) {
+	letter, _ := json.Marshal(&queuehelpers.DeadLetter{
+		Name:   "q1",
+		Action: v1alpha2.CloseQueueAction,
+		Event:  v1alpha2.QueueCommandIssuedEvent,
+		Error:  "failed to update queue",
+	})
+
+	testCases := []struct {
+		Name         string
+		QueueName    string
+		ConfigMap    string
+		ExpectErr    string
+		ExpectCmd    string
+		ExpectRemove bool
+	}{
+		{
+			Name:         "redrive dropped request",
+			QueueName:    "q1",
+			ConfigMap:    "volcano-system/dead-letters",
+			ExpectCmd:    string(v1alpha2.CloseQueueAction),
+			ExpectRemove: true,
+		},
+		{
+			Name:      "no dropped request",
+			QueueName: "q2",
+			ConfigMap: "volcano-system/dead-letters",
+			ExpectErr: "no dropped request of queue q2",
+		},
+		{
+			Name:      "configmap without namespace",
+			QueueName: "q1",
+			ConfigMap: "dead-letters",
+			ExpectErr: "ConfigMap must be namespace/name",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var command *busv1alpha1.Command
+			var updated *v1.ConfigMap
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				var val []byte
+				switch {
+				case r.Method == http.MethodPost:
+					command = &busv1alpha1.Command{}
+					json.NewDecoder(r.Body).Decode(command)
+					val, _ = json.Marshal(command)
+				case r.Method == http.MethodPut:
+					updated = &v1.ConfigMap{}
+					json.NewDecoder(r.Body).Decode(updated)
+					val, _ = json.Marshal(updated)
+				case strings.Contains(r.URL.Path, "/configmaps/"):
+					val, _ = json.Marshal(v1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "volcano-system", Name: "dead-letters"},
+						Data:       map[string]string{"q1": string(letter)},
+					})
+				default:
+					val, _ = json.Marshal(v1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: testCase.QueueName}})
+				}
+				w.Write(val)
+			})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			redriveQueueFlags.Master = server.URL
+			redriveQueueFlags.ConfigMap = testCase.ConfigMap
+
+			err := RedriveQueue(testCase.QueueName)
+			if testCase.ExpectErr == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if testCase.ExpectErr != "" && (err == nil || !strings.Contains(err.Error(), testCase.ExpectErr)) {
+				t.Errorf("expected error %s, got %v", testCase.ExpectErr, err)
+			}
+
+			if testCase.ExpectCmd == "" {
+				if command != nil {
+					t.Errorf("expected no command, got %v", command)
+				}
+				return
+			}
+			if command == nil || command.Action != testCase.ExpectCmd || command.TargetObject == nil ||
+				command.TargetObject.Name != testCase.QueueName {
+				t.Errorf("expected command %s of queue %s, got %v", testCase.ExpectCmd, testCase.QueueName, command)
+			}
+			if testCase.ExpectRemove {
+				if updated == nil {
+					t.Fatalf("expected dead letter of queue %s removed, got no update", testCase.QueueName)
+				}
+				if _, found := updated.Data[testCase.QueueName]; found {
+					t.Errorf("expected dead letter of queue %s removed, got %v", testCase.QueueName, updated.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestInitRedriveFlags(t *testing.T) {
+	var cmd cobra.Command
+	InitRedriveFlags(&cmd)
+
+	if flag := cmd.Flag("configmap"); flag == nil || flag.DefValue != queuehelpers.DefaultDeadLetterConfigMap {
+		t.Errorf("expected flag configmap with default %s, got %v", queuehelpers.DefaultDeadLetterConfigMap, flag)
+	}
+}
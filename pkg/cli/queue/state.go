@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+)
+
+const (
+	defaultStateTimeout = time.Minute
+)
+
+// statePollInterval is the interval to check the state of queue after the command is created
+var statePollInterval = time.Second
+
+type stateFlags struct {
+	commonFlags
+
+	// Timeout is the time to wait for the controller to change the state of queue
+	Timeout time.Duration
+}
+
+var openQueueFlags = &stateFlags{}
+var closeQueueFlags = &stateFlags{}
+
+func initStateFlags(cmd *cobra.Command, sf *stateFlags) {
+	initFlags(cmd, &sf.commonFlags)
+
+	cmd.Flags().DurationVarP(&sf.Timeout, "timeout", "t", defaultStateTimeout,
+		"the time to wait for the queue to change its state")
+}
+
+// InitOpenFlags is used to init all flags during queue opening
+func InitOpenFlags(cmd *cobra.Command) {
+	initStateFlags(cmd, openQueueFlags)
+}
+
+// InitCloseFlags is used to init all flags during queue closing
+func InitCloseFlags(cmd *cobra.Command) {
+	initStateFlags(cmd, closeQueueFlags)
+}
+
+// OpenQueue creates a command to open the queue, and waits until it is open
+func OpenQueue(name string) error {
+	return changeQueueState(openQueueFlags, name, schedulingv1alpha2.OpenQueueAction,
+		schedulingv1alpha2.QueueStateOpen)
+}
+
+// CloseQueue creates a command to close the queue, and waits until it is closing or closed;
+// the queue stays closing until all its podgroups are gone
+func CloseQueue(name string) error {
+	return changeQueueState(closeQueueFlags, name, schedulingv1alpha2.CloseQueueAction,
+		schedulingv1alpha2.QueueStateClosing, schedulingv1alpha2.QueueStateClosed)
+}
+
+func changeQueueState(flags *stateFlags, name string, action schedulingv1alpha2.QueueAction,
+	states ...schedulingv1alpha2.QueueState) error {
+	if len(name) == 0 {
+		return fmt.Errorf("Queue name must be specified")
+	}
+
+	config, err := buildConfig(flags.Master, flags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if err := createQueueCommand(config, name, action); err != nil {
+		return err
+	}
+
+	queueClient := versioned.NewForConfigOrDie(config)
+	var queue *schedulingv1alpha2.Queue
+	err = wait.PollImmediate(statePollInterval, flags.Timeout, func() (bool, error) {
+		var err error
+		if queue, err = queueClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{}); err != nil {
+			return false, err
+		}
+		for _, state := range states {
+			if queue.Status.State == state {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("Queue %s is still %s after %v, the %s command was not processed; "+
+			"check whether vc-controllers is running", name, queue.Status.State, flags.Timeout, action)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Queue %s is %s\n", name, queue.Status.State)
+
+	return nil
+}
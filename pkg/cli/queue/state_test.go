@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestChangeQueueState(t *testing.T) {
+	statePollInterval = 10 * time.Millisecond
+	defer func() { statePollInterval = time.Second }()
+
+	testCases := []struct {
+		Name        string
+		QueueName   string
+		State       v1alpha2.QueueState
+		Annotations map[string]string
+		Change      func(name string) error
+		Flags       *stateFlags
+		ExpectErr   string
+		ExpectCmd   string
+		ExpectNoCmd bool
+	}{
+		{
+			Name:      "open queue",
+			QueueName: "q1",
+			State:     v1alpha2.QueueStateOpen,
+			Change:    OpenQueue,
+			Flags:     openQueueFlags,
+			ExpectCmd: string(v1alpha2.OpenQueueAction),
+		},
+		{
+			Name:      "close queue with podgroups",
+			QueueName: "q1",
+			State:     v1alpha2.QueueStateClosing,
+			Change:    CloseQueue,
+			Flags:     closeQueueFlags,
+			ExpectCmd: string(v1alpha2.CloseQueueAction),
+		},
+		{
+			Name:      "command not processed",
+			QueueName: "q1",
+			State:     v1alpha2.QueueStateOpen,
+			Change:    CloseQueue,
+			Flags:     closeQueueFlags,
+			ExpectErr: "Queue q1 is still Open after 50ms",
+			ExpectCmd: string(v1alpha2.CloseQueueAction),
+		},
+		{
+			Name:        "cordon queue",
+			QueueName:   "q1",
+			State:       v1alpha2.QueueStateOpen,
+			Annotations: map[string]string{v1alpha2.CordonedAnnotationKey: "true"},
+			Change:      CordonQueue,
+			Flags:       cordonQueueFlags,
+			ExpectCmd:   string(v1alpha2.CordonQueueAction),
+		},
+		{
+			Name:      "uncordon queue",
+			QueueName: "q1",
+			State:     v1alpha2.QueueStateOpen,
+			Change:    UncordonQueue,
+			Flags:     uncordonQueueFlags,
+			ExpectCmd: string(v1alpha2.UncordonQueueAction),
+		},
+		{
+			Name:      "cordon command not processed",
+			QueueName: "q1",
+			State:     v1alpha2.QueueStateOpen,
+			Change:    CordonQueue,
+			Flags:     cordonQueueFlags,
+			ExpectErr: "Queue q1 is not cordoned after 50ms",
+			ExpectCmd: string(v1alpha2.CordonQueueAction),
+		},
+		{
+			Name:        "name not specified",
+			Change:      OpenQueue,
+			Flags:       openQueueFlags,
+			ExpectErr:   "Queue name must be specified",
+			ExpectNoCmd: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var command *busv1alpha1.Command
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				var val []byte
+				if r.Method == http.MethodPost {
+					command = &busv1alpha1.Command{}
+					if err := json.NewDecoder(r.Body).Decode(command); err != nil {
+						t.Errorf("failed to decode command: %v", err)
+					}
+					val, _ = json.Marshal(command)
+				} else {
+					val, _ = json.Marshal(v1alpha2.Queue{
+						ObjectMeta: metav1.ObjectMeta{Name: testCase.QueueName, Annotations: testCase.Annotations},
+						Status:     v1alpha2.QueueStatus{State: testCase.State},
+					})
+				}
+				w.Write(val)
+			})
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			testCase.Flags.Master = server.URL
+			testCase.Flags.Timeout = 50 * time.Millisecond
+
+			err := testCase.Change(testCase.QueueName)
+			if testCase.ExpectErr == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if testCase.ExpectErr != "" && (err == nil || !strings.Contains(err.Error(), testCase.ExpectErr)) {
+				t.Errorf("expected error %s, got %v", testCase.ExpectErr, err)
+			}
+
+			if testCase.ExpectNoCmd {
+				if command != nil {
+					t.Errorf("expected no command, got %v", command)
+				}
+				return
+			}
+			if command == nil {
+				t.Fatalf("expected command %s, got none", testCase.ExpectCmd)
+			}
+			if command.Action != testCase.ExpectCmd || command.TargetObject == nil ||
+				command.TargetObject.Name != testCase.QueueName {
+				t.Errorf("expected command %s of queue %s, got %s of %v",
+					testCase.ExpectCmd, testCase.QueueName, command.Action, command.TargetObject)
+			}
+		})
+	}
+}
+
+func TestInitStateFlags(t *testing.T) {
+	var cmd cobra.Command
+	InitOpenFlags(&cmd)
+
+	if cmd.Flag("timeout") == nil {
+		t.Errorf("Could not find the flag timeout")
+	}
+}
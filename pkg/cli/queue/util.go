@@ -19,12 +19,14 @@ package queue
 import (
 	"fmt"
 	"os"
+	"os/user"
 	"strings"
 
 	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/helpers"
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
@@ -40,17 +42,34 @@ func homeDir() string {
 	return os.Getenv("USERPROFILE") // windows
 }
 
+// commandCreator returns the local user issuing the command, it is only informative
+// as it is not verified by the apiserver.
+func commandCreator() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
 func buildConfig(master, kubeconfig string) (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags(master, kubeconfig)
 }
 
-func createQueueCommand(config *rest.Config, action schedulingv1alpha2.QueueAction) error {
+func createQueueCommand(config *rest.Config, name string, action schedulingv1alpha2.QueueAction) error {
 	queueClient := versioned.NewForConfigOrDie(config)
-	queue, err := queueClient.SchedulingV1alpha2().Queues().Get(operateQueueFlags.Name, metav1.GetOptions{})
+	queue, err := queueClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 
+	annotations := map[string]string{
+		busv1alpha1.CommandCreatorAnnotationKey: commandCreator(),
+	}
+	// The span of the command joins the trace of the caller.
+	if traceparent := tracing.TraceParentFromEnv(); traceparent != "" {
+		annotations[tracing.TraceParentAnnotationKey] = traceparent
+	}
+
 	ctrlRef := metav1.NewControllerRef(queue, helpers.V1alpha2QueueKind)
 	cmd := &busv1alpha1.Command{
 		ObjectMeta: metav1.ObjectMeta{
@@ -59,6 +78,10 @@ func createQueueCommand(config *rest.Config, action schedulingv1alpha2.QueueActi
 			OwnerReferences: []metav1.OwnerReference{
 				*ctrlRef,
 			},
+			Labels: map[string]string{
+				busv1alpha1.QueueNameLabelKey: queue.Name,
+			},
+			Annotations: annotations,
 		},
 		TargetObject: ctrlRef,
 		Action:       string(action),
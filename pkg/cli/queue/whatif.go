@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/client/pager"
+	queuehelpers "volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+type whatIfFlags struct {
+	commonFlags
+
+	// Capability is the proposed capability, e.g. cpu=10,memory=20Gi; the current one is kept if empty
+	Capability string
+	// Guaranteed is the proposed guarantee; the current one is kept if empty
+	Guaranteed string
+}
+
+var whatIfQueueFlags = &whatIfFlags{}
+
+// InitWhatIfFlags is used to init all flags during queue what-if
+func InitWhatIfFlags(cmd *cobra.Command) {
+	initFlags(cmd, &whatIfQueueFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&whatIfQueueFlags.Capability, "capability", "", "",
+		"the proposed capability of queue, e.g. cpu=10,memory=20Gi")
+	cmd.Flags().StringVarP(&whatIfQueueFlags.Guaranteed, "guaranteed", "", "",
+		"the proposed guaranteed resources of queue, e.g. cpu=2,memory=4Gi")
+}
+
+// WhatIfQueue simulates the proposed capability and guarantee of the queue against its current
+// podgroups, and prints the podgroups which would be starved; nothing is changed in the cluster.
+func WhatIfQueue(name string) error {
+	if whatIfQueueFlags.Capability == "" && whatIfQueueFlags.Guaranteed == "" {
+		return fmt.Errorf("at least one of capability and guaranteed is mandatory to simulate")
+	}
+
+	config, err := buildConfig(whatIfQueueFlags.Master, whatIfQueueFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	queueClient := versioned.NewForConfigOrDie(config)
+	queue, err := queueClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	proposed := queue.Spec.DeepCopy()
+	if whatIfQueueFlags.Capability != "" {
+		if proposed.Capability, err = parseResources(whatIfQueueFlags.Capability); err != nil {
+			return err
+		}
+	}
+	if whatIfQueueFlags.Guaranteed != "" {
+		if proposed.Guaranteed, err = parseResources(whatIfQueueFlags.Guaranteed); err != nil {
+			return err
+		}
+	}
+
+	queues, err := queueClient.SchedulingV1alpha2().Queues().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	members := queueTree(queues, name)
+
+	var podGroups []*v1alpha2.PodGroup
+	if err := pager.ListPodGroups(queueClient, "", metav1.ListOptions{}, func(pg *v1alpha2.PodGroup) error {
+		if members[pg.Spec.Queue] {
+			podGroups = append(podGroups, pg.DeepCopy())
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return PrintWhatIf(queuehelpers.WhatIf(queue, *proposed, podGroups), os.Stdout)
+}
+
+// PrintWhatIf prints the violations and the starved podgroups of a simulation
+func PrintWhatIf(result *queuehelpers.WhatIfResult, writer io.Writer) error {
+	if _, err := fmt.Fprintf(writer, "Used: %s\n", formatResources(result.Used)); err != nil {
+		return err
+	}
+	for _, violation := range result.Violations {
+		if _, err := fmt.Fprintf(writer, "Violation: %s\n", violation); err != nil {
+			return err
+		}
+	}
+
+	if len(result.Starved) == 0 {
+		_, err := fmt.Fprintf(writer, "No podgroups would be starved\n")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writer, "%-25s%-25s%-8s%s\n", "Namespace", Name, "New", "Reason"); err != nil {
+		return err
+	}
+	for _, pg := range result.Starved {
+		if _, err := fmt.Fprintf(writer, "%-25s%-25s%-8t%s\n", pg.Namespace, pg.Name, pg.New, pg.Reason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queueTree returns the queue of the name and all its descendants.
+func queueTree(queues *v1alpha2.QueueList, name string) map[string]bool {
+	members := map[string]bool{name: true}
+	for changed := true; changed; {
+		changed = false
+		for _, queue := range queues.Items {
+			if !members[queue.Name] && queue.Spec.Parent != "" && members[queue.Spec.Parent] {
+				members[queue.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	return members
+}
+
+// parseResources parses resources given as name=quantity pairs separated by comma.
+func parseResources(spec string) (v1.ResourceList, error) {
+	result := v1.ResourceList{}
+	for _, statement := range strings.Split(spec, ",") {
+		parts := strings.Split(statement, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid argument syntax %v, expected <resource>=<value>", statement)
+		}
+		quantity, err := resource.ParseQuantity(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity of %s: %v", parts[0], err)
+		}
+		result[v1.ResourceName(parts[0])] = quantity
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestParseResources(t *testing.T) {
+	testCases := []struct {
+		spec        string
+		expect      string
+		expectError bool
+	}{
+		{spec: "cpu=10,memory=20Gi", expect: "cpu=10,memory=20Gi"},
+		{spec: "cpu", expectError: true},
+		{spec: "cpu=ten", expectError: true},
+	}
+
+	for _, testCase := range testCases {
+		resources, err := parseResources(testCase.spec)
+		if (err != nil) != testCase.expectError {
+			t.Errorf("spec %s: expected error %v, got %v", testCase.spec, testCase.expectError, err)
+			continue
+		}
+		if err == nil && formatResources(resources) != testCase.expect {
+			t.Errorf("spec %s: expected %s, got %s", testCase.spec, testCase.expect, formatResources(resources))
+		}
+	}
+}
+
+func TestQueueTree(t *testing.T) {
+	queues := &v1alpha2.QueueList{Items: []v1alpha2.Queue{
+		{ObjectMeta: v1.ObjectMeta{Name: "grandchild"}, Spec: v1alpha2.QueueSpec{Parent: "child"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "child"}, Spec: v1alpha2.QueueSpec{Parent: "root"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "root"}},
+		{ObjectMeta: v1.ObjectMeta{Name: "other"}},
+	}}
+
+	expect := map[string]bool{"root": true, "child": true, "grandchild": true}
+	if members := queueTree(queues, "root"); !reflect.DeepEqual(members, expect) {
+		t.Errorf("expected %v, got %v", expect, members)
+	}
+}
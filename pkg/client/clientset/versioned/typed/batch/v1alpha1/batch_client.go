@@ -28,6 +28,7 @@ import (
 type BatchV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	JobsGetter
+	JobFlowsGetter
 }
 
 // BatchV1alpha1Client is used to interact with features provided by the batch group.
@@ -39,6 +40,10 @@ func (c *BatchV1alpha1Client) Jobs(namespace string) JobInterface {
 	return newJobs(c, namespace)
 }
 
+func (c *BatchV1alpha1Client) JobFlows(namespace string) JobFlowInterface {
+	return newJobFlows(c, namespace)
+}
+
 // NewForConfig creates a new BatchV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*BatchV1alpha1Client, error) {
 	config := *c
@@ -32,6 +32,10 @@ func (c *FakeBatchV1alpha1) Jobs(namespace string) v1alpha1.JobInterface {
 	return &FakeJobs{c, namespace}
 }
 
+func (c *FakeBatchV1alpha1) JobFlows(namespace string) v1alpha1.JobFlowInterface {
+	return &FakeJobFlows{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeBatchV1alpha1) RESTClient() rest.Interface {
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// Jobs returns a JobInformer.
 	Jobs() JobInformer
+	// JobFlows returns a JobFlowInformer.
+	JobFlows() JobFlowInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) Jobs() JobInformer {
 	return &jobInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// JobFlows returns a JobFlowInformer.
+func (v *version) JobFlows() JobFlowInformer {
+	return &jobFlowInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -25,3 +25,11 @@ type JobListerExpansion interface{}
 // JobNamespaceListerExpansion allows custom methods to be added to
 // JobNamespaceLister.
 type JobNamespaceListerExpansion interface{}
+
+// JobFlowListerExpansion allows custom methods to be added to
+// JobFlowLister.
+type JobFlowListerExpansion interface{}
+
+// JobFlowNamespaceListerExpansion allows custom methods to be added to
+// JobFlowNamespaceLister.
+type JobFlowNamespaceListerExpansion interface{}
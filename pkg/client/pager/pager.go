@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pager lists objects from the API server page by page, so that the memory
+// of a full scan is bounded by the page size rather than the number of objects.
+package pager
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+)
+
+// DefaultPageSize is the number of objects listed by one request if no limit is set.
+const DefaultPageSize = 500
+
+// PodGroupListFunc lists one page of podgroups with the options.
+type PodGroupListFunc func(opts metav1.ListOptions) (*v1alpha2.PodGroupList, error)
+
+// ListPodGroups calls fn for each podgroup in the namespace, all namespaces if it is empty.
+func ListPodGroups(client versioned.Interface, namespace string, opts metav1.ListOptions,
+	fn func(pg *v1alpha2.PodGroup) error) error {
+	return EachPodGroup(client.SchedulingV1alpha2().PodGroups(namespace).List, opts, fn)
+}
+
+// EachPodGroup lists the podgroups page by page with opts.Limit, DefaultPageSize if it is not set,
+// following the continue token of each page, and calls fn for each of them. A page is released
+// before the next one is listed; the listing stops at the first error of list or fn.
+func EachPodGroup(list PodGroupListFunc, opts metav1.ListOptions, fn func(pg *v1alpha2.PodGroup) error) error {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+
+	for {
+		page, err := list(opts)
+		if err != nil {
+			return err
+		}
+
+		for i := range page.Items {
+			if err := fn(&page.Items[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(page.Continue) == 0 {
+			return nil
+		}
+		opts.Continue = page.Continue
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pager
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// fakePages serves the podgroups in pages of the requested limit, the continue
+// token is the index of the first podgroup of the next page.
+func fakePages(names []string, requests *[]metav1.ListOptions) PodGroupListFunc {
+	return func(opts metav1.ListOptions) (*v1alpha2.PodGroupList, error) {
+		*requests = append(*requests, opts)
+
+		start := 0
+		if len(opts.Continue) != 0 {
+			if _, err := fmt.Sscanf(opts.Continue, "%d", &start); err != nil {
+				return nil, err
+			}
+		}
+		end := start + int(opts.Limit)
+		if end > len(names) {
+			end = len(names)
+		}
+
+		list := &v1alpha2.PodGroupList{}
+		for _, name := range names[start:end] {
+			list.Items = append(list.Items, v1alpha2.PodGroup{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		if end < len(names) {
+			list.Continue = fmt.Sprintf("%d", end)
+		}
+		return list, nil
+	}
+}
+
+func TestEachPodGroup(t *testing.T) {
+	names := []string{"pg1", "pg2", "pg3", "pg4", "pg5"}
+
+	testCases := []struct {
+		name           string
+		limit          int64
+		expectContinue []string
+	}{
+		{
+			name:           "continuation tokens are followed",
+			limit:          2,
+			expectContinue: []string{"", "2", "4"},
+		},
+		{
+			name:           "last page is full",
+			limit:          5,
+			expectContinue: []string{""},
+		},
+		{
+			name:           "default page size",
+			expectContinue: []string{""},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var requests []metav1.ListOptions
+		var got []string
+		err := EachPodGroup(fakePages(names, &requests), metav1.ListOptions{Limit: testCase.limit},
+			func(pg *v1alpha2.PodGroup) error {
+				got = append(got, pg.Name)
+				return nil
+			})
+		if err != nil {
+			t.Errorf("case %s: unexpected error %v", testCase.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, names) {
+			t.Errorf("case %s: expected podgroups %v, got %v", testCase.name, names, got)
+		}
+
+		var tokens []string
+		for _, req := range requests {
+			tokens = append(tokens, req.Continue)
+			if req.Limit <= 0 {
+				t.Errorf("case %s: expected limited request, got limit %d", testCase.name, req.Limit)
+			}
+		}
+		if !reflect.DeepEqual(tokens, testCase.expectContinue) {
+			t.Errorf("case %s: expected continue tokens %v, got %v", testCase.name, testCase.expectContinue, tokens)
+		}
+	}
+}
+
+func TestEachPodGroupStopsOnError(t *testing.T) {
+	var requests []metav1.ListOptions
+	visited := 0
+	err := EachPodGroup(fakePages([]string{"pg1", "pg2", "pg3"}, &requests), metav1.ListOptions{Limit: 1},
+		func(pg *v1alpha2.PodGroup) error {
+			visited++
+			return fmt.Errorf("stop")
+		})
+	if err == nil {
+		t.Errorf("expected error of callback")
+	}
+	if visited != 1 || len(requests) != 1 {
+		t.Errorf("expected listing to stop at first error, visited %d podgroups in %d requests", visited, len(requests))
+	}
+}
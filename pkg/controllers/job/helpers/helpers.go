@@ -19,11 +19,15 @@ package helpers
 import (
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
+	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/controllers/apis"
 )
 
@@ -70,3 +74,67 @@ func GenPVCName(jobName string) string {
 func GetJobKeyByReq(req *apis.Request) string {
 	return fmt.Sprintf("%s/%s", req.Namespace, req.JobName)
 }
+
+// CompletionIndex returns the completion index of the ix-th pod of the task of an Indexed job,
+// the indexes of the tasks follow each other in the order of the tasks.
+func CompletionIndex(job *batch.Job, taskName string, ix int) int {
+	offset := 0
+	for _, task := range job.Spec.Tasks {
+		if task.Name == taskName {
+			break
+		}
+		offset += int(task.Replicas)
+	}
+
+	return offset + ix
+}
+
+// ParseIndexes parses the compressed completion indexes, e.g. "0,2-4", of a job with total replicas;
+// malformed entries are ignored and ranges are clamped to the indexes of the job.
+func ParseIndexes(indexes string, total int) sets.Int {
+	result := sets.NewInt()
+	for _, entry := range strings.Split(indexes, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(entry), "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil || first < 0 || first >= total {
+			continue
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		if last >= total {
+			last = total - 1
+		}
+		for i := first; i <= last; i++ {
+			result.Insert(i)
+		}
+	}
+
+	return result
+}
+
+// FormatIndexes formats the completion indexes in ascending order with ranges of consecutive
+// indexes compressed, e.g. "0,2-4".
+func FormatIndexes(indexes sets.Int) string {
+	sorted := indexes.List()
+	sort.Ints(sorted)
+
+	var entries []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			entries = append(entries, strconv.Itoa(sorted[i]))
+		} else {
+			entries = append(entries, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestFormatIndexes(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Indexes  sets.Int
+		Expected string
+	}{
+		{
+			Name:    "empty",
+			Indexes: sets.NewInt(),
+		},
+		{
+			Name:     "single",
+			Indexes:  sets.NewInt(3),
+			Expected: "3",
+		},
+		{
+			Name:     "ranges",
+			Indexes:  sets.NewInt(4, 0, 2, 3, 7, 8),
+			Expected: "0,2-4,7-8",
+		},
+	}
+
+	for _, testcase := range testcases {
+		formatted := FormatIndexes(testcase.Indexes)
+		if formatted != testcase.Expected {
+			t.Errorf("case %s: expected %q, got %q", testcase.Name, testcase.Expected, formatted)
+		}
+		if parsed := ParseIndexes(formatted, 10); !parsed.Equal(testcase.Indexes) {
+			t.Errorf("case %s: expected %v parsed from %q, got %v", testcase.Name, testcase.Indexes.List(), formatted, parsed.List())
+		}
+	}
+}
+
+func TestParseIndexesMalformed(t *testing.T) {
+	parsed := ParseIndexes("1,x,-2,5-a,6-7", 10)
+	if expected := sets.NewInt(1, 6, 7); !parsed.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected.List(), parsed.List())
+	}
+}
+
+func TestParseIndexesClamped(t *testing.T) {
+	parsed := ParseIndexes("1,3-9,12,0-2147483647", 5)
+	if expected := sets.NewInt(0, 1, 2, 3, 4); !parsed.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected.List(), parsed.List())
+	}
+}
@@ -61,6 +61,14 @@ const (
 	//
 	// 5ms, 10ms, 20ms, 40ms, 80ms, 160ms, 320ms, 640ms, 1.3s, 2.6s, 5.1s, 10.2s, 20.4s, 41s, 82s
 	maxRetries = 15
+
+	// defaultTaskBackoff is the delay before the failed pod of a task is recreated for the first time.
+	defaultTaskBackoff = 10 * time.Second
+	// maxTaskBackoff is the upper limit of the delay before the failed pod of a task is recreated.
+	maxTaskBackoff = 5 * time.Minute
+
+	// oomKilledReason is the reason of the container terminated for running out of memory.
+	oomKilledReason = "OOMKilled"
 )
 
 // Controller the Job Controller type
@@ -112,6 +120,8 @@ type Controller struct {
 	sync.Mutex
 	errTasks workqueue.RateLimitingInterface
 	workers  uint32
+	// schedulerName is the scheduler of the pods whose job does not specify one
+	schedulerName string
 }
 
 // NewJobController create new Job Controller
@@ -120,6 +130,7 @@ func NewJobController(
 	vcClient vcclientset.Interface,
 	sharedInformers informers.SharedInformerFactory,
 	workers uint32,
+	schedulerName string,
 ) *Controller {
 
 	//Initialize event client
@@ -138,6 +149,7 @@ func NewJobController(
 		recorder:        recorder,
 		priorityClasses: make(map[string]*v1beta1.PriorityClass),
 		workers:         workers,
+		schedulerName:   schedulerName,
 	}
 	var i uint32
 	for i = 0; i < workers; i++ {
@@ -331,7 +343,7 @@ func (cc *Controller) processNextReq(count uint32) bool {
 	klog.V(3).Infof("Execute <%v> on Job <%s/%s> in <%s> by <%T>.",
 		action, req.Namespace, req.JobName, jobInfo.Job.Status.State.Phase, st)
 
-	if action != batchv1alpha1.SyncJobAction {
+	if action != batchv1alpha1.SyncJobAction && action != batchv1alpha1.SuspendJobAction {
 		cc.recordJobEvent(jobInfo.Job.Namespace, jobInfo.Job.Name, batchv1alpha1.ExecuteAction, fmt.Sprintf(
 			"Start to execute action %s ", action))
 	}
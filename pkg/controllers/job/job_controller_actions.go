@@ -21,10 +21,12 @@ import (
 	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 	k8scontroller "k8s.io/kubernetes/pkg/controller"
 
@@ -103,6 +105,10 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, podRetainPhase state.PhaseM
 		Version:      job.Status.Version,
 		MinAvailable: int32(job.Spec.MinAvailable),
 		RetryCount:   job.Status.RetryCount,
+
+		TaskRetryCount:     job.Status.TaskRetryCount,
+		CompletedIndexes:   job.Status.CompletedIndexes,
+		ObservedGeneration: job.Status.ObservedGeneration,
 	}
 
 	if updateStatus != nil {
@@ -194,7 +200,28 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 
 	var podToCreate []*v1.Pod
 	var podToDelete []*v1.Pod
+	var podToRetry []*v1.Pod
 	var creationErrs []error
+
+	// The failed pods of tasks with retries are deleted after their backoff and then recreated as missing pods.
+	now := time.Now()
+	taskRetryCount := map[string]int32{}
+	for name, count := range job.Status.TaskRetryCount {
+		taskRetryCount[name] = count
+	}
+	var retryAfter time.Duration
+	var exhaustedTask string
+
+	// The succeeded indexes of an Indexed job stay completed, their pods are not recreated once deleted.
+	indexed := job.Spec.CompletionMode == batch.IndexedCompletion
+	completedIndexes := jobhelpers.ParseIndexes(job.Status.CompletedIndexes, int(state.TotalTasks(job)))
+	currentIndexes := sets.NewInt()
+
+	// The pods created from a superseded template of their task are deleted, the missing pods are
+	// created from the current templates once all of them are gone.
+	superseded := false
+	var supersededPods int
+
 	var deletionErrs []error
 	appendMutex := sync.Mutex{}
 
@@ -208,16 +235,32 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 		ts.Template.Name = ts.Name
 		tc := ts.Template.DeepCopy()
 		name := ts.Template.Name
+		templateHash := podTemplateHash(tc)
 
 		pods, found := jobInfo.Pods[name]
 		if !found {
 			pods = map[string]*v1.Pod{}
 		}
 
+		// The missing pods of the task are not created until its dependencies are running.
+		dependency, ready, required := waitingDependency(job, ts, jobInfo.Pods)
+		waiting := false
+
 		for i := 0; i < int(ts.Replicas); i++ {
 			podName := fmt.Sprintf(jobhelpers.PodNameFmt, job.Name, name, i)
+			index := jobhelpers.CompletionIndex(job, name, i)
+			currentIndexes.Insert(index)
 			if pod, found := pods[podName]; !found {
-				newPod := createJobPod(job, tc, i)
+				if indexed && completedIndexes.Has(index) {
+					atomic.AddInt32(&succeeded, 1)
+					continue
+				}
+				if dependency != "" {
+					waiting = true
+					continue
+				}
+				newPod := createJobPod(job, tc, i, cc.schedulerName)
+				cc.setPodPriority(job, newPod)
 				if err := cc.pluginOnPodCreate(job, newPod); err != nil {
 					return err
 				}
@@ -227,9 +270,38 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 				if pod.DeletionTimestamp != nil {
 					klog.Infof("Pod <%s/%s> is terminating", pod.Namespace, pod.Name)
 					atomic.AddInt32(&terminating, 1)
+					if isSupersededPod(templateHash, pod) {
+						superseded = true
+					}
+					continue
+				}
+
+				if isSupersededPod(templateHash, pod) {
+					superseded = true
+					supersededPods++
+					podToDelete = append(podToDelete, pod)
 					continue
 				}
 
+				if pod.Status.Phase == v1.PodFailed && ts.MaxRetry > 0 {
+					if taskRetryCount[name] >= ts.MaxRetry {
+						if exhaustedTask == "" {
+							exhaustedTask = name
+						}
+					} else if delay := taskRetryDelay(ts, taskRetryCount[name], pod, now); delay > 0 {
+						if retryAfter == 0 || delay < retryAfter {
+							retryAfter = delay
+						}
+					} else {
+						taskRetryCount[name]++
+						podToRetry = append(podToRetry, pod)
+						continue
+					}
+				}
+
+				if indexed && pod.Status.Phase == v1.PodSucceeded {
+					completedIndexes.Insert(index)
+				}
 				classifyAndAddUpPodBaseOnPhase(pod, &pending, &running, &succeeded, &failed, &unknown)
 			}
 		}
@@ -237,6 +309,29 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 		for _, pod := range pods {
 			podToDelete = append(podToDelete, pod)
 		}
+
+		if waiting {
+			cc.recorder.Eventf(job, v1.EventTypeNormal, "WaitingForDependency",
+				"Task %s is waiting for task %s: %d/%d pods are running", name, dependency, ready, required)
+		}
+	}
+
+	// The retries are saved before the failed pods are deleted, otherwise they are not counted
+	// if the status update fails after the pods are gone.
+	if len(podToRetry) > 0 {
+		if err := cc.saveTaskRetryCount(job, taskRetryCount); err != nil {
+			return err
+		}
+		podToDelete = append(podToDelete, podToRetry...)
+	}
+
+	if supersededPods > 0 {
+		if err := cc.supersedePods(job); err != nil {
+			return err
+		}
+	}
+	if superseded {
+		podToCreate = nil
 	}
 
 	waitCreationGroup := sync.WaitGroup{}
@@ -311,6 +406,17 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 		MinAvailable:        int32(job.Spec.MinAvailable),
 		ControlledResources: job.Status.ControlledResources,
 		RetryCount:          job.Status.RetryCount,
+		TaskRetryCount:      taskRetryCount,
+		ObservedGeneration:  job.Status.ObservedGeneration,
+	}
+	if !superseded {
+		job.Status.ObservedGeneration = job.Generation
+	}
+	if len(taskRetryCount) == 0 {
+		job.Status.TaskRetryCount = nil
+	}
+	if indexed {
+		job.Status.CompletedIndexes = jobhelpers.FormatIndexes(completedIndexes.Intersection(currentIndexes))
 	}
 
 	if updateStatus != nil {
@@ -318,6 +424,26 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, updateStatus state.UpdateSt
 			job.Status.State.LastTransitionTime = metav1.Now()
 		}
 	}
+
+	if exhaustedTask != "" {
+		msg := fmt.Sprintf("Task %s failed after %d retries", exhaustedTask, taskRetryCount[exhaustedTask])
+		job.Status.State = batch.JobState{
+			Phase:              batch.Failed,
+			Reason:             "TaskRetryExhausted",
+			Message:            msg,
+			LastTransitionTime: metav1.Now(),
+		}
+		cc.recorder.Event(job, v1.EventTypeWarning, "TaskRetryExhausted", msg)
+	} else if retryAfter > 0 {
+		// The job is synced again once the backoff of the earliest failed pod expires.
+		req := apis.Request{
+			Namespace:  job.Namespace,
+			JobName:    job.Name,
+			Event:      batch.OutOfSyncEvent,
+			JobVersion: job.Status.Version,
+		}
+		cc.getWorkerQueue(jobhelpers.GetJobKeyByReq(&req)).AddAfter(req, retryAfter)
+	}
 	newJob, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(job)
 	if err != nil {
 		klog.Errorf("Failed to update status of Job %v/%v: %v",
@@ -439,6 +565,7 @@ func (cc *Controller) createPodGroupIfNotExist(job *batch.Job) error {
 			},
 			Spec: scheduling.PodGroupSpec{
 				MinMember:         job.Spec.MinAvailable,
+				MinTaskMember:     taskMinAvailable(job),
 				Queue:             job.Spec.Queue,
 				MinResources:      cc.calcPGMinResources(job),
 				PriorityClassName: job.Spec.PriorityClassName,
@@ -457,6 +584,48 @@ func (cc *Controller) createPodGroupIfNotExist(job *batch.Job) error {
 	return nil
 }
 
+// supersedePods prepares the job for the pods of its current spec while the pods of a superseded
+// spec are deleted: the job version is bumped, so that the deletion of the pods does not trigger
+// the policies of the job, and the PodGroup is deleted to be created again from the current spec.
+func (cc *Controller) supersedePods(job *batch.Job) error {
+	klog.Infof("Task templates of Job <%s/%s> are updated in generation %d, deleting the pods of the superseded templates.",
+		job.Namespace, job.Name, job.Generation)
+	cc.recorder.Eventf(job, v1.EventTypeNormal, "SpecUpdated",
+		"Recreating the pods of the job from generation %d of its spec", job.Generation)
+
+	job.Status.Version++
+
+	if err := cc.vcClient.SchedulingV1alpha2().PodGroups(job.Namespace).Delete(job.Name, nil); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to delete PodGroup of Job %v/%v: %v",
+				job.Namespace, job.Name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveTaskRetryCount updates the retry count of the tasks in the status of the job.
+func (cc *Controller) saveTaskRetryCount(job *batch.Job, taskRetryCount map[string]int32) error {
+	newJob := job.DeepCopy()
+	newJob.Status.TaskRetryCount = taskRetryCount
+	newJob, err := cc.vcClient.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(newJob)
+	if err != nil {
+		klog.Errorf("Failed to update task retry count of Job %v/%v: %v",
+			job.Namespace, job.Name, err)
+		return err
+	}
+	if err := cc.cache.Update(newJob); err != nil {
+		klog.Errorf("Failed to update Job %v/%v in cache: %v",
+			newJob.Namespace, newJob.Name, err)
+		return err
+	}
+	job.ResourceVersion = newJob.ResourceVersion
+
+	return nil
+}
+
 func (cc *Controller) deleteJobPod(jobName string, pod *v1.Pod) error {
 	err := cc.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, nil)
 	if err != nil && !apierrors.IsNotFound(err) {
@@ -469,6 +638,27 @@ func (cc *Controller) deleteJobPod(jobName string, pod *v1.Pod) error {
 	return nil
 }
 
+// setPodPriority sets the priority class of the job, which is the priority class of its podgroup,
+// on the pod whose template does not specify one, so that the pods are preempted as the podgroup.
+func (cc *Controller) setPodPriority(job *batch.Job, pod *v1.Pod) {
+	if len(pod.Spec.PriorityClassName) != 0 || len(job.Spec.PriorityClassName) == 0 {
+		return
+	}
+
+	pod.Spec.PriorityClassName = job.Spec.PriorityClassName
+
+	cc.Mutex.Lock()
+	defer cc.Mutex.Unlock()
+
+	// The priority is resolved by the apiserver too, it must match the value of the priority class.
+	if pc := cc.priorityClasses[job.Spec.PriorityClassName]; pc != nil {
+		priority := pc.Value
+		pod.Spec.Priority = &priority
+	} else {
+		pod.Spec.Priority = nil
+	}
+}
+
 func (cc *Controller) calcPGMinResources(job *batch.Job) *v1.ResourceList {
 	cc.Mutex.Lock()
 	defer cc.Mutex.Unlock()
@@ -494,8 +684,10 @@ func (cc *Controller) calcPGMinResources(job *batch.Job) *v1.ResourceList {
 				break
 			}
 			podCnt++
-			for _, c := range task.Template.Spec.Containers {
-				addResourceList(minAvailableTasksRes, c.Resources.Requests, c.Resources.Limits)
+			for name, quantity := range helpers.GetPodRequests(&task.Template.Spec) {
+				total := minAvailableTasksRes[name]
+				total.Add(quantity)
+				minAvailableTasksRes[name] = total
 			}
 		}
 	}
@@ -19,13 +19,19 @@ package job
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
 
 	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	volcanoclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
 	"volcano.sh/volcano/pkg/controllers/apis"
 	"volcano.sh/volcano/pkg/controllers/job/state"
 )
@@ -243,6 +249,81 @@ func TestSyncJobFunc(t *testing.T) {
 			Plugins:      []string{"svc", "ssh", "env"},
 			ExpextVal:    nil,
 		},
+		{
+			Name: "SyncJob with task waiting for dependency",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task1",
+							Replicas: 2,
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Name:      "pods",
+									Namespace: namespace,
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name: "Containers",
+										},
+									},
+								},
+							},
+						},
+						{
+							Name:      "task2",
+							Replicas:  3,
+							DependsOn: []string{"task1"},
+							Template: v1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Name:      "pods",
+									Namespace: namespace,
+								},
+								Spec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Name: "Containers",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Status: v1alpha1.JobStatus{
+					State: v1alpha1.JobState{
+						Phase: v1alpha1.Pending,
+					},
+				},
+			},
+			PodGroup: &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+			},
+			PodRetainPhase: state.PodRetainPhaseNone,
+			UpdateStatus:   nil,
+			JobInfo: &apis.JobInfo{
+				Namespace: namespace,
+				Name:      "jobinfo1",
+				Pods: map[string]map[string]*v1.Pod{
+					"task1": {
+						"job1-task1-0": buildPod(namespace, "job1-task1-0", v1.PodRunning, nil),
+					},
+				},
+			},
+			Pods: map[string]*v1.Pod{
+				"job1-task1-0": buildPod(namespace, "job1-task1-0", v1.PodRunning, nil),
+			},
+			TotalNumPods: 2,
+			ExpextVal:    nil,
+		},
 	}
 	for i, testcase := range testcases {
 
@@ -289,6 +370,503 @@ func TestSyncJobFunc(t *testing.T) {
 	}
 }
 
+func TestSyncJobTaskRetry(t *testing.T) {
+	namespace := "test"
+	int32Ptr := func(i int32) *int32 { return &i }
+	recentlyFailed := func(pod *v1.Pod) *v1.Pod {
+		pod.CreationTimestamp = metav1.Now()
+		return pod
+	}
+
+	testcases := []struct {
+		Name               string
+		MaxRetry           int32
+		BackoffSeconds     *int32
+		TaskRetryCount     map[string]int32
+		Pod                *v1.Pod
+		ExpectedPods       int
+		ExpectedRetryCount map[string]int32
+		ExpectedPhase      v1alpha1.JobPhase
+	}{
+		{
+			Name:               "recreate failed pod",
+			MaxRetry:           2,
+			Pod:                buildPod(namespace, "job1-task1-0", v1.PodFailed, nil),
+			ExpectedPods:       0,
+			ExpectedRetryCount: map[string]int32{"task1": 1},
+			ExpectedPhase:      v1alpha1.Pending,
+		},
+		{
+			Name:           "wait for backoff of failed pod",
+			MaxRetry:       2,
+			BackoffSeconds: int32Ptr(600),
+			Pod:            recentlyFailed(buildPod(namespace, "job1-task1-0", v1.PodFailed, nil)),
+			ExpectedPods:   1,
+			ExpectedPhase:  v1alpha1.Pending,
+		},
+		{
+			Name:               "fail job once retries are exhausted",
+			MaxRetry:           1,
+			TaskRetryCount:     map[string]int32{"task1": 1},
+			Pod:                buildPod(namespace, "job1-task1-0", v1.PodFailed, nil),
+			ExpectedPods:       1,
+			ExpectedRetryCount: map[string]int32{"task1": 1},
+			ExpectedPhase:      v1alpha1.Failed,
+		},
+		{
+			Name:          "succeeded pod is not retried",
+			MaxRetry:      2,
+			Pod:           buildPod(namespace, "job1-task1-0", v1.PodSucceeded, nil),
+			ExpectedPods:  1,
+			ExpectedPhase: v1alpha1.Pending,
+		},
+		{
+			Name:          "failed pod is not retried without maxRetry",
+			Pod:           buildPod(namespace, "job1-task1-0", v1.PodFailed, nil),
+			ExpectedPods:  1,
+			ExpectedPhase: v1alpha1.Pending,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			fakeController := newFakeController()
+
+			job := &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:           "task1",
+							Replicas:       1,
+							MaxRetry:       testcase.MaxRetry,
+							BackoffSeconds: testcase.BackoffSeconds,
+						},
+					},
+				},
+				Status: v1alpha1.JobStatus{
+					State:          v1alpha1.JobState{Phase: v1alpha1.Pending},
+					TaskRetryCount: testcase.TaskRetryCount,
+				},
+			}
+			if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Create(testcase.Pod); err != nil {
+				t.Fatalf("Expected no error while creating pod, but got %v", err)
+			}
+			if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(job); err != nil {
+				t.Fatalf("Expected no error while creating job, but got %v", err)
+			}
+			if err := fakeController.cache.Add(job); err != nil {
+				t.Fatalf("Expected no error while adding job in cache, but got %v", err)
+			}
+
+			jobInfo := &apis.JobInfo{
+				Namespace: namespace,
+				Name:      job.Name,
+				Job:       job,
+				Pods: map[string]map[string]*v1.Pod{
+					"task1": {testcase.Pod.Name: testcase.Pod},
+				},
+			}
+			if err := fakeController.syncJob(jobInfo, nil); err != nil {
+				t.Fatalf("Expected no error while syncing job, but got %v", err)
+			}
+
+			podList, err := fakeController.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error while listing pods, but got %v", err)
+			}
+			if len(podList.Items) != testcase.ExpectedPods {
+				t.Errorf("Expected %d pods, got %d", testcase.ExpectedPods, len(podList.Items))
+			}
+
+			newJob, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error while getting job, but got %v", err)
+			}
+			if !reflect.DeepEqual(newJob.Status.TaskRetryCount, testcase.ExpectedRetryCount) {
+				t.Errorf("Expected task retry count %v, got %v", testcase.ExpectedRetryCount, newJob.Status.TaskRetryCount)
+			}
+			if newJob.Status.State.Phase != testcase.ExpectedPhase {
+				t.Errorf("Expected job phase %s, got %s", testcase.ExpectedPhase, newJob.Status.State.Phase)
+			}
+		})
+	}
+}
+
+func TestSyncJobTaskRetryConflict(t *testing.T) {
+	namespace := "test"
+
+	fakeController := newFakeController()
+	fakeController.vcClient.(*volcanoclient.Clientset).PrependReactor("update", "jobs",
+		func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "status" {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewConflict(v1alpha1.Resource("jobs"), "job1", errors.New("object has been modified"))
+		})
+
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1",
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.JobSpec{
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name:     "task1",
+					Replicas: 1,
+					MaxRetry: 2,
+				},
+			},
+		},
+		Status: v1alpha1.JobStatus{
+			State: v1alpha1.JobState{Phase: v1alpha1.Pending},
+		},
+	}
+	pod := buildPod(namespace, "job1-task1-0", v1.PodFailed, nil)
+	if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+		t.Fatalf("Expected no error while creating pod, but got %v", err)
+	}
+	if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(job); err != nil {
+		t.Fatalf("Expected no error while creating job, but got %v", err)
+	}
+	if err := fakeController.cache.Add(job); err != nil {
+		t.Fatalf("Expected no error while adding job in cache, but got %v", err)
+	}
+
+	jobInfo := &apis.JobInfo{
+		Namespace: namespace,
+		Name:      job.Name,
+		Job:       job,
+		Pods: map[string]map[string]*v1.Pod{
+			"task1": {pod.Name: pod},
+		},
+	}
+	if err := fakeController.syncJob(jobInfo, nil); !apierrors.IsConflict(err) {
+		t.Fatalf("Expected conflict while syncing job, but got %v", err)
+	}
+
+	// The failed pod is kept until its retry is saved.
+	if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Get(pod.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected failed pod to be kept, but got %v", err)
+	}
+}
+
+func TestSyncJobIndexed(t *testing.T) {
+	namespace := "test"
+
+	testcases := []struct {
+		Name             string
+		CompletedIndexes string
+		Pods             []*v1.Pod
+		ExpectedPods     int
+		ExpectedIndexes  string
+		ExpectedSucceed  int32
+	}{
+		{
+			Name: "record succeeded indexes",
+			Pods: []*v1.Pod{
+				buildPod(namespace, "job1-task1-0", v1.PodSucceeded, nil),
+				buildPod(namespace, "job1-task1-1", v1.PodRunning, nil),
+				buildPod(namespace, "job1-task1-2", v1.PodSucceeded, nil),
+			},
+			ExpectedPods:    3,
+			ExpectedIndexes: "0,2",
+			ExpectedSucceed: 2,
+		},
+		{
+			Name:             "pods of completed indexes are not recreated",
+			CompletedIndexes: "0-1",
+			Pods: []*v1.Pod{
+				buildPod(namespace, "job1-task1-2", v1.PodRunning, nil),
+			},
+			ExpectedPods:    1,
+			ExpectedIndexes: "0-1",
+			ExpectedSucceed: 2,
+		},
+		{
+			Name:             "pods of uncompleted indexes are recreated",
+			CompletedIndexes: "0",
+			Pods: []*v1.Pod{
+				buildPod(namespace, "job1-task1-2", v1.PodSucceeded, nil),
+			},
+			ExpectedPods:    2,
+			ExpectedIndexes: "0,2",
+			ExpectedSucceed: 2,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			fakeController := newFakeController()
+
+			job := &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					CompletionMode: v1alpha1.IndexedCompletion,
+					Tasks: []v1alpha1.TaskSpec{
+						{
+							Name:     "task1",
+							Replicas: 3,
+						},
+					},
+				},
+				Status: v1alpha1.JobStatus{
+					State:            v1alpha1.JobState{Phase: v1alpha1.Running},
+					CompletedIndexes: testcase.CompletedIndexes,
+				},
+			}
+			pods := map[string]*v1.Pod{}
+			for _, pod := range testcase.Pods {
+				if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+					t.Fatalf("Expected no error while creating pod, but got %v", err)
+				}
+				pods[pod.Name] = pod
+			}
+			if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(job); err != nil {
+				t.Fatalf("Expected no error while creating job, but got %v", err)
+			}
+			if err := fakeController.cache.Add(job); err != nil {
+				t.Fatalf("Expected no error while adding job in cache, but got %v", err)
+			}
+
+			jobInfo := &apis.JobInfo{
+				Namespace: namespace,
+				Name:      job.Name,
+				Job:       job,
+				Pods:      map[string]map[string]*v1.Pod{"task1": pods},
+			}
+			if err := fakeController.syncJob(jobInfo, nil); err != nil {
+				t.Fatalf("Expected no error while syncing job, but got %v", err)
+			}
+
+			podList, err := fakeController.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error while listing pods, but got %v", err)
+			}
+			if len(podList.Items) != testcase.ExpectedPods {
+				t.Errorf("Expected %d pods, got %d", testcase.ExpectedPods, len(podList.Items))
+			}
+
+			newJob, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error while getting job, but got %v", err)
+			}
+			if newJob.Status.CompletedIndexes != testcase.ExpectedIndexes {
+				t.Errorf("Expected completed indexes %q, got %q", testcase.ExpectedIndexes, newJob.Status.CompletedIndexes)
+			}
+			if newJob.Status.Succeeded != testcase.ExpectedSucceed {
+				t.Errorf("Expected %d succeeded pods, got %d", testcase.ExpectedSucceed, newJob.Status.Succeeded)
+			}
+		})
+	}
+}
+
+func TestSyncJobTemplateUpdated(t *testing.T) {
+	namespace := "test"
+	template := func(image string) v1.PodTemplateSpec {
+		return v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "task1"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "nginx", Image: image}},
+			},
+		}
+	}
+	templateHash := func(image string) map[string]string {
+		tmpl := template(image)
+		return map[string]string{v1alpha1.PodTemplateHashKey: podTemplateHash(&tmpl)}
+	}
+
+	fakeController := newFakeController()
+
+	// The image of the job is bumped, which is generation 2 of its spec.
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "job1",
+			Namespace:  namespace,
+			Generation: 2,
+		},
+		Spec: v1alpha1.JobSpec{
+			MinAvailable: 2,
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name:     "task1",
+					Replicas: 3,
+					Template: template("nginx:1.17"),
+				},
+			},
+		},
+		Status: v1alpha1.JobStatus{
+			State:              v1alpha1.JobState{Phase: v1alpha1.Running},
+			Version:            1,
+			ObservedGeneration: 1,
+		},
+	}
+	pods := map[string]*v1.Pod{
+		"job1-task1-0": buildPod(namespace, "job1-task1-0", v1.PodRunning, templateHash("nginx:1.16")),
+		"job1-task1-1": buildPod(namespace, "job1-task1-1", v1.PodRunning, templateHash("nginx:1.16")),
+		"job1-task1-2": buildPod(namespace, "job1-task1-2", v1.PodSucceeded, templateHash("nginx:1.16")),
+	}
+	for _, pod := range pods {
+		if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+			t.Fatalf("Expected no error while creating pod, but got %v", err)
+		}
+	}
+	if _, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(&schedulingv1alpha2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: namespace},
+	}); err != nil {
+		t.Fatalf("Expected no error while creating podgroup, but got %v", err)
+	}
+	if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(job); err != nil {
+		t.Fatalf("Expected no error while creating job, but got %v", err)
+	}
+	if err := fakeController.cache.Add(job); err != nil {
+		t.Fatalf("Expected no error while adding job in cache, but got %v", err)
+	}
+
+	// The running pods of the old template and the podgroup are deleted, no pod is created meanwhile;
+	// the succeeded pod is kept.
+	succeededPod := pods["job1-task1-2"]
+	jobInfo := &apis.JobInfo{Namespace: namespace, Name: job.Name, Job: job,
+		Pods: map[string]map[string]*v1.Pod{"task1": pods}}
+	if err := fakeController.syncJob(jobInfo, nil); err != nil {
+		t.Fatalf("Expected no error while syncing job, but got %v", err)
+	}
+
+	podList, _ := fakeController.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if len(podList.Items) != 1 || podList.Items[0].Name != "job1-task1-2" {
+		t.Errorf("Expected only the succeeded pod of the old template kept, got %v", podList.Items)
+	}
+	if _, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get("job1", metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected podgroup of the old template deleted")
+	}
+	newJob, _ := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+	if newJob.Status.Version != 2 || newJob.Status.ObservedGeneration != 1 {
+		t.Errorf("Expected version 2 and observed generation 1, got %d and %d",
+			newJob.Status.Version, newJob.Status.ObservedGeneration)
+	}
+
+	// The pods of the new template and the podgroup are created once the old pods are gone.
+	jobInfo = &apis.JobInfo{Namespace: namespace, Name: job.Name, Job: newJob,
+		Pods: map[string]map[string]*v1.Pod{"task1": {"job1-task1-2": succeededPod}}}
+	if err := fakeController.syncJob(jobInfo, nil); err != nil {
+		t.Fatalf("Expected no error while syncing job, but got %v", err)
+	}
+
+	podList, _ = fakeController.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if len(podList.Items) != 3 {
+		t.Errorf("Expected 3 pods, got %d pods", len(podList.Items))
+	}
+	for _, pod := range podList.Items {
+		if pod.Name == "job1-task1-2" {
+			continue
+		}
+		if pod.Labels[v1alpha1.PodTemplateHashKey] != templateHash("nginx:1.17")[v1alpha1.PodTemplateHashKey] ||
+			pod.Annotations[v1alpha1.JobVersion] != "2" {
+			t.Errorf("Expected pod %s of the new template and version 2, got labels %v and annotations %v",
+				pod.Name, pod.Labels, pod.Annotations)
+		}
+		if image := pod.Spec.Containers[0].Image; image != "nginx:1.17" {
+			t.Errorf("Expected pod %s with image nginx:1.17, got %s", pod.Name, image)
+		}
+	}
+	if _, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get("job1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected podgroup of the new template created, got %v", err)
+	}
+	newJob, _ = fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+	if newJob.Status.ObservedGeneration != 2 {
+		t.Errorf("Expected observed generation 2, got %d", newJob.Status.ObservedGeneration)
+	}
+}
+
+func TestSyncJobScaled(t *testing.T) {
+	namespace := "test"
+	template := v1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: "task1"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "nginx", Image: "nginx:1.17"}},
+		},
+	}
+	templateHash := map[string]string{v1alpha1.PodTemplateHashKey: podTemplateHash(&template)}
+
+	fakeController := newFakeController()
+
+	// The replicas of the task are scaled from 2 to 3, which is generation 2 of the job spec.
+	job := &v1alpha1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "job1",
+			Namespace:  namespace,
+			Generation: 2,
+		},
+		Spec: v1alpha1.JobSpec{
+			MinAvailable: 2,
+			Tasks: []v1alpha1.TaskSpec{
+				{
+					Name:     "task1",
+					Replicas: 3,
+					Template: template,
+				},
+			},
+		},
+		Status: v1alpha1.JobStatus{
+			State:              v1alpha1.JobState{Phase: v1alpha1.Running},
+			Version:            1,
+			ObservedGeneration: 1,
+		},
+	}
+	pods := map[string]*v1.Pod{
+		"job1-task1-0": buildPod(namespace, "job1-task1-0", v1.PodRunning, templateHash),
+		"job1-task1-1": buildPod(namespace, "job1-task1-1", v1.PodRunning, templateHash),
+	}
+	for _, pod := range pods {
+		if _, err := fakeController.kubeClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+			t.Fatalf("Expected no error while creating pod, but got %v", err)
+		}
+	}
+	if _, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(&schedulingv1alpha2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: namespace},
+	}); err != nil {
+		t.Fatalf("Expected no error while creating podgroup, but got %v", err)
+	}
+	if _, err := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Create(job); err != nil {
+		t.Fatalf("Expected no error while creating job, but got %v", err)
+	}
+	if err := fakeController.cache.Add(job); err != nil {
+		t.Fatalf("Expected no error while adding job in cache, but got %v", err)
+	}
+
+	// The existing pods are kept running, only the new replica is created.
+	jobInfo := &apis.JobInfo{Namespace: namespace, Name: job.Name, Job: job,
+		Pods: map[string]map[string]*v1.Pod{"task1": pods}}
+	if err := fakeController.syncJob(jobInfo, nil); err != nil {
+		t.Fatalf("Expected no error while syncing job, but got %v", err)
+	}
+
+	podList, _ := fakeController.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+	if len(podList.Items) != 3 {
+		t.Errorf("Expected 3 pods, got %d pods", len(podList.Items))
+	}
+	for _, pod := range podList.Items {
+		if existing, found := pods[pod.Name]; found && pod.UID != existing.UID {
+			t.Errorf("Expected pod %s kept, got it recreated", pod.Name)
+		}
+	}
+	if _, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get("job1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected podgroup kept, got %v", err)
+	}
+	newJob, _ := fakeController.vcClient.BatchV1alpha1().Jobs(namespace).Get(job.Name, metav1.GetOptions{})
+	if newJob.Status.Version != 1 || newJob.Status.ObservedGeneration != 2 {
+		t.Errorf("Expected version 1 and observed generation 2, got %d and %d",
+			newJob.Status.Version, newJob.Status.ObservedGeneration)
+	}
+}
+
 func TestCreateJobIOIfNotExistFunc(t *testing.T) {
 	namespace := "test"
 
@@ -382,10 +960,13 @@ func TestCreatePVCFunc(t *testing.T) {
 func TestCreatePodGroupIfNotExistFunc(t *testing.T) {
 	namespace := "test"
 
+	int32Ptr := func(i int32) *int32 { return &i }
+
 	testcases := []struct {
-		Name      string
-		Job       *v1alpha1.Job
-		ExpextVal error
+		Name               string
+		Job                *v1alpha1.Job
+		ExpextVal          error
+		ExpectedTaskMember map[string]int32
 	}{
 		{
 			Name: "CreatePodGroup success Case",
@@ -397,6 +978,25 @@ func TestCreatePodGroupIfNotExistFunc(t *testing.T) {
 			},
 			ExpextVal: nil,
 		},
+		{
+			Name: "CreatePodGroup with minAvailable of tasks",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      "job2",
+				},
+				Spec: v1alpha1.JobSpec{
+					MinAvailable: 10,
+					Tasks: []v1alpha1.TaskSpec{
+						{Name: "ps", Replicas: 2, MinAvailable: int32Ptr(2)},
+						{Name: "worker", Replicas: 10, MinAvailable: int32Ptr(8)},
+						{Name: "evaluator", Replicas: 1},
+					},
+				},
+			},
+			ExpextVal:          nil,
+			ExpectedTaskMember: map[string]int32{"ps": 2, "worker": 8},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -408,9 +1008,12 @@ func TestCreatePodGroupIfNotExistFunc(t *testing.T) {
 				t.Errorf("Expected return value to be equal to expected: %s, but got: %s", testcase.ExpextVal, err)
 			}
 
-			_, err = fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get(testcase.Job.Name, metav1.GetOptions{})
+			pg, err := fakeController.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get(testcase.Job.Name, metav1.GetOptions{})
 			if err != nil {
-				t.Error("Expected PodGroup to get created, but not created")
+				t.Fatal("Expected PodGroup to get created, but not created")
+			}
+			if !reflect.DeepEqual(pg.Spec.MinTaskMember, testcase.ExpectedTaskMember) {
+				t.Errorf("Expected minTaskMember %v, got %v", testcase.ExpectedTaskMember, pg.Spec.MinTaskMember)
 			}
 		})
 
@@ -467,3 +1070,63 @@ func TestDeleteJobPod(t *testing.T) {
 		})
 	}
 }
+
+func TestSetPodPriority(t *testing.T) {
+	testcases := []struct {
+		Name             string
+		JobPriorityClass string
+		PodPriorityClass string
+		ExpectedClass    string
+		ExpectedPriority *int32
+	}{
+		{
+			Name:             "pod inherits priority of job",
+			JobPriorityClass: "high",
+			ExpectedClass:    "high",
+			ExpectedPriority: func() *int32 { p := int32(1000); return &p }(),
+		},
+		{
+			Name:             "priority class of task wins",
+			JobPriorityClass: "high",
+			PodPriorityClass: "low",
+			ExpectedClass:    "low",
+		},
+		{
+			Name:             "unknown priority class is resolved by apiserver",
+			JobPriorityClass: "unknown",
+			ExpectedClass:    "unknown",
+		},
+		{
+			Name: "job without priority class",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			fakeController := newFakeController()
+			fakeController.addPriorityClass(&v1beta1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "high"},
+				Value:      1000,
+			})
+
+			job := &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "test"},
+				Spec:       v1alpha1.JobSpec{PriorityClassName: testcase.JobPriorityClass},
+			}
+			template := &v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Name: "task1"},
+				Spec:       v1.PodSpec{PriorityClassName: testcase.PodPriorityClass},
+			}
+
+			pod := createJobPod(job, template, 0, "volcano")
+			fakeController.setPodPriority(job, pod)
+
+			if pod.Spec.PriorityClassName != testcase.ExpectedClass {
+				t.Errorf("expected priority class %s, got %s", testcase.ExpectedClass, pod.Spec.PriorityClassName)
+			}
+			if !reflect.DeepEqual(pod.Spec.Priority, testcase.ExpectedPriority) {
+				t.Errorf("expected priority %v, got %v", testcase.ExpectedPriority, pod.Spec.Priority)
+			}
+		})
+	}
+}
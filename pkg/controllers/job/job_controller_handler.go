@@ -95,6 +95,8 @@ func (cc *Controller) updateJob(oldObj, newObj interface{}) {
 			newJob.Namespace, newJob.Name, err)
 	}
 
+	cc.recordSuspendTransition(oldJob, newJob)
+
 	req := apis.Request{
 		Namespace: newJob.Namespace,
 		JobName:   newJob.Name,
@@ -274,6 +276,15 @@ func (cc *Controller) updatePod(oldObj, newObj interface{}) {
 
 	key := jobhelpers.GetJobKeyByReq(&req)
 	queue := cc.getWorkerQueue(key)
+
+	// The OOMKilled request goes ahead of the request of the pod, which becomes outdated
+	// and only syncs the job if the OOMKilled request restarts or aborts the job.
+	if newOOMKilled(oldPod, newPod) {
+		oomReq := req
+		oomReq.Event = batch.PodOOMKilledEvent
+		oomReq.ExitCode = 0
+		queue.Add(oomReq)
+	}
 	queue.Add(req)
 }
 
@@ -345,6 +356,19 @@ func (cc *Controller) deletePod(obj interface{}) {
 	queue.Add(req)
 }
 
+// recordSuspendTransition records the event of a job entering or leaving the Suspended phase.
+func (cc *Controller) recordSuspendTransition(oldJob, newJob *batch.Job) {
+	oldPhase, newPhase := oldJob.Status.State.Phase, newJob.Status.State.Phase
+	switch {
+	case oldPhase != batch.Suspended && newPhase == batch.Suspended:
+		cc.recorder.Event(newJob, v1.EventTypeNormal, string(batch.JobSuspended),
+			"Job is suspended, its running pods are released")
+	case oldPhase == batch.Suspended && newPhase != batch.Suspended:
+		cc.recorder.Event(newJob, v1.EventTypeNormal, string(batch.JobResumed),
+			fmt.Sprintf("Job is resumed to phase %s", newPhase))
+	}
+}
+
 func (cc *Controller) recordJobEvent(namespace, name string, event batch.JobEvent, message string) {
 	job, err := cc.cache.Get(jobcache.JobKeyByName(namespace, name))
 	if err != nil {
@@ -18,6 +18,7 @@ package job
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"k8s.io/api/core/v1"
@@ -33,6 +34,7 @@ import (
 	"volcano.sh/volcano/pkg/apis/helpers"
 	scheduling "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclientset "volcano.sh/volcano/pkg/client/clientset/versioned"
+	"volcano.sh/volcano/pkg/controllers/apis"
 )
 
 func newController() *Controller {
@@ -53,7 +55,7 @@ func newController() *Controller {
 
 	sharedInformers := informers.NewSharedInformerFactory(kubeClientSet, 0)
 
-	controller := NewJobController(kubeClientSet, vcclient, sharedInformers, 3)
+	controller := NewJobController(kubeClientSet, vcclient, sharedInformers, 3, "volcano")
 
 	return controller
 }
@@ -419,6 +421,93 @@ func TestUpdatePodFunc(t *testing.T) {
 	}
 }
 
+func TestUpdatePodOOMKilled(t *testing.T) {
+	namespace := "test"
+	annotations := map[string]string{
+		batch.JobNameKey:  "job1",
+		batch.JobVersion:  "0",
+		batch.TaskSpecKey: "task1",
+	}
+	withContainerStatus := func(pod *v1.Pod, restartCount int32, state, lastState *v1.ContainerStateTerminated) *v1.Pod {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{
+			{
+				Name:                 "nginx",
+				RestartCount:         restartCount,
+				State:                v1.ContainerState{Terminated: state},
+				LastTerminationState: v1.ContainerState{Terminated: lastState},
+			},
+		}
+		return pod
+	}
+	oomKilled := &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+	errored := &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}
+
+	testcases := []struct {
+		Name           string
+		oldPod         *v1.Pod
+		newPod         *v1.Pod
+		ExpectedEvents []batch.Event
+	}{
+		{
+			Name:           "container is restarted after OOMKilled",
+			oldPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 0, nil, nil),
+			newPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 1, nil, oomKilled),
+			ExpectedEvents: []batch.Event{batch.PodOOMKilledEvent, batch.OutOfSyncEvent},
+		},
+		{
+			Name:           "pod fails for OOMKilled",
+			oldPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 0, nil, nil),
+			newPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodFailed, nil), 0, oomKilled, nil),
+			ExpectedEvents: []batch.Event{batch.PodOOMKilledEvent, batch.PodFailedEvent},
+		},
+		{
+			Name:           "OOMKilled container is already handled",
+			oldPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 1, nil, oomKilled),
+			newPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 1, nil, oomKilled),
+			ExpectedEvents: []batch.Event{batch.OutOfSyncEvent},
+		},
+		{
+			Name:           "pod fails for other reasons",
+			oldPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodRunning, nil), 0, nil, nil),
+			newPod:         withContainerStatus(buildPod(namespace, "pod1", v1.PodFailed, nil), 0, errored, nil),
+			ExpectedEvents: []batch.Event{batch.PodFailedEvent},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			controller := newController()
+			controller.addJob(&batch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+			})
+			addPodAnnotation(testcase.oldPod, annotations)
+			addPodAnnotation(testcase.newPod, annotations)
+			controller.addPod(testcase.oldPod)
+
+			queue := controller.getWorkerQueue(fmt.Sprintf("%s/%s", namespace, "job1"))
+			for queue.Len() != 0 {
+				req, _ := queue.Get()
+				queue.Done(req)
+			}
+
+			controller.updatePod(testcase.oldPod, testcase.newPod)
+
+			var events []batch.Event
+			for queue.Len() != 0 {
+				req, _ := queue.Get()
+				events = append(events, req.(apis.Request).Event)
+				queue.Done(req)
+			}
+			if !reflect.DeepEqual(events, testcase.ExpectedEvents) {
+				t.Errorf("expected events %v, got %v", testcase.ExpectedEvents, events)
+			}
+		})
+	}
+}
+
 func TestDeletePodFunc(t *testing.T) {
 	namespace := "test"
 
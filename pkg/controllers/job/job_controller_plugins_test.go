@@ -35,7 +35,7 @@ func newFakeController() *Controller {
 
 	sharedInformers := informers.NewSharedInformerFactory(kubeClientSet, 0)
 
-	controller := NewJobController(kubeClientSet, volcanoClientSet, sharedInformers, 3)
+	controller := NewJobController(kubeClientSet, volcanoClientSet, sharedInformers, 3, "volcano")
 	return controller
 }
 
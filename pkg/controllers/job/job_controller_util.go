@@ -18,11 +18,16 @@ package job
 
 import (
 	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
 
 	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/helpers"
@@ -31,12 +36,29 @@ import (
 	jobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
 )
 
+// podTemplateHash returns the hash of the task template which the pods of the task are created from.
+func podTemplateHash(template *v1.PodTemplateSpec) string {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, template)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// isSupersededPod returns whether the pod is created from a superseded template of its task, whose hash
+// is templateHash; the pods created before their template hash was labeled are not, and neither are
+// the succeeded pods, whose work is done.
+func isSupersededPod(templateHash string, pod *v1.Pod) bool {
+	hash, found := pod.Labels[batch.PodTemplateHashKey]
+	return found && hash != templateHash && pod.Status.Phase != v1.PodSucceeded
+}
+
 //MakePodName append podname,jobname,taskName and index and returns the string
 func MakePodName(jobName string, taskName string, index int) string {
 	return fmt.Sprintf(jobhelpers.PodNameFmt, jobName, taskName, index)
 }
 
-func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
+// createJobPod creates the ix-th pod of the task template of the job, the pod is scheduled by schedulerName
+// if neither the template nor the job specifies a scheduler.
+func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, ix int, schedulerName string) *v1.Pod {
 	templateCopy := template.DeepCopy()
 
 	pod := &v1.Pod{
@@ -104,6 +126,10 @@ func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod
 	pod.Annotations[batch.JobNameKey] = job.Name
 	pod.Annotations[batch.JobVersion] = fmt.Sprintf("%d", job.Status.Version)
 
+	if job.Spec.CompletionMode == batch.IndexedCompletion {
+		setCompletionIndex(pod, jobhelpers.CompletionIndex(job, template.Name, ix))
+	}
+
 	if len(pod.Labels) == 0 {
 		pod.Labels = make(map[string]string)
 	}
@@ -111,20 +137,62 @@ func createJobPod(job *batch.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod
 	// Set pod labels for Service.
 	pod.Labels[batch.JobNameKey] = job.Name
 	pod.Labels[batch.JobNamespaceKey] = job.Namespace
+	pod.Labels[batch.PodTemplateHashKey] = podTemplateHash(template)
 
 	// we fill the schedulerName in the pod definition with the one specified in the QJ template
 	if job.Spec.SchedulerName != "" && pod.Spec.SchedulerName == "" {
 		pod.Spec.SchedulerName = job.Spec.SchedulerName
 	}
 
+	// The job may be created without the mutating webhook, which sets the scheduler name of its tasks.
+	if pod.Spec.SchedulerName == "" {
+		pod.Spec.SchedulerName = schedulerName
+	}
+
 	return pod
 }
 
+// setCompletionIndex exposes the completion index to the containers of the pod.
+func setCompletionIndex(pod *v1.Pod, index int) {
+	value := strconv.Itoa(index)
+	pod.Annotations[batch.JobCompletionIndexAnnotationKey] = value
+
+	env := v1.EnvVar{Name: batch.JobCompletionIndexEnv, Value: value}
+	for i := range pod.Spec.InitContainers {
+		pod.Spec.InitContainers[i].Env = append(pod.Spec.InitContainers[i].Env, env)
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, env)
+	}
+}
+
+// taskMinAvailable returns the minimal available pods of the tasks which set one, which is the
+// gang constraint of each task on top of the minAvailable of the job.
+func taskMinAvailable(job *batch.Job) map[string]int32 {
+	var result map[string]int32
+	for _, task := range job.Spec.Tasks {
+		if task.MinAvailable == nil {
+			continue
+		}
+		if result == nil {
+			result = map[string]int32{}
+		}
+		result[task.Name] = *task.MinAvailable
+	}
+
+	return result
+}
+
 func applyPolicies(job *batch.Job, req *apis.Request) batch.Action {
 	if len(req.Action) != 0 {
 		return req.Action
 	}
 
+	// The policies are not applied to a suspended job, e.g. to the pods evicted when suspending it.
+	if job.Spec.Suspend {
+		return batch.SuspendJobAction
+	}
+
 	if req.Event == batch.OutOfSyncEvent {
 		return batch.SyncJobAction
 	}
@@ -144,7 +212,7 @@ func applyPolicies(job *batch.Job, req *apis.Request) batch.Action {
 					policyEvents := getEventlist(policy)
 
 					if len(policyEvents) > 0 && len(req.Event) > 0 {
-						if checkEventExist(policyEvents, req.Event) || checkEventExist(policyEvents, batch.AnyEvent) {
+						if matchEvent(policyEvents, req.Event) {
 							return policy.Action
 						}
 					}
@@ -164,7 +232,7 @@ func applyPolicies(job *batch.Job, req *apis.Request) batch.Action {
 		policyEvents := getEventlist(policy)
 
 		if len(policyEvents) > 0 && len(req.Event) > 0 {
-			if checkEventExist(policyEvents, req.Event) || checkEventExist(policyEvents, batch.AnyEvent) {
+			if matchEvent(policyEvents, req.Event) {
 				return policy.Action
 			}
 		}
@@ -186,6 +254,16 @@ func getEventlist(policy batch.LifecyclePolicy) []batch.Event {
 	return policyEventsList
 }
 
+// matchEvent returns whether the policy events match the event of request; PodOOMKilled is not
+// matched by AnyEvent, as it used to be handled by kubelet restarting the container.
+func matchEvent(policyEvents []batch.Event, reqEvent batch.Event) bool {
+	if checkEventExist(policyEvents, reqEvent) {
+		return true
+	}
+
+	return reqEvent != batch.PodOOMKilledEvent && checkEventExist(policyEvents, batch.AnyEvent)
+}
+
 func checkEventExist(policyEvents []batch.Event, reqEvent batch.Event) bool {
 	for _, event := range policyEvents {
 		if event == reqEvent {
@@ -244,3 +322,103 @@ func isControlledBy(obj metav1.Object, gvk schema.GroupVersionKind) bool {
 	}
 	return false
 }
+
+// waitingDependency returns the first dependency of the task whose running pods are not enough yet,
+// together with its running and required number of pods; the dependency is empty if the task is not
+// waiting. A dependency is ready once its minAvailable pods, or all its replicas if minAvailable is not
+// set, are running or succeeded; unknown dependencies, which are rejected by the admission, are ignored.
+func waitingDependency(job *batch.Job, ts batch.TaskSpec, pods map[string]map[string]*v1.Pod) (string, int32, int32) {
+	for _, dependency := range ts.DependsOn {
+		var required int32 = -1
+		for _, task := range job.Spec.Tasks {
+			if task.Name == dependency {
+				required = task.Replicas
+				if task.MinAvailable != nil {
+					required = *task.MinAvailable
+				}
+				break
+			}
+		}
+		if required < 0 {
+			continue
+		}
+
+		var ready int32
+		for _, pod := range pods[dependency] {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			if pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodSucceeded {
+				ready++
+			}
+		}
+		if ready < required {
+			return dependency, ready, required
+		}
+	}
+
+	return "", 0, 0
+}
+
+// taskRetryDelay returns how long the failed pod of the task still waits before it is recreated for the
+// retried+1 time; the backoff starts from the time the pod failed and is doubled for every retry.
+func taskRetryDelay(ts batch.TaskSpec, retried int32, pod *v1.Pod, now time.Time) time.Duration {
+	backoff := defaultTaskBackoff
+	if ts.BackoffSeconds != nil {
+		backoff = time.Duration(*ts.BackoffSeconds) * time.Second
+	}
+	for i := int32(0); i < retried && backoff < maxTaskBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxTaskBackoff {
+		backoff = maxTaskBackoff
+	}
+
+	return podFailedTime(pod).Add(backoff).Sub(now)
+}
+
+// podFailedTime returns the time the last container of the pod terminated, or the creation time
+// of the pod if none of its containers has terminated.
+func podFailedTime(pod *v1.Pod) time.Time {
+	failed := pod.CreationTimestamp.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.FinishedAt.After(failed) {
+			failed = status.State.Terminated.FinishedAt.Time
+		}
+	}
+
+	return failed
+}
+
+// newOOMKilled returns whether a container of the pod was terminated for OOMKilled since the old pod.
+func newOOMKilled(oldPod, newPod *v1.Pod) bool {
+	old := oomKilledContainers(oldPod)
+	for container := range oomKilledContainers(newPod) {
+		if _, found := old[container]; !found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// oomKilledContainers returns the terminations of the containers for OOMKilled, identified by the
+// container name and its restart count when it was terminated.
+func oomKilledContainers(pod *v1.Pod) map[string]struct{} {
+	// The statuses are copied, as appending to the statuses of the cached pod may write into its array.
+	statuses := make([]v1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	containers := map[string]struct{}{}
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == oomKilledReason {
+			containers[fmt.Sprintf("%s/%d", status.Name, status.RestartCount)] = struct{}{}
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == oomKilledReason {
+			containers[fmt.Sprintf("%s/%d", status.Name, status.RestartCount-1)] = struct{}{}
+		}
+	}
+
+	return containers
+}
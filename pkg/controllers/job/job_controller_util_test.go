@@ -18,6 +18,7 @@ package job
 
 import (
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -239,7 +240,7 @@ func TestCreateJobPod(t *testing.T) {
 	for i, testcase := range testcases {
 
 		t.Run(testcase.Name, func(t *testing.T) {
-			pod := createJobPod(testcase.Job, testcase.PodTemplate, testcase.Index)
+			pod := createJobPod(testcase.Job, testcase.PodTemplate, testcase.Index, "volcano")
 
 			if testcase.ReturnVal != nil && pod != nil && pod.Name != testcase.ReturnVal.Name && pod.Namespace != testcase.ReturnVal.Namespace {
 				t.Errorf("Expected Return Value to be %v but got %v in case %d", testcase.ReturnVal, pod, i)
@@ -248,6 +249,102 @@ func TestCreateJobPod(t *testing.T) {
 	}
 }
 
+func TestCreateJobPodSchedulerName(t *testing.T) {
+	testcases := []struct {
+		Name              string
+		JobScheduler      string
+		TemplateScheduler string
+		Expected          string
+	}{
+		{
+			Name:              "scheduler of template",
+			JobScheduler:      "job-scheduler",
+			TemplateScheduler: "template-scheduler",
+			Expected:          "template-scheduler",
+		},
+		{
+			Name:         "scheduler of job",
+			JobScheduler: "job-scheduler",
+			Expected:     "job-scheduler",
+		},
+		{
+			Name:     "scheduler of controller",
+			Expected: "volcano-canary",
+		},
+	}
+
+	for _, testcase := range testcases {
+		job := &v1alpha1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "test"},
+			Spec:       v1alpha1.JobSpec{SchedulerName: testcase.JobScheduler},
+		}
+		template := &v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "task1"},
+			Spec:       v1.PodSpec{SchedulerName: testcase.TemplateScheduler},
+		}
+
+		pod := createJobPod(job, template, 0, "volcano-canary")
+		if pod.Spec.SchedulerName != testcase.Expected {
+			t.Errorf("case %s: expected scheduler %s, got %s", testcase.Name, testcase.Expected, pod.Spec.SchedulerName)
+		}
+	}
+}
+
+func TestCreateJobPodCompletionIndex(t *testing.T) {
+	testcases := []struct {
+		Name     string
+		Mode     v1alpha1.CompletionMode
+		Expected string
+	}{
+		{
+			Name:     "indexed",
+			Mode:     v1alpha1.IndexedCompletion,
+			Expected: "3",
+		},
+		{
+			Name: "non indexed",
+			Mode: v1alpha1.NonIndexedCompletion,
+		},
+	}
+
+	for _, testcase := range testcases {
+		job := &v1alpha1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "test"},
+			Spec: v1alpha1.JobSpec{
+				CompletionMode: testcase.Mode,
+				Tasks: []v1alpha1.TaskSpec{
+					{Name: "ps", Replicas: 2},
+					{Name: "worker", Replicas: 3},
+				},
+			},
+		}
+		template := &v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "init"}},
+				Containers:     []v1.Container{{Name: "main"}},
+			},
+		}
+
+		pod := createJobPod(job, template, 1, "volcano")
+		if index := pod.Annotations[v1alpha1.JobCompletionIndexAnnotationKey]; index != testcase.Expected {
+			t.Errorf("case %s: expected completion index %q, got %q", testcase.Name, testcase.Expected, index)
+		}
+		for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			var index string
+			for _, env := range container.Env {
+				if env.Name == v1alpha1.JobCompletionIndexEnv {
+					index = env.Value
+				}
+			}
+			if index != testcase.Expected {
+				t.Errorf("case %s: expected completion index %q in container %s, got %q",
+					testcase.Name, testcase.Expected, container.Name, index)
+			}
+		}
+	}
+}
+
 func TestApplyPolicies(t *testing.T) {
 	namespace := "test"
 	errorCode0 := int32(0)
@@ -564,6 +661,90 @@ func TestApplyPolicies(t *testing.T) {
 			Request:   &apis.Request{},
 			ReturnVal: v1alpha1.SyncJobAction,
 		},
+		{
+			Name: "Test Apply policies with PodOOMKilled event",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Action: v1alpha1.AbortJobAction,
+							Event:  v1alpha1.PodFailedEvent,
+						},
+						{
+							Action: v1alpha1.RestartJobAction,
+							Event:  v1alpha1.PodOOMKilledEvent,
+						},
+					},
+				},
+			},
+			Request: &apis.Request{
+				Event: v1alpha1.PodOOMKilledEvent,
+			},
+			ReturnVal: v1alpha1.RestartJobAction,
+		},
+		{
+			Name: "Test Apply policies with PodOOMKilled event not matched by any event",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Action: v1alpha1.RestartJobAction,
+							Event:  v1alpha1.AnyEvent,
+						},
+					},
+				},
+			},
+			Request: &apis.Request{
+				Event: v1alpha1.PodOOMKilledEvent,
+			},
+			ReturnVal: v1alpha1.SyncJobAction,
+		},
+		{
+			Name: "Test Apply policies on suspended job",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Suspend: true,
+					Policies: []v1alpha1.LifecyclePolicy{
+						{
+							Action: v1alpha1.RestartJobAction,
+							Event:  v1alpha1.PodEvictedEvent,
+						},
+					},
+				},
+			},
+			Request: &apis.Request{
+				Event: v1alpha1.PodEvictedEvent,
+			},
+			ReturnVal: v1alpha1.SuspendJobAction,
+		},
+		{
+			Name: "Test Apply policies on suspended job with command",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Suspend: true,
+				},
+			},
+			Request: &apis.Request{
+				Action: v1alpha1.TerminateJobAction,
+			},
+			ReturnVal: v1alpha1.TerminateJobAction,
+		},
 	}
 
 	for i, testcase := range testcases {
@@ -715,3 +896,184 @@ func TestTasksPriority_Swap(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitingDependency(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	namespace := "test"
+	job := &v1alpha1.Job{
+		Spec: v1alpha1.JobSpec{
+			Tasks: []v1alpha1.TaskSpec{
+				{Name: "ps", Replicas: 1},
+				{Name: "worker", Replicas: 2, DependsOn: []string{"ps"}},
+				{Name: "evaluator", Replicas: 1, DependsOn: []string{"ps", "worker"}},
+				{Name: "unknown", Replicas: 1, DependsOn: []string{"missing"}},
+				{Name: "server", Replicas: 3, MinAvailable: int32Ptr(2)},
+				{Name: "client", Replicas: 1, DependsOn: []string{"server"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name               string
+		Task               int
+		Pods               map[string]map[string]*v1.Pod
+		ExpectedDependency string
+		ExpectedReady      int32
+		ExpectedRequired   int32
+	}{
+		{
+			Name: "task without dependencies",
+			Task: 0,
+		},
+		{
+			Name:               "dependency without pods",
+			Task:               1,
+			ExpectedDependency: "ps",
+			ExpectedRequired:   1,
+		},
+		{
+			Name: "dependency is pending",
+			Task: 1,
+			Pods: map[string]map[string]*v1.Pod{
+				"ps": {"job1-ps-0": buildPod(namespace, "job1-ps-0", v1.PodPending, nil)},
+			},
+			ExpectedDependency: "ps",
+			ExpectedRequired:   1,
+		},
+		{
+			Name: "dependency is running",
+			Task: 1,
+			Pods: map[string]map[string]*v1.Pod{
+				"ps": {"job1-ps-0": buildPod(namespace, "job1-ps-0", v1.PodRunning, nil)},
+			},
+		},
+		{
+			Name: "second dependency is partially running",
+			Task: 2,
+			Pods: map[string]map[string]*v1.Pod{
+				"ps": {"job1-ps-0": buildPod(namespace, "job1-ps-0", v1.PodSucceeded, nil)},
+				"worker": {
+					"job1-worker-0": buildPod(namespace, "job1-worker-0", v1.PodRunning, nil),
+					"job1-worker-1": buildPod(namespace, "job1-worker-1", v1.PodPending, nil),
+				},
+			},
+			ExpectedDependency: "worker",
+			ExpectedReady:      1,
+			ExpectedRequired:   2,
+		},
+		{
+			Name: "unknown dependency is ignored",
+			Task: 3,
+		},
+		{
+			Name: "dependency below minAvailable",
+			Task: 5,
+			Pods: map[string]map[string]*v1.Pod{
+				"server": {
+					"job1-server-0": buildPod(namespace, "job1-server-0", v1.PodRunning, nil),
+					"job1-server-1": buildPod(namespace, "job1-server-1", v1.PodPending, nil),
+				},
+			},
+			ExpectedDependency: "server",
+			ExpectedReady:      1,
+			ExpectedRequired:   2,
+		},
+		{
+			Name: "dependency reaches minAvailable",
+			Task: 5,
+			Pods: map[string]map[string]*v1.Pod{
+				"server": {
+					"job1-server-0": buildPod(namespace, "job1-server-0", v1.PodRunning, nil),
+					"job1-server-1": buildPod(namespace, "job1-server-1", v1.PodRunning, nil),
+					"job1-server-2": buildPod(namespace, "job1-server-2", v1.PodPending, nil),
+				},
+			},
+		},
+	}
+
+	for i, testcase := range testCases {
+		dependency, ready, required := waitingDependency(job, job.Spec.Tasks[testcase.Task], testcase.Pods)
+		if dependency != testcase.ExpectedDependency || ready != testcase.ExpectedReady || required != testcase.ExpectedRequired {
+			t.Errorf("case %d (%s): expected %s %d/%d, got %s %d/%d", i, testcase.Name,
+				testcase.ExpectedDependency, testcase.ExpectedReady, testcase.ExpectedRequired, dependency, ready, required)
+		}
+	}
+}
+
+func TestTaskRetryDelay(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	now := time.Now()
+	failedAt := now.Add(-5 * time.Second)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+		Status: v1.PodStatus{
+			Phase: v1.PodFailed,
+			ContainerStatuses: []v1.ContainerStatus{
+				{State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: metav1.NewTime(failedAt)}}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		Name           string
+		BackoffSeconds *int32
+		Retried        int32
+		ExpectedDelay  time.Duration
+	}{
+		{
+			Name:          "default backoff",
+			ExpectedDelay: defaultTaskBackoff - 5*time.Second,
+		},
+		{
+			Name:          "backoff is doubled for every retry",
+			Retried:       2,
+			ExpectedDelay: 4*defaultTaskBackoff - 5*time.Second,
+		},
+		{
+			Name:          "backoff is capped",
+			Retried:       10,
+			ExpectedDelay: maxTaskBackoff - 5*time.Second,
+		},
+		{
+			Name:           "backoff has expired",
+			BackoffSeconds: int32Ptr(1),
+			ExpectedDelay:  -4 * time.Second,
+		},
+		{
+			Name:           "no backoff",
+			BackoffSeconds: int32Ptr(0),
+			Retried:        3,
+			ExpectedDelay:  -5 * time.Second,
+		},
+	}
+
+	for i, testcase := range testCases {
+		ts := v1alpha1.TaskSpec{Name: "task1", BackoffSeconds: testcase.BackoffSeconds}
+		if delay := taskRetryDelay(ts, testcase.Retried, pod, now); delay != testcase.ExpectedDelay {
+			t.Errorf("case %d (%s): expected delay %v, got %v", i, testcase.Name, testcase.ExpectedDelay, delay)
+		}
+	}
+}
+
+func TestOOMKilledContainersKeepsCachedPod(t *testing.T) {
+	oomKilled := &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+	// The init container statuses have spare capacity, which must not be written.
+	initStatuses := make([]v1.ContainerStatus, 1, 2)
+	initStatuses[0] = v1.ContainerStatus{Name: "init", State: v1.ContainerState{Terminated: oomKilled}}
+	spare := initStatuses[:2]
+	spare[1] = v1.ContainerStatus{Name: "spare"}
+
+	pod := buildPod("test", "pod1", v1.PodRunning, nil)
+	pod.Status.InitContainerStatuses = initStatuses
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{Name: "main", RestartCount: 1, LastTerminationState: v1.ContainerState{Terminated: oomKilled}},
+	}
+
+	containers := oomKilledContainers(pod)
+	if _, found := containers["init/0"]; !found || len(containers) != 2 {
+		t.Errorf("expected OOMKilled containers init/0 and main/0, got %v", containers)
+	}
+	if spare[1].Name != "spare" {
+		t.Errorf("expected the array of the cached init container statuses untouched, got %s", spare[1].Name)
+	}
+}
@@ -1292,3 +1292,124 @@ func TestTerminatingState_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestSuspendState_Execute(t *testing.T) {
+	namespace := "test"
+
+	newJobInfo := func(phase v1alpha1.JobPhase, suspend bool, pods map[string]map[string]*v1.Pod) *apis.JobInfo {
+		return &apis.JobInfo{
+			Namespace: namespace,
+			Name:      "jobinfo1",
+			Job: &v1alpha1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "Job1",
+					Namespace: namespace,
+				},
+				Spec: v1alpha1.JobSpec{
+					Suspend: suspend,
+				},
+				Status: v1alpha1.JobStatus{
+					State: v1alpha1.JobState{
+						Phase: phase,
+					},
+				},
+			},
+			Pods: pods,
+		}
+	}
+
+	testcases := []struct {
+		Name          string
+		JobInfo       *apis.JobInfo
+		Action        v1alpha1.Action
+		ExpectedPhase v1alpha1.JobPhase
+	}{
+		{
+			Name: "RunningState-SuspendAction case",
+			JobInfo: newJobInfo(v1alpha1.Running, true, map[string]map[string]*v1.Pod{
+				"task1": {
+					"pod1": buildPod(namespace, "pod1", v1.PodRunning, nil),
+				},
+			}),
+			Action:        v1alpha1.SuspendJobAction,
+			ExpectedPhase: v1alpha1.Suspending,
+		},
+		{
+			Name:          "PendingState-SuspendAction case",
+			JobInfo:       newJobInfo(v1alpha1.Pending, true, nil),
+			Action:        v1alpha1.SuspendJobAction,
+			ExpectedPhase: v1alpha1.Suspending,
+		},
+		{
+			Name: "SuspendingState-SuspendAction case with alive pods",
+			JobInfo: newJobInfo(v1alpha1.Suspending, true, map[string]map[string]*v1.Pod{
+				"task1": {
+					"pod1": buildPod(namespace, "pod1", v1.PodRunning, nil),
+				},
+			}),
+			Action:        v1alpha1.SuspendJobAction,
+			ExpectedPhase: v1alpha1.Suspending,
+		},
+		{
+			Name: "SuspendingState-SuspendAction case with finished pods",
+			JobInfo: newJobInfo(v1alpha1.Suspending, true, map[string]map[string]*v1.Pod{
+				"task1": {
+					"pod1": buildPod(namespace, "pod1", v1.PodSucceeded, nil),
+				},
+			}),
+			Action:        v1alpha1.SuspendJobAction,
+			ExpectedPhase: v1alpha1.Suspended,
+		},
+		{
+			Name:          "SuspendedState-SuspendAction case",
+			JobInfo:       newJobInfo(v1alpha1.Suspended, true, nil),
+			Action:        v1alpha1.SuspendJobAction,
+			ExpectedPhase: v1alpha1.Suspended,
+		},
+		{
+			Name:          "SuspendedState-SyncAction case",
+			JobInfo:       newJobInfo(v1alpha1.Suspended, false, nil),
+			Action:        v1alpha1.SyncJobAction,
+			ExpectedPhase: v1alpha1.Pending,
+		},
+		{
+			Name:          "SuspendedState-TerminateAction case",
+			JobInfo:       newJobInfo(v1alpha1.Suspended, true, nil),
+			Action:        v1alpha1.TerminateJobAction,
+			ExpectedPhase: v1alpha1.Terminating,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Name, func(t *testing.T) {
+			st := state.NewState(testcase.JobInfo)
+
+			fakecontroller := newFakeController()
+			state.KillJob = fakecontroller.killJob
+
+			_, err := fakecontroller.vcClient.BatchV1alpha1().Jobs(namespace).Create(testcase.JobInfo.Job)
+			if err != nil {
+				t.Error("Error while creating Job")
+			}
+
+			err = fakecontroller.cache.Add(testcase.JobInfo.Job)
+			if err != nil {
+				t.Error("Error while adding Job in cache")
+			}
+
+			err = st.Execute(testcase.Action)
+			if err != nil {
+				t.Errorf("Expected Error not to occur but got: %s", err)
+			}
+
+			jobInfo, err := fakecontroller.cache.Get(fmt.Sprintf("%s/%s", testcase.JobInfo.Job.Namespace, testcase.JobInfo.Job.Name))
+			if err != nil {
+				t.Error("Error while retrieving value from Cache")
+			}
+
+			if jobInfo.Job.Status.State.Phase != testcase.ExpectedPhase {
+				t.Errorf("Expected phase %s, but got %s", testcase.ExpectedPhase, jobInfo.Job.Status.State.Phase)
+			}
+		})
+	}
+}
@@ -42,3 +42,10 @@ type PluginInterface interface {
 	// do once when killJob
 	OnJobDelete(job *vcbatch.Job) error
 }
+
+// VolumeInjector is implemented by the plugins mounting volumes of their own into the pods of a job,
+// which the containers of its tasks may reference without declaring them.
+type VolumeInjector interface {
+	// The names of the volumes mounted into the pods of the job.
+	InjectedVolumes(job *vcbatch.Job) []string
+}
@@ -98,6 +98,11 @@ func (sp *sshPlugin) OnJobDelete(job *batch.Job) error {
 	return helpers.DeleteSecret(job, sp.Clientset.KubeClients, sp.secretName(job))
 }
 
+// InjectedVolumes returns the volume of the ssh secret mounted into the pods of the job.
+func (sp *sshPlugin) InjectedVolumes(job *batch.Job) []string {
+	return []string{sp.secretName(job)}
+}
+
 func (sp *sshPlugin) mountRsaKey(pod *v1.Pod, job *batch.Job) {
 	secretName := sp.secretName(job)
 
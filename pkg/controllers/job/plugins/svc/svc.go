@@ -133,6 +133,11 @@ func (sp *servicePlugin) OnJobDelete(job *batch.Job) error {
 	return nil
 }
 
+// InjectedVolumes returns the volume of the hosts ConfigMap mounted into the pods of the job.
+func (sp *servicePlugin) InjectedVolumes(job *batch.Job) []string {
+	return []string{sp.cmName(job)}
+}
+
 func (sp *servicePlugin) mountConfigmap(pod *v1.Pod, job *batch.Job) {
 	cmName := sp.cmName(job)
 	cmVolume := v1.Volume{
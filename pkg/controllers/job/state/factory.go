@@ -77,6 +77,10 @@ func NewState(jobInfo *apis.JobInfo) State {
 		return &abortedState{job: jobInfo}
 	case vcbatch.Completing:
 		return &completingState{job: jobInfo}
+	case vcbatch.Suspending:
+		return &suspendingState{job: jobInfo}
+	case vcbatch.Suspended:
+		return &suspendedState{job: jobInfo}
 	}
 
 	// It's pending by default.
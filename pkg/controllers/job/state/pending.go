@@ -49,6 +49,11 @@ func (ps *pendingState) Execute(action vcbatch.Action) error {
 			status.State.Phase = vcbatch.Terminating
 			return true
 		})
+	case vcbatch.SuspendJobAction:
+		return KillJob(ps.job, PodRetainPhaseSoft, func(status *vcbatch.JobStatus) bool {
+			status.State.Phase = vcbatch.Suspending
+			return true
+		})
 	default:
 		return SyncJob(ps.job, func(status *vcbatch.JobStatus) bool {
 			phase := vcbatch.Pending
@@ -43,6 +43,11 @@ func (ps *runningState) Execute(action vcbatch.Action) error {
 			status.State.Phase = vcbatch.Terminating
 			return true
 		})
+	case vcbatch.SuspendJobAction:
+		return KillJob(ps.job, PodRetainPhaseSoft, func(status *vcbatch.JobStatus) bool {
+			status.State.Phase = vcbatch.Suspending
+			return true
+		})
 	case vcbatch.CompleteJobAction:
 		return KillJob(ps.job, PodRetainPhaseSoft, func(status *vcbatch.JobStatus) bool {
 			status.State.Phase = vcbatch.Completing
@@ -50,6 +55,10 @@ func (ps *runningState) Execute(action vcbatch.Action) error {
 		})
 	default:
 		return SyncJob(ps.job, func(status *vcbatch.JobStatus) bool {
+			if ps.job.Job.Spec.CompletionMode == vcbatch.IndexedCompletion {
+				return syncIndexedJob(ps.job.Job, status)
+			}
+
 			if status.Succeeded+status.Failed == TotalTasks(ps.job.Job) {
 				status.State.Phase = vcbatch.Completed
 				return true
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	vcbatch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/controllers/apis"
+)
+
+type suspendedState struct {
+	job *apis.JobInfo
+}
+
+func (ss *suspendedState) Execute(action vcbatch.Action) error {
+	switch action {
+	case vcbatch.AbortJobAction, vcbatch.TerminateJobAction, vcbatch.CompleteJobAction:
+		return executeOnSuspended(ss.job, action)
+	case vcbatch.SuspendJobAction:
+		return KillJob(ss.job, PodRetainPhaseSoft, nil)
+	default:
+		// spec.suspend is unset: the job starts over from Pending with a new PodGroup,
+		// the pods which already finished are kept and not created again.
+		return KillJob(ss.job, PodRetainPhaseSoft, func(status *vcbatch.JobStatus) bool {
+			status.State.Phase = vcbatch.Pending
+			return true
+		})
+	}
+}
+
+// executeOnSuspended handles the commands which finish a suspended job.
+func executeOnSuspended(job *apis.JobInfo, action vcbatch.Action) error {
+	phase := map[vcbatch.Action]vcbatch.JobPhase{
+		vcbatch.AbortJobAction:     vcbatch.Aborting,
+		vcbatch.TerminateJobAction: vcbatch.Terminating,
+		vcbatch.CompleteJobAction:  vcbatch.Completing,
+	}[action]
+
+	return KillJob(job, PodRetainPhaseSoft, func(status *vcbatch.JobStatus) bool {
+		status.State.Phase = phase
+		return true
+	})
+}
@@ -18,6 +18,7 @@ package state
 
 import (
 	vcbatch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/controllers/job/helpers"
 )
 
 //DefaultMaxRetry is the default number of retries.
@@ -33,3 +34,20 @@ func TotalTasks(job *vcbatch.Job) int32 {
 
 	return rep
 }
+
+// syncIndexedJob completes an Indexed job once every completion index succeeded, it fails if all
+// of its pods are finished without that.
+func syncIndexedJob(job *vcbatch.Job, status *vcbatch.JobStatus) bool {
+	total := TotalTasks(job)
+	if int32(helpers.ParseIndexes(status.CompletedIndexes, int(total)).Len()) == total {
+		status.State.Phase = vcbatch.Completed
+		return true
+	}
+
+	if status.Succeeded+status.Failed == total {
+		status.State.Phase = vcbatch.Failed
+		return true
+	}
+
+	return false
+}
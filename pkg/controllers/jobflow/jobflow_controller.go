@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobflow
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	vcclientset "volcano.sh/volcano/pkg/client/clientset/versioned"
+	vcscheme "volcano.sh/volcano/pkg/client/clientset/versioned/scheme"
+	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
+	batchinformer "volcano.sh/volcano/pkg/client/informers/externalversions/batch/v1alpha1"
+	batchlister "volcano.sh/volcano/pkg/client/listers/batch/v1alpha1"
+)
+
+// Controller the JobFlow Controller type
+type Controller struct {
+	kubeClient kubernetes.Interface
+	vcClient   vcclientset.Interface
+
+	vcInformers  informerfactory.SharedInformerFactory
+	flowInformer batchinformer.JobFlowInformer
+	jobInformer  batchinformer.JobInformer
+
+	// A store of flows
+	flowLister batchlister.JobFlowLister
+	flowSynced func() bool
+
+	// A store of jobs
+	jobLister batchlister.JobLister
+	jobSynced func() bool
+
+	// queue is the queue of flows to sync
+	queue workqueue.RateLimitingInterface
+
+	recorder record.EventRecorder
+}
+
+// NewJobFlowController create new JobFlow Controller
+func NewJobFlowController(
+	kubeClient kubernetes.Interface,
+	vcClient vcclientset.Interface,
+	vcInformers informerfactory.SharedInformerFactory,
+) *Controller {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+
+	c := &Controller{
+		kubeClient: kubeClient,
+		vcClient:   vcClient,
+
+		vcInformers: vcInformers,
+
+		queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+
+		recorder: eventBroadcaster.NewRecorder(vcscheme.Scheme, v1.EventSource{Component: "vc-controllers"}),
+	}
+
+	c.flowInformer = vcInformers.Batch().V1alpha1().JobFlows()
+	c.flowLister = c.flowInformer.Lister()
+	c.flowSynced = c.flowInformer.Informer().HasSynced
+	c.flowInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueFlow,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueFlow(newObj)
+		},
+	})
+
+	c.jobInformer = vcInformers.Batch().V1alpha1().Jobs()
+	c.jobLister = c.jobInformer.Lister()
+	c.jobSynced = c.jobInformer.Informer().HasSynced
+	c.jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueFlowOfJob,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.enqueueFlowOfJob(newObj)
+		},
+		DeleteFunc: c.enqueueFlowOfJob,
+	})
+
+	return c
+}
+
+// Run start JobFlow Controller
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	// The informers of the shared factory are only started once.
+	c.vcInformers.Start(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, c.flowSynced, c.jobSynced) {
+		klog.Errorf("unable to sync caches for JobFlow controller.")
+		return
+	}
+
+	go wait.Until(c.worker, 0, stopCh)
+
+	klog.Infof("JobFlowController is running ...... ")
+}
+
+func (c *Controller) worker() {
+	for c.processNextFlow() {
+	}
+}
+
+func (c *Controller) processNextFlow() bool {
+	obj, shutdown := c.queue.Get()
+	if shutdown {
+		klog.Errorf("Fail to pop item from queue")
+		return false
+	}
+
+	key := obj.(string)
+	defer c.queue.Done(key)
+
+	if err := c.syncFlow(key); err != nil {
+		klog.Errorf("Failed to sync JobFlow <%s>: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+
+	return true
+}
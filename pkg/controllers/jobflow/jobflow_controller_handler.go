@@ -0,0 +1,352 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobflow
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+)
+
+func (c *Controller) enqueueFlow(obj interface{}) {
+	flow, ok := obj.(*batch.JobFlow)
+	if !ok {
+		klog.Errorf("Failed to convert %v to JobFlow", obj)
+		return
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(flow)
+	if err != nil {
+		klog.Errorf("Failed to get key of JobFlow <%s/%s>: %v", flow.Namespace, flow.Name, err)
+		return
+	}
+
+	c.queue.Add(key)
+}
+
+// enqueueFlowOfJob enqueues the flow controlling the job, so that its stages are synced.
+func (c *Controller) enqueueFlowOfJob(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	job, ok := obj.(*batch.Job)
+	if !ok {
+		klog.Errorf("Failed to convert %v to Job", obj)
+		return
+	}
+
+	ref := metav1.GetControllerOf(job)
+	if ref == nil || ref.Kind != helpers.JobFlowKind.Kind ||
+		ref.APIVersion != helpers.JobFlowKind.GroupVersion().String() {
+		return
+	}
+
+	c.queue.Add(job.Namespace + "/" + ref.Name)
+}
+
+// syncFlow creates the jobs of the stages whose dependencies are completed and
+// updates the status of the flow.
+func (c *Controller) syncFlow(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	flow, err := c.flowLister.JobFlows(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if flow.DeletionTimestamp != nil {
+		return nil
+	}
+
+	if err := validateStages(flow.Spec.Stages); err != nil {
+		if flow.Status.Phase != batch.FlowInvalid {
+			c.recorder.Event(flow, v1.EventTypeWarning, string(batch.FlowInvalid), err.Error())
+		}
+		return c.updateStatus(flow, batch.JobFlowStatus{
+			Phase:   batch.FlowInvalid,
+			Message: err.Error(),
+		})
+	}
+
+	jobs, err := c.stageJobs(flow)
+	if err != nil {
+		return err
+	}
+
+	status, start := flowStatus(flow, jobs)
+	for _, stage := range start {
+		if err := c.createStageJob(flow, stage); err != nil {
+			return err
+		}
+	}
+
+	if status.Phase != flow.Status.Phase && status.Phase != batch.FlowRunning {
+		c.recorder.Eventf(flow, v1.EventTypeNormal, string(status.Phase), "JobFlow is %s", strings.ToLower(string(status.Phase)))
+	}
+
+	return c.updateStatus(flow, status)
+}
+
+// stageJobs returns the jobs controlled by the flow by stage name.
+func (c *Controller) stageJobs(flow *batch.JobFlow) (map[string]*batch.Job, error) {
+	selector := labels.SelectorFromSet(labels.Set{batch.JobFlowNameKey: flow.Name})
+	jobs, err := c.jobLister.Jobs(flow.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	stageJobs := map[string]*batch.Job{}
+	for _, job := range jobs {
+		if !metav1.IsControlledBy(job, flow) {
+			continue
+		}
+		stageJobs[job.Labels[batch.JobFlowStageKey]] = job
+	}
+
+	return stageJobs, nil
+}
+
+func (c *Controller) createStageJob(flow *batch.JobFlow, stage *batch.StageSpec) error {
+	job := &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: flow.Namespace,
+			Name:      stageJobName(flow, stage.Name),
+			Labels: map[string]string{
+				batch.JobFlowNameKey:  flow.Name,
+				batch.JobFlowStageKey: stage.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(flow, helpers.JobFlowKind),
+			},
+		},
+		Spec: *stage.Template.DeepCopy(),
+	}
+
+	if _, err := c.vcClient.BatchV1alpha1().Jobs(flow.Namespace).Create(job); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		// The job may be created by the last sync and not in the cache yet.
+		existing, err := c.vcClient.BatchV1alpha1().Jobs(flow.Namespace).Get(job.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(existing, flow) {
+			c.recorder.Eventf(flow, v1.EventTypeWarning, "StageConflict",
+				"Job <%s> of stage <%s> exists and is not controlled by the JobFlow", job.Name, stage.Name)
+			return fmt.Errorf("job <%s/%s> of stage <%s> is not controlled by JobFlow <%s>",
+				job.Namespace, job.Name, stage.Name, flow.Name)
+		}
+		return nil
+	}
+
+	c.recorder.Eventf(flow, v1.EventTypeNormal, "StageStarted", "Created Job <%s> of stage <%s>", job.Name, stage.Name)
+	return nil
+}
+
+func (c *Controller) updateStatus(flow *batch.JobFlow, status batch.JobFlowStatus) error {
+	if equality.Semantic.DeepEqual(flow.Status, status) {
+		return nil
+	}
+
+	newFlow := flow.DeepCopy()
+	newFlow.Status = status
+	if _, err := c.vcClient.BatchV1alpha1().JobFlows(flow.Namespace).UpdateStatus(newFlow); err != nil {
+		klog.Errorf("Failed to update status of JobFlow <%s/%s>: %v", flow.Namespace, flow.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+func stageJobName(flow *batch.JobFlow, stage string) string {
+	return fmt.Sprintf("%s-%s", flow.Name, stage)
+}
+
+// validateStages checks that the stages have unique names and only depend on
+// other known stages without cycles.
+func validateStages(stages []batch.StageSpec) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("no stage is defined")
+	}
+
+	deps := map[string][]string{}
+	for _, stage := range stages {
+		if errs := validation.IsDNS1123Label(stage.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid name of stage <%s>: %s", stage.Name, strings.Join(errs, ", "))
+		}
+		if _, found := deps[stage.Name]; found {
+			return fmt.Errorf("stage <%s> is defined more than once", stage.Name)
+		}
+		deps[stage.Name] = stage.DependsOn
+	}
+
+	for _, stage := range stages {
+		for _, dep := range stage.DependsOn {
+			if _, found := deps[dep]; !found {
+				return fmt.Errorf("stage <%s> depends on unknown stage <%s>", stage.Name, dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("stage <%s> depends on itself", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, stage := range stages {
+		if err := visit(stage.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stagePhaseOfJob returns the phase of the stage whose job is created.
+func stagePhaseOfJob(job *batch.Job) batch.StagePhase {
+	switch job.Status.State.Phase {
+	case batch.Completed:
+		return batch.StageCompleted
+	case batch.Failed, batch.Aborted, batch.Terminated:
+		return batch.StageFailed
+	}
+
+	return batch.StageRunning
+}
+
+// flowStatus computes the status of a valid flow from the jobs of its stages, and returns
+// the stages whose dependencies are completed and whose jobs are to be created.
+func flowStatus(flow *batch.JobFlow, jobs map[string]*batch.Job) (batch.JobFlowStatus, []*batch.StageSpec) {
+	previous := map[string]batch.StageStatus{}
+	for _, stage := range flow.Status.Stages {
+		previous[stage.Name] = stage
+	}
+
+	stages := map[string]*batch.StageSpec{}
+	for i := range flow.Spec.Stages {
+		stages[flow.Spec.Stages[i].Name] = &flow.Spec.Stages[i]
+	}
+
+	var start []*batch.StageSpec
+	statuses := map[string]batch.StageStatus{}
+	var resolve func(name string) batch.StagePhase
+	resolve = func(name string) batch.StagePhase {
+		if status, found := statuses[name]; found {
+			return status.Phase
+		}
+
+		stage := stages[name]
+		status := batch.StageStatus{Name: name}
+		if job, found := jobs[name]; found {
+			status.Phase = stagePhaseOfJob(job)
+			status.JobName = job.Name
+		} else if prev := previous[name]; prev.Phase == batch.StageCompleted || prev.Phase == batch.StageFailed {
+			// The job of a finished stage may be deleted, e.g. by its TTL; the stage is not run again.
+			status = prev
+		} else {
+			ready, failed := true, false
+			for _, dep := range stage.DependsOn {
+				switch resolve(dep) {
+				case batch.StageCompleted:
+				case batch.StageFailed, batch.StageAborted:
+					failed = true
+					ready = false
+				default:
+					ready = false
+				}
+			}
+
+			switch {
+			case failed && flow.Spec.AbortOnFailure:
+				status.Phase = batch.StageAborted
+			case ready:
+				status.Phase = batch.StageRunning
+				status.JobName = stageJobName(flow, name)
+				start = append(start, stage)
+			default:
+				status.Phase = batch.StageWaiting
+			}
+		}
+
+		statuses[name] = status
+		return status.Phase
+	}
+
+	status := batch.JobFlowStatus{}
+	completed, running, failed := 0, 0, 0
+	for _, stage := range flow.Spec.Stages {
+		resolve(stage.Name)
+		stageStatus := statuses[stage.Name]
+		status.Stages = append(status.Stages, stageStatus)
+
+		switch stageStatus.Phase {
+		case batch.StageCompleted:
+			completed++
+		case batch.StageRunning:
+			running++
+		case batch.StageFailed:
+			failed++
+		}
+	}
+
+	switch {
+	case completed == len(flow.Spec.Stages):
+		status.Phase = batch.FlowCompleted
+	case running == 0 && failed > 0:
+		// The remaining stages are aborted or wait for failed stages forever.
+		status.Phase = batch.FlowFailed
+	default:
+		status.Phase = batch.FlowRunning
+	}
+
+	return status, start
+}
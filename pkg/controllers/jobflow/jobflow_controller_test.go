@@ -0,0 +1,319 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobflow
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+
+	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
+)
+
+func newFakeController() *Controller {
+	kubeClient := kubeclient.NewSimpleClientset()
+	vcClient := vcclient.NewSimpleClientset()
+
+	return NewJobFlowController(kubeClient, vcClient, informerfactory.NewSharedInformerFactory(vcClient, 0))
+}
+
+func newFlow(abortOnFailure bool, stages ...batch.StageSpec) *batch.JobFlow {
+	return &batch.JobFlow{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "flow",
+			UID:       "flow-uid",
+		},
+		Spec: batch.JobFlowSpec{
+			Stages:         stages,
+			AbortOnFailure: abortOnFailure,
+		},
+	}
+}
+
+func newStage(name string, dependsOn ...string) batch.StageSpec {
+	return batch.StageSpec{
+		Name:      name,
+		DependsOn: dependsOn,
+		Template:  batch.JobSpec{MinAvailable: 1},
+	}
+}
+
+func newStageJob(flow *batch.JobFlow, stage string, phase batch.JobPhase) *batch.Job {
+	return &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: flow.Namespace,
+			Name:      stageJobName(flow, stage),
+			Labels: map[string]string{
+				batch.JobFlowNameKey:  flow.Name,
+				batch.JobFlowStageKey: stage,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(flow, helpers.JobFlowKind),
+			},
+		},
+		Status: batch.JobStatus{
+			State: batch.JobState{Phase: phase},
+		},
+	}
+}
+
+func TestValidateStages(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stages []batch.StageSpec
+		valid  bool
+	}{
+		{
+			name:   "chain of stages",
+			stages: []batch.StageSpec{newStage("a"), newStage("b", "a"), newStage("c", "a", "b")},
+			valid:  true,
+		},
+		{
+			name:   "no stage",
+			stages: nil,
+		},
+		{
+			name:   "duplicated stage",
+			stages: []batch.StageSpec{newStage("a"), newStage("a")},
+		},
+		{
+			name:   "invalid stage name",
+			stages: []batch.StageSpec{newStage("A_1")},
+		},
+		{
+			name:   "unknown dependency",
+			stages: []batch.StageSpec{newStage("a", "b")},
+		},
+		{
+			name:   "cycle of stages",
+			stages: []batch.StageSpec{newStage("a", "c"), newStage("b", "a"), newStage("c", "b")},
+		},
+		{
+			name:   "stage depending on itself",
+			stages: []batch.StageSpec{newStage("a", "a")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := validateStages(testCase.stages)
+		if (err == nil) != testCase.valid {
+			t.Errorf("case %s: expected valid %v, got error %v", testCase.name, testCase.valid, err)
+		}
+	}
+}
+
+func TestSyncFlow(t *testing.T) {
+	chain := newFlow(false, newStage("a"), newStage("b", "a"), newStage("c", "b"), newStage("d"))
+	abortChain := newFlow(true, newStage("a"), newStage("b", "a"), newStage("c", "b"), newStage("d"))
+
+	testCases := []struct {
+		name           string
+		flow           *batch.JobFlow
+		jobs           []*batch.Job
+		expectedPhase  batch.JobFlowPhase
+		expectedStages map[string]batch.StagePhase
+		expectedJobs   []string
+	}{
+		{
+			name:          "starts the stages without dependencies",
+			flow:          chain,
+			expectedPhase: batch.FlowRunning,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageRunning,
+				"b": batch.StageWaiting,
+				"c": batch.StageWaiting,
+				"d": batch.StageRunning,
+			},
+			expectedJobs: []string{"flow-a", "flow-d"},
+		},
+		{
+			name: "starts the stage once its dependency is completed",
+			flow: chain,
+			jobs: []*batch.Job{
+				newStageJob(chain, "a", batch.Completed),
+				newStageJob(chain, "d", batch.Running),
+			},
+			expectedPhase: batch.FlowRunning,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageCompleted,
+				"b": batch.StageRunning,
+				"c": batch.StageWaiting,
+				"d": batch.StageRunning,
+			},
+			expectedJobs: []string{"flow-a", "flow-b", "flow-d"},
+		},
+		{
+			name: "completes the flow once all stages are completed",
+			flow: chain,
+			jobs: []*batch.Job{
+				newStageJob(chain, "a", batch.Completed),
+				newStageJob(chain, "b", batch.Completed),
+				newStageJob(chain, "c", batch.Completed),
+				newStageJob(chain, "d", batch.Completed),
+			},
+			expectedPhase: batch.FlowCompleted,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageCompleted,
+				"b": batch.StageCompleted,
+				"c": batch.StageCompleted,
+				"d": batch.StageCompleted,
+			},
+			expectedJobs: []string{"flow-a", "flow-b", "flow-c", "flow-d"},
+		},
+		{
+			name: "keeps the dependent stages waiting on failure",
+			flow: chain,
+			jobs: []*batch.Job{
+				newStageJob(chain, "a", batch.Failed),
+				newStageJob(chain, "d", batch.Running),
+			},
+			expectedPhase: batch.FlowRunning,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageFailed,
+				"b": batch.StageWaiting,
+				"c": batch.StageWaiting,
+				"d": batch.StageRunning,
+			},
+			expectedJobs: []string{"flow-a", "flow-d"},
+		},
+		{
+			name: "fails the flow once no stage is running",
+			flow: chain,
+			jobs: []*batch.Job{
+				newStageJob(chain, "a", batch.Failed),
+				newStageJob(chain, "d", batch.Completed),
+			},
+			expectedPhase: batch.FlowFailed,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageFailed,
+				"b": batch.StageWaiting,
+				"c": batch.StageWaiting,
+				"d": batch.StageCompleted,
+			},
+			expectedJobs: []string{"flow-a", "flow-d"},
+		},
+		{
+			name: "aborts the dependent stages on failure",
+			flow: abortChain,
+			jobs: []*batch.Job{
+				newStageJob(abortChain, "a", batch.Terminated),
+			},
+			expectedPhase: batch.FlowRunning,
+			expectedStages: map[string]batch.StagePhase{
+				"a": batch.StageFailed,
+				"b": batch.StageAborted,
+				"c": batch.StageAborted,
+				"d": batch.StageRunning,
+			},
+			expectedJobs: []string{"flow-a", "flow-d"},
+		},
+		{
+			name:           "does not create jobs for invalid flow",
+			flow:           newFlow(false, newStage("a", "b")),
+			expectedPhase:  batch.FlowInvalid,
+			expectedStages: map[string]batch.StagePhase{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+
+		flow := testCase.flow.DeepCopy()
+		if _, err := c.vcClient.BatchV1alpha1().JobFlows(flow.Namespace).Create(flow); err != nil {
+			t.Fatalf("case %s: failed to create flow: %v", testCase.name, err)
+		}
+		c.flowInformer.Informer().GetIndexer().Add(flow)
+		for _, job := range testCase.jobs {
+			if _, err := c.vcClient.BatchV1alpha1().Jobs(job.Namespace).Create(job); err != nil {
+				t.Fatalf("case %s: failed to create job: %v", testCase.name, err)
+			}
+			c.jobInformer.Informer().GetIndexer().Add(job)
+		}
+
+		if err := c.syncFlow(flow.Namespace + "/" + flow.Name); err != nil {
+			t.Errorf("case %s: failed to sync flow: %v", testCase.name, err)
+			continue
+		}
+
+		updated, err := c.vcClient.BatchV1alpha1().JobFlows(flow.Namespace).Get(flow.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("case %s: failed to get flow: %v", testCase.name, err)
+		}
+		if updated.Status.Phase != testCase.expectedPhase {
+			t.Errorf("case %s: expected phase %s, got %s", testCase.name, testCase.expectedPhase, updated.Status.Phase)
+		}
+		stages := map[string]batch.StagePhase{}
+		for _, stage := range updated.Status.Stages {
+			stages[stage.Name] = stage.Phase
+		}
+		if !reflect.DeepEqual(stages, testCase.expectedStages) {
+			t.Errorf("case %s: expected stages %v, got %v", testCase.name, testCase.expectedStages, stages)
+		}
+
+		var jobs []string
+		for _, stage := range flow.Spec.Stages {
+			job, err := c.vcClient.BatchV1alpha1().Jobs(flow.Namespace).Get(stageJobName(flow, stage.Name), metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			if !metav1.IsControlledBy(job, flow) {
+				t.Errorf("case %s: job %s is not controlled by the flow", testCase.name, job.Name)
+			}
+			jobs = append(jobs, job.Name)
+		}
+		if !reflect.DeepEqual(jobs, testCase.expectedJobs) {
+			t.Errorf("case %s: expected jobs %v, got %v", testCase.name, testCase.expectedJobs, jobs)
+		}
+	}
+}
+
+func TestSyncFlowKeepsDeletedStageJob(t *testing.T) {
+	c := newFakeController()
+
+	flow := newFlow(false, newStage("a"), newStage("b", "a"))
+	flow.Status = batch.JobFlowStatus{
+		Phase: batch.FlowRunning,
+		Stages: []batch.StageStatus{
+			{Name: "a", Phase: batch.StageCompleted, JobName: "flow-a"},
+			{Name: "b", Phase: batch.StageRunning, JobName: "flow-b"},
+		},
+	}
+	job := newStageJob(flow, "b", batch.Completed)
+
+	c.vcClient.BatchV1alpha1().JobFlows(flow.Namespace).Create(flow)
+	c.flowInformer.Informer().GetIndexer().Add(flow)
+	c.vcClient.BatchV1alpha1().Jobs(job.Namespace).Create(job)
+	c.jobInformer.Informer().GetIndexer().Add(job)
+
+	if err := c.syncFlow(flow.Namespace + "/" + flow.Name); err != nil {
+		t.Fatalf("failed to sync flow: %v", err)
+	}
+
+	if _, err := c.vcClient.BatchV1alpha1().Jobs(flow.Namespace).Get("flow-a", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the deleted job of completed stage not to be created again")
+	}
+	updated, _ := c.vcClient.BatchV1alpha1().JobFlows(flow.Namespace).Get(flow.Name, metav1.GetOptions{})
+	if updated.Status.Phase != batch.FlowCompleted {
+		t.Errorf("expected phase %s, got %s", batch.FlowCompleted, updated.Status.Phase)
+	}
+}
@@ -17,18 +17,23 @@ limitations under the License.
 package podgroup
 
 import (
+	"time"
+
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
 	scheduling "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclientset "volcano.sh/volcano/pkg/client/clientset/versioned"
+	vcscheme "volcano.sh/volcano/pkg/client/clientset/versioned/scheme"
 	informerfactory "volcano.sh/volcano/pkg/client/informers/externalversions"
 	schedulinginformer "volcano.sh/volcano/pkg/client/informers/externalversions/scheduling/v1alpha2"
 	schedulinglister "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
@@ -51,6 +56,12 @@ type Controller struct {
 	pgSynced func() bool
 
 	queue workqueue.RateLimitingInterface
+	// pgQueue is the queue of podgroups whose minResources is computed from member pods
+	pgQueue workqueue.RateLimitingInterface
+	// gangQueue is the queue of SoftGang podgroups waiting for their gang timeout
+	gangQueue workqueue.RateLimitingInterface
+
+	recorder record.EventRecorder
 }
 
 // NewPodgroupController create new Podgroup Controller
@@ -60,11 +71,19 @@ func NewPodgroupController(
 	sharedInformers informers.SharedInformerFactory,
 	schedulerName string,
 ) *Controller {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(klog.Infof)
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+
 	cc := &Controller{
 		kubeClient: kubeClient,
 		vcClient:   vcClient,
 
-		queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		queue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		pgQueue:   workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		gangQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+
+		recorder: eventBroadcaster.NewRecorder(vcscheme.Scheme, v1.EventSource{Component: "vc-controllers"}),
 	}
 
 	cc.podInformer = sharedInformers.Core().V1().Pods()
@@ -89,10 +108,23 @@ func NewPodgroupController(
 				AddFunc: cc.addPod,
 			},
 		})
+	cc.podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueuePodGroupOfPod,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			cc.enqueuePodGroupOfPod(newObj)
+		},
+		DeleteFunc: cc.enqueuePodGroupOfPod,
+	})
 
 	cc.pgInformer = informerfactory.NewSharedInformerFactory(cc.vcClient, 0).Scheduling().V1alpha2().PodGroups()
 	cc.pgLister = cc.pgInformer.Lister()
 	cc.pgSynced = cc.pgInformer.Informer().HasSynced
+	cc.pgInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueuePodGroup,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			cc.enqueuePodGroup(newObj)
+		},
+	})
 
 	return cc
 }
@@ -105,6 +137,8 @@ func (cc *Controller) Run(stopCh <-chan struct{}) {
 	cache.WaitForCacheSync(stopCh, cc.podSynced, cc.pgSynced)
 
 	go wait.Until(cc.worker, 0, stopCh)
+	go wait.Until(cc.pgWorker, 0, stopCh)
+	go wait.Until(cc.gangWorker, 0, stopCh)
 
 	klog.Infof("PodgroupController is running ...... ")
 }
@@ -142,3 +176,59 @@ func (cc *Controller) processNextReq() bool {
 
 	return true
 }
+
+func (cc *Controller) pgWorker() {
+	for cc.processNextPodGroup() {
+	}
+}
+
+func (cc *Controller) processNextPodGroup() bool {
+	obj, shutdown := cc.pgQueue.Get()
+	if shutdown {
+		klog.Errorf("Fail to pop item from podgroup queue")
+		return false
+	}
+
+	key := obj.(string)
+	defer cc.pgQueue.Done(key)
+
+	if err := cc.syncMinResources(key); err != nil {
+		klog.Errorf("Failed to compute minResources of PodGroup <%s>: %v", key, err)
+		cc.pgQueue.AddRateLimited(key)
+		return true
+	}
+
+	cc.pgQueue.Forget(key)
+
+	return true
+}
+
+func (cc *Controller) gangWorker() {
+	for cc.processNextSoftGang() {
+	}
+}
+
+func (cc *Controller) processNextSoftGang() bool {
+	obj, shutdown := cc.gangQueue.Get()
+	if shutdown {
+		klog.Errorf("Fail to pop item from gang queue")
+		return false
+	}
+
+	key := obj.(string)
+	defer cc.gangQueue.Done(key)
+
+	wait, err := cc.syncSoftGang(key, time.Now())
+	if err != nil {
+		klog.Errorf("Failed to check gang timeout of PodGroup <%s>: %v", key, err)
+		cc.gangQueue.AddRateLimited(key)
+		return true
+	}
+
+	cc.gangQueue.Forget(key)
+	if wait > 0 {
+		cc.gangQueue.AddAfter(key, wait)
+	}
+
+	return true
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package podgroup
 
 import (
+	"fmt"
+	"sort"
+	"time"
+
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/apis/helpers"
@@ -87,6 +94,7 @@ func (cc *Controller) createNormalPodPGIfNotExist(pod *v1.Pod) error {
 			Spec: scheduling.PodGroupSpec{
 				MinMember:         1,
 				PriorityClassName: pod.Spec.PriorityClassName,
+				Queue:             pod.Annotations[scheduling.QueueNameAnnotationKey],
 			},
 		}
 
@@ -118,3 +126,212 @@ func newPGOwnerReferences(pod *v1.Pod) []metav1.OwnerReference {
 		UID:        pod.UID,
 	}}
 }
+
+// enqueuePodGroupOfPod enqueues the podgroup of the pod, so that its minResources is recomputed.
+func (cc *Controller) enqueuePodGroupOfPod(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		klog.Errorf("Failed to convert %v to v1.Pod", obj)
+		return
+	}
+
+	pgName := pod.Annotations[scheduling.GroupNameAnnotationKey]
+	if len(pgName) == 0 {
+		return
+	}
+
+	cc.pgQueue.Add(pod.Namespace + "/" + pgName)
+}
+
+func (cc *Controller) enqueuePodGroup(obj interface{}) {
+	pg, ok := obj.(*scheduling.PodGroup)
+	if !ok {
+		klog.Errorf("Failed to convert %v to PodGroup", obj)
+		return
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(pg)
+	if err != nil {
+		klog.Errorf("Failed to get key of PodGroup <%s/%s>: %v", pg.Namespace, pg.Name, err)
+		return
+	}
+
+	if autoMinResources(pg) {
+		cc.pgQueue.Add(key)
+	}
+	if softGang(pg) {
+		cc.gangQueue.Add(key)
+	}
+}
+
+// autoMinResources returns whether minResources of the podgroup is computed by the controller.
+func autoMinResources(pg *scheduling.PodGroup) bool {
+	switch pg.Annotations[scheduling.AutoMinResourcesAnnotationKey] {
+	case "false":
+		return false
+	case "true":
+		return true
+	}
+
+	return pg.Spec.MinResources == nil
+}
+
+// syncMinResources sets minResources of the podgroup to the sum of the requests of its
+// minMember smallest member pods, once minMember pods are created.
+func (cc *Controller) syncMinResources(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	pg, err := cc.pgLister.PodGroups(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !autoMinResources(pg) {
+		return nil
+	}
+
+	pods, err := cc.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	var requests []v1.ResourceList
+	for _, pod := range pods {
+		if pod.Annotations[scheduling.GroupNameAnnotationKey] != name || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		requests = append(requests, helpers.GetPodRequests(&pod.Spec))
+	}
+
+	// Wait for the member pods, the podgroup would be admitted with the requests of a part of
+	// its gang otherwise.
+	if len(requests) == 0 || int32(len(requests)) < pg.Spec.MinMember {
+		klog.V(4).Infof("Wait for %d member pods of PodGroup <%s> to compute minResources, %d created.",
+			pg.Spec.MinMember, key, len(requests))
+		return nil
+	}
+
+	minResources := minMemberResources(requests, pg.Spec.MinMember)
+	if pg.Spec.MinResources != nil && equality.Semantic.DeepEqual(*pg.Spec.MinResources, minResources) {
+		return nil
+	}
+
+	newPG := pg.DeepCopy()
+	newPG.Spec.MinResources = &minResources
+	if newPG.Annotations == nil {
+		newPG.Annotations = map[string]string{}
+	}
+	newPG.Annotations[scheduling.AutoMinResourcesAnnotationKey] = "true"
+	if _, err := cc.vcClient.SchedulingV1alpha2().PodGroups(namespace).Update(newPG); err != nil {
+		klog.Errorf("Failed to update minResources of PodGroup <%s>: %v", key, err)
+		return err
+	}
+
+	klog.V(3).Infof("MinResources of PodGroup <%s> is set to <%v>.", key, minResources)
+
+	return nil
+}
+
+// minMemberResources returns the sum of the minMember smallest requests; the requests are
+// ordered by cpu, then by memory.
+func minMemberResources(requests []v1.ResourceList, minMember int32) v1.ResourceList {
+	sort.SliceStable(requests, func(i, j int) bool {
+		li, lj := requests[i], requests[j]
+		if c := li.Cpu().Cmp(*lj.Cpu()); c != 0 {
+			return c < 0
+		}
+		return li.Memory().Cmp(*lj.Memory()) < 0
+	})
+
+	minResources := v1.ResourceList{}
+	for i, request := range requests {
+		if int32(i) >= minMember {
+			break
+		}
+		for name, quantity := range request {
+			total := minResources[name]
+			total.Add(quantity)
+			minResources[name] = total
+		}
+	}
+
+	return minResources
+}
+
+// softGang returns whether the podgroup falls back to best-effort scheduling after its gang timeout.
+func softGang(pg *scheduling.PodGroup) bool {
+	return pg.Spec.SchedulingPolicy == scheduling.SoftGangSchedulingPolicy && pg.Spec.GangTimeoutSeconds != nil
+}
+
+// gangDowngraded returns whether the podgroup was already downgraded to best-effort scheduling.
+func gangDowngraded(pg *scheduling.PodGroup) bool {
+	for _, c := range pg.Status.Conditions {
+		if c.Type == scheduling.PodGroupGangDowngradedType {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// syncSoftGang downgrades the SoftGang podgroup to best-effort scheduling if it is not
+// scheduled as a gang within its gang timeout; it returns how long to wait for the timeout.
+func (cc *Controller) syncSoftGang(key string, now time.Time) (time.Duration, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	pg, err := cc.pgLister.PodGroups(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if !softGang(pg) || gangDowngraded(pg) {
+		return 0, nil
+	}
+
+	// The gang was scheduled in time.
+	switch pg.Status.Phase {
+	case scheduling.PodGroupRunning, scheduling.PodGroupCompleted:
+		return 0, nil
+	}
+
+	timeout := time.Duration(*pg.Spec.GangTimeoutSeconds) * time.Second
+	if deadline := pg.CreationTimestamp.Add(timeout); now.Before(deadline) {
+		return deadline.Sub(now), nil
+	}
+
+	msg := fmt.Sprintf("%d of minMember %d pods were not scheduled within %v, the pods are scheduled best-effort",
+		pg.Spec.MinMember-pg.Status.Running, pg.Spec.MinMember, timeout)
+	newPG := pg.DeepCopy()
+	newPG.Status.Conditions = append(newPG.Status.Conditions, scheduling.PodGroupCondition{
+		Type:               scheduling.PodGroupGangDowngradedType,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             scheduling.GangTimeoutReason,
+		Message:            msg,
+	})
+	if _, err := cc.vcClient.SchedulingV1alpha2().PodGroups(namespace).Update(newPG); err != nil {
+		klog.Errorf("Failed to downgrade PodGroup <%s>: %v", key, err)
+		return 0, err
+	}
+
+	cc.recorder.Event(pg, v1.EventTypeWarning, string(scheduling.PodGroupGangDowngradedType), msg)
+	klog.V(3).Infof("PodGroup <%s> is downgraded: %s.", key, msg)
+
+	return 0, nil
+}
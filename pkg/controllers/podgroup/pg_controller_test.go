@@ -19,12 +19,18 @@ package podgroup
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	kubeclient "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 
 	scheduling "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
@@ -132,6 +138,44 @@ func TestAddPodGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "AddPodGroup: pod has queue annotation",
+			pod: &v1.Pod{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Pod",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "pod1",
+					Namespace:   namespace,
+					UID:         types.UID("7a09885b-b753-4924-9fba-77c0836bac20"),
+					Annotations: map[string]string{scheduling.QueueNameAnnotationKey: "preemptible"},
+				},
+			},
+			expectedPodGroup: &scheduling.PodGroup{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "scheduling.sigs.dev/v1alpha2",
+					Kind:       "PodGroup",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "podgroup-7a09885b-b753-4924-9fba-77c0836bac20",
+					Namespace: namespace,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "v1",
+							Kind:       "Pod",
+							Name:       "pod1",
+							UID:        "7a09885b-b753-4924-9fba-77c0836bac20",
+							Controller: &isController,
+						},
+					},
+				},
+				Spec: scheduling.PodGroupSpec{
+					MinMember: 1,
+					Queue:     "preemptible",
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -167,5 +211,235 @@ func TestAddPodGroup(t *testing.T) {
 			t.Errorf("Case %s failed, expect %v, got %v", testCase.name,
 				testCase.expectedPodGroup.Spec.PriorityClassName, pod.Spec.PriorityClassName)
 		}
+
+		if testCase.expectedPodGroup.Spec.Queue != pg.Spec.Queue {
+			t.Errorf("Case %s failed, expect queue %v, got %v", testCase.name,
+				testCase.expectedPodGroup.Spec.Queue, pg.Spec.Queue)
+		}
+	}
+}
+
+func TestSyncMinResources(t *testing.T) {
+	namespace := "test"
+	newPod := func(name, pgName, cpu, memory string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{scheduling.GroupNameAnnotationKey: pgName},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(cpu),
+							v1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+	}
+	resources := func(cpu, memory string) *v1.ResourceList {
+		return &v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}
+	}
+
+	pods := []*v1.Pod{
+		newPod("p1", "pg1", "2", "2Gi", v1.PodRunning),
+		newPod("p2", "pg1", "1", "1Gi", v1.PodPending),
+		newPod("p3", "pg1", "1", "2Gi", v1.PodPending),
+		newPod("p4", "pg1", "500m", "1Gi", v1.PodSucceeded),
+		newPod("p5", "pg2", "100m", "100Mi", v1.PodPending),
+	}
+
+	testCases := []struct {
+		name         string
+		annotations  map[string]string
+		minMember    int32
+		minResources *v1.ResourceList
+		expected     *v1.ResourceList
+		expectUpdate bool
+	}{
+		{
+			name:         "minResources is not set",
+			expected:     resources("2", "3Gi"),
+			expectUpdate: true,
+		},
+		{
+			name:         "minResources is set by user",
+			minResources: resources("1", "1Gi"),
+			expected:     resources("1", "1Gi"),
+		},
+		{
+			name:         "minResources computed before is recomputed",
+			annotations:  map[string]string{scheduling.AutoMinResourcesAnnotationKey: "true"},
+			minResources: resources("1", "1Gi"),
+			expected:     resources("2", "3Gi"),
+			expectUpdate: true,
+		},
+		{
+			name:         "minResources is not changed",
+			annotations:  map[string]string{scheduling.AutoMinResourcesAnnotationKey: "true"},
+			minResources: resources("2", "3Gi"),
+			expected:     resources("2", "3Gi"),
+		},
+		{
+			name:        "auto computation is disabled",
+			annotations: map[string]string{scheduling.AutoMinResourcesAnnotationKey: "false"},
+		},
+		{
+			name:      "less than minMember pods are created",
+			minMember: 4,
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+		for _, pod := range pods {
+			c.podInformer.Informer().GetIndexer().Add(pod)
+		}
+		if testCase.minMember == 0 {
+			testCase.minMember = 2
+		}
+
+		pg := &scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pg1",
+				Namespace:   namespace,
+				Annotations: testCase.annotations,
+			},
+			Spec: scheduling.PodGroupSpec{
+				MinMember:    testCase.minMember,
+				MinResources: testCase.minResources,
+			},
+		}
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+		c.vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(pg)
+
+		if err := c.syncMinResources(namespace + "/pg1"); err != nil {
+			t.Errorf("Case %s failed for %v", testCase.name, err)
+		}
+
+		got, _ := c.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get(pg.Name, metav1.GetOptions{})
+		if !equality.Semantic.DeepEqual(got.Spec.MinResources, testCase.expected) {
+			t.Errorf("Case %s failed, expect minResources %v, got %v", testCase.name, testCase.expected, got.Spec.MinResources)
+		}
+
+		updated := false
+		for _, action := range c.vcClient.(*vcclient.Clientset).Actions() {
+			if action.GetVerb() == "update" {
+				updated = true
+			}
+		}
+		if updated != testCase.expectUpdate {
+			t.Errorf("Case %s failed, expect update %v, got %v", testCase.name, testCase.expectUpdate, updated)
+		}
+		if updated && got.Annotations[scheduling.AutoMinResourcesAnnotationKey] != "true" {
+			t.Errorf("Case %s failed, expect annotation %s set", testCase.name, scheduling.AutoMinResourcesAnnotationKey)
+		}
+	}
+}
+
+func TestSyncSoftGang(t *testing.T) {
+	namespace := "test"
+	created := time.Now().Add(-time.Minute)
+	timeout := func(seconds int32) *int32 {
+		return &seconds
+	}
+
+	testCases := []struct {
+		name            string
+		policy          scheduling.PodGroupSchedulingPolicy
+		timeout         *int32
+		phase           scheduling.PodGroupPhase
+		conditions      []scheduling.PodGroupCondition
+		expectWait      bool
+		expectDowngrade bool
+	}{
+		{
+			name:    "gang podgroup is not downgraded",
+			policy:  scheduling.GangSchedulingPolicy,
+			timeout: timeout(30),
+			phase:   scheduling.PodGroupPending,
+		},
+		{
+			name:       "timeout is not reached",
+			policy:     scheduling.SoftGangSchedulingPolicy,
+			timeout:    timeout(120),
+			phase:      scheduling.PodGroupPending,
+			expectWait: true,
+		},
+		{
+			name:            "timeout is reached",
+			policy:          scheduling.SoftGangSchedulingPolicy,
+			timeout:         timeout(30),
+			phase:           scheduling.PodGroupPending,
+			expectDowngrade: true,
+		},
+		{
+			name:    "gang is scheduled in time",
+			policy:  scheduling.SoftGangSchedulingPolicy,
+			timeout: timeout(30),
+			phase:   scheduling.PodGroupRunning,
+		},
+		{
+			name:    "podgroup is downgraded before",
+			policy:  scheduling.SoftGangSchedulingPolicy,
+			timeout: timeout(30),
+			phase:   scheduling.PodGroupPending,
+			conditions: []scheduling.PodGroupCondition{{
+				Type:   scheduling.PodGroupGangDowngradedType,
+				Status: v1.ConditionTrue,
+			}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+		// PodGroup CRD has no status subresource, so apiserver rejects status updates.
+		c.vcClient.(*vcclient.Clientset).PrependReactor("update", "podgroups",
+			func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "status" {
+					return false, nil, nil
+				}
+				return true, nil, apierrors.NewNotFound(scheduling.Resource("podgroups"), "pg1")
+			})
+
+		pg := &scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pg1",
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: scheduling.PodGroupSpec{
+				MinMember:          3,
+				SchedulingPolicy:   testCase.policy,
+				GangTimeoutSeconds: testCase.timeout,
+			},
+			Status: scheduling.PodGroupStatus{
+				Phase:      testCase.phase,
+				Conditions: testCase.conditions,
+			},
+		}
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+		c.vcClient.SchedulingV1alpha2().PodGroups(namespace).Create(pg)
+
+		wait, err := c.syncSoftGang(namespace+"/pg1", time.Now())
+		if err != nil {
+			t.Errorf("Case %s failed for %v", testCase.name, err)
+		}
+		if (wait > 0) != testCase.expectWait {
+			t.Errorf("Case %s failed, expect wait %v, got %v", testCase.name, testCase.expectWait, wait)
+		}
+
+		got, _ := c.vcClient.SchedulingV1alpha2().PodGroups(namespace).Get(pg.Name, metav1.GetOptions{})
+		downgraded := gangDowngraded(got) && len(got.Status.Conditions) > len(testCase.conditions)
+		if downgraded != testCase.expectDowngrade {
+			t.Errorf("Case %s failed, expect downgrade %v, got %v", testCase.name, testCase.expectDowngrade, downgraded)
+		}
 	}
 }
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+	"time"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+)
+
+// backlogAgePeriod is the period to refresh the age of the oldest command, so that the age keeps
+// growing while the command workers are stalled and do not refresh it themselves.
+const backlogAgePeriod = 10 * time.Second
+
+// commandBacklog tracks the creation time of the commands in a workqueue until they are handled
+// or dropped, retries included.
+type commandBacklog struct {
+	sync.Mutex
+	created map[string]time.Time
+}
+
+func newCommandBacklog() *commandBacklog {
+	return &commandBacklog{created: map[string]time.Time{}}
+}
+
+func commandKey(cmd *busv1alpha1.Command) string {
+	return cmd.Namespace + "/" + cmd.Name
+}
+
+// add tracks the command entering the workqueue, the backlog may be nil.
+func (b *commandBacklog) add(cmd *busv1alpha1.Command) {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	b.created[commandKey(cmd)] = cmd.CreationTimestamp.Time
+}
+
+// remove stops tracking the command leaving the workqueue, the backlog may be nil.
+func (b *commandBacklog) remove(cmd *busv1alpha1.Command) {
+	if b == nil || cmd == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	delete(b.created, commandKey(cmd))
+}
+
+// oldestAge returns the age of the oldest command in the backlog, 0 if the backlog is empty.
+func (b *commandBacklog) oldestAge(now time.Time) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+
+	var age time.Duration
+	for _, created := range b.created {
+		if d := now.Sub(created); d > age {
+			age = d
+		}
+	}
+
+	return age
+}
+
+// updateCommandBacklogAge exports the age of the oldest unprocessed command.
+func (c *Controller) updateCommandBacklogAge() {
+	updateCommandOldestAge(c.commandAges.oldestAge(time.Now()))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func newBacklogCommand(name string, created time.Time) *busv1alpha1.Command {
+	return &busv1alpha1.Command{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		TargetObject: &metav1.OwnerReference{
+			APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+			Kind:       "Queue",
+			Name:       "q1",
+		},
+		Action: string(schedulingv1alpha2.CloseQueueAction),
+	}
+}
+
+func TestCommandBacklog(t *testing.T) {
+	now := time.Now()
+	backlog := newCommandBacklog()
+	if age := backlog.oldestAge(now); age != 0 {
+		t.Errorf("expected no age of empty backlog, got %v", age)
+	}
+
+	oldest := newBacklogCommand("cmd1", now.Add(-time.Minute))
+	backlog.add(oldest)
+	backlog.add(newBacklogCommand("cmd2", now.Add(-time.Second)))
+	if age := backlog.oldestAge(now); age != time.Minute {
+		t.Errorf("expected age of oldest command 1m, got %v", age)
+	}
+
+	backlog.remove(oldest)
+	if age := backlog.oldestAge(now); age != time.Second {
+		t.Errorf("expected age of oldest command 1s after it is removed, got %v", age)
+	}
+
+	// Routes without backlog ignore the commands.
+	var none *commandBacklog
+	none.add(oldest)
+	none.remove(oldest)
+}
+
+func TestProcessNextCommandBacklog(t *testing.T) {
+	c := newFakeController()
+	failures := 1
+	c.syncCommandHandler = func(ctx context.Context, cmd *busv1alpha1.Command) error {
+		if failures > 0 {
+			failures--
+			return fmt.Errorf("transient error")
+		}
+		return nil
+	}
+
+	cmd := newBacklogCommand("cmd1", time.Now().Add(-time.Minute))
+	c.addCommand(cmd)
+	route := c.routeCommand(cmd)
+
+	// The command stays in the backlog while it is retried.
+	c.processNextCommand(context.TODO(), route)
+	if age := c.commandAges.oldestAge(time.Now()); age < time.Minute {
+		t.Errorf("expected command retried in backlog, got age %v", age)
+	}
+
+	// The rate limited command is added back by the workqueue after its delay.
+	for c.commandQueue.Len() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.processNextCommand(context.TODO(), route)
+	if age := c.commandAges.oldestAge(time.Now()); age != 0 {
+		t.Errorf("expected empty backlog once the command is handled, got age %v", age)
+	}
+}
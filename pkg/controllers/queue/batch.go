@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+)
+
+// batchedCommands tracks the commands coalesced into an applied batch until their own entries leave the
+// workqueue, so that a stale entry never applies its superseded action again. The value is whether the
+// command is deleted already.
+type batchedCommands struct {
+	sync.Mutex
+	deleted map[string]bool
+}
+
+func newBatchedCommands() *batchedCommands {
+	return &batchedCommands{deleted: map[string]bool{}}
+}
+
+// add tracks the commands of an applied batch.
+func (b *batchedCommands) add(commands []*busv1alpha1.Command, deleted bool) {
+	b.Lock()
+	defer b.Unlock()
+	for _, cmd := range commands {
+		b.deleted[commandKey(cmd)] = deleted
+	}
+}
+
+// get returns whether the command is deleted and whether it was coalesced into an applied batch.
+func (b *batchedCommands) get(cmd *busv1alpha1.Command) (deleted bool, batched bool) {
+	b.Lock()
+	defer b.Unlock()
+	deleted, batched = b.deleted[commandKey(cmd)]
+	return deleted, batched
+}
+
+// remove stops tracking the command whose entry leaves the workqueue.
+func (b *batchedCommands) remove(cmd *busv1alpha1.Command) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.deleted, commandKey(cmd))
+}
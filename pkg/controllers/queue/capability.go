@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"math"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+// resolveCapability returns the capability of the queue resolved from spec.capabilityPercent against
+// the allocatable resources of the cluster, with the resources of spec.capability taking precedence;
+// it returns nil if spec.capabilityPercent is not set.
+func resolveCapability(queue *schedulingv1alpha2.Queue, allocatable v1.ResourceList) v1.ResourceList {
+	if len(queue.Spec.CapabilityPercent) == 0 {
+		return nil
+	}
+
+	capability := v1.ResourceList{}
+	for name, percent := range queue.Spec.CapabilityPercent {
+		capability[name] = percentOf(name, allocatable[name], percent)
+	}
+	for name, quantity := range queue.Spec.Capability {
+		capability[name] = quantity
+	}
+
+	return capability
+}
+
+// percentOf returns the percent of the quantity in its format, CPU is rounded down to millicores and
+// the other resources to units, e.g. bytes of memory, rather than fractions of them. The quantity is
+// scaled in units if it is too large to be scaled in millis without overflow.
+func percentOf(name v1.ResourceName, quantity resource.Quantity, percent int32) resource.Quantity {
+	if name == v1.ResourceCPU {
+		if milli := quantity.MilliValue(); milli <= math.MaxInt64/100 {
+			return *resource.NewMilliQuantity(milli*int64(percent)/100, quantity.Format)
+		}
+	}
+
+	value := quantity.Value()
+	if value <= math.MaxInt64/100 {
+		return *resource.NewQuantity(value*int64(percent)/100, quantity.Format)
+	}
+	return *resource.NewQuantity(value/100*int64(percent), quantity.Format)
+}
+
+// clusterAllocatable returns the sum of the allocatable resources of all nodes.
+func (c *Controller) clusterAllocatable() (v1.ResourceList, error) {
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list nodes failed for %v", err)
+	}
+
+	var allocatable v1.ResourceList
+	for _, node := range nodes {
+		allocatable = helpers.AddResourceList(allocatable, node.Status.Allocatable)
+	}
+
+	return allocatable, nil
+}
+
+// enqueueRelativeQueues enqueues the queues whose capability is relative to the cluster, so that
+// their capability is resolved again as the allocatable resources of the cluster change.
+func (c *Controller) enqueueRelativeQueues() {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues: %v", err)
+		return
+	}
+
+	for _, queue := range queues {
+		if len(queue.Spec.CapabilityPercent) > 0 {
+			c.enqueue(queue.Name, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+		}
+	}
+}
+
+func (c *Controller) addNode(obj interface{}) {
+	c.enqueueRelativeQueues()
+}
+
+func (c *Controller) updateNode(old, new interface{}) {
+	oldNode, ok := old.(*v1.Node)
+	if !ok {
+		klog.Errorf("Can not covert old object %v to nodes.", old)
+		return
+	}
+
+	newNode, ok := new.(*v1.Node)
+	if !ok {
+		klog.Errorf("Can not covert new object %v to nodes.", new)
+		return
+	}
+
+	// Nodes are updated by their heartbeats frequently, which do not change the allocatable resources.
+	if equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) {
+		return
+	}
+
+	c.enqueueRelativeQueues()
+}
+
+func (c *Controller) deleteNode(obj interface{}) {
+	if _, ok := obj.(*v1.Node); !ok {
+		if _, ok := obj.(cache.DeletedFinalStateUnknown); !ok {
+			klog.Errorf("Couldn't get object from tombstone %#v.", obj)
+			return
+		}
+	}
+
+	c.enqueueRelativeQueues()
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+func TestResolveCapability(t *testing.T) {
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("10"),
+		v1.ResourceMemory: resource.MustParse("64Gi"),
+		"nvidia.com/gpu":  resource.MustParse("8"),
+	}
+
+	testCases := []struct {
+		name       string
+		spec       schedulingv1alpha2.QueueSpec
+		expected   v1.ResourceList
+		expectNone bool
+	}{
+		{
+			name: "absolute capability only",
+			spec: schedulingv1alpha2.QueueSpec{
+				Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+			},
+			expectNone: true,
+		},
+		{
+			name: "percent of cluster",
+			spec: schedulingv1alpha2.QueueSpec{
+				CapabilityPercent: map[v1.ResourceName]int32{
+					v1.ResourceCPU:    25,
+					v1.ResourceMemory: 50,
+					"nvidia.com/gpu":  100,
+				},
+			},
+			expected: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2500m"),
+				v1.ResourceMemory: resource.MustParse("32Gi"),
+				"nvidia.com/gpu":  resource.MustParse("8"),
+			},
+		},
+		{
+			name: "absolute capability overrides percent",
+			spec: schedulingv1alpha2.QueueSpec{
+				Capability: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				CapabilityPercent: map[v1.ResourceName]int32{
+					v1.ResourceCPU:   50,
+					"nvidia.com/gpu": 50,
+				},
+			},
+			expected: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+				"nvidia.com/gpu":  resource.MustParse("4"),
+			},
+		},
+		{
+			name: "resource not allocatable in cluster",
+			spec: schedulingv1alpha2.QueueSpec{
+				CapabilityPercent: map[v1.ResourceName]int32{"example.com/fpga": 50},
+			},
+			expected: v1.ResourceList{"example.com/fpga": resource.MustParse("0")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		queue := &schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       testCase.spec,
+		}
+		capability := resolveCapability(queue, allocatable)
+		if testCase.expectNone {
+			if capability != nil {
+				t.Errorf("case %s: expected no capability, got %v", testCase.name, capability)
+			}
+			continue
+		}
+
+		if len(capability) != len(testCase.expected) {
+			t.Errorf("case %s: expected capability %v, got %v", testCase.name, testCase.expected, capability)
+			continue
+		}
+		for name, quantity := range testCase.expected {
+			if got, found := capability[name]; !found || got.Cmp(quantity) != 0 {
+				t.Errorf("case %s: expected %s capability %s, got %s", testCase.name, name, quantity.String(), got.String())
+			}
+		}
+	}
+}
+
+func TestPercentOf(t *testing.T) {
+	testCases := []struct {
+		name     v1.ResourceName
+		quantity string
+		percent  int32
+		expected string
+	}{
+		{name: v1.ResourceCPU, quantity: "1", percent: 50, expected: "500m"},
+		{name: v1.ResourceCPU, quantity: "1500m", percent: 33, expected: "495m"},
+		{name: v1.ResourceMemory, quantity: "2G", percent: 50, expected: "1G"},
+		// Memory is rounded down to bytes rather than millibytes.
+		{name: v1.ResourceMemory, quantity: "10Gi", percent: 33, expected: "3543348019"},
+		// Memory too large to be scaled in millibytes.
+		{name: v1.ResourceMemory, quantity: "100Pi", percent: 50, expected: "50Pi"},
+		{name: "nvidia.com/gpu", quantity: "1", percent: 50, expected: "0"},
+	}
+
+	for _, testCase := range testCases {
+		got := percentOf(testCase.name, resource.MustParse(testCase.quantity), testCase.percent)
+		if expected := resource.MustParse(testCase.expected); got.Cmp(expected) != 0 {
+			t.Errorf("expected %d%% of %s %s to be %s, got %s", testCase.percent, testCase.name,
+				testCase.quantity, testCase.expected, got.String())
+		}
+	}
+}
+
+func TestSyncQueueCapabilityPercent(t *testing.T) {
+	newNode := func(name, cpu string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+			},
+		}
+	}
+
+	c := newFakeController()
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec: schedulingv1alpha2.QueueSpec{
+			CapabilityPercent: map[v1.ResourceName]int32{v1.ResourceCPU: 50},
+		},
+	}
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+	c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q2"}})
+	c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+	node1 := newNode("n1", "4")
+	c.nodeInformer.Informer().GetIndexer().Add(node1)
+	c.nodeInformer.Informer().GetIndexer().Add(newNode("n2", "8"))
+
+	if err := c.syncQueue(context.TODO(), queue, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if cpu := item.Status.Capability[v1.ResourceCPU]; cpu.Cmp(resource.MustParse("6")) != 0 {
+		t.Errorf("expected cpu capability 6, got %s", cpu.String())
+	}
+
+	// Heartbeats of nodes do not resolve the capability again.
+	c.updateNode(node1, node1.DeepCopy())
+	if c.queue.Len() != 0 {
+		t.Errorf("expected no queue enqueued for unchanged node, got %d", c.queue.Len())
+	}
+
+	// Only the queues relative to the cluster are enqueued as the allocatable resources change.
+	resized := newNode("n1", "8")
+	c.nodeInformer.Informer().GetIndexer().Update(resized)
+	c.updateNode(node1, resized)
+	if c.queue.Len() != 1 {
+		t.Fatalf("expected one queue enqueued, got %d", c.queue.Len())
+	}
+	obj, _ := c.queue.Get()
+	if name := obj.(*schedulingv1alpha2.QueueRequest).Name; name != queue.Name {
+		t.Errorf("expected queue %s enqueued, got %s", queue.Name, name)
+	}
+	c.queue.Done(obj)
+
+	if err := c.syncQueue(context.TODO(), item, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	item, _ = c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if cpu := item.Status.Capability[v1.ResourceCPU]; cpu.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("expected cpu capability 8, got %s", cpu.String())
+	}
+	if capability := helpers.EffectiveCapability(item); len(capability) != 1 {
+		t.Errorf("expected effective capability resolved from percent, got %v", capability)
+	}
+}
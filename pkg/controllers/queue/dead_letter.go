@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+// recordDeadLetter records the dropped request to the dead-letter ConfigMap, which is created if
+// it does not exist; the request is only logged if the dead-letter ConfigMap is not set.
+func (c *Controller) recordDeadLetter(req *schedulingv1alpha2.QueueRequest, reqErr error) {
+	if c.deadLetterConfigMap == "" || req == nil {
+		return
+	}
+
+	data, err := json.Marshal(&helpers.DeadLetter{
+		Name:   req.Name,
+		Action: req.Action,
+		Event:  req.Event,
+		Error:  reqErr.Error(),
+		Time:   metav1.NewTime(time.Now()),
+	})
+	if err != nil {
+		klog.Errorf("Failed to marshal dropped request of queue %s: %v", req.Name, err)
+		return
+	}
+
+	// The key is validated by the options, the error can not occur.
+	namespace, name, _ := cache.SplitMetaNamespaceKey(c.deadLetterConfigMap)
+	configMaps := c.kubeClient.CoreV1().ConfigMaps(namespace)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = configMaps.Create(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Data:       map[string]string{req.Name: string(data)},
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[req.Name] = string(data)
+		_, err = configMaps.Update(cm)
+		return err
+	})
+	if err != nil {
+		klog.Errorf("Failed to record dropped request of queue %s to ConfigMap %s: %v",
+			req.Name, c.deadLetterConfigMap, err)
+		return
+	}
+
+	klog.V(4).Infof("Recorded dropped request of queue %s to ConfigMap %s.", req.Name, c.deadLetterConfigMap)
+}
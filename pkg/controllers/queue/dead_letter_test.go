@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+func TestRecordDeadLetter(t *testing.T) {
+	c := newFakeController()
+	c.queueMaxRetries = 1
+	c.deadLetterConfigMap = "volcano-system/dead-letters"
+
+	retried := &schedulingv1alpha2.QueueRequest{
+		Name:   "q1",
+		Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+		Action: schedulingv1alpha2.SyncQueueAction,
+	}
+	// The first failure is retried, the second one drops the request.
+	c.handleQueueErr(fmt.Errorf("failed to sync queue"), retried)
+	if _, err := c.kubeClient.CoreV1().ConfigMaps("volcano-system").Get("dead-letters", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected no dead letter before the request is dropped, got %v", err)
+	}
+	c.handleQueueErr(fmt.Errorf("failed to sync queue"), retried)
+
+	permanent := &schedulingv1alpha2.QueueRequest{
+		Name:   "q2",
+		Event:  schedulingv1alpha2.QueueCommandIssuedEvent,
+		Action: schedulingv1alpha2.CloseQueueAction,
+	}
+	c.handleQueueErr(&ErrInvalidState{Queue: "q2", State: "Pausing"}, permanent)
+
+	cm, err := c.kubeClient.CoreV1().ConfigMaps("volcano-system").Get("dead-letters", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected dead-letter ConfigMap, got %v", err)
+	}
+	for _, req := range []*schedulingv1alpha2.QueueRequest{retried, permanent} {
+		letter, err := helpers.GetDeadLetter(cm, req.Name)
+		if err != nil {
+			t.Fatalf("expected dead letter of queue %s, got %v", req.Name, err)
+		}
+		if letter.Name != req.Name || letter.Action != req.Action || letter.Event != req.Event ||
+			letter.Error == "" || letter.Time.IsZero() {
+			t.Errorf("expected dead letter of request %v, got %v", req, letter)
+		}
+	}
+
+	if _, err := helpers.GetDeadLetter(cm, "q3"); err == nil {
+		t.Errorf("expected error of queue without dead letter")
+	}
+}
+
+func TestRecordDeadLetterDisabled(t *testing.T) {
+	c := newFakeController()
+	c.queueMaxRetries = 1
+
+	req := &schedulingv1alpha2.QueueRequest{
+		Name:   "q1",
+		Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+		Action: schedulingv1alpha2.SyncQueueAction,
+	}
+	c.handleQueueErr(fmt.Errorf("failed to sync queue"), req)
+	c.handleQueueErr(fmt.Errorf("failed to sync queue"), req)
+
+	configMaps, err := c.kubeClient.CoreV1().ConfigMaps("").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list ConfigMaps: %v", err)
+	}
+	if len(configMaps.Items) != 0 {
+		t.Errorf("expected no dead-letter ConfigMap, got %v", configMaps.Items)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// errPaused is returned for a command while the reconciliation of queues is paused, the command is
+// kept and handled once resumed.
+var errPaused = fmt.Errorf("queue reconciliation is paused")
+
+// ErrQueueNotFound is returned when the queue of a request does not exist, e.g. it has been deleted.
+type ErrQueueNotFound struct {
+	Queue string
+}
+
+func (e *ErrQueueNotFound) Error() string {
+	return fmt.Sprintf("queue %s is not found", e.Queue)
+}
+
+// ErrInvalidState is returned when the queue is in a state unknown to queue controller.
+type ErrInvalidState struct {
+	Queue string
+	State schedulingv1alpha2.QueueState
+}
+
+func (e *ErrInvalidState) Error() string {
+	return fmt.Sprintf("queue %s state %s is invalid", e.Queue, e.State)
+}
+
+// ErrStateExecution is returned when the action of a request failed in the current state of the queue.
+type ErrStateExecution struct {
+	Queue  string
+	Event  schedulingv1alpha2.QueueEvent
+	Action schedulingv1alpha2.QueueAction
+	// Err is the cause of the failure, e.g. the error of updating the queue.
+	Err error
+}
+
+func (e *ErrStateExecution) Error() string {
+	return fmt.Sprintf("sync queue %s failed for %v, event is %v, action is %s", e.Queue, e.Err, e.Event, e.Action)
+}
+
+// Unwrap returns the cause of the failure, so that it can be inspected by errors.Is and errors.As.
+func (e *ErrStateExecution) Unwrap() error {
+	return e.Err
+}
+
+// isRetriable returns whether the failed request may succeed if it is retried. The errors which are
+// not classified, e.g. the errors of getting the queue from cache, are regarded as transient.
+func isRetriable(err error) bool {
+	switch e := err.(type) {
+	case *ErrQueueNotFound, *ErrInvalidState:
+		return false
+	case *ErrStateExecution:
+		// The request is rejected by apiserver for itself, rather than the state of apiserver.
+		return !apierrors.IsInvalid(e.Err) && !apierrors.IsBadRequest(e.Err) && !apierrors.IsNotFound(e.Err)
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultEventDedupWindow is the default window in which identical events are suppressed.
+	DefaultEventDedupWindow = time.Minute
+
+	// maxEventCacheEntries is the number of entries in the cache above which expired entries are pruned.
+	maxEventCacheEntries = 4096
+)
+
+type eventKey struct {
+	uid       types.UID
+	namespace string
+	name      string
+	eventType string
+	reason    string
+	message   string
+}
+
+type eventEntry struct {
+	firstSeen  time.Time
+	suppressed int
+}
+
+// dedupRecorder wraps an EventRecorder and suppresses the events which are identical
+// (same object, type, reason and message) to one recorded within the window; once the
+// window expires, the next identical event carries the number of suppressed events.
+type dedupRecorder struct {
+	recorder record.EventRecorder
+	window   time.Duration
+	now      func() time.Time
+
+	mutex  sync.Mutex
+	events map[eventKey]*eventEntry
+}
+
+// newDedupRecorder returns recorder itself if window is not positive.
+func newDedupRecorder(recorder record.EventRecorder, window time.Duration) record.EventRecorder {
+	if window <= 0 {
+		return recorder
+	}
+
+	return &dedupRecorder{
+		recorder: recorder,
+		window:   window,
+		now:      time.Now,
+		events:   make(map[eventKey]*eventEntry),
+	}
+}
+
+// filter returns whether the event should be recorded, and the message to record.
+func (r *dedupRecorder) filter(object runtime.Object, eventType, reason, message string) (bool, string) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return true, message
+	}
+
+	key := eventKey{
+		uid:       accessor.GetUID(),
+		namespace: accessor.GetNamespace(),
+		name:      accessor.GetName(),
+		eventType: eventType,
+		reason:    reason,
+		message:   message,
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	if entry, found := r.events[key]; found && now.Sub(entry.firstSeen) < r.window {
+		entry.suppressed++
+		return false, message
+	}
+
+	suppressed := 0
+	if entry, found := r.events[key]; found {
+		suppressed = entry.suppressed
+	}
+
+	if len(r.events) >= maxEventCacheEntries {
+		for k, entry := range r.events {
+			if now.Sub(entry.firstSeen) >= r.window {
+				delete(r.events, k)
+			}
+		}
+	}
+	r.events[key] = &eventEntry{firstSeen: now}
+
+	if suppressed > 0 {
+		message = fmt.Sprintf("%s (%d identical events suppressed in the last %v)", message, suppressed, r.window)
+	}
+
+	return true, message
+}
+
+func (r *dedupRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if ok, msg := r.filter(object, eventType, reason, message); ok {
+		r.recorder.Event(object, eventType, reason, msg)
+	}
+}
+
+func (r *dedupRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *dedupRecorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventType, reason, messageFmt string, args ...interface{}) {
+	if ok, msg := r.filter(object, eventType, reason, fmt.Sprintf(messageFmt, args...)); ok {
+		r.recorder.PastEventf(object, timestamp, eventType, reason, "%s", msg)
+	}
+}
+
+func (r *dedupRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	if ok, msg := r.filter(object, eventType, reason, fmt.Sprintf(messageFmt, args...)); ok {
+		r.recorder.AnnotatedEventf(object, annotations, eventType, reason, "%s", msg)
+	}
+}
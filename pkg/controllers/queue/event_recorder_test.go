@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestDedupRecorder(t *testing.T) {
+	q1 := &schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1", UID: "uid-q1"}}
+	q2 := &schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q2", UID: "uid-q2"}}
+
+	type event struct {
+		queue   *schedulingv1alpha2.Queue
+		reason  string
+		message string
+		// elapsed is the time passed since the first event.
+		elapsed time.Duration
+	}
+
+	failure := func(queue *schedulingv1alpha2.Queue, elapsed time.Duration) event {
+		return event{queue: queue, reason: "SyncQueueFailed", message: "connection refused", elapsed: elapsed}
+	}
+
+	testCases := []struct {
+		Name           string
+		Window         time.Duration
+		Events         []event
+		ExpectedEvents []string
+	}{
+		{
+			Name:   "10 rapid identical failures",
+			Window: time.Minute,
+			Events: func() []event {
+				var events []event
+				for i := 0; i < 10; i++ {
+					events = append(events, failure(q1, time.Duration(i)*time.Millisecond))
+				}
+				return events
+			}(),
+			ExpectedEvents: []string{"Warning SyncQueueFailed connection refused"},
+		},
+		{
+			Name:   "different queues and messages",
+			Window: time.Minute,
+			Events: []event{
+				failure(q1, 0),
+				failure(q2, 0),
+				{queue: q1, reason: "SyncQueueFailed", message: "timeout"},
+			},
+			ExpectedEvents: []string{
+				"Warning SyncQueueFailed connection refused",
+				"Warning SyncQueueFailed connection refused",
+				"Warning SyncQueueFailed timeout",
+			},
+		},
+		{
+			Name:   "window expired",
+			Window: time.Minute,
+			Events: []event{
+				failure(q1, 0),
+				failure(q1, time.Second),
+				failure(q1, 2*time.Second),
+				failure(q1, 2*time.Minute),
+			},
+			ExpectedEvents: []string{
+				"Warning SyncQueueFailed connection refused",
+				"Warning SyncQueueFailed connection refused (2 identical events suppressed in the last 1m0s)",
+			},
+		},
+		{
+			Name:   "dedup disabled",
+			Window: 0,
+			Events: []event{
+				failure(q1, 0),
+				failure(q1, 0),
+			},
+			ExpectedEvents: []string{
+				"Warning SyncQueueFailed connection refused",
+				"Warning SyncQueueFailed connection refused",
+			},
+		},
+	}
+
+	for i, testcase := range testCases {
+		fakeRecorder := record.NewFakeRecorder(100)
+		recorder := newDedupRecorder(fakeRecorder, testcase.Window)
+
+		start := time.Now()
+		var elapsed time.Duration
+		if r, ok := recorder.(*dedupRecorder); ok {
+			r.now = func() time.Time { return start.Add(elapsed) }
+		}
+
+		for _, e := range testcase.Events {
+			elapsed = e.elapsed
+			recorder.Event(e.queue, v1.EventTypeWarning, e.reason, e.message)
+		}
+		close(fakeRecorder.Events)
+
+		var got []string
+		for e := range fakeRecorder.Events {
+			got = append(got, e)
+		}
+
+		if len(got) != len(testcase.ExpectedEvents) {
+			t.Errorf("case %d (%s): expected events %v, got %v", i, testcase.Name, testcase.ExpectedEvents, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != testcase.ExpectedEvents[j] {
+				t.Errorf("case %d (%s): expected event %q, got %q", i, testcase.Name, testcase.ExpectedEvents[j], got[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+)
+
+// jsonEvent is an event written to the stdout sink as a line of JSON.
+type jsonEvent struct {
+	Time time.Time `json:"time"`
+	// Queue is the name of the queue the event is about, empty if it is about another object.
+	Queue     string `json:"queue,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Count     int32  `json:"count,omitempty"`
+}
+
+// startJSONEventSink writes the events of the broadcaster to w, one JSON object per line, in addition
+// to the other sinks of the broadcaster, e.g. where the events are not kept long enough by apiserver.
+func startJSONEventSink(broadcaster record.EventBroadcaster, w io.Writer) {
+	encoder := json.NewEncoder(w)
+	broadcaster.StartEventWatcher(func(event *v1.Event) {
+		if err := encoder.Encode(toJSONEvent(event)); err != nil {
+			klog.Errorf("Failed to write event %s/%s: %v", event.Namespace, event.Name, err)
+		}
+	})
+}
+
+func toJSONEvent(event *v1.Event) *jsonEvent {
+	e := &jsonEvent{
+		Time:      event.LastTimestamp.Time,
+		Kind:      event.InvolvedObject.Kind,
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Count:     event.Count,
+	}
+	if e.Kind == "Queue" {
+		e.Queue = e.Name
+	}
+
+	return e
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	versionedscheme "volcano.sh/volcano/pkg/client/clientset/versioned/scheme"
+)
+
+// syncBuffer is a bytes.Buffer safe to be written by the broadcaster and read by the test.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]byte(nil), b.buffer.Bytes()...)
+}
+
+func TestJSONEventSink(t *testing.T) {
+	broadcaster := record.NewBroadcaster()
+
+	var logged []*v1.Event
+	var loggedMutex sync.Mutex
+	broadcaster.StartEventWatcher(func(event *v1.Event) {
+		loggedMutex.Lock()
+		defer loggedMutex.Unlock()
+		logged = append(logged, event)
+	})
+	out := &syncBuffer{}
+	startJSONEventSink(broadcaster, out)
+
+	recorder := broadcaster.NewRecorder(versionedscheme.Scheme, v1.EventSource{Component: "vc-controllers"})
+	recorder.Event(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1", SelfLink: "/apis/scheduling.sigs.dev/v1alpha2/queues/q1"},
+	}, v1.EventTypeNormal, "Open", "Open queue succeed")
+
+	var event jsonEvent
+	if err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		line := out.Bytes()
+		if len(line) == 0 {
+			return false, nil
+		}
+		return true, json.Unmarshal(line, &event)
+	}); err != nil {
+		t.Fatalf("expected an event written as JSON, got %q: %v", out.Bytes(), err)
+	}
+
+	if event.Queue != "q1" || event.Kind != "Queue" || event.Name != "q1" || event.Type != v1.EventTypeNormal ||
+		event.Reason != "Open" || event.Message != "Open queue succeed" || event.Time.IsZero() {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// The other sinks of the broadcaster receive the same event.
+	if err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		loggedMutex.Lock()
+		defer loggedMutex.Unlock()
+		return len(logged) == 1, nil
+	}); err != nil {
+		t.Errorf("expected the event received by the other sink")
+	}
+}
+
+func TestToJSONEvent(t *testing.T) {
+	event := toJSONEvent(&v1.Event{
+		InvolvedObject: v1.ObjectReference{Kind: "PodGroup", Namespace: "ns", Name: "pg1"},
+		Type:           v1.EventTypeWarning,
+		Reason:         "Unschedulable",
+		Message:        "queue q1 is closed",
+		Count:          2,
+	})
+
+	if event.Queue != "" || event.Kind != "PodGroup" || event.Namespace != "ns" || event.Name != "pg1" ||
+		event.Count != 2 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
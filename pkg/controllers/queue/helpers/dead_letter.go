@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// DefaultDeadLetterConfigMap is the suggested namespace/name of the dead-letter ConfigMap.
+const DefaultDeadLetterConfigMap = "volcano-system/volcano-queue-dead-letters"
+
+// DeadLetter is the record of a queue request dropped by the controller, kept in the data of the
+// dead-letter ConfigMap under the name of the queue, so that operators can inspect and re-drive it;
+// a later drop of the same queue replaces it.
+type DeadLetter struct {
+	Name   string                         `json:"name"`
+	Action schedulingv1alpha2.QueueAction `json:"action"`
+	Event  schedulingv1alpha2.QueueEvent  `json:"event"`
+	Error  string                         `json:"error"`
+	Time   metav1.Time                    `json:"time"`
+}
+
+// GetDeadLetter returns the dead letter of the queue in the dead-letter ConfigMap.
+func GetDeadLetter(cm *v1.ConfigMap, queue string) (*DeadLetter, error) {
+	data, found := cm.Data[queue]
+	if !found {
+		return nil, fmt.Errorf("no dropped request of queue %s in ConfigMap %s/%s", queue, cm.Namespace, cm.Name)
+	}
+
+	letter := &DeadLetter{}
+	if err := json.Unmarshal([]byte(data), letter); err != nil {
+		return nil, fmt.Errorf("invalid dropped request of queue %s in ConfigMap %s/%s: %v",
+			queue, cm.Namespace, cm.Name, err)
+	}
+
+	return letter, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package helpers holds the helpers of queues shared by the queue controller, the admission and the
+// command line, which depend on the API types only.
+package helpers
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// IsQueueReference returns whether the ownerReference refers to a Queue.
+func IsQueueReference(ref *metav1.OwnerReference) bool {
+	if ref == nil {
+		return false
+	}
+
+	if ref.APIVersion != schedulingv1alpha2.SchemeGroupVersion.String() {
+		return false
+	}
+
+	if ref.Kind != "Queue" {
+		return false
+	}
+
+	return true
+}
+
+// EffectiveCapability returns the capability of the queue resolved by queue controller if there is one,
+// spec.capability otherwise, which is the capability used by scheduler.
+func EffectiveCapability(queue *schedulingv1alpha2.Queue) v1.ResourceList {
+	if len(queue.Status.Capability) > 0 {
+		return queue.Status.Capability
+	}
+
+	return queue.Spec.Capability
+}
+
+// AddResourceList adds every resource of list, including extended resources such as
+// nvidia.com/gpu, to total and returns it; total is allocated if it is nil.
+func AddResourceList(total, list v1.ResourceList) v1.ResourceList {
+	for name, quantity := range list {
+		if total == nil {
+			total = v1.ResourceList{}
+		}
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+
+	return total
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"k8s.io/api/core/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// QueueUsage is the usage of a queue summed up from its podgroups.
+type QueueUsage struct {
+	// Queue is the name of the queue.
+	Queue string
+
+	// The number of podgroups in each phase.
+	Pending   int32
+	Running   int32
+	Unknown   int32
+	Inqueue   int32
+	Completed int32
+
+	// Used is the sum of minResources of the admitted podgroups, the completed podgroups are not admitted.
+	Used v1.ResourceList
+}
+
+// AggregateQueueUsage sums up the usage of the queue from the given podgroups, which are expected
+// to be the podgroups of the queue and its descendants. It has no side effect on its arguments.
+func AggregateQueueUsage(queue *schedulingv1alpha2.Queue, podGroups []*schedulingv1alpha2.PodGroup) QueueUsage {
+	usage := QueueUsage{Queue: queue.Name}
+
+	for _, pg := range podGroups {
+		switch phase := pg.Status.Phase; {
+		case IsPodGroupCompleted(pg):
+			usage.Completed++
+		case phase == schedulingv1alpha2.PodGroupPending:
+			usage.Pending++
+		case phase == schedulingv1alpha2.PodGroupRunning:
+			usage.Running++
+		case phase == schedulingv1alpha2.PodGroupUnknown:
+			usage.Unknown++
+		case phase == schedulingv1alpha2.PodGroupInqueue:
+			usage.Inqueue++
+		}
+
+		if isAdmitted(pg) && pg.Spec.MinResources != nil {
+			usage.Used = AddResourceList(usage.Used, *pg.Spec.MinResources)
+		}
+	}
+
+	return usage
+}
+
+// IsPodGroupCompleted returns whether all pods of the podgroup have succeeded, which the scheduler
+// marks by the Completed phase; the counts of pods in the status do not tell it, as the pending pods
+// are not counted.
+func IsPodGroupCompleted(pg *schedulingv1alpha2.PodGroup) bool {
+	return pg.Status.Phase == schedulingv1alpha2.PodGroupCompleted
+}
+
+// ApplyTo sets the podgroup counts and the used resources of the queue status.
+func (u QueueUsage) ApplyTo(status *schedulingv1alpha2.QueueStatus) {
+	status.Pending = u.Pending
+	status.Running = u.Running
+	status.Unknown = u.Unknown
+	status.Inqueue = u.Inqueue
+	status.Completed = u.Completed
+	status.Used = u.Used
+}
+
+// isAdmitted returns whether the podgroup holds the resources of the queue: pending podgroups
+// are not admitted yet and completed ones released their resources.
+func isAdmitted(pg *schedulingv1alpha2.PodGroup) bool {
+	switch pg.Status.Phase {
+	case schedulingv1alpha2.PodGroupInqueue, schedulingv1alpha2.PodGroupRunning, schedulingv1alpha2.PodGroupUnknown:
+		return true
+	}
+	return false
+}
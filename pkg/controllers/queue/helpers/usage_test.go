@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestAggregateQueueUsage(t *testing.T) {
+	newPodGroup := func(phase schedulingv1alpha2.PodGroupPhase, minResources v1.ResourceList) *schedulingv1alpha2.PodGroup {
+		pg := &schedulingv1alpha2.PodGroup{
+			Spec:   schedulingv1alpha2.PodGroupSpec{Queue: "q1"},
+			Status: schedulingv1alpha2.PodGroupStatus{Phase: phase},
+		}
+		if minResources != nil {
+			pg.Spec.MinResources = &minResources
+		}
+		return pg
+	}
+	// succeeded marks the minMember pods of the podgroup succeeded, other pods of it may be still pending.
+	succeeded := func(pg *schedulingv1alpha2.PodGroup) *schedulingv1alpha2.PodGroup {
+		pg.Spec.MinMember = 2
+		pg.Status.Succeeded = 2
+		return pg
+	}
+	queue := &schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}}
+
+	testCases := []struct {
+		name      string
+		podGroups []*schedulingv1alpha2.PodGroup
+		expected  QueueUsage
+	}{
+		{
+			name:     "no podgroups",
+			expected: QueueUsage{Queue: "q1"},
+		},
+		{
+			name: "single running podgroup",
+			podGroups: []*schedulingv1alpha2.PodGroup{
+				newPodGroup(schedulingv1alpha2.PodGroupRunning, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}),
+			},
+			expected: QueueUsage{
+				Queue:   "q1",
+				Running: 1,
+				Used:    v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+		{
+			name: "single pending podgroup",
+			podGroups: []*schedulingv1alpha2.PodGroup{
+				newPodGroup(schedulingv1alpha2.PodGroupPending, v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}),
+			},
+			expected: QueueUsage{Queue: "q1", Pending: 1},
+		},
+		{
+			name: "mixed resources and phases",
+			podGroups: []*schedulingv1alpha2.PodGroup{
+				newPodGroup(schedulingv1alpha2.PodGroupRunning, v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("500m"),
+					v1.ResourceMemory: resource.MustParse("1Gi"),
+				}),
+				newPodGroup(schedulingv1alpha2.PodGroupInqueue, v1.ResourceList{
+					v1.ResourceCPU:                    resource.MustParse("1500m"),
+					v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+				}),
+				newPodGroup(schedulingv1alpha2.PodGroupUnknown, v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")}),
+				newPodGroup(schedulingv1alpha2.PodGroupUnknown, nil),
+				newPodGroup(schedulingv1alpha2.PodGroupCompleted, v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}),
+				newPodGroup(schedulingv1alpha2.PodGroupPending, v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")}),
+				succeeded(newPodGroup(schedulingv1alpha2.PodGroupRunning, v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")})),
+			},
+			expected: QueueUsage{
+				Queue:     "q1",
+				Pending:   1,
+				Running:   2,
+				Unknown:   2,
+				Inqueue:   1,
+				Completed: 1,
+				Used: v1.ResourceList{
+					v1.ResourceCPU:                    resource.MustParse("10"),
+					v1.ResourceMemory:                 resource.MustParse("2Gi"),
+					v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var before []*schedulingv1alpha2.PodGroup
+		for _, pg := range testCase.podGroups {
+			before = append(before, pg.DeepCopy())
+		}
+
+		usage := AggregateQueueUsage(queue, testCase.podGroups)
+		if !equality.Semantic.DeepEqual(usage, testCase.expected) {
+			t.Errorf("case %s: expected %v, got %v", testCase.name, testCase.expected, usage)
+		}
+		if !equality.Semantic.DeepEqual(before, testCase.podGroups) {
+			t.Errorf("case %s: podgroups are changed", testCase.name)
+		}
+	}
+}
+
+func TestCustomResourceUsage(t *testing.T) {
+	fpga := v1.ResourceName("example.com/fpga")
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec: schedulingv1alpha2.QueueSpec{
+			Guaranteed: v1.ResourceList{fpga: resource.MustParse("2")},
+			Capability: v1.ResourceList{fpga: resource.MustParse("3")},
+		},
+	}
+	newPodGroup := func(name string, phase schedulingv1alpha2.PodGroupPhase, quantity string) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue: "q1",
+				MinResources: &v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("1"),
+					fpga:           resource.MustParse(quantity),
+				},
+			},
+			Status: schedulingv1alpha2.PodGroupStatus{Phase: phase},
+		}
+	}
+	podGroups := []*schedulingv1alpha2.PodGroup{
+		newPodGroup("running", schedulingv1alpha2.PodGroupRunning, "2"),
+		newPodGroup("pending", schedulingv1alpha2.PodGroupPending, "2"),
+	}
+
+	usage := AggregateQueueUsage(queue, podGroups)
+	if used := usage.Used[fpga]; used.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("expected used fpga 2, got %s", used.String())
+	}
+
+	// cpu is not capped, only the fpga left under the capability starves the pending podgroup.
+	result := WhatIf(queue, queue.Spec, podGroups)
+	if len(result.Violations) != 0 {
+		t.Errorf("expected no violations, got %v", result.Violations)
+	}
+	if len(result.Starved) != 1 || result.Starved[0].Name != "pending" {
+		t.Fatalf("expected podgroup pending to be starved, got %v", result.Starved)
+	}
+	if expected := "requires 2 of example.com/fpga, only 1 is left under the capability 3"; result.Starved[0].Reason != expected {
+		t.Errorf("expected reason %q, got %q", expected, result.Starved[0].Reason)
+	}
+}
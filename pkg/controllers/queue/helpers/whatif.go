@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/api/core/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// StarvedPodGroup is a pending podgroup which would not be admitted into the queue.
+type StarvedPodGroup struct {
+	Namespace string
+	Name      string
+	// Reason tells the resource which is short.
+	Reason string
+	// New is true if the podgroup is admitted under the current spec of the queue.
+	New bool
+}
+
+// WhatIfResult is the outcome of a proposed spec of a queue against its podgroups.
+type WhatIfResult struct {
+	// Used is the resources held by the admitted podgroups, which are not changed by the spec.
+	Used v1.ResourceList
+	// Violations are the guarantees and capabilities of the spec which cannot be satisfied.
+	Violations []string
+	// Starved are the pending podgroups which would not be admitted, in the order of admission.
+	Starved []StarvedPodGroup
+}
+
+// WhatIf simulates the reconciliation of the queue with the proposed spec against the podgroups of
+// the queue and its descendants, without any side effect. The pending podgroups are admitted in the
+// order of creation while their minResources fit in the capability left by the admitted ones; the
+// overcommit ratio is not applied, as the queue shrinks back to its capability under contention.
+func WhatIf(queue *schedulingv1alpha2.Queue, proposed schedulingv1alpha2.QueueSpec,
+	podGroups []*schedulingv1alpha2.PodGroup) *WhatIfResult {
+	result := simulate(queue, proposed, podGroups)
+
+	starvedNow := map[string]bool{}
+	for _, pg := range simulate(queue, queue.Spec, podGroups).Starved {
+		starvedNow[pg.Namespace+"/"+pg.Name] = true
+	}
+	for i := range result.Starved {
+		result.Starved[i].New = !starvedNow[result.Starved[i].Namespace+"/"+result.Starved[i].Name]
+	}
+
+	return result
+}
+
+func simulate(queue *schedulingv1alpha2.Queue, spec schedulingv1alpha2.QueueSpec,
+	podGroups []*schedulingv1alpha2.PodGroup) *WhatIfResult {
+	result := &WhatIfResult{Used: AggregateQueueUsage(queue, podGroups).Used}
+
+	var pending []*schedulingv1alpha2.PodGroup
+	for _, pg := range podGroups {
+		if pg.Status.Phase == schedulingv1alpha2.PodGroupPending {
+			pending = append(pending, pg)
+		}
+	}
+
+	for _, name := range sortedResourceNames(spec.Guaranteed) {
+		guaranteed := spec.Guaranteed[name]
+		if capability, found := spec.Capability[name]; found && capability.Cmp(guaranteed) < 0 {
+			result.Violations = append(result.Violations, fmt.Sprintf("guarantee %s of %s exceeds the capability %s",
+				guaranteed.String(), name, capability.String()))
+		}
+	}
+	for _, name := range sortedResourceNames(spec.Capability) {
+		capability := spec.Capability[name]
+		if used, found := result.Used[name]; found && used.Cmp(capability) > 0 {
+			result.Violations = append(result.Violations, fmt.Sprintf("admitted podgroups use %s of %s, above the capability %s",
+				used.String(), name, capability.String()))
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].CreationTimestamp.Equal(&pending[j].CreationTimestamp) {
+			return pending[i].CreationTimestamp.Before(&pending[j].CreationTimestamp)
+		}
+		return pending[i].Namespace+"/"+pending[i].Name < pending[j].Namespace+"/"+pending[j].Name
+	})
+
+	used := AddResourceList(nil, result.Used)
+	for _, pg := range pending {
+		var minResources v1.ResourceList
+		if pg.Spec.MinResources != nil {
+			minResources = *pg.Spec.MinResources
+		}
+
+		if reason := shortResource(minResources, used, spec.Capability); reason != "" {
+			result.Starved = append(result.Starved, StarvedPodGroup{
+				Namespace: pg.Namespace,
+				Name:      pg.Name,
+				Reason:    reason,
+			})
+			continue
+		}
+		used = AddResourceList(used, minResources)
+	}
+
+	return result
+}
+
+// shortResource returns why the request does not fit in the capability besides used, empty if it fits;
+// resources without capability are unlimited.
+func shortResource(request, used, capability v1.ResourceList) string {
+	for _, name := range sortedResourceNames(request) {
+		limit, found := capability[name]
+		if !found {
+			continue
+		}
+
+		required := request[name]
+		if required.Cmp(limit) > 0 {
+			return fmt.Sprintf("requires %s of %s, above the capability %s", required.String(), name, limit.String())
+		}
+
+		total := used[name]
+		total.Add(required)
+		if total.Cmp(limit) > 0 {
+			left := limit.DeepCopy()
+			left.Sub(used[name])
+			if left.Sign() < 0 {
+				left.Set(0)
+			}
+			return fmt.Sprintf("requires %s of %s, only %s is left under the capability %s",
+				required.String(), name, left.String(), limit.String())
+		}
+	}
+
+	return ""
+}
+
+func sortedResourceNames(list v1.ResourceList) []v1.ResourceName {
+	var names []v1.ResourceName
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	return names
+}
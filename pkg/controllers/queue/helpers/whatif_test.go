@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestWhatIf(t *testing.T) {
+	now := time.Now()
+	newPodGroup := func(name string, phase schedulingv1alpha2.PodGroupPhase, age time.Duration, cpu string) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "ns",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue:        "q1",
+				MinResources: &v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+			},
+			Status: schedulingv1alpha2.PodGroupStatus{Phase: phase},
+		}
+	}
+	cpu := func(quantity string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(quantity)}
+	}
+
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       schedulingv1alpha2.QueueSpec{Capability: cpu("10")},
+	}
+	podGroups := []*schedulingv1alpha2.PodGroup{
+		newPodGroup("running", schedulingv1alpha2.PodGroupRunning, 4*time.Hour, "4"),
+		newPodGroup("completed", schedulingv1alpha2.PodGroupCompleted, 4*time.Hour, "8"),
+		newPodGroup("pending-new", schedulingv1alpha2.PodGroupPending, time.Hour, "2"),
+		newPodGroup("pending-old", schedulingv1alpha2.PodGroupPending, 2*time.Hour, "3"),
+		newPodGroup("pending-large", schedulingv1alpha2.PodGroupPending, 3*time.Hour, "12"),
+	}
+
+	testCases := []struct {
+		name             string
+		proposed         schedulingv1alpha2.QueueSpec
+		expectViolations int
+		expectStarved    []StarvedPodGroup
+	}{
+		{
+			name:     "unchanged spec",
+			proposed: queue.Spec,
+			expectStarved: []StarvedPodGroup{
+				{Namespace: "ns", Name: "pending-large", Reason: "requires 12 of cpu, above the capability 10"},
+			},
+		},
+		{
+			name:     "capability removed",
+			proposed: schedulingv1alpha2.QueueSpec{},
+		},
+		{
+			name:     "capability shrunk",
+			proposed: schedulingv1alpha2.QueueSpec{Capability: cpu("8")},
+			expectStarved: []StarvedPodGroup{
+				{Namespace: "ns", Name: "pending-large", Reason: "requires 12 of cpu, above the capability 8"},
+				{Namespace: "ns", Name: "pending-new", Reason: "requires 2 of cpu, only 1 is left under the capability 8", New: true},
+			},
+		},
+		{
+			name:             "capability below usage and guarantee",
+			proposed:         schedulingv1alpha2.QueueSpec{Capability: cpu("3"), Guaranteed: cpu("5")},
+			expectViolations: 2,
+			expectStarved: []StarvedPodGroup{
+				{Namespace: "ns", Name: "pending-large", Reason: "requires 12 of cpu, above the capability 3"},
+				{Namespace: "ns", Name: "pending-old", Reason: "requires 3 of cpu, only 0 is left under the capability 3", New: true},
+				{Namespace: "ns", Name: "pending-new", Reason: "requires 2 of cpu, only 0 is left under the capability 3", New: true},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		result := WhatIf(queue, testCase.proposed, podGroups)
+		if used := result.Used[v1.ResourceCPU]; used.Cmp(resource.MustParse("4")) != 0 {
+			t.Errorf("case %s: expected used cpu 4, got %s", testCase.name, used.String())
+		}
+		if len(result.Violations) != testCase.expectViolations {
+			t.Errorf("case %s: expected %d violations, got %v", testCase.name, testCase.expectViolations, result.Violations)
+		}
+		if !reflect.DeepEqual(result.Starved, testCase.expectStarved) {
+			t.Errorf("case %s: expected starved %v, got %v", testCase.name, testCase.expectStarved, result.Starved)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+// minIdleCheckPeriod is the lower limit of the period to look for idle queues.
+const minIdleCheckPeriod = time.Second
+
+// idleCheckPeriod returns the period to look for idle queues, so that a queue is
+// closed no later than a quarter of the timeout after it becomes idle.
+func idleCheckPeriod(timeout time.Duration) time.Duration {
+	if period := timeout / 4; period > minIdleCheckPeriod {
+		return period
+	}
+
+	return minIdleCheckPeriod
+}
+
+// closeIdleQueues issues an Idle event to close the open queues which have no pending
+// or running podgroups for the idle timeout.
+func (c *Controller) closeIdleQueues(now time.Time) {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues: %v", err)
+		return
+	}
+
+	for _, queue := range queues {
+		if queue.DeletionTimestamp != nil || queue.Spec.State == schedulingv1alpha2.QueueStateClosed ||
+			queue.Status.State != schedulingv1alpha2.QueueStateOpen {
+			// The timer starts over once the queue is opened again.
+			c.forgetActivity(queue.Name)
+			continue
+		}
+
+		if c.hasActivePodGroups(queue.Name) {
+			c.recordActivity(queue.Name, now)
+			continue
+		}
+
+		if idle := c.idleDuration(queue.Name, now); idle >= c.idleTimeout {
+			klog.V(3).Infof("Queue %s has no pending or running podgroups for %v, close it.", queue.Name, idle)
+			c.enqueue(queue.Name, schedulingv1alpha2.QueueIdleEvent, schedulingv1alpha2.CloseQueueAction)
+		}
+	}
+}
+
+// hasActivePodGroups returns whether the queue or its descendants have pending or running podgroups.
+func (c *Controller) hasActivePodGroups(name string) bool {
+	for _, key := range append(c.getDescendantPodGroups(name), c.getPodGroups(name)...) {
+		ns, pgName, _ := cache.SplitMetaNamespaceKey(key)
+		pg, err := c.pgLister.PodGroups(ns).Get(pgName)
+		if err != nil {
+			continue
+		}
+
+		if !helpers.IsPodGroupCompleted(pg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reopenIdleQueue opens the queue of the podgroup again if it was closed as idle.
+func (c *Controller) reopenIdleQueue(pg *schedulingv1alpha2.PodGroup) {
+	if helpers.IsPodGroupCompleted(pg) {
+		return
+	}
+
+	queue, err := c.queueLister.Get(pg.Spec.Queue)
+	if err != nil || queue.Spec.State != schedulingv1alpha2.QueueStateClosed ||
+		queue.Annotations[schedulingv1alpha2.IdleClosedAnnotationKey] != "true" {
+		return
+	}
+
+	klog.V(3).Infof("PodGroup %s/%s is created in idle queue %s, open it.", pg.Namespace, pg.Name, queue.Name)
+	c.enqueue(queue.Name, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.OpenQueueAction)
+}
+
+// idleDuration returns how long the queue has been idle, the first call starts the timer.
+func (c *Controller) idleDuration(name string, now time.Time) time.Duration {
+	c.idleMutex.Lock()
+	defer c.idleMutex.Unlock()
+
+	last, found := c.lastActive[name]
+	if !found {
+		c.lastActive[name] = now
+		return 0
+	}
+
+	return now.Sub(last)
+}
+
+func (c *Controller) recordActivity(name string, now time.Time) {
+	c.idleMutex.Lock()
+	defer c.idleMutex.Unlock()
+
+	c.lastActive[name] = now
+}
+
+func (c *Controller) forgetActivity(name string) {
+	c.idleMutex.Lock()
+	defer c.idleMutex.Unlock()
+
+	delete(c.lastActive, name)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func TestCloseIdleQueues(t *testing.T) {
+	timeout := 10 * time.Minute
+	now := time.Now()
+
+	testCases := []struct {
+		name        string
+		state       schedulingv1alpha2.QueueState
+		phase       schedulingv1alpha2.PodGroupPhase
+		elapsed     time.Duration
+		expectClose bool
+	}{
+		{
+			name:        "queue without podgroups is closed after the timeout",
+			state:       schedulingv1alpha2.QueueStateOpen,
+			elapsed:     timeout,
+			expectClose: true,
+		},
+		{
+			name:    "queue without podgroups is not closed before the timeout",
+			state:   schedulingv1alpha2.QueueStateOpen,
+			elapsed: timeout - time.Second,
+		},
+		{
+			name:        "queue with completed podgroups is closed",
+			state:       schedulingv1alpha2.QueueStateOpen,
+			phase:       schedulingv1alpha2.PodGroupCompleted,
+			elapsed:     timeout,
+			expectClose: true,
+		},
+		{
+			name:    "queue with pending podgroups is not closed",
+			state:   schedulingv1alpha2.QueueStateOpen,
+			phase:   schedulingv1alpha2.PodGroupPending,
+			elapsed: timeout,
+		},
+		{
+			name:    "queue with running podgroups is not closed",
+			state:   schedulingv1alpha2.QueueStateOpen,
+			phase:   schedulingv1alpha2.PodGroupRunning,
+			elapsed: timeout,
+		},
+		{
+			name:    "closed queue is not closed again",
+			state:   schedulingv1alpha2.QueueStateClosed,
+			elapsed: timeout,
+		},
+	}
+
+	for _, testCase := range testCases {
+		opt := NewOptions()
+		opt.IdleTimeout = timeout
+		c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+
+		var requests []*schedulingv1alpha2.QueueRequest
+		c.enqueueQueue = func(req *schedulingv1alpha2.QueueRequest) {
+			requests = append(requests, req)
+		}
+
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       schedulingv1alpha2.QueueSpec{State: testCase.state},
+			Status:     schedulingv1alpha2.QueueStatus{State: testCase.state},
+		})
+		if len(testCase.phase) != 0 {
+			pg := &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg1"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1"},
+				Status:     schedulingv1alpha2.PodGroupStatus{Phase: testCase.phase},
+			}
+			c.pgInformer.Informer().GetIndexer().Add(pg)
+			c.podGroups["q1"] = map[string]struct{}{"ns/pg1": {}}
+		}
+
+		c.closeIdleQueues(now)
+		c.closeIdleQueues(now.Add(testCase.elapsed))
+
+		closed := false
+		for _, req := range requests {
+			if req.Name == "q1" && req.Event == schedulingv1alpha2.QueueIdleEvent &&
+				req.Action == schedulingv1alpha2.CloseQueueAction {
+				closed = true
+			}
+		}
+		if closed != testCase.expectClose {
+			t.Errorf("%s: expected close %v, got requests %v", testCase.name, testCase.expectClose, requests)
+		}
+	}
+}
+
+func TestReopenIdleQueue(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		phase       schedulingv1alpha2.PodGroupPhase
+		expectOpen  bool
+	}{
+		{
+			name:        "queue closed as idle is opened",
+			annotations: map[string]string{schedulingv1alpha2.IdleClosedAnnotationKey: "true"},
+			phase:       schedulingv1alpha2.PodGroupPending,
+			expectOpen:  true,
+		},
+		{
+			name:  "queue closed by user is not opened",
+			phase: schedulingv1alpha2.PodGroupPending,
+		},
+		{
+			name:        "completed podgroup does not open the queue",
+			annotations: map[string]string{schedulingv1alpha2.IdleClosedAnnotationKey: "true"},
+			phase:       schedulingv1alpha2.PodGroupCompleted,
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+
+		var requests []*schedulingv1alpha2.QueueRequest
+		c.enqueueQueue = func(req *schedulingv1alpha2.QueueRequest) {
+			requests = append(requests, req)
+		}
+
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1", Annotations: testCase.annotations},
+			Spec:       schedulingv1alpha2.QueueSpec{State: schedulingv1alpha2.QueueStateClosed},
+			Status:     schedulingv1alpha2.QueueStatus{State: schedulingv1alpha2.QueueStateClosed},
+		})
+
+		c.addPodGroup(&schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1"},
+			Status:     schedulingv1alpha2.PodGroupStatus{Phase: testCase.phase},
+		})
+
+		opened := false
+		for _, req := range requests {
+			if req.Name == "q1" && req.Action == schedulingv1alpha2.OpenQueueAction {
+				opened = true
+			}
+		}
+		if opened != testCase.expectOpen {
+			t.Errorf("%s: expected open %v, got requests %v", testCase.name, testCase.expectOpen, requests)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/klog"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// infof logs the message if the verbosity is enabled; the key-value pairs are appended
+// to the message only if structured logging is enabled.
+func (c *Controller) infof(level klog.Level, keysAndValues []interface{}, format string, args ...interface{}) {
+	if !klog.V(level) {
+		return
+	}
+
+	klog.InfoDepth(1, c.logMessage(keysAndValues, format, args...))
+}
+
+// errorf logs the error message; the key-value pairs are appended to the message only
+// if structured logging is enabled.
+func (c *Controller) errorf(keysAndValues []interface{}, format string, args ...interface{}) {
+	klog.ErrorDepth(1, c.logMessage(keysAndValues, format, args...))
+}
+
+func (c *Controller) logMessage(keysAndValues []interface{}, format string, args ...interface{}) string {
+	msg := fmt.Sprintf(format, args...)
+	if !c.structuredLogging {
+		return msg
+	}
+
+	return structuredMessage(msg, keysAndValues)
+}
+
+// structuredMessage formats the message and the key-value pairs as klog.InfoS does, e.g.
+// msg="Finished syncing queue" queue="q1" action="SyncQueue", so that the logs can be
+// filtered by the keys once they are collected.
+func structuredMessage(msg string, keysAndValues []interface{}) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "msg=%q", msg)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		fmt.Fprintf(b, " %v=", keysAndValues[i])
+		switch v := value.(type) {
+		case error:
+			fmt.Fprintf(b, "%q", v.Error())
+		case fmt.Stringer:
+			fmt.Fprintf(b, "%q", v.String())
+		default:
+			if value != nil && reflect.TypeOf(value).Kind() == reflect.String {
+				fmt.Fprintf(b, "%q", v)
+			} else {
+				fmt.Fprintf(b, "%+v", v)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// requestFields returns the key-value pairs of the queue request followed by the extra ones.
+func requestFields(req *schedulingv1alpha2.QueueRequest, keysAndValues ...interface{}) []interface{} {
+	if req == nil {
+		return keysAndValues
+	}
+
+	return append([]interface{}{"queue", req.Name, "action", req.Action, "event", req.Event}, keysAndValues...)
+}
+
+// commandFields returns the key-value pairs of the command followed by the extra ones.
+func commandFields(cmd *busv1alpha1.Command, keysAndValues ...interface{}) []interface{} {
+	if cmd == nil {
+		return keysAndValues
+	}
+
+	fields := []interface{}{"command", cmd.Namespace + "/" + cmd.Name, "action", cmd.Action}
+	if cmd.TargetObject != nil {
+		fields = append(fields, "queue", cmd.TargetObject.Name)
+	}
+
+	return append(fields, keysAndValues...)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestLogMessage(t *testing.T) {
+	req := &schedulingv1alpha2.QueueRequest{
+		Name:   "q1",
+		Event:  schedulingv1alpha2.QueueCommandIssuedEvent,
+		Action: schedulingv1alpha2.OpenQueueAction,
+	}
+	cmd := &busv1alpha1.Command{
+		ObjectMeta:   metav1.ObjectMeta{Name: "cmd1", Namespace: "default"},
+		Action:       string(schedulingv1alpha2.CloseQueueAction),
+		TargetObject: &metav1.OwnerReference{Name: "q1"},
+	}
+
+	testCases := []struct {
+		Name          string
+		structured    bool
+		keysAndValues []interface{}
+		ExpectValue   string
+	}{
+		{
+			Name:          "plain message",
+			keysAndValues: requestFields(req),
+			ExpectValue:   "Finished syncing queue q1.",
+		},
+		{
+			Name:          "queue request",
+			structured:    true,
+			keysAndValues: requestFields(req, "duration", time.Second),
+			ExpectValue:   `msg="Finished syncing queue q1." queue="q1" action="OpenQueue" event="CommandIssued" duration="1s"`,
+		},
+		{
+			Name:          "command with error",
+			structured:    true,
+			keysAndValues: commandFields(cmd, "err", fmt.Errorf("not found")),
+			ExpectValue:   `msg="Finished syncing queue q1." command="default/cmd1" action="CloseQueue" queue="q1" err="not found"`,
+		},
+		{
+			Name:          "missing value",
+			structured:    true,
+			keysAndValues: []interface{}{"retries", 3, "queue"},
+			ExpectValue:   `msg="Finished syncing queue q1." retries=3 queue="(MISSING)"`,
+		},
+		{
+			Name:          "nil request",
+			structured:    true,
+			keysAndValues: requestFields(nil),
+			ExpectValue:   `msg="Finished syncing queue q1."`,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := &Controller{structuredLogging: testcase.structured}
+		msg := c.logMessage(testcase.keysAndValues, "Finished syncing queue %s.", "q1")
+		if msg != testcase.ExpectValue {
+			t.Errorf("case %d (%s): expected: %s, got %s", i, testcase.Name, testcase.ExpectValue, msg)
+		}
+	}
+}
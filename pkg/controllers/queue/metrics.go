@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto" // auto-registry collectors in default registry
+)
+
+const (
+	// volcanoNamespace - namespace in prometheus used by volcano
+	volcanoNamespace = "volcano"
+
+	// queueWorkqueue label of the workqueue holding queue requests
+	queueWorkqueue = "queue"
+	// commandWorkqueue label of the workqueue holding commands
+	commandWorkqueue = "command"
+	// jobCommandWorkqueue label of the workqueue holding commands targeting jobs
+	jobCommandWorkqueue = "job_command"
+)
+
+var (
+	queueSyncDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "queue_sync_duration_seconds",
+			Help:      "Latency of queue reconciliation in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{"action"},
+	)
+
+	queueSyncErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "queue_sync_errors_total",
+			Help:      "Total number of failed queue reconciliations",
+		}, []string{"action"},
+	)
+
+	queueWorkqueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "queue_workqueue_depth",
+			Help:      "Current depth of the workqueues in queue controller",
+		}, []string{"workqueue"},
+	)
+
+	commandOldestAge = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: volcanoNamespace,
+			Name:      "command_oldest_age_seconds",
+			Help:      "Age of the oldest unprocessed command targeting queues in seconds",
+		},
+	)
+)
+
+// updateQueueSyncDuration updates latency of queue reconciliation for the action
+func updateQueueSyncDuration(action string, duration time.Duration) {
+	queueSyncDuration.WithLabelValues(action).Observe(duration.Seconds())
+}
+
+// registerQueueSyncError records a failed queue reconciliation for the action
+func registerQueueSyncError(action string) {
+	queueSyncErrors.WithLabelValues(action).Inc()
+}
+
+// updateWorkqueueDepth updates current depth of the workqueue
+func updateWorkqueueDepth(workqueue string, depth int) {
+	queueWorkqueueDepth.WithLabelValues(workqueue).Set(float64(depth))
+}
+
+// updateCommandOldestAge updates the age of the oldest unprocessed command
+func updateCommandOldestAge(age time.Duration) {
+	commandOldestAge.Set(age.Seconds())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+const (
+	// PausedAnnotationKey is the annotation of the pause ConfigMap which pauses the reconciliation
+	// of queues while it is "true", e.g. during cluster maintenance.
+	PausedAnnotationKey = "volcano.sh/queue-reconciliation-paused"
+
+	// pausedRequeueDelay is the delay before a queue request is checked again while paused.
+	pausedRequeueDelay = time.Second
+
+	// pausedLogPeriod is the interval of logging that the reconciliation is paused.
+	pausedLogPeriod = time.Minute
+)
+
+// newPauseInformer creates the informer of the pause ConfigMap, namespace/name, which only lists
+// and watches that ConfigMap.
+func newPauseInformer(kubeClient kubernetes.Interface, configMap string, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	parts := strings.SplitN(configMap, "/", 2)
+	if len(parts) != 2 {
+		klog.Errorf("Invalid pause ConfigMap %q of queue controller, must be namespace/name.", configMap)
+		return nil
+	}
+
+	return informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod,
+		informers.WithNamespace(parts[0]),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", parts[1]).String()
+		}),
+	).Core().V1().ConfigMaps().Informer()
+}
+
+// isPaused returns whether the reconciliation of queues is paused.
+func (c *Controller) isPaused() bool {
+	return atomic.LoadInt32(&c.paused) == 1
+}
+
+// setPaused pauses or resumes the reconciliation of queues by the annotation of the pause ConfigMap.
+func (c *Controller) setPaused(cm *v1.ConfigMap) {
+	var paused int32
+	if cm != nil && cm.Annotations[PausedAnnotationKey] == "true" {
+		paused = 1
+	}
+
+	if atomic.SwapInt32(&c.paused, paused) == paused {
+		return
+	}
+	if paused == 1 {
+		klog.Warningf("Queue reconciliation is paused by ConfigMap %s/%s.", cm.Namespace, cm.Name)
+	} else {
+		klog.Infof("Queue reconciliation is resumed.")
+	}
+}
+
+func (c *Controller) addPauseConfigMap(obj interface{}) {
+	cm, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		klog.Errorf("Can not covert object %v to ConfigMap.", obj)
+		return
+	}
+
+	c.setPaused(cm)
+}
+
+func (c *Controller) updatePauseConfigMap(old, new interface{}) {
+	c.addPauseConfigMap(new)
+}
+
+func (c *Controller) deletePauseConfigMap(obj interface{}) {
+	c.setPaused(nil)
+}
+
+// logPaused reminds that the reconciliation of queues is paused.
+func (c *Controller) logPaused() {
+	if c.isPaused() {
+		klog.Warningf("Queue reconciliation is paused by ConfigMap %s, remove annotation %s to resume.",
+			c.pauseConfigMap, PausedAnnotationKey)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func newPauseConfigMap(annotations map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "volcano-system",
+			Name:        "volcano-queue-pause",
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestPauseConfigMapHandlers(t *testing.T) {
+	paused := newPauseConfigMap(map[string]string{PausedAnnotationKey: "true"})
+	resumed := newPauseConfigMap(map[string]string{PausedAnnotationKey: "false"})
+
+	testCases := []struct {
+		name     string
+		handle   func(c *Controller)
+		expected bool
+	}{
+		{
+			name:     "add paused",
+			handle:   func(c *Controller) { c.addPauseConfigMap(paused) },
+			expected: true,
+		},
+		{
+			name:   "add without annotation",
+			handle: func(c *Controller) { c.addPauseConfigMap(newPauseConfigMap(nil)) },
+		},
+		{
+			name: "update to resumed",
+			handle: func(c *Controller) {
+				c.addPauseConfigMap(paused)
+				c.updatePauseConfigMap(paused, resumed)
+			},
+		},
+		{
+			name: "delete with tombstone",
+			handle: func(c *Controller) {
+				c.addPauseConfigMap(paused)
+				c.deletePauseConfigMap(cache.DeletedFinalStateUnknown{Key: "volcano-system/volcano-queue-pause", Obj: paused})
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+		testCase.handle(c)
+		if c.isPaused() != testCase.expected {
+			t.Errorf("case %s: expected paused %v, got %v", testCase.name, testCase.expected, c.isPaused())
+		}
+	}
+}
+
+func TestProcessNextWorkItemPaused(t *testing.T) {
+	c := newFakeController()
+	var handled []string
+	c.syncHandler = func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
+		handled = append(handled, req.Name)
+		return nil
+	}
+
+	c.addPauseConfigMap(newPauseConfigMap(map[string]string{PausedAnnotationKey: "true"}))
+	c.enqueue("q1", schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+
+	c.processNextWorkItem(context.TODO())
+	if len(handled) != 0 {
+		t.Fatalf("expected no request handled while paused, got %v", handled)
+	}
+
+	// The request is requeued after a delay and handled once resumed.
+	c.deletePauseConfigMap(newPauseConfigMap(nil))
+	deadline := time.Now().Add(5 * pausedRequeueDelay)
+	for c.queue.Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected request requeued after %v", pausedRequeueDelay)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.processNextWorkItem(context.TODO())
+	if len(handled) != 1 || handled[0] != "q1" {
+		t.Errorf("expected request of q1 handled once resumed, got %v", handled)
+	}
+}
+
+func TestNewPauseInformer(t *testing.T) {
+	if c := newFakeController(); c.pauseInformer != nil {
+		t.Errorf("expected no pause informer without pause ConfigMap")
+	}
+
+	opt := NewOptions()
+	opt.PauseConfigMap = "volcano-system/volcano-queue-pause"
+	c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+	if c.pauseInformer == nil {
+		t.Errorf("expected pause informer of pause ConfigMap %s", opt.PauseConfigMap)
+	}
+}
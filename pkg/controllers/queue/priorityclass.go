@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sort"
+
+	"k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// indexPodGroupByPriorityClass indexes the podgroup by the name of its PriorityClass, the podgroups
+// without PriorityClass are indexed by the empty name.
+func indexPodGroupByPriorityClass(obj interface{}) ([]string, error) {
+	pg, ok := obj.(*schedulingv1alpha2.PodGroup)
+	if !ok {
+		return nil, nil
+	}
+
+	return []string{pg.Spec.PriorityClassName}, nil
+}
+
+// queuesOfPriorityClass returns the queues affected by a change of the PriorityClass: the queues
+// of the podgroups referencing it, or not referencing any if it is the global default, and the
+// queues whose priority is the value of it or one of the given old values.
+func (c *Controller) queuesOfPriorityClass(pc *v1beta1.PriorityClass, oldValues ...int32) []string {
+	names := map[string]struct{}{}
+
+	classNames := []string{pc.Name}
+	if pc.GlobalDefault {
+		classNames = append(classNames, "")
+	}
+	for _, className := range classNames {
+		objs, err := c.pgInformer.Informer().GetIndexer().ByIndex(priorityClassIndex, className)
+		if err != nil {
+			klog.Errorf("Failed to get podgroups of PriorityClass %s: %v", pc.Name, err)
+			continue
+		}
+		for _, obj := range objs {
+			pg, ok := obj.(*schedulingv1alpha2.PodGroup)
+			if !ok || !c.isWatchedNamespace(pg.Namespace) {
+				continue
+			}
+			names[pg.Spec.Queue] = struct{}{}
+		}
+	}
+
+	// The priority of a queue is validated against the values of the PriorityClasses.
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues for PriorityClass %s: %v", pc.Name, err)
+	}
+	for _, queue := range queues {
+		if queue.Spec.Priority == 0 {
+			continue
+		}
+		for _, value := range append([]int32{pc.Value}, oldValues...) {
+			if queue.Spec.Priority == value {
+				names[queue.Name] = struct{}{}
+			}
+		}
+	}
+
+	var result []string
+	for name := range names {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// enqueueQueuesOfPriorityClass enqueues the queues affected by a change of the PriorityClass.
+func (c *Controller) enqueueQueuesOfPriorityClass(pc *v1beta1.PriorityClass, oldValues ...int32) {
+	for _, name := range c.queuesOfPriorityClass(pc, oldValues...) {
+		klog.V(4).Infof("PriorityClass %s changed, sync queue %s.", pc.Name, name)
+		c.enqueue(name, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+	}
+}
+
+func (c *Controller) addPriorityClass(obj interface{}) {
+	pc, ok := obj.(*v1beta1.PriorityClass)
+	if !ok {
+		klog.Errorf("Can not covert object %v to PriorityClass.", obj)
+		return
+	}
+
+	// All queues are synced once the caches are synced, the PriorityClasses listed before that do
+	// not need to sync them again.
+	if !c.Ready() {
+		return
+	}
+
+	c.enqueueQueuesOfPriorityClass(pc)
+}
+
+func (c *Controller) updatePriorityClass(old, new interface{}) {
+	oldPC, ok := old.(*v1beta1.PriorityClass)
+	if !ok {
+		klog.Errorf("Can not covert old object %v to PriorityClass.", old)
+		return
+	}
+
+	newPC, ok := new.(*v1beta1.PriorityClass)
+	if !ok {
+		klog.Errorf("Can not covert new object %v to PriorityClass.", new)
+		return
+	}
+
+	// Only the value and the global default affect the queues, e.g. resync or a new description does not.
+	if oldPC.Value == newPC.Value && oldPC.GlobalDefault == newPC.GlobalDefault {
+		return
+	}
+
+	// The podgroups without PriorityClass are affected if the class was or becomes the global default.
+	pc, other := newPC, oldPC
+	if oldPC.GlobalDefault && !newPC.GlobalDefault {
+		pc, other = oldPC, newPC
+	}
+	c.enqueueQueuesOfPriorityClass(pc, other.Value)
+}
+
+func (c *Controller) deletePriorityClass(obj interface{}) {
+	pc, ok := obj.(*v1beta1.PriorityClass)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			klog.Errorf("Couldn't get object from tombstone %#v.", obj)
+			return
+		}
+		pc, ok = tombstone.Obj.(*v1beta1.PriorityClass)
+		if !ok {
+			klog.Errorf("Tombstone contained object that is not a PriorityClass: %#v.", obj)
+			return
+		}
+	}
+
+	c.enqueueQueuesOfPriorityClass(pc)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func TestPriorityClassHandlers(t *testing.T) {
+	newPC := func(name string, value int32, globalDefault bool) *schedulingv1beta1.PriorityClass {
+		return &schedulingv1beta1.PriorityClass{
+			ObjectMeta:    metav1.ObjectMeta{Name: name},
+			Value:         value,
+			GlobalDefault: globalDefault,
+		}
+	}
+	described := newPC("high", 1000, false)
+	described.Description = "retuned"
+
+	testCases := []struct {
+		name     string
+		handle   func(c *Controller)
+		expected []string
+	}{
+		{
+			name:     "add",
+			handle:   func(c *Controller) { c.addPriorityClass(newPC("high", 1000, false)) },
+			expected: []string{"q1"},
+		},
+		{
+			name: "add before caches are synced",
+			handle: func(c *Controller) {
+				atomic.StoreInt32(&c.ready, 0)
+				c.addPriorityClass(newPC("high", 1000, false))
+			},
+		},
+		{
+			name:   "update description",
+			handle: func(c *Controller) { c.updatePriorityClass(newPC("high", 1000, false), described) },
+		},
+		{
+			name:     "update value",
+			handle:   func(c *Controller) { c.updatePriorityClass(newPC("high", 1000, false), newPC("high", 2000, false)) },
+			expected: []string{"q1"},
+		},
+		{
+			name:     "update value from the priority of a queue",
+			handle:   func(c *Controller) { c.updatePriorityClass(newPC("high", 100, false), newPC("high", 2000, false)) },
+			expected: []string{"q1", "q4"},
+		},
+		{
+			name:     "update value to the priority of a queue",
+			handle:   func(c *Controller) { c.updatePriorityClass(newPC("high", 1000, false), newPC("high", 100, false)) },
+			expected: []string{"q1", "q4"},
+		},
+		{
+			name:     "delete the priority of a queue",
+			handle:   func(c *Controller) { c.deletePriorityClass(newPC("queue", 100, false)) },
+			expected: []string{"q4"},
+		},
+		{
+			name:     "become global default",
+			handle:   func(c *Controller) { c.updatePriorityClass(newPC("medium", 500, false), newPC("medium", 500, true)) },
+			expected: []string{"q2"},
+		},
+		{
+			name:     "no longer global default",
+			handle:   func(c *Controller) { c.updatePriorityClass(newPC("medium", 500, true), newPC("medium", 500, false)) },
+			expected: []string{"q2"},
+		},
+		{
+			name: "delete with tombstone",
+			handle: func(c *Controller) {
+				c.deletePriorityClass(cache.DeletedFinalStateUnknown{Key: "low", Obj: newPC("low", 10, false)})
+			},
+			expected: []string{"q3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newFakeController()
+		atomic.StoreInt32(&c.ready, 1)
+		for _, pg := range []*schedulingv1alpha2.PodGroup{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pg1"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1", PriorityClassName: "high"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pg2"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q2"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pg3"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q3", PriorityClassName: "low"},
+			},
+		} {
+			c.pgInformer.Informer().GetIndexer().Add(pg)
+		}
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q4"},
+			Spec:       schedulingv1alpha2.QueueSpec{Priority: 100},
+		})
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q5"}})
+
+		testCase.handle(c)
+
+		var enqueued []string
+		for c.queue.Len() > 0 {
+			obj, _ := c.queue.Get()
+			enqueued = append(enqueued, obj.(*schedulingv1alpha2.QueueRequest).Name)
+			c.queue.Done(obj)
+		}
+		sort.Strings(enqueued)
+		if !reflect.DeepEqual(enqueued, testCase.expected) {
+			t.Errorf("case %s: expected queues %v enqueued, got %v", testCase.name, testCase.expected, enqueued)
+		}
+	}
+}
+
+func TestQueuesOfPriorityClassWatchNamespaces(t *testing.T) {
+	opt := NewOptions()
+	opt.WatchNamespaces = []string{"ns1", "ns2"}
+	c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+	for _, pg := range []*schedulingv1alpha2.PodGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pg1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1", PriorityClassName: "high"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "pg2"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q2", PriorityClassName: "high"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns3", Name: "pg3"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q3", PriorityClassName: "high"},
+		},
+	} {
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+	}
+
+	queues := c.queuesOfPriorityClass(&schedulingv1beta1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}})
+	if expected := []string{"q1", "q2"}; !reflect.DeepEqual(queues, expected) {
+		t.Errorf("expected queues %v, got %v", expected, queues)
+	}
+	if _, err := c.pgLister.PodGroups("ns3").Get("pg3"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected podgroup out of the watched namespaces not found, got %v", err)
+	}
+	if _, err := c.pgLister.PodGroups("ns2").Get("pg2"); err != nil {
+		t.Errorf("expected podgroup in the watched namespaces found, got %v", err)
+	}
+}
@@ -17,16 +17,28 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformer "k8s.io/client-go/informers/core/v1"
+	schedulingv1beta1informer "k8s.io/client-go/informers/scheduling/v1beta1"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelister "k8s.io/client-go/listers/core/v1"
+	schedulingv1beta1lister "k8s.io/client-go/listers/scheduling/v1beta1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -41,17 +53,135 @@ import (
 	schedulinginformer "volcano.sh/volcano/pkg/client/informers/externalversions/scheduling/v1alpha2"
 	busv1alpha1lister "volcano.sh/volcano/pkg/client/listers/bus/v1alpha1"
 	schedulinglister "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
 	queuestate "volcano.sh/volcano/pkg/controllers/queue/state"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 )
 
 const (
-	// maxRetries is the number of times a queue or command will be retried before it is dropped out of the queue.
-	// With the current rate-limiter in use (5ms*2^(maxRetries-1)) the following numbers represent the times
-	// a queue or command is going to be requeued:
-	// 5ms, 10ms, 20ms, 40ms, 80ms, 160ms, 320ms, 640ms, 1.3s, 2.6s, 5.1s, 10.2s, 20.4s, 41s, 82s
-	maxRetries = 15
+	// DefaultMaxRetries is the default number of times a queue or command will be retried before it is dropped
+	// out of the queue. With the current rate-limiter in use (5ms*2^(maxRetries-1) capped by DefaultRetryMaxDelay,
+	// and randomly shortened by up to 50%) the following numbers represent the max delays before a queue or
+	// command is going to be requeued:
+	// 5ms, 10ms, 20ms, 40ms, 80ms, 160ms, 320ms, 640ms, 1.3s, 2.6s, 5.1s, 10.2s, 20.4s, 30s, 30s
+	DefaultMaxRetries = 15
+
+	// DefaultWorkers is the default number of workers of the queue requests and of the commands.
+	DefaultWorkers = 1
+
+	// DefaultCommandQueueWaitTimeout is the default time a command waits for its target queue to be created.
+	DefaultCommandQueueWaitTimeout = time.Minute
+
+	// queueFinalizer is added to queues so that they are drained before deletion.
+	queueFinalizer = "volcano.sh/queue-controller"
+
+	// maxQueueConditions is the number of the latest state transitions kept in the status of queue.
+	maxQueueConditions = 10
+
+	// commandQueueIndex is the index of the cached commands by the name of their target queue.
+	commandQueueIndex = "queue"
+
+	// priorityClassIndex is the index of the cached podgroups by the name of their PriorityClass.
+	priorityClassIndex = "priorityClass"
 )
 
+// Options is the configuration of queue controller.
+type Options struct {
+	// QueueMaxRetries is the number of times a queue request is retried, 0 means retry forever.
+	QueueMaxRetries int
+	// CommandMaxRetries is the number of times a command is retried, 0 means retry forever.
+	CommandMaxRetries int
+	// CommandBatchSize is the max number of commands targeting the same queue which are
+	// coalesced into one queue request, batching is disabled if it is less than 2.
+	CommandBatchSize int
+	// CommandQueueWaitTimeout is how long after its creation a command targeting a queue which does not
+	// exist yet is kept and retried, 0 means the command is dropped at once.
+	CommandQueueWaitTimeout time.Duration
+	// EventStdout writes the events to stdout as JSON lines in addition to recording them to apiserver.
+	EventStdout bool
+	// EventDedupWindow is the window in which identical events of a queue are suppressed,
+	// 0 means no suppression.
+	EventDedupWindow time.Duration
+	// EventClient writes the events to apiserver, so that the events are rate limited apart from
+	// the other API calls of the controller; the events are written by kubeClient if it is nil.
+	EventClient kubernetes.Interface
+	// RequestClient sends the requests of the controller, it should have a timeout as the requests
+	// abandoned once their context is done are not aborted; the requests are sent by vcClient if
+	// it is nil, which also lists and watches the objects.
+	RequestClient vcclientset.Interface
+	// ResyncPeriod is the resync period of the informers and the interval of the full
+	// reconcile of all queues, 0 means no resync.
+	ResyncPeriod time.Duration
+	// RetryMaxDelay is the upper limit of the delay before a failed queue request or command is retried.
+	RetryMaxDelay time.Duration
+	// StructuredLogging appends the queue, action and event of the requests to the logs as key-value pairs.
+	StructuredLogging bool
+	// WatchNamespaces are the namespaces whose podgroups are counted in the status of queues,
+	// empty means all namespaces. Queues are cluster scoped and always watched.
+	WatchNamespaces []string
+	// IdleTimeout is the duration after which an open queue without pending or running podgroups
+	// is closed, 0 means queues are never closed as idle.
+	IdleTimeout time.Duration
+	// QueueWorkers is the number of workers handling the queue requests concurrently.
+	QueueWorkers int
+	// CommandWorkers is the number of workers handling the commands of every kind concurrently.
+	CommandWorkers int
+	// ShutdownDrainTimeout is how long the workers keep handling the queued requests and commands
+	// once the controller is stopped, 0 means they are dropped at once.
+	ShutdownDrainTimeout time.Duration
+	// WeightAdjustPeriod is the interval of adjusting the effective weights of queues by their
+	// historical usage, 0 means the scheduler always uses spec.weight.
+	WeightAdjustPeriod time.Duration
+	// WeightMinRatio and WeightMaxRatio bound the effective weight of a queue relative to its spec.weight.
+	WeightMinRatio float64
+	WeightMaxRatio float64
+	// UsageSnapshotPeriod is the interval of exporting the usage snapshot of all queues, 0 disables it.
+	UsageSnapshotPeriod time.Duration
+	// UsageSnapshotConfigMap is the namespace/name of the ConfigMap the usage snapshot is written to.
+	UsageSnapshotConfigMap string
+	// Identity identifies the controller in the usage snapshot.
+	Identity string
+	// PauseConfigMap is the namespace/name of the ConfigMap whose annotation PausedAnnotationKey
+	// pauses the reconciliation of queues, empty means the reconciliation is never paused.
+	PauseConfigMap string
+	// Tracer creates a span of every queue request and command handled, nil disables tracing.
+	Tracer *tracing.Tracer
+	// DeadLetterConfigMap is the namespace/name of the ConfigMap the dropped queue requests are
+	// recorded to, empty means they are only logged.
+	DeadLetterConfigMap string
+	// DefaultState is the state of the new queues which do not set spec.state, Open or Closed;
+	// empty means Open.
+	DefaultState schedulingv1alpha2.QueueState
+}
+
+// NewOptions creates Options with default values.
+func NewOptions() Options {
+	return Options{
+		QueueMaxRetries:   DefaultMaxRetries,
+		CommandMaxRetries: DefaultMaxRetries,
+		EventDedupWindow:  DefaultEventDedupWindow,
+		RetryMaxDelay:     DefaultRetryMaxDelay,
+		QueueWorkers:      DefaultWorkers,
+		CommandWorkers:    DefaultWorkers,
+		WeightMinRatio:    DefaultWeightMinRatio,
+		WeightMaxRatio:    DefaultWeightMaxRatio,
+
+		CommandQueueWaitTimeout: DefaultCommandQueueWaitTimeout,
+	}
+}
+
+// commandRoute holds the workqueue and the handler of the commands targeting one kind of object,
+// so that the commands of a kind are not delayed by the failing commands of other kinds.
+type commandRoute struct {
+	// name is the label of the workqueue in metrics.
+	name    string
+	matches func(cmd *busv1alpha1.Command) bool
+	queue   workqueue.RateLimitingInterface
+	handler func(ctx context.Context, cmd *busv1alpha1.Command) error
+	// backlog tracks the commands in the workqueue for their age, nil if the age is not exported.
+	backlog *commandBacklog
+}
+
 // Controller manages queue status.
 type Controller struct {
 	kubeClient kubernetes.Interface
@@ -73,38 +203,137 @@ type Controller struct {
 	cmdLister   busv1alpha1lister.CommandLister
 	cmdSynced   cache.InformerSynced
 
+	pcInformer schedulingv1beta1informer.PriorityClassInformer
+	pcLister   schedulingv1beta1lister.PriorityClassLister
+	pcSynced   cache.InformerSynced
+
+	// nodes are watched to resolve the capability of queues relative to the cluster.
+	nodeInformer coreinformer.NodeInformer
+	nodeLister   corelister.NodeLister
+	nodeSynced   cache.InformerSynced
+
+	// the pause ConfigMap is watched to pause the reconciliation of queues, nil if it is not set.
+	pauseInformer  cache.SharedIndexInformer
+	pauseConfigMap string
+	// paused is set to 1 while the reconciliation of queues is paused.
+	paused int32
+
+	tracer *tracing.Tracer
+
+	// deadLetterConfigMap is the namespace/name of the ConfigMap of the dropped queue requests.
+	deadLetterConfigMap string
+
+	// defaultState is the state of the new queues which do not set spec.state.
+	defaultState schedulingv1alpha2.QueueState
+
 	// queues that need to be updated.
-	queue        workqueue.RateLimitingInterface
-	commandQueue workqueue.RateLimitingInterface
+	queue           workqueue.RateLimitingInterface
+	commandQueue    workqueue.RateLimitingInterface
+	commandAges     *commandBacklog
+	jobCommandQueue workqueue.RateLimitingInterface
+
+	// queueLocks serialize the handling of each queue by the workers.
+	queueLocks *queueLocks
+	// batchedCommands are the commands coalesced into applied batches.
+	batchedCommands *batchedCommands
+
+	// commandRoutes dispatch the commands by the kind of their target object.
+	commandRoutes []*commandRoute
 
 	pgMutex sync.RWMutex
 	// queue name -> podgroup namespace/name
 	podGroups map[string]map[string]struct{}
 
-	syncHandler        func(req *schedulingv1alpha2.QueueRequest) error
-	syncCommandHandler func(cmd *busv1alpha1.Command) error
+	queueMutex sync.RWMutex
+	// parent queue name -> child queue names
+	children map[string]map[string]struct{}
+
+	syncHandler        func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error
+	syncCommandHandler func(ctx context.Context, cmd *busv1alpha1.Command) error
 
 	enqueueQueue func(req *schedulingv1alpha2.QueueRequest)
 
 	recorder record.EventRecorder
+
+	// ready is set to 1 once the caches are synced.
+	ready int32
+
+	queueMaxRetries   int
+	commandMaxRetries int
+	commandBatchSize  int
+	resyncPeriod      time.Duration
+
+	commandQueueWaitTimeout time.Duration
+
+	structuredLogging bool
+	queueWorkers      int
+	commandWorkers    int
+
+	// watchNamespaces are the namespaces of the podgroups handled by the controller, nil means all.
+	watchNamespaces map[string]struct{}
+
+	idleTimeout time.Duration
+	idleMutex   sync.Mutex
+	// queue name -> the last time the queue had pending or running podgroups
+	lastActive map[string]time.Time
+
+	shutdownDrainTimeout time.Duration
+
+	weightAdjustPeriod time.Duration
+	weightMinRatio     float64
+	weightMaxRatio     float64
+	// queue name -> the moving average of the share of the queue in the usage of its siblings,
+	// only accessed by the goroutine of adjustWeights
+	usageShares map[string]float64
+
+	usageSnapshotPeriod    time.Duration
+	usageSnapshotConfigMap string
+	identity               string
 }
 
 // NewQueueController creates a QueueController
 func NewQueueController(
 	kubeClient kubernetes.Interface,
 	vcClient vcclientset.Interface,
+	opt Options,
 ) *Controller {
-	factory := informerfactory.NewSharedInformerFactory(vcClient, 0)
+	factory := informerfactory.NewSharedInformerFactory(vcClient, opt.ResyncPeriod)
 	queueInformer := factory.Scheduling().V1alpha2().Queues()
 	pgInformer := factory.Scheduling().V1alpha2().PodGroups()
 
+	watchNamespaces := newNamespaceSet(opt.WatchNamespaces)
+	if len(watchNamespaces) == 1 {
+		// The informer of a single namespace only lists and watches the podgroups in it;
+		// several namespaces share the cluster wide informer, which is filtered by the handlers
+		// and by the lister.
+		pgInformer = informerfactory.NewSharedInformerFactoryWithOptions(vcClient, opt.ResyncPeriod,
+			informerfactory.WithNamespace(opt.WatchNamespaces[0])).Scheduling().V1alpha2().PodGroups()
+	}
+
+	retryMaxDelay := opt.RetryMaxDelay
+	if retryMaxDelay <= 0 {
+		retryMaxDelay = DefaultRetryMaxDelay
+	}
+
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(klog.Infof)
-	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	eventClient := opt.EventClient
+	if eventClient == nil {
+		eventClient = kubeClient
+	}
+	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: eventClient.CoreV1().Events("")})
+	if opt.EventStdout {
+		startJSONEventSink(eventBroadcaster, os.Stdout)
+	}
+
+	requestClient := opt.RequestClient
+	if requestClient == nil {
+		requestClient = vcClient
+	}
 
 	c := &Controller{
 		kubeClient: kubeClient,
-		vcClient:   vcClient,
+		vcClient:   requestClient,
 
 		queueInformer: queueInformer,
 		pgInformer:    pgInformer,
@@ -112,15 +341,57 @@ func NewQueueController(
 		queueLister: queueInformer.Lister(),
 		queueSynced: queueInformer.Informer().HasSynced,
 
-		pgLister: pgInformer.Lister(),
+		pgLister: newPodGroupLister(pgInformer.Lister(), watchNamespaces),
 		pgSynced: pgInformer.Informer().HasSynced,
 
-		queue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
-		commandQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		queue:           workqueue.NewRateLimitingQueue(newRateLimiter(retryMaxDelay)),
+		commandQueue:    workqueue.NewRateLimitingQueue(newRateLimiter(retryMaxDelay)),
+		commandAges:     newCommandBacklog(),
+		jobCommandQueue: workqueue.NewRateLimitingQueue(newRateLimiter(retryMaxDelay)),
+
+		queueLocks:      newQueueLocks(),
+		batchedCommands: newBatchedCommands(),
 
 		podGroups: make(map[string]map[string]struct{}),
+		children:  make(map[string]map[string]struct{}),
+
+		recorder: newDedupRecorder(eventBroadcaster.NewRecorder(versionedscheme.Scheme, v1.EventSource{Component: "vc-controllers"}),
+			opt.EventDedupWindow),
+
+		queueMaxRetries:   opt.QueueMaxRetries,
+		commandMaxRetries: opt.CommandMaxRetries,
+		commandBatchSize:  opt.CommandBatchSize,
+		resyncPeriod:      opt.ResyncPeriod,
+
+		commandQueueWaitTimeout: opt.CommandQueueWaitTimeout,
+
+		structuredLogging: opt.StructuredLogging,
+		queueWorkers:      opt.QueueWorkers,
+		commandWorkers:    opt.CommandWorkers,
+
+		watchNamespaces: watchNamespaces,
+
+		idleTimeout: opt.IdleTimeout,
+		lastActive:  make(map[string]time.Time),
+
+		shutdownDrainTimeout: opt.ShutdownDrainTimeout,
 
-		recorder: eventBroadcaster.NewRecorder(versionedscheme.Scheme, v1.EventSource{Component: "vc-controllers"}),
+		weightAdjustPeriod: opt.WeightAdjustPeriod,
+		weightMinRatio:     opt.WeightMinRatio,
+		weightMaxRatio:     opt.WeightMaxRatio,
+		usageShares:        make(map[string]float64),
+
+		usageSnapshotPeriod:    opt.UsageSnapshotPeriod,
+		usageSnapshotConfigMap: opt.UsageSnapshotConfigMap,
+		identity:               opt.Identity,
+
+		pauseConfigMap: opt.PauseConfigMap,
+
+		tracer: opt.Tracer,
+
+		deadLetterConfigMap: opt.DeadLetterConfigMap,
+
+		defaultState: opt.DefaultState,
 	}
 
 	queueInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -134,14 +405,17 @@ func NewQueueController(
 		UpdateFunc: c.updatePodGroup,
 		DeleteFunc: c.deletePodGroup,
 	})
+	if err := pgInformer.Informer().AddIndexers(cache.Indexers{priorityClassIndex: indexPodGroupByPriorityClass}); err != nil {
+		klog.Errorf("Failed to index podgroups by PriorityClass: %v.", err)
+	}
 
-	c.cmdInformer = informerfactory.NewSharedInformerFactory(c.vcClient, 0).Bus().V1alpha1().Commands()
+	c.cmdInformer = informerfactory.NewSharedInformerFactory(vcClient, 0).Bus().V1alpha1().Commands()
 	c.cmdInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: func(obj interface{}) bool {
 			switch obj.(type) {
 			case *busv1alpha1.Command:
 				cmd := obj.(*busv1alpha1.Command)
-				return IsQueueReference(cmd.TargetObject)
+				return c.routeCommand(cmd) != nil
 			default:
 				return false
 			}
@@ -150,17 +424,79 @@ func NewQueueController(
 			AddFunc: c.addCommand,
 		},
 	})
+	if err := c.cmdInformer.Informer().AddIndexers(cache.Indexers{commandQueueIndex: indexCommandByQueue}); err != nil {
+		klog.Errorf("Failed to index commands by queue: %v.", err)
+	}
 	c.cmdLister = c.cmdInformer.Lister()
 	c.cmdSynced = c.cmdInformer.Informer().HasSynced
 
+	c.pcInformer = informers.NewSharedInformerFactory(kubeClient, opt.ResyncPeriod).Scheduling().V1beta1().PriorityClasses()
+	c.pcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addPriorityClass,
+		UpdateFunc: c.updatePriorityClass,
+		DeleteFunc: c.deletePriorityClass,
+	})
+	c.pcLister = c.pcInformer.Lister()
+	c.pcSynced = c.pcInformer.Informer().HasSynced
+
+	c.nodeInformer = informers.NewSharedInformerFactory(kubeClient, opt.ResyncPeriod).Core().V1().Nodes()
+	c.nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addNode,
+		UpdateFunc: c.updateNode,
+		DeleteFunc: c.deleteNode,
+	})
+	c.nodeLister = c.nodeInformer.Lister()
+	c.nodeSynced = c.nodeInformer.Informer().HasSynced
+
+	if opt.PauseConfigMap != "" {
+		c.pauseInformer = newPauseInformer(kubeClient, opt.PauseConfigMap, opt.ResyncPeriod)
+	}
+	if c.pauseInformer != nil {
+		c.pauseInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPauseConfigMap,
+			UpdateFunc: c.updatePauseConfigMap,
+			DeleteFunc: c.deletePauseConfigMap,
+		})
+	}
+
 	queuestate.SyncQueue = c.syncQueue
 	queuestate.OpenQueue = c.openQueue
 	queuestate.CloseQueue = c.closeQueue
+	queuestate.DrainQueue = c.drainQueue
+	queuestate.CordonQueue = c.cordonQueueAction(true)
+	queuestate.UncordonQueue = c.cordonQueueAction(false)
 
 	c.syncHandler = c.handleQueue
 	c.syncCommandHandler = c.handleCommand
+	if c.commandBatchSize > 1 {
+		c.syncCommandHandler = c.handleCommandBatch
+	}
+
+	c.commandRoutes = []*commandRoute{
+		{
+			name: commandWorkqueue,
+			matches: func(cmd *busv1alpha1.Command) bool {
+				return helpers.IsQueueReference(cmd.TargetObject)
+			},
+			queue: c.commandQueue,
+			handler: func(ctx context.Context, cmd *busv1alpha1.Command) error {
+				return c.syncCommandHandler(ctx, cmd)
+			},
+			backlog: c.commandAges,
+		},
+		{
+			name: jobCommandWorkqueue,
+			matches: func(cmd *busv1alpha1.Command) bool {
+				return IsJobReference(cmd.TargetObject) && c.isWatchedNamespace(cmd.Namespace)
+			},
+			queue:   c.jobCommandQueue,
+			handler: c.handleJobCommand,
+		},
+	}
 
-	c.enqueueQueue = c.enqueue
+	c.enqueueQueue = func(req *schedulingv1alpha2.QueueRequest) {
+		c.queue.Add(req)
+	}
 
 	return c
 }
@@ -168,8 +504,10 @@ func NewQueueController(
 // Run starts QueueController
 func (c *Controller) Run(stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
-	defer c.commandQueue.ShutDown()
+	defer shutDown(c.queue)
+	for _, route := range c.commandRoutes {
+		defer shutDown(route.queue)
+	}
 
 	klog.Infof("Starting queue controller.")
 	defer klog.Infof("Shutting down queue controller.")
@@ -177,28 +515,98 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	go c.queueInformer.Informer().Run(stopCh)
 	go c.pgInformer.Informer().Run(stopCh)
 	go c.cmdInformer.Informer().Run(stopCh)
+	go c.pcInformer.Informer().Run(stopCh)
+	go c.nodeInformer.Informer().Run(stopCh)
+
+	synced := []cache.InformerSynced{c.queueSynced, c.pgSynced, c.cmdSynced, c.pcSynced, c.nodeSynced}
+	if c.pauseInformer != nil {
+		// The pause ConfigMap is synced before the workers start, so a paused reconciliation
+		// stays paused across restarts.
+		go c.pauseInformer.Run(stopCh)
+		synced = append(synced, c.pauseInformer.HasSynced)
+		go wait.Until(c.logPaused, pausedLogPeriod, stopCh)
+	}
 
-	if !cache.WaitForCacheSync(stopCh, c.queueSynced, c.pgSynced, c.cmdSynced) {
+	if !cache.WaitForCacheSync(stopCh, synced...) {
 		klog.Errorf("unable to sync caches for queue controller.")
 		return
 	}
 
-	go wait.Until(c.worker, 0, stopCh)
-	go wait.Until(c.commandWorker, 0, stopCh)
+	if pruned := c.pruneStalePodGroups(); pruned > 0 {
+		klog.Infof("Pruned %d podgroups which do not exist any more.", pruned)
+	}
+	atomic.StoreInt32(&c.ready, 1)
+
+	// ctx is cancelled once the workqueues are drained or the drain times out after stopCh is
+	// closed, so in-flight API calls do not block the shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The workers are safe to run concurrently: the requests of a queue are distinct items of the
+	// workqueue, so they are serialized by the lock of the queue rather than by the workqueue. The
+	// workers exit once their workqueues are shut down and empty, rather than on stopCh, so that the
+	// queued items are drained.
+	var queueWorkers, commandWorkers sync.WaitGroup
+	for i := 0; i < workers(c.queueWorkers); i++ {
+		queueWorkers.Add(1)
+		go func() {
+			defer queueWorkers.Done()
+			c.worker(ctx)
+		}()
+	}
+	for _, route := range c.commandRoutes {
+		route := route
+		for i := 0; i < workers(c.commandWorkers); i++ {
+			commandWorkers.Add(1)
+			go func() {
+				defer commandWorkers.Done()
+				c.commandWorker(ctx, route)
+			}()
+		}
+	}
+
+	// Resync of the informers is ignored by updateQueue as the resource version does not
+	// change, so all the queues are enqueued periodically in case any event is missed.
+	if c.resyncPeriod > 0 {
+		go wait.Until(c.enqueueAllQueues, c.resyncPeriod, stopCh)
+	}
+
+	if c.idleTimeout > 0 {
+		go wait.Until(func() { c.closeIdleQueues(time.Now()) }, idleCheckPeriod(c.idleTimeout), stopCh)
+	}
+
+	if c.weightAdjustPeriod > 0 {
+		go wait.Until(func() { c.adjustWeights(ctx) }, c.weightAdjustPeriod, stopCh)
+	} else {
+		c.resetEffectiveWeights(ctx)
+	}
+
+	if c.usageSnapshotPeriod > 0 {
+		go wait.Until(func() { c.exportUsageSnapshot(ctx) }, c.usageSnapshotPeriod, stopCh)
+	}
+
+	go wait.Until(c.updateCommandBacklogAge, backlogAgePeriod, stopCh)
 
 	<-stopCh
+
+	c.drain(&commandWorkers, &queueWorkers)
+}
+
+// Ready returns whether the caches of QueueController are synced.
+func (c *Controller) Ready() bool {
+	return atomic.LoadInt32(&c.ready) == 1
 }
 
 // worker runs a worker thread that just dequeues items, processes them, and
 // marks them done. You may run as many of these in parallel as you wish; the
-// workqueue guarantees that they will not end up processing the same `queue`
-// at the same time.
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
+// lock of the queue guarantees that they will not end up processing the same
+// `queue` at the same time.
+func (c *Controller) worker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
 	}
 }
 
-func (c *Controller) processNextWorkItem() bool {
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	obj, shutdown := c.queue.Get()
 	if shutdown {
 		return false
@@ -207,40 +615,104 @@ func (c *Controller) processNextWorkItem() bool {
 
 	req, ok := obj.(*schedulingv1alpha2.QueueRequest)
 	if !ok {
-		klog.Errorf("%v is not a valid queue request struct.", obj)
+		c.errorf(nil, "%v is not a valid queue request struct.", obj)
 		return true
 	}
 
-	err := c.syncHandler(req)
+	// While paused, the requests are requeued as they are, keeping their retries, and handled once
+	// resumed; so are the commands.
+	if c.isPaused() {
+		c.queue.AddAfter(obj, pausedRequeueDelay)
+		return true
+	}
+
+	spanCtx, span := c.tracer.Start(ctx, "handleQueue",
+		tracing.Attr("queue", req.Name),
+		tracing.Attr("action", string(req.Action)),
+		tracing.Attr("event", string(req.Event)))
+	unlock := c.queueLocks.lock(req.Name)
+	err := c.syncHandler(spanCtx, req)
+	unlock()
+	span.End(err)
 	c.handleQueueErr(err, obj)
 
 	return true
 }
 
-func (c *Controller) handleQueue(req *schedulingv1alpha2.QueueRequest) error {
+func (c *Controller) handleQueue(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
 	startTime := time.Now()
 	defer func() {
-		klog.V(4).Infof("Finished syncing queue %s (%v).", req.Name, time.Since(startTime))
+		c.infof(4, requestFields(req, "duration", time.Since(startTime)),
+			"Finished syncing queue %s (%v).", req.Name, time.Since(startTime))
+		updateQueueSyncDuration(string(req.Action), time.Since(startTime))
+		updateWorkqueueDepth(queueWorkqueue, c.queue.Len())
 	}()
 
+	// Requests are validated when they are enqueued; a request built otherwise with an
+	// unknown action is dropped as it would never succeed.
+	if err := schedulingv1alpha2.ValidateQueueAction(req.Action); err != nil {
+		c.errorf(requestFields(req, "err", err), "Dropping request of queue %s: %v.", req.Name, err)
+		return nil
+	}
+
 	queue, err := c.queueLister.Get(req.Name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			klog.V(4).Infof("Queue %s has been deleted.", req.Name)
-			return nil
+			return &ErrQueueNotFound{Queue: req.Name}
 		}
 
 		return fmt.Errorf("get queue %s failed for %v", req.Name, err)
 	}
 
+	if err := c.validateParent(queue); err != nil {
+		c.recorder.Event(queue, v1.EventTypeWarning, "InvalidParent", err.Error())
+	}
+
+	if err := c.validatePriority(queue); err != nil {
+		c.recorder.Event(queue, v1.EventTypeWarning, "InvalidPriority", err.Error())
+	}
+
+	// Queue will be synced again by the update event, while the action of a command is applied at
+	// once, as the command is deleted after it is handled.
+	if queue.DeletionTimestamp == nil && !hasFinalizer(queue) {
+		if err := c.addFinalizer(ctx, queue); err != nil {
+			return &ErrStateExecution{Queue: req.Name, Event: req.Event, Action: req.Action, Err: err}
+		}
+		if req.Event != schedulingv1alpha2.QueueCommandIssuedEvent {
+			return nil
+		}
+
+		if err := callWithContext(ctx, func() (err error) {
+			queue, err = c.vcClient.SchedulingV1alpha2().Queues().Get(req.Name, metav1.GetOptions{})
+			return err
+		}); err != nil {
+			if apierrors.IsNotFound(err) {
+				return &ErrQueueNotFound{Queue: req.Name}
+			}
+			return &ErrStateExecution{Queue: req.Name, Event: req.Event, Action: req.Action, Err: err}
+		}
+	}
+
+	if queue.DeletionTimestamp == nil && ownPodGroups(queue) {
+		if err := c.adoptPodGroups(ctx, queue); err != nil {
+			return &ErrStateExecution{Queue: req.Name, Event: req.Event, Action: req.Action, Err: err}
+		}
+	}
+
+	// A podgroup may be created in the queue after it was found idle.
+	if req.Event == schedulingv1alpha2.QueueIdleEvent && c.hasActivePodGroups(queue.Name) {
+		c.infof(4, requestFields(req), "Queue %s is not idle any more.", req.Name)
+		return nil
+	}
+
 	queueState := queuestate.NewState(queue)
 	if queueState == nil {
-		return fmt.Errorf("queue %s state %s is invalid", queue.Name, queue.Status.State)
+		return &ErrInvalidState{Queue: queue.Name, State: queue.Status.State}
 	}
 
-	if err := queueState.Execute(req.Action); err != nil {
-		return fmt.Errorf("sync queue %s failed for %v, event is %v, action is %s",
-			req.Name, err, req.Event, req.Action)
+	ctx = withQueueEvent(withQueueAction(ctx, req.Action), req.Event)
+	if err := queueState.Execute(ctx, req.Action); err != nil {
+		return &ErrStateExecution{Queue: req.Name, Event: req.Event, Action: req.Action, Err: err}
 	}
 
 	return nil
@@ -252,50 +724,150 @@ func (c *Controller) handleQueueErr(err error, obj interface{}) {
 		return
 	}
 
-	if c.queue.NumRequeues(obj) < maxRetries {
-		klog.V(4).Infof("Error syncing queue request %v for %v.", obj, err)
+	req, ok := obj.(*schedulingv1alpha2.QueueRequest)
+	fields := requestFields(req, "err", err)
+
+	if notFound, deleted := err.(*ErrQueueNotFound); deleted {
+		c.infof(4, fields, "Queue %s has been deleted.", notFound.Queue)
+		c.queue.Forget(obj)
+		return
+	}
+
+	if ok {
+		registerQueueSyncError(string(req.Action))
+	}
+
+	// Permanent failures are reported and dropped at once rather than retried in vain.
+	if !isRetriable(err) {
+		if ok {
+			c.recordEventsForQueue(req.Name, v1.EventTypeWarning, string(req.Action),
+				fmt.Sprintf("%v queue failed for %v", req.Action, err))
+		}
+		c.infof(2, fields, "Dropping queue request %v out of the queue for permanent error %v.", obj, err)
+		c.recordDeadLetter(req, err)
+		c.queue.Forget(obj)
+		return
+	}
+
+	if c.queueMaxRetries == 0 || c.queue.NumRequeues(obj) < c.queueMaxRetries {
+		c.infof(4, fields, "Error syncing queue request %v for %v.", obj, err)
 		c.queue.AddRateLimited(obj)
 		return
 	}
 
-	req, _ := obj.(*schedulingv1alpha2.QueueRequest)
-	c.recordEventsForQueue(req.Name, v1.EventTypeWarning, string(req.Action),
-		fmt.Sprintf("%v queue failed for %v", req.Action, err))
-	klog.V(2).Infof("Dropping queue request %v out of the queue for %v.", obj, err)
+	if ok {
+		c.recordEventsForQueue(req.Name, v1.EventTypeWarning, string(req.Action),
+			fmt.Sprintf("%v queue failed for %v", req.Action, err))
+	}
+	c.infof(2, fields, "Dropping queue request %v out of the queue for %v.", obj, err)
+	c.recordDeadLetter(req, err)
 	c.queue.Forget(obj)
 }
 
-func (c *Controller) commandWorker() {
-	for c.processNextCommand() {
+// routeCommand returns the route of the command by the kind of its target object,
+// or nil if the command is not handled by queue controller.
+func (c *Controller) routeCommand(cmd *busv1alpha1.Command) *commandRoute {
+	for _, route := range c.commandRoutes {
+		if route.matches(cmd) {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) commandWorker(ctx context.Context, route *commandRoute) {
+	for c.processNextCommand(ctx, route) {
 	}
 }
 
-func (c *Controller) processNextCommand() bool {
-	obj, shutdown := c.commandQueue.Get()
+func (c *Controller) processNextCommand(ctx context.Context, route *commandRoute) bool {
+	obj, shutdown := route.queue.Get()
 	if shutdown {
 		return false
 	}
-	defer c.commandQueue.Done(obj)
+	defer route.queue.Done(obj)
 
 	cmd, ok := obj.(*busv1alpha1.Command)
 	if !ok {
-		klog.Errorf("%v is not a valid Command struct.", obj)
+		c.errorf(nil, "%v is not a valid Command struct.", obj)
 		return true
 	}
 
-	err := c.syncCommandHandler(cmd)
-	c.handleCommandErr(err, obj)
+	// The span of the command joins the trace of the operation which created the command.
+	spanCtx, span := c.tracer.Start(tracing.ContextWithTraceParent(ctx, cmd.Annotations[tracing.TraceParentAnnotationKey]),
+		"handleCommand",
+		tracing.Attr("command", cmd.Namespace+"/"+cmd.Name),
+		tracing.Attr("route", route.name),
+		tracing.Attr("target", cmd.TargetObject.Name),
+		tracing.Attr("action", cmd.Action))
+	err := route.handler(spanCtx, cmd)
+	span.End(err)
+	c.handleCommandErr(route, err, obj)
+	if route.backlog != nil {
+		c.updateCommandBacklogAge()
+	}
 
 	return true
 }
 
-func (c *Controller) handleCommand(cmd *busv1alpha1.Command) error {
+func (c *Controller) handleCommand(ctx context.Context, cmd *busv1alpha1.Command) error {
+	startTime := time.Now()
+	defer func() {
+		c.infof(4, commandFields(cmd, "duration", time.Since(startTime)),
+			"Finished syncing command %s/%s (%v).", cmd.Namespace, cmd.Name, time.Since(startTime))
+		updateWorkqueueDepth(commandWorkqueue, c.commandQueue.Len())
+	}()
+
+	if err := c.checkCommandQueue(cmd.TargetObject.Name, cmd); err != nil {
+		return err
+	}
+
+	unlock := c.queueLocks.lock(cmd.TargetObject.Name)
+	defer unlock()
+
+	// The command is deleted only after its action is applied, so the action is not lost if the controller
+	// restarts in between: the command is handled again after restart.
+	if err := c.applyCommand(ctx, cmd.TargetObject.Name, cmd); err != nil {
+		return err
+	}
+
+	return c.deleteCommand(ctx, cmd)
+}
+
+// handleJobCommand resyncs the queue of the job targeted by a command, so that the status of the
+// queue follows the job control, e.g. an aborted job, at once. The command is executed and deleted
+// by job controller; its action is never applied to the queue, as a namespaced object must not
+// change the state of the cluster scoped queue.
+func (c *Controller) handleJobCommand(ctx context.Context, cmd *busv1alpha1.Command) error {
 	startTime := time.Now()
 	defer func() {
-		klog.V(4).Infof("Finished syncing command %s/%s (%v).", cmd.Namespace, cmd.Name, time.Since(startTime))
+		c.infof(4, commandFields(cmd, "duration", time.Since(startTime)),
+			"Finished syncing job command %s/%s (%v).", cmd.Namespace, cmd.Name, time.Since(startTime))
+		updateWorkqueueDepth(jobCommandWorkqueue, c.jobCommandQueue.Len())
 	}()
 
-	err := c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Delete(cmd.Name, nil)
+	// The podgroup of a job is named after the job.
+	pg, err := c.pgLister.PodGroups(cmd.Namespace).Get(cmd.TargetObject.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get podgroup of job %s/%s failed for %v", cmd.Namespace, cmd.TargetObject.Name, err)
+	}
+
+	c.infof(4, commandFields(cmd), "Command %s of job %s/%s is issued, syncing queue %s.",
+		cmd.Action, cmd.Namespace, cmd.TargetObject.Name, pg.Spec.Queue)
+	c.enqueue(pg.Spec.Queue, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+
+	return nil
+}
+
+// deleteCommand deletes the handled command, so that it is not handled again.
+func (c *Controller) deleteCommand(ctx context.Context, cmd *busv1alpha1.Command) error {
+	err := callWithContext(ctx, func() error {
+		return c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Delete(cmd.Name, nil)
+	})
 	if err != nil {
 		if true == apierrors.IsNotFound(err) {
 			return nil
@@ -304,29 +876,289 @@ func (c *Controller) handleCommand(cmd *busv1alpha1.Command) error {
 		return fmt.Errorf("failed to delete command <%s/%s> for %v", cmd.Namespace, cmd.Name, err)
 	}
 
-	req := &schedulingv1alpha2.QueueRequest{
-		Name:   cmd.TargetObject.Name,
-		Event:  schedulingv1alpha2.QueueCommandIssuedEvent,
-		Action: schedulingv1alpha2.QueueAction(cmd.Action),
+	return nil
+}
+
+// recordCommand records the command as the last command of the queue, so that the administrative
+// actions on the queue can be audited after the command is deleted.
+func (c *Controller) recordCommand(ctx context.Context, name string, cmd *busv1alpha1.Command) error {
+	// Status of queue is updated by the action of the command, get the latest one.
+	var queue *schedulingv1alpha2.Queue
+	err := callWithContext(ctx, func() (err error) {
+		queue, err = c.vcClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("get queue %s failed for %v", name, err)
+	}
+
+	newQueue := queue.DeepCopy()
+	newQueue.Status.LastCommand = &schedulingv1alpha2.QueueCommandRecord{
+		Action:  schedulingv1alpha2.QueueAction(cmd.Action),
+		Command: commandKey(cmd),
+		Creator: cmd.Annotations[busv1alpha1.CommandCreatorAnnotationKey],
+		Time:    metav1.Now(),
+	}
+
+	if err := callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue)
+		return err
+	}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("record command <%s/%s> in queue %s failed for %v", cmd.Namespace, cmd.Name, name, err)
+	}
+
+	return nil
+}
+
+// queueNotFoundError is returned for a command whose target queue does not exist yet, the command is
+// kept and retried with backoff until the queue is created or the command expires.
+type queueNotFoundError struct {
+	queue string
+}
+
+func (e *queueNotFoundError) Error() string {
+	return fmt.Sprintf("queue %s is not found", e.queue)
+}
+
+// checkCommandQueue returns a queueNotFoundError if the target queue of the command does not exist
+// and the command was created within commandQueueWaitTimeout, as the command may arrive slightly
+// before the queue is created. The expiry of the command is reported, which is then handled as before.
+func (c *Controller) checkCommandQueue(queue string, cmd *busv1alpha1.Command) error {
+	if c.commandQueueWaitTimeout <= 0 {
+		return nil
+	}
+	if _, err := c.queueLister.Get(queue); err == nil || !apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if time.Since(cmd.CreationTimestamp.Time) < c.commandQueueWaitTimeout {
+		return &queueNotFoundError{queue: queue}
+	}
+
+	c.recorder.Event(cmd, v1.EventTypeWarning, "QueueNotFound",
+		fmt.Sprintf("Queue %s is not created within %v, command <%s/%s> is dropped",
+			queue, c.commandQueueWaitTimeout, cmd.Namespace, cmd.Name))
+	return nil
+}
+
+// applyCommand applies the action of the command to the queue and records the command in the status of
+// the queue, the caller holds the lock of the queue. A command which was applied already, whose action is
+// invalid or fails permanently, or whose queue is deleted, is not applied again, so that it is deleted
+// rather than retried in vain.
+func (c *Controller) applyCommand(ctx context.Context, queue string, cmd *busv1alpha1.Command) error {
+	req, err := schedulingv1alpha2.NewQueueRequest(queue,
+		schedulingv1alpha2.QueueCommandIssuedEvent, schedulingv1alpha2.QueueAction(cmd.Action))
+	if err != nil {
+		c.errorf(commandFields(cmd, "err", err), "Dropping command <%s/%s>: %v.", cmd.Namespace, cmd.Name, err)
+		c.recordEventsForQueue(queue, v1.EventTypeWarning, "InvalidCommand",
+			fmt.Sprintf("Command <%s/%s> is dropped for %v", cmd.Namespace, cmd.Name, err))
+		return nil
+	}
+
+	// The command was applied and recorded before the controller restarted, it is only left to be deleted.
+	if q, err := c.queueLister.Get(queue); err == nil &&
+		q.Status.LastCommand != nil && q.Status.LastCommand.Command == commandKey(cmd) {
+		c.infof(4, commandFields(cmd), "Command <%s/%s> is applied already.", cmd.Namespace, cmd.Name)
+		return nil
+	}
+
+	if c.isPaused() {
+		return errPaused
+	}
+
+	if err := c.syncHandler(ctx, req); err != nil {
+		fields := commandFields(cmd, "err", err)
+		if _, deleted := err.(*ErrQueueNotFound); deleted {
+			c.infof(4, fields, "Queue %s of command <%s/%s> has been deleted.", queue, cmd.Namespace, cmd.Name)
+			return nil
+		}
+
+		registerQueueSyncError(string(req.Action))
+		if isRetriable(err) {
+			return err
+		}
+
+		c.recordEventsForQueue(queue, v1.EventTypeWarning, string(req.Action),
+			fmt.Sprintf("%v queue failed for %v", req.Action, err))
+		c.infof(2, fields, "Dropping command <%s/%s> for permanent error %v.", cmd.Namespace, cmd.Name, err)
+		c.recordDeadLetter(req, err)
+		return nil
+	}
+
+	return c.recordCommand(ctx, queue, cmd)
+}
+
+// handleCommandBatch coalesces the command with other commands targeting the same queue
+// into one queue request with the action of the latest command.
+func (c *Controller) handleCommandBatch(ctx context.Context, cmd *busv1alpha1.Command) error {
+	startTime := time.Now()
+	defer func() {
+		c.infof(4, commandFields(cmd, "duration", time.Since(startTime)),
+			"Finished syncing command batch of %s/%s (%v).", cmd.Namespace, cmd.Name, time.Since(startTime))
+		updateWorkqueueDepth(commandWorkqueue, c.commandQueue.Len())
+	}()
+
+	// The command was coalesced into a batch applied already, its superseded action is not applied again.
+	if deleted, batched := c.batchedCommands.get(cmd); batched {
+		if !deleted {
+			if err := c.deleteCommand(ctx, cmd); err != nil {
+				return err
+			}
+		}
+		c.batchedCommands.remove(cmd)
+		return nil
 	}
 
-	c.enqueueQueue(req)
+	unlock := c.queueLocks.lock(cmd.TargetObject.Name)
+	defer unlock()
+
+	commands := c.getQueueCommands(cmd)
+	latest := commands[0]
+	for _, command := range commands {
+		if latest.CreationTimestamp.Before(&command.CreationTimestamp) {
+			latest = command
+		}
+	}
+
+	if err := c.checkCommandQueue(latest.TargetObject.Name, latest); err != nil {
+		return err
+	}
+
+	// As handleCommand, the commands are deleted only after the action is applied.
+	if err := c.applyCommand(ctx, latest.TargetObject.Name, latest); err != nil {
+		return err
+	}
+
+	c.batchedCommands.add(commands, false)
+	if err := c.deleteCommands(ctx, latest.TargetObject.Name, commands); err != nil {
+		return err
+	}
+	c.batchedCommands.add(commands[1:], true)
+	c.batchedCommands.remove(cmd)
 
 	return nil
 }
 
-func (c *Controller) handleCommandErr(err error, obj interface{}) {
+// deleteCommands deletes the handled commands of the queue. The commands of a namespace are deleted by
+// one DeleteCollection if they are all the cached commands of the queue in the namespace and are all
+// labeled with the queue; they are deleted one by one otherwise, or if DeleteCollection is not allowed.
+// A command created meanwhile may be deleted by DeleteCollection too, its action is still applied as
+// the command is queued by its add event.
+func (c *Controller) deleteCommands(ctx context.Context, queue string, commands []*busv1alpha1.Command) error {
+	cached := map[string]int{}
+	for _, command := range c.queueCommands(queue) {
+		cached[command.Namespace]++
+	}
+	byNamespace := map[string][]*busv1alpha1.Command{}
+	for _, command := range commands {
+		byNamespace[command.Namespace] = append(byNamespace[command.Namespace], command)
+	}
+
+	var errs []error
+	for namespace, commands := range byNamespace {
+		if len(commands) > 1 && len(commands) == cached[namespace] && labeledWithQueue(commands, queue) {
+			err := callWithContext(ctx, func() error {
+				return c.vcClient.BusV1alpha1().Commands(namespace).DeleteCollection(nil, metav1.ListOptions{
+					LabelSelector: labels.SelectorFromSet(labels.Set{busv1alpha1.QueueNameLabelKey: queue}).String(),
+				})
+			})
+			if err == nil {
+				continue
+			}
+			if !apierrors.IsForbidden(err) && !apierrors.IsMethodNotSupported(err) {
+				errs = append(errs, fmt.Errorf("failed to delete commands of queue %s in namespace %s for %v",
+					queue, namespace, err))
+				continue
+			}
+			klog.V(4).Infof("Deleting commands of queue %s one by one, as DeleteCollection failed for %v.", queue, err)
+		}
+
+		for _, command := range commands {
+			if err := c.deleteCommand(ctx, command); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// getQueueCommands returns the command and up to commandBatchSize-1 other pending commands targeting the same queue.
+func (c *Controller) getQueueCommands(cmd *busv1alpha1.Command) []*busv1alpha1.Command {
+	commands := []*busv1alpha1.Command{cmd}
+
+	for _, command := range c.queueCommands(cmd.TargetObject.Name) {
+		if len(commands) >= c.commandBatchSize {
+			break
+		}
+		if command.Namespace == cmd.Namespace && command.Name == cmd.Name {
+			continue
+		}
+		// The cache may lag behind the deletion of the commands of an applied batch.
+		if _, batched := c.batchedCommands.get(command); batched {
+			continue
+		}
+		commands = append(commands, command)
+	}
+
+	return commands
+}
+
+// queueCommands returns the cached commands targeting the queue, the oldest first.
+func (c *Controller) queueCommands(queue string) []*busv1alpha1.Command {
+	objs, err := c.cmdInformer.Informer().GetIndexer().ByIndex(commandQueueIndex, queue)
+	if err != nil {
+		klog.Errorf("Failed to list commands of queue %s: %v", queue, err)
+		return nil
+	}
+
+	commands := make([]*busv1alpha1.Command, 0, len(objs))
+	for _, obj := range objs {
+		if command, ok := obj.(*busv1alpha1.Command); ok {
+			commands = append(commands, command)
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].CreationTimestamp.Before(&commands[j].CreationTimestamp)
+	})
+
+	return commands
+}
+
+func (c *Controller) handleCommandErr(route *commandRoute, err error, obj interface{}) {
+	cmd, _ := obj.(*busv1alpha1.Command)
 	if err == nil {
-		c.commandQueue.Forget(obj)
+		route.queue.Forget(obj)
+		route.backlog.remove(cmd)
+		return
+	}
+
+	fields := commandFields(cmd, "err", err)
+
+	// While paused, the commands are kept and requeued as they are, keeping their retries.
+	if err == errPaused {
+		route.queue.AddAfter(obj, pausedRequeueDelay)
+		return
+	}
+
+	// Commands waiting for their queue are not limited by commandMaxRetries but expire by age.
+	if _, ok := err.(*queueNotFoundError); ok {
+		c.infof(4, fields, "Command %v is waiting for its queue: %v.", obj, err)
+		route.queue.AddRateLimited(obj)
 		return
 	}
 
-	if c.commandQueue.NumRequeues(obj) < maxRetries {
-		klog.V(4).Infof("Error syncing command %v for %v.", obj, err)
-		c.commandQueue.AddRateLimited(obj)
+	if c.commandMaxRetries == 0 || route.queue.NumRequeues(obj) < c.commandMaxRetries {
+		c.infof(4, fields, "Error syncing command %v for %v.", obj, err)
+		route.queue.AddRateLimited(obj)
 		return
 	}
 
-	klog.V(2).Infof("Dropping command %v out of the queue for %v.", obj, err)
-	c.commandQueue.Forget(obj)
+	c.infof(2, fields, "Dropping command %v out of the queue for %v.", obj, err)
+	route.queue.Forget(obj)
+	route.backlog.remove(cmd)
 }
@@ -17,45 +17,51 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"fmt"
-	"reflect"
 
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
 	"volcano.sh/volcano/pkg/controllers/queue/state"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/cache"
 
 	"k8s.io/klog"
 )
 
-func (c *Controller) syncQueue(queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
+func (c *Controller) syncQueue(ctx context.Context, queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
 	klog.V(4).Infof("Begin to sync queue %s.", queue.Name)
 
 	podGroups := c.getPodGroups(queue.Name)
-	queueStatus := schedulingv1alpha2.QueueStatus{}
+	queueStatus := schedulingv1alpha2.QueueStatus{
+		// Unmet guarantee, overcommitted and reclaim budget are maintained by scheduler.
+		UnmetGuarantee: queue.Status.UnmetGuarantee,
+		Overcommitted:  queue.Status.Overcommitted,
+		ReclaimBudget:  queue.Status.ReclaimBudget,
+		Conditions:     queue.Status.Conditions,
+		LastCommand:    queue.Status.LastCommand,
+		// Effective weight is maintained by adjustWeights.
+		EffectiveWeight: queue.Status.EffectiveWeight,
+	}
 
-	for _, pgKey := range podGroups {
-		// Ignore error here, tt can not occur.
-		ns, name, _ := cache.SplitMetaNamespaceKey(pgKey)
+	// The status of a parent queue also counts the podgroups of its descendants.
+	pgs, err := c.listPodGroups(queue.Name)
+	if err != nil {
+		return err
+	}
+	helpers.AggregateQueueUsage(queue, pgs).ApplyTo(&queueStatus)
 
-		// TODO: check NotFound error and sync local cache.
-		pg, err := c.pgLister.PodGroups(ns).Get(name)
+	if len(queue.Spec.CapabilityPercent) > 0 {
+		allocatable, err := c.clusterAllocatable()
 		if err != nil {
 			return err
 		}
-
-		switch pg.Status.Phase {
-		case schedulingv1alpha2.PodGroupPending:
-			queueStatus.Pending++
-		case schedulingv1alpha2.PodGroupRunning:
-			queueStatus.Running++
-		case schedulingv1alpha2.PodGroupUnknown:
-			queueStatus.Unknown++
-		case schedulingv1alpha2.PodGroupInqueue:
-			queueStatus.Inqueue++
-		}
+		queueStatus.Capability = resolveCapability(queue, allocatable)
 	}
 
 	if updateStateFn != nil {
@@ -63,30 +69,43 @@ func (c *Controller) syncQueue(queue *schedulingv1alpha2.Queue, updateStateFn st
 	} else {
 		queueStatus.State = queue.Status.State
 	}
+	changed := appendStateCondition(&queueStatus, queue.Status.State, queueActionFrom(ctx))
 
-	// ignore update when status does not change
-	if reflect.DeepEqual(queueStatus, queue.Status) {
+	// ignore update when status does not change, quantities are compared by value
+	// as the usage summed up here has no cached string form.
+	if equality.Semantic.DeepEqual(queueStatus, queue.Status) {
 		return nil
 	}
 
 	newQueue := queue.DeepCopy()
 	newQueue.Status = queueStatus
-	if _, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue); err != nil {
+	if err := callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue)
+		return err
+	}); err != nil {
 		klog.Errorf("Failed to update status of Queue %s: %v.", newQueue.Name, err)
 		return err
 	}
 
+	if changed {
+		c.recordStateTransition(newQueue)
+	}
+
 	return nil
 }
 
-func (c *Controller) openQueue(queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
+func (c *Controller) openQueue(ctx context.Context, queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
 	klog.V(4).Infof("Begin to open queue %s.", queue.Name)
 
 	newQueue := queue.DeepCopy()
 	newQueue.Spec.State = schedulingv1alpha2.QueueStateOpen
+	delete(newQueue.Annotations, schedulingv1alpha2.IdleClosedAnnotationKey)
 
 	if queue.Spec.State != newQueue.Spec.State {
-		if _, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue); err != nil {
+		if err := callWithContext(ctx, func() error {
+			_, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue)
+			return err
+		}); err != nil {
 			c.recorder.Event(newQueue, v1.EventTypeWarning, string(schedulingv1alpha2.OpenQueueAction),
 				fmt.Sprintf("Open queue failed for %v", err))
 			return err
@@ -98,7 +117,11 @@ func (c *Controller) openQueue(queue *schedulingv1alpha2.Queue, updateStateFn st
 		return nil
 	}
 
-	q, err := c.vcClient.SchedulingV1alpha2().Queues().Get(newQueue.Name, metav1.GetOptions{})
+	var q *schedulingv1alpha2.Queue
+	err := callWithContext(ctx, func() (err error) {
+		q, err = c.vcClient.SchedulingV1alpha2().Queues().Get(newQueue.Name, metav1.GetOptions{})
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -110,38 +133,61 @@ func (c *Controller) openQueue(queue *schedulingv1alpha2.Queue, updateStateFn st
 		return fmt.Errorf("internal error, update state function should be provided")
 	}
 
-	if queue.Status.State != newQueue.Status.State {
-		if _, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue); err != nil {
+	if appendStateCondition(&newQueue.Status, q.Status.State, queueActionFrom(ctx)) {
+		if err := callWithContext(ctx, func() error {
+			_, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue)
+			return err
+		}); err != nil {
 			c.recorder.Event(newQueue, v1.EventTypeWarning, string(schedulingv1alpha2.OpenQueueAction),
 				fmt.Sprintf("Update queue status from %s to %s failed for %v",
 					queue.Status.State, newQueue.Status.State, err))
 			return err
 		}
+		c.recordStateTransition(newQueue)
 	}
 
 	return nil
 }
 
-func (c *Controller) closeQueue(queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
+func (c *Controller) closeQueue(ctx context.Context, queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
 	klog.V(4).Infof("Begin to close queue %s.", queue.Name)
 
 	newQueue := queue.DeepCopy()
 	newQueue.Spec.State = schedulingv1alpha2.QueueStateClosed
+	idle := queueEventFrom(ctx) == schedulingv1alpha2.QueueIdleEvent
+	if idle {
+		if newQueue.Annotations == nil {
+			newQueue.Annotations = map[string]string{}
+		}
+		newQueue.Annotations[schedulingv1alpha2.IdleClosedAnnotationKey] = "true"
+	}
 
 	if queue.Spec.State != newQueue.Spec.State {
-		if _, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue); err != nil {
+		if err := callWithContext(ctx, func() error {
+			_, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue)
+			return err
+		}); err != nil {
 			c.recorder.Event(newQueue, v1.EventTypeWarning, string(schedulingv1alpha2.CloseQueueAction),
 				fmt.Sprintf("Close queue failed for %v", err))
 			return err
 		}
 
-		c.recorder.Event(newQueue, v1.EventTypeNormal, string(schedulingv1alpha2.CloseQueueAction),
-			fmt.Sprintf("Close queue succeed"))
+		if idle {
+			c.recorder.Event(newQueue, v1.EventTypeNormal, string(schedulingv1alpha2.CloseQueueAction),
+				fmt.Sprintf("Close queue succeed, it has no pending or running podgroups for %v", c.idleTimeout))
+		} else {
+			c.recorder.Event(newQueue, v1.EventTypeNormal, string(schedulingv1alpha2.CloseQueueAction),
+				fmt.Sprintf("Close queue succeed"))
+		}
 	} else {
 		return nil
 	}
 
-	q, err := c.vcClient.SchedulingV1alpha2().Queues().Get(newQueue.Name, metav1.GetOptions{})
+	var q *schedulingv1alpha2.Queue
+	err := callWithContext(ctx, func() (err error) {
+		q, err = c.vcClient.SchedulingV1alpha2().Queues().Get(newQueue.Name, metav1.GetOptions{})
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -154,14 +200,252 @@ func (c *Controller) closeQueue(queue *schedulingv1alpha2.Queue, updateStateFn s
 		return fmt.Errorf("internal error, update state function should be provided")
 	}
 
-	if queue.Status.State != newQueue.Status.State {
-		if _, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue); err != nil {
+	if appendStateCondition(&newQueue.Status, q.Status.State, queueActionFrom(ctx)) {
+		if err := callWithContext(ctx, func() error {
+			_, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue)
+			return err
+		}); err != nil {
 			c.recorder.Event(newQueue, v1.EventTypeWarning, string(schedulingv1alpha2.CloseQueueAction),
 				fmt.Sprintf("Update queue status from %s to %s failed for %v",
 					queue.Status.State, newQueue.Status.State, err))
 			return err
 		}
+		c.recordStateTransition(newQueue)
+	}
+
+	return nil
+}
+
+func (c *Controller) drainQueue(ctx context.Context, queue *schedulingv1alpha2.Queue, updateStateFn state.UpdateQueueStatusFn) error {
+	klog.V(4).Infof("Begin to drain queue %s.", queue.Name)
+
+	if err := c.syncQueue(ctx, queue, updateStateFn); err != nil {
+		return err
+	}
+
+	if queue.Status.State != schedulingv1alpha2.QueueStateDraining {
+		c.recorder.Event(queue, v1.EventTypeNormal, string(schedulingv1alpha2.QueueStateDraining),
+			"Queue is deleted, stop admitting podgroups and start draining")
+	}
+
+	if ownPodGroups(queue) && !hasOrphanFinalizer(queue) {
+		if err := c.deleteOwnedPodGroups(ctx, queue); err != nil {
+			c.recorder.Event(queue, v1.EventTypeWarning, string(schedulingv1alpha2.QueueStateDraining),
+				fmt.Sprintf("Delete owned podgroups failed for %v", err))
+			return err
+		}
+	}
+
+	running, err := c.runningPodGroups(queue)
+	if err != nil {
+		return err
+	}
+	if running != 0 {
+		c.recorder.Event(queue, v1.EventTypeNormal, string(schedulingv1alpha2.QueueStateDraining),
+			fmt.Sprintf("Waiting for %d podgroups to finish", running))
+		return nil
+	}
+
+	if !hasFinalizer(queue) {
+		return nil
+	}
+
+	if err := c.removeFinalizer(ctx, queue); err != nil {
+		c.recorder.Event(queue, v1.EventTypeWarning, string(schedulingv1alpha2.QueueStateDraining),
+			fmt.Sprintf("Remove finalizer failed for %v", err))
+		return err
+	}
+
+	c.recorder.Event(queue, v1.EventTypeNormal, "Drained",
+		"Queue is drained, finalizer is removed")
+
+	return nil
+}
+
+// recordStateTransition records an event for the latest state transition of queue.
+func (c *Controller) recordStateTransition(queue *schedulingv1alpha2.Queue) {
+	conditions := queue.Status.Conditions
+	if len(conditions) == 0 {
+		return
+	}
+
+	condition := conditions[len(conditions)-1]
+	c.recorder.Event(queue, v1.EventTypeNormal, "StateTransition",
+		fmt.Sprintf("%s, reason: %s", condition.Message, condition.Reason))
+}
+
+// cordonQueue sets or removes the cordoned annotation of the queue; the state of the queue is not
+// changed, so the podgroups already in the queue are still scheduled.
+func (c *Controller) cordonQueue(ctx context.Context, queue *schedulingv1alpha2.Queue, cordon bool) error {
+	action := schedulingv1alpha2.UncordonQueueAction
+	if cordon {
+		action = schedulingv1alpha2.CordonQueueAction
+	}
+	if isCordoned(queue) == cordon {
+		return nil
+	}
+
+	newQueue := queue.DeepCopy()
+	if cordon {
+		if newQueue.Annotations == nil {
+			newQueue.Annotations = map[string]string{}
+		}
+		newQueue.Annotations[schedulingv1alpha2.CordonedAnnotationKey] = "true"
+	} else {
+		delete(newQueue.Annotations, schedulingv1alpha2.CordonedAnnotationKey)
+	}
+
+	if err := callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue)
+		return err
+	}); err != nil {
+		c.recorder.Event(newQueue, v1.EventTypeWarning, string(action),
+			fmt.Sprintf("%s failed for %v", action, err))
+		return err
+	}
+
+	if cordon {
+		c.recorder.Event(newQueue, v1.EventTypeNormal, string(action),
+			"Cordon queue succeed, new jobs are rejected in the queue")
+	} else {
+		c.recorder.Event(newQueue, v1.EventTypeNormal, string(action),
+			"Uncordon queue succeed")
+	}
+
+	return nil
+}
+
+// cordonQueueAction returns the action cordoning or uncordoning the queue for the state machine; cordoning
+// does not change the state of queue, which is synced again by the update event.
+func (c *Controller) cordonQueueAction(cordon bool) state.QueueActionFn {
+	return func(ctx context.Context, queue *schedulingv1alpha2.Queue, _ state.UpdateQueueStatusFn) error {
+		return c.cordonQueue(ctx, queue, cordon)
+	}
+}
+
+func (c *Controller) addFinalizer(ctx context.Context, queue *schedulingv1alpha2.Queue) error {
+	newQueue := queue.DeepCopy()
+	newQueue.Finalizers = append(newQueue.Finalizers, queueFinalizer)
+	if err := callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue)
+		return err
+	}); err != nil {
+		klog.Errorf("Failed to add finalizer to Queue %s: %v.", queue.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *Controller) removeFinalizer(ctx context.Context, queue *schedulingv1alpha2.Queue) error {
+	// Status of queue may be updated, get the latest one.
+	var q *schedulingv1alpha2.Queue
+	err := callWithContext(ctx, func() (err error) {
+		q, err = c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	newQueue := q.DeepCopy()
+	newQueue.Finalizers = nil
+	for _, f := range q.Finalizers {
+		if f != queueFinalizer {
+			newQueue.Finalizers = append(newQueue.Finalizers, f)
+		}
+	}
+
+	if err := callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().Update(newQueue)
+		return err
+	}); err != nil {
+		klog.Errorf("Failed to remove finalizer from Queue %s: %v.", queue.Name, err)
+		return err
 	}
 
 	return nil
 }
+
+// adoptPodGroups sets the queue as the controller of its podgroups which have no controller yet;
+// podgroups controlled by others, e.g. a Job, are left alone.
+func (c *Controller) adoptPodGroups(ctx context.Context, queue *schedulingv1alpha2.Queue) error {
+	var errs []error
+	for _, pgKey := range c.getPodGroups(queue.Name) {
+		ns, name, _ := cache.SplitMetaNamespaceKey(pgKey)
+		pg, err := c.pgLister.PodGroups(ns).Get(name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if metav1.GetControllerOf(pg) != nil {
+			continue
+		}
+
+		newPG := pg.DeepCopy()
+		newPG.OwnerReferences = append(newPG.OwnerReferences,
+			*metav1.NewControllerRef(queue, schedulingv1alpha2.SchemeGroupVersion.WithKind("Queue")))
+		if err := callWithContext(ctx, func() error {
+			_, err := c.vcClient.SchedulingV1alpha2().PodGroups(ns).Update(newPG)
+			return err
+		}); err != nil {
+			klog.Errorf("Failed to adopt PodGroup %s by Queue %s: %v.", pgKey, queue.Name, err)
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// runningPodGroups returns the number of podgroups of the queue which are not finished yet, the
+// completed or failed podgroups left behind by their jobs do not hold the queue.
+func (c *Controller) runningPodGroups(queue *schedulingv1alpha2.Queue) (int, error) {
+	running := 0
+	for _, pgKey := range c.getPodGroups(queue.Name) {
+		ns, name, _ := cache.SplitMetaNamespaceKey(pgKey)
+		pg, err := c.pgLister.PodGroups(ns).Get(name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return 0, err
+		}
+
+		if !podGroupFinished(pg) {
+			running++
+		}
+	}
+
+	return running, nil
+}
+
+// deleteOwnedPodGroups deletes the podgroups controlled by the queue.
+func (c *Controller) deleteOwnedPodGroups(ctx context.Context, queue *schedulingv1alpha2.Queue) error {
+	var errs []error
+	for _, pgKey := range c.getPodGroups(queue.Name) {
+		ns, name, _ := cache.SplitMetaNamespaceKey(pgKey)
+		pg, err := c.pgLister.PodGroups(ns).Get(name)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if ref := metav1.GetControllerOf(pg); ref == nil || ref.UID != queue.UID || pg.DeletionTimestamp != nil {
+			continue
+		}
+
+		if err := callWithContext(ctx, func() error {
+			return c.vcClient.SchedulingV1alpha2().PodGroups(ns).Delete(name, nil)
+		}); err != nil && !apierrors.IsNotFound(err) {
+			klog.Errorf("Failed to delete PodGroup %s owned by Queue %s: %v.", pgKey, queue.Name, err)
+			errs = append(errs, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
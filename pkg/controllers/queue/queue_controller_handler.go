@@ -17,29 +17,62 @@ limitations under the License.
 package queue
 
 import (
+	"fmt"
+
 	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 
 	"k8s.io/klog"
 )
 
-func (c *Controller) enqueue(req *schedulingv1alpha2.QueueRequest) {
-	c.queue.Add(req)
+func (c *Controller) enqueue(name string, event schedulingv1alpha2.QueueEvent, action schedulingv1alpha2.QueueAction) {
+	req, err := schedulingv1alpha2.NewQueueRequest(name, event, action)
+	if err != nil {
+		klog.Errorf("Failed to enqueue queue %s: %v.", name, err)
+		return
+	}
+
+	c.enqueueQueue(req)
+}
+
+// enqueueAllQueues enqueues all the queues to reconcile their status.
+func (c *Controller) enqueueAllQueues() {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues: %v", err)
+		return
+	}
+
+	klog.V(4).Infof("Reconcile all %d queues.", len(queues))
+	for _, queue := range queues {
+		c.enqueue(queue.Name, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+	}
 }
 
 func (c *Controller) addQueue(obj interface{}) {
 	queue := obj.(*schedulingv1alpha2.Queue)
 
-	req := &schedulingv1alpha2.QueueRequest{
-		Name: queue.Name,
+	c.addChildQueue(queue)
 
-		Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
-		Action: schedulingv1alpha2.SyncQueueAction,
+	c.enqueue(queue.Name, schedulingv1alpha2.QueueOutOfSyncEvent, c.initialAction(queue))
+	c.enqueueAncestors(queue.Spec.Parent)
+}
+
+// initialAction returns the action of a queue when it is added: a new queue, which has neither
+// spec nor status state, is closed if the default state is Closed, so that it is only
+// opened explicitly; the other queues are synced.
+func (c *Controller) initialAction(queue *schedulingv1alpha2.Queue) schedulingv1alpha2.QueueAction {
+	if c.defaultState == schedulingv1alpha2.QueueStateClosed &&
+		queue.Spec.State == "" && queue.Status.State == "" && queue.DeletionTimestamp == nil {
+		klog.V(3).Infof("Queue %s is created without state, close it by default.", queue.Name)
+		return schedulingv1alpha2.CloseQueueAction
 	}
 
-	c.enqueue(req)
+	return schedulingv1alpha2.SyncQueueAction
 }
 
 func (c *Controller) deleteQueue(obj interface{}) {
@@ -57,6 +90,18 @@ func (c *Controller) deleteQueue(obj interface{}) {
 		}
 	}
 
+	// Queue is deleted after it is drained and its finalizer is removed,
+	// so clean up the index only.
+	c.deleteChildQueue(queue)
+	c.enqueueAncestors(queue.Spec.Parent)
+
+	// Children of the deleted queue are synced again to report the missing parent.
+	for _, child := range c.getChildQueues(queue.Name) {
+		c.enqueue(child, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+	}
+
+	c.forgetActivity(queue.Name)
+
 	c.pgMutex.Lock()
 	defer c.pgMutex.Unlock()
 	delete(c.podGroups, queue.Name)
@@ -79,6 +124,11 @@ func (c *Controller) updateQueue(old, new interface{}) {
 		return
 	}
 
+	if oldQueue.Spec.Parent != newQueue.Spec.Parent {
+		c.deleteChildQueue(oldQueue)
+		c.enqueueAncestors(oldQueue.Spec.Parent)
+	}
+
 	c.addQueue(newQueue)
 
 	return
@@ -86,6 +136,9 @@ func (c *Controller) updateQueue(old, new interface{}) {
 
 func (c *Controller) addPodGroup(obj interface{}) {
 	pg := obj.(*schedulingv1alpha2.PodGroup)
+	if !c.isWatchedNamespace(pg.Namespace) {
+		return
+	}
 	key, _ := cache.MetaNamespaceKeyFunc(obj)
 
 	c.pgMutex.Lock()
@@ -96,27 +149,46 @@ func (c *Controller) addPodGroup(obj interface{}) {
 	}
 	c.podGroups[pg.Spec.Queue][key] = struct{}{}
 
-	req := &schedulingv1alpha2.QueueRequest{
-		Name: pg.Spec.Queue,
-
-		Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
-		Action: schedulingv1alpha2.SyncQueueAction,
-	}
-
-	c.enqueue(req)
+	c.enqueue(pg.Spec.Queue, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+	c.enqueueQueueAncestors(pg.Spec.Queue)
+	c.reopenIdleQueue(pg)
 }
 
 func (c *Controller) updatePodGroup(old, new interface{}) {
 	oldPG := old.(*schedulingv1alpha2.PodGroup)
 	newPG := new.(*schedulingv1alpha2.PodGroup)
+	if !c.isWatchedNamespace(newPG.Namespace) {
+		return
+	}
+
+	// The podgroup is moved to another queue, remove it from the old queue
+	// which is synced again too.
+	if oldPG.Spec.Queue != newPG.Spec.Queue {
+		key, _ := cache.MetaNamespaceKeyFunc(oldPG)
+		c.deletePodGroupFromQueue(oldPG.Spec.Queue, key)
+
+		c.enqueue(oldPG.Spec.Queue, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+		c.enqueueQueueAncestors(oldPG.Spec.Queue)
+		c.addPodGroup(newPG)
+		return
+	}
 
-	// Note: we have no use case update PodGroup.Spec.Queue
-	// So do not consider it here.
 	if oldPG.Status.Phase != newPG.Status.Phase {
 		c.addPodGroup(newPG)
 	}
 }
 
+// deletePodGroupFromQueue removes the podgroup from the index of the queue.
+func (c *Controller) deletePodGroupFromQueue(queue, key string) {
+	c.pgMutex.Lock()
+	defer c.pgMutex.Unlock()
+
+	delete(c.podGroups[queue], key)
+	if len(c.podGroups[queue]) == 0 {
+		delete(c.podGroups, queue)
+	}
+}
+
 func (c *Controller) deletePodGroup(obj interface{}) {
 	pg, ok := obj.(*schedulingv1alpha2.PodGroup)
 	if !ok {
@@ -132,21 +204,64 @@ func (c *Controller) deletePodGroup(obj interface{}) {
 		}
 	}
 
-	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	key, _ := cache.MetaNamespaceKeyFunc(pg)
 
+	// The queue of the podgroup may be missing in the deleted object,
+	// so remove it from all the queues it is indexed in.
+	for _, queue := range c.deletePodGroupIndex(key) {
+		c.enqueue(queue, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+		c.enqueueQueueAncestors(queue)
+	}
+}
+
+// deletePodGroupIndex removes the podgroup from the index and returns the queues it was indexed in.
+func (c *Controller) deletePodGroupIndex(key string) []string {
 	c.pgMutex.Lock()
 	defer c.pgMutex.Unlock()
 
-	delete(c.podGroups[pg.Spec.Queue], key)
+	var queues []string
+	for queue, podGroups := range c.podGroups {
+		if _, found := podGroups[key]; !found {
+			continue
+		}
+
+		delete(podGroups, key)
+		if len(podGroups) == 0 {
+			delete(c.podGroups, queue)
+		}
+		queues = append(queues, queue)
+	}
 
-	req := &schedulingv1alpha2.QueueRequest{
-		Name: pg.Spec.Queue,
+	return queues
+}
 
-		Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
-		Action: schedulingv1alpha2.SyncQueueAction,
+// pruneStalePodGroups removes the podgroups which do not exist any more from the index and returns
+// the number of pruned entries; the entries are left behind if the deletion of a podgroup is missed.
+// The podgroups of the queues which do not exist are kept, they are counted once the queues are
+// created again.
+func (c *Controller) pruneStalePodGroups() int {
+	c.pgMutex.Lock()
+	defer c.pgMutex.Unlock()
+
+	pruned := 0
+	for queue, podGroups := range c.podGroups {
+		for key := range podGroups {
+			namespace, name, err := cache.SplitMetaNamespaceKey(key)
+			if err == nil {
+				if _, err = c.pgLister.PodGroups(namespace).Get(name); err == nil || !apierrors.IsNotFound(err) {
+					continue
+				}
+			}
+
+			delete(podGroups, key)
+			pruned++
+		}
+		if len(podGroups) == 0 {
+			delete(c.podGroups, queue)
+		}
 	}
 
-	c.enqueue(req)
+	return pruned
 }
 
 func (c *Controller) addCommand(obj interface{}) {
@@ -156,7 +271,14 @@ func (c *Controller) addCommand(obj interface{}) {
 		return
 	}
 
-	c.commandQueue.Add(cmd)
+	route := c.routeCommand(cmd)
+	if route == nil {
+		klog.Errorf("Command <%s/%s> targets %v which is not handled by queue controller.",
+			cmd.Namespace, cmd.Name, cmd.TargetObject)
+		return
+	}
+	route.backlog.add(cmd)
+	route.queue.Add(cmd)
 }
 
 func (c *Controller) getPodGroups(key string) []string {
@@ -174,6 +296,166 @@ func (c *Controller) getPodGroups(key string) []string {
 	return podGroups
 }
 
+func (c *Controller) addChildQueue(queue *schedulingv1alpha2.Queue) {
+	if queue.Spec.Parent == "" {
+		return
+	}
+
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	if c.children[queue.Spec.Parent] == nil {
+		c.children[queue.Spec.Parent] = make(map[string]struct{})
+	}
+	c.children[queue.Spec.Parent][queue.Name] = struct{}{}
+}
+
+func (c *Controller) deleteChildQueue(queue *schedulingv1alpha2.Queue) {
+	if queue.Spec.Parent == "" {
+		return
+	}
+
+	c.queueMutex.Lock()
+	defer c.queueMutex.Unlock()
+
+	delete(c.children[queue.Spec.Parent], queue.Name)
+	if len(c.children[queue.Spec.Parent]) == 0 {
+		delete(c.children, queue.Spec.Parent)
+	}
+}
+
+func (c *Controller) getChildQueues(key string) []string {
+	c.queueMutex.RLock()
+	defer c.queueMutex.RUnlock()
+
+	if c.children[key] == nil {
+		return nil
+	}
+	children := make([]string, 0, len(c.children[key]))
+	for child := range c.children[key] {
+		children = append(children, child)
+	}
+
+	return children
+}
+
+// enqueueQueueAncestors enqueues all ancestors of the queue, e.g. when its podgroup set changes.
+func (c *Controller) enqueueQueueAncestors(name string) {
+	queue, err := c.queueLister.Get(name)
+	if err != nil {
+		return
+	}
+
+	c.enqueueAncestors(queue.Spec.Parent)
+}
+
+// enqueueAncestors enqueues the parent queue and all of its ancestors.
+func (c *Controller) enqueueAncestors(parent string) {
+	visited := map[string]struct{}{}
+	for parent != "" {
+		if _, found := visited[parent]; found {
+			return
+		}
+		visited[parent] = struct{}{}
+
+		c.enqueue(parent, schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+
+		queue, err := c.queueLister.Get(parent)
+		if err != nil {
+			return
+		}
+		parent = queue.Spec.Parent
+	}
+}
+
+// validateParent checks that the parent of queue exists and that its ancestors do not form a cycle.
+func (c *Controller) validateParent(queue *schedulingv1alpha2.Queue) error {
+	visited := map[string]struct{}{queue.Name: {}}
+	parent := queue.Spec.Parent
+	for parent != "" {
+		if _, found := visited[parent]; found {
+			return fmt.Errorf("parent %s of queue %s introduces a cycle", queue.Spec.Parent, queue.Name)
+		}
+		visited[parent] = struct{}{}
+
+		p, err := c.queueLister.Get(parent)
+		if err != nil {
+			if apierrors.IsNotFound(err) && parent == queue.Spec.Parent {
+				return fmt.Errorf("parent %s of queue %s does not exist", parent, queue.Name)
+			}
+			return nil
+		}
+		parent = p.Spec.Parent
+	}
+
+	return nil
+}
+
+// getDescendantPodGroups returns the podgroups of all valid descendants of the queue.
+func (c *Controller) getDescendantPodGroups(key string) []string {
+	var podGroups []string
+
+	visited := map[string]struct{}{key: {}}
+	pending := c.getChildQueues(key)
+	for len(pending) > 0 {
+		child := pending[0]
+		pending = pending[1:]
+
+		if _, found := visited[child]; found {
+			continue
+		}
+		visited[child] = struct{}{}
+
+		queue, err := c.queueLister.Get(child)
+		if err != nil || c.validateParent(queue) != nil {
+			continue
+		}
+
+		podGroups = append(podGroups, c.getPodGroups(child)...)
+		pending = append(pending, c.getChildQueues(child)...)
+	}
+
+	return podGroups
+}
+
+// listPodGroups returns the podgroups of the queue and all its valid descendants from the lister.
+func (c *Controller) listPodGroups(key string) ([]*schedulingv1alpha2.PodGroup, error) {
+	var podGroups []*schedulingv1alpha2.PodGroup
+	for _, pgKey := range append(c.getDescendantPodGroups(key), c.getPodGroups(key)...) {
+		// Ignore error here, tt can not occur.
+		ns, name, _ := cache.SplitMetaNamespaceKey(pgKey)
+
+		// TODO: check NotFound error and sync local cache.
+		pg, err := c.pgLister.PodGroups(ns).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		podGroups = append(podGroups, pg)
+	}
+
+	return podGroups, nil
+}
+
+// validatePriority checks the priority of queue is the value of a PriorityClass, as the admission does.
+func (c *Controller) validatePriority(queue *schedulingv1alpha2.Queue) error {
+	if queue.Spec.Priority == 0 {
+		return nil
+	}
+
+	priorityClasses, err := c.pcLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list PriorityClasses: %v", err)
+	}
+
+	for _, pc := range priorityClasses {
+		if pc.Value == queue.Spec.Priority {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("priority %d of queue %s is not the value of any PriorityClass", queue.Spec.Priority, queue.Name)
+}
+
 func (c *Controller) recordEventsForQueue(name, eventType, reason, message string) {
 	queue, err := c.queueLister.Get(name)
 	if err != nil {
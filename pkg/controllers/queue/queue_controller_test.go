@@ -17,25 +17,83 @@ limitations under the License.
 package queue
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
+	schedulingv1beta1 "k8s.io/api/scheduling/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kubeclient "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+	"volcano.sh/volcano/pkg/controllers/tracing"
 )
 
 func newFakeController() *Controller {
 	KubeBatchClientSet := vcclient.NewSimpleClientset()
 	KubeClientSet := kubeclient.NewSimpleClientset()
 
-	controller := NewQueueController(KubeClientSet, KubeBatchClientSet)
+	controller := NewQueueController(KubeClientSet, KubeBatchClientSet, NewOptions())
 	return controller
 }
 
+// handledActions replaces the handler of queue requests by recording the actions of the handled requests.
+func handledActions(c *Controller) *[]schedulingv1alpha2.QueueAction {
+	actions := &[]schedulingv1alpha2.QueueAction{}
+	c.syncHandler = func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
+		*actions = append(*actions, req.Action)
+		return nil
+	}
+	return actions
+}
+
+func TestEventClient(t *testing.T) {
+	kubeClient := kubeclient.NewSimpleClientset()
+	eventClient := kubeclient.NewSimpleClientset()
+	created := make(chan struct{}, 1)
+	eventClient.PrependReactor("create", "events", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		created <- struct{}{}
+		return true, action.(kubetesting.CreateAction).GetObject(), nil
+	})
+	kubeClient.PrependReactor("create", "events", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		t.Errorf("expected no event written by the client of the controller")
+		return false, nil, nil
+	})
+
+	opt := NewOptions()
+	opt.EventClient = eventClient
+	c := NewQueueController(kubeClient, vcclient.NewSimpleClientset(), opt)
+
+	c.recorder.Event(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1", SelfLink: "/apis/scheduling.sigs.dev/v1alpha2/queues/q1"},
+	}, v1.EventTypeNormal, "Test", "event of queue")
+
+	select {
+	case <-created:
+	case <-time.After(3 * time.Second):
+		t.Errorf("expected event written by the event client")
+	}
+}
+
 func TestAddQueue(t *testing.T) {
 	testCases := []struct {
 		Name        string
@@ -67,6 +125,69 @@ func TestAddQueue(t *testing.T) {
 	}
 }
 
+func TestAddQueueDefaultState(t *testing.T) {
+	testCases := []struct {
+		name         string
+		defaultState schedulingv1alpha2.QueueState
+		specState    schedulingv1alpha2.QueueState
+		statusState  schedulingv1alpha2.QueueState
+		expectState  schedulingv1alpha2.QueueState
+	}{
+		{
+			name:        "open by default",
+			expectState: schedulingv1alpha2.QueueStateOpen,
+		},
+		{
+			name:         "closed by default",
+			defaultState: schedulingv1alpha2.QueueStateClosed,
+			expectState:  schedulingv1alpha2.QueueStateClosed,
+		},
+		{
+			name:         "explicitly opened",
+			defaultState: schedulingv1alpha2.QueueStateClosed,
+			specState:    schedulingv1alpha2.QueueStateOpen,
+			expectState:  schedulingv1alpha2.QueueStateOpen,
+		},
+		{
+			name:         "existing queue is not closed",
+			defaultState: schedulingv1alpha2.QueueStateClosed,
+			statusState:  schedulingv1alpha2.QueueStateOpen,
+			expectState:  schedulingv1alpha2.QueueStateOpen,
+		},
+	}
+
+	for _, testCase := range testCases {
+		opt := NewOptions()
+		opt.DefaultState = testCase.defaultState
+		c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+
+		queue := &schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       schedulingv1alpha2.QueueSpec{Weight: 1, State: testCase.specState},
+			Status:     schedulingv1alpha2.QueueStatus{State: testCase.statusState},
+		}
+		c.queueInformer.Informer().GetIndexer().Add(queue)
+		c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+		// The finalizer is added by the first request, the queue is handled again by its update event.
+		c.addQueue(queue)
+		c.processNextWorkItem(context.TODO())
+		updated, _ := c.vcClient.SchedulingV1alpha2().Queues().Get("q1", metav1.GetOptions{})
+		c.queueInformer.Informer().GetIndexer().Update(updated)
+		c.addQueue(updated)
+		c.processNextWorkItem(context.TODO())
+
+		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get("q1", metav1.GetOptions{})
+		if item.Status.State != testCase.expectState {
+			t.Errorf("case %s: expected state %s, got %s", testCase.name, testCase.expectState, item.Status.State)
+		}
+		if testCase.defaultState == schedulingv1alpha2.QueueStateClosed && testCase.specState == "" &&
+			testCase.statusState == "" && item.Spec.State != schedulingv1alpha2.QueueStateClosed {
+			t.Errorf("case %s: expected spec state %s, got %s", testCase.name, schedulingv1alpha2.QueueStateClosed, item.Spec.State)
+		}
+	}
+}
+
 func TestDeleteQueue(t *testing.T) {
 	testCases := []struct {
 		Name        string
@@ -142,9 +263,10 @@ func TestDeletePodGroup(t *testing.T) {
 	namespace := "c1"
 
 	testCases := []struct {
-		Name        string
-		podGroup    *schedulingv1alpha2.PodGroup
-		ExpectValue bool
+		Name         string
+		podGroup     *schedulingv1alpha2.PodGroup
+		IndexedQueue string
+		ExpectValue  bool
 	}{
 		{
 			Name: "deletepodgroup",
@@ -157,7 +279,19 @@ func TestDeletePodGroup(t *testing.T) {
 					Queue: "c1",
 				},
 			},
-			ExpectValue: false,
+			IndexedQueue: "c1",
+			ExpectValue:  false,
+		},
+		{
+			Name: "delete podgroup without queue",
+			podGroup: &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pg1",
+					Namespace: namespace,
+				},
+			},
+			IndexedQueue: "c1",
+			ExpectValue:  false,
 		},
 	}
 
@@ -165,13 +299,55 @@ func TestDeletePodGroup(t *testing.T) {
 		c := newFakeController()
 
 		key, _ := cache.MetaNamespaceKeyFunc(testcase.podGroup)
-		c.podGroups[testcase.podGroup.Spec.Queue] = make(map[string]struct{})
-		c.podGroups[testcase.podGroup.Spec.Queue][key] = struct{}{}
+		c.podGroups[testcase.IndexedQueue] = make(map[string]struct{})
+		c.podGroups[testcase.IndexedQueue][key] = struct{}{}
 
 		c.deletePodGroup(testcase.podGroup)
-		if _, ok := c.podGroups[testcase.podGroup.Spec.Queue][key]; ok != testcase.ExpectValue {
+		if _, ok := c.podGroups[testcase.IndexedQueue][key]; ok != testcase.ExpectValue {
 			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, ok)
 		}
+		if _, ok := c.podGroups[testcase.IndexedQueue]; ok {
+			t.Errorf("case %d (%s): expected empty index of queue %s to be removed", i, testcase.Name, testcase.IndexedQueue)
+		}
+	}
+}
+
+func TestPruneStalePodGroups(t *testing.T) {
+	c := newFakeController()
+
+	c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+	})
+	for _, pg := range []*schedulingv1alpha2.PodGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg1"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg2"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "deleted1"},
+		},
+	} {
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+	}
+	c.podGroups["q1"] = map[string]struct{}{"ns/pg1": {}, "ns/pg5": {}}
+	c.podGroups["deleted1"] = map[string]struct{}{"ns/pg2": {}}
+	c.podGroups["deleted2"] = map[string]struct{}{"ns/pg3": {}, "ns/pg4": {}}
+
+	if pruned := c.pruneStalePodGroups(); pruned != 3 {
+		t.Errorf("expected 3 pruned podgroups, got %d", pruned)
+	}
+	// The podgroups of the queue which does not exist are kept.
+	expected := map[string]map[string]struct{}{
+		"q1":       {"ns/pg1": {}},
+		"deleted1": {"ns/pg2": {}},
+	}
+	if !reflect.DeepEqual(c.podGroups, expected) {
+		t.Errorf("expected index %v, got %v", expected, c.podGroups)
+	}
+
+	if pruned := c.pruneStalePodGroups(); pruned != 0 {
+		t.Errorf("expected nothing to prune, got %d", pruned)
 	}
 }
 
@@ -225,6 +401,159 @@ func TestUpdatePodGroup(t *testing.T) {
 	}
 }
 
+func TestUpdatePodGroupQueue(t *testing.T) {
+	newPodGroup := func(name, queue string) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "c1",
+			},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue: queue,
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name           string
+		podGroups      []*schedulingv1alpha2.PodGroup
+		podGroupold    *schedulingv1alpha2.PodGroup
+		podGroupnew    *schedulingv1alpha2.PodGroup
+		ExpectedIndex  map[string][]string
+		ExpectedQueues []string
+	}{
+		{
+			Name:        "move the only podgroup of queue",
+			podGroups:   []*schedulingv1alpha2.PodGroup{newPodGroup("pg1", "queueA")},
+			podGroupold: newPodGroup("pg1", "queueA"),
+			podGroupnew: newPodGroup("pg1", "queueB"),
+			ExpectedIndex: map[string][]string{
+				"queueB": {"c1/pg1"},
+			},
+			ExpectedQueues: []string{"queueA", "queueB"},
+		},
+		{
+			Name:        "move one of podgroups of queue",
+			podGroups:   []*schedulingv1alpha2.PodGroup{newPodGroup("pg1", "queueA"), newPodGroup("pg2", "queueA")},
+			podGroupold: newPodGroup("pg1", "queueA"),
+			podGroupnew: newPodGroup("pg1", "queueB"),
+			ExpectedIndex: map[string][]string{
+				"queueA": {"c1/pg2"},
+				"queueB": {"c1/pg1"},
+			},
+			ExpectedQueues: []string{"queueA", "queueB"},
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		for _, pg := range testcase.podGroups {
+			c.addPodGroup(pg)
+		}
+		for c.queue.Len() != 0 {
+			item, _ := c.queue.Get()
+			c.queue.Done(item)
+		}
+
+		c.updatePodGroup(testcase.podGroupold, testcase.podGroupnew)
+
+		index := map[string][]string{}
+		for queue := range c.podGroups {
+			index[queue] = c.getPodGroups(queue)
+			sort.Strings(index[queue])
+		}
+		if !reflect.DeepEqual(index, testcase.ExpectedIndex) {
+			t.Errorf("case %d (%s): expected index %v, got %v", i, testcase.Name, testcase.ExpectedIndex, index)
+		}
+
+		var queues []string
+		for c.queue.Len() != 0 {
+			item, _ := c.queue.Get()
+			queues = append(queues, item.(*schedulingv1alpha2.QueueRequest).Name)
+			c.queue.Done(item)
+		}
+		sort.Strings(queues)
+		if !reflect.DeepEqual(queues, testcase.ExpectedQueues) {
+			t.Errorf("case %d (%s): expected queues %v enqueued, got %v", i, testcase.Name, testcase.ExpectedQueues, queues)
+		}
+	}
+}
+
+func TestWatchNamespaces(t *testing.T) {
+	newPodGroup := func(namespace string, phase schedulingv1alpha2.PodGroupPhase) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pg1",
+				Namespace: namespace,
+			},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue: "c1",
+			},
+			Status: schedulingv1alpha2.PodGroupStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name            string
+		watchNamespaces []string
+		namespace       string
+		ExpectedIndex   map[string][]string
+	}{
+		{
+			Name:          "all namespaces are watched by default",
+			namespace:     "ns1",
+			ExpectedIndex: map[string][]string{"c1": {"ns1/pg1"}},
+		},
+		{
+			Name:            "podgroup in the watched namespace",
+			watchNamespaces: []string{"ns1"},
+			namespace:       "ns1",
+			ExpectedIndex:   map[string][]string{"c1": {"ns1/pg1"}},
+		},
+		{
+			Name:            "podgroup in one of the watched namespaces",
+			watchNamespaces: []string{"ns1", "ns2"},
+			namespace:       "ns2",
+			ExpectedIndex:   map[string][]string{"c1": {"ns2/pg1"}},
+		},
+		{
+			Name:            "podgroup out of the watched namespaces",
+			watchNamespaces: []string{"ns1", "ns2"},
+			namespace:       "ns3",
+			ExpectedIndex:   map[string][]string{},
+		},
+	}
+
+	for i, testcase := range testCases {
+		opt := NewOptions()
+		opt.WatchNamespaces = testcase.watchNamespaces
+		c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+
+		c.addPodGroup(newPodGroup(testcase.namespace, schedulingv1alpha2.PodGroupPending))
+		c.updatePodGroup(newPodGroup(testcase.namespace, schedulingv1alpha2.PodGroupPending),
+			newPodGroup(testcase.namespace, schedulingv1alpha2.PodGroupRunning))
+
+		index := map[string][]string{}
+		for queue := range c.podGroups {
+			index[queue] = c.getPodGroups(queue)
+		}
+		if !reflect.DeepEqual(index, testcase.ExpectedIndex) {
+			t.Errorf("case %d (%s): expected index %v, got %v", i, testcase.Name, testcase.ExpectedIndex, index)
+		}
+
+		// Both the add and the update enqueue the queue of a watched podgroup.
+		expectedLen := 0
+		if len(testcase.ExpectedIndex) != 0 {
+			expectedLen = 2
+		}
+		if c.queue.Len() != expectedLen {
+			t.Errorf("case %d (%s): expected %d queue requests, got %d", i, testcase.Name, expectedLen, c.queue.Len())
+		}
+	}
+}
+
 func TestSyncQueue(t *testing.T) {
 	namespace := "c1"
 
@@ -271,7 +600,7 @@ func TestSyncQueue(t *testing.T) {
 		c.queueInformer.Informer().GetIndexer().Add(testcase.queue)
 		c.vcClient.SchedulingV1alpha2().Queues().Create(testcase.queue)
 
-		err := c.syncQueue(testcase.queue, nil)
+		err := c.syncQueue(context.TODO(), testcase.queue, nil)
 		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(testcase.queue.Name, metav1.GetOptions{})
 		if err != nil && testcase.ExpectValue != item.Status.Pending {
 			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, c.queue.Len())
@@ -280,24 +609,1854 @@ func TestSyncQueue(t *testing.T) {
 
 }
 
-func TestProcessNextWorkItem(t *testing.T) {
+func TestSyncQueueStatus(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		phases      []schedulingv1alpha2.PodGroupPhase
+		ExpectValue schedulingv1alpha2.QueueStatus
+	}{
+		{
+			Name: "count podgroups by phase",
+			phases: []schedulingv1alpha2.PodGroupPhase{
+				schedulingv1alpha2.PodGroupPending,
+				schedulingv1alpha2.PodGroupRunning,
+				schedulingv1alpha2.PodGroupRunning,
+				schedulingv1alpha2.PodGroupUnknown,
+				schedulingv1alpha2.PodGroupCompleted,
+			},
+			ExpectValue: schedulingv1alpha2.QueueStatus{
+				Pending:   1,
+				Running:   2,
+				Unknown:   1,
+				Completed: 1,
+			},
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+
+		queue := &schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		}
+		c.queueInformer.Informer().GetIndexer().Add(queue)
+		c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+		for j, phase := range testcase.phases {
+			pg := &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pg%d", j), Namespace: "c1"},
+				Spec:       schedulingv1alpha2.PodGroupSpec{Queue: queue.Name},
+				Status:     schedulingv1alpha2.PodGroupStatus{Phase: phase},
+			}
+			c.pgInformer.Informer().GetIndexer().Add(pg)
+			c.addPodGroup(pg)
+		}
+
+		if err := c.syncQueue(context.TODO(), queue, nil); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+		if !reflect.DeepEqual(testcase.ExpectValue, item.Status) {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, item.Status)
+		}
+	}
+}
+
+func TestSyncQueueReclaimBudget(t *testing.T) {
+	c := newFakeController()
+	vcClient := c.vcClient.(*vcclient.Clientset)
+
+	// The budget is written by scheduler, the status is up to date otherwise.
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Status: schedulingv1alpha2.QueueStatus{
+			State:         schedulingv1alpha2.QueueStateOpen,
+			ReclaimBudget: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		},
+	}
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+	vcClient.SchedulingV1alpha2().Queues().Create(queue)
+	vcClient.ClearActions()
+
+	if err := c.syncQueue(context.TODO(), queue, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, action := range vcClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("expected status with the reclaim budget not updated, got %v", action)
+		}
+	}
+	item, _ := vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if !reflect.DeepEqual(item.Status.ReclaimBudget, queue.Status.ReclaimBudget) {
+		t.Errorf("expected reclaim budget %v, got %v", queue.Status.ReclaimBudget, item.Status.ReclaimBudget)
+	}
+}
+
+func TestSyncQueueUsedResources(t *testing.T) {
+	newPodGroup := func(name string, phase schedulingv1alpha2.PodGroupPhase, resources v1.ResourceList) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue:        "c1",
+				MinResources: &resources,
+			},
+			Status: schedulingv1alpha2.PodGroupStatus{Phase: phase},
+		}
+	}
+
+	c := newFakeController()
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+	}
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+	c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+	podGroups := []*schedulingv1alpha2.PodGroup{
+		newPodGroup("pg1", schedulingv1alpha2.PodGroupRunning, v1.ResourceList{
+			v1.ResourceCPU:   resource.MustParse("2"),
+			"nvidia.com/gpu": resource.MustParse("1"),
+		}),
+		newPodGroup("pg2", schedulingv1alpha2.PodGroupInqueue, v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+			"nvidia.com/gpu":  resource.MustParse("2"),
+			"example.com/foo": resource.MustParse("3"),
+		}),
+		// Pending and completed podgroups do not use resources of the queue.
+		newPodGroup("pg3", schedulingv1alpha2.PodGroupPending, v1.ResourceList{
+			"nvidia.com/gpu": resource.MustParse("4"),
+		}),
+		newPodGroup("pg4", schedulingv1alpha2.PodGroupCompleted, v1.ResourceList{
+			v1.ResourceCPU: resource.MustParse("8"),
+		}),
+	}
+	for _, pg := range podGroups {
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+		c.addPodGroup(pg)
+	}
+
+	if err := c.syncQueue(context.TODO(), queue, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+
+	expected := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2500m"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+		"nvidia.com/gpu":  resource.MustParse("3"),
+		"example.com/foo": resource.MustParse("3"),
+	}
+	if len(item.Status.Used) != len(expected) {
+		t.Errorf("expected used resources %v, got %v", expected, item.Status.Used)
+	}
+	for name, quantity := range expected {
+		used, found := item.Status.Used[name]
+		if !found || used.Cmp(quantity) != 0 {
+			t.Errorf("expected %s used %s, got %s", name, quantity.String(), used.String())
+		}
+	}
+
+	// The status is not updated again when the usage does not change.
+	c.queueInformer.Informer().GetIndexer().Update(item)
+	updated := false
+	c.vcClient.(*vcclient.Clientset).PrependReactor("update", "queues", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		updated = true
+		return false, nil, nil
+	})
+	if err := c.syncQueue(context.TODO(), item, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if updated {
+		t.Errorf("expected no update of unchanged status")
+	}
+}
+
+func TestAppendStateCondition(t *testing.T) {
+	conditions := func(n int) []schedulingv1alpha2.QueueCondition {
+		var cs []schedulingv1alpha2.QueueCondition
+		for i := 0; i < n; i++ {
+			cs = append(cs, schedulingv1alpha2.QueueCondition{Reason: fmt.Sprintf("r%d", i)})
+		}
+		return cs
+	}
+
+	testCases := []struct {
+		Name          string
+		previous      schedulingv1alpha2.QueueState
+		status        schedulingv1alpha2.QueueStatus
+		ExpectChanged bool
+		ExpectLen     int
+		ExpectFirst   string
+		ExpectMessage string
+	}{
+		{
+			Name:     "state not changed",
+			previous: schedulingv1alpha2.QueueStateOpen,
+			status: schedulingv1alpha2.QueueStatus{
+				State:      schedulingv1alpha2.QueueStateOpen,
+				Conditions: conditions(1),
+			},
+			ExpectLen:   1,
+			ExpectFirst: "r0",
+		},
+		{
+			Name:     "initial state",
+			previous: "",
+			status: schedulingv1alpha2.QueueStatus{
+				State: schedulingv1alpha2.QueueStateOpen,
+			},
+			ExpectChanged: true,
+			ExpectLen:     1,
+			ExpectFirst:   string(schedulingv1alpha2.CloseQueueAction),
+			ExpectMessage: "Queue state is set to Open",
+		},
+		{
+			Name:     "oldest condition is dropped",
+			previous: schedulingv1alpha2.QueueStateOpen,
+			status: schedulingv1alpha2.QueueStatus{
+				State:      schedulingv1alpha2.QueueStateClosing,
+				Conditions: conditions(maxQueueConditions),
+			},
+			ExpectChanged: true,
+			ExpectLen:     maxQueueConditions,
+			ExpectFirst:   "r1",
+			ExpectMessage: "Queue state changed from Open to Closing",
+		},
+	}
+
+	for i, testcase := range testCases {
+		origin := testcase.status.Conditions
+		changed := appendStateCondition(&testcase.status, testcase.previous, schedulingv1alpha2.CloseQueueAction)
+		if changed != testcase.ExpectChanged {
+			t.Errorf("case %d (%s): expected changed %v, got %v", i, testcase.Name, testcase.ExpectChanged, changed)
+		}
+		got := testcase.status.Conditions
+		if len(got) != testcase.ExpectLen {
+			t.Fatalf("case %d (%s): expected %d conditions, got %d", i, testcase.Name, testcase.ExpectLen, len(got))
+		}
+		if got[0].Reason != testcase.ExpectFirst {
+			t.Errorf("case %d (%s): expected first reason %s, got %s", i, testcase.Name, testcase.ExpectFirst, got[0].Reason)
+		}
+		if !changed {
+			continue
+		}
+		last := got[len(got)-1]
+		if last.Type != testcase.status.State || last.PreviousState != testcase.previous ||
+			last.Message != testcase.ExpectMessage || last.LastTransitionTime.IsZero() {
+			t.Errorf("case %d (%s): unexpected condition %v", i, testcase.Name, last)
+		}
+		for j := range origin {
+			if origin[j].Reason != fmt.Sprintf("r%d", j) {
+				t.Errorf("case %d (%s): conditions of origin status are modified", i, testcase.Name)
+			}
+		}
+	}
+}
+
+func TestSyncQueueStateCondition(t *testing.T) {
+	c := newFakeController()
+
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Status:     schedulingv1alpha2.QueueStatus{State: schedulingv1alpha2.QueueStateOpen},
+	}
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+	c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+	ctx := withQueueAction(context.TODO(), schedulingv1alpha2.CloseQueueAction)
+	if err := c.syncQueue(ctx, queue, func(status *schedulingv1alpha2.QueueStatus, podGroupList []string) {
+		status.State = schedulingv1alpha2.QueueStateClosed
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if len(item.Status.Conditions) != 1 {
+		t.Fatalf("expected 1 condition, got %v", item.Status.Conditions)
+	}
+	condition := item.Status.Conditions[0]
+	if condition.Type != schedulingv1alpha2.QueueStateClosed ||
+		condition.PreviousState != schedulingv1alpha2.QueueStateOpen ||
+		condition.Reason != string(schedulingv1alpha2.CloseQueueAction) {
+		t.Errorf("unexpected condition %v", condition)
+	}
+
+	// Sync again without state change does not add condition.
+	if err := c.syncQueue(context.TODO(), item, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	item, _ = c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if len(item.Status.Conditions) != 1 {
+		t.Errorf("expected 1 condition, got %v", item.Status.Conditions)
+	}
+}
+
+func TestSyncParentQueue(t *testing.T) {
 	testCases := []struct {
 		Name        string
+		podGroups   []*schedulingv1alpha2.PodGroup
+		queues      []*schedulingv1alpha2.Queue
+		parent      string
 		ExpectValue int32
 	}{
 		{
-			Name:        "processNextWorkItem",
-			ExpectValue: 0,
+			Name: "parent queue counts podgroups of descendants",
+			podGroups: []*schedulingv1alpha2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+					Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "team1"},
+					Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pg2", Namespace: "c1"},
+					Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "subteam1"},
+					Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pg3", Namespace: "c1"},
+					Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "org"},
+					Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+				},
+			},
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "org"},
+					Spec:       schedulingv1alpha2.QueueSpec{Weight: 1},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "team1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Weight: 1, Parent: "org"},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "subteam1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Weight: 1, Parent: "team1"},
+				},
+			},
+			parent:      "org",
+			ExpectValue: 3,
+		},
+		{
+			Name: "queues in a cycle are not aggregated",
+			podGroups: []*schedulingv1alpha2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+					Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q1"},
+					Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pg2", Namespace: "c1"},
+					Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "q2"},
+					Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+				},
+			},
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Weight: 1, Parent: "q2"},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "q2"},
+					Spec:       schedulingv1alpha2.QueueSpec{Weight: 1, Parent: "q1"},
+				},
+			},
+			parent:      "q1",
+			ExpectValue: 1,
 		},
 	}
 
 	for i, testcase := range testCases {
 		c := newFakeController()
-		c.queue.Add("test")
-		bVal := c.processNextWorkItem()
-		fmt.Println("The value of boolean is ", bVal)
-		if c.queue.Len() != 0 {
-			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, c.queue.Len())
+
+		for _, queue := range testcase.queues {
+			c.queueInformer.Informer().GetIndexer().Add(queue)
+			c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+			c.addQueue(queue)
 		}
+		for _, pg := range testcase.podGroups {
+			c.pgInformer.Informer().GetIndexer().Add(pg)
+			c.addPodGroup(pg)
+		}
+
+		queue, _ := c.queueLister.Get(testcase.parent)
+		if err := c.syncQueue(context.TODO(), queue, nil); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(testcase.parent, metav1.GetOptions{})
+		if testcase.ExpectValue != item.Status.Pending {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, item.Status.Pending)
+		}
+	}
+}
+
+func TestValidateParent(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		queues      []*schedulingv1alpha2.Queue
+		queue       string
+		ExpectValue bool
+	}{
+		{
+			Name: "root queue",
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "org"},
+				},
+			},
+			queue:       "org",
+			ExpectValue: true,
+		},
+		{
+			Name: "existing parent",
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "org"},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "team1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Parent: "org"},
+				},
+			},
+			queue:       "team1",
+			ExpectValue: true,
+		},
+		{
+			Name: "missing parent",
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "team1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Parent: "org"},
+				},
+			},
+			queue:       "team1",
+			ExpectValue: false,
+		},
+		{
+			Name: "cycle",
+			queues: []*schedulingv1alpha2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+					Spec:       schedulingv1alpha2.QueueSpec{Parent: "q2"},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "q2"},
+					Spec:       schedulingv1alpha2.QueueSpec{Parent: "q1"},
+				},
+			},
+			queue:       "q1",
+			ExpectValue: false,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		for _, queue := range testcase.queues {
+			c.queueInformer.Informer().GetIndexer().Add(queue)
+		}
+
+		queue, _ := c.queueLister.Get(testcase.queue)
+		err := c.validateParent(queue)
+		if testcase.ExpectValue != (err == nil) {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, err)
+		}
+	}
+}
+
+func TestUpdateQueueParent(t *testing.T) {
+	c := newFakeController()
+
+	oldQueue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "team1", ResourceVersion: "1"},
+		Spec:       schedulingv1alpha2.QueueSpec{Parent: "org1"},
+	}
+	newQueue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "team1", ResourceVersion: "2"},
+		Spec:       schedulingv1alpha2.QueueSpec{Parent: "org2"},
+	}
+
+	c.addQueue(oldQueue)
+	c.updateQueue(oldQueue, newQueue)
+
+	if children := c.getChildQueues("org1"); len(children) != 0 {
+		t.Errorf("expected no children of org1, got %v", children)
+	}
+	if children := c.getChildQueues("org2"); len(children) != 1 {
+		t.Errorf("expected one child of org2, got %v", children)
+	}
+}
+
+func TestHandleQueueErr(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		maxRetries  int
+		failures    int
+		ExpectValue int
+	}{
+		{
+			Name:        "drop request after max retries",
+			maxRetries:  2,
+			failures:    3,
+			ExpectValue: 0,
+		},
+		{
+			Name:        "retry forever",
+			maxRetries:  0,
+			failures:    20,
+			ExpectValue: 20,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		c.queueMaxRetries = testcase.maxRetries
+
+		req := &schedulingv1alpha2.QueueRequest{
+			Name:   "c1",
+			Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+			Action: schedulingv1alpha2.SyncQueueAction,
+		}
+		for f := 0; f < testcase.failures; f++ {
+			c.handleQueueErr(fmt.Errorf("failed to sync queue"), req)
+		}
+
+		if testcase.ExpectValue != c.queue.NumRequeues(req) {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, c.queue.NumRequeues(req))
+		}
+	}
+}
+
+func TestHandleQueueErrClassification(t *testing.T) {
+	conflict := apierrors.NewConflict(schedulingv1alpha2.Resource("queues"), "c1", fmt.Errorf("modified"))
+	invalid := apierrors.NewInvalid(schedulingv1alpha2.SchemeGroupVersion.WithKind("Queue").GroupKind(), "c1", nil)
+
+	testCases := []struct {
+		name        string
+		err         error
+		expectRetry bool
+	}{
+		{
+			name:        "transient error of cache",
+			err:         fmt.Errorf("get queue c1 failed for timeout"),
+			expectRetry: true,
+		},
+		{
+			name: "queue not found",
+			err:  &ErrQueueNotFound{Queue: "c1"},
+		},
+		{
+			name: "invalid state",
+			err:  &ErrInvalidState{Queue: "c1", State: "Pausing"},
+		},
+		{
+			name:        "state execution failed for conflict",
+			err:         &ErrStateExecution{Queue: "c1", Action: schedulingv1alpha2.SyncQueueAction, Err: conflict},
+			expectRetry: true,
+		},
+		{
+			name: "state execution rejected as invalid",
+			err:  &ErrStateExecution{Queue: "c1", Action: schedulingv1alpha2.SyncQueueAction, Err: invalid},
+		},
+	}
+
+	for _, testcase := range testCases {
+		if retriable := isRetriable(testcase.err); retriable != testcase.expectRetry {
+			t.Errorf("case %s: expected retriable %v, got %v", testcase.name, testcase.expectRetry, retriable)
+		}
+
+		c := newFakeController()
+		req := &schedulingv1alpha2.QueueRequest{
+			Name:   "c1",
+			Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+			Action: schedulingv1alpha2.SyncQueueAction,
+		}
+		c.handleQueueErr(testcase.err, req)
+
+		expected := 0
+		if testcase.expectRetry {
+			expected = 1
+		}
+		if requeues := c.queue.NumRequeues(req); requeues != expected {
+			t.Errorf("case %s: expected %d requeues, got %d", testcase.name, expected, requeues)
+		}
+	}
+}
+
+func TestHandleQueueErrorTypes(t *testing.T) {
+	c := newFakeController()
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Finalizers: []string{queueFinalizer}},
+		Status:     schedulingv1alpha2.QueueStatus{State: "Pausing"},
+	}
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+
+	newRequest := func(name string) *schedulingv1alpha2.QueueRequest {
+		return &schedulingv1alpha2.QueueRequest{
+			Name:   name,
+			Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+			Action: schedulingv1alpha2.SyncQueueAction,
+		}
+	}
+
+	err := c.handleQueue(context.TODO(), newRequest("deleted"))
+	if _, ok := err.(*ErrQueueNotFound); !ok {
+		t.Errorf("expected ErrQueueNotFound, got %v", err)
+	}
+
+	err = c.handleQueue(context.TODO(), newRequest("c1"))
+	if e, ok := err.(*ErrInvalidState); !ok || e.State != "Pausing" {
+		t.Errorf("expected ErrInvalidState, got %v", err)
+	}
+
+	// The API error of adding the finalizer is kept as the cause of the failure.
+	c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "c2"}})
+	invalid := apierrors.NewInvalid(schedulingv1alpha2.SchemeGroupVersion.WithKind("Queue").GroupKind(), "c2", nil)
+	c.vcClient.(*vcclient.Clientset).PrependReactor("update", "queues", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, invalid
+	})
+	err = c.handleQueue(context.TODO(), newRequest("c2"))
+	var execErr *ErrStateExecution
+	if !errors.As(err, &execErr) || !apierrors.IsInvalid(errors.Unwrap(err)) {
+		t.Errorf("expected ErrStateExecution caused by the invalid error, got %v", err)
+	}
+}
+
+func TestHandleQueueErrWithoutRequest(t *testing.T) {
+	c := newFakeController()
+	c.queueMaxRetries = 1
+
+	// Permanent and exhausted failures of an object other than a queue request are dropped without events.
+	for _, err := range []error{&ErrInvalidState{Queue: "c1", State: "Pausing"}, fmt.Errorf("failed to sync queue")} {
+		obj := "c1"
+		c.handleQueueErr(err, obj)
+		c.handleQueueErr(err, obj)
+		if requeues := c.queue.NumRequeues(obj); requeues != 0 {
+			t.Errorf("expected %v to be dropped, got %d requeues", err, requeues)
+		}
+	}
+}
+
+func TestDrainQueue(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+
+	testCases := []struct {
+		Name            string
+		podGroup        *schedulingv1alpha2.PodGroup
+		queue           *schedulingv1alpha2.Queue
+		ExpectFinalizer bool
+	}{
+		{
+			Name: "keep finalizer when podgroups remain",
+			podGroup: &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pg1",
+					Namespace: "c1",
+				},
+				Spec: schedulingv1alpha2.PodGroupSpec{
+					Queue: "c1",
+				},
+				Status: schedulingv1alpha2.PodGroupStatus{
+					Phase: schedulingv1alpha2.PodGroupRunning,
+				},
+			},
+			queue: &schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "c1",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{queueFinalizer},
+				},
+			},
+			ExpectFinalizer: true,
+		},
+		{
+			Name: "remove finalizer when podgroups are completed",
+			podGroup: &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pg1",
+					Namespace: "c1",
+				},
+				Spec: schedulingv1alpha2.PodGroupSpec{
+					Queue: "c1",
+				},
+				Status: schedulingv1alpha2.PodGroupStatus{
+					Phase: schedulingv1alpha2.PodGroupCompleted,
+				},
+			},
+			queue: &schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "c1",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{queueFinalizer},
+				},
+			},
+			ExpectFinalizer: false,
+		},
+		{
+			Name: "remove finalizer when podgroups are failed",
+			podGroup: &schedulingv1alpha2.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pg1",
+					Namespace: "c1",
+				},
+				Spec: schedulingv1alpha2.PodGroupSpec{
+					Queue:     "c1",
+					MinMember: 2,
+				},
+				Status: schedulingv1alpha2.PodGroupStatus{
+					Phase:     schedulingv1alpha2.PodGroupPending,
+					Failed:    1,
+					Succeeded: 1,
+				},
+			},
+			queue: &schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "c1",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{queueFinalizer},
+				},
+			},
+			ExpectFinalizer: false,
+		},
+		{
+			Name: "remove finalizer when queue is drained",
+			queue: &schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "c1",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{queueFinalizer},
+				},
+			},
+			ExpectFinalizer: false,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+
+		if testcase.podGroup != nil {
+			c.pgInformer.Informer().GetIndexer().Add(testcase.podGroup)
+			c.addPodGroup(testcase.podGroup)
+		}
+		c.queueInformer.Informer().GetIndexer().Add(testcase.queue)
+		c.vcClient.SchedulingV1alpha2().Queues().Create(testcase.queue)
+
+		if err := c.handleQueue(context.TODO(), &schedulingv1alpha2.QueueRequest{
+			Name:   testcase.queue.Name,
+			Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+			Action: schedulingv1alpha2.SyncQueueAction,
+		}); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(testcase.queue.Name, metav1.GetOptions{})
+		if item.Status.State != schedulingv1alpha2.QueueStateDraining {
+			t.Errorf("case %d (%s): expected state %s, got %s", i, testcase.Name,
+				schedulingv1alpha2.QueueStateDraining, item.Status.State)
+		}
+		if testcase.ExpectFinalizer != hasFinalizer(item) {
+			t.Errorf("case %d (%s): expected finalizer: %v, got %v ", i, testcase.Name, testcase.ExpectFinalizer, item.Finalizers)
+		}
+	}
+}
+
+func TestCordonQueue(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Annotations    map[string]string
+		Action         schedulingv1alpha2.QueueAction
+		ExpectCordoned bool
+	}{
+		{
+			Name:           "cordon queue",
+			Action:         schedulingv1alpha2.CordonQueueAction,
+			ExpectCordoned: true,
+		},
+		{
+			Name:           "cordon cordoned queue",
+			Annotations:    map[string]string{schedulingv1alpha2.CordonedAnnotationKey: "true"},
+			Action:         schedulingv1alpha2.CordonQueueAction,
+			ExpectCordoned: true,
+		},
+		{
+			Name:           "uncordon queue",
+			Annotations:    map[string]string{schedulingv1alpha2.CordonedAnnotationKey: "true"},
+			Action:         schedulingv1alpha2.UncordonQueueAction,
+			ExpectCordoned: false,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+
+		queue := &schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "c1",
+				Annotations: testcase.Annotations,
+				Finalizers:  []string{queueFinalizer},
+			},
+			Spec: schedulingv1alpha2.QueueSpec{
+				State: schedulingv1alpha2.QueueStateOpen,
+			},
+			Status: schedulingv1alpha2.QueueStatus{
+				State: schedulingv1alpha2.QueueStateOpen,
+			},
+		}
+		c.queueInformer.Informer().GetIndexer().Add(queue)
+		c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+
+		if err := c.handleQueue(context.TODO(), &schedulingv1alpha2.QueueRequest{
+			Name:   queue.Name,
+			Event:  schedulingv1alpha2.QueueCommandIssuedEvent,
+			Action: testcase.Action,
+		}); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+		if isCordoned(item) != testcase.ExpectCordoned {
+			t.Errorf("case %d (%s): expected cordoned %v, got annotations %v", i, testcase.Name,
+				testcase.ExpectCordoned, item.Annotations)
+		}
+		if item.Spec.State != schedulingv1alpha2.QueueStateOpen || item.Status.State != schedulingv1alpha2.QueueStateOpen {
+			t.Errorf("case %d (%s): expected queue to stay open, got spec %s, status %s", i, testcase.Name,
+				item.Spec.State, item.Status.State)
+		}
+	}
+}
+
+func TestHandleCommandBatch(t *testing.T) {
+	now := time.Now()
+	newCommand := func(name, queue string, action schedulingv1alpha2.QueueAction, created time.Time) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+				Kind:       "Queue",
+				Name:       queue,
+			},
+			Action: string(action),
+		}
+	}
+	labeled := func(cmd *busv1alpha1.Command) *busv1alpha1.Command {
+		cmd.Labels = map[string]string{busv1alpha1.QueueNameLabelKey: cmd.TargetObject.Name}
+		return cmd
+	}
+
+	testCases := []struct {
+		Name              string
+		batchSize         int
+		commands          []*busv1alpha1.Command
+		forbidCollection  bool
+		ExpectAction      schedulingv1alpha2.QueueAction
+		ExpectDeleted     int
+		ExpectCollections int
+	}{
+		{
+			Name:      "coalesce commands of the same queue",
+			batchSize: 10,
+			commands: []*busv1alpha1.Command{
+				newCommand("cmd1", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-2*time.Second)),
+				newCommand("cmd2", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-time.Second)),
+				newCommand("cmd3", "q1", schedulingv1alpha2.OpenQueueAction, now),
+				newCommand("cmd4", "q2", schedulingv1alpha2.CloseQueueAction, now),
+			},
+			ExpectAction:  schedulingv1alpha2.OpenQueueAction,
+			ExpectDeleted: 3,
+		},
+		{
+			Name:      "batch size limits coalesced commands",
+			batchSize: 2,
+			commands: []*busv1alpha1.Command{
+				newCommand("cmd1", "q1", schedulingv1alpha2.OpenQueueAction, now.Add(-2*time.Second)),
+				newCommand("cmd2", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-time.Second)),
+				newCommand("cmd3", "q1", schedulingv1alpha2.OpenQueueAction, now),
+			},
+			ExpectAction:  schedulingv1alpha2.CloseQueueAction,
+			ExpectDeleted: 2,
+		},
+		{
+			Name:      "labeled commands deleted by one call",
+			batchSize: 10,
+			commands: []*busv1alpha1.Command{
+				labeled(newCommand("cmd1", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-2*time.Second))),
+				labeled(newCommand("cmd2", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-time.Second))),
+				labeled(newCommand("cmd3", "q1", schedulingv1alpha2.OpenQueueAction, now)),
+				labeled(newCommand("cmd4", "q2", schedulingv1alpha2.CloseQueueAction, now)),
+			},
+			ExpectAction:      schedulingv1alpha2.OpenQueueAction,
+			ExpectDeleted:     3,
+			ExpectCollections: 1,
+		},
+		{
+			Name:      "labeled commands beyond the batch are not deleted by one call",
+			batchSize: 2,
+			commands: []*busv1alpha1.Command{
+				labeled(newCommand("cmd1", "q1", schedulingv1alpha2.OpenQueueAction, now.Add(-2*time.Second))),
+				labeled(newCommand("cmd2", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-time.Second))),
+				labeled(newCommand("cmd3", "q1", schedulingv1alpha2.OpenQueueAction, now)),
+			},
+			ExpectAction:  schedulingv1alpha2.CloseQueueAction,
+			ExpectDeleted: 2,
+		},
+		{
+			Name:      "commands deleted one by one if DeleteCollection is forbidden",
+			batchSize: 10,
+			commands: []*busv1alpha1.Command{
+				labeled(newCommand("cmd1", "q1", schedulingv1alpha2.CloseQueueAction, now.Add(-time.Second))),
+				labeled(newCommand("cmd2", "q1", schedulingv1alpha2.OpenQueueAction, now)),
+			},
+			forbidCollection: true,
+			ExpectAction:     schedulingv1alpha2.OpenQueueAction,
+			ExpectDeleted:    2,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		c.commandBatchSize = testcase.batchSize
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}})
+		actions := handledActions(c)
+
+		vcClient := c.vcClient.(*vcclient.Clientset)
+		collections := 0
+		collected := map[string]bool{}
+		vcClient.PrependReactor("delete-collection", "commands", func(action kubetesting.Action) (bool, runtime.Object, error) {
+			if testcase.forbidCollection {
+				return true, nil, apierrors.NewForbidden(busv1alpha1.SchemeGroupVersion.WithResource("commands").GroupResource(),
+					"", fmt.Errorf("deletecollection is not allowed"))
+			}
+			collections++
+			selector := action.(kubetesting.DeleteCollectionAction).GetListRestrictions().Labels
+			for _, cmd := range testcase.commands {
+				if cmd.Namespace == action.GetNamespace() && selector.Matches(labels.Set(cmd.Labels)) {
+					collected[cmd.Name] = true
+				}
+			}
+			return true, nil, nil
+		})
+
+		for _, cmd := range testcase.commands {
+			c.cmdInformer.Informer().GetIndexer().Add(cmd)
+			c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Create(cmd)
+		}
+
+		if err := c.handleCommandBatch(context.TODO(), testcase.commands[0]); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		if len(*actions) != 1 || (*actions)[0] != testcase.ExpectAction {
+			t.Errorf("case %d (%s): expected action %s applied, got %v", i, testcase.Name, testcase.ExpectAction, *actions)
+		}
+
+		deleted := 0
+		for _, cmd := range testcase.commands {
+			if _, err := c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Get(cmd.Name, metav1.GetOptions{}); err != nil || collected[cmd.Name] {
+				deleted++
+			}
+		}
+		if deleted != testcase.ExpectDeleted {
+			t.Errorf("case %d (%s): expected %d deleted commands, got %d", i, testcase.Name, testcase.ExpectDeleted, deleted)
+		}
+		if collections != testcase.ExpectCollections {
+			t.Errorf("case %d (%s): expected %d DeleteCollection calls, got %d", i, testcase.Name,
+				testcase.ExpectCollections, collections)
+		}
+	}
+}
+
+func TestProcessNextWorkItem(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		ExpectValue int32
+	}{
+		{
+			Name:        "processNextWorkItem",
+			ExpectValue: 0,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		c.queue.Add("test")
+		bVal := c.processNextWorkItem(context.TODO())
+		fmt.Println("The value of boolean is ", bVal)
+		if c.queue.Len() != 0 {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, testcase.Name, testcase.ExpectValue, c.queue.Len())
+		}
+	}
+}
+
+func TestReady(t *testing.T) {
+	c := newFakeController()
+	if c.Ready() {
+		t.Errorf("expected controller not ready before caches are synced")
+	}
+
+	// The fake clientset is unable to list, so the caches never sync by themselves.
+	synced := func() bool { return true }
+	c.queueSynced, c.pgSynced, c.cmdSynced, c.pcSynced = synced, synced, synced, synced
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go c.Run(stopCh)
+
+	if err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return c.Ready(), nil
+	}); err != nil {
+		t.Errorf("expected controller ready after caches are synced, got %v", err)
+	}
+}
+
+func TestCallWithContext(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	testCases := []struct {
+		Name        string
+		Cancel      bool
+		Call        func() error
+		ExpectedErr error
+	}{
+		{
+			Name:        "call returns",
+			Call:        func() error { return fmt.Errorf("conflict") },
+			ExpectedErr: fmt.Errorf("conflict"),
+		},
+		{
+			Name:   "cancelled while call blocks",
+			Cancel: true,
+			Call: func() error {
+				<-block
+				return nil
+			},
+			ExpectedErr: context.Canceled,
+		},
+	}
+
+	for i, testcase := range testCases {
+		ctx, cancel := context.WithCancel(context.Background())
+		if testcase.Cancel {
+			time.AfterFunc(10*time.Millisecond, cancel)
+		}
+
+		err := callWithContext(ctx, testcase.Call)
+		if !reflect.DeepEqual(err, testcase.ExpectedErr) {
+			t.Errorf("case %d (%s): expected error %v, got %v", i, testcase.Name, testcase.ExpectedErr, err)
+		}
+		cancel()
+	}
+}
+
+func TestOwnPodGroups(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	jobRef := metav1.OwnerReference{
+		APIVersion: "batch.volcano.sh/v1alpha1",
+		Kind:       "Job",
+		Name:       "job1",
+		UID:        "uid-job1",
+		Controller: func() *bool { b := true; return &b }(),
+	}
+
+	newQueue := func(annotated, deleted bool, finalizers ...string) *schedulingv1alpha2.Queue {
+		queue := &schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "c1",
+				UID:        "uid-c1",
+				Finalizers: append([]string{queueFinalizer}, finalizers...),
+			},
+		}
+		if annotated {
+			queue.Annotations = map[string]string{schedulingv1alpha2.OwnPodGroupsAnnotationKey: "true"}
+		}
+		if deleted {
+			queue.DeletionTimestamp = &deletionTimestamp
+		}
+		return queue
+	}
+	newPodGroup := func(name string, refs ...metav1.OwnerReference) *schedulingv1alpha2.PodGroup {
+		return &schedulingv1alpha2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "c1",
+				OwnerReferences: refs,
+			},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue: "c1",
+			},
+		}
+	}
+	queueRef := *metav1.NewControllerRef(newQueue(true, false), schedulingv1alpha2.SchemeGroupVersion.WithKind("Queue"))
+
+	testCases := []struct {
+		Name      string
+		queue     *schedulingv1alpha2.Queue
+		podGroups []*schedulingv1alpha2.PodGroup
+		// ExpectedOwners is the controller UID of the podgroups after sync, "" means no controller;
+		// a missing podgroup means it is deleted.
+		ExpectedOwners map[string]string
+	}{
+		{
+			Name:           "podgroups are not adopted without annotation",
+			queue:          newQueue(false, false),
+			podGroups:      []*schedulingv1alpha2.PodGroup{newPodGroup("pg1")},
+			ExpectedOwners: map[string]string{"pg1": ""},
+		},
+		{
+			Name:      "podgroups without controller are adopted",
+			queue:     newQueue(true, false),
+			podGroups: []*schedulingv1alpha2.PodGroup{newPodGroup("pg1"), newPodGroup("pg2", jobRef)},
+			ExpectedOwners: map[string]string{
+				"pg1": "uid-c1",
+				"pg2": "uid-job1",
+			},
+		},
+		{
+			Name:           "owned podgroups are deleted with queue",
+			queue:          newQueue(true, true),
+			podGroups:      []*schedulingv1alpha2.PodGroup{newPodGroup("pg1", queueRef), newPodGroup("pg2", jobRef)},
+			ExpectedOwners: map[string]string{"pg2": "uid-job1"},
+		},
+		{
+			Name:           "owned podgroups are orphaned",
+			queue:          newQueue(true, true, metav1.FinalizerOrphanDependents),
+			podGroups:      []*schedulingv1alpha2.PodGroup{newPodGroup("pg1", queueRef)},
+			ExpectedOwners: map[string]string{"pg1": "uid-c1"},
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+
+		for _, pg := range testcase.podGroups {
+			c.pgInformer.Informer().GetIndexer().Add(pg)
+			c.vcClient.SchedulingV1alpha2().PodGroups(pg.Namespace).Create(pg)
+			c.addPodGroup(pg)
+		}
+		c.queueInformer.Informer().GetIndexer().Add(testcase.queue)
+		c.vcClient.SchedulingV1alpha2().Queues().Create(testcase.queue)
+
+		if err := c.handleQueue(context.TODO(), &schedulingv1alpha2.QueueRequest{
+			Name:   testcase.queue.Name,
+			Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+			Action: schedulingv1alpha2.SyncQueueAction,
+		}); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		for _, pg := range testcase.podGroups {
+			expected, exist := testcase.ExpectedOwners[pg.Name]
+			item, err := c.vcClient.SchedulingV1alpha2().PodGroups(pg.Namespace).Get(pg.Name, metav1.GetOptions{})
+			if err != nil {
+				if exist {
+					t.Errorf("case %d (%s): expected podgroup %s exists, got %v", i, testcase.Name, pg.Name, err)
+				}
+				continue
+			}
+			if !exist {
+				t.Errorf("case %d (%s): expected podgroup %s deleted", i, testcase.Name, pg.Name)
+				continue
+			}
+
+			owner := ""
+			if ref := metav1.GetControllerOf(item); ref != nil {
+				owner = string(ref.UID)
+			}
+			if owner != expected {
+				t.Errorf("case %d (%s): expected controller of podgroup %s <%s>, got <%s>",
+					i, testcase.Name, pg.Name, expected, owner)
+			}
+		}
+	}
+}
+
+func TestEnqueueAllQueues(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		queues         []string
+		ExpectedQueues []string
+	}{
+		{
+			Name: "no queue",
+		},
+		{
+			Name:           "all queues are enqueued",
+			queues:         []string{"q1", "q2", "q3"},
+			ExpectedQueues: []string{"q1", "q2", "q3"},
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		for _, name := range testcase.queues {
+			c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			})
+		}
+
+		c.enqueueAllQueues()
+
+		var queues []string
+		for c.queue.Len() != 0 {
+			item, _ := c.queue.Get()
+			req := item.(*schedulingv1alpha2.QueueRequest)
+			if req.Action != schedulingv1alpha2.SyncQueueAction {
+				t.Errorf("case %d (%s): expected action %s, got %s", i, testcase.Name, schedulingv1alpha2.SyncQueueAction, req.Action)
+			}
+			queues = append(queues, req.Name)
+			c.queue.Done(item)
+		}
+		sort.Strings(queues)
+		if !reflect.DeepEqual(queues, testcase.ExpectedQueues) {
+			t.Errorf("case %d (%s): expected queues %v enqueued, got %v", i, testcase.Name, testcase.ExpectedQueues, queues)
+		}
+	}
+}
+
+func TestValidatePriority(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		priorityClasses []int32
+		priority        int32
+		ExpectErr       bool
+	}{
+		{
+			Name:     "no priority",
+			priority: 0,
+		},
+		{
+			Name:      "no PriorityClass",
+			priority:  100,
+			ExpectErr: true,
+		},
+		{
+			Name:            "priority of a PriorityClass",
+			priorityClasses: []int32{100, 1000},
+			priority:        1000,
+		},
+		{
+			Name:            "priority within range of PriorityClasses",
+			priorityClasses: []int32{100, 1000},
+			priority:        500,
+			ExpectErr:       true,
+		},
+		{
+			Name:            "priority out of range",
+			priorityClasses: []int32{100, 1000},
+			priority:        2000,
+			ExpectErr:       true,
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		for j, value := range testcase.priorityClasses {
+			c.pcInformer.Informer().GetIndexer().Add(&schedulingv1beta1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pc%d", j)},
+				Value:      value,
+			})
+		}
+
+		err := c.validatePriority(&schedulingv1alpha2.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       schedulingv1alpha2.QueueSpec{Priority: testcase.priority},
+		})
+		if (err != nil) != testcase.ExpectErr {
+			t.Errorf("case %d (%s): expected error %v, got %v", i, testcase.Name, testcase.ExpectErr, err)
+		}
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	newCommand := func(action string) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cmd1",
+				Namespace: "default",
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+				Kind:       "Queue",
+				Name:       "q1",
+			},
+			Action: action,
+		}
+	}
+
+	testCases := []struct {
+		Name         string
+		command      *busv1alpha1.Command
+		ExpectAction schedulingv1alpha2.QueueAction
+	}{
+		{
+			Name:    "unknown action is dropped",
+			command: newCommand("CloseQeueu"),
+		},
+	}
+	for _, action := range schedulingv1alpha2.ValidActions() {
+		testCases = append(testCases, struct {
+			Name         string
+			command      *busv1alpha1.Command
+			ExpectAction schedulingv1alpha2.QueueAction
+		}{
+			Name:         fmt.Sprintf("known action %s", action),
+			command:      newCommand(string(action)),
+			ExpectAction: action,
+		})
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		actions := handledActions(c)
+		c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Create(testcase.command)
+
+		if err := c.handleCommand(context.TODO(), testcase.command); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		if _, err := c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Get(testcase.command.Name, metav1.GetOptions{}); err == nil {
+			t.Errorf("case %d (%s): expected command deleted", i, testcase.Name)
+		}
+
+		var action schedulingv1alpha2.QueueAction
+		if len(*actions) != 0 {
+			action = (*actions)[0]
+		}
+		if action != testcase.ExpectAction {
+			t.Errorf("case %d (%s): expected action %q applied, got %q", i, testcase.Name, testcase.ExpectAction, action)
+		}
+	}
+}
+
+func TestHandleQueueUnknownAction(t *testing.T) {
+	c := newFakeController()
+
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	}
+	c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+
+	req := &schedulingv1alpha2.QueueRequest{
+		Name:   "q1",
+		Event:  schedulingv1alpha2.QueueCommandIssuedEvent,
+		Action: "DrainQueue",
+	}
+	if err := c.handleQueue(context.TODO(), req); err != nil {
+		t.Errorf("expected request with unknown action dropped, got error %v", err)
+	}
+
+	item, _ := c.vcClient.SchedulingV1alpha2().Queues().Get("q1", metav1.GetOptions{})
+	if hasFinalizer(item) {
+		t.Errorf("expected queue not handled for unknown action")
+	}
+}
+
+func TestHandleCommandRestartBeforeDelete(t *testing.T) {
+	vcClient := vcclient.NewSimpleClientset()
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "q1",
+			Finalizers: []string{queueFinalizer},
+		},
+		Spec: schedulingv1alpha2.QueueSpec{
+			State: schedulingv1alpha2.QueueStateOpen,
+		},
+		Status: schedulingv1alpha2.QueueStatus{
+			State: schedulingv1alpha2.QueueStateOpen,
+		},
+	}
+	vcClient.SchedulingV1alpha2().Queues().Create(queue)
+	cmd := &busv1alpha1.Command{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cmd1",
+			Namespace: "default",
+		},
+		TargetObject: &metav1.OwnerReference{
+			APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+			Kind:       "Queue",
+			Name:       "q1",
+		},
+		Action: string(schedulingv1alpha2.CloseQueueAction),
+	}
+	vcClient.BusV1alpha1().Commands(cmd.Namespace).Create(cmd)
+
+	newController := func() *Controller {
+		c := NewQueueController(kubeclient.NewSimpleClientset(), vcClient, NewOptions())
+		item, _ := vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+		c.queueInformer.Informer().GetIndexer().Add(item)
+		return c
+	}
+	getState := func() schedulingv1alpha2.QueueState {
+		item, _ := vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+		return item.Spec.State
+	}
+
+	// The command is kept if its action fails.
+	failed := true
+	vcClient.PrependReactor("update", "queues", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if failed {
+			return true, nil, fmt.Errorf("apiserver is unavailable")
+		}
+		return false, nil, nil
+	})
+	c := newController()
+	if err := c.handleCommand(context.TODO(), cmd); err == nil {
+		t.Errorf("expected error when the action of command fails")
+	}
+	if _, err := vcClient.BusV1alpha1().Commands(cmd.Namespace).Get(cmd.Name, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected command kept when its action fails, got %v", err)
+	}
+
+	// The controller crashes between applying the action and deleting the command.
+	failed = false
+	crashed := true
+	vcClient.PrependReactor("delete", "commands", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		if crashed {
+			return true, nil, fmt.Errorf("controller is stopped")
+		}
+		return false, nil, nil
+	})
+	c = newController()
+	if err := c.handleCommand(context.TODO(), cmd); err == nil {
+		t.Errorf("expected error when command is not deleted")
+	}
+	if state := getState(); state != schedulingv1alpha2.QueueStateClosed {
+		t.Errorf("expected queue closed, got %s", state)
+	}
+	if _, err := vcClient.BusV1alpha1().Commands(cmd.Namespace).Get(cmd.Name, metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected command kept for the restarted controller, got %v", err)
+	}
+
+	// The queue is opened meanwhile; the restarted controller finds the command applied already,
+	// deletes it and does not close the queue again.
+	item, _ := vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	item.Spec.State = schedulingv1alpha2.QueueStateOpen
+	vcClient.SchedulingV1alpha2().Queues().Update(item)
+
+	crashed = false
+	c = newController()
+	actions := handledActions(c)
+	if err := c.handleCommand(context.TODO(), cmd); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := vcClient.BusV1alpha1().Commands(cmd.Namespace).Get(cmd.Name, metav1.GetOptions{}); err == nil {
+		t.Errorf("expected command deleted")
+	}
+	if len(*actions) != 0 {
+		t.Errorf("expected applied command not applied again, got %v", *actions)
+	}
+}
+
+func TestHandleCommandBatchStaleCommand(t *testing.T) {
+	now := time.Now()
+	newCommand := func(name string, action schedulingv1alpha2.QueueAction, created time.Time) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+				Kind:       "Queue",
+				Name:       "q1",
+			},
+			Action: string(action),
+		}
+	}
+
+	for _, deleteFails := range []bool{false, true} {
+		c := newFakeController()
+		c.commandBatchSize = 10
+		c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}})
+		actions := handledActions(c)
+
+		failed := deleteFails
+		c.vcClient.(*vcclient.Clientset).PrependReactor("delete", "commands", func(action kubetesting.Action) (bool, runtime.Object, error) {
+			if failed && action.(kubetesting.DeleteAction).GetName() == "open" {
+				return true, nil, fmt.Errorf("apiserver is unavailable")
+			}
+			return false, nil, nil
+		})
+
+		open := newCommand("open", schedulingv1alpha2.OpenQueueAction, now.Add(-time.Second))
+		closed := newCommand("close", schedulingv1alpha2.CloseQueueAction, now)
+		for _, cmd := range []*busv1alpha1.Command{open, closed} {
+			c.cmdInformer.Informer().GetIndexer().Add(cmd)
+			c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Create(cmd)
+		}
+
+		// The open command is coalesced into the batch of the close command, while the cache
+		// still has it and its own entry is left in the workqueue.
+		if err := c.handleCommandBatch(context.TODO(), closed); (err != nil) != deleteFails {
+			t.Errorf("case delete fails %v: unexpected error %v", deleteFails, err)
+		}
+		failed = false
+		if err := c.handleCommandBatch(context.TODO(), open); err != nil {
+			t.Errorf("case delete fails %v: unexpected error %v", deleteFails, err)
+		}
+
+		if len(*actions) != 1 || (*actions)[0] != schedulingv1alpha2.CloseQueueAction {
+			t.Errorf("case delete fails %v: expected only %s applied, got %v", deleteFails,
+				schedulingv1alpha2.CloseQueueAction, *actions)
+		}
+		for _, cmd := range []*busv1alpha1.Command{open, closed} {
+			if _, err := c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Get(cmd.Name, metav1.GetOptions{}); err == nil {
+				t.Errorf("case delete fails %v: expected command %s deleted", deleteFails, cmd.Name)
+			}
+		}
+		if _, batched := c.batchedCommands.get(open); batched {
+			t.Errorf("case delete fails %v: expected command %s not tracked any more", deleteFails, open.Name)
+		}
+	}
+}
+
+func TestHandleCommandWaitForQueue(t *testing.T) {
+	now := time.Now()
+	newCommand := func(created time.Time) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "cmd1",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+				Kind:       "Queue",
+				Name:       "q1",
+			},
+			Action: string(schedulingv1alpha2.CloseQueueAction),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		command       *busv1alpha1.Command
+		queueExists   bool
+		timeout       time.Duration
+		expectWaiting bool
+		expectEvent   bool
+	}{
+		{
+			name:          "queue not created yet",
+			command:       newCommand(now),
+			timeout:       time.Minute,
+			expectWaiting: true,
+		},
+		{
+			name:        "queue created",
+			command:     newCommand(now),
+			queueExists: true,
+			timeout:     time.Minute,
+		},
+		{
+			name:        "queue not created before timeout",
+			command:     newCommand(now.Add(-2 * time.Minute)),
+			timeout:     time.Minute,
+			expectEvent: true,
+		},
+		{
+			name:    "waiting disabled",
+			command: newCommand(now),
+		},
+	}
+
+	for _, testcase := range testCases {
+		c := newFakeController()
+		actions := handledActions(c)
+		c.commandQueueWaitTimeout = testcase.timeout
+		recorder := record.NewFakeRecorder(10)
+		c.recorder = recorder
+		c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Create(testcase.command)
+		if testcase.queueExists {
+			c.queueInformer.Informer().GetIndexer().Add(&schedulingv1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}})
+		}
+
+		err := c.handleCommand(context.TODO(), testcase.command)
+		if _, waiting := err.(*queueNotFoundError); waiting != testcase.expectWaiting {
+			t.Errorf("case %s: expected waiting %v, got error %v", testcase.name, testcase.expectWaiting, err)
+		}
+
+		_, getErr := c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Get(testcase.command.Name, metav1.GetOptions{})
+		if kept := getErr == nil; kept != testcase.expectWaiting {
+			t.Errorf("case %s: expected command kept %v, got %v", testcase.name, testcase.expectWaiting, kept)
+		}
+		if applied := len(*actions) == 1; applied == testcase.expectWaiting {
+			t.Errorf("case %s: expected action applied %v, got %v", testcase.name, !testcase.expectWaiting, applied)
+		}
+
+		var events []string
+		for len(recorder.Events) > 0 {
+			events = append(events, <-recorder.Events)
+		}
+		if recorded := len(events) == 1 && strings.Contains(events[0], "QueueNotFound"); recorded != testcase.expectEvent {
+			t.Errorf("case %s: expected QueueNotFound event %v, got %v", testcase.name, testcase.expectEvent, events)
+		}
+	}
+
+	// The waiting command is retried even if it exceeds commandMaxRetries.
+	c := newFakeController()
+	c.commandMaxRetries = 1
+	route := c.routeCommand(newCommand(now))
+	cmd := newCommand(now)
+	for i := 0; i < 3; i++ {
+		c.handleCommandErr(route, &queueNotFoundError{queue: "q1"}, cmd)
+	}
+	if requeues := route.queue.NumRequeues(cmd); requeues != 3 {
+		t.Errorf("expected waiting command requeued 3 times, got %d", requeues)
+	}
+}
+
+func TestRouteCommand(t *testing.T) {
+	newCommand := func(apiVersion, kind string) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cmd1",
+				Namespace: "default",
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: apiVersion,
+				Kind:       kind,
+				Name:       "target",
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name        string
+		command     *busv1alpha1.Command
+		ExpectRoute string
+	}{
+		{
+			Name:        "queue command",
+			command:     newCommand(schedulingv1alpha2.SchemeGroupVersion.String(), "Queue"),
+			ExpectRoute: commandWorkqueue,
+		},
+		{
+			Name:        "job command",
+			command:     newCommand("batch.volcano.sh/v1alpha1", "Job"),
+			ExpectRoute: jobCommandWorkqueue,
+		},
+		{
+			Name:    "podgroup command is not handled",
+			command: newCommand(schedulingv1alpha2.SchemeGroupVersion.String(), "PodGroup"),
+		},
+		{
+			Name:    "command without target",
+			command: &busv1alpha1.Command{},
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+
+		var name string
+		if route := c.routeCommand(testcase.command); route != nil {
+			name = route.name
+		}
+		if name != testcase.ExpectRoute {
+			t.Errorf("case %d (%s): expected route %q, got %q", i, testcase.Name, testcase.ExpectRoute, name)
+		}
+
+		c.addCommand(testcase.command)
+		if testcase.ExpectRoute == commandWorkqueue && c.commandQueue.Len() != 1 {
+			t.Errorf("case %d (%s): expected command in command queue", i, testcase.Name)
+		}
+		if testcase.ExpectRoute == jobCommandWorkqueue && c.jobCommandQueue.Len() != 1 {
+			t.Errorf("case %d (%s): expected command in job command queue", i, testcase.Name)
+		}
+	}
+}
+
+func TestHandleJobCommand(t *testing.T) {
+	pg := &schedulingv1alpha2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job1",
+			Namespace: "default",
+		},
+		Spec: schedulingv1alpha2.PodGroupSpec{
+			Queue: "q1",
+		},
+	}
+
+	newCommand := func(target, action string) *busv1alpha1.Command {
+		return &busv1alpha1.Command{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cmd1",
+				Namespace: "default",
+			},
+			TargetObject: &metav1.OwnerReference{
+				APIVersion: "batch.volcano.sh/v1alpha1",
+				Kind:       "Job",
+				Name:       target,
+			},
+			Action: action,
+		}
+	}
+
+	testCases := []struct {
+		Name        string
+		command     *busv1alpha1.Command
+		ExpectQueue string
+	}{
+		{
+			Name:        "queue of job is synced",
+			command:     newCommand("job1", "AbortJob"),
+			ExpectQueue: "q1",
+		},
+		{
+			Name:        "queue action of job command is not applied",
+			command:     newCommand("job1", string(schedulingv1alpha2.CloseQueueAction)),
+			ExpectQueue: "q1",
+		},
+		{
+			Name:    "podgroup of job not found",
+			command: newCommand("job2", "AbortJob"),
+		},
+	}
+
+	for i, testcase := range testCases {
+		c := newFakeController()
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+		c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Create(testcase.command)
+
+		if err := c.handleJobCommand(context.TODO(), testcase.command); err != nil {
+			t.Errorf("case %d (%s): unexpected error %v", i, testcase.Name, err)
+		}
+
+		// The command is left to job controller.
+		if _, err := c.vcClient.BusV1alpha1().Commands(testcase.command.Namespace).Get(testcase.command.Name, metav1.GetOptions{}); err != nil {
+			t.Errorf("case %d (%s): expected command kept, got %v", i, testcase.Name, err)
+		}
+
+		var queue string
+		var action schedulingv1alpha2.QueueAction
+		if c.queue.Len() != 0 {
+			item, _ := c.queue.Get()
+			queue = item.(*schedulingv1alpha2.QueueRequest).Name
+			action = item.(*schedulingv1alpha2.QueueRequest).Action
+			c.queue.Done(item)
+		}
+		if queue != testcase.ExpectQueue {
+			t.Errorf("case %d (%s): expected queue %q enqueued, got %q", i, testcase.Name, testcase.ExpectQueue, queue)
+		}
+		if queue != "" && action != schedulingv1alpha2.SyncQueueAction {
+			t.Errorf("case %d (%s): expected action %s, got %s", i, testcase.Name, schedulingv1alpha2.SyncQueueAction, action)
+		}
+	}
+}
+
+func TestHandleCommandRecordsLastCommand(t *testing.T) {
+	queue := &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+		Status: schedulingv1alpha2.QueueStatus{
+			State: schedulingv1alpha2.QueueStateOpen,
+		},
+	}
+	cmd := &busv1alpha1.Command{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cmd1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				busv1alpha1.CommandCreatorAnnotationKey: "admin",
+			},
+		},
+		TargetObject: &metav1.OwnerReference{
+			APIVersion: schedulingv1alpha2.SchemeGroupVersion.String(),
+			Kind:       "Queue",
+			Name:       "q1",
+		},
+		Action: string(schedulingv1alpha2.CloseQueueAction),
+	}
+
+	c := newFakeController()
+	c.vcClient.SchedulingV1alpha2().Queues().Create(queue)
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+	c.vcClient.BusV1alpha1().Commands(cmd.Namespace).Create(cmd)
+
+	if err := c.handleCommand(context.TODO(), cmd); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	item, err := c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get queue: %v", err)
+	}
+	record := item.Status.LastCommand
+	if record == nil {
+		t.Fatalf("expected last command recorded")
+	}
+	if record.Action != schedulingv1alpha2.CloseQueueAction || record.Command != "default/cmd1" ||
+		record.Creator != "admin" || record.Time.IsZero() {
+		t.Errorf("unexpected last command %+v", record)
+	}
+
+	// The record is kept when the status is synced.
+	item.Status.Pending = 1
+	c.queueInformer.Informer().GetIndexer().Update(item)
+	if err := c.syncQueue(context.TODO(), item, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	item, _ = c.vcClient.SchedulingV1alpha2().Queues().Get(queue.Name, metav1.GetOptions{})
+	if item.Status.Pending != 0 {
+		t.Errorf("expected status synced, got %d pending podgroups", item.Status.Pending)
+	}
+	if !reflect.DeepEqual(item.Status.LastCommand, record) {
+		t.Errorf("expected last command %+v kept, got %+v", record, item.Status.LastCommand)
+	}
+}
+
+func TestProcessNextWorkItemTracing(t *testing.T) {
+	var spans []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []map[string]interface{} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		spans = append(spans, request.ResourceSpans[0].ScopeSpans[0].Spans...)
+	}))
+	defer server.Close()
+
+	tracer, err := tracing.NewTracer(server.URL, "vc-controllers")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opt := NewOptions()
+	opt.Tracer = tracer
+	c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+	c.syncHandler = func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
+		return fmt.Errorf("transient error")
+	}
+
+	c.enqueue("q1", schedulingv1alpha2.QueueOutOfSyncEvent, schedulingv1alpha2.SyncQueueAction)
+	c.processNextWorkItem(context.TODO())
+	tracer.Flush()
+
+	if len(spans) != 1 || spans[0]["name"] != "handleQueue" {
+		t.Fatalf("expected span of handleQueue, got %v", spans)
+	}
+	attributes := map[string]string{}
+	for _, attribute := range spans[0]["attributes"].([]interface{}) {
+		kv := attribute.(map[string]interface{})
+		attributes[kv["key"].(string)] = kv["value"].(map[string]interface{})["stringValue"].(string)
+	}
+	expected := map[string]string{
+		"queue":  "q1",
+		"action": string(schedulingv1alpha2.SyncQueueAction),
+		"event":  string(schedulingv1alpha2.QueueOutOfSyncEvent),
+		"result": "error",
+	}
+	if !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("expected span attributes %v, got %v", expected, attributes)
 	}
 }
@@ -17,24 +17,278 @@ limitations under the License.
 package queue
 
 import (
+	"context"
+	"fmt"
+
+	batchv1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	busv1alpha1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 
+	schedulinglister "volcano.sh/volcano/pkg/client/listers/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
-// IsQueueReference return if ownerReference is Queue Kind
-func IsQueueReference(ref *metav1.OwnerReference) bool {
+type queueActionKey struct{}
+
+type queueEventKey struct{}
+
+// indexCommandByQueue indexes the commands targeting a queue by the name of the queue.
+func indexCommandByQueue(obj interface{}) ([]string, error) {
+	cmd, ok := obj.(*busv1alpha1.Command)
+	if !ok || !helpers.IsQueueReference(cmd.TargetObject) {
+		return nil, nil
+	}
+
+	return []string{cmd.TargetObject.Name}, nil
+}
+
+// labeledWithQueue returns whether all the commands are labeled with the queue they target.
+func labeledWithQueue(commands []*busv1alpha1.Command, queue string) bool {
+	for _, cmd := range commands {
+		if cmd.Labels[busv1alpha1.QueueNameLabelKey] != queue {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsJobReference return if ownerReference is Job Kind
+func IsJobReference(ref *metav1.OwnerReference) bool {
 	if ref == nil {
 		return false
 	}
 
-	if ref.APIVersion != schedulingv1alpha2.SchemeGroupVersion.String() {
+	if ref.APIVersion != batchv1alpha1.SchemeGroupVersion.String() {
+		return false
+	}
+
+	if ref.Kind != "Job" {
 		return false
 	}
 
-	if ref.Kind != "Queue" {
+	return true
+}
+
+// hasFinalizer return if queue has the finalizer of queue controller
+func hasFinalizer(queue *schedulingv1alpha2.Queue) bool {
+	for _, f := range queue.Finalizers {
+		if f == queueFinalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callWithContext runs call and returns the error of ctx once ctx is done before call returns.
+// The generated clientset has no context aware methods, so the request is not aborted but left
+// to the timeout of Options.RequestClient, while the caller is not blocked anymore.
+func callWithContext(ctx context.Context, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- call()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// workers returns the number of workers to start, at least one worker is started.
+func workers(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// podGroupFinished returns whether the podgroup will not run any more: it is completed, or its
+// minMember pods have terminated with failed ones and none of its pods is running.
+func podGroupFinished(pg *schedulingv1alpha2.PodGroup) bool {
+	if helpers.IsPodGroupCompleted(pg) {
+		return true
+	}
+
+	return pg.Status.Failed > 0 && pg.Status.Running == 0 && pg.Status.Failed+pg.Status.Succeeded >= pg.Spec.MinMember
+}
+
+// ownPodGroups return if the queue controller owns the podgroups of queue
+func ownPodGroups(queue *schedulingv1alpha2.Queue) bool {
+	return queue.Annotations[schedulingv1alpha2.OwnPodGroupsAnnotationKey] == "true"
+}
+
+// isCordoned return if new jobs are rejected in the queue
+func isCordoned(queue *schedulingv1alpha2.Queue) bool {
+	return queue.Annotations[schedulingv1alpha2.CordonedAnnotationKey] == "true"
+}
+
+// hasOrphanFinalizer return if queue is deleted with the orphan policy
+func hasOrphanFinalizer(queue *schedulingv1alpha2.Queue) bool {
+	for _, f := range queue.Finalizers {
+		if f == metav1.FinalizerOrphanDependents {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withQueueAction returns a copy of ctx which carries the action that triggers the sync of queue.
+func withQueueAction(ctx context.Context, action schedulingv1alpha2.QueueAction) context.Context {
+	return context.WithValue(ctx, queueActionKey{}, action)
+}
+
+// queueActionFrom returns the action carried by ctx, SyncQueue if there is none.
+func queueActionFrom(ctx context.Context) schedulingv1alpha2.QueueAction {
+	if action, ok := ctx.Value(queueActionKey{}).(schedulingv1alpha2.QueueAction); ok && len(action) != 0 {
+		return action
+	}
+
+	return schedulingv1alpha2.SyncQueueAction
+}
+
+// withQueueEvent returns a copy of ctx which carries the event that triggers the sync of queue.
+func withQueueEvent(ctx context.Context, event schedulingv1alpha2.QueueEvent) context.Context {
+	return context.WithValue(ctx, queueEventKey{}, event)
+}
+
+// queueEventFrom returns the event carried by ctx, OutOfSync if there is none.
+func queueEventFrom(ctx context.Context) schedulingv1alpha2.QueueEvent {
+	if event, ok := ctx.Value(queueEventKey{}).(schedulingv1alpha2.QueueEvent); ok && len(event) != 0 {
+		return event
+	}
+
+	return schedulingv1alpha2.QueueOutOfSyncEvent
+}
+
+// appendStateCondition records the transition from previous state to the state of status
+// in its conditions, only the latest maxQueueConditions are kept; it returns whether the
+// state is changed.
+func appendStateCondition(status *schedulingv1alpha2.QueueStatus, previous schedulingv1alpha2.QueueState,
+	action schedulingv1alpha2.QueueAction) bool {
+	if status.State == previous {
 		return false
 	}
 
+	condition := schedulingv1alpha2.QueueCondition{
+		Type:               status.State,
+		Status:             v1.ConditionTrue,
+		PreviousState:      previous,
+		LastTransitionTime: metav1.Now(),
+		Reason:             string(action),
+		Message:            stateTransitionMessage(previous, status.State),
+	}
+
+	// Conditions may be shared with the queue in cache, do not modify it in place.
+	conditions := make([]schedulingv1alpha2.QueueCondition, 0, len(status.Conditions)+1)
+	conditions = append(conditions, status.Conditions...)
+	conditions = append(conditions, condition)
+	if len(conditions) > maxQueueConditions {
+		conditions = conditions[len(conditions)-maxQueueConditions:]
+	}
+	status.Conditions = conditions
+
 	return true
 }
+
+func stateTransitionMessage(previous, current schedulingv1alpha2.QueueState) string {
+	if len(previous) == 0 {
+		return fmt.Sprintf("Queue state is set to %s", current)
+	}
+
+	return fmt.Sprintf("Queue state changed from %s to %s", previous, current)
+}
+
+// newNamespaceSet returns the set of the given namespaces, nil means all namespaces.
+func newNamespaceSet(namespaces []string) map[string]struct{} {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		set[ns] = struct{}{}
+	}
+
+	return set
+}
+
+// isWatchedNamespace returns whether the podgroups in the namespace are handled by the controller.
+func (c *Controller) isWatchedNamespace(namespace string) bool {
+	if c.watchNamespaces == nil {
+		return true
+	}
+
+	_, found := c.watchNamespaces[namespace]
+	return found
+}
+
+// namespacedPodGroupLister lists the podgroups of the watched namespaces from the cluster wide lister.
+type namespacedPodGroupLister struct {
+	schedulinglister.PodGroupLister
+	namespaces map[string]struct{}
+}
+
+// newPodGroupLister returns the lister of the podgroups in the namespaces, nil means all namespaces;
+// the lister of a single namespace lists its podgroups only already.
+func newPodGroupLister(lister schedulinglister.PodGroupLister, namespaces map[string]struct{}) schedulinglister.PodGroupLister {
+	if len(namespaces) <= 1 {
+		return lister
+	}
+
+	return &namespacedPodGroupLister{PodGroupLister: lister, namespaces: namespaces}
+}
+
+// List lists the podgroups of the watched namespaces.
+func (l *namespacedPodGroupLister) List(selector labels.Selector) ([]*schedulingv1alpha2.PodGroup, error) {
+	podGroups, err := l.PodGroupLister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*schedulingv1alpha2.PodGroup
+	for _, pg := range podGroups {
+		if _, found := l.namespaces[pg.Namespace]; found {
+			result = append(result, pg)
+		}
+	}
+
+	return result, nil
+}
+
+// PodGroups returns the lister of the podgroups in the namespace, which is empty if the namespace
+// is not watched.
+func (l *namespacedPodGroupLister) PodGroups(namespace string) schedulinglister.PodGroupNamespaceLister {
+	if _, found := l.namespaces[namespace]; !found {
+		return unwatchedPodGroupLister{}
+	}
+
+	return l.PodGroupLister.PodGroups(namespace)
+}
+
+// unwatchedPodGroupLister is the lister of the podgroups in a namespace which is not watched.
+type unwatchedPodGroupLister struct{}
+
+// List lists no podgroup.
+func (unwatchedPodGroupLister) List(selector labels.Selector) ([]*schedulingv1alpha2.PodGroup, error) {
+	return nil, nil
+}
+
+// Get returns not found error.
+func (unwatchedPodGroupLister) Get(name string) (*schedulingv1alpha2.PodGroup, error) {
+	return nil, apierrors.NewNotFound(schedulingv1alpha2.Resource("podgroup"), name)
+}
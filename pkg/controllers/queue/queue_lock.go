@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+)
+
+// queueLocks serializes the handling of each queue. The workqueue only merges identical items,
+// and every request of a queue is a distinct item, so without the lock several workers could
+// handle the requests of the same queue at once.
+type queueLocks struct {
+	mutex sync.Mutex
+	// queue name -> the lock of the queue, removed once no worker holds or waits for it
+	locks map[string]*queueLock
+}
+
+type queueLock struct {
+	sync.Mutex
+	// refs is the number of the workers holding or waiting for the lock
+	refs int
+}
+
+func newQueueLocks() *queueLocks {
+	return &queueLocks{locks: map[string]*queueLock{}}
+}
+
+// lock locks the queue and returns the function unlocking it.
+func (l *queueLocks) lock(name string) func() {
+	l.mutex.Lock()
+	ql, found := l.locks[name]
+	if !found {
+		ql = &queueLock{}
+		l.locks[name] = ql
+	}
+	ql.refs++
+	l.mutex.Unlock()
+
+	ql.Lock()
+
+	return func() {
+		ql.Unlock()
+
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if ql.refs--; ql.refs == 0 {
+			delete(l.locks, name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestConcurrentQueueWorkers(t *testing.T) {
+	const workers, queues, requests = 4, 3, 5
+
+	c := newFakeController()
+
+	var mutex sync.Mutex
+	active := map[string]int{}
+	handled := map[string]int{}
+	overlapped := map[string]bool{}
+	c.syncHandler = func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
+		mutex.Lock()
+		active[req.Name]++
+		if active[req.Name] > 1 {
+			overlapped[req.Name] = true
+		}
+		mutex.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		active[req.Name]--
+		handled[req.Name]++
+		mutex.Unlock()
+		return nil
+	}
+
+	// Every request is a distinct item of the workqueue, even the identical ones.
+	for i := 0; i < requests; i++ {
+		for q := 0; q < queues; q++ {
+			c.queue.Add(&schedulingv1alpha2.QueueRequest{
+				Name:   fmt.Sprintf("q%d", q),
+				Event:  schedulingv1alpha2.QueueOutOfSyncEvent,
+				Action: schedulingv1alpha2.SyncQueueAction,
+			})
+		}
+	}
+	c.queue.ShutDown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.worker(context.TODO())
+		}()
+	}
+	wg.Wait()
+
+	for q := 0; q < queues; q++ {
+		name := fmt.Sprintf("q%d", q)
+		if overlapped[name] {
+			t.Errorf("expected queue %s handled by one worker at a time", name)
+		}
+		if handled[name] != requests {
+			t.Errorf("expected %d requests of queue %s handled, got %d", requests, name, handled[name])
+		}
+	}
+	if len(c.queueLocks.locks) != 0 {
+		t.Errorf("expected the locks of queues released, got %d", len(c.queueLocks.locks))
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// DefaultRetryMaxDelay is the default upper limit of the delay before a failed queue request
+	// or command is retried.
+	DefaultRetryMaxDelay = 30 * time.Second
+
+	// retryBaseDelay is the delay before the first retry, it is doubled on every failure.
+	retryBaseDelay = 5 * time.Millisecond
+	// retryJitter is the max fraction by which the delay is randomly shortened.
+	retryJitter = 0.5
+)
+
+// newRateLimiter returns the rate limiter of the work queues: the delay of an item grows
+// exponentially with its failures up to maxDelay and is randomly shortened, so the retries
+// of items which failed together are spread out; all items are also limited to 10 qps with
+// a burst of 100 as the default controller rate limiter.
+func newRateLimiter(maxDelay time.Duration) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		newJitteredExponentialRateLimiter(retryBaseDelay, maxDelay, retryJitter),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// jitteredExponentialRateLimiter delays an item baseDelay*2^<num-failures>, capped by maxDelay,
+// and then shortened by a random fraction up to jitter.
+type jitteredExponentialRateLimiter struct {
+	failuresLock sync.Mutex
+	failures     map[interface{}]int
+
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	jitter    float64
+	// random returns a number in [0.0, 1.0).
+	random func() float64
+}
+
+func newJitteredExponentialRateLimiter(baseDelay, maxDelay time.Duration, jitter float64) *jitteredExponentialRateLimiter {
+	return &jitteredExponentialRateLimiter{
+		failures:  map[interface{}]int{},
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+		jitter:    jitter,
+		random:    rand.Float64,
+	}
+}
+
+// When returns the delay before the item is retried.
+func (r *jitteredExponentialRateLimiter) When(item interface{}) time.Duration {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+
+	// The backoff is capped, so it does not overflow.
+	backoff := float64(r.baseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	if backoff > float64(r.maxDelay.Nanoseconds()) {
+		backoff = float64(r.maxDelay.Nanoseconds())
+	}
+
+	return time.Duration(backoff * (1 - r.jitter*r.random()))
+}
+
+// NumRequeues returns the number of failures of the item.
+func (r *jitteredExponentialRateLimiter) NumRequeues(item interface{}) int {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+
+	return r.failures[item]
+}
+
+// Forget stops tracking the failures of the item.
+func (r *jitteredExponentialRateLimiter) Forget(item interface{}) {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+
+	delete(r.failures, item)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitteredExponentialRateLimiter(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		random float64
+		// ExpectDelays are the delays of the consecutive failures of an item.
+		ExpectDelays []time.Duration
+	}{
+		{
+			Name:   "no jitter",
+			random: 0,
+			ExpectDelays: []time.Duration{
+				5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond,
+				80 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond,
+			},
+		},
+		{
+			Name:   "max jitter shortens delay by half",
+			random: 1,
+			ExpectDelays: []time.Duration{
+				2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond,
+				40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond,
+			},
+		},
+	}
+
+	for i, testcase := range testCases {
+		limiter := newJitteredExponentialRateLimiter(5*time.Millisecond, 100*time.Millisecond, 0.5)
+		limiter.random = func() float64 { return testcase.random }
+
+		for j, expected := range testcase.ExpectDelays {
+			if delay := limiter.When("item"); delay != expected {
+				t.Errorf("case %d (%s): expected delay %v of failure %d, got %v", i, testcase.Name, expected, j, delay)
+			}
+		}
+		if n := limiter.NumRequeues("item"); n != len(testcase.ExpectDelays) {
+			t.Errorf("case %d (%s): expected %d requeues, got %d", i, testcase.Name, len(testcase.ExpectDelays), n)
+		}
+
+		limiter.Forget("item")
+		if n := limiter.NumRequeues("item"); n != 0 {
+			t.Errorf("case %d (%s): expected no requeues after forget, got %d", i, testcase.Name, n)
+		}
+		if delay := limiter.When("item"); delay != testcase.ExpectDelays[0] {
+			t.Errorf("case %d (%s): expected delay %v after forget, got %v", i, testcase.Name, testcase.ExpectDelays[0], delay)
+		}
+	}
+}
+
+func TestJitteredExponentialRateLimiterLargeFailures(t *testing.T) {
+	limiter := newJitteredExponentialRateLimiter(5*time.Millisecond, DefaultRetryMaxDelay, retryJitter)
+	for i := 0; i < 1000; i++ {
+		delay := limiter.When("item")
+		if delay < 0 || delay > DefaultRetryMaxDelay {
+			t.Fatalf("delay %v of failure %d is out of range", delay, i)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// drain stops accepting new items and lets the workers handle the items already queued,
+// until the workqueues are empty or shutdownDrainTimeout expires. The commands are drained
+// first, as the requests they enqueue are dropped once the workqueue of queues is shut down.
+func (c *Controller) drain(commandWorkers, queueWorkers *sync.WaitGroup) {
+	if c.shutdownDrainTimeout <= 0 {
+		return
+	}
+
+	deadline := time.NewTimer(c.shutdownDrainTimeout)
+	defer deadline.Stop()
+
+	klog.Infof("Draining workqueues of queue controller in %v.", c.shutdownDrainTimeout)
+
+	for _, route := range c.commandRoutes {
+		shutDown(route.queue)
+	}
+	if !waitOrTimeout(commandWorkers, deadline.C) {
+		klog.Warningf("Timed out draining commands, %d commands are dropped.", c.commandBacklog())
+		return
+	}
+
+	shutDown(c.queue)
+	if !waitOrTimeout(queueWorkers, deadline.C) {
+		klog.Warningf("Timed out draining queue requests, %d requests are dropped.", c.queue.Len())
+		return
+	}
+
+	klog.Infof("Drained workqueues of queue controller.")
+}
+
+// commandBacklog returns the number of commands in the workqueues.
+func (c *Controller) commandBacklog() int {
+	backlog := 0
+	for _, route := range c.commandRoutes {
+		backlog += route.queue.Len()
+	}
+	return backlog
+}
+
+// waitOrTimeout waits for the workers to exit, and returns false if timeout fires first.
+func waitOrTimeout(workers *sync.WaitGroup, timeout <-chan time.Time) bool {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-timeout:
+		return false
+	}
+}
+
+// shutDown shuts down the workqueue unless it is shut down by drain, which would panic.
+func shutDown(queue workqueue.RateLimitingInterface) {
+	if !queue.ShuttingDown() {
+		queue.ShutDown()
+	}
+}
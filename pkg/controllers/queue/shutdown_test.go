@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func TestRunDrainsWorkqueue(t *testing.T) {
+	testCases := []struct {
+		name            string
+		drainTimeout    time.Duration
+		blockForever    bool
+		expectedHandled int32
+	}{
+		{
+			name:            "queued requests are handled before stop",
+			drainTimeout:    5 * time.Second,
+			expectedHandled: 3,
+		},
+		{
+			name:            "queued requests are dropped without drain",
+			expectedHandled: 0,
+		},
+		{
+			name:            "drain stops at timeout",
+			drainTimeout:    100 * time.Millisecond,
+			blockForever:    true,
+			expectedHandled: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		opt := NewOptions()
+		opt.ShutdownDrainTimeout = testCase.drainTimeout
+		c := NewQueueController(kubeclient.NewSimpleClientset(), vcclient.NewSimpleClientset(), opt)
+		synced := func() bool { return true }
+		c.queueSynced, c.pgSynced, c.cmdSynced, c.pcSynced = synced, synced, synced, synced
+
+		release := make(chan struct{})
+		forever := make(chan struct{})
+		var handled int32
+		c.syncHandler = func(ctx context.Context, req *schedulingv1alpha2.QueueRequest) error {
+			<-release
+			if testCase.blockForever {
+				<-forever
+			}
+			if ctx.Err() == nil {
+				atomic.AddInt32(&handled, 1)
+			}
+			return nil
+		}
+		for i := 0; i < 3; i++ {
+			c.queue.Add(&schedulingv1alpha2.QueueRequest{
+				Name:   fmt.Sprintf("q%d", i),
+				Action: schedulingv1alpha2.SyncQueueAction,
+			})
+		}
+
+		stopCh := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			c.Run(stopCh)
+		}()
+		if err := wait.Poll(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+			return c.Ready(), nil
+		}); err != nil {
+			t.Fatalf("case %s: expected controller ready, got %v", testCase.name, err)
+		}
+
+		close(stopCh)
+		if testCase.drainTimeout == 0 {
+			// Run returns at once and cancels the in-flight request.
+			<-stopped
+		}
+		close(release)
+
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("case %s: expected Run to return", testCase.name)
+		}
+		close(forever)
+
+		if got := atomic.LoadInt32(&handled); got != testCase.expectedHandled {
+			t.Errorf("case %s: expected %d requests handled, got %d", testCase.name, testCase.expectedHandled, got)
+		}
+	}
+}
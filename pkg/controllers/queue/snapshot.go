@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+// UsageSnapshotKey is the key of the snapshot in the data of the ConfigMap.
+const UsageSnapshotKey = "snapshot.json"
+
+// usageSnapshot is the usage of all queues at a time, exported for capacity planning.
+type usageSnapshot struct {
+	Time metav1.Time `json:"time"`
+	// Controller is the identity of the controller which took the snapshot.
+	Controller string          `json:"controller"`
+	Queues     []queueSnapshot `json:"queues"`
+}
+
+type queueSnapshot struct {
+	Name       string          `json:"name"`
+	Parent     string          `json:"parent,omitempty"`
+	Capability v1.ResourceList `json:"capability,omitempty"`
+	Guaranteed v1.ResourceList `json:"guaranteed,omitempty"`
+	Used       v1.ResourceList `json:"used,omitempty"`
+	Pending    int32           `json:"pending"`
+	Running    int32           `json:"running"`
+	Unknown    int32           `json:"unknown"`
+	Inqueue    int32           `json:"inqueue"`
+	Completed  int32           `json:"completed"`
+}
+
+// takeUsageSnapshot aggregates the usage of all queues from the podgroups in the cache.
+func (c *Controller) takeUsageSnapshot(now time.Time) (*usageSnapshot, error) {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+
+	snapshot := &usageSnapshot{
+		Time:       metav1.NewTime(now),
+		Controller: c.identity,
+		Queues:     make([]queueSnapshot, 0, len(queues)),
+	}
+	for _, queue := range queues {
+		podGroups, err := c.listPodGroups(queue.Name)
+		if err != nil {
+			return nil, err
+		}
+		usage := helpers.AggregateQueueUsage(queue, podGroups)
+		snapshot.Queues = append(snapshot.Queues, queueSnapshot{
+			Name:       queue.Name,
+			Parent:     queue.Spec.Parent,
+			Capability: helpers.EffectiveCapability(queue),
+			Guaranteed: queue.Spec.Guaranteed,
+			Used:       usage.Used,
+			Pending:    usage.Pending,
+			Running:    usage.Running,
+			Unknown:    usage.Unknown,
+			Inqueue:    usage.Inqueue,
+			Completed:  usage.Completed,
+		})
+	}
+
+	return snapshot, nil
+}
+
+// exportUsageSnapshot writes the usage snapshot of all queues to the ConfigMap, which is
+// created if it does not exist.
+func (c *Controller) exportUsageSnapshot(ctx context.Context) {
+	snapshot, err := c.takeUsageSnapshot(time.Now())
+	if err != nil {
+		klog.Errorf("Failed to take usage snapshot of queues: %v", err)
+		return
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		klog.Errorf("Failed to marshal usage snapshot of queues: %v", err)
+		return
+	}
+
+	// The key is validated by the options, the error can not occur.
+	namespace, name, _ := cache.SplitMetaNamespaceKey(c.usageSnapshotConfigMap)
+	configMaps := c.kubeClient.CoreV1().ConfigMaps(namespace)
+	err = callWithContext(ctx, func() error {
+		cm, err := configMaps.Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = configMaps.Create(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Data:       map[string]string{UsageSnapshotKey: string(data)},
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[UsageSnapshotKey] = string(data)
+		_, err = configMaps.Update(cm)
+		return err
+	})
+	if err != nil {
+		klog.Errorf("Failed to export usage snapshot of queues to ConfigMap %s: %v", c.usageSnapshotConfigMap, err)
+		return
+	}
+
+	klog.V(4).Infof("Exported usage snapshot of %d queues to ConfigMap %s.", len(snapshot.Queues), c.usageSnapshotConfigMap)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func TestExportUsageSnapshot(t *testing.T) {
+	kubeClient := kubeclient.NewSimpleClientset()
+	opt := NewOptions()
+	opt.UsageSnapshotConfigMap = "volcano-system/queue-usage"
+	opt.Identity = "controller-0"
+	c := NewQueueController(kubeClient, vcclient.NewSimpleClientset(), opt)
+
+	for _, queue := range []*schedulingv1alpha2.Queue{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "root"},
+			Spec: schedulingv1alpha2.QueueSpec{
+				Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")},
+				Guaranteed: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "child"},
+			Spec:       schedulingv1alpha2.QueueSpec{Parent: "root"},
+		},
+	} {
+		c.queueInformer.Informer().GetIndexer().Add(queue)
+		c.addChildQueue(queue)
+	}
+	for _, pg := range []*schedulingv1alpha2.PodGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg1"},
+			Spec: schedulingv1alpha2.PodGroupSpec{
+				Queue:        "child",
+				MinResources: &v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")},
+			},
+			Status: schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupRunning},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg2"},
+			Spec:       schedulingv1alpha2.PodGroupSpec{Queue: "root"},
+			Status:     schedulingv1alpha2.PodGroupStatus{Phase: schedulingv1alpha2.PodGroupPending},
+		},
+	} {
+		c.pgInformer.Informer().GetIndexer().Add(pg)
+		c.addPodGroup(pg)
+	}
+
+	// The ConfigMap is created by the first export and updated by the following ones.
+	for i := 0; i < 2; i++ {
+		c.exportUsageSnapshot(context.Background())
+	}
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("volcano-system").Get("queue-usage", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+	snapshot := &usageSnapshot{}
+	if err := json.Unmarshal([]byte(cm.Data[UsageSnapshotKey]), snapshot); err != nil {
+		t.Fatalf("failed to unmarshal snapshot: %v", err)
+	}
+
+	if snapshot.Controller != "controller-0" || snapshot.Time.IsZero() {
+		t.Errorf("expected provenance of controller-0 with time, got %q at %v", snapshot.Controller, snapshot.Time)
+	}
+	if len(snapshot.Queues) != 2 {
+		t.Fatalf("expected 2 queues, got %v", snapshot.Queues)
+	}
+
+	child, root := snapshot.Queues[0], snapshot.Queues[1]
+	if child.Name != "child" || child.Parent != "root" || child.Running != 1 {
+		t.Errorf("unexpected snapshot of queue child: %+v", child)
+	}
+	if used := child.Used[v1.ResourceCPU]; used.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("expected used cpu 3 of queue child, got %s", used.String())
+	}
+	// The parent queue counts the podgroups of its descendants as well.
+	if root.Name != "root" || root.Running != 1 || root.Pending != 1 {
+		t.Errorf("unexpected snapshot of queue root: %+v", root)
+	}
+	if capability := root.Capability[v1.ResourceCPU]; capability.Cmp(resource.MustParse("10")) != 0 {
+		t.Errorf("expected capability cpu 10 of queue root, got %s", capability.String())
+	}
+	if used := root.Used[v1.ResourceCPU]; used.Cmp(resource.MustParse("3")) != 0 {
+		t.Errorf("expected used cpu 3 of queue root, got %s", used.String())
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package state
 
 import (
+	"context"
+
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 )
 
@@ -24,35 +26,6 @@ type closedState struct {
 	queue *v1alpha2.Queue
 }
 
-func (cs *closedState) Execute(action v1alpha2.QueueAction) error {
-	switch action {
-	case v1alpha2.OpenQueueAction:
-		return OpenQueue(cs.queue, func(status *v1alpha2.QueueStatus, podGroupList []string) {
-			status.State = v1alpha2.QueueStateOpen
-			return
-		})
-	case v1alpha2.CloseQueueAction:
-		return SyncQueue(cs.queue, func(status *v1alpha2.QueueStatus, podGroupList []string) {
-			status.State = v1alpha2.QueueStateClosed
-			return
-		})
-	default:
-		return SyncQueue(cs.queue, func(status *v1alpha2.QueueStatus, podGroupList []string) {
-			specState := cs.queue.Spec.State
-			if specState == v1alpha2.QueueStateOpen {
-				status.State = v1alpha2.QueueStateOpen
-				return
-			}
-
-			if specState == v1alpha2.QueueStateClosed {
-				status.State = v1alpha2.QueueStateClosed
-				return
-			}
-
-			status.State = v1alpha2.QueueStateUnknown
-			return
-		})
-	}
-
-	return nil
+func (cs *closedState) Execute(ctx context.Context, action v1alpha2.QueueAction) error {
+	return execute(ctx, v1alpha2.QueueStateClosed, cs.queue, action)
 }
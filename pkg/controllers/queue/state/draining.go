@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+type drainingState struct {
+	queue *v1alpha2.Queue
+}
+
+func (ds *drainingState) Execute(ctx context.Context, action v1alpha2.QueueAction) error {
+	return execute(ctx, v1alpha2.QueueStateDraining, ds.queue, action)
+}
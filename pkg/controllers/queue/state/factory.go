@@ -17,20 +17,22 @@ limitations under the License.
 package state
 
 import (
+	"context"
+
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 )
 
 // State interface
 type State interface {
 	// Execute executes the actions based on current state.
-	Execute(action v1alpha2.QueueAction) error
+	Execute(ctx context.Context, action v1alpha2.QueueAction) error
 }
 
 // UpdateQueueStatusFn updates the queue status
 type UpdateQueueStatusFn func(status *v1alpha2.QueueStatus, podGroupList []string)
 
-// QueueActionFn will open, close or sync queue.
-type QueueActionFn func(queue *v1alpha2.Queue, fn UpdateQueueStatusFn) error
+// QueueActionFn will open, close, sync, drain or cordon queue.
+type QueueActionFn func(ctx context.Context, queue *v1alpha2.Queue, fn UpdateQueueStatusFn) error
 
 var (
 	// SyncQueue will sync queue status.
@@ -39,10 +41,21 @@ var (
 	OpenQueue QueueActionFn
 	// CloseQueue will set state of queue to close
 	CloseQueue QueueActionFn
+	// DrainQueue will drain the queue before it is deleted
+	DrainQueue QueueActionFn
+	// CordonQueue will cordon the queue without changing its state
+	CordonQueue QueueActionFn
+	// UncordonQueue will uncordon the queue without changing its state
+	UncordonQueue QueueActionFn
 )
 
 // NewState gets the state from queue status
 func NewState(queue *v1alpha2.Queue) State {
+	// A deleted queue is drained whatever its current state is.
+	if queue.DeletionTimestamp != nil {
+		return &drainingState{queue: queue}
+	}
+
 	switch queue.Status.State {
 	case "", v1alpha2.QueueStateOpen:
 		return &openState{queue: queue}
@@ -52,6 +65,8 @@ func NewState(queue *v1alpha2.Queue) State {
 		return &closingState{queue: queue}
 	case v1alpha2.QueueStateUnknown:
 		return &unknownState{queue: queue}
+	case v1alpha2.QueueStateDraining:
+		return &drainingState{queue: queue}
 	}
 
 	return nil
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+// Transition is a transition of queue state by an action.
+type Transition struct {
+	// Action is the action of the transition, SyncQueue is taken on every resync of the queue.
+	Action v1alpha2.QueueAction
+	// States are the states which the queue may transition to; which one depends on whether
+	// the queue has podgroups and on its spec.state.
+	States []v1alpha2.QueueState
+}
+
+// transition is an entry of the transition table, which is how the states execute the actions.
+type transition struct {
+	Transition
+	// fn returns the function taking the action, it is set by the queue controller.
+	fn func() QueueActionFn
+	// next returns the state of the queue after the action.
+	next func(queue *v1alpha2.Queue, podGroupList []string) v1alpha2.QueueState
+}
+
+func syncQueue() QueueActionFn     { return SyncQueue }
+func openQueue() QueueActionFn     { return OpenQueue }
+func closeQueue() QueueActionFn    { return CloseQueue }
+func drainQueue() QueueActionFn    { return DrainQueue }
+func cordonQueue() QueueActionFn   { return CordonQueue }
+func uncordonQueue() QueueActionFn { return UncordonQueue }
+
+// to returns the next function which always moves the queue to the state.
+func to(state v1alpha2.QueueState) func(*v1alpha2.Queue, []string) v1alpha2.QueueState {
+	return func(*v1alpha2.Queue, []string) v1alpha2.QueueState {
+		return state
+	}
+}
+
+// closeQueueState closes the queue, which is closing until its podgroups are gone.
+func closeQueueState(_ *v1alpha2.Queue, podGroupList []string) v1alpha2.QueueState {
+	if len(podGroupList) == 0 {
+		return v1alpha2.QueueStateClosed
+	}
+	return v1alpha2.QueueStateClosing
+}
+
+// specState returns the next function of SyncQueue, which moves the queue to its spec.state; closed is
+// the state of a closed spec.state and an empty spec.state is open only if openByDefault is set.
+func specState(openByDefault bool, closed func(*v1alpha2.Queue, []string) v1alpha2.QueueState) func(*v1alpha2.Queue, []string) v1alpha2.QueueState {
+	return func(queue *v1alpha2.Queue, podGroupList []string) v1alpha2.QueueState {
+		switch queue.Spec.State {
+		case v1alpha2.QueueStateOpen:
+			return v1alpha2.QueueStateOpen
+		case v1alpha2.QueueStateClosed:
+			return closed(queue, podGroupList)
+		case "":
+			if openByDefault {
+				return v1alpha2.QueueStateOpen
+			}
+		}
+		return v1alpha2.QueueStateUnknown
+	}
+}
+
+// states returns the states of a transition.
+func states(states ...v1alpha2.QueueState) []v1alpha2.QueueState {
+	return states
+}
+
+// cordonTransitions returns the cordon and uncordon transitions of the state, cordoning does not change
+// the state of queue.
+func cordonTransitions(state v1alpha2.QueueState) []transition {
+	return []transition{
+		{Transition{v1alpha2.CordonQueueAction, states(state)}, cordonQueue, to(state)},
+		{Transition{v1alpha2.UncordonQueueAction, states(state)}, uncordonQueue, to(state)},
+	}
+}
+
+// transitions is the transition table of the states; a state executes an action by its entry in the
+// table, and by its SyncQueue entry if the action is not in the table.
+var transitions = map[v1alpha2.QueueState][]transition{
+	v1alpha2.QueueStateOpen: append([]transition{
+		{Transition{v1alpha2.OpenQueueAction, states(v1alpha2.QueueStateOpen)},
+			syncQueue, to(v1alpha2.QueueStateOpen)},
+		{Transition{v1alpha2.CloseQueueAction, states(v1alpha2.QueueStateClosing, v1alpha2.QueueStateClosed)},
+			closeQueue, closeQueueState},
+		{Transition{v1alpha2.SyncQueueAction, states(v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosing,
+			v1alpha2.QueueStateClosed, v1alpha2.QueueStateUnknown)},
+			syncQueue, specState(true, closeQueueState)},
+	}, cordonTransitions(v1alpha2.QueueStateOpen)...),
+	v1alpha2.QueueStateClosed: append([]transition{
+		{Transition{v1alpha2.OpenQueueAction, states(v1alpha2.QueueStateOpen)},
+			openQueue, to(v1alpha2.QueueStateOpen)},
+		{Transition{v1alpha2.CloseQueueAction, states(v1alpha2.QueueStateClosed)},
+			syncQueue, to(v1alpha2.QueueStateClosed)},
+		{Transition{v1alpha2.SyncQueueAction, states(v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosed,
+			v1alpha2.QueueStateUnknown)},
+			syncQueue, specState(false, to(v1alpha2.QueueStateClosed))},
+	}, cordonTransitions(v1alpha2.QueueStateClosed)...),
+	v1alpha2.QueueStateClosing: append([]transition{
+		{Transition{v1alpha2.OpenQueueAction, states(v1alpha2.QueueStateOpen)},
+			openQueue, to(v1alpha2.QueueStateOpen)},
+		{Transition{v1alpha2.CloseQueueAction, states(v1alpha2.QueueStateClosing, v1alpha2.QueueStateClosed)},
+			syncQueue, closeQueueState},
+		{Transition{v1alpha2.SyncQueueAction, states(v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosing,
+			v1alpha2.QueueStateClosed, v1alpha2.QueueStateUnknown)},
+			syncQueue, specState(false, closeQueueState)},
+	}, cordonTransitions(v1alpha2.QueueStateClosing)...),
+	v1alpha2.QueueStateUnknown: append([]transition{
+		{Transition{v1alpha2.OpenQueueAction, states(v1alpha2.QueueStateOpen)},
+			openQueue, to(v1alpha2.QueueStateOpen)},
+		{Transition{v1alpha2.CloseQueueAction, states(v1alpha2.QueueStateClosing, v1alpha2.QueueStateClosed)},
+			closeQueue, closeQueueState},
+		{Transition{v1alpha2.SyncQueueAction, states(v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosing,
+			v1alpha2.QueueStateClosed, v1alpha2.QueueStateUnknown)},
+			syncQueue, specState(false, closeQueueState)},
+	}, cordonTransitions(v1alpha2.QueueStateUnknown)...),
+	// A deleted queue is drained whatever the action is.
+	v1alpha2.QueueStateDraining: {
+		{Transition{v1alpha2.OpenQueueAction, states(v1alpha2.QueueStateDraining)},
+			drainQueue, to(v1alpha2.QueueStateDraining)},
+		{Transition{v1alpha2.CloseQueueAction, states(v1alpha2.QueueStateDraining)},
+			drainQueue, to(v1alpha2.QueueStateDraining)},
+		{Transition{v1alpha2.SyncQueueAction, states(v1alpha2.QueueStateDraining)},
+			drainQueue, to(v1alpha2.QueueStateDraining)},
+		{Transition{v1alpha2.CordonQueueAction, states(v1alpha2.QueueStateDraining)},
+			drainQueue, to(v1alpha2.QueueStateDraining)},
+		{Transition{v1alpha2.UncordonQueueAction, states(v1alpha2.QueueStateDraining)},
+			drainQueue, to(v1alpha2.QueueStateDraining)},
+	},
+}
+
+// execute takes the action on the queue in the state by the transition table.
+func execute(ctx context.Context, state v1alpha2.QueueState, queue *v1alpha2.Queue, action v1alpha2.QueueAction) error {
+	var found *transition
+	for i, t := range transitions[state] {
+		if t.Action == action {
+			found = &transitions[state][i]
+			break
+		}
+		if t.Action == v1alpha2.SyncQueueAction {
+			found = &transitions[state][i]
+		}
+	}
+
+	return found.fn()(ctx, queue, func(status *v1alpha2.QueueStatus, podGroupList []string) {
+		status.State = found.next(queue, podGroupList)
+	})
+}
+
+// Transitions returns the transitions of the queue state, an empty state is open as in
+// NewState; nil is returned if the state is not known.
+func Transitions(state v1alpha2.QueueState) []Transition {
+	if state == "" {
+		state = v1alpha2.QueueStateOpen
+	}
+
+	var result []Transition
+	for _, transition := range transitions[state] {
+		result = append(result, Transition{
+			Action: transition.Action,
+			States: append([]v1alpha2.QueueState(nil), transition.States...),
+		})
+	}
+
+	return result
+}
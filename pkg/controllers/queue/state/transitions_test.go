@@ -0,0 +1,186 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+)
+
+func TestTransitionsMatchStates(t *testing.T) {
+	var reached map[v1alpha2.QueueState]bool
+	var podGroups []string
+	record := func(ctx context.Context, queue *v1alpha2.Queue, fn UpdateQueueStatusFn) error {
+		status := queue.Status.DeepCopy()
+		fn(status, podGroups)
+		reached[status.State] = true
+		return nil
+	}
+	SyncQueue, OpenQueue, CloseQueue, DrainQueue, CordonQueue, UncordonQueue = record, record, record, record, record, record
+	defer func() {
+		SyncQueue, OpenQueue, CloseQueue, DrainQueue, CordonQueue, UncordonQueue = nil, nil, nil, nil, nil, nil
+	}()
+
+	for state := range transitions {
+		for _, transition := range Transitions(state) {
+			reached = map[v1alpha2.QueueState]bool{}
+			for _, specState := range []v1alpha2.QueueState{"", v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosed, "Invalid"} {
+				for _, podGroups = range [][]string{nil, {"pg1"}} {
+					queue := &v1alpha2.Queue{
+						ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+						Spec:       v1alpha2.QueueSpec{State: specState},
+						Status:     v1alpha2.QueueStatus{State: state},
+					}
+					if state == v1alpha2.QueueStateDraining {
+						queue.DeletionTimestamp = &metav1.Time{}
+					}
+					if err := NewState(queue).Execute(context.TODO(), transition.Action); err != nil {
+						t.Fatalf("state %s: failed to execute %s: %v", state, transition.Action, err)
+					}
+				}
+			}
+
+			expected := map[v1alpha2.QueueState]bool{}
+			for _, s := range transition.States {
+				expected[s] = true
+			}
+			if !reflect.DeepEqual(expected, reached) {
+				t.Errorf("state %s: expected %s to reach %v, got %v", state, transition.Action, expected, reached)
+			}
+		}
+	}
+}
+
+func TestExecuteActionFn(t *testing.T) {
+	var called string
+	fn := func(name string) QueueActionFn {
+		return func(ctx context.Context, queue *v1alpha2.Queue, fn UpdateQueueStatusFn) error {
+			called = name
+			return nil
+		}
+	}
+	SyncQueue, OpenQueue, CloseQueue, DrainQueue = fn("sync"), fn("open"), fn("close"), fn("drain")
+	CordonQueue, UncordonQueue = fn("cordon"), fn("uncordon")
+	defer func() {
+		SyncQueue, OpenQueue, CloseQueue, DrainQueue, CordonQueue, UncordonQueue = nil, nil, nil, nil, nil, nil
+	}()
+
+	testCases := []struct {
+		name     string
+		deleted  bool
+		action   v1alpha2.QueueAction
+		expected string
+	}{
+		{
+			name:     "cordon open queue",
+			action:   v1alpha2.CordonQueueAction,
+			expected: "cordon",
+		},
+		{
+			name:     "uncordon open queue",
+			action:   v1alpha2.UncordonQueueAction,
+			expected: "uncordon",
+		},
+		{
+			name:     "close open queue",
+			action:   v1alpha2.CloseQueueAction,
+			expected: "close",
+		},
+		{
+			name:     "action not in the table is synced",
+			action:   "Invalid",
+			expected: "sync",
+		},
+		{
+			name:     "cordon deleted queue",
+			deleted:  true,
+			action:   v1alpha2.CordonQueueAction,
+			expected: "drain",
+		},
+	}
+
+	for _, testCase := range testCases {
+		called = ""
+		queue := &v1alpha2.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}}
+		if testCase.deleted {
+			queue.DeletionTimestamp = &metav1.Time{}
+		}
+		if err := NewState(queue).Execute(context.TODO(), testCase.action); err != nil {
+			t.Fatalf("case %s: failed to execute: %v", testCase.name, err)
+		}
+		if called != testCase.expected {
+			t.Errorf("case %s: expected %s, got %s", testCase.name, testCase.expected, called)
+		}
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    v1alpha2.QueueState
+		expected []Transition
+	}{
+		{
+			name:  "empty state is open",
+			state: "",
+			expected: []Transition{
+				{Action: v1alpha2.OpenQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateOpen}},
+				{Action: v1alpha2.CloseQueueAction, States: []v1alpha2.QueueState{
+					v1alpha2.QueueStateClosing, v1alpha2.QueueStateClosed}},
+				{Action: v1alpha2.SyncQueueAction, States: []v1alpha2.QueueState{
+					v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosing, v1alpha2.QueueStateClosed, v1alpha2.QueueStateUnknown}},
+				{Action: v1alpha2.CordonQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateOpen}},
+				{Action: v1alpha2.UncordonQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateOpen}},
+			},
+		},
+		{
+			name:  "closed",
+			state: v1alpha2.QueueStateClosed,
+			expected: []Transition{
+				{Action: v1alpha2.OpenQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateOpen}},
+				{Action: v1alpha2.CloseQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateClosed}},
+				{Action: v1alpha2.SyncQueueAction, States: []v1alpha2.QueueState{
+					v1alpha2.QueueStateOpen, v1alpha2.QueueStateClosed, v1alpha2.QueueStateUnknown}},
+				{Action: v1alpha2.CordonQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateClosed}},
+				{Action: v1alpha2.UncordonQueueAction, States: []v1alpha2.QueueState{v1alpha2.QueueStateClosed}},
+			},
+		},
+		{
+			name:  "state not known",
+			state: "Invalid",
+		},
+	}
+
+	for _, testCase := range testCases {
+		result := Transitions(testCase.state)
+		if !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("case %s: expected %v, got %v", testCase.name, testCase.expected, result)
+		}
+		if len(result) > 0 {
+			result[0].States[0] = "Changed"
+			if transitions[v1alpha2.QueueStateOpen][0].States[0] == "Changed" ||
+				transitions[v1alpha2.QueueStateClosed][0].States[0] == "Changed" {
+				t.Errorf("case %s: expected a copy of the transitions", testCase.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"math"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/controllers/queue/helpers"
+)
+
+const (
+	// DefaultWeightMinRatio is the default lower bound of the effective weight relative to spec.weight.
+	DefaultWeightMinRatio = 0.5
+	// DefaultWeightMaxRatio is the default upper bound of the effective weight relative to spec.weight.
+	DefaultWeightMaxRatio = 2.0
+
+	// usageSmoothing is the weight of the latest usage share in the moving average of a queue.
+	usageSmoothing = 0.2
+	// maxWeightStepRatio limits the change of the effective weight in one period relative to spec.weight,
+	// so that the weights converge instead of oscillating.
+	maxWeightStepRatio = 0.1
+)
+
+// adjustWeights nudges the effective weight of every queue towards the weight which would have
+// given the queue its deserved share among its siblings, according to the moving average of its usage.
+func (c *Controller) adjustWeights(ctx context.Context) {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues: %v", err)
+		return
+	}
+
+	siblings := map[string][]*schedulingv1alpha2.Queue{}
+	for _, queue := range queues {
+		if queue.DeletionTimestamp != nil {
+			continue
+		}
+		siblings[queue.Spec.Parent] = append(siblings[queue.Spec.Parent], queue)
+	}
+
+	seen := map[string]struct{}{}
+	for _, group := range siblings {
+		var totalWeight float64
+		var totalUsed v1.ResourceList
+		for _, queue := range group {
+			totalWeight += float64(baseWeight(queue))
+			totalUsed = helpers.AddResourceList(totalUsed, queue.Status.Used)
+		}
+
+		for _, queue := range group {
+			seen[queue.Name] = struct{}{}
+
+			usage := c.smoothUsageShare(queue.Name, dominantShare(queue.Status.Used, totalUsed))
+			deserved := float64(baseWeight(queue)) / totalWeight
+			hungry := queue.Status.Pending > 0 || queue.Status.Inqueue > 0
+
+			weight := nextEffectiveWeight(baseWeight(queue), queue.Status.EffectiveWeight,
+				deserved, usage, hungry, c.weightMinRatio, c.weightMaxRatio)
+			if weight == queue.Status.EffectiveWeight {
+				continue
+			}
+
+			klog.V(3).Infof("Adjust effective weight of queue %s from %d to %d, deserved share %.3f, usage share %.3f.",
+				queue.Name, queue.Status.EffectiveWeight, weight, deserved, usage)
+			if err := c.setEffectiveWeight(ctx, queue, weight); err != nil {
+				klog.Errorf("Failed to update effective weight of queue %s: %v", queue.Name, err)
+			}
+		}
+	}
+
+	for name := range c.usageShares {
+		if _, found := seen[name]; !found {
+			delete(c.usageShares, name)
+		}
+	}
+}
+
+// resetEffectiveWeights unsets the effective weights left by a previous run with dynamic weight
+// enabled, so that scheduler uses spec.weight again.
+func (c *Controller) resetEffectiveWeights(ctx context.Context) {
+	queues, err := c.queueLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list queues: %v", err)
+		return
+	}
+
+	for _, queue := range queues {
+		if queue.Status.EffectiveWeight == 0 {
+			continue
+		}
+		if err := c.setEffectiveWeight(ctx, queue, 0); err != nil {
+			klog.Errorf("Failed to reset effective weight of queue %s: %v", queue.Name, err)
+		}
+	}
+}
+
+func (c *Controller) setEffectiveWeight(ctx context.Context, queue *schedulingv1alpha2.Queue, weight int32) error {
+	newQueue := queue.DeepCopy()
+	newQueue.Status.EffectiveWeight = weight
+
+	return callWithContext(ctx, func() error {
+		_, err := c.vcClient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue)
+		return err
+	})
+}
+
+// smoothUsageShare adds the latest usage share of the queue to its exponential moving average.
+func (c *Controller) smoothUsageShare(name string, share float64) float64 {
+	avg, found := c.usageShares[name]
+	if !found {
+		avg = share
+	} else {
+		avg = usageSmoothing*share + (1-usageSmoothing)*avg
+	}
+	c.usageShares[name] = avg
+
+	return avg
+}
+
+// baseWeight returns spec.weight of the queue, which is never changed by the controller.
+func baseWeight(queue *schedulingv1alpha2.Queue) int32 {
+	if queue.Spec.Weight < 1 {
+		return 1
+	}
+
+	return queue.Spec.Weight
+}
+
+// dominantShare returns the largest share of the used resources in the total ones.
+func dominantShare(used, total v1.ResourceList) float64 {
+	var share float64
+	for name, quantity := range used {
+		t, found := total[name]
+		if !found || t.IsZero() {
+			continue
+		}
+		if s := float64(quantity.MilliValue()) / float64(t.MilliValue()); s > share {
+			share = s
+		}
+	}
+
+	return share
+}
+
+// nextEffectiveWeight returns the effective weight of a queue after one adjustment. An over-served
+// queue loses weight; an underserved queue gains weight only if it is hungry, i.e. it has podgroups
+// waiting for resources; otherwise the weight goes back to spec.weight. The weight moves by at most
+// maxWeightStepRatio of spec.weight at a time, and stays within [minRatio, maxRatio] of spec.weight.
+func nextEffectiveWeight(base, current int32, deserved, usage float64, hungry bool, minRatio, maxRatio float64) int32 {
+	if current <= 0 {
+		current = base
+	}
+
+	target := float64(base)
+	switch {
+	case usage > deserved:
+		target = float64(base) * deserved / usage
+	case usage < deserved && hungry:
+		target = float64(base) * maxRatio
+		if usage > 0 {
+			target = float64(base) * deserved / usage
+		}
+	}
+
+	lower := math.Max(1, float64(base)*minRatio)
+	upper := math.Max(lower, float64(base)*maxRatio)
+	target = math.Min(math.Max(target, lower), upper)
+
+	step := math.Max(1, float64(base)*maxWeightStepRatio)
+	next := float64(current)
+	if target > next {
+		next = math.Min(target, next+step)
+	} else {
+		next = math.Max(target, next-step)
+	}
+
+	return int32(math.Round(math.Min(math.Max(next, lower), upper)))
+}
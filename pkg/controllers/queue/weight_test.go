@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeclient "k8s.io/client-go/kubernetes/fake"
+
+	schedulingv1alpha2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	vcclient "volcano.sh/volcano/pkg/client/clientset/versioned/fake"
+)
+
+func TestNextEffectiveWeight(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     int32
+		current  int32
+		deserved float64
+		usage    float64
+		hungry   bool
+		maxRatio float64
+		expected int32
+	}{
+		{
+			name:     "fairly served queue keeps spec.weight",
+			base:     10,
+			deserved: 0.5,
+			usage:    0.5,
+			hungry:   true,
+			expected: 10,
+		},
+		{
+			name:     "over-served queue loses weight step by step",
+			base:     10,
+			current:  10,
+			deserved: 0.25,
+			usage:    0.5,
+			expected: 9,
+		},
+		{
+			name:     "over-served queue stops at its target",
+			base:     10,
+			current:  6,
+			deserved: 0.25,
+			usage:    0.5,
+			expected: 5,
+		},
+		{
+			name:     "over-served queue does not go below the lower bound",
+			base:     10,
+			current:  5,
+			deserved: 0.1,
+			usage:    0.9,
+			expected: 5,
+		},
+		{
+			name:     "hungry underserved queue gains weight",
+			base:     10,
+			deserved: 0.5,
+			usage:    0.25,
+			hungry:   true,
+			expected: 11,
+		},
+		{
+			name:     "hungry queue without usage gains weight",
+			base:     10,
+			current:  19,
+			deserved: 0.5,
+			hungry:   true,
+			expected: 20,
+		},
+		{
+			name:     "underserved queue without waiting podgroups goes back to spec.weight",
+			base:     10,
+			current:  12,
+			deserved: 0.5,
+			usage:    0.25,
+			expected: 11,
+		},
+		{
+			name:     "weight is clamped to the upper bound",
+			base:     10,
+			current:  20,
+			deserved: 0.5,
+			hungry:   true,
+			maxRatio: 1.5,
+			expected: 15,
+		},
+		{
+			name:     "weight is at least 1",
+			base:     1,
+			current:  1,
+			deserved: 0.1,
+			usage:    0.9,
+			expected: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		maxRatio := testCase.maxRatio
+		if maxRatio == 0 {
+			maxRatio = DefaultWeightMaxRatio
+		}
+		weight := nextEffectiveWeight(testCase.base, testCase.current, testCase.deserved, testCase.usage,
+			testCase.hungry, DefaultWeightMinRatio, maxRatio)
+		if weight != testCase.expected {
+			t.Errorf("case %s: expected weight %d, got %d", testCase.name, testCase.expected, weight)
+		}
+	}
+}
+
+func newWeightedQueue(name string, weight int32, cpu string, pending int32) *schedulingv1alpha2.Queue {
+	return &schedulingv1alpha2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       schedulingv1alpha2.QueueSpec{Weight: weight},
+		Status: schedulingv1alpha2.QueueStatus{
+			Used:    v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+			Pending: pending,
+		},
+	}
+}
+
+func TestAdjustWeights(t *testing.T) {
+	vcClient := vcclient.NewSimpleClientset()
+	opt := NewOptions()
+	c := NewQueueController(kubeclient.NewSimpleClientset(), vcClient, opt)
+
+	queues := []*schedulingv1alpha2.Queue{
+		newWeightedQueue("busy", 10, "3", 0),
+		newWeightedQueue("hungry", 10, "1", 1),
+	}
+	for _, queue := range queues {
+		vcClient.SchedulingV1alpha2().Queues().Create(queue)
+		c.queueInformer.Informer().GetIndexer().Add(queue)
+	}
+	c.usageShares["deleted"] = 0.5
+
+	c.adjustWeights(context.Background())
+
+	expected := map[string]int32{"busy": 9, "hungry": 11}
+	for name, weight := range expected {
+		queue, err := vcClient.SchedulingV1alpha2().Queues().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get queue %s: %v", name, err)
+		}
+		if queue.Status.EffectiveWeight != weight {
+			t.Errorf("queue %s: expected effective weight %d, got %d", name, weight, queue.Status.EffectiveWeight)
+		}
+		if queue.Spec.Weight != 10 {
+			t.Errorf("queue %s: expected spec.weight not to be changed, got %d", name, queue.Spec.Weight)
+		}
+	}
+	if _, found := c.usageShares["deleted"]; found {
+		t.Errorf("expected usage share of deleted queue to be dropped")
+	}
+}
+
+func TestResetEffectiveWeights(t *testing.T) {
+	vcClient := vcclient.NewSimpleClientset()
+	c := NewQueueController(kubeclient.NewSimpleClientset(), vcClient, NewOptions())
+
+	queue := newWeightedQueue("q1", 10, "1", 0)
+	queue.Status.EffectiveWeight = 15
+	vcClient.SchedulingV1alpha2().Queues().Create(queue)
+	c.queueInformer.Informer().GetIndexer().Add(queue)
+
+	c.resetEffectiveWeights(context.Background())
+
+	updated, err := vcClient.SchedulingV1alpha2().Queues().Get("q1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get queue: %v", err)
+	}
+	if updated.Status.EffectiveWeight != 0 {
+		t.Errorf("expected effective weight to be reset, got %d", updated.Status.EffectiveWeight)
+	}
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+const (
+	// TraceParentAnnotationKey is the annotation of objects, e.g. commands, carrying the W3C
+	// traceparent of the operation which created them, so that their spans join its trace.
+	TraceParentAnnotationKey = "volcano.sh/traceparent"
+
+	// TraceParentEnv is the environment variable of the W3C traceparent of the caller of vcctl, e.g. a
+	// CI pipeline, which vcctl sets in the annotation of the commands it creates.
+	TraceParentEnv = "TRACEPARENT"
+
+	// exportPeriod is the interval of exporting the ended spans.
+	exportPeriod = 5 * time.Second
+	// maxPendingSpans is the number of ended spans kept until exported, newer spans are dropped.
+	maxPendingSpans = 2048
+	// exportTimeout is the timeout of exporting spans to the collector.
+	exportTimeout = 10 * time.Second
+
+	// tracesPath is the default path of the OTLP/HTTP traces endpoint.
+	tracesPath = "/v1/traces"
+	// instrumentationScope is the name of the instrumentation scope of the spans.
+	instrumentationScope = "volcano.sh/volcano"
+
+	// OTLP status codes and span kind.
+	statusCodeError  = 2
+	spanKindInternal = 1
+)
+
+// Attribute is a string attribute of a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr creates an attribute of a span.
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer creates the spans of the controllers and exports them to an OpenTelemetry collector
+// by OTLP/HTTP with JSON encoding; a nil Tracer creates no spans, so tracing is a no-op unless
+// it is configured.
+//
+// It is a minimal exporter rather than the OpenTelemetry SDK, which is not vendored: a failed
+// export is not retried and its spans are dropped, so are the spans ended while maxPendingSpans
+// spans wait for the export, and no headers, compression or TLS settings other than the system
+// certificate pool are supported. Put a local collector in front of the backend if those matter.
+type Tracer struct {
+	endpoint string
+	service  string
+	client   *http.Client
+
+	mutex   sync.Mutex
+	pending []*Span
+	dropped int
+}
+
+// NewTracer creates a tracer exporting the spans of service to the OTLP/HTTP endpoint, the
+// path defaults to /v1/traces, e.g. http://otel-collector:4318.
+func NewTracer(endpoint, service string) (*Tracer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenTelemetry endpoint %q: %v", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("OpenTelemetry endpoint must be an http or https URL, got %q", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = tracesPath
+	}
+
+	return &Tracer{
+		endpoint: u.String(),
+		service:  service,
+		client:   &http.Client{Timeout: exportTimeout},
+	}, nil
+}
+
+// Run exports the ended spans periodically until stopCh is closed.
+func (t *Tracer) Run(stopCh <-chan struct{}) {
+	if t == nil {
+		return
+	}
+
+	wait.Until(t.Flush, exportPeriod, stopCh)
+}
+
+type spanContextKey struct{}
+
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+// ContextWithTraceParent returns a context whose spans are children of the W3C traceparent,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; the context is returned
+// as it is if traceparent is malformed.
+func ContextWithTraceParent(ctx context.Context, traceparent string) context.Context {
+	parent, ok := parseTraceParent(traceparent)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, parent)
+}
+
+// TraceParentFromEnv returns the traceparent of TraceParentEnv, or an empty string if it is not set
+// or malformed.
+func TraceParentFromEnv() string {
+	traceparent := os.Getenv(TraceParentEnv)
+	if _, ok := parseTraceParent(traceparent); !ok {
+		return ""
+	}
+
+	return traceparent
+}
+
+func parseTraceParent(traceparent string) (spanContext, bool) {
+	var parent spanContext
+
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return parent, false
+	}
+	if _, err := hex.Decode(parent.traceID[:], []byte(parts[1])); err != nil {
+		return parent, false
+	}
+	if _, err := hex.Decode(parent.spanID[:], []byte(parts[2])); err != nil {
+		return parent, false
+	}
+	if parent.traceID == [16]byte{} || parent.spanID == [8]byte{} {
+		return parent, false
+	}
+
+	return parent, true
+}
+
+// Start starts a span, which is a child of the span of ctx if any; the returned context
+// carries the new span.
+func (t *Tracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		start:      time.Now(),
+		attributes: attributes,
+	}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.hasParent = true
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+
+	return context.WithValue(ctx, spanContextKey{}, spanContext{traceID: span.traceID, spanID: span.spanID}), span
+}
+
+// Span is an operation of a trace, the methods of a nil Span do nothing.
+type Span struct {
+	tracer *Tracer
+
+	traceID   [16]byte
+	spanID    [8]byte
+	parentID  [8]byte
+	hasParent bool
+
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        error
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// End ends the span with its result, the error is recorded on the span if err is not nil.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+
+	s.end = time.Now()
+	s.err = err
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	s.attributes = append(s.attributes, Attr("result", result))
+
+	s.tracer.add(s)
+}
+
+func (t *Tracer) add(span *Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.pending) >= maxPendingSpans {
+		t.dropped++
+		return
+	}
+	t.pending = append(t.pending, span)
+}
+
+// Flush exports the ended spans, the spans are dropped if the export fails.
+func (t *Tracer) Flush() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	spans, dropped := t.pending, t.dropped
+	t.pending, t.dropped = nil, 0
+	t.mutex.Unlock()
+
+	if dropped > 0 {
+		klog.Warningf("Dropped %d spans as the export to %s falls behind.", dropped, t.endpoint)
+	}
+	if len(spans) == 0 {
+		return
+	}
+
+	if err := t.export(spans); err != nil {
+		klog.Warningf("Failed to export %d spans to %s: %v.", len(spans), t.endpoint, err)
+	}
+}
+
+func (t *Tracer) export(spans []*Span) error {
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// The OTLP/HTTP JSON encoding of spans, the IDs are hex encoded and the times are strings of
+// nanoseconds since the epoch.
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []keyValue  `json:"attributes,omitempty"`
+	Events            []spanEvent `json:"events,omitempty"`
+	Status            *spanStatus `json:"status,omitempty"`
+}
+
+type spanEvent struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type spanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func newKeyValues(attributes []Attribute) []keyValue {
+	var result []keyValue
+	for _, attribute := range attributes {
+		result = append(result, keyValue{Key: attribute.Key, Value: anyValue{StringValue: attribute.Value}})
+	}
+
+	return result
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func (t *Tracer) encode(spans []*Span) *exportRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.traceID[:]),
+			SpanID:            hex.EncodeToString(span.spanID[:]),
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: unixNano(span.start),
+			EndTimeUnixNano:   unixNano(span.end),
+			Attributes:        newKeyValues(span.attributes),
+		}
+		if span.hasParent {
+			s.ParentSpanID = hex.EncodeToString(span.parentID[:])
+		}
+		if span.err != nil {
+			s.Status = &spanStatus{Code: statusCodeError, Message: span.err.Error()}
+			s.Events = []spanEvent{{
+				TimeUnixNano: unixNano(span.end),
+				Name:         "exception",
+				Attributes:   newKeyValues([]Attribute{Attr("exception.message", span.err.Error())}),
+			}}
+		}
+		encoded = append(encoded, s)
+	}
+
+	return &exportRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource: resource{Attributes: newKeyValues([]Attribute{Attr("service.name", t.service)})},
+			ScopeSpans: []scopeSpans{{
+				Scope: scope{Name: instrumentationScope},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
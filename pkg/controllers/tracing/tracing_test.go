@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func newCollector(t *testing.T) (*httptest.Server, *[]exportRequest) {
+	var received []exportRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != tracesPath {
+			t.Errorf("expected spans posted to %s, got %s", tracesPath, r.URL.Path)
+		}
+		request := exportRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode spans: %v", err)
+		}
+		received = append(received, request)
+	}))
+
+	return server, &received
+}
+
+func attributes(kvs []keyValue) map[string]string {
+	result := map[string]string{}
+	for _, kv := range kvs {
+		result[kv.Key] = kv.Value.StringValue
+	}
+	return result
+}
+
+func TestTracer(t *testing.T) {
+	server, received := newCollector(t)
+	defer server.Close()
+
+	tracer, err := NewTracer(server.URL, "vc-controllers")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx := ContextWithTraceParent(context.TODO(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, parent := tracer.Start(ctx, "handleCommand", Attr("action", "CloseQueue"))
+	_, child := tracer.Start(ctx, "handleQueue", Attr("queue", "q1"))
+	child.End(fmt.Errorf("queue q1 not found"))
+	parent.End(nil)
+	tracer.Flush()
+
+	if len(*received) != 1 {
+		t.Fatalf("expected spans exported once, got %d", len(*received))
+	}
+	request := (*received)[0]
+	if service := attributes(request.ResourceSpans[0].Resource.Attributes)["service.name"]; service != "vc-controllers" {
+		t.Errorf("expected service vc-controllers, got %s", service)
+	}
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	queueSpan, commandSpan := spans[0], spans[1]
+	if commandSpan.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || commandSpan.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected command span in trace of traceparent, got %+v", commandSpan)
+	}
+	if queueSpan.TraceID != commandSpan.TraceID || queueSpan.ParentSpanID != commandSpan.SpanID {
+		t.Errorf("expected queue span child of command span, got %+v", queueSpan)
+	}
+	if attrs := attributes(queueSpan.Attributes); attrs["queue"] != "q1" || attrs["result"] != "error" {
+		t.Errorf("expected queue q1 and result error, got %v", attrs)
+	}
+	if queueSpan.Status == nil || queueSpan.Status.Code != statusCodeError || len(queueSpan.Events) != 1 {
+		t.Errorf("expected error recorded on queue span, got %+v", queueSpan)
+	}
+	if attrs := attributes(commandSpan.Attributes); attrs["result"] != "success" || commandSpan.Status != nil {
+		t.Errorf("expected successful command span, got %+v", commandSpan)
+	}
+
+	// Nothing is exported without spans.
+	tracer.Flush()
+	if len(*received) != 1 {
+		t.Errorf("expected no export without spans, got %d exports", len(*received))
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.TODO(), "handleQueue")
+	if ctx != context.TODO() || span != nil {
+		t.Errorf("expected no span of nil tracer")
+	}
+	span.SetAttributes(Attr("queue", "q1"))
+	span.End(nil)
+	tracer.Flush()
+}
+
+func TestContextWithTraceParent(t *testing.T) {
+	for _, traceparent := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+	} {
+		if ctx := ContextWithTraceParent(context.TODO(), traceparent); ctx != context.TODO() {
+			t.Errorf("expected malformed traceparent %q ignored", traceparent)
+		}
+	}
+}
+
+func TestTraceParentFromEnv(t *testing.T) {
+	defer os.Unsetenv(TraceParentEnv)
+
+	testCases := map[string]string{
+		"":                                       "",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-01": "",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	for env, expected := range testCases {
+		os.Setenv(TraceParentEnv, env)
+		if traceparent := TraceParentFromEnv(); traceparent != expected {
+			t.Errorf("expected traceparent %q of %q, got %q", expected, env, traceparent)
+		}
+	}
+}
+
+func TestNewTracer(t *testing.T) {
+	testCases := []struct {
+		endpoint  string
+		expected  string
+		expectErr bool
+	}{
+		{endpoint: "http://otel-collector:4318", expected: "http://otel-collector:4318/v1/traces"},
+		{endpoint: "https://otel-collector/otlp/v1/traces", expected: "https://otel-collector/otlp/v1/traces"},
+		{endpoint: "otel-collector:4317", expectErr: true},
+		{endpoint: "grpc://otel-collector:4317", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		tracer, err := NewTracer(testCase.endpoint, "vc-controllers")
+		if testCase.expectErr != (err != nil) {
+			t.Errorf("endpoint %s: expected error %v, got %v", testCase.endpoint, testCase.expectErr, err)
+			continue
+		}
+		if err == nil && tracer.endpoint != testCase.expected {
+			t.Errorf("endpoint %s: expected spans exported to %s, got %s", testCase.endpoint, testCase.expected, tracer.endpoint)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deadlock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
+)
+
+const (
+	// stalledSessions is the number of consecutive sessions in which a set of
+	// blocked jobs across queues makes no progress before it is treated as a deadlock.
+	stalledSessions = "deadlock.stalledSessions"
+)
+
+var (
+	// defaultStalledSessions defines the default number of stalled sessions for deadlock action
+	defaultStalledSessions = 3
+)
+
+type deadlockAction struct {
+	// signature of the blocked jobs found in the last session.
+	lastSignature string
+	// stalled is the number of consecutive sessions the signature did not change.
+	stalled int
+}
+
+func New() *deadlockAction {
+	return &deadlockAction{}
+}
+
+func (dl *deadlockAction) Name() string {
+	return "deadlock"
+}
+
+func (dl *deadlockAction) Initialize() {}
+
+func (dl *deadlockAction) Execute(ssn *framework.Session) {
+	klog.V(3).Infof("Enter Deadlock ...")
+	defer klog.V(3).Infof("Leaving Deadlock ...")
+
+	blocked := blockedJobs(ssn)
+	if countQueues(blocked) < 2 {
+		dl.lastSignature = ""
+		dl.stalled = 0
+		return
+	}
+
+	signature := jobsSignature(blocked)
+	if signature == dl.lastSignature {
+		dl.stalled++
+	} else {
+		dl.lastSignature = signature
+		dl.stalled = 1
+	}
+
+	threshold := dl.getStalledSessions(ssn)
+	if dl.stalled < threshold {
+		klog.V(4).Infof("Blocked jobs <%s> made no progress for %d of %d sessions.",
+			signature, dl.stalled, threshold)
+		return
+	}
+
+	sort.Slice(blocked, func(i, j int) bool {
+		return higherPrecedence(blocked[i], blocked[j])
+	})
+
+	winner := blocked[0]
+	victim := selectVictim(winner, blocked)
+
+	klog.Warningf("Deadlock detected between <%d> jobs in <%d> queues after %d sessions without progress: %s; "+
+		"Job <%s/%s> in Queue <%s> keeps its resources, Job <%s/%s> in Queue <%s> is evicted to break the deadlock.",
+		len(blocked), countQueues(blocked), dl.stalled, signature,
+		winner.Namespace, winner.Name, winner.Queue, victim.Namespace, victim.Name, victim.Queue)
+	metrics.RegisterDeadlockDetected()
+
+	for _, task := range victim.Tasks {
+		if !api.AllocatedStatus(task.Status) {
+			continue
+		}
+		if err := ssn.Evict(task, "deadlock"); err != nil {
+			klog.Errorf("Failed to evict Task <%s/%s> to break deadlock: %v",
+				task.Namespace, task.Name, err)
+		}
+	}
+
+	dl.lastSignature = ""
+	dl.stalled = 0
+}
+
+func (dl *deadlockAction) UnInitialize() {}
+
+func (dl *deadlockAction) getStalledSessions(ssn *framework.Session) int {
+	threshold := defaultStalledSessions
+	arg := framework.GetArgOfActionFromConf(ssn.Configurations, dl.Name())
+	if arg != nil {
+		arg.GetInt(&threshold, stalledSessions)
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return threshold
+}
+
+// blockedJobs returns the jobs which hold part of their gang resources, still have pending tasks
+// and are not ready to run, while the missing tasks of their gangs do not fit on the idle resources;
+// i.e. the gangs do not fit on the idle resources plus the resources they hold.
+func blockedJobs(ssn *framework.Session) []*api.JobInfo {
+	idle := api.EmptyResource()
+	for _, node := range ssn.Nodes {
+		idle.Add(node.FutureIdle())
+	}
+
+	var blocked []*api.JobInfo
+	for _, job := range ssn.Jobs {
+		if job.PodGroup == nil {
+			continue
+		}
+		if len(job.TaskStatusIndex[api.Pending]) == 0 {
+			continue
+		}
+		if job.ReadyTaskNum() == 0 || ssn.JobReady(job) {
+			continue
+		}
+		if missingResources(job).LessEqual(idle) {
+			continue
+		}
+		blocked = append(blocked, job)
+	}
+
+	return blocked
+}
+
+// missingResources returns the resources requested by the smallest pending tasks which the job
+// is missing to reach its minAvailable.
+func missingResources(job *api.JobInfo) *api.Resource {
+	var pending []*api.TaskInfo
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		pending = append(pending, task)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Resreq.MilliCPU != pending[j].Resreq.MilliCPU {
+			return pending[i].Resreq.MilliCPU < pending[j].Resreq.MilliCPU
+		}
+		if pending[i].Resreq.Memory != pending[j].Resreq.Memory {
+			return pending[i].Resreq.Memory < pending[j].Resreq.Memory
+		}
+		return pending[i].UID < pending[j].UID
+	})
+
+	missing := api.EmptyResource()
+	for i := int32(0); i < job.MinAvailable-job.ReadyTaskNum() && int(i) < len(pending); i++ {
+		missing.Add(pending[i].Resreq)
+	}
+
+	return missing
+}
+
+func countQueues(jobs []*api.JobInfo) int {
+	queues := map[api.QueueID]struct{}{}
+	for _, job := range jobs {
+		queues[job.Queue] = struct{}{}
+	}
+
+	return len(queues)
+}
+
+// jobsSignature builds a stable description of the blocked jobs, which changes
+// as soon as any of them makes progress.
+func jobsSignature(jobs []*api.JobInfo) string {
+	var parts []string
+	for _, job := range jobs {
+		parts = append(parts, fmt.Sprintf("%s/%s(%d/%d)",
+			job.Queue, job.UID, job.ReadyTaskNum(), job.MinAvailable))
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, ",")
+}
+
+// higherPrecedence orders jobs by priority first, then by creation time (older first),
+// and finally by UID so that the decision is deterministic.
+func higherPrecedence(l, r *api.JobInfo) bool {
+	if l.Priority != r.Priority {
+		return l.Priority > r.Priority
+	}
+	if !l.CreationTimestamp.Equal(&r.CreationTimestamp) {
+		return l.CreationTimestamp.Before(&r.CreationTimestamp)
+	}
+
+	return l.UID < r.UID
+}
+
+// selectVictim returns the job with the lowest precedence which is not in the winner's queue;
+// jobs must be sorted by precedence.
+func selectVictim(winner *api.JobInfo, jobs []*api.JobInfo) *api.JobInfo {
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].Queue != winner.Queue {
+			return jobs[i]
+		}
+	}
+
+	return jobs[len(jobs)-1]
+}
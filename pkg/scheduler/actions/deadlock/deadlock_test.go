@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deadlock
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/plugins/gang"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestDeadlock(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := metav1.NewTime(time.Now())
+
+	tests := []struct {
+		name      string
+		podGroups []*schedulingv2.PodGroup
+		pods      []*v1.Pod
+		nodes     []*v1.Node
+		queues    []*schedulingv2.Queue
+		sessions  int
+		expected  []string
+	}{
+		{
+			name: "Two Queue with half-started gangs, should evict the newer one after stalled sessions",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "pg1",
+						Namespace:         "c1",
+						CreationTimestamp: older,
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "pg2",
+						Namespace:         "c1",
+						CreationTimestamp: newer,
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "pg1-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg1-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("2", "2Gi"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q2",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			sessions: defaultStalledSessions,
+			expected: []string{"c1/pg2-running"},
+		},
+		{
+			name: "Two Queue with half-started gangs, should not evict before stalled sessions",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "pg1-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg1-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("2", "2Gi"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q2",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			sessions: defaultStalledSessions - 1,
+			expected: nil,
+		},
+		{
+			name: "Two Queue with half-started gangs fitting on idle resources, should not evict",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 2,
+						Queue:     "q2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "pg1-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg1-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-running", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "pg2-pending", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("3", "4Gi"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q2",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			sessions: defaultStalledSessions,
+			expected: nil,
+		},
+	}
+
+	for i, test := range tests {
+		deadlock := New()
+
+		binder := &util.FakeBinder{
+			Binds:   map[string]string{},
+			Channel: make(chan string),
+		}
+		evictor := &util.FakeEvictor{
+			Channel: make(chan string),
+		}
+		schedulerCache := &cache.SchedulerCache{
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Queues:        make(map[api.QueueID]*api.QueueInfo),
+			Binder:        binder,
+			Evictor:       evictor,
+			StatusUpdater: &util.FakeStatusUpdater{},
+			VolumeBinder:  &util.FakeVolumeBinder{},
+
+			Recorder: record.NewFakeRecorder(100),
+		}
+		for _, node := range test.nodes {
+			schedulerCache.AddNode(node)
+		}
+		for _, pod := range test.pods {
+			schedulerCache.AddPod(pod)
+		}
+
+		for _, ss := range test.podGroups {
+			schedulerCache.AddPodGroupV1alpha2(ss)
+		}
+
+		for _, q := range test.queues {
+			schedulerCache.AddQueueV1alpha2(q)
+		}
+
+		trueValue := true
+		tiers := []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:            "gang",
+						EnabledJobReady: &trueValue,
+					},
+				},
+			},
+		}
+
+		for s := 0; s < test.sessions; s++ {
+			ssn := framework.OpenSession(schedulerCache, tiers, nil)
+			deadlock.Execute(ssn)
+			framework.CloseSession(ssn)
+		}
+
+		for range test.expected {
+			select {
+			case <-evictor.Channel:
+			case <-time.After(3 * time.Second):
+				t.Errorf("Failed to get Evictor request.")
+			}
+		}
+
+		evicts := evictor.Evicts()
+		if len(test.expected) != len(evicts) {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.expected, evicts)
+			continue
+		}
+		for j := range test.expected {
+			if test.expected[j] != evicts[j] {
+				t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.expected, evicts)
+			}
+		}
+	}
+}
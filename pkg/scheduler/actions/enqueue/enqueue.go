@@ -113,7 +113,8 @@ func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
 
 		inqueue := false
 
-		if job.PodGroup.Spec.MinResources == nil {
+		// Downgraded SoftGang jobs take whatever is available.
+		if job.PodGroup.Spec.MinResources == nil || api.GangDowngraded(job.PodGroup) {
 			inqueue = true
 		} else {
 			pgResource := api.NewResource(*job.PodGroup.Spec.MinResources)
@@ -18,7 +18,10 @@ package preempt
 
 import (
 	"fmt"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/apis/scheduling"
@@ -28,6 +31,18 @@ import (
 	"volcano.sh/volcano/pkg/scheduler/util"
 )
 
+const (
+	// cooldownSeconds is the number of seconds after a podgroup is preempted by other podgroups
+	// in which it is not preempted again, so that podgroups do not keep preempting each other;
+	// 0 disables the cooldown.
+	cooldownSeconds = "preempt.cooldownSeconds"
+)
+
+var (
+	// defaultCooldownSeconds defines the default preemption cooldown of podgroups
+	defaultCooldownSeconds = 30
+)
+
 type preemptAction struct {
 	ssn *framework.Session
 }
@@ -52,6 +67,9 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 	var underRequest []*api.JobInfo
 	queues := map[api.QueueID]*api.QueueInfo{}
 
+	cooldown := alloc.getCooldown(ssn)
+	now := time.Now()
+
 	for _, job := range ssn.Jobs {
 		if job.PodGroup.Status.Phase == scheduling.PodGroupPending {
 			continue
@@ -97,6 +115,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 
 			stmt := ssn.Statement()
 			assigned := false
+			victimJobs := map[api.JobID]struct{}{}
 			for {
 				// If job is pipelined, then stop preempting.
 				if ssn.JobPipelined(preemptorJob) {
@@ -122,9 +141,12 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 					if !found {
 						return false
 					}
+					if inCooldown(job, now, cooldown) {
+						return false
+					}
 					// Preempt other jobs within queue
 					return job.Queue == preemptorJob.Queue && preemptor.Job != task.Job
-				}); preempted {
+				}, victimJobs); preempted {
 					assigned = true
 				}
 			}
@@ -132,6 +154,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 			// Commit changes only if job is pipelined, otherwise try next job.
 			if ssn.JobPipelined(preemptorJob) {
 				stmt.Commit()
+				markPreempted(ssn, victimJobs, preemptorJob)
 			} else {
 				stmt.Discard()
 				continue
@@ -164,7 +187,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 
 					// Preempt tasks within job.
 					return preemptor.Job == task.Job
-				})
+				}, nil)
 				stmt.Commit()
 
 				// If no preemption, next job.
@@ -178,11 +201,63 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 
 func (alloc *preemptAction) UnInitialize() {}
 
+func (alloc *preemptAction) getCooldown(ssn *framework.Session) time.Duration {
+	seconds := defaultCooldownSeconds
+	arg := framework.GetArgOfActionFromConf(ssn.Configurations, alloc.Name())
+	if arg != nil {
+		arg.GetInt(&seconds, cooldownSeconds)
+	}
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// inCooldown returns whether the job was preempted by other jobs within the cooldown.
+func inCooldown(job *api.JobInfo, now time.Time, cooldown time.Duration) bool {
+	if cooldown == 0 || job.PodGroup == nil {
+		return false
+	}
+
+	for _, c := range job.PodGroup.Status.Conditions {
+		if c.Type == scheduling.PodGroupPreemptedType && c.Status == v1.ConditionTrue {
+			return now.Before(c.LastTransitionTime.Add(cooldown))
+		}
+	}
+
+	return false
+}
+
+// markPreempted records in the podgroups of the victim jobs that they were preempted by the preemptor,
+// which starts their cooldown.
+func markPreempted(ssn *framework.Session, victimJobs map[api.JobID]struct{}, preemptor *api.JobInfo) {
+	for jobID := range victimJobs {
+		job, found := ssn.Jobs[jobID]
+		if !found || job.PodGroup == nil {
+			continue
+		}
+
+		jc := &scheduling.PodGroupCondition{
+			Type:               scheduling.PodGroupPreemptedType,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			TransitionID:       string(ssn.UID),
+			Reason:             scheduling.PreemptedReason,
+			Message:            fmt.Sprintf("Tasks are preempted by podgroup %s/%s", preemptor.Namespace, preemptor.Name),
+		}
+		if err := ssn.UpdateJobCondition(job, jc); err != nil {
+			klog.Errorf("Failed to update condition of Job <%s/%s>: %v", job.Namespace, job.Name, err)
+		}
+	}
+}
+
 func preempt(
 	ssn *framework.Session,
 	stmt *framework.Statement,
 	preemptor *api.TaskInfo,
 	filter func(*api.TaskInfo) bool,
+	victimJobs map[api.JobID]struct{},
 ) (bool, error) {
 	assigned := false
 
@@ -236,6 +311,9 @@ func preempt(
 				continue
 			}
 			preempted.Add(preemptee.Resreq)
+			if victimJobs != nil {
+				victimJobs[preemptee.Job] = struct{}{}
+			}
 		}
 
 		metrics.RegisterPreemptionAttempts()
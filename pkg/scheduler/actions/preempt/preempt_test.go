@@ -181,6 +181,112 @@ func TestPreempt(t *testing.T) {
 			},
 			expected: 1,
 		},
+		{
+			name: "do not preempt job in preemption cooldown",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 1,
+						Queue:     "q1",
+					},
+					Status: schedulingv2.PodGroupStatus{
+						Conditions: []schedulingv2.PodGroupCondition{
+							{
+								Type:               schedulingv2.PodGroupPreemptedType,
+								Status:             v1.ConditionTrue,
+								LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Second)),
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 1,
+						Queue:     "q1",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptor1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("2", "2G"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: 0,
+		},
+		{
+			name: "preempt job after preemption cooldown",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 1,
+						Queue:     "q1",
+					},
+					Status: schedulingv2.PodGroupStatus{
+						Conditions: []schedulingv2.PodGroupCondition{
+							{
+								Type:               schedulingv2.PodGroupPreemptedType,
+								Status:             v1.ConditionTrue,
+								LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						MinMember: 1,
+						Queue:     "q1",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptor1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("2", "2G"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: 1,
+		},
 		{
 			name: "preempt enough tasks to fit large task of different job",
 			podGroups: []*schedulingv2.PodGroup{
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reclaim
+
+import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+// reclaimBudget is the resources a queue may still reclaim in the scheduling cycle, per resource
+// name of spec.maxReclaimPerCycle in the units of api.Resource; a nil budget is unlimited.
+type reclaimBudget map[v1.ResourceName]float64
+
+// newReclaimBudgets returns the budgets of the queues with spec.maxReclaimPerCycle for the cycle.
+func newReclaimBudgets(ssn *framework.Session) map[api.QueueID]reclaimBudget {
+	budgets := map[api.QueueID]reclaimBudget{}
+	for _, queue := range ssn.Queues {
+		if queue.Queue == nil || len(queue.Queue.Spec.MaxReclaimPerCycle) == 0 {
+			continue
+		}
+
+		limit := api.NewResource(queue.Queue.Spec.MaxReclaimPerCycle)
+		budget := reclaimBudget{}
+		for rn := range queue.Queue.Spec.MaxReclaimPerCycle {
+			budget[rn] = limit.Get(rn)
+		}
+		budgets[queue.UID] = budget
+	}
+
+	return budgets
+}
+
+// allows returns whether the resources can be reclaimed within the budget.
+func (b reclaimBudget) allows(res *api.Resource) bool {
+	for rn, remaining := range b {
+		if res.Get(rn) > remaining {
+			return false
+		}
+	}
+
+	return true
+}
+
+// consume takes the reclaimed resources from the budget.
+func (b reclaimBudget) consume(res *api.Resource) {
+	for rn, remaining := range b {
+		b[rn] = math.Max(remaining-res.Get(rn), 0)
+	}
+}
+
+// resourceList returns the remaining budget as a resource list.
+func (b reclaimBudget) resourceList() v1.ResourceList {
+	if b == nil {
+		return nil
+	}
+
+	list := v1.ResourceList{}
+	for rn, remaining := range b {
+		switch rn {
+		case v1.ResourceMemory:
+			list[rn] = *resource.NewQuantity(int64(remaining), resource.BinarySI)
+		default:
+			list[rn] = *resource.NewMilliQuantity(int64(remaining), resource.DecimalSI)
+		}
+	}
+
+	return list
+}
+
+// updateReclaimBudgets records the remaining budgets in the status of queues.
+func updateReclaimBudgets(ssn *framework.Session, budgets map[api.QueueID]reclaimBudget) {
+	for _, queue := range ssn.Queues {
+		if queue.Queue == nil {
+			continue
+		}
+
+		remaining := budgets[queue.UID].resourceList()
+		if equality.Semantic.DeepEqual(remaining, queue.Queue.Status.ReclaimBudget) {
+			continue
+		}
+
+		updated := queue.Clone()
+		updated.Queue = queue.Queue.DeepCopy()
+		updated.Queue.Status.ReclaimBudget = remaining
+		if err := ssn.UpdateQueueStatus(updated); err != nil {
+			klog.Errorf("Failed to update reclaim budget of Queue <%s>: %v", queue.Name, err)
+		}
+	}
+}
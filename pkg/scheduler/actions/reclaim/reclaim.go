@@ -17,9 +17,13 @@ limitations under the License.
 package reclaim
 
 import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/framework"
 	"volcano.sh/volcano/pkg/scheduler/util"
@@ -52,6 +56,13 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 	klog.V(3).Infof("There are <%d> Jobs and <%d> Queues in total for scheduling.",
 		len(ssn.Jobs), len(ssn.Queues))
 
+	// exempts are the jobs whose tasks are skipped as victims, the event is recorded once per session.
+	exempts := map[api.JobID]bool{}
+
+	// budgets caps the resources reclaimed by the queues in this cycle.
+	budgets := newReclaimBudgets(ssn)
+	defer updateReclaimBudgets(ssn, budgets)
+
 	var underRequest []*api.JobInfo
 	for _, job := range ssn.Jobs {
 		if job.PodGroup.Status.Phase == scheduling.PodGroupPending {
@@ -118,6 +129,15 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 			task = tasks.Pop().(*api.TaskInfo)
 		}
 
+		// The queue reclaims no more in this cycle once the task does not fit its budget,
+		// the rest of its deficit is reclaimed in the next cycles.
+		budget := budgets[queue.UID]
+		if !budget.allows(task.InitResreq) {
+			klog.V(3).Infof("Reclaim budget <%v> of Queue <%s> is not enough for Task <%s/%s>, stop reclaiming.",
+				budget, queue.Name, task.Namespace, task.Name)
+			continue
+		}
+
 		assigned := false
 		for _, n := range ssn.Nodes {
 			// If predicates failed, next node.
@@ -141,6 +161,17 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 				if j, found := ssn.Jobs[task.Job]; !found {
 					continue
 				} else if j.Queue != job.Queue {
+					// Only the queues marked reclaimable give back their resources.
+					if q, found := ssn.Queues[j.Queue]; !found || !q.Reclaimable {
+						continue
+					}
+					if exempt(j) {
+						if !exempts[j.UID] {
+							exempts[j.UID] = true
+							recordExemptEvent(ssn, job, j)
+						}
+						continue
+					}
 					// Clone task to avoid modify Task's status on node.
 					reclaimees = append(reclaimees, task.Clone())
 				}
@@ -162,16 +193,40 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 				continue
 			}
 
-			// Reclaim victims for tasks.
-			for _, reclaimee := range victims {
+			victimsQueue := util.NewPriorityQueue(func(l, r interface{}) bool {
+				lv := l.(*api.TaskInfo)
+				rv := r.(*api.TaskInfo)
+				lj, rj := ssn.Jobs[lv.Job], ssn.Jobs[rv.Job]
+				// Reclaim from overcommitted queues first.
+				if lo, ro := overcommitted(ssn, lj), overcommitted(ssn, rj); lo != ro {
+					return lo
+				}
+				if lv.Job != rv.Job {
+					return !ssn.JobOrderFn(lj, rj)
+				}
+				return !ssn.TaskOrderFn(l, r)
+			})
+			for _, victim := range victims {
+				victimsQueue.Push(victim)
+			}
+
+			// Reclaim victims for tasks, pick the tasks of overcommitted queues and lowest priority podgroup first.
+			for !victimsQueue.Empty() {
+				reclaimee := victimsQueue.Pop().(*api.TaskInfo)
+				if !budget.allows(reclaimee.Resreq) {
+					klog.V(3).Infof("Reclaim budget <%v> of Queue <%s> is not enough for Task <%s/%s>.",
+						budget, queue.Name, reclaimee.Namespace, reclaimee.Name)
+					break
+				}
 				klog.Errorf("Try to reclaim Task <%s/%s> for Tasks <%s/%s>",
 					reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name)
-				if err := ssn.Evict(reclaimee, "reclaim"); err != nil {
+				if err := ssn.Evict(reclaimee, "reclaim", reclaimEvents(ssn, job, reclaimee)); err != nil {
 					klog.Errorf("Failed to reclaim Task <%s/%s> for Tasks <%s/%s>: %v",
 						reclaimee.Namespace, reclaimee.Name, task.Namespace, task.Name, err)
 					continue
 				}
 				reclaimed.Add(reclaimee.Resreq)
+				budget.consume(reclaimee.Resreq)
 				// If reclaimed enough resources, break loop to avoid Sub panic.
 				if resreq.LessEqual(reclaimed) {
 					break
@@ -203,3 +258,53 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 
 func (ra *reclaimAction) UnInitialize() {
 }
+
+// overcommitted returns whether the queue of the job exceeds its capability by the overcommit ratio.
+func overcommitted(ssn *framework.Session, job *api.JobInfo) bool {
+	queue, found := ssn.Queues[job.Queue]
+	return found && queue.Queue != nil && queue.Queue.Status.Overcommitted
+}
+
+// reclaimEvents returns the function recording events on both the podgroup which reclaims resources and
+// the reclaimed one. It is called once the reclaimee is evicted, which may be after the session is closed,
+// so the podgroups are copied.
+func reclaimEvents(ssn *framework.Session, reclaimer *api.JobInfo, reclaimee *api.TaskInfo) func() {
+	reclaimeeJob, found := ssn.Jobs[reclaimee.Job]
+	if !found {
+		return func() {}
+	}
+
+	reclaimerPG, reclaimeePG := copyPodGroup(reclaimer), copyPodGroup(reclaimeeJob)
+	reclaimMsg := fmt.Sprintf("Reclaim Task <%s/%s> of Queue <%s> for Queue <%s>",
+		reclaimee.Namespace, reclaimee.Name, reclaimeeJob.Queue, reclaimer.Queue)
+	reclaimedMsg := fmt.Sprintf("Task <%s/%s> is reclaimed by Job <%s/%s> of Queue <%s>",
+		reclaimee.Namespace, reclaimee.Name, reclaimer.Namespace, reclaimer.Name, reclaimer.Queue)
+
+	return func() {
+		ssn.RecordPodGroupEvent(reclaimerPG, v1.EventTypeNormal, "Reclaim", reclaimMsg)
+		ssn.RecordPodGroupEvent(reclaimeePG, v1.EventTypeWarning, "Reclaimed", reclaimedMsg)
+	}
+}
+
+// copyPodGroup returns a job with only a copy of the podgroup of the job, for recording events.
+func copyPodGroup(job *api.JobInfo) *api.JobInfo {
+	if job.PodGroup == nil {
+		return &api.JobInfo{}
+	}
+
+	return &api.JobInfo{
+		PodGroup: &api.PodGroup{PodGroup: *job.PodGroup.PodGroup.DeepCopy(), Version: job.PodGroup.Version},
+	}
+}
+
+// exempt returns whether the tasks of the job must never be reclaimed.
+func exempt(job *api.JobInfo) bool {
+	return job.PodGroup != nil && job.PodGroup.Annotations[v1alpha2.NoReclaimAnnotationKey] == "true"
+}
+
+// recordExemptEvent records an event on the exempt podgroup whose tasks are skipped for the reclaimer.
+func recordExemptEvent(ssn *framework.Session, reclaimer, exempt *api.JobInfo) {
+	ssn.RecordPodGroupEvent(exempt, v1.EventTypeNormal, "ReclaimSkipped",
+		fmt.Sprintf("Tasks are not reclaimed for Job <%s/%s> of Queue <%s> as the podgroup is annotated with %s",
+			reclaimer.Namespace, reclaimer.Name, reclaimer.Queue, v1alpha2.NoReclaimAnnotationKey))
+}
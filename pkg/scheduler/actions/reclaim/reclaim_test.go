@@ -17,13 +17,17 @@ limitations under the License.
 package reclaim
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
+	"volcano.sh/volcano/pkg/apis/scheduling"
 	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/cache"
@@ -39,6 +43,7 @@ func TestReclaim(t *testing.T) {
 	framework.RegisterPluginBuilder("gang", gang.New)
 	defer framework.CleanupPluginBuilders()
 
+	falseValue := false
 	tests := []struct {
 		name      string
 		podGroups []*schedulingv2.PodGroup
@@ -46,6 +51,7 @@ func TestReclaim(t *testing.T) {
 		nodes     []*v1.Node
 		queues    []*schedulingv2.Queue
 		expected  int
+		skipped   int
 	}{
 		{
 			name: "Two Queue with one Queue overusing resource, should reclaim",
@@ -98,6 +104,111 @@ func TestReclaim(t *testing.T) {
 			},
 			expected: 1,
 		},
+		{
+			name: "Two Queue with one Queue overusing resource but not reclaimable, should not reclaim",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						Queue: "q1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						Queue: "q2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee3", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptor1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("3", "3Gi"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight:      1,
+						Reclaimable: &falseValue,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q2",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: 0,
+		},
+		{
+			name: "Two Queue with one Queue overusing resource but exempt from reclaim, should not reclaim",
+			podGroups: []*schedulingv2.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "pg1",
+						Namespace:   "c1",
+						Annotations: map[string]string{schedulingv2.NoReclaimAnnotationKey: "true"},
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						Queue: "q1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: schedulingv2.PodGroupSpec{
+						Queue: "q2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				util.BuildPod("c1", "preemptee1", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee2", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptee3", "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				util.BuildPod("c1", "preemptor1", "", v1.PodPending, util.BuildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				util.BuildNode("n1", util.BuildResourceList("3", "3Gi"), make(map[string]string)),
+			},
+			queues: []*schedulingv2.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q1",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "q2",
+					},
+					Spec: schedulingv2.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: 0,
+			skipped:  1,
+		},
 	}
 
 	reclaim := New()
@@ -110,6 +221,7 @@ func TestReclaim(t *testing.T) {
 		evictor := &util.FakeEvictor{
 			Channel: make(chan string),
 		}
+		recorder := record.NewFakeRecorder(100)
 		schedulerCache := &cache.SchedulerCache{
 			Nodes:         make(map[string]*api.NodeInfo),
 			Jobs:          make(map[api.JobID]*api.JobInfo),
@@ -119,7 +231,7 @@ func TestReclaim(t *testing.T) {
 			StatusUpdater: &util.FakeStatusUpdater{},
 			VolumeBinder:  &util.FakeVolumeBinder{},
 
-			Recorder: record.NewFakeRecorder(100),
+			Recorder: recorder,
 		}
 		for _, node := range test.nodes {
 			schedulerCache.AddNode(node)
@@ -166,5 +278,131 @@ func TestReclaim(t *testing.T) {
 		if test.expected != len(evictor.Evicts()) {
 			t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.expected, len(evictor.Evicts()))
 		}
+
+		skipped := 0
+		for len(recorder.Events) > 0 {
+			if strings.Contains(<-recorder.Events, "ReclaimSkipped") {
+				skipped++
+			}
+		}
+		if test.skipped != skipped {
+			t.Errorf("case %d (%s): expected skipped events: %v, got %v ", i, test.name, test.skipped, skipped)
+		}
+	}
+}
+
+// queueStatusRecorder records the status of queues updated by scheduler.
+type queueStatusRecorder struct {
+	util.FakeStatusUpdater
+	queues map[string]*scheduling.Queue
+}
+
+func (r *queueStatusRecorder) UpdateQueueStatus(queue *api.QueueInfo) error {
+	r.queues[queue.Name] = queue.Queue
+	return nil
+}
+
+func TestReclaimBudget(t *testing.T) {
+	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	evictor := &util.FakeEvictor{
+		Channel: make(chan string),
+	}
+	statusUpdater := &queueStatusRecorder{queues: map[string]*scheduling.Queue{}}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:  make(map[string]*api.NodeInfo),
+		Jobs:   make(map[api.JobID]*api.JobInfo),
+		Queues: make(map[api.QueueID]*api.QueueInfo),
+		Binder: &util.FakeBinder{
+			Binds:   map[string]string{},
+			Channel: make(chan string),
+		},
+		Evictor:       evictor,
+		StatusUpdater: statusUpdater,
+		VolumeBinder:  &util.FakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("5", "5Gi"), make(map[string]string)))
+	// Queue q2 is owed 5 cpu, but reclaims at most 2 cpu per cycle.
+	for _, name := range []string{"preemptee1", "preemptee2", "preemptee3", "preemptee4", "preemptee5"} {
+		schedulerCache.AddPod(util.BuildPod("c1", name, "n1", v1.PodRunning, util.BuildResourceList("1", "1G"), "pg1",
+			make(map[string]string), make(map[string]string)))
+	}
+	schedulerCache.AddPodGroupV1alpha2(&schedulingv2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       schedulingv2.PodGroupSpec{Queue: "q1"},
+	})
+	for i := 1; i <= 5; i++ {
+		pg := fmt.Sprintf("preemptor-pg%d", i)
+		schedulerCache.AddPod(util.BuildPod("c1", fmt.Sprintf("preemptor%d", i), "", v1.PodPending,
+			util.BuildResourceList("1", "1G"), pg, make(map[string]string), make(map[string]string)))
+		schedulerCache.AddPodGroupV1alpha2(&schedulingv2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: pg, Namespace: "c1"},
+			Spec:       schedulingv2.PodGroupSpec{Queue: "q2"},
+		})
+	}
+	schedulerCache.AddQueueV1alpha2(&schedulingv2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       schedulingv2.QueueSpec{Weight: 1},
+	})
+	schedulerCache.AddQueueV1alpha2(&schedulingv2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q2"},
+		Spec: schedulingv2.QueueSpec{
+			Weight:             1,
+			MaxReclaimPerCycle: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+		},
+	})
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:               "conformance",
+					EnabledReclaimable: &trueValue,
+				},
+				{
+					Name:               "gang",
+					EnabledReclaimable: &trueValue,
+				},
+			},
+		},
+	}
+
+	// The deficit is reclaimed over three cycles: 2, 2 and the last 1 cpu.
+	evicted := 0
+	for cycle, expected := range []int{2, 4, 5} {
+		ssn := framework.OpenSession(schedulerCache, tiers, nil)
+		New().Execute(ssn)
+		framework.CloseSession(ssn)
+
+		for evicted < expected {
+			select {
+			case <-evictor.Channel:
+				evicted++
+			case <-time.After(3 * time.Second):
+				t.Fatalf("cycle %d: expected %d evictions, got %d", cycle, expected, evicted)
+			}
+		}
+		select {
+		case <-evictor.Channel:
+			t.Fatalf("cycle %d: expected %d evictions, got more", cycle, expected)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	q2, found := statusUpdater.queues["q2"]
+	if !found {
+		t.Fatalf("expected reclaim budget of queue q2 in status")
+	}
+	remaining := q2.Status.ReclaimBudget[v1.ResourceCPU]
+	if remaining.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("expected 1 cpu of reclaim budget left in the last cycle, got %v", q2.Status.ReclaimBudget)
+	}
+	if _, found := statusUpdater.queues["q1"]; found {
+		t.Errorf("expected no reclaim budget of queue q1 without maxReclaimPerCycle")
 	}
 }
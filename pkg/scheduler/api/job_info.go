@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/scheduling"
 	"volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 )
@@ -136,6 +137,9 @@ type JobInfo struct {
 
 	MinAvailable int32
 
+	// The minimal available tasks of each task spec, in addition to MinAvailable.
+	TaskMinAvailable map[string]int32
+
 	NodesFitDelta NodeResourceMap
 
 	JobFitErrors   string
@@ -188,12 +192,34 @@ func (ji *JobInfo) SetPodGroup(pg *PodGroup) {
 	ji.Name = pg.Name
 	ji.Namespace = pg.Namespace
 	ji.MinAvailable = pg.Spec.MinMember
+	ji.TaskMinAvailable = pg.Spec.MinTaskMember
+	if GangDowngraded(pg) {
+		if ji.MinAvailable > 1 {
+			ji.MinAvailable = 1
+		}
+		ji.TaskMinAvailable = nil
+	}
 	ji.Queue = QueueID(pg.Spec.Queue)
 	ji.CreationTimestamp = pg.GetCreationTimestamp()
 
 	ji.PodGroup = pg
 }
 
+// GangDowngraded returns whether the SoftGang podgroup was downgraded by the podgroup controller
+// after its gang timeout, so that its members are scheduled best-effort.
+func GangDowngraded(pg *PodGroup) bool {
+	if pg.Spec.SchedulingPolicy != scheduling.SoftGangSchedulingPolicy {
+		return false
+	}
+	for _, c := range pg.Status.Conditions {
+		if c.Type == scheduling.PodGroupGangDowngradedType {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
 // SetPDB sets PDB to a job
 func (ji *JobInfo) SetPDB(pdb *policyv1.PodDisruptionBudget) {
 	ji.Name = pdb.Name
@@ -285,10 +311,11 @@ func (ji *JobInfo) Clone() *JobInfo {
 		Queue:     ji.Queue,
 		Priority:  ji.Priority,
 
-		MinAvailable:  ji.MinAvailable,
-		Allocated:     EmptyResource(),
-		TotalRequest:  EmptyResource(),
-		NodesFitDelta: make(NodeResourceMap),
+		MinAvailable:     ji.MinAvailable,
+		TaskMinAvailable: ji.TaskMinAvailable,
+		Allocated:        EmptyResource(),
+		TotalRequest:     EmptyResource(),
+		NodesFitDelta:    make(NodeResourceMap),
 
 		NodesFitErrors: make(map[TaskID]*FitErrors),
 
@@ -330,6 +357,9 @@ func (ji *JobInfo) FitError() string {
 		reasons[fmt.Sprintf("%s", status)] += len(taskMap)
 	}
 	reasons["minAvailable"] = int(ji.MinAvailable)
+	if task, _, min := ji.unmetTaskMinAvailable(readyStatus); task != "" {
+		reasons[fmt.Sprintf("minAvailable of task %s", task)] = int(min)
+	}
 
 	sortReasonsHistogram := func() []string {
 		reasonStrings := []string{}
@@ -386,13 +416,94 @@ func (ji *JobInfo) ValidTaskNum() int32 {
 // Ready returns whether job is ready for run
 func (ji *JobInfo) Ready() bool {
 	occupied := ji.ReadyTaskNum()
+	if occupied < ji.MinAvailable {
+		return false
+	}
 
-	return occupied >= ji.MinAvailable
+	task, _, _ := ji.unmetTaskMinAvailable(readyStatus)
+	return task == ""
 }
 
 // Pipelined returns whether the number of ready and pipelined task is enough
 func (ji *JobInfo) Pipelined() bool {
 	occupied := ji.WaitingTaskNum() + ji.ReadyTaskNum()
+	if occupied < ji.MinAvailable {
+		return false
+	}
+
+	task, _, _ := ji.unmetTaskMinAvailable(func(status TaskStatus) bool {
+		return readyStatus(status) || status == Pipelined
+	})
+	return task == ""
+}
+
+// CheckTaskMinAvailable returns the first task spec, in order of name, which has fewer valid tasks
+// than its minimal available, with its number of valid tasks and minimal available; the task spec
+// is empty if every task spec meets it.
+func (ji *JobInfo) CheckTaskMinAvailable() (string, int32, int32) {
+	return ji.unmetTaskMinAvailable(func(status TaskStatus) bool {
+		return readyStatus(status) || status == Pipelined || status == Pending
+	})
+}
+
+// TaskMinAvailableKept returns whether the task spec of the ready task still meets its minimal
+// available once the task is evicted.
+func (ji *JobInfo) TaskMinAvailableKept(ti *TaskInfo) bool {
+	spec := getTaskSpec(ti)
+	min, found := ji.TaskMinAvailable[spec]
+	if !found {
+		return true
+	}
+
+	return ji.taskNum(spec, readyStatus)-1 >= min
+}
+
+// readyStatus returns whether the task in the status counts as ready for the gang.
+func readyStatus(status TaskStatus) bool {
+	return AllocatedStatus(status) || status == Succeeded
+}
+
+// getTaskSpec returns the name of the task spec of the job which the task belongs to.
+func getTaskSpec(ti *TaskInfo) string {
+	if ti.Pod == nil {
+		return ""
+	}
+
+	return ti.Pod.Annotations[batch.TaskSpecKey]
+}
+
+// taskNum returns the number of tasks of the task spec in the matched statuses.
+func (ji *JobInfo) taskNum(spec string, match func(TaskStatus) bool) int32 {
+	var num int32
+	for status, tasks := range ji.TaskStatusIndex {
+		if !match(status) {
+			continue
+		}
+		for _, task := range tasks {
+			if getTaskSpec(task) == spec {
+				num++
+			}
+		}
+	}
+
+	return num
+}
+
+// unmetTaskMinAvailable returns the first task spec, in order of name, which has fewer tasks in the
+// matched statuses than its minimal available, with its number of tasks and minimal available.
+func (ji *JobInfo) unmetTaskMinAvailable(match func(TaskStatus) bool) (string, int32, int32) {
+	var specs []string
+	for spec := range ji.TaskMinAvailable {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	for _, spec := range specs {
+		min := ji.TaskMinAvailable[spec]
+		if num := ji.taskNum(spec, match); num < min {
+			return spec, num, min
+		}
+	}
 
-	return occupied >= ji.MinAvailable
+	return "", 0, 0
 }
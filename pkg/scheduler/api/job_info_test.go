@@ -24,6 +24,9 @@ import (
 	policyv1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	batch "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/scheduling"
 )
 
 func jobInfoEqual(l, r *JobInfo) bool {
@@ -221,3 +224,157 @@ func TestJobInfo_SetPDB(t *testing.T) {
 	}
 	info.SetPDB(pdb)
 }
+
+func TestJobInfo_SetPodGroup(t *testing.T) {
+	downgraded := []scheduling.PodGroupCondition{{
+		Type:   scheduling.PodGroupGangDowngradedType,
+		Status: v1.ConditionTrue,
+	}}
+
+	tests := []struct {
+		name       string
+		policy     scheduling.PodGroupSchedulingPolicy
+		conditions []scheduling.PodGroupCondition
+		expected   int32
+		tasks      map[string]int32
+	}{
+		{
+			name:     "gang podgroup",
+			policy:   scheduling.GangSchedulingPolicy,
+			expected: 3,
+			tasks:    map[string]int32{"worker": 2},
+		},
+		{
+			name:     "soft gang podgroup before timeout",
+			policy:   scheduling.SoftGangSchedulingPolicy,
+			expected: 3,
+			tasks:    map[string]int32{"worker": 2},
+		},
+		{
+			name:       "soft gang podgroup after timeout",
+			policy:     scheduling.SoftGangSchedulingPolicy,
+			conditions: downgraded,
+			expected:   1,
+		},
+		{
+			name:       "gang podgroup is never downgraded",
+			policy:     scheduling.GangSchedulingPolicy,
+			conditions: downgraded,
+			expected:   3,
+			tasks:      map[string]int32{"worker": 2},
+		},
+	}
+
+	for _, test := range tests {
+		pg := &PodGroup{
+			PodGroup: scheduling.PodGroup{
+				Spec: scheduling.PodGroupSpec{
+					MinMember:        3,
+					MinTaskMember:    map[string]int32{"worker": 2},
+					SchedulingPolicy: test.policy,
+				},
+				Status: scheduling.PodGroupStatus{
+					Conditions: test.conditions,
+				},
+			},
+		}
+
+		info := &JobInfo{}
+		info.SetPodGroup(pg)
+		if info.MinAvailable != test.expected {
+			t.Errorf("case %s: expected minAvailable %d, got %d", test.name, test.expected, info.MinAvailable)
+		}
+		if !reflect.DeepEqual(info.TaskMinAvailable, test.tasks) {
+			t.Errorf("case %s: expected minAvailable of tasks %v, got %v", test.name, test.tasks, info.TaskMinAvailable)
+		}
+	}
+}
+
+func TestJobInfo_TaskMinAvailable(t *testing.T) {
+	buildTask := func(name, spec string, phase v1.PodPhase) *TaskInfo {
+		pod := buildPod("c1", name, "", phase, nil, nil, nil)
+		pod.Annotations = map[string]string{batch.TaskSpecKey: spec}
+		return NewTaskInfo(pod)
+	}
+	pipelined := func(task *TaskInfo) *TaskInfo {
+		task.Status = Pipelined
+		return task
+	}
+
+	tests := []struct {
+		name          string
+		tasks         []*TaskInfo
+		ready         bool
+		pipelined     bool
+		invalidTask   string
+		evicted       int
+		evictionKeeps bool
+	}{
+		{
+			name: "job minAvailable met without ps",
+			tasks: []*TaskInfo{
+				buildTask("ps-0", "ps", v1.PodPending),
+				buildTask("worker-0", "worker", v1.PodRunning),
+				buildTask("worker-1", "worker", v1.PodRunning),
+			},
+			evicted:       1,
+			evictionKeeps: false,
+		},
+		{
+			name: "every task meets its minAvailable",
+			tasks: []*TaskInfo{
+				buildTask("ps-0", "ps", v1.PodRunning),
+				buildTask("worker-0", "worker", v1.PodRunning),
+				buildTask("worker-1", "worker", v1.PodSucceeded),
+				buildTask("worker-2", "worker", v1.PodRunning),
+			},
+			ready:         true,
+			pipelined:     true,
+			evicted:       3,
+			evictionKeeps: true,
+		},
+		{
+			name: "pipelined worker",
+			tasks: []*TaskInfo{
+				buildTask("ps-0", "ps", v1.PodRunning),
+				buildTask("worker-0", "worker", v1.PodRunning),
+				pipelined(buildTask("worker-1", "worker", v1.PodPending)),
+			},
+			pipelined:     true,
+			evicted:       0,
+			evictionKeeps: false,
+		},
+		{
+			name: "not enough workers",
+			tasks: []*TaskInfo{
+				buildTask("ps-0", "ps", v1.PodRunning),
+				buildTask("ps-1", "ps", v1.PodRunning),
+				buildTask("worker-0", "worker", v1.PodRunning),
+				buildTask("worker-1", "worker", v1.PodFailed),
+			},
+			invalidTask:   "worker",
+			evicted:       1,
+			evictionKeeps: true,
+		},
+	}
+
+	for _, test := range tests {
+		job := NewJobInfo("job1", test.tasks...)
+		job.MinAvailable = 2
+		job.TaskMinAvailable = map[string]int32{"ps": 1, "worker": 2}
+
+		if ready := job.Ready(); ready != test.ready {
+			t.Errorf("case %s: expected ready %t, got %t", test.name, test.ready, ready)
+		}
+		if pipelined := job.Pipelined(); pipelined != test.pipelined {
+			t.Errorf("case %s: expected pipelined %t, got %t", test.name, test.pipelined, pipelined)
+		}
+		if task, _, _ := job.CheckTaskMinAvailable(); task != test.invalidTask {
+			t.Errorf("case %s: expected task %q without enough valid tasks, got %q", test.name, test.invalidTask, task)
+		}
+		if keeps := job.TaskMinAvailableKept(test.tasks[test.evicted]); keeps != test.evictionKeeps {
+			t.Errorf("case %s: expected minAvailable kept %t after evicting %s, got %t",
+				test.name, test.evictionKeeps, test.tasks[test.evicted].Name, keeps)
+		}
+	}
+}
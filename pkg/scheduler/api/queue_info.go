@@ -17,6 +17,7 @@ limitations under the License.
 package api
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"volcano.sh/volcano/pkg/apis/scheduling"
@@ -31,17 +32,39 @@ type QueueInfo struct {
 	Name string
 
 	Weight int32
+	// Priority of queue, the queue with higher priority is scheduled first
+	Priority int32
+	// Reclaimable indicates whether resources of the queue can be reclaimed by other queues
+	Reclaimable bool
+	// Capability is the upper limit of resources of the queue, nil if it is not limited
+	Capability v1.ResourceList
 
 	Queue *scheduling.Queue
 }
 
 // NewQueueInfo creates new queueInfo object
 func NewQueueInfo(queue *scheduling.Queue) *QueueInfo {
+	// The effective weight is set by queue controller when dynamic weight is enabled.
+	weight := queue.Spec.Weight
+	if queue.Status.EffectiveWeight > 0 {
+		weight = queue.Status.EffectiveWeight
+	}
+
+	// The capability relative to the cluster is resolved by queue controller.
+	capability := queue.Spec.Capability
+	if len(queue.Status.Capability) > 0 {
+		capability = queue.Status.Capability
+	}
+
 	return &QueueInfo{
 		UID:  QueueID(queue.Name),
 		Name: queue.Name,
 
-		Weight: queue.Spec.Weight,
+		Weight:   weight,
+		Priority: queue.Spec.Priority,
+		// Queue is reclaimable unless it is disabled explicitly.
+		Reclaimable: queue.Spec.Reclaimable == nil || *queue.Spec.Reclaimable,
+		Capability:  capability,
 
 		Queue: queue,
 	}
@@ -50,9 +73,12 @@ func NewQueueInfo(queue *scheduling.Queue) *QueueInfo {
 // Clone is used to clone queueInfo object
 func (q *QueueInfo) Clone() *QueueInfo {
 	return &QueueInfo{
-		UID:    q.UID,
-		Name:   q.Name,
-		Weight: q.Weight,
-		Queue:  q.Queue,
+		UID:         q.UID,
+		Name:        q.Name,
+		Weight:      q.Weight,
+		Priority:    q.Priority,
+		Reclaimable: q.Reclaimable,
+		Capability:  q.Capability,
+		Queue:       q.Queue,
 	}
 }
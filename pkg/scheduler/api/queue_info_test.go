@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling"
+)
+
+func TestNewQueueInfoWeight(t *testing.T) {
+	testCases := []struct {
+		name            string
+		weight          int32
+		effectiveWeight int32
+		expected        int32
+	}{
+		{
+			name:     "spec.weight without effective weight",
+			weight:   4,
+			expected: 4,
+		},
+		{
+			name:            "effective weight overrides spec.weight",
+			weight:          4,
+			effectiveWeight: 6,
+			expected:        6,
+		},
+	}
+
+	for _, testCase := range testCases {
+		queue := &scheduling.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       scheduling.QueueSpec{Weight: testCase.weight},
+			Status:     scheduling.QueueStatus{EffectiveWeight: testCase.effectiveWeight},
+		}
+		if weight := NewQueueInfo(queue).Weight; weight != testCase.expected {
+			t.Errorf("case %s: expected weight %d, got %d", testCase.name, testCase.expected, weight)
+		}
+	}
+}
+
+func TestNewQueueInfoCapability(t *testing.T) {
+	specCapability := v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")}
+	statusCapability := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+
+	testCases := []struct {
+		name             string
+		specCapability   v1.ResourceList
+		statusCapability v1.ResourceList
+		expected         v1.ResourceList
+	}{
+		{
+			name:           "spec.capability without resolved capability",
+			specCapability: specCapability,
+			expected:       specCapability,
+		},
+		{
+			name:             "resolved capability overrides spec.capability",
+			specCapability:   specCapability,
+			statusCapability: statusCapability,
+			expected:         statusCapability,
+		},
+		{
+			name: "capability not set",
+		},
+	}
+
+	for _, testCase := range testCases {
+		queue := &scheduling.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+			Spec:       scheduling.QueueSpec{Capability: testCase.specCapability},
+			Status:     scheduling.QueueStatus{Capability: testCase.statusCapability},
+		}
+		if capability := NewQueueInfo(queue).Capability; !reflect.DeepEqual(capability, testCase.expected) {
+			t.Errorf("case %s: expected capability %v, got %v", testCase.name, testCase.expected, capability)
+		}
+	}
+}
@@ -356,6 +356,16 @@ func (r *Resource) Diff(rr *Resource) (*Resource, *Resource) {
 			decreasedVal.ScalarResources[rName] += rrQuant - rQuant
 		}
 	}
+	// The scalar resources which r does not have are decreased to zero.
+	for rrName, rrQuant := range rr.ScalarResources {
+		if _, found := r.ScalarResources[rrName]; found || rrQuant <= 0 {
+			continue
+		}
+		if decreasedVal.ScalarResources == nil {
+			decreasedVal.ScalarResources = map[v1.ResourceName]float64{}
+		}
+		decreasedVal.ScalarResources[rrName] += rrQuant
+	}
 
 	return increasedVal, decreasedVal
 }
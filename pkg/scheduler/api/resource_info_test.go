@@ -572,3 +572,51 @@ func TestLessEqualStrict(t *testing.T) {
 		}
 	}
 }
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name              string
+		resource1         *Resource
+		resource2         *Resource
+		expectedIncreased *Resource
+		expectedDecreased *Resource
+	}{
+		{
+			name: "both have the scalar resource",
+			resource1: &Resource{
+				MilliCPU:        3000,
+				ScalarResources: map[v1.ResourceName]float64{"example.com/fpga": 1000},
+			},
+			resource2: &Resource{
+				MilliCPU:        1000,
+				ScalarResources: map[v1.ResourceName]float64{"example.com/fpga": 3000},
+			},
+			expectedIncreased: &Resource{MilliCPU: 2000},
+			expectedDecreased: &Resource{
+				ScalarResources: map[v1.ResourceName]float64{"example.com/fpga": 2000},
+			},
+		},
+		{
+			name:      "former does not have the scalar resource",
+			resource1: &Resource{MilliCPU: 1000},
+			resource2: &Resource{
+				MilliCPU:        1000,
+				ScalarResources: map[v1.ResourceName]float64{"example.com/fpga": 3000},
+			},
+			expectedIncreased: &Resource{},
+			expectedDecreased: &Resource{
+				ScalarResources: map[v1.ResourceName]float64{"example.com/fpga": 3000},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		increased, decreased := test.resource1.Diff(test.resource2)
+		if !reflect.DeepEqual(test.expectedIncreased, increased) {
+			t.Errorf("case %s, expected increased: %#v, got: %#v", test.name, test.expectedIncreased, increased)
+		}
+		if !reflect.DeepEqual(test.expectedDecreased, decreased) {
+			t.Errorf("case %s, expected decreased: %#v, got: %#v", test.name, test.expectedDecreased, decreased)
+		}
+	}
+}
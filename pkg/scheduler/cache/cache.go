@@ -236,6 +236,22 @@ func (su *defaultStatusUpdater) UpdatePodGroup(pg *schedulingapi.PodGroup) (*sch
 	return nil, fmt.Errorf("invalid PodGroup version: %s", pg.Version)
 }
 
+// UpdateQueueStatus will update the status of queue
+func (su *defaultStatusUpdater) UpdateQueueStatus(queue *schedulingapi.QueueInfo) error {
+	newQueue := &v1alpha2.Queue{}
+	if err := schedulingscheme.Scheme.Convert(queue.Queue, newQueue, nil); err != nil {
+		klog.Errorf("Error while converting api.Queue to v1alpha2.Queue with error: %v", err)
+		return err
+	}
+
+	if _, err := su.vcclient.SchedulingV1alpha2().Queues().UpdateStatus(newQueue); err != nil {
+		klog.Errorf("Error while updating status of Queue %s with error: %v", newQueue.Name, err)
+		return err
+	}
+
+	return nil
+}
+
 type defaultVolumeBinder struct {
 	volumeBinder *volumebinder.VolumeBinder
 }
@@ -495,7 +511,7 @@ func (sc *SchedulerCache) findJobAndTask(taskInfo *schedulingapi.TaskInfo) (*sch
 }
 
 // Evict will evict the pod
-func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string) error {
+func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string, onEvicted ...func()) error {
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
@@ -527,6 +543,10 @@ func (sc *SchedulerCache) Evict(taskInfo *schedulingapi.TaskInfo, reason string)
 		err := sc.Evictor.Evict(p)
 		if err != nil {
 			sc.resyncTask(task)
+			return
+		}
+		for _, fn := range onEvicted {
+			fn()
 		}
 	}()
 
@@ -882,6 +902,16 @@ func (sc *SchedulerCache) UpdateJobStatus(job *schedulingapi.JobInfo, updatePG b
 	return job, nil
 }
 
+// UpdateQueueStatus update the status of queue.
+func (sc *SchedulerCache) UpdateQueueStatus(queue *schedulingapi.QueueInfo) error {
+	return sc.StatusUpdater.UpdateQueueStatus(queue)
+}
+
+// RecordPodGroupEvent records an event on the podgroup of the job.
+func (sc *SchedulerCache) RecordPodGroupEvent(job *schedulingapi.JobInfo, eventType, reason, msg string) {
+	sc.recordPodGroupEvent(job.PodGroup, eventType, reason, msg)
+}
+
 func (sc *SchedulerCache) recordPodGroupEvent(podGroup *schedulingapi.PodGroup, eventType, reason, msg string) {
 	if podGroup == nil {
 		return
@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 
+	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/scheduler/api"
 )
 
@@ -174,3 +178,67 @@ func TestGetOrCreateJob(t *testing.T) {
 		}
 	}
 }
+
+// fakeEvictor reports the evicted pods, and fails the evictions with err.
+type fakeEvictor struct {
+	err     error
+	evicted chan string
+}
+
+func (fe *fakeEvictor) Evict(p *v1.Pod) error {
+	fe.evicted <- p.Name
+	return fe.err
+}
+
+func TestEvictOnEvicted(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		expectEvicted bool
+	}{
+		{
+			name:          "called once evicted",
+			expectEvicted: true,
+		},
+		{
+			name: "not called if eviction failed",
+			err:  fmt.Errorf("failed to delete pod"),
+		},
+	}
+
+	for _, testcase := range testCases {
+		evictor := &fakeEvictor{err: testcase.err, evicted: make(chan string, 1)}
+		cache := &SchedulerCache{
+			Nodes:    make(map[string]*api.NodeInfo),
+			Jobs:     make(map[api.JobID]*api.JobInfo),
+			Evictor:  evictor,
+			Recorder: record.NewFakeRecorder(10),
+			errTasks: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		}
+		cache.AddNode(buildNode("n1", buildResourceList("2", "2G")))
+		pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1", "1G"), nil, make(map[string]string))
+		pod.Annotations = map[string]string{schedulingv2.GroupNameAnnotationKey: "pg1"}
+		cache.AddPod(pod)
+		cache.AddPodGroupV1alpha2(&schedulingv2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		})
+
+		evicted := make(chan struct{}, 1)
+		if err := cache.Evict(api.NewTaskInfo(pod), "test", func() { evicted <- struct{}{} }); err != nil {
+			t.Fatalf("case %s: evict failed: %v", testcase.name, err)
+		}
+		<-evictor.evicted
+
+		select {
+		case <-evicted:
+			if !testcase.expectEvicted {
+				t.Errorf("case %s: expected onEvicted not to be called", testcase.name)
+			}
+		case <-time.After(time.Second):
+			if testcase.expectEvicted {
+				t.Errorf("case %s: expected onEvicted to be called", testcase.name)
+			}
+		}
+		cache.errTasks.ShutDown()
+	}
+}
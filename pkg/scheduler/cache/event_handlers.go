@@ -33,6 +33,7 @@ import (
 	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
 	"volcano.sh/volcano/pkg/apis/utils"
 	schedulingapi "volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
 )
 
 func isTerminated(status schedulingapi.TaskStatus) bool {
@@ -858,6 +859,9 @@ func (sc *SchedulerCache) updateQueue(queue *scheduling.Queue) {
 }
 
 func (sc *SchedulerCache) deleteQueue(id schedulingapi.QueueID) {
+	if queue, found := sc.Queues[id]; found {
+		metrics.DeleteQueueMetrics(queue.Name)
+	}
 	delete(sc.Queues, id)
 }
 
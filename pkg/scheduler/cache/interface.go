@@ -38,13 +38,16 @@ type Cache interface {
 	// TODO(jinzhej): clean up expire Tasks.
 	Bind(task *api.TaskInfo, hostname string) error
 
-	// Evict evicts the task to release resources.
-	Evict(task *api.TaskInfo, reason string) error
+	// Evict evicts the task to release resources; onEvicted is called once the pod is evicted by apiserver.
+	Evict(task *api.TaskInfo, reason string, onEvicted ...func()) error
 
 	// RecordJobStatusEvent records related events according to job status.
 	// Deprecated: remove it after removed PDB support.
 	RecordJobStatusEvent(job *api.JobInfo)
 
+	// RecordPodGroupEvent records an event on the podgroup of the job.
+	RecordPodGroupEvent(job *api.JobInfo, eventType, reason, msg string)
+
 	// UpdateJobStatus puts job in backlog for a while.
 	UpdateJobStatus(job *api.JobInfo, updatePG bool) (*api.JobInfo, error)
 
@@ -53,6 +56,9 @@ type Cache interface {
 
 	// BindVolumes binds volumes to the task
 	BindVolumes(task *api.TaskInfo) error
+
+	// UpdateQueueStatus updates the status of queue.
+	UpdateQueueStatus(queue *api.QueueInfo) error
 }
 
 // VolumeBinder interface for allocate and bind volumes
@@ -75,4 +81,5 @@ type Evictor interface {
 type StatusUpdater interface {
 	UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error)
 	UpdatePodGroup(pg *api.PodGroup) (*api.PodGroup, error)
+	UpdateQueueStatus(queue *api.QueueInfo) error
 }
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+const (
+	// maxDumpRequests is the number of order dump requests waiting for the next session.
+	maxDumpRequests = 16
+	// minDumpTimeout is the least time to wait for the next session to serve an order dump request.
+	minDumpTimeout = 10 * time.Second
+)
+
+type dumpResult struct {
+	dump *framework.OrderDump
+	err  error
+}
+
+type dumpRequest struct {
+	job    api.JobID
+	result chan *dumpResult
+}
+
+// OrderDumpHandler returns the handler dumping the ordering of the next session for the podgroup
+// given by the "podgroup" query parameter as <namespace>/<name>. The ordering is taken right after
+// the session is opened, before any action runs; the session is not changed.
+func (pc *Scheduler) OrderDumpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		podGroup := r.URL.Query().Get("podgroup")
+		if parts := strings.Split(podGroup, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			http.Error(w, "podgroup must be given as <namespace>/<name>", http.StatusBadRequest)
+			return
+		}
+
+		req := &dumpRequest{
+			job:    api.JobID(podGroup),
+			result: make(chan *dumpResult, 1),
+		}
+		select {
+		case pc.dumpRequests <- req:
+		default:
+			http.Error(w, "too many order dump requests", http.StatusServiceUnavailable)
+			return
+		}
+
+		timeout := 2 * pc.schedulePeriod
+		if timeout < minDumpTimeout {
+			timeout = minDumpTimeout
+		}
+
+		select {
+		case result := <-req.result:
+			if result.err != nil {
+				http.Error(w, result.err.Error(), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(result.dump); err != nil {
+				klog.Errorf("Failed to write order dump of %s: %v", podGroup, err)
+			}
+		case <-time.After(timeout):
+			http.Error(w, fmt.Sprintf("no session is opened in %v", timeout), http.StatusGatewayTimeout)
+		case <-r.Context().Done():
+		}
+	})
+}
+
+// serveDumpRequests serves the waiting order dump requests from the session.
+func (pc *Scheduler) serveDumpRequests(ssn *framework.Session) {
+	for {
+		select {
+		case req := <-pc.dumpRequests:
+			dump, err := ssn.DumpOrder(req.job)
+			req.result <- &dumpResult{dump: dump, err: err}
+		default:
+			return
+		}
+	}
+}
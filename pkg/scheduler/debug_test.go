@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+// reversePlugin orders the jobs by name descending and reports the weight of queues as share.
+type reversePlugin struct {
+	ssn *framework.Session
+}
+
+func (rp *reversePlugin) Name() string {
+	return "reverse"
+}
+
+func (rp *reversePlugin) OnSessionOpen(ssn *framework.Session) {
+	rp.ssn = ssn
+	ssn.AddJobOrderFn(rp.Name(), func(l, r interface{}) int {
+		lv := l.(*api.JobInfo)
+		rv := r.(*api.JobInfo)
+		if lv.Name == rv.Name {
+			return 0
+		}
+		if lv.Name > rv.Name {
+			return -1
+		}
+		return 1
+	})
+}
+
+func (rp *reversePlugin) OnSessionClose(ssn *framework.Session) {}
+
+func (rp *reversePlugin) QueueShares() map[api.QueueID]float64 {
+	shares := map[api.QueueID]float64{}
+	for id, queue := range rp.ssn.Queues {
+		shares[id] = float64(queue.Weight)
+	}
+	return shares
+}
+
+func TestOrderDumpHandler(t *testing.T) {
+	framework.RegisterPluginBuilder("reverse", func(framework.Arguments) framework.Plugin {
+		return &reversePlugin{}
+	})
+	defer framework.CleanupPluginBuilders()
+
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("2", "4Gi"), make(map[string]string)))
+	schedulerCache.AddQueueV1alpha2(&schedulingv2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       schedulingv2.QueueSpec{Weight: 2},
+	})
+	for _, name := range []string{"pg1", "pg2", "pg3"} {
+		schedulerCache.AddPodGroupV1alpha2(&schedulingv2.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+			Spec:       schedulingv2.PodGroupSpec{MinMember: 1, Queue: "q1"},
+		})
+		schedulerCache.AddPod(util.BuildPod("c1", name+"-p1", "", v1.PodPending, util.BuildResourceList("1", "1G"),
+			name, make(map[string]string), make(map[string]string)))
+	}
+
+	trueValue := true
+	tiers := []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:            "reverse",
+					EnabledJobOrder: &trueValue,
+				},
+			},
+		},
+	}
+
+	pc := &Scheduler{
+		schedulePeriod: time.Second,
+		dumpRequests:   make(chan *dumpRequest, maxDumpRequests),
+	}
+	handler := pc.OrderDumpHandler()
+
+	// The request is served once a session is opened.
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/ordering?podgroup=c1/pg2", nil))
+		close(done)
+	}()
+
+	ssn := framework.OpenSession(schedulerCache, tiers, nil)
+	defer framework.CloseSession(ssn)
+	for served := false; !served; {
+		select {
+		case <-done:
+			served = true
+		case <-time.After(10 * time.Millisecond):
+			pc.serveDumpRequests(ssn)
+		}
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	dump := &framework.OrderDump{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), dump); err != nil {
+		t.Fatalf("failed to decode dump: %v", err)
+	}
+
+	expectedJobs := []framework.JobOrder{
+		{Job: "c1/pg3", MinAvailable: 1, Pending: 1, DecidedBy: "reverse"},
+		{Job: "c1/pg2", MinAvailable: 1, Pending: 1},
+		{Job: "c1/pg1", MinAvailable: 1, Pending: 1},
+	}
+	if !reflect.DeepEqual(dump.Jobs, expectedJobs) {
+		t.Errorf("expected jobs %+v, got %+v", expectedJobs, dump.Jobs)
+	}
+	expectedQueues := []framework.QueueOrder{
+		{Name: "q1", Shares: map[string]float64{"reverse": 2}},
+	}
+	if !reflect.DeepEqual(dump.Queues, expectedQueues) {
+		t.Errorf("expected queues %+v, got %+v", expectedQueues, dump.Queues)
+	}
+	if !reflect.DeepEqual(dump.Tasks, []string{"c1/pg2-p1"}) {
+		t.Errorf("expected tasks [c1/pg2-p1], got %v", dump.Tasks)
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		code   int
+	}{
+		{
+			name:   "invalid podgroup",
+			method: http.MethodGet,
+			url:    "/debug/ordering?podgroup=pg1",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "read only",
+			method: http.MethodPost,
+			url:    "/debug/ordering?podgroup=c1/pg1",
+			code:   http.StatusMethodNotAllowed,
+		},
+	}
+	for _, testCase := range testCases {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(testCase.method, testCase.url, nil))
+		if recorder.Code != testCase.code {
+			t.Errorf("case %s: expected status %d, got %d", testCase.name, testCase.code, recorder.Code)
+		}
+	}
+
+	if _, err := ssn.DumpOrder("c1/missing"); err == nil {
+		t.Errorf("expected error for missing job")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+// defaultOrder is reported as the decider of two jobs which no plugin orders.
+const defaultOrder = "creationTimestamp"
+
+// QueueShareReporter is implemented by the plugins computing the share of queues,
+// the shares are reported by the order dump of the session.
+type QueueShareReporter interface {
+	QueueShares() map[api.QueueID]float64
+}
+
+// OrderDump describes the ordering the plugins of a session produced for a job.
+type OrderDump struct {
+	Session string `json:"session"`
+	Job     string `json:"job"`
+	Queue   string `json:"queue"`
+	// Queues are all queues in the order they are considered.
+	Queues []QueueOrder `json:"queues"`
+	// Jobs are the jobs of the queue of the job in the order they are considered.
+	Jobs []JobOrder `json:"jobs"`
+	// Tasks are the pending tasks of the job in the order they are allocated.
+	Tasks []string `json:"tasks"`
+}
+
+// QueueOrder describes a queue in the order dump.
+type QueueOrder struct {
+	Name string `json:"name"`
+	// Shares are the shares of the queue by plugin name.
+	Shares map[string]float64 `json:"shares,omitempty"`
+}
+
+// JobOrder describes a job in the order dump.
+type JobOrder struct {
+	Job          string `json:"job"`
+	Priority     int32  `json:"priority"`
+	MinAvailable int32  `json:"minAvailable"`
+	Ready        int32  `json:"ready"`
+	Pending      int32  `json:"pending"`
+	// DecidedBy is the plugin which ordered the job ahead of the dumped job.
+	DecidedBy string `json:"decidedBy,omitempty"`
+}
+
+// DumpOrder returns the ordering of queues, jobs and tasks of the session for the job, and
+// which plugin put every job ahead of it; it does not change the session.
+func (ssn *Session) DumpOrder(jobID api.JobID) (*OrderDump, error) {
+	job, found := ssn.Jobs[jobID]
+	if !found {
+		return nil, fmt.Errorf("job %s is not found in session %s", jobID, ssn.UID)
+	}
+
+	dump := &OrderDump{
+		Session: string(ssn.UID),
+		Job:     string(jobID),
+		Queue:   string(job.Queue),
+	}
+
+	shares := map[api.QueueID]map[string]float64{}
+	for name, plugin := range ssn.plugins {
+		reporter, ok := plugin.(QueueShareReporter)
+		if !ok {
+			continue
+		}
+		for queue, share := range reporter.QueueShares() {
+			if shares[queue] == nil {
+				shares[queue] = map[string]float64{}
+			}
+			shares[queue][name] = share
+		}
+	}
+
+	var queues []*api.QueueInfo
+	for _, queue := range ssn.Queues {
+		queues = append(queues, queue)
+	}
+	sort.Slice(queues, func(i, j int) bool {
+		return ssn.QueueOrderFn(queues[i], queues[j])
+	})
+	for _, queue := range queues {
+		dump.Queues = append(dump.Queues, QueueOrder{
+			Name:   queue.Name,
+			Shares: shares[queue.UID],
+		})
+	}
+
+	var jobs []*api.JobInfo
+	for _, j := range ssn.Jobs {
+		if j.Queue == job.Queue {
+			jobs = append(jobs, j)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return ssn.JobOrderFn(jobs[i], jobs[j])
+	})
+	ahead := true
+	for _, j := range jobs {
+		order := JobOrder{
+			Job:          string(j.UID),
+			Priority:     j.Priority,
+			MinAvailable: j.MinAvailable,
+			Ready:        j.ReadyTaskNum(),
+			Pending:      int32(len(j.TaskStatusIndex[api.Pending])),
+		}
+		if j.UID == job.UID {
+			ahead = false
+		} else if ahead {
+			order.DecidedBy = ssn.jobOrderDecider(j, job)
+		}
+		dump.Jobs = append(dump.Jobs, order)
+	}
+
+	var tasks []*api.TaskInfo
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return ssn.TaskOrderFn(tasks[i], tasks[j])
+	})
+	for _, task := range tasks {
+		dump.Tasks = append(dump.Tasks, fmt.Sprintf("%s/%s", task.Namespace, task.Name))
+	}
+
+	return dump, nil
+}
+
+// jobOrderDecider returns the name of the plugin which orders l ahead of r, as JobOrderFn does.
+func (ssn *Session) jobOrderDecider(l, r *api.JobInfo) string {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			if !isEnabled(plugin.EnabledJobOrder) {
+				continue
+			}
+			jof, found := ssn.jobOrderFns[plugin.Name]
+			if !found {
+				continue
+			}
+			if j := jof(l, r); j != 0 {
+				return plugin.Name
+			}
+		}
+	}
+
+	if l.CreationTimestamp.Equal(&r.CreationTimestamp) {
+		if name, j := ssn.jobTieBreak(l, r); j != 0 {
+			return name
+		}
+	}
+
+	return defaultOrder
+}
@@ -61,6 +61,10 @@ func isPodGroupConditionsUpdated(newCondition, oldCondition []scheduling.PodGrou
 
 		newTime := newCond.LastTransitionTime
 		oldTime := oldCond.LastTransitionTime
+		// The preempted time starts the cooldown of the podgroup, so it is always updated.
+		if newCond.Type == scheduling.PodGroupPreemptedType && !newTime.Equal(&oldTime) {
+			return true
+		}
 		if TimeJitterAfter(newTime.Time, oldTime.Time, jobConditionUpdateTime, jobConditionUpdateTimeJitter) {
 			return true
 		}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/scheduling"
+)
+
+func TestIsPodGroupConditionsUpdated(t *testing.T) {
+	now := time.Now()
+	condition := func(conditionType scheduling.PodGroupConditionType, t time.Time) []scheduling.PodGroupCondition {
+		return []scheduling.PodGroupCondition{{
+			Type:               conditionType,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(t),
+		}}
+	}
+
+	cases := []struct {
+		name         string
+		newCondition []scheduling.PodGroupCondition
+		oldCondition []scheduling.PodGroupCondition
+		expected     bool
+	}{
+		{
+			name:         "unschedulable condition within update time",
+			newCondition: condition(scheduling.PodGroupUnschedulableType, now.Add(10*time.Second)),
+			oldCondition: condition(scheduling.PodGroupUnschedulableType, now),
+			expected:     false,
+		},
+		{
+			name:         "preempted condition within update time",
+			newCondition: condition(scheduling.PodGroupPreemptedType, now.Add(10*time.Second)),
+			oldCondition: condition(scheduling.PodGroupPreemptedType, now),
+			expected:     true,
+		},
+		{
+			name:         "preempted condition not changed",
+			newCondition: condition(scheduling.PodGroupPreemptedType, now),
+			oldCondition: condition(scheduling.PodGroupPreemptedType, now),
+			expected:     false,
+		},
+	}
+
+	for _, c := range cases {
+		if got := isPodGroupConditionsUpdated(c.newCondition, c.oldCondition); got != c.expected {
+			t.Errorf("case %s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
@@ -52,6 +52,7 @@ type Session struct {
 	plugins           map[string]Plugin
 	eventHandlers     []*EventHandler
 	jobOrderFns       map[string]api.CompareFn
+	jobTieBreakFns    map[string]api.CompareFn
 	queueOrderFns     map[string]api.CompareFn
 	taskOrderFns      map[string]api.CompareFn
 	namespaceOrderFns map[string]api.CompareFn
@@ -82,6 +83,7 @@ func openSession(cache cache.Cache) *Session {
 
 		plugins:           map[string]Plugin{},
 		jobOrderFns:       map[string]api.CompareFn{},
+		jobTieBreakFns:    map[string]api.CompareFn{},
 		queueOrderFns:     map[string]api.CompareFn{},
 		taskOrderFns:      map[string]api.CompareFn{},
 		namespaceOrderFns: map[string]api.CompareFn{},
@@ -148,6 +150,7 @@ func closeSession(ssn *Session) {
 	ssn.plugins = nil
 	ssn.eventHandlers = nil
 	ssn.jobOrderFns = nil
+	ssn.jobTieBreakFns = nil
 	ssn.namespaceOrderFns = nil
 	ssn.queueOrderFns = nil
 
@@ -168,19 +171,17 @@ func jobStatus(ssn *Session, jobInfo *api.JobInfo) scheduling.PodGroupStatus {
 		}
 	}
 
-	// If running tasks && unschedulable, unknown phase
-	if len(jobInfo.TaskStatusIndex[api.Running]) != 0 && unschedulable {
+	// The podgroup is completed once all of its pods have succeeded, it is pending again if new pods
+	// are created, e.g. the job is restarted.
+	succeeded := len(jobInfo.TaskStatusIndex[api.Succeeded])
+	if succeeded != 0 && succeeded == len(jobInfo.Tasks) && int32(succeeded) >= jobInfo.MinAvailable {
+		status.Phase = scheduling.PodGroupCompleted
+	} else if len(jobInfo.TaskStatusIndex[api.Running]) != 0 && unschedulable {
+		// If running tasks && unschedulable, unknown phase
 		status.Phase = scheduling.PodGroupUnknown
 	} else {
-		allocated := 0
-		for status, tasks := range jobInfo.TaskStatusIndex {
-			if api.AllocatedStatus(status) || status == api.Succeeded {
-				allocated += len(tasks)
-			}
-		}
-
 		// If there're enough allocated resource, it's running
-		if int32(allocated) >= jobInfo.PodGroup.Spec.MinMember {
+		if jobInfo.Ready() {
 			status.Phase = scheduling.PodGroupRunning
 		} else if jobInfo.PodGroup.Status.Phase != scheduling.PodGroupInqueue {
 			status.Phase = scheduling.PodGroupPending
@@ -328,9 +329,9 @@ func (ssn *Session) dispatch(task *api.TaskInfo) error {
 	return nil
 }
 
-//Evict the task in the session
-func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string) error {
-	if err := ssn.cache.Evict(reclaimee, reason); err != nil {
+//Evict the task in the session, onEvicted is called once the pod is evicted by apiserver
+func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string, onEvicted ...func()) error {
+	if err := ssn.cache.Evict(reclaimee, reason, onEvicted...); err != nil {
 		return err
 	}
 
@@ -393,6 +394,16 @@ func (ssn *Session) UpdateJobCondition(jobInfo *api.JobInfo, cond *scheduling.Po
 	return nil
 }
 
+// UpdateQueueStatus updates the status of queue, e.g. the unmet guarantee of queue.
+func (ssn *Session) UpdateQueueStatus(queue *api.QueueInfo) error {
+	return ssn.cache.UpdateQueueStatus(queue)
+}
+
+// RecordPodGroupEvent records an event on the podgroup of the job, e.g. the podgroup reclaims resources of others.
+func (ssn *Session) RecordPodGroupEvent(job *api.JobInfo, eventType, reason, msg string) {
+	ssn.cache.RecordPodGroupEvent(job, eventType, reason, msg)
+}
+
 // AddEventHandler add event handlers
 func (ssn *Session) AddEventHandler(eh *EventHandler) {
 	ssn.eventHandlers = append(ssn.eventHandlers, eh)
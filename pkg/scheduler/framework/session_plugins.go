@@ -27,6 +27,12 @@ func (ssn *Session) AddJobOrderFn(name string, cf api.CompareFn) {
 	ssn.jobOrderFns[name] = cf
 }
 
+// AddJobTieBreakFn add job tie-break function, it orders the jobs which no job order function
+// and the creation time order
+func (ssn *Session) AddJobTieBreakFn(name string, cf api.CompareFn) {
+	ssn.jobTieBreakFns[name] = cf
+}
+
 // AddQueueOrderFn add queue order function
 func (ssn *Session) AddQueueOrderFn(name string, qf api.CompareFn) {
 	ssn.queueOrderFns[name] = qf
@@ -300,16 +306,40 @@ func (ssn *Session) JobOrderFn(l, r interface{}) bool {
 		}
 	}
 
-	// If no job order funcs, order job by CreationTimestamp first, then by UID.
+	// If no job order funcs, order job by CreationTimestamp first, then by tie-break funcs and UID.
 	lv := l.(*api.JobInfo)
 	rv := r.(*api.JobInfo)
 	if lv.CreationTimestamp.Equal(&rv.CreationTimestamp) {
+		if _, j := ssn.jobTieBreak(lv, rv); j != 0 {
+			return j < 0
+		}
 		return lv.UID < rv.UID
 	}
 	return lv.CreationTimestamp.Before(&rv.CreationTimestamp)
 
 }
 
+// jobTieBreak invokes the job tie-break functions of the plugins with job order enabled,
+// it returns the plugin which orders the jobs and its result.
+func (ssn *Session) jobTieBreak(l, r *api.JobInfo) (string, int) {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			if !isEnabled(plugin.EnabledJobOrder) {
+				continue
+			}
+			tbf, found := ssn.jobTieBreakFns[plugin.Name]
+			if !found {
+				continue
+			}
+			if j := tbf(l, r); j != 0 {
+				return plugin.Name, j
+			}
+		}
+	}
+
+	return "", 0
+}
+
 // NamespaceOrderFn invoke namespaceorder function of the plugins
 func (ssn *Session) NamespaceOrderFn(l, r interface{}) bool {
 	for _, tier := range ssn.Tiers {
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"volcano.sh/volcano/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+)
+
+func TestJobStatusCompleted(t *testing.T) {
+	newJob := func(phase scheduling.PodGroupPhase, podPhases ...v1.PodPhase) *api.JobInfo {
+		job := api.NewJobInfo("ns/pg")
+		for i, podPhase := range podPhases {
+			job.AddTaskInfo(api.NewTaskInfo(&v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("p%d", i), UID: types.UID(fmt.Sprintf("p%d", i))},
+				Status:     v1.PodStatus{Phase: podPhase},
+			}))
+		}
+		job.SetPodGroup(&api.PodGroup{PodGroup: scheduling.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg"},
+			Spec:       scheduling.PodGroupSpec{MinMember: 2},
+			Status:     scheduling.PodGroupStatus{Phase: phase},
+		}})
+		return job
+	}
+
+	cases := []struct {
+		name     string
+		job      *api.JobInfo
+		expected scheduling.PodGroupPhase
+	}{
+		{
+			name:     "all pods succeeded",
+			job:      newJob(scheduling.PodGroupRunning, v1.PodSucceeded, v1.PodSucceeded),
+			expected: scheduling.PodGroupCompleted,
+		},
+		{
+			name:     "pods succeeded with a pending pod",
+			job:      newJob(scheduling.PodGroupRunning, v1.PodSucceeded, v1.PodSucceeded, v1.PodPending),
+			expected: scheduling.PodGroupRunning,
+		},
+		{
+			name:     "less than minMember pods succeeded",
+			job:      newJob(scheduling.PodGroupInqueue, v1.PodSucceeded),
+			expected: scheduling.PodGroupInqueue,
+		},
+		{
+			name:     "completed podgroup with new pods",
+			job:      newJob(scheduling.PodGroupCompleted, v1.PodPending, v1.PodPending),
+			expected: scheduling.PodGroupPending,
+		},
+	}
+
+	ssn := &Session{UID: "ssn"}
+	for _, c := range cases {
+		if status := jobStatus(ssn, c.job); status.Phase != c.expected {
+			t.Errorf("case %s: expected phase %s, got %s", c.name, c.expected, status.Phase)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package metrics
 
 import (
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -118,8 +119,85 @@ var (
 			Help:      "Number of retry counts for one job",
 		}, []string{"job_id"},
 	)
+
+	deadlockDetected = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "total_deadlocks_detected",
+			Help:      "Total scheduling deadlocks between queues detected in the cluster till now",
+		},
+	)
+
+	queueAllocatedMilliCPU = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_allocated_milli_cpu",
+			Help:      "Allocated CPU count for one queue",
+		}, []string{"queue_name"},
+	)
+
+	queueAllocatedMemory = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_allocated_memory_bytes",
+			Help:      "Allocated memory for one queue",
+		}, []string{"queue_name"},
+	)
+
+	queueAllocatedScalarResources = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_allocated_scalar_resources",
+			Help:      "Allocated scalar resources, e.g. nvidia.com/gpu, for one queue",
+		}, []string{"queue_name", "resource"},
+	)
+
+	queueCapabilityMilliCPU = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_capability_milli_cpu",
+			Help:      "CPU capability for one queue, not reported if the queue has no capability",
+		}, []string{"queue_name"},
+	)
+
+	queueCapabilityMemory = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_capability_memory_bytes",
+			Help:      "Memory capability for one queue, not reported if the queue has no capability",
+		}, []string{"queue_name"},
+	)
+
+	queueCapabilityScalarResources = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_capability_scalar_resources",
+			Help:      "Scalar resources capability, e.g. nvidia.com/gpu, for one queue",
+		}, []string{"queue_name", "resource"},
+	)
+
+	queueShare = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: VolcanoNamespace,
+			Name:      "queue_share",
+			Help:      "Share of one queue, i.e. the max ratio of its allocated to deserved resources",
+		}, []string{"queue_name"},
+	)
+
+	// queueScalarMutex guards queueScalars.
+	queueScalarMutex sync.Mutex
+	// queueScalars are the scalar resources reported for every queue, so that they are deleted together with the queue.
+	queueScalars = map[string]map[string]struct{}{}
 )
 
+// QueueResources are the resources of a queue reported in the metrics.
+type QueueResources struct {
+	MilliCPU float64
+	Memory   float64
+	// Scalars are the scalar resources by name, e.g. nvidia.com/gpu
+	Scalars map[string]float64
+}
+
 // UpdatePluginDuration updates latency for every plugin
 func UpdatePluginDuration(pluginName, OnSessionStatus string, duration time.Duration) {
 	pluginSchedulingLatency.WithLabelValues(pluginName, OnSessionStatus).Observe(DurationInMicroseconds(duration))
@@ -170,6 +248,90 @@ func RegisterJobRetries(jobID string) {
 	jobRetryCount.WithLabelValues(jobID).Inc()
 }
 
+// RegisterDeadlockDetected records number of detected scheduling deadlocks
+func RegisterDeadlockDetected() {
+	deadlockDetected.Inc()
+}
+
+// UpdateQueueAllocated records the resources allocated to the queue
+func UpdateQueueAllocated(queueName string, allocated QueueResources) {
+	queueAllocatedMilliCPU.WithLabelValues(queueName).Set(allocated.MilliCPU)
+	queueAllocatedMemory.WithLabelValues(queueName).Set(allocated.Memory)
+	for name, value := range allocated.Scalars {
+		queueAllocatedScalarResources.WithLabelValues(queueName, name).Set(value)
+	}
+	for _, name := range recordQueueScalars(queueName, allocated.Scalars) {
+		queueAllocatedScalarResources.WithLabelValues(queueName, name).Set(0)
+	}
+}
+
+// UpdateQueueCapability records the capability of the queue, nil means the queue has no capability
+func UpdateQueueCapability(queueName string, capability *QueueResources) {
+	if capability == nil {
+		queueCapabilityMilliCPU.DeleteLabelValues(queueName)
+		queueCapabilityMemory.DeleteLabelValues(queueName)
+		capability = &QueueResources{}
+	} else {
+		queueCapabilityMilliCPU.WithLabelValues(queueName).Set(capability.MilliCPU)
+		queueCapabilityMemory.WithLabelValues(queueName).Set(capability.Memory)
+	}
+
+	for name, value := range capability.Scalars {
+		queueCapabilityScalarResources.WithLabelValues(queueName, name).Set(value)
+	}
+	for _, name := range recordQueueScalars(queueName, capability.Scalars) {
+		queueCapabilityScalarResources.DeleteLabelValues(queueName, name)
+	}
+}
+
+// UpdateQueueShare records the share of the queue
+func UpdateQueueShare(queueName string, share float64) {
+	queueShare.WithLabelValues(queueName).Set(share)
+}
+
+// DeleteQueueMetrics deletes all the metrics of the queue, e.g. the queue is deleted
+func DeleteQueueMetrics(queueName string) {
+	queueAllocatedMilliCPU.DeleteLabelValues(queueName)
+	queueAllocatedMemory.DeleteLabelValues(queueName)
+	queueCapabilityMilliCPU.DeleteLabelValues(queueName)
+	queueCapabilityMemory.DeleteLabelValues(queueName)
+	queueShare.DeleteLabelValues(queueName)
+
+	queueScalarMutex.Lock()
+	defer queueScalarMutex.Unlock()
+	for name := range queueScalars[queueName] {
+		queueAllocatedScalarResources.DeleteLabelValues(queueName, name)
+		queueCapabilityScalarResources.DeleteLabelValues(queueName, name)
+	}
+	delete(queueScalars, queueName)
+}
+
+// recordQueueScalars records the scalar resources reported for the queue,
+// and returns the ones reported before but missing in scalars.
+func recordQueueScalars(queueName string, scalars map[string]float64) []string {
+	queueScalarMutex.Lock()
+	defer queueScalarMutex.Unlock()
+
+	var missing []string
+	for name := range queueScalars[queueName] {
+		if _, found := scalars[name]; !found {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(scalars) == 0 {
+		return missing
+	}
+	if queueScalars[queueName] == nil {
+		queueScalars[queueName] = map[string]struct{}{}
+	}
+	for name := range scalars {
+		queueScalars[queueName][name] = struct{}{}
+	}
+
+	return missing
+}
+
 // DurationInMicroseconds gets the time in microseconds.
 func DurationInMicroseconds(duration time.Duration) float64 {
 	return float64(duration.Nanoseconds()) / float64(time.Microsecond.Nanoseconds())
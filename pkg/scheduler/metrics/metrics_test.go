@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func gaugeValue(t *testing.T, vec *prometheus.GaugeVec, labels ...string) float64 {
+	m := &dto.Metric{}
+	if err := vec.WithLabelValues(labels...).Write(m); err != nil {
+		t.Fatalf("failed to read gauge %v: %v", labels, err)
+	}
+
+	return m.GetGauge().GetValue()
+}
+
+func TestQueueMetrics(t *testing.T) {
+	UpdateQueueAllocated("q1", QueueResources{
+		MilliCPU: 2000,
+		Memory:   1024,
+		Scalars:  map[string]float64{"nvidia.com/gpu": 2000},
+	})
+	UpdateQueueCapability("q1", &QueueResources{
+		MilliCPU: 4000,
+		Memory:   2048,
+		Scalars:  map[string]float64{"nvidia.com/gpu": 4000},
+	})
+	UpdateQueueShare("q1", 0.5)
+
+	if v := gaugeValue(t, queueAllocatedMilliCPU, "q1"); v != 2000 {
+		t.Errorf("expected allocated cpu 2000, got %v", v)
+	}
+	if v := gaugeValue(t, queueCapabilityMemory, "q1"); v != 2048 {
+		t.Errorf("expected memory capability 2048, got %v", v)
+	}
+	if v := gaugeValue(t, queueShare, "q1"); v != 0.5 {
+		t.Errorf("expected share 0.5, got %v", v)
+	}
+
+	// The gpus are released and the capability is removed.
+	UpdateQueueAllocated("q1", QueueResources{MilliCPU: 1000})
+	UpdateQueueCapability("q1", nil)
+
+	if v := gaugeValue(t, queueAllocatedScalarResources, "q1", "nvidia.com/gpu"); v != 0 {
+		t.Errorf("expected allocated gpu 0, got %v", v)
+	}
+	if queueCapabilityMilliCPU.DeleteLabelValues("q1") {
+		t.Errorf("expected cpu capability to be removed")
+	}
+	if queueCapabilityScalarResources.DeleteLabelValues("q1", "nvidia.com/gpu") {
+		t.Errorf("expected gpu capability to be removed")
+	}
+
+	DeleteQueueMetrics("q1")
+	if queueAllocatedMilliCPU.DeleteLabelValues("q1") || queueShare.DeleteLabelValues("q1") ||
+		queueAllocatedScalarResources.DeleteLabelValues("q1", "nvidia.com/gpu") {
+		t.Errorf("expected metrics of deleted queue to be removed")
+	}
+	if _, found := queueScalars["q1"]; found {
+		t.Errorf("expected scalar resources of deleted queue to be forgotten")
+	}
+}
@@ -67,6 +67,14 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 					vtn, job.MinAvailable),
 			}
 		}
+		if task, valid, min := job.CheckTaskMinAvailable(); task != "" {
+			return &api.ValidateResult{
+				Pass:   false,
+				Reason: v1alpha1.NotEnoughPodsReason,
+				Message: fmt.Sprintf("Not enough valid tasks of task %s for gang-scheduling, valid: %d, min: %d",
+					task, valid, min),
+			}
+		}
 		return nil
 	}
 
@@ -78,7 +86,8 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 		for _, preemptee := range preemptees {
 			job := ssn.Jobs[preemptee.Job]
 			occupid := job.ReadyTaskNum()
-			preemptable := job.MinAvailable <= occupid-1 || job.MinAvailable == 1
+			preemptable := (job.MinAvailable <= occupid-1 || job.MinAvailable == 1) &&
+				job.TaskMinAvailableKept(preemptee)
 
 			if !preemptable {
 				klog.V(4).Infof("Can not preempt task <%v/%v> because of gang-scheduling",
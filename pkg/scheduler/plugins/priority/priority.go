@@ -17,6 +17,10 @@ limitations under the License.
 package priority
 
 import (
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/scheduler/api"
@@ -26,6 +30,13 @@ import (
 // PluginName indicates name of volcano scheduler plugin.
 const PluginName = "priority"
 
+const (
+	// RandomTieBreak is the key for enabling the random order of the jobs with the same priority
+	// and creation time, which are otherwise ordered by UID, so that none of them is always
+	// considered last under contention
+	RandomTieBreak = "priority.randomTieBreak"
+)
+
 type priorityPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments framework.Arguments
@@ -82,6 +93,26 @@ func (pp *priorityPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	ssn.AddJobOrderFn(pp.Name(), jobOrderFn)
 
+	randomTieBreak := false
+	pp.pluginArguments.GetBool(&randomTieBreak, RandomTieBreak)
+	if randomTieBreak {
+		// The seed changes every session, while the order is consistent within the session.
+		seed := strconv.FormatInt(rand.Int63(), 10)
+		jobTieBreakFn := func(l, r interface{}) int {
+			lk := tieBreakKey(seed, l.(*api.JobInfo))
+			rk := tieBreakKey(seed, r.(*api.JobInfo))
+			if lk < rk {
+				return -1
+			}
+			if lk > rk {
+				return 1
+			}
+			return 0
+		}
+
+		ssn.AddJobTieBreakFn(pp.Name(), jobTieBreakFn)
+	}
+
 	preemptableFn := func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) []*api.TaskInfo {
 		preemptorJob := ssn.Jobs[preemptor.Job]
 
@@ -105,3 +136,12 @@ func (pp *priorityPlugin) OnSessionOpen(ssn *framework.Session) {
 }
 
 func (pp *priorityPlugin) OnSessionClose(ssn *framework.Session) {}
+
+// tieBreakKey returns the random key of the job in the session with the seed,
+// every job is equally likely to have the lowest key.
+func tieBreakKey(seed string, job *api.JobInfo) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	h.Write([]byte(job.UID))
+	return h.Sum64()
+}
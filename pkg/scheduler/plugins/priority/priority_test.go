@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+func TestRandomTieBreak(t *testing.T) {
+	framework.RegisterPluginBuilder(PluginName, New)
+	defer framework.CleanupPluginBuilders()
+
+	created := metav1.Now()
+	newJob := func(uid string, priority int32) *api.JobInfo {
+		job := api.NewJobInfo(api.JobID(uid))
+		job.Priority = priority
+		job.CreationTimestamp = created
+		return job
+	}
+
+	const sessions = 2000
+
+	tests := []struct {
+		name      string
+		arguments framework.Arguments
+		l, r      *api.JobInfo
+		// minFirst and maxFirst bound the number of sessions l is ordered first.
+		minFirst, maxFirst int
+	}{
+		{
+			name:      "ordered by uid by default",
+			arguments: framework.Arguments{},
+			l:         newJob("job-a", 1),
+			r:         newJob("job-b", 1),
+			minFirst:  sessions,
+			maxFirst:  sessions,
+		},
+		{
+			name:      "balanced with random tie-break",
+			arguments: framework.Arguments{RandomTieBreak: "true"},
+			l:         newJob("job-a", 1),
+			r:         newJob("job-b", 1),
+			minFirst:  sessions * 45 / 100,
+			maxFirst:  sessions * 55 / 100,
+		},
+		{
+			name:      "priority is not overridden by random tie-break",
+			arguments: framework.Arguments{RandomTieBreak: "true"},
+			l:         newJob("job-b", 1),
+			r:         newJob("job-a", 0),
+			minFirst:  sessions,
+			maxFirst:  sessions,
+		},
+	}
+
+	for _, test := range tests {
+		schedulerCache := &cache.SchedulerCache{
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Queues:        make(map[api.QueueID]*api.QueueInfo),
+			Binder:        &util.FakeBinder{Binds: map[string]string{}, Channel: make(chan string)},
+			StatusUpdater: &util.FakeStatusUpdater{},
+			VolumeBinder:  &util.FakeVolumeBinder{},
+
+			Recorder: record.NewFakeRecorder(100),
+		}
+
+		trueValue := true
+		tiers := []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:            PluginName,
+						EnabledJobOrder: &trueValue,
+						Arguments:       test.arguments,
+					},
+				},
+			},
+		}
+
+		first := 0
+		for i := 0; i < sessions; i++ {
+			ssn := framework.OpenSession(schedulerCache, tiers, nil)
+			lFirst := ssn.JobOrderFn(test.l, test.r)
+			if lFirst == ssn.JobOrderFn(test.r, test.l) {
+				t.Fatalf("case %s: the order of jobs is not consistent in session", test.name)
+			}
+			if lFirst {
+				first++
+			}
+			framework.CloseSession(ssn)
+		}
+
+		if first < test.minFirst || first > test.maxFirst {
+			t.Errorf("case %s: expected job %s to be first in [%d, %d] of %d sessions, got %d",
+				test.name, test.l.UID, test.minFirst, test.maxFirst, sessions, first)
+		}
+	}
+}
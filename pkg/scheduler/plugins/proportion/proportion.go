@@ -17,11 +17,17 @@ limitations under the License.
 package proportion
 
 import (
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog"
 
 	"volcano.sh/volcano/pkg/scheduler/api"
 	"volcano.sh/volcano/pkg/scheduler/api/helpers"
 	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/metrics"
 )
 
 // PluginName indicates name of volcano scheduler plugin.
@@ -43,6 +49,10 @@ type queueAttr struct {
 	deserved  *api.Resource
 	allocated *api.Resource
 	request   *api.Resource
+	// guarantee is the fitted guaranteed resource, nil if not guaranteed
+	guarantee *api.Resource
+	// capability is the upper limit of deserved, no limit if empty
+	capability v1.ResourceList
 }
 
 // New return proportion action
@@ -80,6 +90,8 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 				deserved:  api.EmptyResource(),
 				allocated: api.EmptyResource(),
 				request:   api.EmptyResource(),
+
+				capability: queue.Capability,
 			}
 			pp.queueOpts[job.Queue] = attr
 			klog.V(4).Infof("Added Queue <%s> attributes.", job.Queue)
@@ -101,8 +113,41 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		}
 	}
 
+	// Queues may exceed their capability by the overcommit ratio while the cluster has headroom.
+	totalRequest := api.EmptyResource()
+	for _, attr := range pp.queueOpts {
+		totalRequest.Add(attr.request)
+	}
+	headroom := totalRequest.LessEqual(pp.totalResource)
+	for _, attr := range pp.queueOpts {
+		queue := ssn.Queues[attr.queueID]
+		if headroom && queue.Queue.Spec.OvercommitRatio != nil {
+			attr.capability = scaleResourceList(queue.Capability, *queue.Queue.Spec.OvercommitRatio)
+		}
+	}
+
+	// Guaranteed resources are reserved before weights apply, as far as the queue requests them;
+	// if the guarantees of all queues exceed the total resource, each of them is scaled down in proportion.
+	guarantees := fitGuarantees(pp.totalResource, ssn.Queues)
+	pp.updateQueueStatus(ssn, guarantees)
+
 	remaining := pp.totalResource.Clone()
 	meet := map[api.QueueID]struct{}{}
+	for _, attr := range pp.queueOpts {
+		guarantee, found := guarantees[attr.queueID]
+		if !found {
+			continue
+		}
+		attr.guarantee = guarantee
+		attr.deserved = helpers.Min(guarantee, attr.request)
+		remaining.Sub(attr.deserved)
+		if attr.request.LessEqual(attr.deserved) {
+			meet[attr.queueID] = struct{}{}
+			klog.V(4).Infof("queue <%s> is meet by its guarantee", attr.name)
+		}
+		pp.updateShare(attr)
+	}
+
 	for {
 		totalWeight := int32(0)
 		for _, attr := range pp.queueOpts {
@@ -139,6 +184,10 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 				klog.V(4).Infof("queue <%s> is meet", attr.name)
 
 			}
+			if capResource(attr.deserved, attr.capability) {
+				meet[attr.queueID] = struct{}{}
+				klog.V(4).Infof("queue <%s> is capped by its capability", attr.name)
+			}
 			pp.updateShare(attr)
 
 			klog.V(4).Infof("The attributes of queue <%s> in proportion: deserved <%v>, allocate <%v>, request <%v>, share <%0.2f>",
@@ -160,6 +209,15 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		lv := l.(*api.QueueInfo)
 		rv := r.(*api.QueueInfo)
 
+		// Queue with higher priority is considered first whatever its share is,
+		// weight only decides the order of queues with the same priority.
+		if lv.Priority != rv.Priority {
+			if lv.Priority > rv.Priority {
+				return -1
+			}
+			return 1
+		}
+
 		if pp.queueOpts[lv.UID].share == pp.queueOpts[rv.UID].share {
 			return 0
 		}
@@ -190,6 +248,12 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 			}
 
 			allocated.Sub(reclaimee.Resreq)
+			// Never reclaim the queue below its guarantee.
+			if attr.guarantee != nil && !attr.guarantee.LessEqual(allocated) {
+				klog.V(3).Infof("Skip reclaiming Task <%s/%s>, Queue <%s> would be below its guarantee <%v>.",
+					reclaimee.Namespace, reclaimee.Name, job.Queue, attr.guarantee)
+				continue
+			}
 			if attr.deserved.LessEqualStrict(allocated) {
 				victims = append(victims, reclaimee)
 			}
@@ -218,21 +282,18 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		queue := ssn.Queues[queueID]
 
 		// If no capability is set, always enqueue the job.
-		if len(queue.Queue.Spec.Capability) == 0 {
+		if len(queue.Capability) == 0 {
 			return true
 		}
 
 		pgResource := api.NewResource(*job.PodGroup.Spec.MinResources)
-		if len(queue.Queue.Spec.Capability) == 0 {
+		if len(queue.Capability) == 0 {
 			klog.V(4).Infof("Capability of queue <%s> was not set, allow job <%s/%s> to Inqueue.",
 				queue.Name, job.Namespace, job.Name)
 			return true
 		}
-		// The queue resource quota limit has not reached
-		if pgResource.Clone().Add(attr.allocated).LessEqual(api.NewResource(queue.Queue.Spec.Capability)) {
-			return true
-		}
-		return false
+		// The queue resource quota limit, which may be overcommitted, has not reached
+		return withinCapability(pgResource.Clone().Add(attr.allocated), attr.capability)
 	})
 
 	// Register event handlers.
@@ -261,10 +322,57 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 }
 
 func (pp *proportionPlugin) OnSessionClose(ssn *framework.Session) {
+	pp.updateQueueMetrics(ssn)
+
 	pp.totalResource = nil
 	pp.queueOpts = nil
 }
 
+// QueueShares returns the share of every queue in the session, for the order dump of the session.
+func (pp *proportionPlugin) QueueShares() map[api.QueueID]float64 {
+	shares := map[api.QueueID]float64{}
+	for id, attr := range pp.queueOpts {
+		shares[id] = attr.share
+	}
+
+	return shares
+}
+
+// updateQueueMetrics records the allocated resources, capability and share of all queues;
+// the queues without jobs in the session have nothing allocated.
+func (pp *proportionPlugin) updateQueueMetrics(ssn *framework.Session) {
+	for _, queue := range ssn.Queues {
+		allocated, share := api.EmptyResource(), float64(0)
+		if attr, found := pp.queueOpts[queue.UID]; found {
+			allocated, share = attr.allocated, attr.share
+		}
+		metrics.UpdateQueueAllocated(queue.Name, queueResources(allocated))
+		metrics.UpdateQueueShare(queue.Name, share)
+
+		if len(queue.Capability) == 0 {
+			metrics.UpdateQueueCapability(queue.Name, nil)
+		} else {
+			capability := queueResources(api.NewResource(queue.Capability))
+			metrics.UpdateQueueCapability(queue.Name, &capability)
+		}
+	}
+}
+
+func queueResources(r *api.Resource) metrics.QueueResources {
+	res := metrics.QueueResources{
+		MilliCPU: r.MilliCPU,
+		Memory:   r.Memory,
+	}
+	for name, value := range r.ScalarResources {
+		if res.Scalars == nil {
+			res.Scalars = map[string]float64{}
+		}
+		res.Scalars[string(name)] = value
+	}
+
+	return res
+}
+
 func (pp *proportionPlugin) updateShare(attr *queueAttr) {
 	res := float64(0)
 
@@ -278,3 +386,164 @@ func (pp *proportionPlugin) updateShare(attr *queueAttr) {
 
 	attr.share = res
 }
+
+// updateQueueStatus records the guarantees which can not be met and whether the queues are
+// overcommitted in the status of queues.
+func (pp *proportionPlugin) updateQueueStatus(ssn *framework.Session, guarantees map[api.QueueID]*api.Resource) {
+	for _, queue := range ssn.Queues {
+		if queue.Queue == nil {
+			continue
+		}
+
+		var unmet v1.ResourceList
+		if guarantee, found := guarantees[queue.UID]; found {
+			unmet = unmetGuarantee(queue.Queue.Spec.Guaranteed, guarantee)
+		}
+		overcommitted := pp.overcommitted(queue)
+		if equality.Semantic.DeepEqual(unmet, queue.Queue.Status.UnmetGuarantee) &&
+			overcommitted == queue.Queue.Status.Overcommitted {
+			continue
+		}
+
+		if !equality.Semantic.DeepEqual(unmet, queue.Queue.Status.UnmetGuarantee) {
+			if len(unmet) != 0 {
+				klog.Warningf("Guarantee of Queue <%s> can not be met as the guarantees of all queues exceed "+
+					"the cluster capacity, unmet <%v>.", queue.Name, unmet)
+			} else {
+				klog.V(3).Infof("Guarantee of Queue <%s> is met.", queue.Name)
+			}
+		}
+		if overcommitted != queue.Queue.Status.Overcommitted {
+			klog.V(3).Infof("Queue <%s> overcommitted: %v.", queue.Name, overcommitted)
+		}
+
+		updated := queue.Clone()
+		updated.Queue = queue.Queue.DeepCopy()
+		updated.Queue.Status.UnmetGuarantee = unmet
+		updated.Queue.Status.Overcommitted = overcommitted
+		if err := ssn.UpdateQueueStatus(updated); err != nil {
+			klog.Errorf("Failed to update status of Queue <%s>: %v", queue.Name, err)
+		}
+	}
+}
+
+// overcommitted returns whether the queue is allocated more resources than its capability.
+func (pp *proportionPlugin) overcommitted(queue *api.QueueInfo) bool {
+	attr, found := pp.queueOpts[queue.UID]
+	if !found || queue.Queue.Spec.OvercommitRatio == nil || len(queue.Capability) == 0 {
+		return false
+	}
+
+	capability := api.NewResource(queue.Capability)
+	for rn := range queue.Capability {
+		if attr.allocated.Get(rn) > capability.Get(rn) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fitGuarantees returns the guaranteed resources of queues; for each resource whose guarantees
+// exceed the total resource, the guarantees are scaled down by the same ratio.
+func fitGuarantees(total *api.Resource, queues map[api.QueueID]*api.QueueInfo) map[api.QueueID]*api.Resource {
+	guarantees := map[api.QueueID]*api.Resource{}
+	sum := api.EmptyResource()
+	for _, queue := range queues {
+		if queue.Queue == nil || len(queue.Queue.Spec.Guaranteed) == 0 {
+			continue
+		}
+		guarantee := api.NewResource(queue.Queue.Spec.Guaranteed)
+		guarantees[queue.UID] = guarantee
+		sum.Add(guarantee)
+	}
+
+	for _, rn := range sum.ResourceNames() {
+		if sum.Get(rn) <= total.Get(rn) {
+			continue
+		}
+		ratio := total.Get(rn) / sum.Get(rn)
+		for _, guarantee := range guarantees {
+			setResource(guarantee, rn, guarantee.Get(rn)*ratio)
+		}
+	}
+
+	return guarantees
+}
+
+// unmetGuarantee returns the part of guaranteed resources which is not covered by the fitted guarantee.
+func unmetGuarantee(guaranteed v1.ResourceList, fitted *api.Resource) v1.ResourceList {
+	requested := api.NewResource(guaranteed)
+	unmet := v1.ResourceList{}
+	for rn := range guaranteed {
+		value := requested.Get(rn) - fitted.Get(rn)
+		// Ignore the rounding errors of scaling.
+		if value < 1 {
+			continue
+		}
+		switch rn {
+		case v1.ResourceMemory:
+			unmet[rn] = *resource.NewQuantity(int64(math.Ceil(value)), resource.BinarySI)
+		default:
+			unmet[rn] = *resource.NewMilliQuantity(int64(math.Ceil(value)), resource.DecimalSI)
+		}
+	}
+
+	if len(unmet) == 0 {
+		return nil
+	}
+	return unmet
+}
+
+// scaleResourceList returns the resource list whose quantities are multiplied by the ratio.
+func scaleResourceList(list v1.ResourceList, ratio float64) v1.ResourceList {
+	scaled := v1.ResourceList{}
+	for rn, quantity := range list {
+		q := resource.NewMilliQuantity(int64(float64(quantity.MilliValue())*ratio), quantity.Format)
+		scaled[rn] = *q
+	}
+
+	return scaled
+}
+
+// capResource limits the resource by capability, and returns whether any of its resources is limited.
+func capResource(r *api.Resource, capability v1.ResourceList) bool {
+	if len(capability) == 0 {
+		return false
+	}
+
+	capped := false
+	limit := api.NewResource(capability)
+	for rn := range capability {
+		if r.Get(rn) > limit.Get(rn) {
+			setResource(r, rn, limit.Get(rn))
+			capped = true
+		}
+	}
+
+	return capped
+}
+
+// withinCapability returns whether the resource does not exceed the capability; the resources
+// which the capability does not name are unlimited, whatever their names are.
+func withinCapability(r *api.Resource, capability v1.ResourceList) bool {
+	limit := r.Clone()
+	capped := api.NewResource(capability)
+	for rn := range capability {
+		setResource(limit, rn, capped.Get(rn))
+	}
+
+	return r.LessEqual(limit)
+}
+
+// setResource sets the quantity of a resource.
+func setResource(r *api.Resource, rn v1.ResourceName, value float64) {
+	switch rn {
+	case v1.ResourceCPU:
+		r.MilliCPU = value
+	case v1.ResourceMemory:
+		r.Memory = value
+	default:
+		r.SetScalar(rn, value)
+	}
+}
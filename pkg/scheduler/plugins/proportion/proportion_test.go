@@ -0,0 +1,386 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv2 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha2"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+// queueStatusUpdater records the status of queues updated by the plugin.
+type queueStatusUpdater struct {
+	util.FakeStatusUpdater
+
+	unmet         map[string]v1.ResourceList
+	overcommitted map[string]bool
+}
+
+func (u *queueStatusUpdater) UpdateQueueStatus(queue *api.QueueInfo) error {
+	u.unmet[queue.Name] = queue.Queue.Status.UnmetGuarantee
+	u.overcommitted[queue.Name] = queue.Queue.Status.Overcommitted
+	return nil
+}
+
+func buildQueue(name string, weight int32, guaranteed, capability v1.ResourceList) *schedulingv2.Queue {
+	return &schedulingv2.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: schedulingv2.QueueSpec{
+			Weight:     weight,
+			Guaranteed: guaranteed,
+			Capability: capability,
+		},
+	}
+}
+
+func buildPodGroup(name, queue string) *schedulingv2.PodGroup {
+	return &schedulingv2.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec: schedulingv2.PodGroupSpec{
+			MinMember: 1,
+			Queue:     queue,
+		},
+	}
+}
+
+func buildPendingPods(pg string, num int, req v1.ResourceList) []*v1.Pod {
+	var pods []*v1.Pod
+	for i := 0; i < num; i++ {
+		pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-%d", pg, i), "", v1.PodPending,
+			req, pg, map[string]string{}, map[string]string{}))
+	}
+	return pods
+}
+
+func TestProportionGuarantee(t *testing.T) {
+	cpu := func(q string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(q)}
+	}
+
+	tests := []struct {
+		name      string
+		queues    []*schedulingv2.Queue
+		podGroups []*schedulingv2.PodGroup
+		pods      []*v1.Pod
+		// expected deserved milli cpu of queues
+		deserved map[string]float64
+		// expected unmet guarantees reported in queue status
+		unmet map[string]string
+	}{
+		{
+			name: "guarantees exceeding cluster capacity are scaled down fairly",
+			queues: []*schedulingv2.Queue{
+				buildQueue("q1", 1, cpu("6"), nil),
+				buildQueue("q2", 1, cpu("4"), nil),
+				buildQueue("q3", 1, cpu("2"), nil),
+			},
+			podGroups: []*schedulingv2.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+				buildPodGroup("pg3", "q3"),
+			},
+			pods: append(append(
+				buildPendingPods("pg1", 8, util.BuildResourceList("1", "1G")),
+				buildPendingPods("pg2", 8, util.BuildResourceList("1", "1G"))...),
+				buildPendingPods("pg3", 8, util.BuildResourceList("1", "1G"))...),
+			deserved: map[string]float64{"q1": 3000, "q2": 2000, "q3": 1000},
+			unmet:    map[string]string{"q1": "3", "q2": "2", "q3": "1"},
+		},
+		{
+			name: "surplus is split by weight after guarantees and capped by capability",
+			queues: []*schedulingv2.Queue{
+				buildQueue("q1", 1, cpu("2"), nil),
+				buildQueue("q2", 2, nil, nil),
+				buildQueue("q3", 1, nil, cpu("1")),
+			},
+			podGroups: []*schedulingv2.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+				buildPodGroup("pg3", "q3"),
+			},
+			pods: append(append(
+				buildPendingPods("pg1", 8, util.BuildResourceList("1", "1G")),
+				buildPendingPods("pg2", 8, util.BuildResourceList("1", "1G"))...),
+				buildPendingPods("pg3", 8, util.BuildResourceList("1", "1G"))...),
+			// 2 cpu are reserved for q1, q3 is capped at 1 cpu of its 1 cpu share,
+			// and the 3 cpu left are split by weight between q1 and q2.
+			deserved: map[string]float64{"q1": 3000, "q2": 2000, "q3": 1000},
+		},
+		{
+			name: "guarantee is not reserved beyond request",
+			queues: []*schedulingv2.Queue{
+				buildQueue("q1", 1, cpu("4"), nil),
+				buildQueue("q2", 1, nil, nil),
+			},
+			podGroups: []*schedulingv2.PodGroup{
+				buildPodGroup("pg1", "q1"),
+				buildPodGroup("pg2", "q2"),
+			},
+			pods: append(
+				buildPendingPods("pg1", 1, util.BuildResourceList("1", "1G")),
+				buildPendingPods("pg2", 8, util.BuildResourceList("1", "1G"))...),
+			// Only the requested 1 cpu of q1 is reserved, the 5 cpu left are split by weight.
+			deserved: map[string]float64{"q1": 3500, "q2": 2500},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updater := &queueStatusUpdater{unmet: map[string]v1.ResourceList{}, overcommitted: map[string]bool{}}
+			schedulerCache := &cache.SchedulerCache{
+				Nodes:         make(map[string]*api.NodeInfo),
+				Jobs:          make(map[api.JobID]*api.JobInfo),
+				Queues:        make(map[api.QueueID]*api.QueueInfo),
+				StatusUpdater: updater,
+				VolumeBinder:  &util.FakeVolumeBinder{},
+
+				Recorder: record.NewFakeRecorder(100),
+			}
+			schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("6", "100Gi"), map[string]string{}))
+			for _, q := range test.queues {
+				schedulerCache.AddQueueV1alpha2(q)
+			}
+			for _, pg := range test.podGroups {
+				schedulerCache.AddPodGroupV1alpha2(pg)
+			}
+			for _, pod := range test.pods {
+				schedulerCache.AddPod(pod)
+			}
+
+			ssn := framework.OpenSession(schedulerCache, nil, nil)
+			defer framework.CloseSession(ssn)
+
+			pp := New(nil).(*proportionPlugin)
+			pp.OnSessionOpen(ssn)
+
+			for name, expected := range test.deserved {
+				attr := pp.queueOpts[api.QueueID(name)]
+				if attr == nil {
+					t.Fatalf("queue %s is not found", name)
+				}
+				if math.Abs(attr.deserved.MilliCPU-expected) > 1 {
+					t.Errorf("expected deserved cpu of queue %s to be %v, got %v", name, expected, attr.deserved.MilliCPU)
+				}
+			}
+
+			if len(updater.unmet) != len(test.unmet) {
+				t.Errorf("expected unmet guarantees %v, got %v", test.unmet, updater.unmet)
+			}
+			for name, expected := range test.unmet {
+				unmet := updater.unmet[name][v1.ResourceCPU]
+				if unmet.Cmp(resource.MustParse(expected)) != 0 {
+					t.Errorf("expected unmet cpu of queue %s to be %s, got %s", name, expected, unmet.String())
+				}
+			}
+		})
+	}
+}
+
+func TestProportionOvercommit(t *testing.T) {
+	cpu := func(q string) v1.ResourceList {
+		return v1.ResourceList{v1.ResourceCPU: resource.MustParse(q)}
+	}
+	overcommitQueue := func(name string, capability v1.ResourceList, ratio float64) *schedulingv2.Queue {
+		queue := buildQueue(name, 1, nil, capability)
+		queue.Spec.OvercommitRatio = &ratio
+		return queue
+	}
+	runningPods := func(pg string, num int) []*v1.Pod {
+		var pods []*v1.Pod
+		for i := 0; i < num; i++ {
+			pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-running-%d", pg, i), "n1", v1.PodRunning,
+				util.BuildResourceList("1", "1G"), pg, map[string]string{}, map[string]string{}))
+		}
+		return pods
+	}
+
+	tests := []struct {
+		name   string
+		queues []*schedulingv2.Queue
+		pods   []*v1.Pod
+		// expected capability in milli cpu of q1
+		capability float64
+		// expected deserved milli cpu of queues
+		deserved map[string]float64
+		// expected queues reported as overcommitted
+		overcommitted map[string]bool
+	}{
+		{
+			name: "capability is overcommitted while cluster has headroom",
+			queues: []*schedulingv2.Queue{
+				overcommitQueue("q1", cpu("2"), 1.5),
+				buildQueue("q2", 1, nil, nil),
+			},
+			pods: append(
+				buildPendingPods("pg1", 4, util.BuildResourceList("1", "1G")),
+				buildPendingPods("pg2", 1, util.BuildResourceList("1", "1G"))...),
+			capability: 3000,
+			deserved:   map[string]float64{"q1": 3000},
+		},
+		{
+			name: "capability shrinks back under contention",
+			queues: []*schedulingv2.Queue{
+				overcommitQueue("q1", cpu("2"), 1.5),
+				buildQueue("q2", 1, nil, nil),
+			},
+			pods: append(
+				buildPendingPods("pg1", 4, util.BuildResourceList("1", "1G")),
+				buildPendingPods("pg2", 8, util.BuildResourceList("1", "1G"))...),
+			capability: 2000,
+			deserved:   map[string]float64{"q1": 2000},
+		},
+		{
+			name: "queue allocated beyond capability is overcommitted",
+			queues: []*schedulingv2.Queue{
+				overcommitQueue("q1", cpu("2"), 1.5),
+				buildQueue("q2", 1, nil, nil),
+			},
+			pods: append(
+				runningPods("pg1", 3),
+				buildPendingPods("pg2", 1, util.BuildResourceList("1", "1G"))...),
+			capability:    3000,
+			overcommitted: map[string]bool{"q1": true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updater := &queueStatusUpdater{unmet: map[string]v1.ResourceList{}, overcommitted: map[string]bool{}}
+			schedulerCache := &cache.SchedulerCache{
+				Nodes:         make(map[string]*api.NodeInfo),
+				Jobs:          make(map[api.JobID]*api.JobInfo),
+				Queues:        make(map[api.QueueID]*api.QueueInfo),
+				StatusUpdater: updater,
+				VolumeBinder:  &util.FakeVolumeBinder{},
+
+				Recorder: record.NewFakeRecorder(100),
+			}
+			schedulerCache.AddNode(util.BuildNode("n1", util.BuildResourceList("6", "100Gi"), map[string]string{}))
+			for _, q := range test.queues {
+				schedulerCache.AddQueueV1alpha2(q)
+			}
+			schedulerCache.AddPodGroupV1alpha2(buildPodGroup("pg1", "q1"))
+			schedulerCache.AddPodGroupV1alpha2(buildPodGroup("pg2", "q2"))
+			for _, pod := range test.pods {
+				schedulerCache.AddPod(pod)
+			}
+
+			ssn := framework.OpenSession(schedulerCache, nil, nil)
+			defer framework.CloseSession(ssn)
+
+			pp := New(nil).(*proportionPlugin)
+			pp.OnSessionOpen(ssn)
+
+			capability := pp.queueOpts["q1"].capability[v1.ResourceCPU]
+			if float64(capability.MilliValue()) != test.capability {
+				t.Errorf("expected capability of queue q1 to be %v, got %v", test.capability, capability.MilliValue())
+			}
+			for name, expected := range test.deserved {
+				attr := pp.queueOpts[api.QueueID(name)]
+				if math.Abs(attr.deserved.MilliCPU-expected) > 1 {
+					t.Errorf("expected deserved cpu of queue %s to be %v, got %v", name, expected, attr.deserved.MilliCPU)
+				}
+			}
+			for name, overcommitted := range updater.overcommitted {
+				if overcommitted != test.overcommitted[name] {
+					t.Errorf("expected overcommitted of queue %s to be %v, got %v", name, test.overcommitted[name], overcommitted)
+				}
+			}
+			if len(test.overcommitted) != 0 && len(updater.overcommitted) == 0 {
+				t.Errorf("expected overcommitted queues %v, got none", test.overcommitted)
+			}
+		})
+	}
+}
+
+func TestProportionCustomResource(t *testing.T) {
+	fpga := v1.ResourceName("example.com/fpga")
+	withFPGA := func(list v1.ResourceList, quantity string) v1.ResourceList {
+		list[fpga] = resource.MustParse(quantity)
+		return list
+	}
+	podGroupWithMinResources := func(name, queue string, minResources v1.ResourceList) *schedulingv2.PodGroup {
+		pg := buildPodGroup(name, queue)
+		pg.Spec.MinResources = &minResources
+		return pg
+	}
+
+	updater := &queueStatusUpdater{unmet: map[string]v1.ResourceList{}, overcommitted: map[string]bool{}}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: updater,
+		VolumeBinder:  &util.FakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(util.BuildNode("n1", withFPGA(util.BuildResourceList("6", "100Gi"), "4"), map[string]string{}))
+	// The capability of q1 only names the custom resource, cpu and memory are unlimited.
+	schedulerCache.AddQueueV1alpha2(buildQueue("q1", 1, v1.ResourceList{fpga: resource.MustParse("3")},
+		v1.ResourceList{fpga: resource.MustParse("3")}))
+	schedulerCache.AddQueueV1alpha2(buildQueue("q2", 1, nil, nil))
+	schedulerCache.AddPodGroupV1alpha2(podGroupWithMinResources("pg1", "q1", withFPGA(util.BuildResourceList("1", "1G"), "2")))
+	schedulerCache.AddPodGroupV1alpha2(podGroupWithMinResources("pg2", "q2", withFPGA(util.BuildResourceList("1", "1G"), "1")))
+	schedulerCache.AddPodGroupV1alpha2(podGroupWithMinResources("pg3", "q1", withFPGA(util.BuildResourceList("1", "1G"), "4")))
+	for _, pod := range append(append(
+		buildPendingPods("pg1", 4, withFPGA(util.BuildResourceList("1", "1G"), "1")),
+		buildPendingPods("pg2", 4, withFPGA(util.BuildResourceList("1", "1G"), "1"))...),
+		buildPendingPods("pg3", 1, withFPGA(util.BuildResourceList("1", "1G"), "4"))...) {
+		schedulerCache.AddPod(pod)
+	}
+
+	ssn := framework.OpenSession(schedulerCache, nil, nil)
+	defer framework.CloseSession(ssn)
+
+	pp := New(nil).(*proportionPlugin)
+	pp.OnSessionOpen(ssn)
+	ssn.Tiers = []conf.Tier{{Plugins: []conf.PluginOption{{Name: PluginName}}}}
+
+	// q1 is guaranteed and capped at 3 fpga, the one left goes to q2.
+	for name, expected := range map[string]float64{"q1": 3000, "q2": 1000} {
+		deserved := pp.queueOpts[api.QueueID(name)].deserved.Get(fpga)
+		if math.Abs(deserved-expected) > 1 {
+			t.Errorf("expected deserved fpga of queue %s to be %v, got %v", name, expected, deserved)
+		}
+	}
+	if unmet := updater.unmet["q1"]; len(unmet) != 0 {
+		t.Errorf("expected guarantee of queue q1 to be met, got unmet %v", unmet)
+	}
+
+	for pg, expected := range map[string]bool{"pg1": true, "pg2": true, "pg3": false} {
+		job := ssn.Jobs[api.JobID("c1/"+pg)]
+		if job == nil {
+			t.Fatalf("job of podgroup %s is not found", pg)
+		}
+		if enqueueable := ssn.JobEnqueueable(job); enqueueable != expected {
+			t.Errorf("expected podgroup %s enqueueable %v, got %v", pg, expected, enqueueable)
+		}
+	}
+}
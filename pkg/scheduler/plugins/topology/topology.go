@@ -0,0 +1,294 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+
+	"volcano.sh/volcano/pkg/apis/scheduling"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+)
+
+// PluginName indicates name of volcano scheduler plugin.
+const PluginName = "topology"
+
+const (
+	// TopologyKey is the key of the node label whose values are the topology domains the members
+	// of a gang are co-located in, e.g. a rack or zone label
+	TopologyKey = "topology.key"
+	// TopologyWeight is the key for providing the weight of the topology score
+	TopologyWeight = "topology.weight"
+	// TopologyStrict is the key for restricting the members of a gang to its domain rather than
+	// preferring it; it is only enforced while the domain can hold the gang and until the
+	// allocation of the gang in the domain fails
+	TopologyStrict = "topology.strict"
+
+	defaultTopologyKey = "failure-domain.beta.kubernetes.io/zone"
+)
+
+type topologyPlugin struct {
+	key    string
+	weight int
+	strict bool
+
+	// job -> the domain its pending members are placed in, the jobs whose members can not be
+	// co-located are not recorded
+	domains map[api.JobID]string
+	// the jobs restricted to their domain in strict mode, a job is unpinned once its allocation
+	// in the domain fails
+	pinned map[api.JobID]bool
+}
+
+// New returns topology plugin
+func New(arguments framework.Arguments) framework.Plugin {
+	/*
+	   actions: "enqueue, allocate, backfill"
+	   tiers:
+	   - plugins:
+	     - name: gang
+	     - name: topology
+	       arguments:
+	         topology.key: example.com/rack
+	         topology.weight: 10
+	         topology.strict: true
+	*/
+	tp := &topologyPlugin{
+		key:    defaultTopologyKey,
+		weight: 1,
+	}
+	if key := arguments[TopologyKey]; key != "" {
+		tp.key = key
+	}
+	arguments.GetInt(&tp.weight, TopologyWeight)
+	if tp.weight < 0 {
+		tp.weight = 1
+	}
+	arguments.GetBool(&tp.strict, TopologyStrict)
+
+	return tp
+}
+
+func (tp *topologyPlugin) Name() string {
+	return PluginName
+}
+
+func (tp *topologyPlugin) OnSessionOpen(ssn *framework.Session) {
+	tp.domains = selectDomains(ssn, tp.key)
+	tp.pinned = map[api.JobID]bool{}
+	for uid := range tp.domains {
+		// The gang failed to be allocated in a previous session, its domain is only preferred
+		// rather than waiting for it forever.
+		if job := ssn.Jobs[uid]; !isUnschedulable(job) {
+			tp.pinned[uid] = true
+		}
+	}
+
+	nodeOrderFn := func(task *api.TaskInfo, node *api.NodeInfo) (float64, error) {
+		domain, found := tp.domains[task.Job]
+		if !found || domainOf(node, tp.key) != domain {
+			return 0, nil
+		}
+
+		return float64(schedulerapi.MaxPriority * tp.weight), nil
+	}
+	if tp.weight != 0 {
+		ssn.AddNodeOrderFn(tp.Name(), nodeOrderFn)
+	}
+
+	if tp.strict {
+		ssn.AddPredicateFn(tp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+			domain := tp.domains[task.Job]
+			if !tp.pinned[task.Job] || domainOf(node, tp.key) == domain {
+				return nil
+			}
+
+			return fmt.Errorf("node <%s> is out of topology domain <%s=%s> of job <%s>",
+				node.Name, tp.key, domain, task.Job)
+		})
+
+		// The allocation of a gang in its domain is discarded, the following actions of the
+		// session may place its members out of the domain.
+		ssn.AddEventHandler(&framework.EventHandler{
+			DeallocateFunc: func(event *framework.Event) {
+				if event.Task.Status == api.Pending && tp.pinned[event.Task.Job] {
+					klog.V(4).Infof("Job <%s> is unpinned from topology domain <%s=%s>.",
+						event.Task.Job, tp.key, tp.domains[event.Task.Job])
+					delete(tp.pinned, event.Task.Job)
+				}
+			},
+		})
+	}
+}
+
+func (tp *topologyPlugin) OnSessionClose(ssn *framework.Session) {
+	tp.domains = nil
+	tp.pinned = nil
+}
+
+// isUnschedulable returns whether the gang failed to be allocated in a previous session.
+func isUnschedulable(job *api.JobInfo) bool {
+	if job == nil || job.PodGroup == nil {
+		return false
+	}
+	for _, c := range job.PodGroup.Status.Conditions {
+		if c.Type == scheduling.PodGroupUnschedulableType && c.Status == v1.ConditionTrue &&
+			c.Reason == scheduling.NotEnoughResourcesReason {
+			return true
+		}
+	}
+
+	return false
+}
+
+// domainOf returns the topology domain of node, empty if the node is not labeled.
+func domainOf(node *api.NodeInfo, key string) string {
+	if node == nil || node.Node == nil {
+		return ""
+	}
+
+	return node.Node.Labels[key]
+}
+
+// selectDomains selects the domain of every gang with pending members: the domain which has the
+// most members placed among those whose idle nodes fit the pending members the gang still needs
+// to reach its minAvailable. The gangs are selected in the job order of the session and the
+// resources of their members are reserved in their domain, so that the next gangs do not count on
+// them. A gang which fits in no domain is left out, its members are spread out by the other
+// plugins rather than waiting for a domain forever.
+func selectDomains(ssn *framework.Session, key string) map[api.JobID]string {
+	idle := map[string]*api.Resource{}
+	nodes := map[string][]string{}
+	for _, node := range ssn.Nodes {
+		domain := domainOf(node, key)
+		if domain == "" {
+			continue
+		}
+		idle[node.Name] = node.Idle.Clone()
+		nodes[domain] = append(nodes[domain], node.Name)
+	}
+	for _, names := range nodes {
+		sort.Strings(names)
+	}
+
+	var jobs []*api.JobInfo
+	for _, job := range ssn.Jobs {
+		if job.MinAvailable > 1 && len(job.TaskStatusIndex[api.Pending]) > 0 {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return ssn.JobOrderFn(jobs[i], jobs[j])
+	})
+
+	domains := map[api.JobID]string{}
+	for _, job := range jobs {
+		tasks := gangTasks(ssn, job)
+
+		members := map[string]int{}
+		for _, task := range job.Tasks {
+			if !api.AllocatedStatus(task.Status) && task.Status != api.Pipelined {
+				continue
+			}
+			if domain := domainOf(ssn.Nodes[task.NodeName], key); domain != "" {
+				members[domain]++
+			}
+		}
+
+		reserved := map[string]map[string]*api.Resource{}
+		var candidates []string
+		for domain, names := range nodes {
+			if reservation := fit(tasks, names, idle); reservation != nil {
+				reserved[domain] = reservation
+				candidates = append(candidates, domain)
+			}
+		}
+		if len(candidates) == 0 {
+			klog.V(4).Infof("Job <%s/%s> fits in no topology domain of <%s>, its tasks are spread out.",
+				job.Namespace, job.Name, key)
+			continue
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if members[candidates[i]] != members[candidates[j]] {
+				return members[candidates[i]] > members[candidates[j]]
+			}
+			return candidates[i] < candidates[j]
+		})
+		domain := candidates[0]
+		for name, resource := range reserved[domain] {
+			idle[name] = resource
+		}
+		domains[job.UID] = domain
+		klog.V(4).Infof("Job <%s/%s> is co-located in topology domain <%s=%s>.",
+			job.Namespace, job.Name, key, domain)
+	}
+
+	return domains
+}
+
+// gangTasks returns the pending tasks of the job which are still needed to reach its minAvailable,
+// in the task order of the session.
+func gangTasks(ssn *framework.Session, job *api.JobInfo) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return ssn.TaskOrderFn(tasks[i], tasks[j])
+	})
+
+	needed := int(job.MinAvailable - job.ReadyTaskNum())
+	if needed < 0 {
+		needed = 0
+	}
+	if needed < len(tasks) {
+		tasks = tasks[:needed]
+	}
+
+	return tasks
+}
+
+// fit places the tasks on the first nodes whose idle resources fit them, and returns the idle
+// resources of the nodes left after placing them; nil is returned if a task fits on no node.
+func fit(tasks []*api.TaskInfo, names []string, idle map[string]*api.Resource) map[string]*api.Resource {
+	left := map[string]*api.Resource{}
+	for _, name := range names {
+		left[name] = idle[name].Clone()
+	}
+
+	for _, task := range tasks {
+		placed := false
+		for _, name := range names {
+			if task.Resreq.LessEqual(left[name]) {
+				left[name].Sub(task.Resreq)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			return nil
+		}
+	}
+
+	return left
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulingv1 "volcano.sh/volcano/pkg/apis/scheduling/v1alpha1"
+	"volcano.sh/volcano/pkg/scheduler/api"
+	"volcano.sh/volcano/pkg/scheduler/cache"
+	"volcano.sh/volcano/pkg/scheduler/conf"
+	"volcano.sh/volcano/pkg/scheduler/framework"
+	"volcano.sh/volcano/pkg/scheduler/util"
+)
+
+// rackNodes are two racks of two nodes, and a node out of the racks.
+var rackNodes = []*v1.Node{
+	util.BuildNode("n1", util.BuildResourceList("2", "4Gi"), map[string]string{"example.com/rack": "r1"}),
+	util.BuildNode("n2", util.BuildResourceList("2", "4Gi"), map[string]string{"example.com/rack": "r1"}),
+	util.BuildNode("n3", util.BuildResourceList("2", "4Gi"), map[string]string{"example.com/rack": "r2"}),
+	util.BuildNode("n4", util.BuildResourceList("2", "4Gi"), map[string]string{"example.com/rack": "r2"}),
+	util.BuildNode("n5", util.BuildResourceList("2", "4Gi"), make(map[string]string)),
+}
+
+// buildPods builds the running pods on n3 and the pending pods of the podgroup.
+func buildPods(podGroup string, running int, pending int, cpu string) []*v1.Pod {
+	var pods []*v1.Pod
+	for i := 0; i < running; i++ {
+		pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-running%d", podGroup, i), "n3", v1.PodRunning,
+			util.BuildResourceList(cpu, "1Gi"), podGroup, make(map[string]string), make(map[string]string)))
+	}
+	for i := 0; i < pending; i++ {
+		pods = append(pods, util.BuildPod("c1", fmt.Sprintf("%s-pending%d", podGroup, i), "", v1.PodPending,
+			util.BuildResourceList(cpu, "1Gi"), podGroup, make(map[string]string), make(map[string]string)))
+	}
+	return pods
+}
+
+// openSession opens a session of the topology plugin with the nodes, pods and podgroups.
+func openSession(nodes []*v1.Node, pods []*v1.Pod, podGroups []*schedulingv1.PodGroup, arguments framework.Arguments) *framework.Session {
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        &util.FakeBinder{Binds: map[string]string{}, Channel: make(chan string)},
+		StatusUpdater: &util.FakeStatusUpdater{},
+		VolumeBinder:  &util.FakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	for _, node := range nodes {
+		schedulerCache.AddNode(node)
+	}
+	for _, pod := range pods {
+		schedulerCache.AddPod(pod)
+	}
+	for _, podGroup := range podGroups {
+		schedulerCache.AddPodGroupV1alpha1(podGroup)
+	}
+	schedulerCache.AddQueueV1alpha1(&schedulingv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Spec:       schedulingv1.QueueSpec{Weight: 1},
+	})
+
+	trueValue := true
+	return framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:             PluginName,
+					EnabledNodeOrder: &trueValue,
+					EnabledPredicate: &trueValue,
+					Arguments:        arguments,
+				},
+			},
+		},
+	}, nil)
+}
+
+func buildPodGroup(name string, minMember int32, unschedulable bool) *schedulingv1.PodGroup {
+	podGroup := &schedulingv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec:       schedulingv1.PodGroupSpec{Queue: "c1", MinMember: minMember},
+	}
+	if unschedulable {
+		podGroup.Status.Conditions = []schedulingv1.PodGroupCondition{{
+			Type:   schedulingv1.PodGroupUnschedulableType,
+			Status: v1.ConditionTrue,
+			Reason: schedulingv1.NotEnoughResourcesReason,
+		}}
+	}
+	return podGroup
+}
+
+func TestTopology(t *testing.T) {
+	framework.RegisterPluginBuilder(PluginName, New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name          string
+		minMember     int32
+		unschedulable bool
+		pods          []*v1.Pod
+		arguments     framework.Arguments
+		expected      map[string]float64
+		expectReject  []string
+	}{
+		{
+			name:      "co-located with placed members",
+			minMember: 3,
+			pods:      buildPods("pg1", 1, 2, "1"),
+			arguments: framework.Arguments{TopologyKey: "example.com/rack", TopologyWeight: "2"},
+			expected:  map[string]float64{"n1": 0, "n2": 0, "n3": 20, "n4": 20, "n5": 0},
+		},
+		{
+			name:      "first domain fitting the gang",
+			minMember: 3,
+			pods:      buildPods("pg1", 0, 3, "1"),
+			arguments: framework.Arguments{TopologyKey: "example.com/rack"},
+			expected:  map[string]float64{"n1": 10, "n2": 10, "n3": 0, "n4": 0, "n5": 0},
+		},
+		{
+			name:         "domain of placed members too small",
+			minMember:    5,
+			pods:         buildPods("pg1", 2, 3, "1"),
+			arguments:    framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:     map[string]float64{"n1": 10, "n2": 10, "n3": 0, "n4": 0, "n5": 0},
+			expectReject: []string{"n3", "n4", "n5"},
+		},
+		{
+			name:      "spread out if no domain fits",
+			minMember: 3,
+			pods:      buildPods("pg1", 0, 3, "2"),
+			arguments: framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:  map[string]float64{"n1": 0, "n2": 0, "n3": 0, "n4": 0, "n5": 0},
+		},
+		{
+			name:         "nodes fit the members needed by the gang",
+			minMember:    2,
+			pods:         buildPods("pg1", 0, 3, "1.2"),
+			arguments:    framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:     map[string]float64{"n1": 10, "n2": 10, "n3": 0, "n4": 0, "n5": 0},
+			expectReject: []string{"n3", "n4", "n5"},
+		},
+		{
+			name:      "spread out if no node fits",
+			minMember: 3,
+			pods:      buildPods("pg1", 0, 3, "1.2"),
+			arguments: framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:  map[string]float64{"n1": 0, "n2": 0, "n3": 0, "n4": 0, "n5": 0},
+		},
+		{
+			name:          "preferred only after failed allocation",
+			minMember:     3,
+			unschedulable: true,
+			pods:          buildPods("pg1", 0, 3, "1"),
+			arguments:     framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:      map[string]float64{"n1": 10, "n2": 10, "n3": 0, "n4": 0, "n5": 0},
+		},
+		{
+			name:      "not a gang",
+			minMember: 1,
+			pods:      buildPods("pg1", 1, 1, "1"),
+			arguments: framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"},
+			expected:  map[string]float64{"n1": 0, "n2": 0, "n3": 0, "n4": 0, "n5": 0},
+		},
+	}
+
+	for _, test := range tests {
+		ssn := openSession(rackNodes, test.pods,
+			[]*schedulingv1.PodGroup{buildPodGroup("pg1", test.minMember, test.unschedulable)}, test.arguments)
+
+		rejected := map[string]bool{}
+		for _, name := range test.expectReject {
+			rejected[name] = true
+		}
+		for _, job := range ssn.Jobs {
+			for _, task := range job.TaskStatusIndex[api.Pending] {
+				for _, node := range ssn.Nodes {
+					score, err := ssn.NodeOrderFn(task, node)
+					if err != nil {
+						t.Errorf("case %s: task %s on node %s has err %v", test.name, task.Name, node.Name, err)
+					}
+					if score != test.expected[node.Name] {
+						t.Errorf("case %s: task %s on node %s expected score %v, got %v",
+							test.name, task.Name, node.Name, test.expected[node.Name], score)
+					}
+					if err := ssn.PredicateFn(task, node); (err != nil) != rejected[node.Name] {
+						t.Errorf("case %s: task %s on node %s expected rejected %v, got %v",
+							test.name, task.Name, node.Name, rejected[node.Name], err)
+					}
+				}
+			}
+		}
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestTopologyReserved(t *testing.T) {
+	framework.RegisterPluginBuilder(PluginName, New)
+	defer framework.CleanupPluginBuilders()
+
+	// Both racks fit one of the gangs only.
+	pods := append(buildPods("pg1", 0, 3, "1"), buildPods("pg2", 0, 3, "1")...)
+	podGroups := []*schedulingv1.PodGroup{buildPodGroup("pg1", 3, false), buildPodGroup("pg2", 3, false)}
+	ssn := openSession(rackNodes, pods, podGroups,
+		framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"})
+	defer framework.CloseSession(ssn)
+
+	expected := map[api.JobID]string{"c1/pg1": "r1", "c1/pg2": "r2"}
+	for _, job := range ssn.Jobs {
+		for _, task := range job.TaskStatusIndex[api.Pending] {
+			for _, node := range ssn.Nodes {
+				if node.Name == "n5" {
+					continue
+				}
+				inDomain := node.Node.Labels["example.com/rack"] == expected[job.UID]
+				if err := ssn.PredicateFn(task, node); (err == nil) != inDomain {
+					t.Errorf("task %s on node %s expected in domain %v, got %v", task.Name, node.Name, inDomain, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTopologyUnpinned(t *testing.T) {
+	framework.RegisterPluginBuilder(PluginName, New)
+	defer framework.CleanupPluginBuilders()
+
+	ssn := openSession(rackNodes, buildPods("pg1", 0, 3, "1"), []*schedulingv1.PodGroup{buildPodGroup("pg1", 3, false)},
+		framework.Arguments{TopologyKey: "example.com/rack", TopologyStrict: "true"})
+	defer framework.CloseSession(ssn)
+
+	job := ssn.Jobs["c1/pg1"]
+	var task *api.TaskInfo
+	for _, task = range job.TaskStatusIndex[api.Pending] {
+		break
+	}
+	if err := ssn.PredicateFn(task, ssn.Nodes["n3"]); err == nil {
+		t.Fatalf("expected task %s rejected by n3 out of its domain", task.Name)
+	}
+
+	// The allocation of the gang in its domain fails, its members are not restricted to it any more.
+	stmt := ssn.Statement()
+	if err := stmt.Allocate(task, "n1"); err != nil {
+		t.Fatalf("failed to allocate task %s: %v", task.Name, err)
+	}
+	stmt.Discard()
+
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		if err := ssn.PredicateFn(task, ssn.Nodes["n3"]); err != nil {
+			t.Errorf("expected task %s unpinned, got %v", task.Name, err)
+		}
+	}
+}
@@ -39,6 +39,9 @@ type Scheduler struct {
 	configurations []conf.Configuration
 	schedulerConf  string
 	schedulePeriod time.Duration
+
+	// dumpRequests are the order dump requests waiting for the next session.
+	dumpRequests chan *dumpRequest
 }
 
 // NewScheduler returns a scheduler
@@ -54,6 +57,7 @@ func NewScheduler(
 		schedulerConf:  conf,
 		cache:          schedcache.New(config, schedulerName, defaultQueue),
 		schedulePeriod: period,
+		dumpRequests:   make(chan *dumpRequest, maxDumpRequests),
 	}
 
 	return scheduler, nil
@@ -79,6 +83,8 @@ func (pc *Scheduler) runOnce() {
 	ssn := framework.OpenSession(pc.cache, pc.plugins, pc.configurations)
 	defer framework.CloseSession(ssn)
 
+	pc.serveDumpRequests(ssn)
+
 	for _, action := range pc.actions {
 		actionStartTime := time.Now()
 		action.Execute(ssn)
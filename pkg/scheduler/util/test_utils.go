@@ -155,6 +155,12 @@ func (ftsu *FakeStatusUpdater) UpdatePodGroup(pg *api.PodGroup) (*api.PodGroup,
 	return nil, nil
 }
 
+// UpdateQueueStatus is a empty function
+func (ftsu *FakeStatusUpdater) UpdateQueueStatus(queue *api.QueueInfo) error {
+	// do nothing here
+	return nil
+}
+
 // FakeVolumeBinder is used as fake volume binder
 type FakeVolumeBinder struct {
 }